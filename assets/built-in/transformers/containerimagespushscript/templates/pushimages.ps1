@@ -0,0 +1,26 @@
+#   Copyright IBM Corporation 2021
+#
+#   Licensed under the Apache License, Version 2.0 (the "License");
+#   you may not use this file except in compliance with the License.
+#   You may obtain a copy of the License at
+#
+#        http://www.apache.org/licenses/LICENSE-2.0
+#
+#   Unless required by applicable law or agreed to in writing, software
+#   distributed under the License is distributed on an "AS IS" BASIS,
+#   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#   See the License for the specific language governing permissions and
+#   limitations under the License.
+
+# Invoke as pushimages.ps1 <registry_url> <registry_namespace>
+param(
+    [string]$RegistryUrl = '{{ .RegistryURL }}',
+    [string]$RegistryNamespace = '{{ .RegistryNamespace }}'
+)
+{{ $containerRuntime := .ContainerRuntime }}
+# Uncomment the below line if you want to enable login before pushing
+# {{ $containerRuntime }} login $RegistryUrl
+
+{{range $image := .Images}}{{ $containerRuntime }} tag {{$image}} "$RegistryUrl/$RegistryNamespace/{{$image}}"
+{{ $containerRuntime }} push "$RegistryUrl/$RegistryNamespace/{{$image}}"
+{{end}}
@@ -25,7 +25,7 @@ cd ..
 {{- range $dockerfile := . }}
 
 pushd {{ $dockerfile.ContextWindows }}
-{{ $dockerfile.ContainerRuntime }} build -f {{ $dockerfile.DockerfileName }} -t {{ $dockerfile.ImageName }} .
+{{ $dockerfile.ContainerRuntime }} build -f {{ $dockerfile.DockerfileName }} -t {{ $dockerfile.ImageName }}{{ if ne $dockerfile.TaggedImageName $dockerfile.ImageName }} -t {{ $dockerfile.TaggedImageName }}{{ end }} .
 popd
 {{- end }}
 
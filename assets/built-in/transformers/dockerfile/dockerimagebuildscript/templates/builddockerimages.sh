@@ -24,7 +24,7 @@ cd .. # go to the parent directory so that all the relative paths will be correc
 
 echo 'building image {{ $dockerfile.ImageName }}'
 cd {{ $dockerfile.ContextUnix }}
-{{ $dockerfile.ContainerRuntime }} build -f {{ $dockerfile.DockerfileName }} -t {{ $dockerfile.ImageName }} .
+{{ $dockerfile.ContainerRuntime }} build -f {{ $dockerfile.DockerfileName }} -t {{ $dockerfile.ImageName }}{{ if ne $dockerfile.TaggedImageName $dockerfile.ImageName }} -t {{ $dockerfile.TaggedImageName }}{{ end }} .
 cd -
 {{- end }}
 
@@ -0,0 +1,9 @@
+# Module stub for the bucket {{ .Name }}{{ if .Host }}, which currently is at {{ .Host }}{{ end }}.
+# Replace the placeholder with the bucket resource of your cloud provider, for example aws_s3_bucket,
+# azurerm_storage_container or google_storage_bucket, and write the outputs to the secret store
+# under var.secret_store_key, so the external secret {{ .SecretName }} can fetch them.
+
+locals {
+  bucket   = var.name
+  endpoint = "TODO"
+}
@@ -0,0 +1,7 @@
+output "bucket" {
+  value = local.bucket
+}
+
+output "endpoint" {
+  value = local.endpoint
+}
@@ -0,0 +1,15 @@
+# Module stub for the database {{ .Name }}{{ if .Host }}, which currently runs at {{ .Host }}{{ if .Port }}:{{ .Port }}{{ end }}{{ end }}.
+# Replace the placeholder with the managed database resource of your cloud provider, for example aws_db_instance,
+# azurerm_postgresql_flexible_server or google_sql_database_instance, and write the outputs to the secret store
+# under var.secret_store_key, so the external secret {{ .SecretName }} can fetch them.
+
+resource "random_password" "password" {
+  length  = 24
+  special = false
+}
+
+locals {
+  host     = "TODO"
+  port     = {{ if .Port }}{{ .Port }}{{ else }}5432{{ end }}
+  username = replace(var.name, "-", "_")
+}
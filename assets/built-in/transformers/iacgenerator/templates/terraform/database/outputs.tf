@@ -0,0 +1,16 @@
+output "host" {
+  value = local.host
+}
+
+output "port" {
+  value = local.port
+}
+
+output "username" {
+  value = local.username
+}
+
+output "password" {
+  value     = random_password.password.result
+  sensitive = true
+}
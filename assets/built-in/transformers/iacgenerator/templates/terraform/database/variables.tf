@@ -0,0 +1,23 @@
+variable "name" {
+  description = "The name of the {{ .Type }}"
+  type        = string
+  default     = "{{ .ProjectName }}-{{ .Name }}"
+}
+
+variable "secret_store_key" {
+  description = "The key in the secret store that the connection details are written to, and that the external secret {{ .SecretName }} fetches"
+  type        = string
+  default     = "{{ .RemoteKey }}"
+}
+
+variable "engine" {
+  description = "The database engine"
+  type        = string
+  default     = "{{ if .Engine }}{{ .Engine }}{{ else }}postgres{{ end }}"
+}
+
+variable "storage_gb" {
+  description = "The size of the storage of the database in GB"
+  type        = number
+  default     = 20
+}
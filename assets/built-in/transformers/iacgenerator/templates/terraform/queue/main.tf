@@ -0,0 +1,9 @@
+# Module stub for the queue {{ .Name }}{{ if .Host }}, which currently runs at {{ .Host }}{{ if .Port }}:{{ .Port }}{{ end }}{{ end }}.
+# Replace the placeholder with the managed message broker or queue of your cloud provider, for example aws_mq_broker,
+# aws_sqs_queue, azurerm_servicebus_queue or google_pubsub_topic, and write the outputs to the secret store
+# under var.secret_store_key, so the external secret {{ .SecretName }} can fetch them.
+
+locals {
+  engine = "{{ if .Engine }}{{ .Engine }}{{ else }}rabbitmq{{ end }}"
+  url    = "TODO"
+}
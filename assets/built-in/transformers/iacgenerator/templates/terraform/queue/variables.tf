@@ -0,0 +1,11 @@
+variable "name" {
+  description = "The name of the {{ .Type }}"
+  type        = string
+  default     = "{{ .ProjectName }}-{{ .Name }}"
+}
+
+variable "secret_store_key" {
+  description = "The key in the secret store that the connection details are written to, and that the external secret {{ .SecretName }} fetches"
+  type        = string
+  default     = "{{ .RemoteKey }}"
+}
@@ -0,0 +1,93 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type artifactsSchemaFlags struct {
+	outputPath string
+}
+
+func artifactsSchemaHandler(flags artifactsSchemaFlags, configTypes []string) {
+	registeredConfigTypes := []artifacts.RegisteredConfigType{}
+	if len(configTypes) == 0 {
+		registeredConfigTypes = artifacts.GetRegisteredConfigTypes()
+	}
+	for _, configType := range configTypes {
+		registered, ok := artifacts.GetRegisteredConfigType(configType)
+		if !ok {
+			logrus.Fatalf("the config type %s has not been registered", configType)
+		}
+		registeredConfigTypes = append(registeredConfigTypes, registered)
+	}
+	if flags.outputPath == "" {
+		schemaBytes, err := json.MarshalIndent(registeredConfigTypes, "", "  ")
+		if err != nil {
+			logrus.Fatalf("failed to marshal the config type schemas to json. Error: %q", err)
+		}
+		fmt.Println(string(schemaBytes))
+		return
+	}
+	outputPath := filepath.Clean(flags.outputPath)
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		logrus.Fatalf("failed to create the output directory at path %s . Error: %q", outputPath, err)
+	}
+	for _, registered := range registeredConfigTypes {
+		schemaBytes, err := json.MarshalIndent(registered.Schema, "", "  ")
+		if err != nil {
+			logrus.Fatalf("failed to marshal the schema of the config type %s to json. Error: %q", registered.ConfigType, err)
+		}
+		schemaPath := filepath.Join(outputPath, registered.ConfigType+".schema.json")
+		if err := os.WriteFile(schemaPath, schemaBytes, common.DefaultFilePermission); err != nil {
+			logrus.Fatalf("failed to write the schema of the config type %s to a file at path %s . Error: %q", registered.ConfigType, schemaPath, err)
+		}
+	}
+	logrus.Infof("Schemas of %d config types written to %s", len(registeredConfigTypes), outputPath)
+}
+
+// GetArtifactsCommand returns a command to inspect the artifact config types
+func GetArtifactsCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	artifactsCmd := &cobra.Command{
+		Use:   "artifacts",
+		Short: "Inspect the config types that artifacts can carry.",
+		Long:  "Inspect the config types that artifacts passed between transformers can carry.",
+	}
+	flags := artifactsSchemaFlags{}
+	schemaCmd := &cobra.Command{
+		Use:   "schema [config types...]",
+		Short: "Print the JSON schemas of the artifact config types.",
+		Long: `Print the JSON schemas of the artifact config types.
+	By default, it prints the schemas of all the registered config types.
+	External transformers can use these schemas to validate the artifacts they consume and produce.`,
+		Run: func(_ *cobra.Command, args []string) { artifactsSchemaHandler(flags, args) },
+	}
+	schemaCmd.Flags().StringVarP(&flags.outputPath, "output", "o", "", "Path to a directory where the schemas should be written, one file per config type. By default the schemas are printed to the console.")
+	artifactsCmd.AddCommand(schemaCmd)
+	return artifactsCmd
+}
@@ -0,0 +1,86 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/types/plan"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type bugReportFlags struct {
+	planfile   string
+	outpath    string
+	configOut  string
+	qaCacheOut string
+	archive    string
+}
+
+func bugReportHandler(flags bugReportFlags) {
+	outpath := flags.outpath
+	projectName := common.DefaultProjectName
+	if p, err := plan.ReadPlan(flags.planfile, ""); err == nil && p.Name != "" {
+		projectName = p.Name
+	}
+	outpath = filepath.Join(outpath, projectName)
+	configPath := filepath.Join(flags.configOut, common.ConfigFile)
+	inputs := lib.BugReportInputs{
+		PlanPath:   flags.planfile,
+		QAPaths:    []string{configPath, qatypes.GetConfigDocsPath(configPath), filepath.Join(flags.qaCacheOut, common.QACacheFile)},
+		OutputPath: outpath,
+	}
+	if flags.archive == "" {
+		flags.archive = fmt.Sprintf("%s-bugreport-%s.tar.gz", projectName, time.Now().Format("20060102-150405"))
+	}
+	files, err := lib.CreateBugReport(inputs, flags.archive)
+	if err != nil {
+		logrus.Fatalf("Failed to create the bug report. Error: %q", err)
+	}
+	logrus.Infof("The bug report with %d files was written to %s", len(files), flags.archive)
+	logrus.Debugf("The bug report has the files:\n\t%s", strings.Join(files, "\n\t"))
+	logrus.Infof("Passwords, tokens and other secrets have been redacted. Review the contents before attaching it to an issue.")
+}
+
+// GetBugReportCommand returns a command to create a diagnostic bundle for filing issues
+func GetBugReportCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	flags := bugReportFlags{}
+	bugReportCmd := &cobra.Command{
+		Use:   "bugreport",
+		Short: "Package the files needed to reproduce a problem into an archive.",
+		Long: `Package the plan, the configs of the transformers used by the plan, the QA config and cache, the logs, the reports and the environment info into an archive.
+	Passwords, tokens and other secrets are redacted. Run it in the directory where the plan and the transform were run.
+	Use --save-logs during the transform to include its logs.`,
+		Args: cobra.NoArgs,
+		Run:  func(*cobra.Command, []string) { bugReportHandler(flags) },
+	}
+	bugReportCmd.Flags().StringVarP(&flags.planfile, planFlag, "p", common.DefaultPlanFile, "Specify the plan file.")
+	bugReportCmd.Flags().StringVarP(&flags.outpath, outputFlag, "o", ".", "Specify the output path given to the transform.")
+	bugReportCmd.Flags().StringVar(&flags.configOut, configOutFlag, ".", "Specify the config file output location given to the transform.")
+	bugReportCmd.Flags().StringVar(&flags.qaCacheOut, qaCacheOutFlag, ".", "Specify the cache file output location given to the transform.")
+	bugReportCmd.Flags().StringVarP(&flags.archive, "archive", "a", "", "Path of the archive to create. By default it is created in the current directory and named after the project.")
+	return bugReportCmd
+}
@@ -0,0 +1,177 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// transformCheckpointKind is the kind of the transform checkpoint file
+	transformCheckpointKind types.Kind = "TransformCheckpoint"
+)
+
+// transformCheckpoint stores the state of a transform that has not finished yet
+type transformCheckpoint struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             transformCheckpointSpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// transformCheckpointSpec stores the flags that the transform was started with
+type transformCheckpointSpec struct {
+	WorkingDir string              `yaml:"workingDir" json:"workingDir"`
+	Flags      map[string][]string `yaml:"flags,omitempty" json:"flags,omitempty"`
+}
+
+// writeTransformCheckpoint records the flags of the transform, so that it can be resumed if it gets interrupted.
+// The passwords set using the set-config flag are only recorded if the passwords should be persisted.
+func writeTransformCheckpoint(cmd *cobra.Command, projectName string, persistPasswords bool) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		logrus.Warnf("Failed to get the current working directory. Error: %q", err)
+	}
+	checkpoint := transformCheckpoint{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(transformCheckpointKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: types.ObjectMeta{Name: projectName},
+		Spec:       transformCheckpointSpec{WorkingDir: workingDir, Flags: map[string][]string{}},
+	}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if flag.Name == resumeFlag {
+			return
+		}
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			values := sliceValue.GetSlice()
+			if flag.Name == setConfigFlag && !persistPasswords {
+				values = removePasswordConfigs(values)
+			}
+			checkpoint.Spec.Flags[flag.Name] = values
+			return
+		}
+		checkpoint.Spec.Flags[flag.Name] = []string{flag.Value.String()}
+	})
+	if err := common.WriteYaml(common.TransformCheckpointFile, checkpoint); err != nil {
+		logrus.Warnf("Failed to write the transform checkpoint to the file at path %s . The transform cannot be resumed if it is interrupted. Error: %q", common.TransformCheckpointFile, err)
+	}
+}
+
+// removePasswordConfigs removes the key value pairs of the set-config flag that set passwords
+func removePasswordConfigs(configs []string) []string {
+	filteredConfigs := []string{}
+	for _, config := range configs {
+		key := strings.TrimSpace(strings.SplitN(config, "=", 2)[0])
+		if strings.EqualFold(key[strings.LastIndex(key, common.Delim)+1:], "password") {
+			logrus.Infof("The config %s is not stored in the transform checkpoint since it is a password. Use the %s flag to store it.", key, qaPersistPasswords)
+			continue
+		}
+		filteredConfigs = append(filteredConfigs, config)
+	}
+	return filteredConfigs
+}
+
+// removeTransformCheckpoint removes the checkpoint once the transform has finished
+func removeTransformCheckpoint() {
+	if err := os.Remove(common.TransformCheckpointFile); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove the transform checkpoint file at path %s . Error: %q", common.TransformCheckpointFile, err)
+	}
+}
+
+// resumeTransformFlags restores the flags of the interrupted transform.
+// Flags that are specified on the command line take precedence over the ones in the checkpoint.
+func resumeTransformFlags(cmd *cobra.Command) {
+	checkpoint := transformCheckpoint{}
+	if err := common.ReadMove2KubeYamlStrict(common.TransformCheckpointFile, &checkpoint, string(transformCheckpointKind)); err != nil {
+		logrus.Fatalf("Unable to find an interrupted transform to resume. Failed to read the checkpoint file at path %s . Error: %q", common.TransformCheckpointFile, err)
+	}
+	if workingDir, err := os.Getwd(); err == nil && checkpoint.Spec.WorkingDir != "" && workingDir != checkpoint.Spec.WorkingDir {
+		logrus.Fatalf("The interrupted transform was run in the directory %s . Run the command again from that directory to resume it.", checkpoint.Spec.WorkingDir)
+	}
+	logrus.Infof("Resuming the interrupted transform of the project %s using the checkpoint at path %s", checkpoint.Name, common.TransformCheckpointFile)
+	for flagName, values := range checkpoint.Spec.Flags {
+		if cmd.Flags().Changed(flagName) {
+			continue
+		}
+		for _, value := range values {
+			if err := cmd.Flags().Set(flagName, value); err != nil {
+				logrus.Fatalf("Failed to restore the value %s of the flag %s from the checkpoint. Error: %q", value, flagName, err)
+			}
+		}
+	}
+	// the output directory of the interrupted transform already exists
+	if err := cmd.Flags().Set(overwriteFlag, "true"); err != nil {
+		logrus.Fatalf("Failed to set the flag %s . Error: %q", overwriteFlag, err)
+	}
+}
+
+// restartTransform restarts the transform using the checkpoint and the answers given so far.
+// It is used when the user edits an answer that the earlier steps of the transform may already have used.
+func restartTransform() {
+	if err := qaengine.WriteStoresToDisk(); err != nil {
+		logrus.Fatalf("Failed to write the answers to disk before restarting the transform. Error: %q", err)
+	}
+	lib.Destroy()
+	// the restarted transform uses the same output directory and the same config and cache files
+	common.ReleaseLocks()
+	executable, err := os.Executable()
+	if err != nil {
+		logrus.Fatalf("Failed to find the executable to restart the transform. Error: %q", err)
+	}
+	args := os.Args[1:]
+	if !common.IsStringPresent(args, "--"+resumeFlag) {
+		args = append(args, "--"+resumeFlag)
+	}
+	// the restarted transform is part of the same run
+	if !hasFlag(args, runIDFlag) {
+		args = append(args, "--"+runIDFlag, common.RunID)
+	}
+	logrus.Infof("Restarting the transform")
+	common.CloseLogSinks()
+	restartCmd := exec.Command(executable, args...)
+	restartCmd.Stdin = os.Stdin
+	restartCmd.Stdout = os.Stdout
+	restartCmd.Stderr = os.Stderr
+	if err := restartCmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		logrus.Fatalf("Failed to restart the transform. Error: %q", err)
+	}
+	os.Exit(0)
+}
+
+// hasFlag checks if the flag is present in the command line arguments
+func hasFlag(args []string, flagName string) bool {
+	for _, arg := range args {
+		if arg == "--"+flagName || strings.HasPrefix(arg, "--"+flagName+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	maxKeySuggestions = 5
+)
+
+type configExplainFlags struct {
+	configOut string
+}
+
+func configExplainHandler(flags configExplainFlags, key string) {
+	docsPath := qatypes.GetConfigDocsPath(filepath.Join(flags.configOut, common.ConfigFile))
+	docs, err := qatypes.ReadConfigDocs(docsPath)
+	if err != nil {
+		logrus.Fatalf("failed to read the documentation of the config keys at path %s . Run a transform to generate it. Error: %q", docsPath, err)
+	}
+	keyDoc, ok := docs.Find(key)
+	if !ok {
+		suggestions := []string{}
+		for _, docKey := range docs.Keys() {
+			if strings.Contains(docKey, key) {
+				suggestions = append(suggestions, docKey)
+			}
+		}
+		if len(suggestions) == 0 {
+			suggestions = common.GetClosestMatches(key, docs.Keys(), maxKeySuggestions)
+		}
+		if len(suggestions) == 0 {
+			logrus.Fatalf("the key %s does not match any of the questions documented in %s", key, docsPath)
+		}
+		logrus.Fatalf("the key %s does not match any of the questions documented in %s . Did you mean: %s", key, docsPath, strings.Join(suggestions, " , "))
+	}
+	fmt.Printf("%d. %s\n", keyDoc.Number, keyDoc.Key)
+	if keyDoc.Question != "" {
+		fmt.Printf("Question: %s\n", keyDoc.Question)
+	}
+	for _, hint := range keyDoc.Hints {
+		fmt.Printf("Hint: %s\n", hint)
+	}
+	if keyDoc.Type != "" {
+		fmt.Printf("Type: %s\n", keyDoc.Type)
+	}
+	if len(keyDoc.AllowedValues) > 0 {
+		fmt.Printf("Allowed values:\n\t%s\n", strings.Join(keyDoc.AllowedValues, "\n\t"))
+	}
+	if keyDoc.Default != nil {
+		fmt.Printf("Default: %v\n", keyDoc.Default)
+	}
+}
+
+// GetConfigCommand returns a command to inspect the config file
+func GetConfigCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the keys of the config file.",
+		Long: `Inspect the keys of the config file.
+	The keys are documented in a file written alongside the config file during transform.`,
+	}
+	flags := configExplainFlags{}
+	explainCmd := &cobra.Command{
+		Use:   "explain <key or number>",
+		Short: "Explain a key of the config file.",
+		Long: `Explain a key of the config file: the question it answers, the hints and the allowed values.
+	The key can also be the number of the question, in the order in which the questions were asked.`,
+		Args: cobra.ExactArgs(1),
+		Run:  func(_ *cobra.Command, args []string) { configExplainHandler(flags, args[0]) },
+	}
+	explainCmd.Flags().StringVar(&flags.configOut, configOutFlag, ".", "Specify the directory with the config file written by the transform.")
+	configCmd.AddCommand(explainCmd)
+	return configCmd
+}
@@ -0,0 +1,147 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type diffOutputFlags struct {
+	// planfile is contains the path to the plan file
+	planfile string
+	// srcpath contains path to the source folder
+	srcpath string
+	// configs contains the paths of the config files with the answers used for the transform
+	configs []string
+	// setconfigs contains config key-value pairs
+	setconfigs []string
+	// preSets contains the preset configs
+	preSets             []string
+	transformerSelector string
+	// showDiff prints the unified diffs of the files that drifted
+	showDiff bool
+	// exitCode exits with status 1 if there is drift
+	exitCode bool
+}
+
+func diffOutputHandler(cmd *cobra.Command, flags diffOutputFlags, outputPath string) {
+	ctx := cmd.Context()
+	defer lib.Destroy()
+	var err error
+	if outputPath, err = filepath.Abs(outputPath); err != nil {
+		logrus.Fatalf("Failed to make the output directory path %q absolute. Error: %q", outputPath, err)
+	}
+	if fi, err := os.Stat(outputPath); err != nil || !fi.IsDir() {
+		logrus.Fatalf("The output directory %s does not exist. Error: %v", outputPath, err)
+	}
+	if flags.srcpath != "" {
+		if flags.srcpath, err = filepath.Abs(flags.srcpath); err != nil {
+			logrus.Fatalf("Failed to make the source directory path %q absolute. Error: %q", flags.srcpath, err)
+		}
+	}
+	p, err := plan.ReadPlan(flags.planfile, flags.srcpath)
+	if err != nil {
+		logrus.Fatalf("Unable to read the plan at path %s Error: %q", flags.planfile, err)
+	}
+	if !cmd.Flags().Changed(configFlag) {
+		if _, err := os.Stat(common.ConfigFile); err == nil {
+			flags.configs = []string{common.ConfigFile}
+		}
+	}
+	checkSourcePath(p.Spec.SourceDir)
+	lib.CheckAndCopyCustomizations(p.Spec.CustomizationsDir)
+	generatedPath, err := os.MkdirTemp(common.TempPath, "diff-output-")
+	if err != nil {
+		logrus.Fatalf("Failed to create a temporary directory for the output. Error: %q", err)
+	}
+	defer os.RemoveAll(generatedPath)
+	// the answers are taken from the configs and the defaults, and the config and cache are written to the temporary directory
+	startQA(qaflags{
+		qaskip:       true,
+		qadisablecli: true,
+		configOut:    generatedPath,
+		qaCacheOut:   generatedPath,
+		configs:      flags.configs,
+		setconfigs:   flags.setconfigs,
+		preSets:      flags.preSets,
+	})
+	transformedPath := filepath.Join(generatedPath, p.Name)
+	lib.Transform(ctx, p, transformedPath, flags.transformerSelector)
+	drifts, hasRecordedHashes, err := lib.GetOutputDrift(transformedPath, outputPath, flags.showDiff)
+	if err != nil {
+		logrus.Fatalf("Failed to compare the output directory %s with the generated output. Error: %q", outputPath, err)
+	}
+	if !hasRecordedHashes {
+		logrus.Warnf("The output directory %s does not have a %s file, so the files edited after the transform cannot be told apart from the changes in the generated files.", outputPath, common.OutputHashesFile)
+	}
+	numDrifts := 0
+	for _, drift := range drifts {
+		if drift.IsDrift() {
+			numDrifts++
+		}
+		fmt.Printf("%-9s %s\n", drift.Status, drift.Path)
+	}
+	if flags.showDiff {
+		for _, drift := range drifts {
+			if drift.Diff != "" {
+				fmt.Print(drift.Diff)
+			}
+		}
+	}
+	if numDrifts == 0 {
+		logrus.Infof("The output directory %s is up to date.", outputPath)
+		return
+	}
+	logrus.Infof("%d files in the output directory %s would change if it was generated again. Files marked as conflict were edited after the transform.", numDrifts, outputPath)
+	if flags.exitCode {
+		lib.Destroy()
+		os.Exit(1)
+	}
+}
+
+// GetDiffOutputCommand returns a command to find the drift between the output directory and what would be generated now
+func GetDiffOutputCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	flags := diffOutputFlags{}
+	diffOutputCmd := &cobra.Command{
+		Use:   "diff-output <output-dir>",
+		Short: "Report the drift between an output directory and what the transform would generate now.",
+		Long: `Run the transform again using the same plan and answers into a temporary directory, and compare it with the output directory.
+	The hashes recorded in ` + common.OutputHashesFile + ` by the transform are used to tell the files edited after the transform apart from the changes in the generated files.
+	Files that are safe to regenerate are reported as changed, and files that were also edited are reported as conflict.`,
+		Args: cobra.ExactArgs(1),
+		Run:  func(cmd *cobra.Command, args []string) { diffOutputHandler(cmd, flags, args[0]) },
+	}
+	diffOutputCmd.Flags().StringVarP(&flags.planfile, planFlag, "p", common.DefaultPlanFile, "Specify the plan file used for the transform.")
+	diffOutputCmd.Flags().StringVarP(&flags.srcpath, sourceFlag, "s", "", "Specify the source directory. If not specified, it is taken from the plan.")
+	diffOutputCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify the config files with the answers used for the transform. By default we look for "+common.ConfigFile)
+	diffOutputCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
+	diffOutputCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
+	diffOutputCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
+	diffOutputCmd.Flags().BoolVar(&flags.showDiff, "show-diff", false, "Print the unified diffs of the files that would change.")
+	diffOutputCmd.Flags().BoolVar(&flags.exitCode, "exit-code", false, "Exit with status 1 if the output directory would change.")
+	return diffOutputCmd
+}
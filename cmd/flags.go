@@ -43,6 +43,64 @@ const (
 	preSetFlag = "preset"
 	// overwriteFlag is the name of the flag that lets you overwrite the output directory if it exists
 	overwriteFlag = "overwrite"
+	// resumeFlag is the name of the flag that lets you resume a previous interrupted transform
+	resumeFlag = "resume"
+	// forceUnlockFlag is the name of the flag that lets you remove the locks left behind by a run that did not finish cleanly
+	forceUnlockFlag = "force-unlock"
+	// runIDFlag is the name of the flag that sets the correlation ID of the run
+	runIDFlag = "run-id"
+	// logSinkFlag is the name of the flag that contains the URLs of the sinks to ship the logs to
+	logSinkFlag = "log-sink"
+	// saveLogsFlag is the name of the flag that saves the logs of the run in the output directory
+	saveLogsFlag = "save-logs"
+	// maxDurationFlag is the name of the flag that contains the maximum duration of the run
+	maxDurationFlag = "max-duration"
+	// maxDiskFlag is the name of the flag that contains the maximum disk space the run can use
+	maxDiskFlag = "max-disk"
+	// devFlag is the name of the flag that reloads the custom transformers when they change during the transform
+	devFlag = "dev"
+	// watchFlag is the name of the flag that runs the transform again when the customizations change
+	watchFlag = "watch"
+	// debugArtifactsFlag is the name of the flag that contains the directory to write the artifacts and path mappings of each transformer run to
+	debugArtifactsFlag = "debug-artifacts"
+	// debugArtifactsEnvFlag is the name of the flag that lets you write the temporary files of the environments of the transformers along with the debug artifacts
+	debugArtifactsEnvFlag = "debug-artifacts-env"
+	// imageTagStrategyFlag is the name of the flag that selects the strategy used to tag the new images
+	imageTagStrategyFlag = "image-tag-strategy"
+	// streamOutputFlag is the name of the flag that lets you see the output of the commands run in the containers of the transformers as it is written
+	streamOutputFlag = "stream-output"
+	// stepFlag is the name of the flag that lets you pause the transform before each transformer runs
+	stepFlag = "step"
+	// maxDetectDepthFlag is the name of the flag that contains the maximum depth of the directories looked at while planning
+	maxDetectDepthFlag = "max-detect-depth"
+	// maxDetectFilesPerDirFlag is the name of the flag that contains the maximum number of files in the directories looked at while planning
+	maxDetectFilesPerDirFlag = "max-detect-files-per-dir"
+	// maxDetectFileSizeFlag is the name of the flag that contains the maximum size of the files looked at while planning
+	maxDetectFileSizeFlag = "max-detect-file-size"
+	// verifyDirFlag is the name of the flag that contains the directory with the expected files and the assertions about the output
+	verifyDirFlag = "verify-dir"
+	// buildImagesFlag is the name of the flag that lets you build the container images after the transform
+	buildImagesFlag = "build-images"
+	// pushImagesFlag is the name of the flag that lets you push the container images after building them
+	pushImagesFlag = "push-images"
+	// buildParallelismFlag is the name of the flag that contains the number of images to build at the same time
+	buildParallelismFlag = "build-parallelism"
+	// prefetchFlag is the name of the flag that lets you pull the container images needed by the transformers before the transform
+	prefetchFlag = "prefetch"
+	// pullParallelismFlag is the name of the flag that contains the number of images to pull at the same time
+	pullParallelismFlag = "pull-parallelism"
+	// waveFlag is the name of the flag that restricts the transform to the services in the given migration waves of the plan
+	waveFlag = "wave"
+	// containerFilesOwnerFlag is the name of the flag that contains the user and group that own the directories copied into the containers of the transformers
+	containerFilesOwnerFlag = "container-files-owner"
+	// outputOwnerFlag is the name of the flag that contains the user and group that own the output files when running as root
+	outputOwnerFlag = "output-owner"
+	// telemetryFlag is the name of the flag that selects whether the anonymous usage summary is written and submitted
+	telemetryFlag = "telemetry"
+	// telemetryEndpointFlag is the name of the flag that contains the endpoint the usage summary is submitted to
+	telemetryEndpointFlag = "telemetry-endpoint"
+	// signManifestKeyFlag is the name of the flag that contains the cosign key used to sign the manifest of the output
+	signManifestKeyFlag = "sign-manifest-key"
 	// customizationsFlag is the path to customizations directory
 	customizationsFlag      = "customizations"
 	qadisablecliFlag        = "qa-disable-cli"
@@ -68,4 +126,6 @@ type qaflags struct {
 	preSets []string
 	// persistPasswords sets whether to persist the password or not
 	persistPasswords bool
+	// resume sets whether to reuse the answers saved by a previous interrupted run
+	resume bool
 }
@@ -17,15 +17,15 @@
 package cmd
 
 import (
-	"context"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/telemetry"
 	"github.com/konveyor/move2kube/lib"
 	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
 	plantypes "github.com/konveyor/move2kube/types/plan"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -40,6 +40,28 @@ type planFlags struct {
 	customizationsPath    string
 	transformerSelector   string
 	disableLocalExecution bool
+	offline               bool
+	buildCacheDir         string
+	containerRuntime      string
+	featureGates          []string
+	// environmentCacheDir is the directory where the prepared images of the environments are cached across runs
+	environmentCacheDir string
+	// streamOutput logs the output of the commands run in containers as it is written
+	streamOutput bool
+	// telemetry selects whether the anonymous usage summary is written and submitted
+	telemetry string
+	// telemetryEndpoint is the endpoint the usage summary is submitted to
+	telemetryEndpoint string
+	// containerFilesOwner is the user and group that own the directories copied into the containers
+	containerFilesOwner string
+	// outputOwner is the user and group that own the output files when running as root
+	outputOwner string
+	// maxDetectDepth is the maximum depth of the directories looked at while planning
+	maxDetectDepth int
+	// maxDetectFilesPerDir is the maximum number of files in the directories looked at while planning
+	maxDetectFilesPerDir int
+	// maxDetectFileSize is the maximum size of the files looked at while planning
+	maxDetectFileSize string
 	//Configs contains a list of config files
 	configs []string
 	//Configs contains a list of key-value configs
@@ -49,16 +71,10 @@ type planFlags struct {
 }
 
 func planHandler(cmd *cobra.Command, flags planFlags) {
-	ctx, cancel := context.WithCancel(cmd.Context())
+	ctx, cancel := handleInterrupts(cmd.Context(), lib.Destroy)
 	logrus.AddHook(common.NewCleanupHook(cancel))
 	logrus.AddHook(common.NewCleanupHook(lib.Destroy))
-	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
-	go func() {
-		<-ctx.Done()
-		lib.Destroy()
-		stop()
-		common.Interrupt()
-	}()
+	defer cancel()
 	defer lib.Destroy()
 
 	var err error
@@ -95,6 +111,26 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	customizationsPath := flags.customizationsPath
 	// Global settings
 	common.DisableLocalExecution = flags.disableLocalExecution
+	common.Offline = flags.offline
+	common.StreamCommandOutput = flags.streamOutput
+	setOwners(flags.containerFilesOwner, flags.outputOwner)
+	if err := common.SetFeatureGates(flags.featureGates); err != nil {
+		logrus.Fatalf("Failed to set the feature gates. Error: %q", err)
+	}
+	if flags.buildCacheDir != "" {
+		buildCacheDir, err := filepath.Abs(flags.buildCacheDir)
+		if err != nil {
+			logrus.Fatalf("Failed to make the build cache directory path %q absolute. Error: %q", flags.buildCacheDir, err)
+		}
+		common.BuildCacheDir = buildCacheDir
+	}
+	if flags.environmentCacheDir != "" {
+		environmentCacheDir, err := filepath.Abs(flags.environmentCacheDir)
+		if err != nil {
+			logrus.Fatalf("Failed to make the environment cache directory path %q absolute. Error: %q", flags.environmentCacheDir, err)
+		}
+		common.EnvironmentCacheDir = environmentCacheDir
+	}
 	// Global settings
 
 	planfile, err = filepath.Abs(planfile)
@@ -124,8 +160,12 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	} else if fi.IsDir() {
 		planfile = filepath.Join(planfile, common.DefaultPlanFile)
 	}
+	startTelemetry(flags.telemetry, flags.telemetryEndpoint, "plan", filepath.Join(filepath.Dir(planfile), common.UsageSummaryFile))
 	qaengine.StartEngine(true, 0, true)
-	qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, false)
+	setconfigs := addContainerRuntimeConfig(flags.setconfigs, flags.containerRuntime)
+	setconfigs = addDetectionLimitsConfig(cmd, setconfigs, flags.maxDetectDepth, flags.maxDetectFilesPerDir, flags.maxDetectFileSize)
+	qaengine.SetupConfigFile("", setconfigs, flags.configs, flags.preSets, false, false)
+	setDetectionLimits()
 	if flags.progressServerPort != 0 {
 		startPlanProgressServer(flags.progressServerPort)
 	}
@@ -134,7 +174,14 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 		logrus.Errorf("Unable to write plan file (%s) : %s", planfile, err)
 		return
 	}
+	if err := common.ChownToOutputOwner(planfile); err != nil {
+		logrus.Warnf("Unable to change the owner of the plan file. Error: %q", err)
+	}
 	logrus.Infof("Plan can be found at [%s].", planfile)
+	if images := lib.GetTransformerImages(p, flags.transformerSelector); len(images) > 0 {
+		logrus.Infof("The transformation will use the container images %+v . Run '%s prefetch' to pull them before the transformation.", images, types.AppName)
+	}
+	telemetry.Finish(ctx)
 }
 
 // GetPlanCommand returns a command to do the planning
@@ -164,6 +211,20 @@ func GetPlanCommand() *cobra.Command {
 	planCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
 	planCmd.Flags().IntVar(&flags.progressServerPort, planProgressPortFlag, 0, "Port for the plan progress server. If not provided, the server won't be started.")
 	planCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
+	planCmd.Flags().BoolVar(&flags.offline, common.OfflineFlag, false, "Do not access the network. Container images needed by the transformers have to be available locally.")
+	planCmd.Flags().StringSliceVar(&flags.featureGates, common.FeatureGatesFlag, []string{}, "Enable or disable experimental transformers and behaviours, for example --"+common.FeatureGatesFlag+"=NewComposeEngine=true . The feature gates are:\n"+common.GetFeatureGatesUsage())
+	planCmd.Flags().StringVar(&flags.containerRuntime, common.ContainerRuntimeFlag, "", "Container runtime used to spawn the containers of the transformers: auto, docker, podman, kubernetes or none. none disables the transformers that rely on containers.")
+	planCmd.Flags().BoolVar(&flags.streamOutput, streamOutputFlag, false, "Show the output of the detect commands run in the containers of the transformers as it is written.")
+	planCmd.Flags().StringVar(&flags.containerFilesOwner, containerFilesOwnerFlag, "", "User and group, like 1001:0, that own the directories copied into the containers of the transformers, for images that do not run as root. By default they keep the owner of the files, or are owned by root for rootless docker and podman.")
+	planCmd.Flags().StringVar(&flags.outputOwner, outputOwnerFlag, "", "User and group, like 1000:1000, that own the plan file when running as root. Defaults to the user that ran move2kube using sudo.")
+	planCmd.Flags().StringVar(&flags.telemetry, telemetryFlag, telemetry.Off, "Write an anonymous summary of the run, with the transformers used, the platforms detected and the number of errors, to "+common.UsageSummaryFile+" (next to the plan file) using local, and also submit it using submit. The summary does not have the names of the services or any paths.")
+	planCmd.Flags().StringVar(&flags.telemetryEndpoint, telemetryEndpointFlag, "", "Endpoint that the usage summary is submitted to as JSON when --"+telemetryFlag+"=submit.")
+	planCmd.Flags().StringVar(&flags.buildCacheDir, common.BuildCacheDirFlag, "", "Directory where the container images built for the transformers are cached, so that later runs (for example on CI) do not build identical images again.")
+	planCmd.Flags().StringVar(&flags.environmentCacheDir, common.EnvironmentCacheDirFlag, "", "Directory where the images of the transformers with the source copied into them are saved and loaded from, so that later runs (for example on CI) with the same source do not copy it into the images again.")
+
+	planCmd.Flags().IntVar(&flags.maxDetectDepth, maxDetectDepthFlag, 0, "Maximum depth of the directories below the source directory to look at. 0 looks at all the directories.")
+	planCmd.Flags().IntVar(&flags.maxDetectFilesPerDir, maxDetectFilesPerDirFlag, common.DefaultMaxFilesPerDir, "Maximum number of files in a directory. Bigger directories, like accidentally committed dependencies, are skipped with a warning. 0 looks at all the directories.")
+	planCmd.Flags().StringVar(&flags.maxDetectFileSize, maxDetectFileSizeFlag, "", "Maximum size of the files to look at, like 10Mi. Bigger files are skipped. By default all the files are looked at.")
 
 	must(planCmd.MarkFlagRequired(sourceFlag))
 	must(planCmd.Flags().MarkHidden(planProgressPortFlag))
@@ -0,0 +1,76 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type prefetchFlags struct {
+	planfile            string
+	transformerSelector string
+	pullParallelism     int
+	containerRuntime    string
+	//Configs contains a list of config files
+	configs []string
+	//Configs contains a list of key-value configs
+	setconfigs []string
+}
+
+func prefetchHandler(cmd *cobra.Command, flags prefetchFlags) {
+	ctx, cancel := handleInterrupts(cmd.Context())
+	defer cancel()
+	p, err := plan.ReadPlan(flags.planfile, "")
+	if err != nil {
+		logrus.Fatalf("Unable to read the plan at path %s Error: %q", flags.planfile, err)
+	}
+	qaengine.StartEngine(true, 0, true)
+	// pulling the images is the reason for running the command, so spawning containers is enabled
+	setconfigs := append(addContainerRuntimeConfig(flags.setconfigs, flags.containerRuntime), common.ConfigSpawnContainersKey+"=true")
+	qaengine.SetupConfigFile("", setconfigs, flags.configs, []string{}, false, false)
+	if err := lib.PrefetchImages(ctx, p, flags.transformerSelector, flags.pullParallelism); err != nil {
+		logrus.Fatalf("Failed to prefetch the container images. Error: %q", err)
+	}
+}
+
+// GetPrefetchCommand returns a command to pull the container images needed by the transformers before the transform
+func GetPrefetchCommand() *cobra.Command {
+	viper.AutomaticEnv()
+
+	flags := prefetchFlags{}
+	prefetchCmd := &cobra.Command{
+		Use:   "prefetch",
+		Short: "Pull the container images needed to transform using the plan",
+		Long:  "Pull the container images that the transformers of the plan need, in parallel, so that the transform does not stall on slow pulls.",
+		Run:   func(cmd *cobra.Command, _ []string) { prefetchHandler(cmd, flags) },
+	}
+
+	prefetchCmd.Flags().StringVarP(&flags.planfile, planFlag, "p", common.DefaultPlanFile, "Specify the plan file to get the transformers from.")
+	prefetchCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
+	prefetchCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations.")
+	prefetchCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
+	prefetchCmd.Flags().IntVar(&flags.pullParallelism, pullParallelismFlag, 4, "Number of container images to pull at the same time.")
+	prefetchCmd.Flags().StringVar(&flags.containerRuntime, common.ContainerRuntimeFlag, "", "Container runtime to pull the images into: auto, docker, podman or kubernetes.")
+
+	return prefetchCmd
+}
@@ -29,6 +29,8 @@ import (
 func GetRootCmd() *cobra.Command {
 	loglevel := logrus.InfoLevel.String()
 	logFile := ""
+	runID := ""
+	logSinks := []string{}
 
 	// RootCmd root level flags and commands
 	rootCmd := &cobra.Command{
@@ -54,18 +56,44 @@ func GetRootCmd() *cobra.Command {
 				}
 				logrus.SetOutput(io.MultiWriter(f, os.Stdout))
 			}
+			if runID == "" {
+				runID = common.NewRunID()
+			}
+			common.RunID = runID
+			logrus.AddHook(common.NewRunIDHook(runID))
+			for _, logSink := range logSinks {
+				hook, err := common.NewLogSinkHook(logSink)
+				if err != nil {
+					logrus.Errorf("Unable to ship the logs to the sink %s . Error: %q", logSink, err)
+					continue
+				}
+				logrus.AddHook(hook)
+			}
+			logrus.RegisterExitHandler(common.CloseLogSinks)
 			return nil
 		},
+		PersistentPostRun: func(*cobra.Command, []string) {
+			common.CloseLogSinks()
+		},
 	}
 
 	rootCmd.PersistentFlags().StringVar(&loglevel, "log-level", logrus.InfoLevel.String(), "Set logging levels.")
 	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "File to store the logs in. By default it only prints to console.")
+	rootCmd.PersistentFlags().StringVar(&runID, runIDFlag, "", "Correlation ID of the run, added to all the logs. By default a new ID is generated for each run.")
+	rootCmd.PersistentFlags().StringArrayVar(&logSinks, logSinkFlag, []string{}, "URL of a sink to ship the logs to. http and https URLs receive the logs as JSON. syslog (UDP) and syslog+tcp URLs receive them as syslog messages. Example: syslog://localhost:514")
 
 	rootCmd.AddCommand(GetVersionCommand())
 	rootCmd.AddCommand(GetCollectCommand())
 	rootCmd.AddCommand(GetPlanCommand())
 	rootCmd.AddCommand(GetTransformCommand())
+	rootCmd.AddCommand(GetPrefetchCommand())
 	rootCmd.AddCommand(GetGenerateDocsCommand())
 	rootCmd.AddCommand(GetGraphCommand())
+	rootCmd.AddCommand(GetArtifactsCommand())
+	rootCmd.AddCommand(GetSchemaCommand())
+	rootCmd.AddCommand(GetConfigCommand())
+	rootCmd.AddCommand(GetBugReportCommand())
+	rootCmd.AddCommand(GetDiffOutputCommand())
+	rootCmd.AddCommand(GetVerifyOutputCommand())
 	return rootCmd
 }
@@ -0,0 +1,212 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/jsonschema"
+	"github.com/konveyor/move2kube/transformer/kubernetes"
+	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
+	"github.com/konveyor/move2kube/types"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configSchemaKind is the name of the schema for the config files, which do not have a kind
+	configSchemaKind = "Config"
+)
+
+type schemaFlags struct {
+	outputPath string
+}
+
+type schemaValidateFlags struct {
+	kind   string
+	strict bool
+}
+
+func getSchemas() map[string]jsonschema.Schema {
+	return map[string]jsonschema.Schema{
+		string(plantypes.PlanKind):               jsonschema.GenerateSchema(reflect.TypeOf(plantypes.Plan{}), string(plantypes.PlanKind)),
+		transformertypes.TransformerKind:         jsonschema.GenerateSchema(reflect.TypeOf(transformertypes.Transformer{}), transformertypes.TransformerKind),
+		string(collecttypes.ClusterMetadataKind): jsonschema.GenerateSchema(reflect.TypeOf(collecttypes.ClusterMetadata{}), string(collecttypes.ClusterMetadataKind)),
+		string(qatypes.QACacheKind):              jsonschema.GenerateSchema(reflect.TypeOf(qatypes.Cache{}), string(qatypes.QACacheKind)),
+		parameterizer.ParameterizerKind:          jsonschema.GenerateSchema(reflect.TypeOf(parameterizer.ParameterizerFileT{}), parameterizer.ParameterizerKind),
+		kubernetes.PriceSheetKind:                jsonschema.GenerateSchema(reflect.TypeOf(kubernetes.PriceSheetT{}), kubernetes.PriceSheetKind),
+		configSchemaKind:                         getConfigSchema(),
+	}
+}
+
+// getConfigSchema returns the schema of the config files passed using --config.
+// The keys under move2kube depend on the questions that get asked, so only the root is fixed.
+func getConfigSchema() jsonschema.Schema {
+	return jsonschema.Schema{
+		"$schema": jsonschema.SchemaVersion,
+		"title":   configSchemaKind,
+		"type":    "object",
+		"properties": jsonschema.Schema{
+			common.BaseKey: jsonschema.Schema{"type": []interface{}{"object", "null"}},
+		},
+		"required": []interface{}{common.BaseKey},
+	}
+}
+
+func getSchemaKinds(schemas map[string]jsonschema.Schema) []string {
+	kinds := []string{}
+	for kind := range schemas {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func schemaHandler(flags schemaFlags, kinds []string) {
+	schemas := getSchemas()
+	if len(kinds) == 0 {
+		kinds = getSchemaKinds(schemas)
+	}
+	selected := map[string]jsonschema.Schema{}
+	for _, kind := range kinds {
+		schema, ok := schemas[kind]
+		if !ok {
+			logrus.Fatalf("there is no schema for the kind %s . Valid kinds are %+v", kind, getSchemaKinds(schemas))
+		}
+		selected[kind] = schema
+	}
+	if flags.outputPath == "" {
+		schemaBytes, err := json.MarshalIndent(selected, "", "  ")
+		if err != nil {
+			logrus.Fatalf("failed to marshal the schemas to json. Error: %q", err)
+		}
+		fmt.Println(string(schemaBytes))
+		return
+	}
+	outputPath := filepath.Clean(flags.outputPath)
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		logrus.Fatalf("failed to create the output directory at path %s . Error: %q", outputPath, err)
+	}
+	for _, kind := range kinds {
+		schemaBytes, err := json.MarshalIndent(selected[kind], "", "  ")
+		if err != nil {
+			logrus.Fatalf("failed to marshal the schema of the kind %s to json. Error: %q", kind, err)
+		}
+		schemaPath := filepath.Join(outputPath, strings.ToLower(kind)+".schema.json")
+		if err := os.WriteFile(schemaPath, schemaBytes, common.DefaultFilePermission); err != nil {
+			logrus.Fatalf("failed to write the schema of the kind %s to a file at path %s . Error: %q", kind, schemaPath, err)
+		}
+	}
+	logrus.Infof("Schemas of %d kinds written to %s", len(kinds), outputPath)
+}
+
+// getFileSchemaKind returns the kind of schema to be used for validating the file
+func getFileSchemaKind(fileBytes []byte) string {
+	preamble := struct {
+		types.TypeMeta `yaml:",inline"`
+		Move2Kube      interface{} `yaml:"move2kube"`
+	}{}
+	if err := yaml.Unmarshal(fileBytes, &preamble); err != nil {
+		return ""
+	}
+	if preamble.Kind == "" && preamble.Move2Kube != nil {
+		return configSchemaKind
+	}
+	return preamble.Kind
+}
+
+func schemaValidateHandler(flags schemaValidateFlags, filePaths []string) {
+	schemas := getSchemas()
+	invalid := 0
+	for _, filePath := range filePaths {
+		fileBytes, err := os.ReadFile(filePath)
+		if err != nil {
+			logrus.Fatalf("failed to read the file at path %s . Error: %q", filePath, err)
+		}
+		kind := flags.kind
+		if kind == "" {
+			kind = getFileSchemaKind(fileBytes)
+		}
+		schema, ok := schemas[kind]
+		if !ok {
+			logrus.Errorf("unable to find a schema for the file at path %s with the kind '%s' . Use --kind to choose from %+v", filePath, kind, getSchemaKinds(schemas))
+			invalid++
+			continue
+		}
+		if flags.strict {
+			schema = jsonschema.Strict(schema)
+		}
+		violations, err := jsonschema.ValidateYaml(schema, fileBytes)
+		if err != nil {
+			logrus.Fatalf("failed to validate the file at path %s . Error: %q", filePath, err)
+		}
+		if len(violations) == 0 {
+			logrus.Infof("%s is a valid %s", filePath, kind)
+			continue
+		}
+		invalid++
+		for _, violation := range violations {
+			fmt.Printf("%s:%s\n", filePath, violation)
+		}
+	}
+	if invalid > 0 {
+		logrus.Fatalf("%d out of %d files failed the schema validation", invalid, len(filePaths))
+	}
+}
+
+// GetSchemaCommand returns a command to print the schemas of the move2kube files and validate files against them
+func GetSchemaCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	flags := schemaFlags{}
+	schemaCmd := &cobra.Command{
+		Use:   "schema [kinds...]",
+		Short: "Print the JSON schemas of the files used by move2kube.",
+		Long: `Print the JSON schemas of the files used by move2kube.
+	This includes the plan, the config files, the transformer yamls and the customization files.
+	By default, it prints the schemas of all the kinds.`,
+		Run: func(_ *cobra.Command, args []string) { schemaHandler(flags, args) },
+	}
+	schemaCmd.Flags().StringVarP(&flags.outputPath, "output", "o", "", "Path to a directory where the schemas should be written, one file per kind. By default the schemas are printed to the console.")
+
+	validateFlags := schemaValidateFlags{}
+	validateCmd := &cobra.Command{
+		Use:   "validate [files...]",
+		Short: "Validate files against the move2kube schemas.",
+		Long: `Validate files against the move2kube schemas.
+	The schema is chosen using the kind field of each file. Files with a move2kube root key are validated as config files.
+	Every violation is printed along with its line and column in the file.`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  func(_ *cobra.Command, args []string) { schemaValidateHandler(validateFlags, args) },
+	}
+	validateCmd.Flags().StringVarP(&validateFlags.kind, "kind", "k", "", "The kind of schema to validate all the files against. By default it is inferred from each file.")
+	validateCmd.Flags().BoolVar(&validateFlags.strict, "strict", false, "Report fields that are not part of the schema.")
+	schemaCmd.AddCommand(validateCmd)
+	return schemaCmd
+}
@@ -19,11 +19,14 @@ package cmd
 import (
 	"context"
 	"os"
-	"os/signal"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/telemetry"
 	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/types/plan"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -36,6 +39,18 @@ type transformFlags struct {
 	ignoreEnv bool
 	// disableLocalExecution disables execution of executables locally
 	disableLocalExecution bool
+	// offline disallows network access
+	offline bool
+	// buildCacheDir is the directory where built container images are cached across runs
+	buildCacheDir string
+	// environmentCacheDir is the directory where the prepared images of the environments are cached across runs
+	environmentCacheDir string
+	// containerRuntime is the container runtime used to spawn the containers
+	containerRuntime string
+	// imageTagStrategy is the strategy used to tag the new images
+	imageTagStrategy string
+	// featureGates enables or disables the experimental transformers and behaviours
+	featureGates []string
 	// planfile is contains the path to the plan file
 	planfile string
 	// outpath contains the path to the output folder
@@ -49,19 +64,84 @@ type transformFlags struct {
 	// CustomizationsPaths contains the path to the customizations directory
 	customizationsPath  string
 	transformerSelector string
+	// buildImages builds the container images after the transform
+	buildImages bool
+	// pushImages pushes the container images after building them
+	pushImages bool
+	// buildParallelism is the number of images to build at the same time
+	buildParallelism int
+	// prefetch pulls the container images needed by the transformers before the transform
+	prefetch bool
+	// pullParallelism is the number of images to pull at the same time
+	pullParallelism int
+	// forceUnlock removes the locks left behind by earlier runs that did not finish cleanly
+	forceUnlock bool
+	// dev reloads the custom transformers when their files change
+	dev bool
+	// watch runs the transform again when the customizations change
+	watch bool
+	// debugArtifacts is the directory to write the artifacts and path mappings of each transformer run to
+	debugArtifacts string
+	// debugArtifactsEnv writes the temporary files of the environments of the transformers along with the debug artifacts
+	debugArtifactsEnv bool
+	// step pauses the transform before each transformer runs, to inspect its input artifacts and skip it
+	step bool
+	// streamOutput logs the output of the commands run in containers as it is written
+	streamOutput bool
+	// telemetry selects whether the anonymous usage summary is written and submitted
+	telemetry string
+	// telemetryEndpoint is the endpoint the usage summary is submitted to
+	telemetryEndpoint string
+	// containerFilesOwner is the user and group that own the directories copied into the containers
+	containerFilesOwner string
+	// outputOwner is the user and group that own the output files when running as root
+	outputOwner string
+	// saveLogs saves the logs of the run in the output directory
+	saveLogs bool
+	// maxDuration is the maximum duration of the run
+	maxDuration time.Duration
+	// maxDisk is the maximum disk space the run can use
+	maxDisk string
+	// waves restricts the transform to the services in these migration waves of the plan
+	waves []string
+	// signManifestKey is the cosign key used to sign the manifest of the output
+	signManifestKey string
+}
+
+// withRunBudget returns a context that stops the commands and containers that are running as soon as the run exceeds its budget
+func withRunBudget(ctx context.Context, outputPath string) (context.Context, context.CancelFunc) {
+	if !common.HasRunBudget() {
+		return ctx, func() {}
+	}
+	return common.WithRunBudget(ctx, common.TempPath, outputPath)
 }
 
 func transformHandler(cmd *cobra.Command, flags transformFlags) {
-	ctx, cancel := context.WithCancel(cmd.Context())
+	ctx, cancel := handleInterrupts(cmd.Context(), lib.Destroy, common.ReleaseLocks)
 	logrus.AddHook(common.NewCleanupHook(cancel))
 	logrus.AddHook(common.NewCleanupHook(lib.Destroy))
-	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
-	go func() {
-		<-ctx.Done()
-		lib.Destroy()
-		stop()
-		common.Interrupt()
-	}()
+	logrus.AddHook(common.NewCleanupHook(common.ReleaseLocks))
+	defer cancel()
+	defer common.ReleaseLocks()
+	common.ForceUnlock = flags.forceUnlock
+	if flags.watch {
+		flags.dev = true
+	}
+	common.DevMode = flags.dev
+	common.StepMode = flags.step
+	common.StreamCommandOutput = flags.streamOutput
+	setOwners(flags.containerFilesOwner, flags.outputOwner)
+	if flags.debugArtifacts != "" {
+		setupDebugArtifacts(flags.debugArtifacts, flags.debugArtifactsEnv)
+	} else if flags.debugArtifactsEnv {
+		logrus.Fatalf("The flag --%s can only be used along with --%s", debugArtifactsEnvFlag, debugArtifactsFlag)
+	}
+	if flags.saveLogs {
+		if err := common.StartRunLog(); err != nil {
+			logrus.Errorf("Unable to save the logs of the run. Error: %q", err)
+			flags.saveLogs = false
+		}
+	}
 	defer lib.Destroy()
 
 	var err error
@@ -76,6 +156,7 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 	if flags.outpath, err = filepath.Abs(flags.outpath); err != nil {
 		logrus.Fatalf("Failed to make the output directory path %q absolute. Error: %q", flags.outpath, err)
 	}
+	startTelemetry(flags.telemetry, flags.telemetryEndpoint, "transform", filepath.Join(flags.configOut, common.UsageSummaryFile))
 	// Check if the default customization folder exists in the working directory.
 	// If not, skip the customization option
 	if !cmd.Flags().Changed(customizationsFlag) {
@@ -106,6 +187,32 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 	// Global settings
 	common.IgnoreEnvironment = flags.ignoreEnv
 	common.DisableLocalExecution = flags.disableLocalExecution
+	common.Offline = flags.offline
+	if err := common.SetFeatureGates(flags.featureGates); err != nil {
+		logrus.Fatalf("Failed to set the feature gates. Error: %q", err)
+	}
+	if err := common.SetRunBudget(flags.maxDuration, flags.maxDisk); err != nil {
+		logrus.Fatalf("Failed to set the budget of the run. Error: %q", err)
+	}
+	if flags.buildCacheDir != "" {
+		buildCacheDir, err := filepath.Abs(flags.buildCacheDir)
+		if err != nil {
+			logrus.Fatalf("Failed to make the build cache directory path %q absolute. Error: %q", flags.buildCacheDir, err)
+		}
+		common.BuildCacheDir = buildCacheDir
+	}
+	if flags.environmentCacheDir != "" {
+		environmentCacheDir, err := filepath.Abs(flags.environmentCacheDir)
+		if err != nil {
+			logrus.Fatalf("Failed to make the environment cache directory path %q absolute. Error: %q", flags.environmentCacheDir, err)
+		}
+		common.EnvironmentCacheDir = environmentCacheDir
+	}
+	flags.setconfigs = addContainerRuntimeConfig(flags.setconfigs, flags.containerRuntime)
+	flags.setconfigs = addImageTagStrategyConfig(flags.setconfigs, flags.imageTagStrategy)
+	if flags.offline && flags.prefetch {
+		logrus.Fatalf("The flags --%s and --%s cannot be used together. Prefetch the images using the prefetch command before going offline.", common.OfflineFlag, prefetchFlag)
+	}
 	// Global settings
 
 	// Parameter cleaning and curate plan
@@ -127,6 +234,9 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		// Global settings
 		checkSourcePath(flags.srcpath)
 		flags.outpath = filepath.Join(flags.outpath, flags.name)
+		if err := common.AcquireLock(flags.outpath); err != nil {
+			logrus.Fatalf("Unable to use the output directory %s . Error: %q", flags.outpath, err)
+		}
 		checkOutputPath(flags.outpath, flags.overwrite)
 		if flags.srcpath == flags.outpath || common.IsParent(flags.outpath, flags.srcpath) || common.IsParent(flags.srcpath, flags.outpath) {
 			logrus.Fatalf("The source path %s and output path %s overlap.", flags.srcpath, flags.outpath)
@@ -134,6 +244,11 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		if err := os.MkdirAll(flags.outpath, common.DefaultDirectoryPermission); err != nil {
 			logrus.Fatalf("Failed to create the output directory at path %s Error: %q", flags.outpath, err)
 		}
+		var cancelBudget context.CancelFunc
+		ctx, cancelBudget = withRunBudget(ctx, flags.outpath)
+		defer cancelBudget()
+		writeTransformCheckpoint(cmd, flags.name, flags.persistPasswords)
+		qaengine.SetRestartHandler(restartTransform)
 		startQA(flags.qaflags)
 		logrus.Debugf("Creating a new plan.")
 		p = lib.CreatePlan(ctx, flags.srcpath, flags.outpath, flags.customizationsPath, flags.transformerSelector, flags.name)
@@ -165,6 +280,9 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		checkSourcePath(p.Spec.SourceDir)
 		lib.CheckAndCopyCustomizations(p.Spec.CustomizationsDir)
 		flags.outpath = filepath.Join(flags.outpath, p.Name)
+		if err := common.AcquireLock(flags.outpath); err != nil {
+			logrus.Fatalf("Unable to use the output directory %s . Error: %q", flags.outpath, err)
+		}
 		checkOutputPath(flags.outpath, flags.overwrite)
 		if p.Spec.SourceDir == flags.outpath || common.IsParent(flags.outpath, p.Spec.SourceDir) || common.IsParent(p.Spec.SourceDir, flags.outpath) {
 			logrus.Fatalf("The source path %s and output path %s overlap.", p.Spec.SourceDir, flags.outpath)
@@ -172,10 +290,86 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		if err := os.MkdirAll(flags.outpath, common.DefaultDirectoryPermission); err != nil {
 			logrus.Fatalf("Failed to create the output directory at path %s Error: %q", flags.outpath, err)
 		}
+		var cancelBudget context.CancelFunc
+		ctx, cancelBudget = withRunBudget(ctx, flags.outpath)
+		defer cancelBudget()
+		writeTransformCheckpoint(cmd, p.Name, flags.persistPasswords)
+		qaengine.SetRestartHandler(restartTransform)
 		startQA(flags.qaflags)
 	}
+	if len(flags.waves) > 0 {
+		if err := p.SelectWaves(flags.waves); err != nil {
+			logrus.Fatalf("Unable to restrict the transform to the waves %+v . Error: %q", flags.waves, err)
+		}
+	}
+	if flags.saveLogs {
+		// the logs are also saved when the transform fails
+		logrus.RegisterExitHandler(func() { saveRunLog(flags.outpath) })
+	}
+	if flags.offline {
+		if violations := lib.GetOfflineViolations(p, flags.transformerSelector, flags.pushImages); len(violations) > 0 {
+			logrus.Fatalf("The transform needs to access the network, which is not allowed in offline mode:\n - %s\nUse the prefetch command to pull the images before going offline.", strings.Join(violations, "\n - "))
+		}
+	}
+	if flags.prefetch {
+		if err := lib.PrefetchImages(ctx, p, flags.transformerSelector, flags.pullParallelism); err != nil {
+			logrus.Errorf("Failed to prefetch the container images. They will be pulled during the transform. Error: %q", err)
+		}
+	}
 	lib.Transform(ctx, p, flags.outpath, flags.transformerSelector)
+	qaengine.WarnUnusedConfigKeys()
+	if err := lib.RecordOutputHashes(flags.outpath); err != nil {
+		logrus.Warnf("Unable to record the hashes of the generated files. The diff-output command will not be able to find the files edited after the transform. Error: %q", err)
+	}
+	if err := lib.WriteOutputManifest(flags.outpath); err != nil {
+		if flags.signManifestKey != "" {
+			logrus.Fatalf("Failed to write the manifest of the generated files, so it cannot be signed. Error: %q", err)
+		}
+		logrus.Warnf("Unable to write the manifest of the generated files. Error: %q", err)
+	} else if flags.signManifestKey != "" {
+		if err := lib.SignOutputManifest(ctx, flags.outpath, flags.signManifestKey); err != nil {
+			logrus.Fatalf("Failed to sign the manifest of the generated files. Error: %q", err)
+		}
+		logrus.Infof("Signed the manifest of the generated files. The signature can be found at %s", filepath.Join(flags.outpath, common.OutputManifestSignatureFile))
+	}
+	if !flags.watch {
+		removeTransformCheckpoint()
+	}
 	logrus.Infof("Transformed target artifacts can be found at [%s].", flags.outpath)
+	if flags.saveLogs {
+		saveRunLog(flags.outpath)
+	}
+	if err := common.ChownToOutputOwner(flags.outpath); err != nil {
+		logrus.Warnf("Unable to change the owner of the generated files. Error: %q", err)
+	}
+	if flags.buildImages || flags.pushImages {
+		if err := lib.BuildImages(ctx, flags.outpath, flags.pushImages, flags.buildParallelism); err != nil {
+			logrus.Fatalf("Failed to build the container images. Error: %q", err)
+		}
+	}
+	telemetry.Finish(ctx)
+	if flags.watch {
+		if p.Spec.CustomizationsDir == "" {
+			logrus.Fatalf("There is no customizations directory to watch for changes.")
+		}
+		if err := lib.WaitForCustomizationChanges(ctx, p.Spec.CustomizationsDir); err != nil {
+			removeTransformCheckpoint()
+			logrus.Infof("Stopped watching the customizations directory %s . Error: %q", p.Spec.CustomizationsDir, err)
+			return
+		}
+		logrus.Infof("The customizations changed. Running the transform again.")
+		restartTransform()
+	}
+}
+
+// saveRunLog saves the logs of the run in the output directory
+func saveRunLog(outputPath string) {
+	logPath, err := common.SaveRunLog(outputPath, common.RunID)
+	if err != nil {
+		logrus.Errorf("Unable to save the logs of the run. Error: %q", err)
+		return
+	}
+	logrus.Infof("The logs of the run can be found at %s", logPath)
 }
 
 // GetTransformCommand returns a command to do the transformation
@@ -189,10 +383,15 @@ func GetTransformCommand() *cobra.Command {
 
 	flags := transformFlags{}
 	transformCmd := &cobra.Command{
-		Use:        "transform",
-		Short:      "Transform using move2kube plan",
-		Long:       "Transform artifacts using move2kube plan",
-		Run:        func(cmd *cobra.Command, _ []string) { transformHandler(cmd, flags) },
+		Use:   "transform",
+		Short: "Transform using move2kube plan",
+		Long:  "Transform artifacts using move2kube plan",
+		Run: func(cmd *cobra.Command, _ []string) {
+			if flags.resume {
+				resumeTransformFlags(cmd)
+			}
+			transformHandler(cmd, flags)
+		},
 		SuggestFor: []string{"translate"},
 	}
 
@@ -211,10 +410,39 @@ func GetTransformCommand() *cobra.Command {
 	transformCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory where customizations are stored. By default we look for "+common.DefaultCustomizationDir)
 	transformCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
 	transformCmd.Flags().BoolVar(&flags.qaskip, qaSkipFlag, false, "Enable/disable the default answers to questions posed in QA Cli sub-system. If disabled, you will have to answer the questions posed by QA during interaction.")
+	transformCmd.Flags().BoolVar(&flags.resume, resumeFlag, false, "Resume a previous interrupted transform using the answers saved in the config and cache files, and the flags saved in "+common.TransformCheckpointFile+".")
+	transformCmd.Flags().BoolVar(&flags.forceUnlock, forceUnlockFlag, false, "Remove the locks on the output directory and the config and cache files left behind by an earlier run that did not finish cleanly.")
+
+	transformCmd.Flags().BoolVar(&flags.buildImages, buildImagesFlag, false, "Build the container images of the generated Dockerfiles after the transform.")
+	transformCmd.Flags().BoolVar(&flags.pushImages, pushImagesFlag, false, "Build the container images and push them to the selected registry. Implies --"+buildImagesFlag+".")
+	transformCmd.Flags().IntVar(&flags.buildParallelism, buildParallelismFlag, 2, "Number of container images to build at the same time.")
+	transformCmd.Flags().BoolVar(&flags.prefetch, prefetchFlag, false, "Pull the container images needed by the transformers in parallel before the transform starts.")
+	transformCmd.Flags().IntVar(&flags.pullParallelism, pullParallelismFlag, 4, "Number of container images to pull at the same time when prefetching.")
+	transformCmd.Flags().DurationVar(&flags.maxDuration, maxDurationFlag, 0, "Maximum duration of the run, for example 30m. When it is exceeded, the running commands and containers are stopped, no more transformers are run, and the partial output is written along with a "+lib.RunReportFile+" that says at which transformer the budget was exceeded. The checkpoint is kept, so that the transform can be resumed.")
+	transformCmd.Flags().StringVar(&flags.maxDisk, maxDiskFlag, "", "Maximum disk space used by the temporary and output directories of the run, for example 10Gi. Handled the same way as --"+maxDurationFlag+".")
+	transformCmd.Flags().BoolVar(&flags.saveLogs, saveLogsFlag, false, "Save the logs of the run to the "+common.RunLogsDir+" directory in the output directory, in a file named after the correlation ID of the run.")
+	transformCmd.Flags().StringVar(&flags.signManifestKey, signManifestKeyFlag, "", "Sign the "+common.OutputManifestFile+" manifest of the generated files using cosign and this key, like a key file or a KMS URI. The signature is written to "+common.OutputManifestSignatureFile+".")
+	transformCmd.Flags().StringSliceVar(&flags.waves, waveFlag, []string{}, "Only transform the services in these migration waves of the plan. The waves are set in the waves field of the plan.")
+	transformCmd.Flags().BoolVar(&flags.dev, devFlag, false, "Dev mode for transformer authors. Reload the custom transformers when their yaml, templates or scripts change in the customizations directory, before they process the next artifacts.")
+	transformCmd.Flags().StringVar(&flags.debugArtifacts, debugArtifactsFlag, "", "Directory to write the artifacts consumed and produced and the path mappings created by each transformer run to, in a sub directory for each iteration. For debugging custom transformers.")
+	transformCmd.Flags().BoolVar(&flags.debugArtifactsEnv, debugArtifactsEnvFlag, false, "Also copy the temporary files of the environment of each transformer run to the --"+debugArtifactsFlag+" directory.")
+	transformCmd.Flags().BoolVar(&flags.step, stepFlag, false, "Pause before each transformer runs, to inspect the artifacts it will consume, and run or skip it. For debugging custom transformers.")
+	transformCmd.Flags().BoolVar(&flags.streamOutput, streamOutputFlag, false, "Show the output of the commands run in the containers of the transformers, like buildpack and gradle builds, as it is written.")
+	transformCmd.Flags().BoolVar(&flags.watch, watchFlag, false, "Run the transform again, using the same answers, whenever the customizations change. Implies --"+devFlag+".")
 
 	// Advanced options
 	transformCmd.Flags().BoolVar(&flags.ignoreEnv, ignoreEnvFlag, false, "Ignore data from local machine.")
 	transformCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
+	transformCmd.Flags().BoolVar(&flags.offline, common.OfflineFlag, false, "Do not access the network. Fails if any of the container images needed by the transformers are not available locally. Use the prefetch command to pull them beforehand.")
+	transformCmd.Flags().StringSliceVar(&flags.featureGates, common.FeatureGatesFlag, []string{}, "Enable or disable experimental transformers and behaviours, for example --"+common.FeatureGatesFlag+"=NewComposeEngine=true . The feature gates are:\n"+common.GetFeatureGatesUsage())
+	transformCmd.Flags().StringVar(&flags.containerRuntime, common.ContainerRuntimeFlag, "", "Container runtime used to spawn the containers of the transformers: auto, docker, podman, kubernetes or none. none disables the transformers that rely on containers.")
+	transformCmd.Flags().StringVar(&flags.imageTagStrategy, imageTagStrategyFlag, "", "Strategy used to tag the new images: latest, git-sha, semver-from-tag or date. The tag is taken from the git repo of the source directory.")
+	transformCmd.Flags().StringVar(&flags.containerFilesOwner, containerFilesOwnerFlag, "", "User and group, like 1001:0, that own the directories copied into the containers of the transformers, for images that do not run as root. By default they keep the owner of the files, or are owned by root for rootless docker and podman.")
+	transformCmd.Flags().StringVar(&flags.outputOwner, outputOwnerFlag, "", "User and group, like 1000:1000, that own the generated files when running as root. Defaults to the user that ran move2kube using sudo.")
+	transformCmd.Flags().StringVar(&flags.telemetry, telemetryFlag, telemetry.Off, "Write an anonymous summary of the run, with the transformers used, the platforms detected and the number of errors, to "+common.UsageSummaryFile+" (next to the config file) using local, and also submit it using submit. The summary does not have the names of the services or any paths.")
+	transformCmd.Flags().StringVar(&flags.telemetryEndpoint, telemetryEndpointFlag, "", "Endpoint that the usage summary is submitted to as JSON when --"+telemetryFlag+"=submit.")
+	transformCmd.Flags().StringVar(&flags.buildCacheDir, common.BuildCacheDirFlag, "", "Directory where the container images built for the transformers are cached, so that later runs (for example on CI) do not build identical images again.")
+	transformCmd.Flags().StringVar(&flags.environmentCacheDir, common.EnvironmentCacheDirFlag, "", "Directory where the images of the transformers with the source copied into them are saved and loaded from, so that later runs (for example on CI) with the same source do not copy it into the images again.")
 
 	// Hidden options
 	transformCmd.Flags().BoolVar(&flags.qadisablecli, qadisablecliFlag, false, "Enable/disable the QA Cli sub-system. Without this system, you will have to use the REST API to interact.")
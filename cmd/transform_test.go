@@ -0,0 +1,71 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/assets"
+	"github.com/konveyor/move2kube/common"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTransformIntoMissingOutputParent(t *testing.T) {
+	assetsFilePermissions := map[string]int{}
+	if err := yaml.Unmarshal([]byte(assets.AssetFilePermissions), &assetsFilePermissions); err != nil {
+		t.Fatalf("failed to read the permissions of the assets. Error: %q", err)
+	}
+	common.TempPath = t.TempDir()
+	assetsPath, tempPath, err := common.CreateAssetsData(assets.AssetsDir, assetsFilePermissions)
+	if err != nil {
+		t.Fatalf("failed to create the assets directory. Error: %q", err)
+	}
+	common.TempPath, common.AssetsPath = tempPath, assetsPath
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "src")
+	if err := os.MkdirAll(srcPath, common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the source directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcPath, "Dockerfile"), []byte("FROM alpine\nEXPOSE 8080\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	// the config and the QA cache are written to the working directory
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get the working directory. Error: %q", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to change the working directory to %s . Error: %q", dir, err)
+	}
+	t.Cleanup(func() { os.Chdir(workingDir) })
+	outPath := filepath.Join(dir, "out", "nested")
+	rootCmd := GetRootCmd()
+	rootCmd.SetArgs([]string{"transform", "--source", srcPath, "--output", outPath, "--qa-skip"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("failed to transform into %s . Error: %q", outPath, err)
+	}
+	projectPath := filepath.Join(outPath, "myproject")
+	if _, err := os.Stat(filepath.Join(projectPath, "deploy")); err != nil {
+		t.Fatalf("the output was not written to %s . Error: %q", projectPath, err)
+	}
+	if _, err := os.Stat(projectPath + ".m2klock"); !os.IsNotExist(err) {
+		t.Fatalf("the lock of the output directory was not released. Error: %q", err)
+	}
+}
@@ -17,17 +17,29 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/telemetry"
+	"github.com/konveyor/move2kube/environment/container"
 	"github.com/konveyor/move2kube/qaengine"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// interruptedExitCode is the exit code when the process is interrupted a second time, like the shells do for SIGINT
+	interruptedExitCode = 130
 )
 
 // checkSourcePath checks if the source path is an existing directory.
@@ -80,39 +92,39 @@ func checkOutputPath(outpath string, overwrite bool) {
 func startQA(flags qaflags) {
 	qaengine.StartEngine(flags.qaskip, flags.qaport, flags.qadisablecli)
 	if flags.configOut == "" {
-		qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+		qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords, flags.resume)
 	} else {
 		if flags.configOut == "." {
-			qaengine.SetupConfigFile(common.ConfigFile, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+			qaengine.SetupConfigFile(common.ConfigFile, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords, flags.resume)
 		} else if fi, err := os.Stat(flags.configOut); err == nil {
 			if fi.IsDir() {
-				qaengine.SetupConfigFile(filepath.Join(flags.configOut, common.ConfigFile), flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+				qaengine.SetupConfigFile(filepath.Join(flags.configOut, common.ConfigFile), flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords, flags.resume)
 			} else {
-				qaengine.SetupConfigFile(flags.configOut, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+				qaengine.SetupConfigFile(flags.configOut, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords, flags.resume)
 			}
 		} else if strings.Contains(filepath.Base(flags.configOut), ".") {
 			os.MkdirAll(filepath.Dir(flags.configOut), common.DefaultDirectoryPermission)
-			qaengine.SetupConfigFile(flags.configOut, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+			qaengine.SetupConfigFile(flags.configOut, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords, flags.resume)
 		} else {
 			os.MkdirAll(flags.configOut, common.DefaultDirectoryPermission)
-			qaengine.SetupConfigFile(filepath.Join(flags.configOut, common.ConfigFile), flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+			qaengine.SetupConfigFile(filepath.Join(flags.configOut, common.ConfigFile), flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords, flags.resume)
 		}
 	}
 	if flags.qaCacheOut != "" {
 		if flags.qaCacheOut == "." {
-			qaengine.SetupWriteCacheFile(common.QACacheFile, flags.persistPasswords)
+			qaengine.SetupWriteCacheFile(common.QACacheFile, flags.persistPasswords, flags.resume)
 		} else if fi, err := os.Stat(flags.qaCacheOut); err == nil {
 			if fi.IsDir() {
-				qaengine.SetupWriteCacheFile(filepath.Join(flags.qaCacheOut, common.QACacheFile), flags.persistPasswords)
+				qaengine.SetupWriteCacheFile(filepath.Join(flags.qaCacheOut, common.QACacheFile), flags.persistPasswords, flags.resume)
 			} else {
-				qaengine.SetupWriteCacheFile(flags.qaCacheOut, flags.persistPasswords)
+				qaengine.SetupWriteCacheFile(flags.qaCacheOut, flags.persistPasswords, flags.resume)
 			}
 		} else if strings.Contains(filepath.Base(flags.qaCacheOut), ".") {
 			os.MkdirAll(filepath.Dir(flags.qaCacheOut), common.DefaultDirectoryPermission)
-			qaengine.SetupWriteCacheFile(flags.qaCacheOut, flags.persistPasswords)
+			qaengine.SetupWriteCacheFile(flags.qaCacheOut, flags.persistPasswords, flags.resume)
 		} else {
 			os.MkdirAll(flags.qaCacheOut, common.DefaultDirectoryPermission)
-			qaengine.SetupWriteCacheFile(filepath.Join(flags.qaCacheOut, common.QACacheFile), flags.persistPasswords)
+			qaengine.SetupWriteCacheFile(filepath.Join(flags.qaCacheOut, common.QACacheFile), flags.persistPasswords, flags.resume)
 		}
 	}
 	if err := qaengine.WriteStoresToDisk(); err != nil {
@@ -139,3 +151,134 @@ func startPlanProgressServer(port int) {
 	}()
 	logrus.Trace("startPlanProgressServer end")
 }
+
+// setupDebugArtifacts sets the directory that the debug artifacts are written to, after removing the ones written by an earlier run
+func setupDebugArtifacts(debugArtifactsDir string, withEnvironment bool) {
+	debugArtifactsDir, err := filepath.Abs(debugArtifactsDir)
+	if err != nil {
+		logrus.Fatalf("Failed to make the debug artifacts directory path %q absolute. Error: %q", debugArtifactsDir, err)
+	}
+	oldSnapshots, err := filepath.Glob(filepath.Join(debugArtifactsDir, "iteration-*"))
+	if err != nil {
+		logrus.Fatalf("Failed to look for the debug artifacts of an earlier run in %s . Error: %q", debugArtifactsDir, err)
+	}
+	for _, oldSnapshot := range oldSnapshots {
+		if err := os.RemoveAll(oldSnapshot); err != nil {
+			logrus.Warnf("Failed to remove the debug artifacts of an earlier run at path %s . Error: %q", oldSnapshot, err)
+		}
+	}
+	if err := os.MkdirAll(debugArtifactsDir, common.DefaultDirectoryPermission); err != nil {
+		logrus.Fatalf("Failed to create the debug artifacts directory at path %s . Error: %q", debugArtifactsDir, err)
+	}
+	common.DebugArtifactsDir = debugArtifactsDir
+	common.DebugArtifactsEnvironment = withEnvironment
+	logrus.Infof("The artifacts and path mappings of each transformer run will be written to %s", debugArtifactsDir)
+}
+
+// setOwners sets the owners of the directories copied into the containers and of the output files given in the flags
+func setOwners(containerFilesOwner, outputOwner string) {
+	if containerFilesOwner != "" {
+		if _, _, err := common.ParseOwner(containerFilesOwner); err != nil {
+			logrus.Fatalf("The owner given in the --%s flag is not valid. Error: %q", containerFilesOwnerFlag, err)
+		}
+		common.ContainerFilesOwner = containerFilesOwner
+	}
+	if outputOwner != "" {
+		if _, _, err := common.ParseOwner(outputOwner); err != nil {
+			logrus.Fatalf("The owner given in the --%s flag is not valid. Error: %q", outputOwnerFlag, err)
+		}
+		common.OutputOwner = outputOwner
+	}
+}
+
+// startTelemetry starts recording the anonymous usage summary of the command, if it is enabled in the flags
+func startTelemetry(mode, endpoint, command, summaryPath string) {
+	if endpoint != "" {
+		telemetry.RegisterReporter("http", &telemetry.HTTPReporter{Endpoint: endpoint})
+	}
+	if err := telemetry.Start(mode, command, summaryPath); err != nil {
+		logrus.Fatalf("The telemetry mode given in the --%s flag is not valid. Error: %q", telemetryFlag, err)
+	}
+}
+
+// addContainerRuntimeConfig adds the config that selects the container runtime given in the flag to the key-value configs
+func addContainerRuntimeConfig(setconfigs []string, containerRuntime string) []string {
+	if containerRuntime == "" {
+		return setconfigs
+	}
+	if !common.IsPresent(container.ContainerRuntimes, containerRuntime) {
+		logrus.Fatalf("The container runtime %s given in the --%s flag is not supported. Supported container runtimes are %+v", containerRuntime, common.ContainerRuntimeFlag, container.ContainerRuntimes)
+	}
+	return append(setconfigs, common.ConfigContainerEngineRuntimeKey+`="`+containerRuntime+`"`)
+}
+
+// addImageTagStrategyConfig adds the config that selects the strategy used to tag the new images given in the flag to the key-value configs
+func addImageTagStrategyConfig(setconfigs []string, imageTagStrategy string) []string {
+	if imageTagStrategy == "" {
+		return setconfigs
+	}
+	if !common.IsPresent(common.ImageTagStrategies, imageTagStrategy) {
+		logrus.Fatalf("The image tag strategy %s given in the --%s flag is not supported. Supported strategies are %+v", imageTagStrategy, imageTagStrategyFlag, common.ImageTagStrategies)
+	}
+	return append(setconfigs, common.ConfigImageTagStrategyKey+`="`+imageTagStrategy+`"`)
+}
+
+// addDetectionLimitsConfig adds the detection limits given in the flags to the configs, so that they take precedence over the config files
+func addDetectionLimitsConfig(cmd *cobra.Command, setconfigs []string, maxDepth, maxFilesPerDir int, maxFileSize string) []string {
+	if cmd.Flags().Changed(maxDetectDepthFlag) {
+		setconfigs = append(setconfigs, common.ConfigPlanningMaxDepthKey+`="`+cast.ToString(maxDepth)+`"`)
+	}
+	if cmd.Flags().Changed(maxDetectFilesPerDirFlag) {
+		setconfigs = append(setconfigs, common.ConfigPlanningMaxFilesPerDirKey+`="`+cast.ToString(maxFilesPerDir)+`"`)
+	}
+	if cmd.Flags().Changed(maxDetectFileSizeFlag) {
+		setconfigs = append(setconfigs, common.ConfigPlanningMaxFileSizeKey+`="`+maxFileSize+`"`)
+	}
+	return setconfigs
+}
+
+// setDetectionLimits sets the limits on the directories and files that the transformers look at while planning
+func setDetectionLimits() {
+	maxDepth := qaengine.FetchStringAnswer(common.ConfigPlanningMaxDepthKey, "Enter the maximum depth of the directories to look at while planning :", []string{"0 looks at all the directories"}, "0")
+	maxFilesPerDir := qaengine.FetchStringAnswer(common.ConfigPlanningMaxFilesPerDirKey, "Enter the maximum number of files in the directories to look at while planning :", []string{"Bigger directories are skipped. 0 looks at all the directories"}, cast.ToString(common.DefaultMaxFilesPerDir))
+	maxFileSize := qaengine.FetchStringAnswer(common.ConfigPlanningMaxFileSizeKey, "Enter the maximum size of the files to look at while planning :", []string{"It is a quantity like 10Mi. Bigger files are skipped. 0 looks at all the files"}, "0")
+	limits := common.DetectionLimits{}
+	var err error
+	if limits.MaxDepth, err = strconv.Atoi(maxDepth); err != nil || limits.MaxDepth < 0 {
+		logrus.Fatalf("The maximum depth %s of the directories to look at while planning is not a valid number. Error: %q", maxDepth, err)
+	}
+	if limits.MaxFilesPerDir, err = strconv.Atoi(maxFilesPerDir); err != nil || limits.MaxFilesPerDir < 0 {
+		logrus.Fatalf("The maximum number of files %s in the directories to look at while planning is not a valid number. Error: %q", maxFilesPerDir, err)
+	}
+	quantity, err := resource.ParseQuantity(maxFileSize)
+	if err != nil || quantity.Sign() < 0 {
+		logrus.Fatalf("The maximum size %s of the files to look at while planning is not a valid quantity. Error: %q", maxFileSize, err)
+	}
+	limits.MaxFileSize = quantity.Value()
+	common.PlanDetectionLimits = limits
+}
+
+// handleInterrupts returns a context that is cancelled on the first interrupt, so that the run stops its containers and cleans up before exiting.
+// On the second interrupt, the cleanup functions are called and the process exits right away.
+func handleInterrupts(ctx context.Context, cleanups ...func()) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			signal.Stop(signals)
+			return
+		}
+		logrus.Warnf("Interrupted. Stopping the running containers and cleaning up. Interrupt again to exit right away.")
+		cancel()
+		<-signals
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+		// the exit handlers remove the temp directory
+		logrus.Exit(interruptedExitCode)
+	}()
+	return ctx, cancel
+}
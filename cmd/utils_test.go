@@ -0,0 +1,108 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/spf13/cobra"
+)
+
+func TestAddContainerRuntimeConfig(t *testing.T) {
+	setconfigs := []string{`move2kube.minreplicas="2"`}
+	if diff := cmp.Diff(setconfigs, addContainerRuntimeConfig(setconfigs, "")); diff != "" {
+		t.Fatalf("expected the configs to not change when the flag is not set. Difference:\n%s", diff)
+	}
+	expected := []string{`move2kube.minreplicas="2"`, `move2kube.containerengine.runtime="podman"`}
+	if diff := cmp.Diff(expected, addContainerRuntimeConfig(setconfigs, "podman")); diff != "" {
+		t.Fatalf("wrong configs. Difference:\n%s", diff)
+	}
+}
+
+func TestAddDetectionLimitsConfig(t *testing.T) {
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Int(maxDetectDepthFlag, 0, "")
+		cmd.Flags().Int(maxDetectFilesPerDirFlag, common.DefaultMaxFilesPerDir, "")
+		cmd.Flags().String(maxDetectFileSizeFlag, "", "")
+		return cmd
+	}
+	t.Run("the limits are not added when the flags are not set", func(t *testing.T) {
+		setconfigs := addDetectionLimitsConfig(newCmd(), nil, 0, common.DefaultMaxFilesPerDir, "")
+		if len(setconfigs) != 0 {
+			t.Fatalf("expected no configs. Actual: %+v", setconfigs)
+		}
+	})
+	t.Run("the limits given in the flags are added", func(t *testing.T) {
+		cmd := newCmd()
+		for flag, value := range map[string]string{maxDetectDepthFlag: "3", maxDetectFilesPerDirFlag: "500", maxDetectFileSizeFlag: "10Mi"} {
+			if err := cmd.Flags().Set(flag, value); err != nil {
+				t.Fatalf("failed to set the flag %s . Error: %q", flag, err)
+			}
+		}
+		expected := []string{
+			`move2kube.minreplicas="2"`,
+			`move2kube.planning.maxdepth="3"`,
+			`move2kube.planning.maxfilesperdir="500"`,
+			`move2kube.planning.maxfilesize="10Mi"`,
+		}
+		setconfigs := addDetectionLimitsConfig(cmd, []string{`move2kube.minreplicas="2"`}, 3, 500, "10Mi")
+		if diff := cmp.Diff(expected, setconfigs); diff != "" {
+			t.Fatalf("wrong configs. Difference:\n%s", diff)
+		}
+	})
+}
+
+func TestSetDetectionLimits(t *testing.T) {
+	oldLimits := common.PlanDetectionLimits
+	defer func() { common.PlanDetectionLimits = oldLimits }()
+	common.TempPath = t.TempDir()
+	// the answers are also written to the config and the QA cache of the earlier tests, which are in the working directory
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get the working directory. Error: %q", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to change the working directory. Error: %q", err)
+	}
+	t.Cleanup(func() { os.Chdir(workingDir) })
+	qaengine.StartEngine(true, 0, true)
+	t.Run("the defaults are used when nothing is given", func(t *testing.T) {
+		qaengine.SetupConfigFile("", nil, nil, nil, false, false)
+		setDetectionLimits()
+		expected := common.DetectionLimits{MaxFilesPerDir: common.DefaultMaxFilesPerDir}
+		if diff := cmp.Diff(expected, common.PlanDetectionLimits); diff != "" {
+			t.Fatalf("wrong limits. Difference:\n%s", diff)
+		}
+	})
+	t.Run("the limits are read from the configs", func(t *testing.T) {
+		qaengine.SetupConfigFile("", []string{
+			`move2kube.planning.maxdepth="3"`,
+			`move2kube.planning.maxfilesperdir="0"`,
+			`move2kube.planning.maxfilesize="1Ki"`,
+		}, nil, nil, false, false)
+		setDetectionLimits()
+		expected := common.DetectionLimits{MaxDepth: 3, MaxFileSize: 1024}
+		if diff := cmp.Diff(expected, common.PlanDetectionLimits); diff != "" {
+			t.Fatalf("wrong limits. Difference:\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultVerifyDir is the directory in the customizations directory with the expected files and the assertions about the output
+	defaultVerifyDir = "verify"
+)
+
+type verifyOutputFlags struct {
+	// planfile is contains the path to the plan file
+	planfile string
+	// srcpath contains path to the source folder
+	srcpath string
+	// verifyPath contains the path to the directory with the expected files and the assertions
+	verifyPath string
+	// configs contains the paths of the config files with the answers used for the transform
+	configs []string
+	// setconfigs contains config key-value pairs
+	setconfigs []string
+	// preSets contains the preset configs
+	preSets             []string
+	transformerSelector string
+	// update overwrites the expected files with the generated files
+	update bool
+}
+
+func verifyOutputHandler(cmd *cobra.Command, flags verifyOutputFlags) {
+	ctx := cmd.Context()
+	defer lib.Destroy()
+	var err error
+	if flags.srcpath != "" {
+		if flags.srcpath, err = filepath.Abs(flags.srcpath); err != nil {
+			logrus.Fatalf("Failed to make the source directory path %q absolute. Error: %q", flags.srcpath, err)
+		}
+	}
+	p, err := plan.ReadPlan(flags.planfile, flags.srcpath)
+	if err != nil {
+		logrus.Fatalf("Unable to read the plan at path %s Error: %q", flags.planfile, err)
+	}
+	if flags.verifyPath == "" {
+		if p.Spec.CustomizationsDir == "" {
+			logrus.Fatalf("The plan does not have a customizations directory. Use --%s to specify the directory with the expected files and the assertions.", verifyDirFlag)
+		}
+		flags.verifyPath = filepath.Join(p.Spec.CustomizationsDir, defaultVerifyDir)
+	}
+	if flags.verifyPath, err = filepath.Abs(flags.verifyPath); err != nil {
+		logrus.Fatalf("Failed to make the verify directory path %q absolute. Error: %q", flags.verifyPath, err)
+	}
+	if fi, err := os.Stat(flags.verifyPath); err != nil || !fi.IsDir() {
+		logrus.Fatalf("The verify directory %s does not exist. Error: %v", flags.verifyPath, err)
+	}
+	if !cmd.Flags().Changed(configFlag) {
+		if _, err := os.Stat(common.ConfigFile); err == nil {
+			flags.configs = []string{common.ConfigFile}
+		}
+	}
+	checkSourcePath(p.Spec.SourceDir)
+	lib.CheckAndCopyCustomizations(p.Spec.CustomizationsDir)
+	generatedPath, err := os.MkdirTemp(common.TempPath, "verify-output-")
+	if err != nil {
+		logrus.Fatalf("Failed to create a temporary directory for the output. Error: %q", err)
+	}
+	defer os.RemoveAll(generatedPath)
+	// the answers are taken from the configs and the defaults, so that the output only changes when move2kube or the setup changes
+	startQA(qaflags{
+		qaskip:       true,
+		qadisablecli: true,
+		configOut:    generatedPath,
+		qaCacheOut:   generatedPath,
+		configs:      flags.configs,
+		setconfigs:   flags.setconfigs,
+		preSets:      flags.preSets,
+	})
+	transformedPath := filepath.Join(generatedPath, p.Name)
+	lib.Transform(ctx, p, transformedPath, flags.transformerSelector)
+	if flags.update {
+		updated, err := lib.UpdateExpectedFiles(transformedPath, flags.verifyPath)
+		if err != nil {
+			logrus.Fatalf("Failed to update the expected files in %s . Error: %q", flags.verifyPath, err)
+		}
+		logrus.Infof("Updated %d expected files in %s", updated, filepath.Join(flags.verifyPath, lib.VerifyExpectedDir))
+	}
+	results, err := lib.VerifyOutput(transformedPath, flags.verifyPath)
+	if err != nil {
+		logrus.Fatalf("Failed to verify the output using %s . Error: %q", flags.verifyPath, err)
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Passed {
+			fmt.Printf("PASS %s\n", result.Name)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s\n", result.Name)
+		fmt.Printf("     %s\n", result.Reason)
+	}
+	if len(results) == 0 {
+		logrus.Warnf("The verify directory %s does not have any expected files or %s files.", flags.verifyPath, lib.OutputAssertionsKind)
+		return
+	}
+	if failed == 0 {
+		logrus.Infof("All %d checks passed.", len(results))
+		return
+	}
+	logrus.Errorf("%d out of %d checks failed.", failed, len(results))
+	lib.Destroy()
+	os.Exit(1)
+}
+
+// GetVerifyOutputCommand returns a command to check the output of the transform against the expected files and assertions kept in the customizations directory
+func GetVerifyOutputCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	flags := verifyOutputFlags{}
+	verifyOutputCmd := &cobra.Command{
+		Use:   "verify-output",
+		Short: "Run the transform and check the output against the expected files and assertions.",
+		Long: `Run the transform using the plan and answers into a temporary directory, and check it against the expected files and assertions in the verify directory of the customizations directory.
+	Every file in the ` + lib.VerifyExpectedDir + ` directory has to be generated exactly the same, at the same path relative to the output directory.
+	The yaml files of kind ` + string(lib.OutputAssertionsKind) + ` have assertions about the generated files and kubernetes resources, like the host of the Ingress of a service.
+	It exits with status 1 if any check fails, so it can be used to test the migration setup when move2kube is upgraded.`,
+		Args: cobra.NoArgs,
+		Run:  func(cmd *cobra.Command, _ []string) { verifyOutputHandler(cmd, flags) },
+	}
+	verifyOutputCmd.Flags().StringVarP(&flags.planfile, planFlag, "p", common.DefaultPlanFile, "Specify the plan file used for the transform.")
+	verifyOutputCmd.Flags().StringVarP(&flags.srcpath, sourceFlag, "s", "", "Specify the source directory. If not specified, it is taken from the plan.")
+	verifyOutputCmd.Flags().StringVar(&flags.verifyPath, verifyDirFlag, "", "Specify the directory with the expected files and the assertions. Defaults to the "+defaultVerifyDir+" directory of the customizations directory.")
+	verifyOutputCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify the config files with the answers used for the transform. By default we look for "+common.ConfigFile)
+	verifyOutputCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
+	verifyOutputCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
+	verifyOutputCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
+	verifyOutputCmd.Flags().BoolVar(&flags.update, "update", false, "Overwrite the expected files with the generated files before checking them, after an intended change of the output.")
+	return verifyOutputCmd
+}
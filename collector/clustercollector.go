@@ -32,6 +32,7 @@ import (
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	cgdiscovery "k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -88,6 +89,14 @@ func (c *ClusterCollector) Collect(inputPath string, outputPath string) error {
 	c.groupOrderPolicy(&clusterMd.Spec.APIKindVersionMap)
 	//c.VersionOrderPolicy(&clusterMd.APIKindVersionMap)
 
+	if clusterMd.Spec.CustomResourceDefinitions, err = c.getCustomResourceDefinitions(); err != nil {
+		logrus.Warnf("Failed to collect the custom resource definitions. Error: %q", err)
+	}
+
+	if clusterMd.Spec.NodeResources, err = c.getNodeResources(); err != nil {
+		logrus.Warnf("Failed to collect the resources of the nodes. Error: %q", err)
+	}
+
 	outputPath = filepath.Join(outputPath, common.NormalizeForFilename(clusterMd.Name)+".yaml")
 	return common.WriteYaml(outputPath, clusterMd)
 }
@@ -157,6 +166,89 @@ func (c *ClusterCollector) getStorageClasses() ([]string, error) {
 	return storageClasses, nil
 }
 
+func (c *ClusterCollector) getCustomResourceDefinitions() ([]collecttypes.CustomResourceDefinitionMetadata, error) {
+	ccmd := c.getClusterCommand()
+	cmd := exec.Command(ccmd, "get", "crd", "-o", "yaml")
+	yamlOutput, err := cmd.CombinedOutput()
+	if err != nil {
+		errDesc := c.interpretError(string(yamlOutput))
+		if errDesc != "" {
+			logrus.Warnf("Error while running %s. %s", ccmd, errDesc)
+		} else {
+			logrus.Warnf("Error while fetching custom resource definitions using command [%s]", cmd)
+		}
+		return nil, err
+	}
+
+	fileContents := map[string]interface{}{}
+	if err := yaml.Unmarshal(yamlOutput, &fileContents); err != nil {
+		logrus.Errorf("Error in unmarshalling yaml: %s. Skipping.", err)
+		return nil, err
+	}
+
+	crdArray, _ := fileContents["items"].([]interface{})
+	crds := []collecttypes.CustomResourceDefinitionMetadata{}
+	for _, crdI := range crdArray {
+		mapCRD, ok := crdI.(map[string]interface{})
+		if !ok {
+			logrus.Warnf("Unknown type detected in cluster metadata [%T]", crdI)
+			continue
+		}
+		crd, err := k8sschema.ParseCRD(mapCRD)
+		if err != nil {
+			logrus.Warnf("Failed to parse the custom resource definition. Error: %q", err)
+			continue
+		}
+		crds = append(crds, crd)
+	}
+
+	return crds, nil
+}
+
+// getNodeResources returns the extended resources that can be allocated on the nodes, like GPUs
+func (c *ClusterCollector) getNodeResources() (map[string]int64, error) {
+	ccmd := c.getClusterCommand()
+	cmd := exec.Command(ccmd, "get", "nodes", "-o", "yaml")
+	yamlOutput, err := cmd.CombinedOutput()
+	if err != nil {
+		errDesc := c.interpretError(string(yamlOutput))
+		if errDesc != "" {
+			logrus.Warnf("Error while running %s. %s", ccmd, errDesc)
+		} else {
+			logrus.Warnf("Error while fetching the nodes using command [%s]", cmd)
+		}
+		return nil, err
+	}
+
+	fileContents := map[string]interface{}{}
+	if err := yaml.Unmarshal(yamlOutput, &fileContents); err != nil {
+		logrus.Errorf("Error in unmarshalling yaml: %s. Skipping.", err)
+		return nil, err
+	}
+
+	nodeArray, _ := fileContents["items"].([]interface{})
+	nodeResources := map[string]int64{}
+	for _, nodeI := range nodeArray {
+		mapNode, _ := nodeI.(map[string]interface{})
+		status, _ := mapNode["status"].(map[string]interface{})
+		allocatable, _ := status["allocatable"].(map[string]interface{})
+		for name, valueI := range allocatable {
+			// only the extended resources have a domain prefix
+			if !strings.Contains(name, "/") {
+				continue
+			}
+			quantity, err := resource.ParseQuantity(fmt.Sprint(valueI))
+			if err != nil {
+				logrus.Warnf("Failed to parse the %s resource %v of the node. Error: %q", name, valueI, err)
+				continue
+			}
+			nodeResources[name] += quantity.Value()
+		}
+	}
+
+	return nodeResources, nil
+}
+
 func (c *ClusterCollector) interpretError(cmdOutput string) string {
 	errorTerms := []string{"Unauthorized", "Username"}
 
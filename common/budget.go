@@ -0,0 +1,204 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// DurationBudget is the budget on the duration of the run
+	DurationBudget = "duration"
+	// DiskBudget is the budget on the disk space used by the run
+	DiskBudget = "disk"
+)
+
+var (
+	// MaxRunDuration is the maximum duration of the run. The duration is not limited if it is 0.
+	MaxRunDuration time.Duration
+	// MaxDiskUsage is the maximum number of bytes in the temporary and output directories. The disk usage is not limited if it is 0.
+	MaxDiskUsage int64
+	// runStartTime is the time at which the budgets were set
+	runStartTime = time.Now()
+	// diskUsageSampleInterval is how often the disk usage is measured while the run has a disk budget
+	diskUsageSampleInterval = 5 * time.Second
+	// sampledDiskUsage is the disk usage measured last
+	sampledDiskUsage      int64
+	sampledDiskUsageMutex sync.Mutex
+)
+
+// BudgetExceededError is returned when the run has used more than its budget
+type BudgetExceededError struct {
+	Budget      string
+	Limit       string
+	Used        string
+	Transformer string
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("the %s budget of %s was exceeded (used %s) at the transformer %s", e.Budget, e.Limit, e.Used, e.Transformer)
+}
+
+// SetRunBudget sets the budgets of the run and starts measuring the duration of the run.
+// The maximum disk usage is a quantity like 500Mi or 10Gi.
+func SetRunBudget(maxDuration time.Duration, maxDisk string) error {
+	MaxRunDuration = maxDuration
+	MaxDiskUsage = 0
+	if maxDisk != "" {
+		quantity, err := resource.ParseQuantity(maxDisk)
+		if err != nil {
+			return fmt.Errorf("the maximum disk usage %s is not a valid quantity. Error: %q", maxDisk, err)
+		}
+		MaxDiskUsage = quantity.Value()
+	}
+	runStartTime = time.Now()
+	sampledDiskUsageMutex.Lock()
+	defer sampledDiskUsageMutex.Unlock()
+	sampledDiskUsage = 0
+	return nil
+}
+
+// HasRunBudget returns true if the run has a budget on the duration or the disk usage
+func HasRunBudget() bool {
+	return MaxRunDuration > 0 || MaxDiskUsage > 0
+}
+
+// GetRunDuration returns the time since the budgets were set
+func GetRunDuration() time.Duration {
+	return time.Since(runStartTime)
+}
+
+// WithRunBudget returns a context that is cancelled when the run exceeds its budget, so that the running commands and containers are stopped.
+// Its deadline is the end of the duration budget. The disk usage is the size of all the files in the given directories,
+// which is measured every diskUsageSampleInterval until the context is done.
+func WithRunBudget(ctx context.Context, dirs ...string) (context.Context, context.CancelFunc) {
+	cancels := []context.CancelFunc{}
+	if MaxRunDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, MaxRunDuration-GetRunDuration())
+		cancels = append(cancels, cancel)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cancels = append(cancels, cancel)
+	if MaxDiskUsage > 0 {
+		sampleDiskUsage(dirs)
+		maxDiskUsage := MaxDiskUsage
+		ticker := time.NewTicker(diskUsageSampleInterval)
+		go func() {
+			defer ticker.Stop()
+			for {
+				if getSampledDiskUsage() > maxDiskUsage {
+					logrus.Debugf("stopping the run since the disk usage exceeded the budget of %s", formatBytes(maxDiskUsage))
+					cancel()
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					sampleDiskUsage(dirs)
+				}
+			}
+		}()
+	}
+	return ctx, func() {
+		for i := len(cancels) - 1; i >= 0; i-- {
+			cancels[i]()
+		}
+	}
+}
+
+// CheckRunBudget returns an error if the run has exceeded its budget before or while running the transformer.
+// The disk usage is the one measured last by the context returned by WithRunBudget.
+func CheckRunBudget(transformerName string) *BudgetExceededError {
+	if MaxRunDuration > 0 {
+		if elapsed := GetRunDuration(); elapsed >= MaxRunDuration {
+			return &BudgetExceededError{Budget: DurationBudget, Limit: MaxRunDuration.String(), Used: elapsed.Round(time.Second).String(), Transformer: transformerName}
+		}
+	}
+	if MaxDiskUsage > 0 {
+		if used := getSampledDiskUsage(); used > MaxDiskUsage {
+			return &BudgetExceededError{Budget: DiskBudget, Limit: formatBytes(MaxDiskUsage), Used: formatBytes(used), Transformer: transformerName}
+		}
+	}
+	return nil
+}
+
+// sampleDiskUsage measures the disk usage of the directories
+func sampleDiskUsage(dirs []string) {
+	used := int64(0)
+	for _, dir := range dirs {
+		used += getDiskUsage(dir)
+	}
+	sampledDiskUsageMutex.Lock()
+	defer sampledDiskUsageMutex.Unlock()
+	sampledDiskUsage = used
+}
+
+// getSampledDiskUsage returns the disk usage measured last
+func getSampledDiskUsage() int64 {
+	sampledDiskUsageMutex.Lock()
+	defer sampledDiskUsageMutex.Unlock()
+	return sampledDiskUsage
+}
+
+// formatBytes formats the number of bytes using the binary units of quantities like Mi and Gi
+func formatBytes(bytes int64) string {
+	units := []string{"Ki", "Mi", "Gi", "Ti"}
+	if bytes < 1024 {
+		return fmt.Sprintf("%d bytes", bytes)
+	}
+	value := float64(bytes) / 1024
+	unit := units[0]
+	for _, u := range units[1:] {
+		if value < 1024 {
+			break
+		}
+		value /= 1024
+		unit = u
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0") + unit
+}
+
+// getDiskUsage returns the total size of the files in the directory
+func getDiskUsage(dir string) int64 {
+	size := int64(0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Debugf("failed to measure the disk usage of the directory %s . Error: %q", dir, err)
+	}
+	return size
+}
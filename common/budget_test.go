@@ -0,0 +1,198 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func resetRunBudget(t *testing.T) {
+	t.Cleanup(func() {
+		if err := SetRunBudget(0, ""); err != nil {
+			t.Fatalf("failed to reset the budget of the run. Error: %q", err)
+		}
+	})
+}
+
+func TestSetRunBudget(t *testing.T) {
+	resetRunBudget(t)
+	if err := SetRunBudget(time.Minute, "2Ki"); err != nil {
+		t.Fatalf("failed to set the budget of the run. Error: %q", err)
+	}
+	if MaxRunDuration != time.Minute || MaxDiskUsage != 2048 {
+		t.Fatalf("expected a budget of 1m and 2048 bytes. Actual: %s and %d bytes", MaxRunDuration, MaxDiskUsage)
+	}
+	if !HasRunBudget() {
+		t.Fatalf("expected the run to have a budget")
+	}
+	if err := SetRunBudget(0, "lots"); err == nil {
+		t.Fatalf("expected an error for an invalid disk budget")
+	}
+	if err := SetRunBudget(0, ""); err != nil || HasRunBudget() {
+		t.Fatalf("expected the run to have no budget. Error: %v", err)
+	}
+}
+
+func TestCheckRunBudget(t *testing.T) {
+	resetRunBudget(t)
+	t.Run("no budget", func(t *testing.T) {
+		if err := SetRunBudget(0, ""); err != nil {
+			t.Fatalf("failed to set the budget of the run. Error: %q", err)
+		}
+		if err := CheckRunBudget("t1"); err != nil {
+			t.Fatalf("expected no error. Actual: %s", err)
+		}
+	})
+	t.Run("duration exceeded", func(t *testing.T) {
+		if err := SetRunBudget(time.Millisecond, ""); err != nil {
+			t.Fatalf("failed to set the budget of the run. Error: %q", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+		err := CheckRunBudget("t1")
+		if err == nil {
+			t.Fatalf("expected the duration budget to be exceeded")
+		}
+		if err.Budget != DurationBudget || err.Transformer != "t1" {
+			t.Fatalf("expected the duration budget to be exceeded at the transformer t1. Actual: %+v", err)
+		}
+	})
+	t.Run("disk usage is not measured without a context", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "big"), make([]byte, 4096), 0644); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+		if err := SetRunBudget(0, "1Ki"); err != nil {
+			t.Fatalf("failed to set the budget of the run. Error: %q", err)
+		}
+		if err := CheckRunBudget("t1"); err != nil {
+			t.Fatalf("expected no error before the disk usage is sampled. Actual: %s", err)
+		}
+	})
+}
+
+func TestWithRunBudget(t *testing.T) {
+	resetRunBudget(t)
+	t.Run("duration", func(t *testing.T) {
+		if err := SetRunBudget(50*time.Millisecond, ""); err != nil {
+			t.Fatalf("failed to set the budget of the run. Error: %q", err)
+		}
+		ctx, cancel := WithRunBudget(context.Background())
+		defer cancel()
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the context to be done at the end of the duration budget")
+		}
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			t.Fatalf("expected the deadline of the context to be exceeded. Actual: %v", ctx.Err())
+		}
+		if err := CheckRunBudget("t1"); err == nil || err.Budget != DurationBudget {
+			t.Fatalf("expected the duration budget to be exceeded. Actual: %v", err)
+		}
+	})
+	t.Run("disk usage sampled on a ticker", func(t *testing.T) {
+		oldInterval := diskUsageSampleInterval
+		diskUsageSampleInterval = 10 * time.Millisecond
+		defer func() { diskUsageSampleInterval = oldInterval }()
+		dir1, dir2 := t.TempDir(), t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir1, "small"), make([]byte, 512), 0644); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+		if err := SetRunBudget(0, "1Ki"); err != nil {
+			t.Fatalf("failed to set the budget of the run. Error: %q", err)
+		}
+		ctx, cancel := WithRunBudget(context.Background(), dir1, dir2)
+		defer cancel()
+		if ctx.Err() != nil {
+			t.Fatalf("expected the context to not be done while the disk usage is within the budget")
+		}
+		if err := os.MkdirAll(filepath.Join(dir2, "sub"), 0755); err != nil {
+			t.Fatalf("failed to create the directory. Error: %q", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir2, "sub", "big"), make([]byte, 1536), 0644); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the context to be cancelled once the disk usage exceeded the budget")
+		}
+		err := CheckRunBudget("t2")
+		if err == nil {
+			t.Fatalf("expected the disk budget to be exceeded")
+		}
+		if err.Budget != DiskBudget || err.Limit != "1Ki" || err.Used != "2Ki" || err.Transformer != "t2" {
+			t.Fatalf("expected the disk budget of 1Ki to be exceeded with 2Ki at the transformer t2. Actual: %+v", err)
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		if err := SetRunBudget(time.Hour, "1Gi"); err != nil {
+			t.Fatalf("failed to set the budget of the run. Error: %q", err)
+		}
+		ctx, cancel := WithRunBudget(context.Background(), t.TempDir())
+		if ctx.Err() != nil {
+			t.Fatalf("expected the context to not be done within the budget")
+		}
+		cancel()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Fatalf("expected the context to be cancelled. Actual: %v", ctx.Err())
+		}
+	})
+}
+
+func TestFormatBytes(t *testing.T) {
+	testCases := map[int64]string{
+		0:                                    "0 bytes",
+		1023:                                 "1023 bytes",
+		1024:                                 "1Ki",
+		1536:                                 "1.5Ki",
+		5 * 1024 * 1024:                      "5Mi",
+		3 * 1024 * 1024 * 1024 * 1024 * 1024: "3072Ti",
+	}
+	for bytes, expected := range testCases {
+		if actual := formatBytes(bytes); actual != expected {
+			t.Errorf("expected %d bytes to be formatted as %s . Actual: %s", bytes, expected, actual)
+		}
+	}
+}
+
+func TestGetDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a", "b"), 0755); err != nil {
+		t.Fatalf("failed to create the directories. Error: %q", err)
+	}
+	files := map[string]int{"f1": 100, "a/f2": 200, "a/b/f3": 300}
+	for path, size := range files {
+		if err := os.WriteFile(filepath.Join(dir, path), make([]byte, size), 0644); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(dir, "a", "b", "f3"), filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("failed to create the symlink. Error: %q", err)
+	}
+	if used := getDiskUsage(dir); used != 600 {
+		t.Fatalf("expected a disk usage of 600 bytes, since symlinks are not counted. Actual: %d", used)
+	}
+	if used := getDiskUsage(filepath.Join(dir, "missing")); used != 0 {
+		t.Fatalf("expected no disk usage for a missing directory. Actual: %d", used)
+	}
+}
@@ -27,6 +27,27 @@ import (
 const (
 	// DisableLocalExecutionFlag is the name of the flag that tells us whether to use allow execution of executables locally
 	DisableLocalExecutionFlag = "disable-local-execution"
+	// OfflineFlag is the name of the flag that tells us to not access the network
+	OfflineFlag = "offline"
+	// BuildCacheDirFlag is the name of the flag that contains the directory where built container images are cached across runs
+	BuildCacheDirFlag = "build-cache-dir"
+	// EnvironmentCacheDirFlag is the name of the flag that contains the directory where the prepared images of the environments are cached across runs
+	EnvironmentCacheDirFlag = "environment-cache-dir"
+	// ContainerRuntimeFlag is the name of the flag that selects the container runtime used to spawn the containers
+	ContainerRuntimeFlag = "container-runtime"
+	// FeatureGatesFlag is the name of the flag that contains the feature gates of the experimental transformers and behaviours
+	FeatureGatesFlag = "feature-gates"
+)
+
+const (
+	// ImageTagStrategyLatest tags the new images as latest
+	ImageTagStrategyLatest = "latest"
+	// ImageTagStrategyGitSHA tags the new images with the short hash of the current commit of the source repo
+	ImageTagStrategyGitSHA = "git-sha"
+	// ImageTagStrategySemverFromTag tags the new images with the highest semantic version among the git tags of the current commit
+	ImageTagStrategySemverFromTag = "semver-from-tag"
+	// ImageTagStrategyDate tags the new images with the date of the current commit of the source repo
+	ImageTagStrategyDate = "date"
 )
 
 const (
@@ -44,6 +65,16 @@ const (
 	QACacheFile = types.AppNameShort + "qacache.yaml"
 	// ConfigFile defines the location of the config file
 	ConfigFile = types.AppNameShort + "config.yaml"
+	// TransformCheckpointFile defines the location of the file that stores the state of an unfinished transform
+	TransformCheckpointFile = types.AppNameShort + "checkpoint.yaml"
+	// OutputHashesFile defines the location of the file in the output directory that stores the hashes of the generated files
+	OutputHashesFile = types.AppNameShort + "hashes.yaml"
+	// UsageSummaryFile defines the location of the anonymous usage summary of the run, written when the telemetry is enabled
+	UsageSummaryFile = types.AppNameShort + "usage.yaml"
+	// OutputManifestFile defines the location of the file in the output directory that lists the sha256 hashes of all the files in the output, in the format of sha256sum
+	OutputManifestFile = types.AppNameShort + "manifest.sha256"
+	// OutputManifestSignatureFile defines the location of the cosign signature of the output manifest file
+	OutputManifestSignatureFile = OutputManifestFile + ".sig"
 	// IgnoreFilename is the name of the file containing the ignore rules and exceptions
 	IgnoreFilename = "." + types.AppNameShort + "ignore"
 	// WindowsAnnotation tag is used tag a service to run on windows nodes
@@ -54,12 +85,25 @@ const (
 	DefaultServicePort int32 = 8080
 	// TODOAnnotation is used to annotate with TODO tasks
 	TODOAnnotation = types.GroupName + "/todo."
+	// IgnoreAnnotation leaves an existing Kubernetes resource out of the output when its value is true
+	IgnoreAnnotation = types.GroupName + "/ignore"
+	// TransformAnnotation writes an existing Kubernetes resource as it is, without converting it for the target cluster, when its value is false
+	TransformAnnotation = types.GroupName + "/transform"
+	// ExposeAnnotation on an existing Kubernetes service creates an ingress or route for it when its value is true,
+	// and removes the ingress paths and routes of the service and makes it a ClusterIP service when its value is false
+	ExposeAnnotation = types.GroupName + "/expose"
+	// ServicePortAnnotation is the name or number of the port of the service that is exposed
+	ServicePortAnnotation = types.GroupName + "/service-port"
+	// ExposePathAnnotation is the path at which the service is exposed
+	ExposePathAnnotation = types.GroupName + "/expose-path"
 	// DefaultBuildContainerName stores default build container name
 	DefaultBuildContainerName = "builder"
 	// ShExt is the extension of sh file
 	ShExt = ".sh"
 	// BatExt is the extension of bat file
 	BatExt = ".bat"
+	// Ps1Ext is the extension of powershell file
+	Ps1Ext = ".ps1"
 )
 
 const (
@@ -79,10 +123,36 @@ const (
 	TransformerSelectorKey = BaseKey + d + "transformerselector"
 	//ConfigServicesKey represents Services Key
 	ConfigServicesKey = BaseKey + d + "services"
+	//ConfigExternalServicesKey represents the dependencies that stay outside the cluster
+	ConfigExternalServicesKey = BaseKey + d + "externalservices"
+	//ConfigExternalServicesDetectedKey represents the key for selecting the detected dependencies that stay outside the cluster
+	ConfigExternalServicesDetectedKey = ConfigExternalServicesKey + d + "detected"
+	//ConfigExternalServicesAdditionalKey represents the key for the dependencies outside the cluster that were not detected
+	ConfigExternalServicesAdditionalKey = ConfigExternalServicesKey + d + "additional"
+	//ConfigExternalServicesEgressPolicyKey represents the key for selecting the dependencies outside the cluster whose clients have their egress restricted
+	ConfigExternalServicesEgressPolicyKey = ConfigExternalServicesKey + d + ConfigEgressPolicyKeySegment
+	//ConfigVaultAddressKey represents the key for the address of the Vault server
+	ConfigVaultAddressKey = BaseKey + d + "vault" + d + "address"
 	//ConfigStoragesKey represents Storages Key
 	ConfigStoragesKey = BaseKey + d + "storages"
 	//ConfigMinReplicasKey represents Ingress host Key
 	ConfigMinReplicasKey = BaseKey + d + "minreplicas"
+	//ConfigInferredReplicasKey represents the key for confirming the replica counts inferred from the source
+	ConfigInferredReplicasKey = BaseKey + d + "inferredreplicas"
+	//ConfigIaCKey represents the key for the questions about provisioning the external services in the cloud
+	ConfigIaCKey = BaseKey + d + "iac"
+	//ConfigIaCProvisionKey represents the key for the external services that are provisioned in the cloud
+	ConfigIaCProvisionKey = ConfigIaCKey + d + "provision"
+	//ConfigIaCToolKey represents the key for the infrastructure as code tool used to provision the external services
+	ConfigIaCToolKey = ConfigIaCKey + d + "tool"
+	//ConfigIaCSecretStoreKey represents the key for the secret store that the connection details are fetched from
+	ConfigIaCSecretStoreKey = ConfigIaCKey + d + "secretstore"
+	//ConfigIaCResourcesKey represents the key for the questions about each external service that is provisioned
+	ConfigIaCResourcesKey = ConfigIaCKey + d + "resources"
+	//ConfigIaCResourceTypeKeySegment represents the key for the type of cloud resource an external service is provisioned as
+	ConfigIaCResourceTypeKeySegment = "type"
+	//ConfigScriptFormatsKey represents the formats in which the scripts should be generated
+	ConfigScriptFormatsKey = BaseKey + d + "scripts" + d + "formats"
 	//ConfigContainerRuntimeKey represents the container runtime to use
 	ConfigContainerRuntimeKey = BaseKey + d + "containerruntime"
 	//ConfigPortsForServiceKeySegment represents the ports used for service
@@ -101,8 +171,92 @@ const (
 	ConfigContainerizationOptionServiceKeySegment = "containerizationoption"
 	//ConfigApacheConfFileForServiceKeySegment represents the conf file used for service
 	ConfigApacheConfFileForServiceKeySegment = "apacheconfig"
+	//ConfigServiceNameKeySegment represents the normalized name of the service
+	ConfigServiceNameKeySegment = "servicename"
+	//ConfigGroupServicesKeySegment represents the key for grouping the detected services that are modules of a single app
+	ConfigGroupServicesKeySegment = "groupservices"
+	//ConfigBuildContextKeySegment represents the key for the build context directory of the image of a service
+	ConfigBuildContextKeySegment = "buildcontext"
+	//ConfigDockerfileKeySegment represents the key for the Dockerfile used to build the image of a service
+	ConfigDockerfileKeySegment = "dockerfile"
+	//ConfigDockerfileTemplateKeySegment represents the key for the custom template used to generate the Dockerfile of a service
+	ConfigDockerfileTemplateKeySegment = "dockerfiletemplate"
+	//ConfigConversionKeySegment represents the key for the resources that a reverse proxy config is converted to
+	ConfigConversionKeySegment = "conversion"
+	//ConfigNamespaceKeySegment represents the key for the namespace a service is deployed to
+	ConfigNamespaceKeySegment = "namespace"
+	//ConfigCronJobsKeySegment represents the key for the scheduled jobs of a service that are converted to cron jobs
+	ConfigCronJobsKeySegment = "cronjobs"
+	//ConfigScheduleKeySegment represents the key for the cron schedule of a job
+	ConfigScheduleKeySegment = "schedule"
+	//ConfigTimeZoneKeySegment represents the key for the timezone of the schedule of a job
+	ConfigTimeZoneKeySegment = "timezone"
+	//ConfigLocaleKeySegment represents the key for the locale of the containers of a service
+	ConfigLocaleKeySegment = "locale"
+	//ConfigJVMOptionsKeySegment represents the key for the JVM options of a java container
+	ConfigJVMOptionsKeySegment = "jvmoptions"
+	//ConfigContainersKeySegment represents the key for the containers of a service
+	ConfigContainersKeySegment = "containers"
+	//ConfigScalingMitigationsKeySegment represents the key for how the local state of a service is handled when scaling it
+	ConfigScalingMitigationsKeySegment = "scalingmitigations"
+	//ConfigUploadsPathKeySegment represents the key for the path where a service stores its uploads
+	ConfigUploadsPathKeySegment = "uploadspath"
+	//ConfigSidecarsKeySegment represents the key for the sidecars that are injected into a service
+	ConfigSidecarsKeySegment = "sidecars"
+	//ConfigSecretsProviderKeySegment represents the key for where a service fetches its secrets from
+	ConfigSecretsProviderKeySegment = "secretsprovider"
+	//ConfigVaultRoleKeySegment represents the key for the Vault role of a service
+	ConfigVaultRoleKeySegment = "vaultrole"
+	//ConfigVaultPathsKeySegment represents the key for the Vault paths of the secrets of a service
+	ConfigVaultPathsKeySegment = "vaultpaths"
+	//ConfigDotNetTargetKeySegment represents the key for choosing between a Windows container and modernizing a dot net framework service
+	ConfigDotNetTargetKeySegment = "dotnettarget"
+	//ConfigKEDAKeySegment represents the key for the questions about scaling a service with KEDA on the events it consumes
+	ConfigKEDAKeySegment = "keda"
+	//ConfigUpgradeRuntimeKeySegment represents the key for the question about targeting a newer version of a runtime that reached its end of life
+	ConfigUpgradeRuntimeKeySegment = "upgraderuntime"
+	//ConfigToolchainVersionKeySegment represents the key for the language toolchain version used to build and run a service
+	ConfigToolchainVersionKeySegment = "toolchainversion"
+	//ConfigServerlessKeySegment represents the key for packaging a small handler for a function platform instead of a Deployment
+	ConfigServerlessKeySegment = "serverless"
+	//ConfigGPUKeySegment represents the key for the questions about requesting GPUs for a service
+	ConfigGPUKeySegment = "gpu"
+	//ConfigEgressPolicyKeySegment represents the key for restricting the egress of a service to its external endpoints
+	ConfigEgressPolicyKeySegment = "egresspolicy"
+	//ConfigCommandKeySegment represents the key for the command run by a job
+	ConfigCommandKeySegment = "command"
+	//ConfigHelmChartsKey represents the helm charts found in the source
+	ConfigHelmChartsKey = BaseKey + d + "helmcharts"
+	//ConfigHelmChartValuesFilesKeySegment represents the values files used to render a helm chart
+	ConfigHelmChartValuesFilesKeySegment = "valuesfiles"
 	//ConfigSpawnContainersKey represents spwan containers option Key
 	ConfigSpawnContainersKey = BaseKey + d + "spawncontainers"
+	//ConfigPlanningKey represents the key for the settings of the planning
+	ConfigPlanningKey = BaseKey + d + "planning"
+	//ConfigPlanningMaxDepthKey represents the maximum depth of the directories looked at while planning
+	ConfigPlanningMaxDepthKey = ConfigPlanningKey + d + "maxdepth"
+	//ConfigPlanningMaxFilesPerDirKey represents the maximum number of files in the directories looked at while planning
+	ConfigPlanningMaxFilesPerDirKey = ConfigPlanningKey + d + "maxfilesperdir"
+	//ConfigPlanningMaxFileSizeKey represents the maximum size of the files looked at while planning
+	ConfigPlanningMaxFileSizeKey = ConfigPlanningKey + d + "maxfilesize"
+	//ConfigContainerEngineKey represents the key for the Docker daemon used to spawn the containers
+	ConfigContainerEngineKey = BaseKey + d + "containerengine"
+	//ConfigContainerEngineHostKey represents the address of the Docker daemon used to spawn the containers
+	ConfigContainerEngineHostKey = ConfigContainerEngineKey + d + "host"
+	//ConfigContainerEngineSocketKey represents the path of the socket of the local Docker compatible daemon used to spawn the containers
+	ConfigContainerEngineSocketKey = ConfigContainerEngineKey + d + "socket"
+	//ConfigContainerEngineCertPathKey represents the directory with the TLS client certificates of the Docker daemon
+	ConfigContainerEngineCertPathKey = ConfigContainerEngineKey + d + "certpath"
+	//ConfigContainerEngineTLSVerifyKey represents the key for verifying the TLS certificate of the Docker daemon
+	ConfigContainerEngineTLSVerifyKey = ConfigContainerEngineKey + d + "tlsverify"
+	//ConfigContainerEngineImageBuilderKey represents the key for the tool used to build the container images
+	ConfigContainerEngineImageBuilderKey = ConfigContainerEngineKey + d + "imagebuilder"
+	//ConfigContainerEngineRuntimeKey represents the key for the container runtime used to spawn the containers
+	ConfigContainerEngineRuntimeKey = ConfigContainerEngineKey + d + "runtime"
+	//ConfigContainerEngineRegistryUserNameKey represents the username used to pull the images of the transformers from a registry
+	ConfigContainerEngineRegistryUserNameKey = ConfigContainerEngineKey + d + "registries" + d + "%s" + d + "username"
+	//ConfigContainerEngineRegistryPasswordKey represents the password used to pull the images of the transformers from a registry
+	ConfigContainerEngineRegistryPasswordKey = ConfigContainerEngineKey + d + "registries" + d + "%s" + d + "password"
 	//ConfigTransformersKey represents transformers Key
 	ConfigTransformersKey = BaseKey + d + "transformers"
 	//ConfigTargetKey represents Target Key
@@ -133,6 +287,8 @@ const (
 	ConfigIngressClassNameKeySuffix = IngressKey + d + "ingressclassname"
 	//ConfigIngressHostKeySuffix represents Ingress host Key
 	ConfigIngressHostKeySuffix = IngressKey + d + "host"
+	// ConfigIngressGRPCClassNameKeySuffix represents the ingress class name of the ingress for the gRPC services
+	ConfigIngressGRPCClassNameKeySuffix = IngressKey + d + "grpcingressclassname"
 	//ConfigIngressTLSKeySuffix represents ingress tls Key
 	ConfigIngressTLSKeySuffix = IngressKey + d + "tls"
 	//ConfigTargetClusterTypeKey represents target cluster type key
@@ -153,10 +309,106 @@ const (
 	ConfigImageRegistryUserNameKey = ConfigImageRegistryKey + d + "%s" + d + "username"
 	//ConfigImageRegistryPasswordKey represents image registry login Password Key
 	ConfigImageRegistryPasswordKey = ConfigImageRegistryKey + d + "%s" + d + "password"
+	//ConfigImageTagStrategyKey represents the key of the strategy used to tag the new images
+	ConfigImageTagStrategyKey = ConfigTargetKey + d + "imagetagstrategy"
+	//ConfigOutputLayoutKey represents the key of the layout of the yaml files of the Kubernetes resources
+	ConfigOutputLayoutKey = ConfigTargetKey + d + "outputlayout"
 	//ConfigStoragesPVCForHostPathKey represents key for PVC for Host Path
 	ConfigStoragesPVCForHostPathKey = ConfigStoragesKey + d + "pvcforhostpath"
 	//ConfigStoragesPerClaimStorageClassKey represents key for having different storage class for claim
 	ConfigStoragesPerClaimStorageClassKey = ConfigStoragesKey + d + "perclaimstorageclass"
+	//ConfigStoragesStorageClassKeySuffix represents key for the storage class of a claim
+	ConfigStoragesStorageClassKeySuffix = "storageclass"
+	//ConfigStoragesAccessModeKeySuffix represents key for the access mode of a claim
+	ConfigStoragesAccessModeKeySuffix = "accessmode"
+	//ConfigStoragesSizeKeySuffix represents key for the size of a claim
+	ConfigStoragesSizeKeySuffix = "size"
+	//ConfigObservabilityKey represents the key for all the observability wiring questions
+	ConfigObservabilityKey = BaseKey + d + "observability"
+	//ConfigObservabilityServicesKey represents the key for the services that should be wired for observability
+	ConfigObservabilityServicesKey = ConfigObservabilityKey + d + "services"
+	//ConfigObservabilityOTelEndpointKey represents the key for the OpenTelemetry collector endpoint
+	ConfigObservabilityOTelEndpointKey = ConfigObservabilityKey + d + "otelendpoint"
+	//ConfigObservabilityFeaturesKeySuffix represents the key for the observability features of a service
+	ConfigObservabilityFeaturesKeySuffix = "features"
+	//ConfigObservabilityMetricsPortKeySuffix represents the key for the metrics port of a service
+	ConfigObservabilityMetricsPortKeySuffix = "metricsport"
+	//ConfigObservabilityMetricsPathKeySuffix represents the key for the metrics path of a service
+	ConfigObservabilityMetricsPathKeySuffix = "metricspath"
+	//ConfigObservabilityLogFormatKeySuffix represents the key for the log format of a service
+	ConfigObservabilityLogFormatKeySuffix = "logformat"
+	//ConfigServiceDiscoveryKey represents the key for the service discovery rewrite questions
+	ConfigServiceDiscoveryKey = BaseKey + d + "servicediscovery"
+	//ConfigServiceDiscoveryDNSNameFormatKey represents the key for the format of the rewritten dns names
+	ConfigServiceDiscoveryDNSNameFormatKey = ConfigServiceDiscoveryKey + d + "dnsnameformat"
+	//ConfigServiceDiscoveryNamespaceKey represents the key for the namespace used in the fully qualified dns names
+	ConfigServiceDiscoveryNamespaceKey = ConfigServiceDiscoveryKey + d + "namespace"
+	//ConfigServiceDiscoveryRewritesKey represents the key for the inter-service references that should be rewritten
+	ConfigServiceDiscoveryRewritesKey = ConfigServiceDiscoveryKey + d + "rewrites"
+	//ConfigRolloutStrategyKeySuffix represents the key for the rollout strategy of a service
+	ConfigRolloutStrategyKeySuffix = "rolloutstrategy"
+	//ConfigRolloutMaxSurgeKeySuffix represents the key for the max surge of the rolling update of a service
+	ConfigRolloutMaxSurgeKeySuffix = "maxsurge"
+	//ConfigRolloutMaxUnavailableKeySuffix represents the key for the max unavailable of the rolling update of a service
+	ConfigRolloutMaxUnavailableKeySuffix = "maxunavailable"
+	//ConfigGRPCProbeKeySuffix represents the key for the kind of health probes of a gRPC service
+	ConfigGRPCProbeKeySuffix = "grpcprobe"
+	//ConfigGRPCHeadlessKeySuffix represents the key for making a gRPC service headless
+	ConfigGRPCHeadlessKeySuffix = "grpcheadless"
+	//ConfigBackupKey represents the key for the backup questions
+	ConfigBackupKey = BaseKey + d + "backup"
+	//ConfigBackupServicesKey represents the key for the stateful services that should be backed up
+	ConfigBackupServicesKey = ConfigBackupKey + d + "services"
+	//ConfigBackupScheduleKey represents the key for the cron schedule of the backups
+	ConfigBackupScheduleKey = ConfigBackupKey + d + "schedule"
+	//ConfigBackupTTLKey represents the key for the retention of the backups
+	ConfigBackupTTLKey = ConfigBackupKey + d + "ttl"
+	//ConfigBackupStorageLocationKey represents the key for generating a backup storage location
+	ConfigBackupStorageLocationKey = ConfigBackupKey + d + "storagelocation"
+	//ConfigBackupBucketKey represents the key for the bucket of the backup storage location
+	ConfigBackupBucketKey = ConfigBackupKey + d + "bucket"
+	//ConfigTimeZoneKey represents the key for the default timezone of the services that depend on the local time
+	ConfigTimeZoneKey = BaseKey + d + "timezone"
+	//ConfigTenancyKey represents the key for the multi-tenancy questions
+	ConfigTenancyKey = BaseKey + d + "tenancy"
+	//ConfigTenancyEnableKey represents the key for organizing the services into multiple namespaces
+	ConfigTenancyEnableKey = ConfigTenancyKey + d + "enable"
+	//ConfigTenancyMappingFileKey represents the key for the file that maps the namespaces to the services
+	ConfigTenancyMappingFileKey = ConfigTenancyKey + d + "mappingfile"
+	//ConfigTenancyQuotaKey represents the key for the resource quota of each namespace
+	ConfigTenancyQuotaKey = ConfigTenancyKey + d + "quota"
+	//ConfigTenancyLimitRangeKey represents the key for the default container resources in each namespace
+	ConfigTenancyLimitRangeKey = ConfigTenancyKey + d + "limitrange"
+	//ConfigTenancyNetworkPolicyKey represents the key for generating the baseline network policy of each namespace
+	ConfigTenancyNetworkPolicyKey = ConfigTenancyKey + d + "networkpolicy"
+	//ConfigTenancyIngressNamespaceKey represents the key for the namespace of the ingress controller
+	ConfigTenancyIngressNamespaceKey = ConfigTenancyKey + d + "ingressnamespace"
+	//ConfigGitOpsKey represents the key for the GitOps repo questions
+	ConfigGitOpsKey = BaseKey + d + "gitops"
+	//ConfigGitOpsEnableKey represents the key for laying out the kubernetes yamls as a GitOps repo
+	ConfigGitOpsEnableKey = ConfigGitOpsKey + d + "enable"
+	//ConfigGitOpsRepoURLKey represents the key for the url of the GitOps repo
+	ConfigGitOpsRepoURLKey = ConfigGitOpsKey + d + "repourl"
+	//ConfigGitOpsRepoRefKey represents the key for the branch, tag or commit of the GitOps repo that is deployed
+	ConfigGitOpsRepoRefKey = ConfigGitOpsKey + d + "reporef"
+	//ConfigBackstageKey represents the key for the questions about the Backstage catalog entities
+	ConfigBackstageKey = BaseKey + d + "backstage"
+	//ConfigBackstageOwnerKey represents the key for the owner of the Backstage catalog entities
+	ConfigBackstageOwnerKey = ConfigBackstageKey + d + "owner"
+	//ConfigBackstageSystemKey represents the key for the system the Backstage components belong to
+	ConfigBackstageSystemKey = ConfigBackstageKey + d + "system"
+	//ConfigBackstageLifecycleKey represents the key for the lifecycle of the Backstage components
+	ConfigBackstageLifecycleKey = ConfigBackstageKey + d + "lifecycle"
+	//ConfigCostEstimationPriceSheetKey represents the key for the price sheet used to estimate the cost
+	ConfigCostEstimationPriceSheetKey = BaseKey + d + "costestimation" + d + "pricesheet"
+	//ConfigAPIGatewayKey represents the key for the API gateway questions
+	ConfigAPIGatewayKey = BaseKey + d + "apigateway"
+	//ConfigAPIGatewayTypeKey represents the key for the type of API gateway configuration to generate
+	ConfigAPIGatewayTypeKey = ConfigAPIGatewayKey + d + "type"
+	//ConfigAPIGatewayNameKey represents the key for the name of the gateway that the routes are attached to
+	ConfigAPIGatewayNameKey = ConfigAPIGatewayKey + d + "gatewayname"
+	//ConfigReverseProxyKey represents the key for the questions about the nginx and HAProxy configs
+	ConfigReverseProxyKey = BaseKey + d + "reverseproxy"
 	//ConfigServicesNamesKey is true if a detected service is enabled for transformation
 	ConfigServicesNamesKey = ConfigServicesKey + d + Special + d + "enable"
 	//ConfigContainerizationTypesKey represents source type Key
@@ -206,6 +458,34 @@ var (
 	IgnoreEnvironment = false
 	// DisableLocalExecution indicates whether to allow execution of local executables
 	DisableLocalExecution = false
+	// Offline indicates that the network must not be accessed, so container images have to be available locally
+	Offline = false
+	// BuildCacheDir is the directory where built container images are cached across runs. Images are not cached if it is empty.
+	BuildCacheDir = ""
+	// EnvironmentCacheDir is the directory where the images of the environments, with the source copied into them, are cached across runs. Images are not cached if it is empty.
+	EnvironmentCacheDir = ""
+	// DevMode indicates that the custom transformers should be reloaded when their files change
+	DevMode = false
+	// DebugArtifactsDir is the directory where the artifacts and path mappings of each transformer run are written. They are not written if it is empty.
+	DebugArtifactsDir = ""
+	// DebugArtifactsEnvironment indicates that the temporary files of the environments of the transformers should be written to DebugArtifactsDir as well
+	DebugArtifactsEnvironment = false
+	// StepMode pauses the transform before each transformer runs, so that the user can inspect its input artifacts and skip it
+	StepMode = false
+	// StreamCommandOutput logs the output of the commands run in the containers of the transformers as it is written, instead of only after they finish
+	StreamCommandOutput = false
+	// ContainerFilesOwner is the user and group, like 1001:0, that own the directories copied into the containers of the transformers.
+	// By default they keep the owner of the files on the host, or are owned by root for rootless engines, where root in the container is the user running move2kube.
+	ContainerFilesOwner = ""
+	// OutputOwner is the user and group, like 1000:1000, that own the files written by a run as root.
+	// By default they are owned by the user that ran move2kube using sudo.
+	OutputOwner = ""
+	// ImageTag is the tag given to the new images when they are pushed and used in the generated objects. The tag is latest if it is empty.
+	ImageTag = ""
+	// ImageTagStrategies are the strategies that can be used to tag the new images
+	ImageTagStrategies = []string{ImageTagStrategyLatest, ImageTagStrategyGitSHA, ImageTagStrategySemverFromTag, ImageTagStrategyDate}
+	// RunID is the correlation ID of the run. It is added to all the logs.
+	RunID = ""
 	// DefaultIgnoreDirRegexps specifies directory name regexes that would be ignored
 	DefaultIgnoreDirRegexps = []*regexp.Regexp{regexp.MustCompile("^[.].*")}
 	// disallowedDNSCharactersRegex provides pattern for characters not allowed in a DNS Name
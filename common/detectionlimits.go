@@ -0,0 +1,132 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DetectionLimits bound the directories and files that the transformers look at while planning, so that planning does not take too long on messy repos.
+// The limits that are 0 are not applied.
+type DetectionLimits struct {
+	// MaxDepth is the maximum depth of the directories below the source directory
+	MaxDepth int
+	// MaxFilesPerDir is the maximum number of files and directories in a directory. Bigger directories are skipped.
+	MaxFilesPerDir int
+	// MaxFileSize is the maximum size of the files in bytes. Bigger files are skipped.
+	MaxFileSize int64
+}
+
+var (
+	// PlanDetectionLimits are the limits applied while planning
+	PlanDetectionLimits = DetectionLimits{MaxFilesPerDir: DefaultMaxFilesPerDir}
+	// SkippedDetectionDirNames are the names of the directories that are skipped while planning, since they have the dependencies of the sources and not the sources
+	SkippedDetectionDirNames = []string{"node_modules", "bower_components", "__pycache__"}
+
+	// detectionRoot is the source directory while planning. The limits are not applied when it is empty.
+	detectionRoot        string
+	detectionWarned      = map[string]bool{}
+	detectionRootMutex   sync.RWMutex
+	detectionWarnedMutex sync.Mutex
+)
+
+const (
+	// DefaultMaxFilesPerDir is the default maximum number of files and directories in a directory while planning
+	DefaultMaxFilesPerDir = 10000
+)
+
+// StartDetection applies the detection limits to the directories below the source directory until StopDetection is called
+func StartDetection(root string) {
+	detectionRootMutex.Lock()
+	defer detectionRootMutex.Unlock()
+	detectionRoot = filepath.Clean(root)
+}
+
+// StopDetection stops applying the detection limits
+func StopDetection() {
+	detectionRootMutex.Lock()
+	defer detectionRootMutex.Unlock()
+	detectionRoot = ""
+}
+
+// SkipDirForDetection returns true if the directory should not be looked at while planning.
+// It warns once for each directory that is skipped because of its name or its number of files.
+func SkipDirForDetection(dir string) bool {
+	detectionRootMutex.RLock()
+	root := detectionRoot
+	detectionRootMutex.RUnlock()
+	if root == "" {
+		return false
+	}
+	relDir, err := filepath.Rel(root, dir)
+	if err != nil || relDir == "." || strings.HasPrefix(relDir, "..") {
+		return false
+	}
+	if IsStringPresent(SkippedDetectionDirNames, filepath.Base(dir)) {
+		warnOnceForDetection(dir, "Skipping the directory %s while planning, since it has the dependencies of the sources", relDir)
+		return true
+	}
+	if PlanDetectionLimits.MaxDepth > 0 && len(strings.Split(relDir, string(os.PathSeparator))) > PlanDetectionLimits.MaxDepth {
+		logrus.Debugf("Skipping the directory %s while planning, since it is deeper than %d directories", relDir, PlanDetectionLimits.MaxDepth)
+		return true
+	}
+	if PlanDetectionLimits.MaxFilesPerDir > 0 {
+		dirHandle, err := os.Open(dir)
+		if err != nil {
+			return false
+		}
+		defer dirHandle.Close()
+		// reading one more than the limit is enough to know that the directory is too big
+		names, _ := dirHandle.Readdirnames(PlanDetectionLimits.MaxFilesPerDir + 1)
+		if len(names) > PlanDetectionLimits.MaxFilesPerDir {
+			warnOnceForDetection(dir, "Skipping the directory %s while planning, since it has more than %d files", relDir, PlanDetectionLimits.MaxFilesPerDir)
+			return true
+		}
+	}
+	return false
+}
+
+// SkipFileForDetection returns true if the file should not be looked at while planning, since it is too big
+func SkipFileForDetection(path string) bool {
+	detectionRootMutex.RLock()
+	root := detectionRoot
+	detectionRootMutex.RUnlock()
+	if root == "" || PlanDetectionLimits.MaxFileSize <= 0 {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() <= PlanDetectionLimits.MaxFileSize {
+		return false
+	}
+	logrus.Debugf("Skipping the file %s while planning, since it is bigger than %d bytes", path, PlanDetectionLimits.MaxFileSize)
+	return true
+}
+
+func warnOnceForDetection(dir, format string, args ...interface{}) {
+	detectionWarnedMutex.Lock()
+	defer detectionWarnedMutex.Unlock()
+	if detectionWarned[dir] {
+		return
+	}
+	detectionWarned[dir] = true
+	logrus.Warnf(format, args...)
+}
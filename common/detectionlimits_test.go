@@ -0,0 +1,150 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func setupDetectionTest(t *testing.T, limits DetectionLimits) string {
+	t.Helper()
+	oldLimits := PlanDetectionLimits
+	t.Cleanup(func() {
+		PlanDetectionLimits = oldLimits
+		StopDetection()
+	})
+	PlanDetectionLimits = limits
+	root := t.TempDir()
+	files := map[string]string{
+		"pom.xml":                       "<project/>",
+		"big.json":                      `{"name": "` + strings.Repeat("m", 100) + `"}`,
+		"a/b/c/pom.xml":                 "<project/>",
+		"node_modules/lib/package.json": "{}",
+	}
+	for i := 0; i < 5; i++ {
+		files[filepath.Join("crowded", fmt.Sprintf("file%d.txt", i))] = ""
+	}
+	for path, contents := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for the file %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	return root
+}
+
+func TestSkipDirForDetection(t *testing.T) {
+	t.Run("directories are not skipped when not planning", func(t *testing.T) {
+		root := setupDetectionTest(t, DetectionLimits{MaxDepth: 1, MaxFilesPerDir: 1})
+		for _, dir := range []string{"node_modules", "a/b/c", "crowded"} {
+			if SkipDirForDetection(filepath.Join(root, dir)) {
+				t.Fatalf("expected the directory %s to not be skipped", dir)
+			}
+		}
+	})
+	t.Run("directories that exceed the limits are skipped while planning", func(t *testing.T) {
+		root := setupDetectionTest(t, DetectionLimits{MaxDepth: 2, MaxFilesPerDir: 4})
+		StartDetection(root)
+		testcases := map[string]bool{
+			".":            false,
+			"a":            false,
+			"a/b":          false,
+			"a/b/c":        true,
+			"node_modules": true,
+			"crowded":      true,
+		}
+		for dir, skip := range testcases {
+			if actual := SkipDirForDetection(filepath.Join(root, dir)); actual != skip {
+				t.Fatalf("expected the skipping of the directory %s to be %t. Actual: %t", dir, skip, actual)
+			}
+		}
+		if SkipDirForDetection(filepath.Dir(root)) {
+			t.Fatalf("expected the directories outside the source directory to not be skipped")
+		}
+	})
+	t.Run("the limits that are 0 are not applied", func(t *testing.T) {
+		root := setupDetectionTest(t, DetectionLimits{})
+		StartDetection(root)
+		for _, dir := range []string{"a/b/c", "crowded"} {
+			if SkipDirForDetection(filepath.Join(root, dir)) {
+				t.Fatalf("expected the directory %s to not be skipped", dir)
+			}
+		}
+		if !SkipDirForDetection(filepath.Join(root, "node_modules")) {
+			t.Fatalf("expected the node_modules directory to be skipped")
+		}
+	})
+}
+
+func TestSkipFileForDetection(t *testing.T) {
+	root := setupDetectionTest(t, DetectionLimits{MaxFileSize: 50})
+	bigFile := filepath.Join(root, "big.json")
+	if SkipFileForDetection(bigFile) {
+		t.Fatalf("expected the file %s to not be skipped when not planning", bigFile)
+	}
+	StartDetection(root)
+	if !SkipFileForDetection(bigFile) {
+		t.Fatalf("expected the file %s to be skipped", bigFile)
+	}
+	if SkipFileForDetection(filepath.Join(root, "pom.xml")) {
+		t.Fatalf("expected the small file to not be skipped")
+	}
+	data := map[string]interface{}{}
+	if err := ReadJSON(bigFile, &data); err == nil {
+		t.Fatalf("expected reading the big json file to fail while planning")
+	}
+	StopDetection()
+	if err := ReadJSON(bigFile, &data); err != nil {
+		t.Fatalf("failed to read the json file %s . Error: %q", bigFile, err)
+	}
+}
+
+func TestGetFilesWithDetectionLimits(t *testing.T) {
+	root := setupDetectionTest(t, DetectionLimits{MaxDepth: 2, MaxFilesPerDir: 4, MaxFileSize: 50})
+	StartDetection(root)
+	files, err := GetFilesByName(root, []string{"pom.xml", "package.json", "big.json"}, nil)
+	if err != nil {
+		t.Fatalf("failed to get the files. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{filepath.Join(root, "pom.xml")}, files); diff != "" {
+		t.Fatalf("wrong files. Difference:\n%s", diff)
+	}
+	files, err = GetFilesByExt(root, []string{".txt", ".xml"})
+	if err != nil {
+		t.Fatalf("failed to get the files. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{filepath.Join(root, "pom.xml")}, files); diff != "" {
+		t.Fatalf("wrong files. Difference:\n%s", diff)
+	}
+	StopDetection()
+	files, err = GetFilesByName(root, []string{"pom.xml"}, nil)
+	if err != nil {
+		t.Fatalf("failed to get the files. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{filepath.Join(root, "a/b/c/pom.xml"), filepath.Join(root, "pom.xml")}, files); diff != "" {
+		t.Fatalf("wrong files after planning. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"os"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	envVarRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?-)([^}]*))?\}`)
+)
+
+// ExpandEnvVars replaces ${VAR}, ${VAR:-default} and ${VAR-default} with the values of the environment variables.
+// With :- the default is also used when the variable is empty. $${VAR} escapes the interpolation.
+// Variables that are not set and do not have a default are left as is, so they can be expanded later (for example by a shell in a container).
+func ExpandEnvVars(value string) string {
+	return envVarRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if match[1] == '$' {
+			return match[1:]
+		}
+		groups := envVarRegex.FindStringSubmatch(match)
+		name, operator, def := groups[1], groups[2], groups[3]
+		envValue, ok := os.LookupEnv(name)
+		if ok && (envValue != "" || operator != ":-") {
+			return envValue
+		}
+		if operator != "" {
+			return def
+		}
+		logrus.Debugf("the environment variable %s is not set. Leaving %s as is.", name, match)
+		return match
+	})
+}
+
+// ExpandEnvVarsInObject expands the environment variables in all the strings of a decoded yaml or json object
+func ExpandEnvVarsInObject(obj interface{}) interface{} {
+	switch v := obj.(type) {
+	case string:
+		return ExpandEnvVars(v)
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = ExpandEnvVarsInObject(val)
+		}
+		return v
+	case map[interface{}]interface{}:
+		for key, val := range v {
+			v[key] = ExpandEnvVarsInObject(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = ExpandEnvVarsInObject(val)
+		}
+		return v
+	}
+	return obj
+}
@@ -0,0 +1,75 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandEnvVars(t *testing.T) {
+	t.Setenv("M2K_TEST_NAME", "app")
+	t.Setenv("M2K_TEST_EMPTY", "")
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "no variables", expected: "no variables"},
+		{value: "${M2K_TEST_NAME}", expected: "app"},
+		{value: "prefix-${M2K_TEST_NAME}-${M2K_TEST_NAME}", expected: "prefix-app-app"},
+		{value: "${M2K_TEST_UNSET}", expected: "${M2K_TEST_UNSET}"},
+		{value: "${M2K_TEST_UNSET:-fallback}", expected: "fallback"},
+		{value: "${M2K_TEST_UNSET-fallback}", expected: "fallback"},
+		{value: "${M2K_TEST_EMPTY:-fallback}", expected: "fallback"},
+		{value: "${M2K_TEST_EMPTY-fallback}", expected: ""},
+		{value: "${M2K_TEST_NAME:-fallback}", expected: "app"},
+		{value: "${M2K_TEST_UNSET:-}", expected: ""},
+		{value: "$${M2K_TEST_NAME}", expected: "${M2K_TEST_NAME}"},
+		{value: "$M2K_TEST_NAME", expected: "$M2K_TEST_NAME"},
+		{value: "${1INVALID}", expected: "${1INVALID}"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.value, func(t *testing.T) {
+			if actual := ExpandEnvVars(testCase.value); actual != testCase.expected {
+				t.Fatalf("failed to expand the environment variables in %s . Expected: %s Actual: %s", testCase.value, testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpandEnvVarsInObject(t *testing.T) {
+	t.Setenv("M2K_TEST_NAME", "app")
+	obj := map[string]interface{}{
+		"name":     "${M2K_TEST_NAME}",
+		"replicas": 2,
+		"labels":   map[interface{}]interface{}{"app": "${M2K_TEST_NAME}", 1: "${M2K_TEST_UNSET:-one}"},
+		"args":     []interface{}{"--name=${M2K_TEST_NAME}", true, "$${M2K_TEST_NAME}"},
+	}
+	expected := map[string]interface{}{
+		"name":     "app",
+		"replicas": 2,
+		"labels":   map[interface{}]interface{}{"app": "app", 1: "one"},
+		"args":     []interface{}{"--name=app", true, "${M2K_TEST_NAME}"},
+	}
+	if diff := cmp.Diff(expected, ExpandEnvVarsInObject(obj)); diff != "" {
+		t.Fatalf("failed to expand the environment variables in the object. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff(expected, obj); diff != "" {
+		t.Fatalf("expected the object to be expanded in place. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package eol
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Java is the name of the java runtime in the EOL database
+	Java = "Java"
+	// Nodejs is the name of the Node.js runtime in the EOL database
+	Nodejs = "Node.js"
+	// Python is the name of the python runtime in the EOL database
+	Python = "Python"
+	// nearingEOLPeriod is how long before its end of life a version gets an advisory
+	nearingEOLPeriod = 180 * 24 * time.Hour
+	dateLayout       = "2006-01-02"
+)
+
+// runtimeInfo is the end of life dates of the versions of a runtime
+type runtimeInfo struct {
+	// eolDates are the end of life dates of the major versions, like 8 for java, 12 for Node.js and 3.7 for python
+	eolDates map[string]string
+	// replacement is the version that the services with an EOL version are offered to target
+	replacement string
+}
+
+// Advisory is a service that uses a runtime version that reached or nears its end of life
+type Advisory struct {
+	ServiceName string
+	Runtime     string
+	Version     string
+	EOLDate     string
+	// TargetVersion is the version the service targets instead, if the user chose to move to a newer version
+	TargetVersion string
+}
+
+var (
+	// runtimes is the embedded EOL database. The java dates are those of the OpenJDK builds of the UBI images.
+	runtimes = map[string]runtimeInfo{
+		Java: {
+			eolDates: map[string]string{
+				"7": "2020-08-31", "8": "2026-11-30", "9": "2018-03-20", "10": "2018-09-25", "11": "2024-10-31",
+				"12": "2019-09-17", "13": "2020-03-17", "14": "2020-09-15", "15": "2021-03-16", "16": "2021-09-14",
+				"17": "2027-10-31", "18": "2022-09-20", "19": "2023-03-21", "20": "2023-09-19", "21": "2029-12-31",
+			},
+			replacement: "17",
+		},
+		Nodejs: {
+			eolDates: map[string]string{
+				"8": "2019-12-31", "10": "2021-04-30", "11": "2019-06-01", "12": "2022-04-30", "13": "2020-06-01",
+				"14": "2023-04-30", "15": "2021-06-01", "16": "2023-09-11", "17": "2022-06-01", "18": "2025-04-30",
+				"19": "2023-06-01", "20": "2026-04-30", "21": "2024-06-01", "22": "2027-04-30", "23": "2025-06-01",
+			},
+			replacement: "v22.11.0",
+		},
+		Python: {
+			eolDates: map[string]string{
+				"2.7": "2020-01-01", "3.5": "2020-09-13", "3.6": "2021-12-23", "3.7": "2023-06-27", "3.8": "2024-10-07",
+				"3.9": "2025-10-31", "3.10": "2026-10-31", "3.11": "2027-10-31", "3.12": "2028-10-31",
+			},
+			replacement: "3.12",
+		},
+	}
+	majorVersionRegex = regexp.MustCompile(`^(\d+)(\.(\d+))?`)
+	advisories        = []Advisory{}
+	advisoriesMutex   sync.Mutex
+)
+
+// CheckRuntimeVersion checks whether the runtime version of the service reached or nears its end of life.
+// If it does, it adds an advisory to the report and asks whether the service should target a newer version.
+// It returns the version the service should target.
+func CheckRuntimeVersion(serviceName, runtime, version string) string {
+	info, ok := runtimes[runtime]
+	if !ok || version == "" {
+		return version
+	}
+	eolDateString, ok := info.eolDates[getMajorVersion(runtime, version)]
+	if !ok {
+		return version
+	}
+	eolDate, err := time.Parse(dateLayout, eolDateString)
+	if err != nil {
+		logrus.Errorf("failed to parse the end of life date %s of %s %s . Error: %q", eolDateString, runtime, version, err)
+		return version
+	}
+	if time.Until(eolDate) > nearingEOLPeriod {
+		return version
+	}
+	state := "reached its end of life on"
+	if time.Now().Before(eolDate) {
+		state = "reaches its end of life on"
+	}
+	logrus.Warnf("The service %s uses %s %s , which %s %s", serviceName, runtime, version, state, eolDateString)
+	advisory := Advisory{ServiceName: serviceName, Runtime: runtime, Version: version, EOLDate: eolDateString}
+	if qaengine.FetchBoolAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigUpgradeRuntimeKeySegment),
+		fmt.Sprintf("The service %s uses %s %s , which %s %s . Do you want to target %s %s instead?", serviceName, runtime, version, state, eolDateString, runtime, info.replacement),
+		[]string{"The newer version may need changes to the sources or the dependencies of the service."},
+		false,
+	) {
+		advisory.TargetVersion = info.replacement
+		version = info.replacement
+	}
+	advisoriesMutex.Lock()
+	advisories = append(advisories, advisory)
+	advisoriesMutex.Unlock()
+	return version
+}
+
+// GetAdvisories returns the advisories added during the run, sorted by service
+func GetAdvisories() []Advisory {
+	advisoriesMutex.Lock()
+	defer advisoriesMutex.Unlock()
+	sorted := append([]Advisory{}, advisories...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ServiceName != sorted[j].ServiceName {
+			return sorted[i].ServiceName < sorted[j].ServiceName
+		}
+		return sorted[i].Runtime < sorted[j].Runtime
+	})
+	return sorted
+}
+
+// getMajorVersion returns the version the EOL dates are given for, like 8 for java 1.8, 12 for Node.js v12.22.12 and 3.7 for python 3.7.9
+func getMajorVersion(runtime, version string) string {
+	version = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(version), "v"), "python-")
+	matches := majorVersionRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return version
+	}
+	switch runtime {
+	case Java:
+		if matches[1] == "1" && matches[3] != "" {
+			return matches[3]
+		}
+		return matches[1]
+	case Python:
+		if matches[3] == "" {
+			return matches[1]
+		}
+		return matches[1] + "." + matches[3]
+	}
+	return matches[1]
+}
@@ -0,0 +1,120 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package eol
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+func TestGetMajorVersion(t *testing.T) {
+	testCases := []struct {
+		runtime  string
+		version  string
+		expected string
+	}{
+		{runtime: Java, version: "1.8", expected: "8"},
+		{runtime: Java, version: "1.8.0_292", expected: "8"},
+		{runtime: Java, version: "11.0.2", expected: "11"},
+		{runtime: Java, version: "17", expected: "17"},
+		{runtime: Nodejs, version: "v12.22.12", expected: "12"},
+		{runtime: Nodejs, version: "18", expected: "18"},
+		{runtime: Python, version: "3.7.9", expected: "3.7"},
+		{runtime: Python, version: "python-3.10.8", expected: "3.10"},
+		{runtime: Python, version: "3", expected: "3"},
+		{runtime: Nodejs, version: "lts/*", expected: "lts/*"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.runtime+" "+testCase.version, func(t *testing.T) {
+			if actual := getMajorVersion(testCase.runtime, testCase.version); actual != testCase.expected {
+				t.Fatalf("wrong major version. Expected: %s Actual: %s", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCheckRuntimeVersion(t *testing.T) {
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{`move2kube.services."upgraded".upgraderuntime=true`}, nil, nil, false, false)
+	t.Cleanup(func() { advisories = []Advisory{} })
+	testCases := []struct {
+		name             string
+		serviceName      string
+		runtime          string
+		version          string
+		expectedVersion  string
+		expectedAdvisory *Advisory
+	}{
+		{name: "supported version", serviceName: "svc", runtime: Java, version: "21", expectedVersion: "21"},
+		{name: "unknown version", serviceName: "svc", runtime: Java, version: "99", expectedVersion: "99"},
+		{name: "unknown runtime", serviceName: "svc", runtime: "Ruby", version: "2.7", expectedVersion: "2.7"},
+		{name: "no version", serviceName: "svc", runtime: Java, version: "", expectedVersion: ""},
+		{
+			name:             "version that reached its end of life",
+			serviceName:      "svc",
+			runtime:          Python,
+			version:          "3.7.9",
+			expectedVersion:  "3.7.9",
+			expectedAdvisory: &Advisory{ServiceName: "svc", Runtime: Python, Version: "3.7.9", EOLDate: "2023-06-27"},
+		},
+		{
+			name:             "upgrade the version that reached its end of life",
+			serviceName:      "upgraded",
+			runtime:          Nodejs,
+			version:          "v12.22.12",
+			expectedVersion:  "v22.11.0",
+			expectedAdvisory: &Advisory{ServiceName: "upgraded", Runtime: Nodejs, Version: "v12.22.12", EOLDate: "2022-04-30", TargetVersion: "v22.11.0"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			advisories = []Advisory{}
+			if actual := CheckRuntimeVersion(testCase.serviceName, testCase.runtime, testCase.version); actual != testCase.expectedVersion {
+				t.Fatalf("wrong target version. Expected: %s Actual: %s", testCase.expectedVersion, actual)
+			}
+			expected := []Advisory{}
+			if testCase.expectedAdvisory != nil {
+				expected = append(expected, *testCase.expectedAdvisory)
+			}
+			if diff := cmp.Diff(expected, GetAdvisories()); diff != "" {
+				t.Fatalf("the advisories are wrong. Difference:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetAdvisories(t *testing.T) {
+	advisories = []Advisory{
+		{ServiceName: "web", Runtime: Python},
+		{ServiceName: "api", Runtime: Nodejs},
+		{ServiceName: "api", Runtime: Java},
+	}
+	t.Cleanup(func() { advisories = []Advisory{} })
+	expected := []Advisory{
+		{ServiceName: "api", Runtime: Java},
+		{ServiceName: "api", Runtime: Nodejs},
+		{ServiceName: "web", Runtime: Python},
+	}
+	if diff := cmp.Diff(expected, GetAdvisories()); diff != "" {
+		t.Fatalf("the advisories are not sorted by service and runtime. Difference:\n%s", diff)
+	}
+	if advisories[0].ServiceName != "web" {
+		t.Fatalf("the advisories of the run were sorted in place")
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FeatureGate is a switch for an experimental transformer or behaviour.
+// Gates are disabled by default, so that the experimental code only runs when the user opts into it.
+type FeatureGate struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+var (
+	featureGates      = map[string]FeatureGate{}
+	featureGateValues = map[string]bool{}
+	featureGatesMutex sync.RWMutex
+)
+
+// RegisterFeatureGate adds a feature gate to the gates the user can set.
+// A gate with the same name replaces the existing one.
+func RegisterFeatureGate(gate FeatureGate) {
+	featureGatesMutex.Lock()
+	defer featureGatesMutex.Unlock()
+	featureGates[gate.Name] = gate
+}
+
+// GetFeatureGates returns all the registered feature gates sorted by name
+func GetFeatureGates() []FeatureGate {
+	featureGatesMutex.RLock()
+	defer featureGatesMutex.RUnlock()
+	gates := []FeatureGate{}
+	for _, gate := range featureGates {
+		gates = append(gates, gate)
+	}
+	sort.Slice(gates, func(i, j int) bool { return gates[i].Name < gates[j].Name })
+	return gates
+}
+
+// SetFeatureGates sets the feature gates from a list of Name=true/false pairs.
+// A name without a value enables the gate.
+func SetFeatureGates(values []string) error {
+	newValues := map[string]bool{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		name, enabledStr, found := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+		enabled := true
+		if found {
+			var err error
+			enabled, err = strconv.ParseBool(strings.TrimSpace(enabledStr))
+			if err != nil {
+				return fmt.Errorf("the feature gate %s has an invalid value %s . Expected true or false. Error: %q", name, enabledStr, err)
+			}
+		}
+		newValues[name] = enabled
+	}
+	featureGatesMutex.Lock()
+	defer featureGatesMutex.Unlock()
+	for name, enabled := range newValues {
+		if _, ok := featureGates[name]; !ok {
+			logrus.Warnf("The feature gate %s is not a built-in feature gate. It only has an effect if a custom transformer uses it.", name)
+		}
+		featureGateValues[name] = enabled
+	}
+	return nil
+}
+
+// IsFeatureEnabled returns true if the feature gate was enabled by the user, or is enabled by default
+func IsFeatureEnabled(name string) bool {
+	featureGatesMutex.RLock()
+	defer featureGatesMutex.RUnlock()
+	if enabled, ok := featureGateValues[name]; ok {
+		return enabled
+	}
+	return featureGates[name].Default
+}
+
+// GetFeatureGatesUsage returns the description of the registered feature gates for the help of the flags
+func GetFeatureGatesUsage() string {
+	descriptions := []string{}
+	for _, gate := range GetFeatureGates() {
+		descriptions = append(descriptions, fmt.Sprintf("%s=true|false (default=%t): %s", gate.Name, gate.Default, gate.Description))
+	}
+	return strings.Join(descriptions, "\n")
+}
@@ -0,0 +1,73 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func resetFeatureGates(t *testing.T) {
+	featureGatesMutex.Lock()
+	oldGates, oldValues := featureGates, featureGateValues
+	featureGates, featureGateValues = map[string]FeatureGate{}, map[string]bool{}
+	featureGatesMutex.Unlock()
+	t.Cleanup(func() {
+		featureGatesMutex.Lock()
+		featureGates, featureGateValues = oldGates, oldValues
+		featureGatesMutex.Unlock()
+	})
+}
+
+func TestFeatureGates(t *testing.T) {
+	resetFeatureGates(t)
+	RegisterFeatureGate(FeatureGate{Name: "NewEngine", Description: "Use the new engine"})
+	RegisterFeatureGate(FeatureGate{Name: "Caching", Description: "Cache the results", Default: true})
+
+	if IsFeatureEnabled("NewEngine") || !IsFeatureEnabled("Caching") || IsFeatureEnabled("Unknown") {
+		t.Fatalf("expected the feature gates to have their default values")
+	}
+	if err := SetFeatureGates([]string{"NewEngine", " Caching = false ", "", "Custom=true"}); err != nil {
+		t.Fatalf("failed to set the feature gates. Error: %q", err)
+	}
+	if !IsFeatureEnabled("NewEngine") || IsFeatureEnabled("Caching") || !IsFeatureEnabled("Custom") {
+		t.Fatalf("expected the feature gates to have the values set by the user")
+	}
+	if err := SetFeatureGates([]string{"NewEngine=false", "Caching=maybe"}); err == nil {
+		t.Fatalf("expected an error for an invalid value of a feature gate")
+	}
+	if !IsFeatureEnabled("NewEngine") {
+		t.Fatalf("expected the feature gates to be unchanged when a value is invalid")
+	}
+
+	names := []string{}
+	for _, gate := range GetFeatureGates() {
+		names = append(names, gate.Name)
+	}
+	if diff := cmp.Diff([]string{"Caching", "NewEngine"}, names); diff != "" {
+		t.Fatalf("wrong registered feature gates. Difference:\n%s", diff)
+	}
+	expectedUsage := "Caching=true|false (default=true): Cache the results\nNewEngine=true|false (default=false): Use the new engine"
+	if usage := GetFeatureGatesUsage(); usage != expectedUsage {
+		t.Fatalf("wrong usage of the feature gates. Expected:\n%s\nActual:\n%s", expectedUsage, usage)
+	}
+	if strings.Contains(GetFeatureGatesUsage(), "Custom") {
+		t.Fatalf("expected only the registered feature gates in the usage")
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"sort"
+)
+
+// GetClosestMatches returns at most max candidates that are closest to the string, by edit distance.
+// Candidates that are further away than half the length of the string are not returned.
+func GetClosestMatches(s string, candidates []string, max int) []string {
+	type match struct {
+		candidate string
+		distance  int
+	}
+	matches := []match{}
+	for _, candidate := range candidates {
+		distance := getEditDistance(s, candidate)
+		if distance > len(s)/2 {
+			continue
+		}
+		matches = append(matches, match{candidate: candidate, distance: distance})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].candidate < matches[j].candidate
+	})
+	closest := []string{}
+	for i := 0; i < len(matches) && i < max; i++ {
+		closest = append(closest, matches[i].candidate)
+	}
+	return closest
+}
+
+// getEditDistance returns the Levenshtein distance between the two strings
+func getEditDistance(s1, s2 string) int {
+	r1, r2 := []rune(s1), []rune(s2)
+	prev := make([]int, len(r2)+1)
+	curr := make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(r2)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -16,7 +16,23 @@
 
 package common
 
+import "sort"
+
 var (
 	// ProjectName stores the project name during an execution
 	ProjectName = DefaultProjectName
+	// ServiceWaves stores the migration wave of each service during an execution
+	ServiceWaves = map[string]string{}
 )
+
+// GetWaves returns the migration waves of the services in sorted order
+func GetWaves() []string {
+	waves := []string{}
+	for _, wave := range ServiceWaves {
+		if !IsPresent(waves, wave) {
+			waves = append(waves, wave)
+		}
+	}
+	sort.Strings(waves)
+	return waves
+}
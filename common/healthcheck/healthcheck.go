@@ -0,0 +1,309 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// testNone disables the health check of the base image
+	testNone = "NONE"
+	// testCmd runs the rest of the test as a command
+	testCmd = "CMD"
+	// testCmdShell runs the rest of the test using the shell of the container
+	testCmdShell = "CMD-SHELL"
+	// The defaults of Docker, which are used when the health check does not set them, since those of Kubernetes are much shorter
+	defaultInterval = 30 * time.Second
+	defaultTimeout  = 30 * time.Second
+	defaultRetries  = 3
+)
+
+var (
+	// localHosts are the hosts that refer to the container itself
+	localHosts = map[string]bool{"": true, "localhost": true, "127.0.0.1": true, "0.0.0.0": true, "::1": true}
+	// requestFlags are the curl and wget flags that change the request, so that it cannot be done by an http probe
+	requestFlags = map[string]bool{
+		"-X": true, "--request": true, "-H": true, "--header": true, "-d": true, "--data": true, "--data-raw": true, "--data-binary": true,
+		"-u": true, "--user": true, "-F": true, "--form": true, "--post-data": true, "--method": true, "--http-user": true, "--unix-socket": true,
+	}
+	// argumentFlags are the curl, wget and nc flags that take an argument
+	argumentFlags = map[string]bool{
+		"-o": true, "--output": true, "-O": true, "--output-document": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+		"-w": true, "--write-out": true, "-t": true, "--tries": true, "-T": true, "--timeout": true, "--retry": true, "-A": true, "--user-agent": true,
+	}
+)
+
+// Check is a health check of a container in the source, like the healthcheck of a compose service or the HEALTHCHECK of a Dockerfile
+type Check struct {
+	// Test is the test of the health check, like [CMD-SHELL, curl -f http://localhost/health] or [CMD, /bin/check]
+	Test []string
+	// Interval, Timeout and StartPeriod are durations with units, like 1m30s, or numbers of seconds
+	Interval    string
+	Timeout     string
+	StartPeriod string
+	Retries     int
+}
+
+// GetProbe translates the health check into a probe. Tests that only request a local http endpoint or check a local port
+// become http and tcp probes, since those do not need curl, wget or nc in the image. It returns nil if the health check is disabled.
+func GetProbe(check Check) (*core.Probe, error) {
+	handler := GetHandler(check.Test)
+	if handler == nil {
+		return nil, nil
+	}
+	probe := &core.Probe{
+		ProbeHandler:     *handler,
+		PeriodSeconds:    int32(defaultInterval.Seconds()),
+		TimeoutSeconds:   int32(defaultTimeout.Seconds()),
+		FailureThreshold: defaultRetries,
+	}
+	// the durations that are not set or are zero use the defaults, like in Docker
+	durations := []struct {
+		name     string
+		duration string
+		seconds  *int32
+	}{
+		{name: "interval", duration: check.Interval, seconds: &probe.PeriodSeconds},
+		{name: "timeout", duration: check.Timeout, seconds: &probe.TimeoutSeconds},
+		{name: "start period", duration: check.StartPeriod, seconds: &probe.InitialDelaySeconds},
+	}
+	for _, d := range durations {
+		if d.duration == "" {
+			continue
+		}
+		seconds, err := ParseSeconds(d.duration)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the %s of the health check. Error: %q", d.name, err)
+		}
+		if seconds > 0 {
+			*d.seconds = seconds
+		}
+	}
+	if check.Retries > 0 {
+		probe.FailureThreshold = int32(check.Retries)
+	}
+	return probe, nil
+}
+
+// GetHandler translates the test of a health check into the handler of a probe. It returns nil if the test is empty or NONE.
+func GetHandler(test []string) *core.ProbeHandler {
+	if len(test) == 0 {
+		return nil
+	}
+	args := []string{}
+	shell := false
+	switch strings.ToUpper(test[0]) {
+	case testNone:
+		return nil
+	case testCmd:
+		args = test[1:]
+	case testCmdShell:
+		shell = true
+		args = test[1:]
+	default:
+		// a test without a type is run using the shell
+		shell = true
+		args = test
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	command := strings.Join(args, " ")
+	if shell {
+		args = splitShellCommand(command)
+	}
+	if args != nil {
+		if handler := getHTTPHandler(args); handler != nil {
+			return handler
+		}
+		if handler := getTCPHandler(args); handler != nil {
+			return handler
+		}
+	}
+	if shell {
+		return &core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"/bin/sh", "-c", command}}}
+	}
+	return &core.ProbeHandler{Exec: &core.ExecAction{Command: test[1:]}}
+}
+
+// GetHTTPHandler returns the handler of an http probe for the path and port
+func GetHTTPHandler(path string, port int32) *core.ProbeHandler {
+	if path == "" {
+		path = "/"
+	}
+	return &core.ProbeHandler{HTTPGet: &core.HTTPGetAction{Path: path, Port: intstr.FromInt(int(port)), Scheme: core.URISchemeHTTP}}
+}
+
+// GetTCPHandler returns the handler of a tcp probe for the port
+func GetTCPHandler(port int32) *core.ProbeHandler {
+	return &core.ProbeHandler{TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(int(port))}}
+}
+
+// ParseSeconds parses a duration with units, like 1m30s or 500ms, or a number of seconds, into seconds.
+// Fractions of a second are rounded up, since the probes cannot be shorter than a second.
+func ParseSeconds(duration string) (int32, error) {
+	duration = strings.TrimSpace(duration)
+	seconds, err := strconv.ParseFloat(duration, 64)
+	if err != nil {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return 0, fmt.Errorf("the duration %s is invalid. Error: %q", duration, err)
+		}
+		seconds = d.Seconds()
+	}
+	if seconds < 0 {
+		return 0, fmt.Errorf("the duration %s is negative", duration)
+	}
+	if seconds == 0 {
+		return 0, nil
+	}
+	return int32(math.Ceil(seconds)), nil
+}
+
+// SetProbes sets the probe as the liveness and readiness probes of the container, unless it already has them
+func SetProbes(container *core.Container, probe *core.Probe) {
+	if probe == nil {
+		return
+	}
+	if container.LivenessProbe == nil {
+		liveness := *probe
+		container.LivenessProbe = &liveness
+	}
+	if container.ReadinessProbe == nil {
+		readiness := *probe
+		container.ReadinessProbe = &readiness
+	}
+}
+
+// splitShellCommand splits a simple shell command into its arguments. It returns nil if the command uses other features
+// of the shell, except for a trailing "|| exit 1", so that only the commands that do nothing else are translated.
+func splitShellCommand(command string) []string {
+	command = strings.TrimSpace(command)
+	for _, suffix := range []string{"|| exit 1", "||exit 1", "|| exit", "|| false"} {
+		command = strings.TrimSpace(strings.TrimSuffix(command, suffix))
+	}
+	if strings.ContainsAny(command, "|&;<>$`(){}\\\n") {
+		return nil
+	}
+	args := []string{}
+	for _, field := range strings.Fields(command) {
+		if strings.ContainsAny(field, `"'`) {
+			unquoted := strings.Trim(field, `"'`)
+			if strings.ContainsAny(unquoted, `"'`) {
+				return nil
+			}
+			field = unquoted
+		}
+		args = append(args, field)
+	}
+	return args
+}
+
+// getHTTPHandler returns an http probe for a curl or wget command that only gets a local url
+func getHTTPHandler(args []string) *core.ProbeHandler {
+	if tool := filepath.Base(args[0]); tool != "curl" && tool != "wget" {
+		return nil
+	}
+	positionals, ok := getPositionalArgs(args[1:])
+	if !ok || len(positionals) != 1 {
+		return nil
+	}
+	rawURL := positionals[0]
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !localHosts[u.Hostname()] || u.User != nil {
+		return nil
+	}
+	port := 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if u.Port() != "" {
+		if port, err = strconv.Atoi(u.Port()); err != nil {
+			return nil
+		}
+	}
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	handler := GetHTTPHandler(path, int32(port))
+	if u.Scheme == "https" {
+		handler.HTTPGet.Scheme = core.URISchemeHTTPS
+	}
+	return handler
+}
+
+// getTCPHandler returns a tcp probe for a nc command that only checks whether a local port is open
+func getTCPHandler(args []string) *core.ProbeHandler {
+	if tool := filepath.Base(args[0]); tool != "nc" && tool != "ncat" && tool != "netcat" {
+		return nil
+	}
+	scan := false
+	for _, arg := range args[1:] {
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, "z") {
+			scan = true
+		}
+	}
+	positionals, ok := getPositionalArgs(args[1:])
+	if !scan || !ok || len(positionals) != 2 || !localHosts[strings.Trim(positionals[0], "[]")] {
+		return nil
+	}
+	port, err := cast.ToInt32E(positionals[1])
+	if err != nil || port <= 0 {
+		return nil
+	}
+	return GetTCPHandler(port)
+}
+
+// getPositionalArgs returns the arguments that are not flags. It returns false if one of the flags changes the request.
+func getPositionalArgs(args []string) ([]string, bool) {
+	positionals := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "-" {
+			// the standard output, like in wget -O -
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			positionals = append(positionals, arg)
+			continue
+		}
+		name := strings.SplitN(arg, "=", 2)[0]
+		if requestFlags[name] || (!strings.HasPrefix(arg, "--") && len(arg) > 2 && requestFlags[arg[:2]]) {
+			return nil, false
+		}
+		if argumentFlags[arg] || (!strings.HasPrefix(arg, "--") && argumentFlags["-"+arg[len(arg)-1:]]) {
+			// the argument of the flag is the next argument
+			i++
+		}
+	}
+	return positionals, true
+}
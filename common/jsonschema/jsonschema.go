@@ -0,0 +1,175 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// SchemaVersion is the JSON schema draft used for the generated schemas
+	SchemaVersion = "http://json-schema.org/draft-04/schema#"
+)
+
+// Schema represents a JSON schema document
+type Schema map[string]interface{}
+
+var (
+	yamlMarshalerType = reflect.TypeOf((*yaml.Marshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// GenerateSchema generates a JSON schema for the given type.
+// Object properties are named using the yaml struct tags, since that is how configs get serialized.
+// Types with custom marshalling logic are allowed to have any value.
+func GenerateSchema(t reflect.Type, title string) Schema {
+	schema := generate(t, map[reflect.Type]bool{})
+	schema["$schema"] = SchemaVersion
+	if title != "" {
+		schema["title"] = title
+	}
+	return schema
+}
+
+// Validate validates the object against the schema and returns all the violations as a single error
+func Validate(schema Schema, obj interface{}) error {
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(map[string]interface{}(schema)), gojsonschema.NewGoLoader(obj))
+	if err != nil {
+		return fmt.Errorf("failed to validate the object against the schema. Error: %q", err)
+	}
+	if result.Valid() {
+		return nil
+	}
+	violations := []string{}
+	for _, resultErr := range result.Errors() {
+		violations = append(violations, resultErr.String())
+	}
+	return fmt.Errorf("%s", strings.Join(violations, "; "))
+}
+
+// generate generates the schema of the type.
+// Every type accepts null, since the decoders leave the zero value in the fields that are null.
+func generate(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if hasCustomMarshaller(t) {
+		return Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return withType("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return withType("integer")
+	case reflect.Float32, reflect.Float64:
+		return withType("number")
+	case reflect.String:
+		return withType("string")
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// bytes are a base64 string in json and a sequence of integers when marshalled by yaml
+			return Schema{"type": []interface{}{"string", "array", "null"}, "items": withType("integer")}
+		}
+		schema := withType("array")
+		schema["items"] = generate(t.Elem(), seen)
+		return schema
+	case reflect.Map:
+		schema := withType("object")
+		schema["additionalProperties"] = generate(t.Elem(), seen)
+		return schema
+	case reflect.Struct:
+		if seen[t] {
+			// recursive types are not expanded further
+			return Schema{}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		properties := Schema{}
+		addStructProperties(t, properties, seen)
+		schema := withType("object")
+		schema["properties"] = properties
+		return schema
+	}
+	return Schema{}
+}
+
+func addStructProperties(t reflect.Type, properties Schema, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, inline := parseYamlTag(field)
+		if name == "-" {
+			continue
+		}
+		if inline {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct && !hasCustomMarshaller(fieldType) {
+				addStructProperties(fieldType, properties, seen)
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		properties[name] = generate(field.Type, seen)
+	}
+}
+
+func parseYamlTag(field reflect.StructField) (name string, inline bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "-", false
+	}
+	parts := strings.Split(tag, ",")
+	for _, flag := range parts[1:] {
+		if flag == "inline" {
+			inline = true
+		}
+	}
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, inline
+}
+
+func hasCustomMarshaller(t reflect.Type) bool {
+	for _, marshalerType := range []reflect.Type{yamlMarshalerType, jsonMarshalerType, textMarshalerType} {
+		if t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) {
+			return true
+		}
+	}
+	return false
+}
+
+func withType(jsonType string) Schema {
+	return Schema{"type": []interface{}{jsonType, "null"}}
+}
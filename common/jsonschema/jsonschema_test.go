@@ -0,0 +1,142 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testNested struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type testEmbedded struct {
+	Region string `yaml:"region"`
+}
+
+type testTree struct {
+	Value    string     `yaml:"value"`
+	Children []testTree `yaml:"children"`
+}
+
+type testMarshalled struct{}
+
+func (testMarshalled) MarshalYAML() (interface{}, error) {
+	return "marshalled", nil
+}
+
+type testConfig struct {
+	testEmbedded `yaml:",inline"`
+	Name         string                `yaml:"name"`
+	Count        int                   `yaml:"count"`
+	Ratio        float64               `yaml:"ratio"`
+	Data         []byte                `yaml:"data"`
+	Labels       map[string]string     `yaml:"labels"`
+	Nested       *testNested           `yaml:"nested"`
+	Ignored      string                `yaml:"-"`
+	Untagged     string                ``
+	Custom       testMarshalled        `yaml:"custom"`
+	Items        map[string]testNested `yaml:"items"`
+	hidden       string
+}
+
+func TestGenerateSchema(t *testing.T) {
+	t.Run("struct with all kinds of fields", func(t *testing.T) {
+		want := Schema{
+			"$schema": SchemaVersion,
+			"title":   "TestConfig",
+			"type":    []interface{}{"object", "null"},
+			"properties": Schema{
+				"region":   withType("string"),
+				"name":     withType("string"),
+				"count":    withType("integer"),
+				"ratio":    withType("number"),
+				"data":     Schema{"type": []interface{}{"string", "array", "null"}, "items": withType("integer")},
+				"labels":   Schema{"type": []interface{}{"object", "null"}, "additionalProperties": withType("string")},
+				"nested":   Schema{"type": []interface{}{"object", "null"}, "properties": Schema{"enabled": withType("boolean")}},
+				"untagged": withType("string"),
+				"custom":   Schema{},
+				"items": Schema{
+					"type":                 []interface{}{"object", "null"},
+					"additionalProperties": Schema{"type": []interface{}{"object", "null"}, "properties": Schema{"enabled": withType("boolean")}},
+				},
+			},
+		}
+		actual := GenerateSchema(reflect.TypeOf(testConfig{}), "TestConfig")
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("the schema is incorrect. Difference:\n%s", cmp.Diff(want, actual))
+		}
+	})
+
+	t.Run("recursive struct", func(t *testing.T) {
+		want := Schema{
+			"$schema": SchemaVersion,
+			"type":    []interface{}{"object", "null"},
+			"properties": Schema{
+				"value":    withType("string"),
+				"children": Schema{"type": []interface{}{"array", "null"}, "items": Schema{}},
+			},
+		}
+		actual := GenerateSchema(reflect.TypeOf(testTree{}), "")
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("the schema is incorrect. Difference:\n%s", cmp.Diff(want, actual))
+		}
+	})
+
+	t.Run("pointer to a map of interfaces", func(t *testing.T) {
+		want := Schema{
+			"$schema":              SchemaVersion,
+			"title":                "TemplateConfig",
+			"type":                 []interface{}{"object", "null"},
+			"additionalProperties": Schema{},
+		}
+		actual := GenerateSchema(reflect.TypeOf(&map[string]interface{}{}), "TemplateConfig")
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("the schema is incorrect. Difference:\n%s", cmp.Diff(want, actual))
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	schema := GenerateSchema(reflect.TypeOf(testConfig{}), "TestConfig")
+	testcases := []struct {
+		name    string
+		obj     interface{}
+		wantErr bool
+	}{
+		{name: "valid", obj: map[string]interface{}{"name": "svc", "count": 2, "labels": map[string]interface{}{"app": "svc"}}},
+		{name: "null fields", obj: map[string]interface{}{"name": nil, "nested": nil}},
+		{name: "any value for a field with a custom marshaller", obj: map[string]interface{}{"custom": []interface{}{1, "two"}}},
+		{name: "wrong type", obj: map[string]interface{}{"count": "two"}, wantErr: true},
+		{name: "wrong type inside a map", obj: map[string]interface{}{"items": map[string]interface{}{"a": map[string]interface{}{"enabled": "yes"}}}, wantErr: true},
+		{name: "not an object", obj: []interface{}{"svc"}, wantErr: true},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := Validate(schema, testcase.obj)
+			if testcase.wantErr && err == nil {
+				t.Fatal("expected the object to be invalid")
+			}
+			if !testcase.wantErr && err != nil {
+				t.Fatalf("expected the object to be valid. Error: %q", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	rootContext      = "(root)"
+	contextDelimiter = "\x00"
+)
+
+// Violation is a single schema violation along with its location in the yaml document
+type Violation struct {
+	Field       string `yaml:"field" json:"field"`
+	Description string `yaml:"description" json:"description"`
+	Line        int    `yaml:"line,omitempty" json:"line,omitempty"`
+	Column      int    `yaml:"column,omitempty" json:"column,omitempty"`
+}
+
+// String returns the violation in the line:column: field: description format
+func (v Violation) String() string {
+	if v.Line == 0 {
+		return fmt.Sprintf("%s: %s", v.Field, v.Description)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", v.Line, v.Column, v.Field, v.Description)
+}
+
+// Strict returns a copy of the schema that does not allow properties other than the ones it declares
+func Strict(schema Schema) Schema {
+	return strict(schema).(Schema)
+}
+
+func strict(value interface{}) interface{} {
+	switch v := value.(type) {
+	case Schema:
+		newSchema := Schema{}
+		for key, val := range v {
+			properties, ok := val.(Schema)
+			if key != "properties" || !ok {
+				newSchema[key] = strict(val)
+				continue
+			}
+			newProperties := Schema{}
+			for name, property := range properties {
+				newProperties[name] = strict(property)
+			}
+			newSchema[key] = newProperties
+		}
+		if _, ok := v["properties"]; ok {
+			if _, ok := v["additionalProperties"]; !ok {
+				newSchema["additionalProperties"] = false
+			}
+		}
+		return newSchema
+	case []interface{}:
+		newValues := []interface{}{}
+		for _, val := range v {
+			newValues = append(newValues, strict(val))
+		}
+		return newValues
+	}
+	return value
+}
+
+// ValidateYaml validates the yaml document against the schema.
+// The violations are sorted by their location in the document.
+func ValidateYaml(schema Schema, yamlBytes []byte) ([]Violation, error) {
+	document := yaml.Node{}
+	if err := yaml.Unmarshal(yamlBytes, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse the yaml. Error: %q", err)
+	}
+	var obj interface{}
+	if err := document.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("failed to decode the yaml. Error: %q", err)
+	}
+	keyedSchema := withKeyedMaps(schema).(Schema)
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(map[string]interface{}(keyedSchema)), gojsonschema.NewGoLoader(obj))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate the yaml against the schema. Error: %q", err)
+	}
+	violations := []Violation{}
+	for _, resultErr := range result.Errors() {
+		path := strings.Split(resultErr.Context().String(contextDelimiter), contextDelimiter)
+		if len(path) > 0 && path[0] == rootContext {
+			path = path[1:]
+		}
+		if property, ok := resultErr.Details()["property"].(string); ok && resultErr.Type() == "additional_property_not_allowed" {
+			path = append(path, property)
+		}
+		if isCoercedToString(resultErr) || resultErr.Type() == "invalid_property_pattern" {
+			// the violations inside the keyed maps are reported on their own
+			continue
+		}
+		location := findNode(&document, path)
+		violation := Violation{Field: strings.Join(path, "."), Description: resultErr.Description()}
+		if violation.Field == "" {
+			violation.Field = rootContext
+		}
+		if location != nil {
+			violation.Line, violation.Column = location.Line, location.Column
+		}
+		violations = append(violations, violation)
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Line != violations[j].Line {
+			return violations[i].Line < violations[j].Line
+		}
+		return violations[i].Column < violations[j].Column
+	})
+	return violations, nil
+}
+
+// withKeyedMaps returns a copy of the schema where the maps, which only have additionalProperties,
+// use a pattern property matching every key instead. The violations inside pattern properties
+// have the key in their context, while the ones inside additionalProperties do not.
+func withKeyedMaps(value interface{}) interface{} {
+	switch v := value.(type) {
+	case Schema:
+		newSchema := Schema{}
+		for key, val := range v {
+			newSchema[key] = withKeyedMaps(val)
+		}
+		additionalProperties, ok := newSchema["additionalProperties"].(Schema)
+		_, hasProperties := v["properties"]
+		_, hasPatternProperties := v["patternProperties"]
+		if ok && !hasProperties && !hasPatternProperties {
+			delete(newSchema, "additionalProperties")
+			newSchema["patternProperties"] = Schema{"": additionalProperties}
+		}
+		return newSchema
+	case []interface{}:
+		newValues := []interface{}{}
+		for _, val := range v {
+			newValues = append(newValues, withKeyedMaps(val))
+		}
+		return newValues
+	}
+	return value
+}
+
+// isCoercedToString returns true if the violation is about a boolean or a number where a string is expected.
+// The yaml decoder accepts these for string fields, so they are not reported.
+func isCoercedToString(resultErr gojsonschema.ResultError) bool {
+	if resultErr.Type() != "invalid_type" {
+		return false
+	}
+	expected, ok := resultErr.Details()["expected"].(string)
+	if !ok || !strings.Contains(expected, "string") {
+		return false
+	}
+	switch resultErr.Value().(type) {
+	case bool, int, int64, uint64, float64, json.Number:
+		return true
+	}
+	return false
+}
+
+// findNode returns the location of the path in the document.
+// For mapping entries the location is the key node, since that is where the user needs to look.
+// If the path is not found, the location of the deepest node found along the path is returned.
+func findNode(document *yaml.Node, path []string) *yaml.Node {
+	current := resolveNode(document)
+	if current == nil {
+		return nil
+	}
+	location := current
+	for _, key := range path {
+		switch current.Kind {
+		case yaml.MappingNode:
+			found := false
+			for i := 0; i+1 < len(current.Content); i += 2 {
+				if current.Content[i].Value == key {
+					location, current = current.Content[i], resolveNode(current.Content[i+1])
+					found = true
+					break
+				}
+			}
+			if !found || current == nil {
+				return location
+			}
+		case yaml.SequenceNode:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(current.Content) {
+				return location
+			}
+			current = resolveNode(current.Content[idx])
+			if current == nil {
+				return location
+			}
+			location = current
+		default:
+			return location
+		}
+	}
+	return location
+}
+
+// resolveNode skips over document and alias nodes
+func resolveNode(node *yaml.Node) *yaml.Node {
+	for node != nil && (node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode) {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+			continue
+		}
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	return node
+}
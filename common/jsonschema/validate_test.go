@@ -0,0 +1,125 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type testArtifact struct {
+	Name  string            `yaml:"name"`
+	Ports []int             `yaml:"ports"`
+	Tags  map[string]string `yaml:"tags"`
+}
+
+type testPlan struct {
+	Kind     string                    `yaml:"kind"`
+	Services map[string][]testArtifact `yaml:"services"`
+}
+
+func TestValidateYaml(t *testing.T) {
+	schema := Strict(GenerateSchema(reflect.TypeOf(testPlan{}), "TestPlan"))
+	testcases := []struct {
+		name  string
+		input string
+		want  []Violation
+	}{
+		{
+			name:  "valid",
+			input: "kind: Plan\nservices:\n  svc:\n    - name: svc\n      ports: [8080]\n",
+			want:  []Violation{},
+		},
+		{
+			name:  "null for a string field",
+			input: "kind:\nservices:\n  svc:\n    - name:\n      tags:\n",
+			want:  []Violation{},
+		},
+		{
+			name:  "coerced to string",
+			input: "kind: 1\nservices:\n  svc:\n    - name: true\n",
+			want:  []Violation{},
+		},
+		{
+			name:  "invalid type inside a map",
+			input: "kind: Plan\nservices:\n  svc:\n    - name: svc\n      ports: [http]\n",
+			want:  []Violation{{Field: "services.svc.0.ports.0", Description: "Invalid type. Expected: [integer,null], given: string", Line: 5, Column: 15}},
+		},
+		{
+			name:  "unknown field",
+			input: "kind: Plan\nservices:\n  svc:\n    - name: svc\n      image: web\n",
+			want:  []Violation{{Field: "services.svc.0.image", Description: "Additional property image is not allowed", Line: 5, Column: 7}},
+		},
+		{
+			name:  "wrong type at the root",
+			input: "- a\n",
+			want:  []Violation{{Field: rootContext, Description: "Invalid type. Expected: [object,null], given: array", Line: 1, Column: 1}},
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			got, err := ValidateYaml(schema, []byte(testcase.input))
+			if err != nil {
+				t.Fatalf("failed to validate the yaml. Error: %q", err)
+			}
+			if !cmp.Equal(got, testcase.want) {
+				t.Fatalf("the violations are incorrect. Difference:\n%s", cmp.Diff(testcase.want, got))
+			}
+		})
+	}
+}
+
+func TestFindNode(t *testing.T) {
+	input := `services:
+  svc:
+    - name: web
+      ports:
+        - 8080
+alias: &a
+  key: value
+ref: *a
+`
+	document := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(input), &document); err != nil {
+		t.Fatalf("failed to parse the yaml. Error: %q", err)
+	}
+	testcases := []struct {
+		path   []string
+		line   int
+		column int
+	}{
+		{path: nil, line: 1, column: 1},
+		{path: []string{"services", "svc"}, line: 2, column: 3},
+		{path: []string{"services", "svc", "0", "name"}, line: 3, column: 7},
+		{path: []string{"services", "svc", "0", "ports", "0"}, line: 5, column: 11},
+		{path: []string{"services", "svc", "0", "missing"}, line: 3, column: 7},
+		{path: []string{"services", "svc", "3"}, line: 2, column: 3},
+		{path: []string{"ref", "key"}, line: 7, column: 3},
+	}
+	for _, testcase := range testcases {
+		location := findNode(&document, testcase.path)
+		if location == nil {
+			t.Fatalf("expected a location for the path %+v", testcase.path)
+		}
+		if location.Line != testcase.line || location.Column != testcase.column {
+			t.Fatalf("wrong location for the path %+v . Expected: %d:%d Actual: %d:%d", testcase.path, testcase.line, testcase.column, location.Line, location.Column)
+		}
+	}
+}
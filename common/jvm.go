@@ -0,0 +1,110 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// JavaToolOptionsEnvName is the environment variable that all JVMs read their options from
+	JavaToolOptionsEnvName = "JAVA_TOOL_OPTIONS"
+	// DefaultMaxRAMPercentage is the percentage of the memory limit of the container that is used for the heap.
+	// The rest is left for the metaspace, the thread stacks and the native memory of the JVM.
+	DefaultMaxRAMPercentage = 75
+)
+
+var (
+	javaVersionRegex     = regexp.MustCompile(`^(?:1\.)?(\d+)(?:\.\d+)*(?:[u_](\d+))?`)
+	jvmMemoryOptionRegex = regexp.MustCompile(`^-(?:Xmx\S+|XX:[+-]?(?:MaxRAMPercentage|InitialRAMPercentage|MinRAMPercentage|MaxRAMFraction|UseCGroupMemoryLimitForHeap|UseContainerSupport|UnlockExperimentalVMOptions)\S*)$`)
+)
+
+// GetJavaVersion returns the major and update versions of a java version like 1.8, 8u151, 1.8.0_151 or 17.
+// The update is -1 if the version does not have one.
+func GetJavaVersion(version string) (int, int, bool) {
+	matches := javaVersionRegex.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return 0, 0, false
+	}
+	major, _ := strconv.Atoi(matches[1])
+	update := -1
+	if matches[2] != "" {
+		update, _ = strconv.Atoi(matches[2])
+	}
+	return major, update, true
+}
+
+// GetJVMMemoryOptions returns the JVM flags that size the heap for the memory limit of the container.
+// JDK 10 and later, and 8u191 and later, detect the limit and the heap is set to a percentage of it.
+// JDK 9 and 8u131 to 8u190 only detect the limit with experimental flags, and older JDKs are not container aware,
+// so for those the heap is set explicitly from the memory limit (in bytes) when there is one.
+func GetJVMMemoryOptions(javaVersion string, memoryLimit int64) string {
+	major, update, ok := GetJavaVersion(javaVersion)
+	if !ok || major >= 10 || (major == 8 && (update < 0 || update >= 191)) {
+		return fmt.Sprintf("-XX:+UseContainerSupport -XX:MaxRAMPercentage=%d.0", DefaultMaxRAMPercentage)
+	}
+	if memoryLimit > 0 {
+		return fmt.Sprintf("-Xmx%dm", memoryLimit*DefaultMaxRAMPercentage/100/(1024*1024))
+	}
+	if major == 9 || (major == 8 && update >= 131) {
+		// MaxRAMFraction=2 uses half of the limit, since the fraction has to be a whole number
+		return "-XX:+UnlockExperimentalVMOptions -XX:+UseCGroupMemoryLimitForHeap -XX:MaxRAMFraction=2"
+	}
+	return ""
+}
+
+// ReplaceJVMMemoryOptions replaces the flags that size the heap in the JVM options, keeping the other flags
+func ReplaceJVMMemoryOptions(options, memoryOptions string) string {
+	kept := []string{}
+	for _, option := range strings.Fields(options) {
+		if !jvmMemoryOptionRegex.MatchString(option) {
+			kept = append(kept, option)
+		}
+	}
+	if memoryOptions != "" {
+		kept = append(kept, memoryOptions)
+	}
+	return strings.Join(kept, " ")
+}
+
+// GetJVMMaxHeap returns the max heap (in bytes) set using -Xmx in the JVM options, or 0 if it is not set
+func GetJVMMaxHeap(options string) int64 {
+	for _, option := range strings.Fields(options) {
+		if !strings.HasPrefix(option, "-Xmx") {
+			continue
+		}
+		value := strings.ToLower(strings.TrimPrefix(option, "-Xmx"))
+		multiplier := int64(1)
+		switch {
+		case strings.HasSuffix(value, "k"):
+			multiplier = 1024
+		case strings.HasSuffix(value, "m"):
+			multiplier = 1024 * 1024
+		case strings.HasSuffix(value, "g"):
+			multiplier = 1024 * 1024 * 1024
+		}
+		size, err := strconv.ParseInt(strings.TrimRight(value, "kmg"), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return size * multiplier
+	}
+	return 0
+}
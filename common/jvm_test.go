@@ -0,0 +1,98 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"testing"
+)
+
+func TestGetJavaVersion(t *testing.T) {
+	testCases := []struct {
+		version string
+		major   int
+		update  int
+		ok      bool
+	}{
+		{version: "1.8", major: 8, update: -1, ok: true},
+		{version: "8u151", major: 8, update: 151, ok: true},
+		{version: "1.8.0_191", major: 8, update: 191, ok: true},
+		{version: "17", major: 17, update: -1, ok: true},
+		{version: "11.0.2", major: 11, update: -1, ok: true},
+		{version: "latest", ok: false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.version, func(t *testing.T) {
+			major, update, ok := GetJavaVersion(testCase.version)
+			if ok != testCase.ok || (ok && (major != testCase.major || update != testCase.update)) {
+				t.Fatalf("wrong java version. Expected: %d %d %t Actual: %d %d %t", testCase.major, testCase.update, testCase.ok, major, update, ok)
+			}
+		})
+	}
+}
+
+func TestGetJVMMemoryOptions(t *testing.T) {
+	containerSupport := "-XX:+UseContainerSupport -XX:MaxRAMPercentage=75.0"
+	testCases := []struct {
+		name        string
+		version     string
+		memoryLimit int64
+		expected    string
+	}{
+		{name: "container aware jdk", version: "17", expected: containerSupport},
+		{name: "container aware jdk 8", version: "8u191", memoryLimit: 1024 * 1024 * 1024, expected: containerSupport},
+		{name: "unknown version", version: "latest", expected: containerSupport},
+		{name: "experimental support with a limit", version: "8u151", memoryLimit: 1024 * 1024 * 1024, expected: "-Xmx768m"},
+		{name: "experimental support without a limit", version: "1.8.0_151", expected: "-XX:+UnlockExperimentalVMOptions -XX:+UseCGroupMemoryLimitForHeap -XX:MaxRAMFraction=2"},
+		{name: "not container aware with a limit", version: "7", memoryLimit: 512 * 1024 * 1024, expected: "-Xmx384m"},
+		{name: "not container aware without a limit", version: "8u121", expected: ""},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := GetJVMMemoryOptions(testCase.version, testCase.memoryLimit); actual != testCase.expected {
+				t.Fatalf("wrong JVM memory options. Expected: %q Actual: %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReplaceJVMMemoryOptions(t *testing.T) {
+	options := "-Xmx2g -Dfile.encoding=UTF-8 -XX:MaxRAMFraction=4 -XX:+UseG1GC -XX:-UseContainerSupport"
+	expected := "-Dfile.encoding=UTF-8 -XX:+UseG1GC -Xmx384m"
+	if actual := ReplaceJVMMemoryOptions(options, "-Xmx384m"); actual != expected {
+		t.Fatalf("wrong JVM options. Expected: %q Actual: %q", expected, actual)
+	}
+	if actual := ReplaceJVMMemoryOptions("-Xms256m -Xmx1g", ""); actual != "-Xms256m" {
+		t.Fatalf("wrong JVM options. Expected: %q Actual: %q", "-Xms256m", actual)
+	}
+}
+
+func TestGetJVMMaxHeap(t *testing.T) {
+	testCases := map[string]int64{
+		"-Dfoo=bar":              0,
+		"-Xms64m -Xmx512m":       512 * 1024 * 1024,
+		"-Xmx2G":                 2 * 1024 * 1024 * 1024,
+		"-Xmx1024k":              1024 * 1024,
+		"-Xmx1048576":            1048576,
+		"-Xmxlots":               0,
+		"-Xmx256m -XX:+UseG1GC ": 256 * 1024 * 1024,
+	}
+	for options, expected := range testCases {
+		if actual := GetJVMMaxHeap(options); actual != expected {
+			t.Fatalf("wrong max heap for the options %q . Expected: %d Actual: %d", options, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// lockFileSuffix is the suffix of the lock files created next to the locked paths
+	lockFileSuffix = ".m2klock"
+)
+
+var (
+	// ForceUnlock removes the locks of earlier runs which did not finish cleanly
+	ForceUnlock = false
+	heldLocks   = map[string]bool{}
+	locksMutex  sync.Mutex
+)
+
+// lockInfo identifies the run holding a lock
+type lockInfo struct {
+	PID       int    `yaml:"pid"`
+	Hostname  string `yaml:"hostname"`
+	StartedAt string `yaml:"startedAt"`
+	Command   string `yaml:"command"`
+}
+
+// AcquireLock takes an advisory lock on the path by creating a lock file next to it, so that concurrent runs do not corrupt the same files.
+// It fails if another run holds the lock. If ForceUnlock is set, the lock of the other run is removed first.
+func AcquireLock(path string) error {
+	lockPath := getLockPath(path)
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+	if heldLocks[lockPath] {
+		return nil
+	}
+	if ForceUnlock {
+		if err := os.Remove(lockPath); err == nil {
+			logrus.Warnf("Removed the lock file at path %s", lockPath)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(lockPath), DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory of the lock file at path %s . Error: %q", lockPath, err)
+	}
+	f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, DefaultFilePermission)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("another run is in progress using %s : %s . If that run is no longer running, use --force-unlock to remove the lock file at path %s", path, getLockHolder(lockPath), lockPath)
+		}
+		return fmt.Errorf("failed to create the lock file at path %s . Error: %q", lockPath, err)
+	}
+	defer f.Close()
+	hostname, _ := os.Hostname()
+	info := lockInfo{PID: os.Getpid(), Hostname: hostname, StartedAt: time.Now().Format(time.RFC3339), Command: strings.Join(os.Args, " ")}
+	if err := yaml.NewEncoder(f).Encode(info); err != nil {
+		logrus.Debugf("failed to write the details of the run to the lock file at path %s . Error: %q", lockPath, err)
+	}
+	heldLocks[lockPath] = true
+	return nil
+}
+
+// ReleaseLock releases the lock on the path, if it is held by this run
+func ReleaseLock(path string) {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+	releaseLock(getLockPath(path))
+}
+
+// ReleaseLocks releases all the locks held by this run
+func ReleaseLocks() {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+	for lockPath := range heldLocks {
+		releaseLock(lockPath)
+	}
+}
+
+func releaseLock(lockPath string) {
+	if !heldLocks[lockPath] {
+		return
+	}
+	if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove the lock file at path %s . Error: %q", lockPath, err)
+	}
+	delete(heldLocks, lockPath)
+}
+
+func getLockPath(path string) string {
+	return strings.TrimSuffix(path, string(os.PathSeparator)) + lockFileSuffix
+}
+
+// getLockHolder describes the run holding the lock
+func getLockHolder(lockPath string) string {
+	lockBytes, err := os.ReadFile(lockPath)
+	if err != nil {
+		return "unknown run"
+	}
+	info := lockInfo{}
+	if err := yaml.Unmarshal(lockBytes, &info); err != nil || info.PID == 0 {
+		return "unknown run"
+	}
+	return fmt.Sprintf("process %d on host %s started at %s (%s)", info.PID, info.Hostname, info.StartedAt, info.Command)
+}
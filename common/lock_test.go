@@ -0,0 +1,139 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func resetLocks(t *testing.T) {
+	t.Cleanup(func() {
+		ReleaseLocks()
+		ForceUnlock = false
+	})
+}
+
+func TestAcquireLock(t *testing.T) {
+	resetLocks(t)
+	path := filepath.Join(t.TempDir(), "myproject") + string(os.PathSeparator)
+	lockPath := getLockPath(path)
+	if lockPath != strings.TrimSuffix(path, string(os.PathSeparator))+lockFileSuffix {
+		t.Fatalf("wrong path of the lock file for %s . Actual: %s", path, lockPath)
+	}
+	if err := AcquireLock(path); err != nil {
+		t.Fatalf("failed to acquire the lock on %s . Error: %q", path, err)
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("the lock file at path %s was not created. Error: %q", lockPath, err)
+	}
+	if err := AcquireLock(path); err != nil {
+		t.Fatalf("failed to acquire the lock held by this run again. Error: %q", err)
+	}
+	if holder := getLockHolder(lockPath); !strings.Contains(holder, "process ") {
+		t.Fatalf("the lock file does not describe this run. Actual: %s", holder)
+	}
+	ReleaseLock(path)
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Fatalf("the lock file at path %s was not removed. Error: %q", lockPath, err)
+	}
+	ReleaseLock(path)
+}
+
+func TestAcquireLockMissingParent(t *testing.T) {
+	resetLocks(t)
+	path := filepath.Join(t.TempDir(), "out", "nested", "myproject")
+	if err := AcquireLock(path); err != nil {
+		t.Fatalf("failed to acquire the lock on %s whose parent does not exist. Error: %q", path, err)
+	}
+	if _, err := os.Stat(getLockPath(path)); err != nil {
+		t.Fatalf("the lock file of %s was not created. Error: %q", path, err)
+	}
+}
+
+func TestAcquireLockHeldByAnotherRun(t *testing.T) {
+	resetLocks(t)
+	path := filepath.Join(t.TempDir(), "myproject")
+	lockPath := getLockPath(path)
+	if err := os.WriteFile(lockPath, []byte("pid: 4242\nhostname: buildhost\nstartedAt: 2021-01-01T00:00:00Z\ncommand: move2kube transform\n"), DefaultFilePermission); err != nil {
+		t.Fatalf("failed to create the lock file at path %s . Error: %q", lockPath, err)
+	}
+	err := AcquireLock(path)
+	if err == nil {
+		t.Fatalf("expected the lock held by another run to fail to be acquired")
+	}
+	if !strings.Contains(err.Error(), "process 4242 on host buildhost") {
+		t.Fatalf("the error does not describe the other run. Actual: %q", err)
+	}
+	ReleaseLocks()
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("the lock file of the other run was removed. Error: %q", err)
+	}
+	ForceUnlock = true
+	if err := AcquireLock(path); err != nil {
+		t.Fatalf("failed to force the lock on %s . Error: %q", path, err)
+	}
+	if holder := getLockHolder(lockPath); strings.Contains(holder, "4242") {
+		t.Fatalf("the lock file still describes the other run. Actual: %s", holder)
+	}
+}
+
+func TestReleaseLocks(t *testing.T) {
+	resetLocks(t)
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "plan.yaml"), filepath.Join(dir, "output")}
+	for _, path := range paths {
+		if err := AcquireLock(path); err != nil {
+			t.Fatalf("failed to acquire the lock on %s . Error: %q", path, err)
+		}
+	}
+	ReleaseLocks()
+	for _, path := range paths {
+		if _, err := os.Stat(getLockPath(path)); !os.IsNotExist(err) {
+			t.Fatalf("the lock file of %s was not removed. Error: %q", path, err)
+		}
+	}
+}
+
+func TestGetLockHolder(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "valid", content: "pid: 7\nhostname: h\nstartedAt: s\ncommand: c\n", expected: "process 7 on host h started at s (c)"},
+		{name: "empty", content: "", expected: "unknown run"},
+		{name: "invalid", content: "pid: [", expected: "unknown run"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			lockPath := filepath.Join(dir, testCase.name+lockFileSuffix)
+			if err := os.WriteFile(lockPath, []byte(testCase.content), DefaultFilePermission); err != nil {
+				t.Fatalf("failed to create the lock file at path %s . Error: %q", lockPath, err)
+			}
+			if actual := getLockHolder(lockPath); actual != testCase.expected {
+				t.Fatalf("wrong holder of the lock. Expected: %s Actual: %s", testCase.expected, actual)
+			}
+		})
+	}
+	if actual := getLockHolder(filepath.Join(dir, "missing"+lockFileSuffix)); actual != "unknown run" {
+		t.Fatalf("wrong holder of a missing lock. Actual: %s", actual)
+	}
+}
@@ -0,0 +1,311 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RunIDLogField is the field of the log entries that has the correlation ID of the run
+	RunIDLogField = "run"
+	// RunLogsDir is the directory in the output directory where the logs of the runs are saved
+	RunLogsDir = "logs"
+	// logSinkBufferSize is the number of log entries buffered for each sink before entries start getting dropped
+	logSinkBufferSize = 1024
+	// logSinkTimeout is the time given to each sink to send the buffered entries when the run ends
+	logSinkTimeout = 5 * time.Second
+)
+
+var (
+	logSinks      []*LogSinkHook
+	logSinksMutex sync.Mutex
+	runLogFile    *os.File
+)
+
+// NewRunID returns a new correlation ID for a run
+func NewRunID() string {
+	return uuid.New().String()
+}
+
+// RunIDHook adds the correlation ID of the run to all the log entries
+type RunIDHook struct {
+	runID string
+}
+
+// NewRunIDHook creates a hook that adds the correlation ID to the log entries
+func NewRunIDHook(runID string) *RunIDHook {
+	return &RunIDHook{runID: runID}
+}
+
+// Fire adds the correlation ID to the entry
+func (hook *RunIDHook) Fire(entry *logrus.Entry) error {
+	entry.Data[RunIDLogField] = hook.runID
+	return nil
+}
+
+// Levels returns the levels on which the hook gets called
+func (hook *RunIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// logSender sends a formatted log entry to a sink
+type logSender interface {
+	send(data []byte) error
+	close() error
+}
+
+// LogSinkHook ships the log entries to a remote sink in the background, so that logging does not block on the network
+type LogSinkHook struct {
+	sink      string
+	sender    logSender
+	formatter logrus.Formatter
+	entries   chan []byte
+	done      chan struct{}
+	dropped   int
+}
+
+// NewLogSinkHook creates a hook that ships the log entries to the sink.
+// The sink is a URL. http and https URLs receive each entry as JSON in a POST request.
+// syslog (UDP) and syslog+tcp URLs receive each entry as a syslog message, for example syslog://localhost:514
+func NewLogSinkHook(sink string) (*LogSinkHook, error) {
+	sinkURL, err := url.Parse(sink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the log sink %s as a URL. Error: %q", sink, err)
+	}
+	var sender logSender
+	var formatter logrus.Formatter = &logrus.JSONFormatter{}
+	switch sinkURL.Scheme {
+	case "http", "https":
+		sender = &httpLogSender{url: sink, client: &http.Client{Timeout: logSinkTimeout}}
+	case "syslog", "syslog+udp", "syslog+tcp":
+		network := "udp"
+		if sinkURL.Scheme == "syslog+tcp" {
+			network = "tcp"
+		}
+		conn, err := net.DialTimeout(network, sinkURL.Host, logSinkTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to the syslog sink %s . Error: %q", sink, err)
+		}
+		sender = &syslogSender{conn: conn}
+		formatter = &syslogFormatter{}
+	default:
+		return nil, fmt.Errorf("the log sink %s is not supported. Use a http, https, syslog or syslog+tcp URL", sink)
+	}
+	hook := &LogSinkHook{
+		sink:      sink,
+		sender:    sender,
+		formatter: formatter,
+		entries:   make(chan []byte, logSinkBufferSize),
+		done:      make(chan struct{}),
+	}
+	go hook.run()
+	logSinksMutex.Lock()
+	logSinks = append(logSinks, hook)
+	logSinksMutex.Unlock()
+	return hook, nil
+}
+
+// Fire queues the entry to be shipped to the sink
+func (hook *LogSinkHook) Fire(entry *logrus.Entry) error {
+	data, err := hook.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	select {
+	case hook.entries <- data:
+	default:
+		hook.dropped++
+	}
+	return nil
+}
+
+// Levels returns the levels on which the hook gets called
+func (hook *LogSinkHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (hook *LogSinkHook) run() {
+	defer close(hook.done)
+	failed := false
+	for data := range hook.entries {
+		if err := hook.sender.send(data); err != nil && !failed {
+			// logging the error would be shipped to the sink again
+			fmt.Fprintf(os.Stderr, "failed to ship the logs to the sink %s . Error: %q\n", hook.sink, err)
+			failed = true
+		}
+	}
+}
+
+func (hook *LogSinkHook) close() {
+	close(hook.entries)
+	select {
+	case <-hook.done:
+	case <-time.After(logSinkTimeout):
+		fmt.Fprintf(os.Stderr, "timed out while shipping the logs to the sink %s\n", hook.sink)
+	}
+	if hook.dropped > 0 {
+		fmt.Fprintf(os.Stderr, "%d log entries were not shipped to the sink %s since it was too slow\n", hook.dropped, hook.sink)
+	}
+	if err := hook.sender.close(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to close the log sink %s . Error: %q\n", hook.sink, err)
+	}
+}
+
+// CloseLogSinks ships the remaining log entries to the sinks and closes them.
+// It is safe to call it more than once.
+func CloseLogSinks() {
+	logSinksMutex.Lock()
+	sinks := logSinks
+	logSinks = nil
+	logSinksMutex.Unlock()
+	for _, hook := range sinks {
+		hook.close()
+	}
+}
+
+type httpLogSender struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpLogSender) send(data []byte) error {
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("the sink returned the status %s", resp.Status)
+	}
+	return nil
+}
+
+func (*httpLogSender) close() error {
+	return nil
+}
+
+type syslogSender struct {
+	conn net.Conn
+}
+
+func (s *syslogSender) send(data []byte) error {
+	_, err := s.conn.Write(data)
+	return err
+}
+
+func (s *syslogSender) close() error {
+	return s.conn.Close()
+}
+
+// syslogFormatter formats the log entries as RFC 5424 syslog messages
+type syslogFormatter struct{}
+
+// Format formats the entry as a syslog message
+func (*syslogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	// facility user (1) and the severity of the level
+	severities := map[logrus.Level]int{
+		logrus.PanicLevel: 0,
+		logrus.FatalLevel: 2,
+		logrus.ErrorLevel: 3,
+		logrus.WarnLevel:  4,
+		logrus.InfoLevel:  6,
+		logrus.DebugLevel: 7,
+		logrus.TraceLevel: 7,
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	fields := []string{}
+	for key, value := range entry.Data {
+		fields = append(fields, fmt.Sprintf("%s=%v", key, value))
+	}
+	message := entry.Message
+	if len(fields) > 0 {
+		message += " " + strings.Join(fields, " ")
+	}
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %d - - %s\n", 8+severities[entry.Level], entry.Time.Format(time.RFC3339), hostname, "move2kube", os.Getpid(), message)), nil
+}
+
+// runLogHook writes the log entries of the run to a file
+type runLogHook struct {
+	writer    io.Writer
+	formatter logrus.Formatter
+	mutex     sync.Mutex
+}
+
+// Fire writes the entry to the file
+func (hook *runLogHook) Fire(entry *logrus.Entry) error {
+	data, err := hook.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	_, err = hook.writer.Write(data)
+	return err
+}
+
+// Levels returns the levels on which the hook gets called
+func (hook *runLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// StartRunLog starts recording the logs of the run, so that they can be saved later using SaveRunLog
+func StartRunLog() error {
+	f, err := os.CreateTemp(TempPath, "run-*.log")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file to record the logs of the run. Error: %q", err)
+	}
+	runLogFile = f
+	logrus.AddHook(&runLogHook{writer: f, formatter: &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}})
+	return nil
+}
+
+// SaveRunLog copies the logs of the run recorded so far to the logs directory in the output directory.
+// The file is named after the correlation ID of the run.
+func SaveRunLog(outputPath, runID string) (string, error) {
+	if runLogFile == nil {
+		return "", nil
+	}
+	logsDir := filepath.Join(outputPath, RunLogsDir)
+	if err := os.MkdirAll(logsDir, DefaultDirectoryPermission); err != nil {
+		return "", fmt.Errorf("failed to create the logs directory at path %s . Error: %q", logsDir, err)
+	}
+	logPath := filepath.Join(logsDir, runID+".log")
+	if err := runLogFile.Sync(); err != nil {
+		return "", fmt.Errorf("failed to flush the logs of the run to the file at path %s . Error: %q", runLogFile.Name(), err)
+	}
+	if err := CopyFile(logPath, runLogFile.Name()); err != nil {
+		return "", fmt.Errorf("failed to copy the logs of the run to the file at path %s . Error: %q", logPath, err)
+	}
+	return logPath, nil
+}
@@ -0,0 +1,202 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestLogger(hooks ...logrus.Hook) (*logrus.Logger, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.Out = out
+	logger.Formatter = &logrus.JSONFormatter{}
+	for _, hook := range hooks {
+		logger.AddHook(hook)
+	}
+	return logger, out
+}
+
+func TestNewRunID(t *testing.T) {
+	first := NewRunID()
+	second := NewRunID()
+	if first == "" || first == second {
+		t.Fatalf("expected unique non empty run ids. Actual: %s and %s", first, second)
+	}
+}
+
+func TestRunIDHook(t *testing.T) {
+	logger, out := newTestLogger(NewRunIDHook("run-1"))
+	logger.Info("hello")
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse the log entry %s . Error: %q", out.String(), err)
+	}
+	if entry[RunIDLogField] != "run-1" {
+		t.Fatalf("expected the log entry to have the run id run-1. Actual: %+v", entry)
+	}
+}
+
+func TestNewLogSinkHook(t *testing.T) {
+	t.Run("unsupported sink", func(t *testing.T) {
+		if _, err := NewLogSinkHook("ftp://localhost/logs"); err == nil {
+			t.Fatalf("expected an error for an unsupported log sink")
+		}
+	})
+
+	t.Run("http sink", func(t *testing.T) {
+		var mutex sync.Mutex
+		bodies := []map[string]interface{}{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("failed to read the request body. Error: %q", err)
+				return
+			}
+			entry := map[string]interface{}{}
+			if err := json.Unmarshal(body, &entry); err != nil {
+				t.Errorf("failed to parse the log entry %s . Error: %q", body, err)
+				return
+			}
+			mutex.Lock()
+			bodies = append(bodies, entry)
+			mutex.Unlock()
+		}))
+		defer server.Close()
+		hook, err := NewLogSinkHook(server.URL)
+		if err != nil {
+			t.Fatalf("failed to create the log sink hook. Error: %q", err)
+		}
+		logger, _ := newTestLogger(NewRunIDHook("run-2"), hook)
+		logger.Info("first")
+		logger.Warn("second")
+		CloseLogSinks()
+		// closing again must be a no-op
+		CloseLogSinks()
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(bodies) != 2 {
+			t.Fatalf("expected the sink to receive 2 entries. Actual: %+v", bodies)
+		}
+		for i, message := range []string{"first", "second"} {
+			if bodies[i]["msg"] != message || bodies[i][RunIDLogField] != "run-2" {
+				t.Fatalf("expected the entry %d to have the message %s and the run id run-2. Actual: %+v", i, message, bodies[i])
+			}
+		}
+	})
+
+	t.Run("syslog sink", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen on a UDP port. Error: %q", err)
+		}
+		defer conn.Close()
+		hook, err := NewLogSinkHook("syslog://" + conn.LocalAddr().String())
+		if err != nil {
+			t.Fatalf("failed to create the log sink hook. Error: %q", err)
+		}
+		logger, _ := newTestLogger(hook)
+		logger.Warn("disk is almost full")
+		CloseLogSinks()
+		if err := conn.SetReadDeadline(time.Now().Add(logSinkTimeout)); err != nil {
+			t.Fatalf("failed to set the read deadline. Error: %q", err)
+		}
+		buf := make([]byte, 4096)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("failed to read the syslog message. Error: %q", err)
+		}
+		message := string(buf[:n])
+		if !strings.HasPrefix(message, "<12>1 ") || !strings.Contains(message, " move2kube ") || !strings.HasSuffix(message, "disk is almost full\n") {
+			t.Fatalf("unexpected syslog message %q", message)
+		}
+	})
+}
+
+func TestSyslogFormatter(t *testing.T) {
+	entry := &logrus.Entry{
+		Time:    time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   logrus.ErrorLevel,
+		Message: "failed",
+		Data:    logrus.Fields{RunIDLogField: "run-3"},
+	}
+	data, err := (&syslogFormatter{}).Format(entry)
+	if err != nil {
+		t.Fatalf("failed to format the entry. Error: %q", err)
+	}
+	message := string(data)
+	if !strings.HasPrefix(message, "<11>1 2022-01-02T03:04:05Z ") {
+		t.Fatalf("expected the message to have the priority of an error and the timestamp. Actual: %q", message)
+	}
+	if !strings.HasSuffix(message, " - - failed run=run-3\n") {
+		t.Fatalf("expected the message to end with the fields. Actual: %q", message)
+	}
+}
+
+func TestSaveRunLog(t *testing.T) {
+	oldTempPath := TempPath
+	oldHooks := logrus.StandardLogger().ReplaceHooks(logrus.LevelHooks{})
+	oldOut := logrus.StandardLogger().Out
+	t.Cleanup(func() {
+		logrus.StandardLogger().ReplaceHooks(oldHooks)
+		logrus.SetOutput(oldOut)
+		if runLogFile != nil {
+			runLogFile.Close()
+			runLogFile = nil
+		}
+		TempPath = oldTempPath
+	})
+	TempPath = t.TempDir()
+	outputPath := t.TempDir()
+
+	logPath, err := SaveRunLog(outputPath, "run-4")
+	if err != nil || logPath != "" {
+		t.Fatalf("expected no log file before the run log is started. Actual: %s Error: %q", logPath, err)
+	}
+
+	logrus.SetOutput(io.Discard)
+	if err := StartRunLog(); err != nil {
+		t.Fatalf("failed to start the run log. Error: %q", err)
+	}
+	logrus.Info("recorded in the run log")
+	logPath, err = SaveRunLog(outputPath, "run-4")
+	if err != nil {
+		t.Fatalf("failed to save the run log. Error: %q", err)
+	}
+	if expected := outputPath + "/" + RunLogsDir + "/run-4.log"; logPath != expected {
+		t.Fatalf("expected the run log at %s . Actual: %s", expected, logPath)
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read the run log. Error: %q", err)
+	}
+	if !strings.Contains(string(data), "recorded in the run log") {
+		t.Fatalf("expected the run log to have the entry. Actual: %s", data)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sudoUIDEnvVar = "SUDO_UID"
+	sudoGIDEnvVar = "SUDO_GID"
+)
+
+// ParseOwner parses a user and group like 1000:1000 . The group defaults to the user if it is not specified.
+func ParseOwner(owner string) (uid, gid int, err error) {
+	parts := strings.SplitN(owner, ":", 2)
+	uid, err = strconv.Atoi(parts[0])
+	if err != nil || uid < 0 {
+		return 0, 0, fmt.Errorf("the user id in %s is not valid. Specify a numeric user and group like 1000:1000", owner)
+	}
+	gid = uid
+	if len(parts) == 2 {
+		gid, err = strconv.Atoi(parts[1])
+		if err != nil || gid < 0 {
+			return 0, 0, fmt.Errorf("the group id in %s is not valid. Specify a numeric user and group like 1000:1000", owner)
+		}
+	}
+	return uid, gid, nil
+}
+
+// GetOutputOwner returns the user and group that should own the files written by move2kube.
+// It returns false if the files should be owned by the user running move2kube, which is always the case when it does not run as root.
+func GetOutputOwner() (uid, gid int, ok bool) {
+	if os.Geteuid() != 0 {
+		return 0, 0, false
+	}
+	if OutputOwner != "" {
+		uid, gid, err := ParseOwner(OutputOwner)
+		if err != nil {
+			logrus.Errorf("The output files are owned by root. Error: %q", err)
+			return 0, 0, false
+		}
+		return uid, gid, true
+	}
+	sudoUID, sudoGID := os.Getenv(sudoUIDEnvVar), os.Getenv(sudoGIDEnvVar)
+	if sudoUID == "" || sudoGID == "" {
+		return 0, 0, false
+	}
+	uid, gid, err := ParseOwner(sudoUID + ":" + sudoGID)
+	if err != nil {
+		logrus.Debugf("the user that ran move2kube using sudo is not valid. Error: %q", err)
+		return 0, 0, false
+	}
+	return uid, gid, true
+}
+
+// ChownToOutputOwner changes the owner of the path and the files in it to the output owner, when move2kube runs as root
+func ChownToOutputOwner(path string) error {
+	uid, gid, ok := GetOutputOwner()
+	if !ok {
+		return nil
+	}
+	logrus.Debugf("changing the owner of %s to %d:%d", path, uid, gid)
+	return filepath.WalkDir(path, func(p string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := os.Lchown(p, uid, gid); err != nil {
+			return fmt.Errorf("failed to change the owner of %s to %d:%d . Error: %q", p, uid, gid, err)
+		}
+		return nil
+	})
+}
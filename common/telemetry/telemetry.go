@@ -0,0 +1,247 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/konveyor/move2kube/types/info"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Off disables the usage summary. It is the default.
+	Off = "off"
+	// Local writes the usage summary to a file without submitting it
+	Local = "local"
+	// Submit writes the usage summary to a file and submits it using the registered reporters
+	Submit = "submit"
+	// UsageSummaryKind is the kind of the usage summary file
+	UsageSummaryKind types.Kind = "UsageSummary"
+	// reportTimeout is the time given to each reporter to submit the summary
+	reportTimeout = 10 * time.Second
+)
+
+var (
+	// Modes are the telemetry modes that can be selected
+	Modes = []string{Off, Local, Submit}
+)
+
+// UsageSummary is the anonymous summary of a run. It does not have the names of the services, the paths or the log messages.
+type UsageSummary struct {
+	types.TypeMeta `yaml:",inline" json:",inline"`
+	Spec           UsageSummarySpec `yaml:"spec" json:"spec"`
+}
+
+// UsageSummarySpec stores what was used in a run
+type UsageSummarySpec struct {
+	Command         string `yaml:"command" json:"command"`
+	Version         string `yaml:"version" json:"version"`
+	OS              string `yaml:"os" json:"os"`
+	Arch            string `yaml:"arch" json:"arch"`
+	DurationSeconds int64  `yaml:"durationSeconds" json:"durationSeconds"`
+	// Transformers is the number of runs of each class of transformer
+	Transformers map[string]int `yaml:"transformers" json:"transformers"`
+	// SourcePlatforms is the number of services detected by each class of transformer, like CloudFoundry or Maven
+	SourcePlatforms map[string]int `yaml:"sourcePlatforms" json:"sourcePlatforms"`
+	// ErrorCategories is the number of errors logged in each stage of the run, like plan or the class of the transformer that was running
+	ErrorCategories map[string]int `yaml:"errorCategories" json:"errorCategories"`
+}
+
+// Reporter submits the usage summaries, for example to the endpoint of a platform team
+type Reporter interface {
+	Report(ctx context.Context, summary UsageSummary) error
+}
+
+var (
+	mode        = Off
+	summaryPath string
+	startTime   time.Time
+	summary     UsageSummarySpec
+	// stage is the category of the errors logged now
+	stage          string
+	reporters      = map[string]Reporter{}
+	summaryMutex   sync.Mutex
+	finishOnce     sync.Once
+	reportersMutex sync.Mutex
+)
+
+// RegisterReporter registers a reporter that submits the usage summaries when the telemetry mode is submit
+func RegisterReporter(name string, reporter Reporter) {
+	reportersMutex.Lock()
+	defer reportersMutex.Unlock()
+	reporters[name] = reporter
+}
+
+// Start starts recording the usage summary of the command, which is written to the path when the run ends
+func Start(telemetryMode, command, path string) error {
+	if !common.IsPresent(Modes, telemetryMode) {
+		return fmt.Errorf("the telemetry mode %s is not supported. Supported modes are %+v", telemetryMode, Modes)
+	}
+	if telemetryMode == Off {
+		return nil
+	}
+	summaryMutex.Lock()
+	mode, summaryPath, startTime, stage = telemetryMode, path, time.Now(), command
+	summary = UsageSummarySpec{
+		Command:         command,
+		Version:         info.GetVersion(),
+		OS:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+		Transformers:    map[string]int{},
+		SourcePlatforms: map[string]int{},
+		ErrorCategories: map[string]int{},
+	}
+	summaryMutex.Unlock()
+	logrus.AddHook(&errorHook{})
+	// the summary is also written when the run fails
+	logrus.RegisterExitHandler(func() { Finish(context.Background()) })
+	return nil
+}
+
+// StartTransformerRun records a run of the class of transformer. The errors logged until the returned function is called are categorized under the class.
+func StartTransformerRun(class string) (done func()) {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	if mode == Off {
+		return func() {}
+	}
+	summary.Transformers[class]++
+	previousStage := stage
+	stage = "transformer/" + class
+	return func() {
+		summaryMutex.Lock()
+		defer summaryMutex.Unlock()
+		stage = previousStage
+	}
+}
+
+// RecordSourcePlatform records a service detected by the class of transformer
+func RecordSourcePlatform(class string) {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	if mode == Off {
+		return
+	}
+	summary.SourcePlatforms[class]++
+}
+
+// Finish writes the usage summary and submits it if the mode is submit. Only the first call has an effect.
+func Finish(ctx context.Context) {
+	finishOnce.Do(func() {
+		summaryMutex.Lock()
+		if mode == Off {
+			summaryMutex.Unlock()
+			return
+		}
+		summary.DurationSeconds = int64(time.Since(startTime).Seconds())
+		usageSummary := UsageSummary{
+			TypeMeta: types.TypeMeta{Kind: string(UsageSummaryKind), APIVersion: types.SchemeGroupVersion.String()},
+			Spec:     summary,
+		}
+		currentMode, path := mode, summaryPath
+		// the errors logged while finishing are not counted
+		mode = Off
+		summaryMutex.Unlock()
+		if err := common.WriteYaml(path, usageSummary); err != nil {
+			logrus.Warnf("Failed to write the usage summary to %s . Error: %q", path, err)
+		} else {
+			logrus.Infof("The anonymous usage summary of the run can be found at %s", path)
+		}
+		if currentMode != Submit {
+			return
+		}
+		reportersMutex.Lock()
+		names := []string{}
+		for name := range reporters {
+			names = append(names, name)
+		}
+		reportersMutex.Unlock()
+		if len(names) == 0 {
+			logrus.Warnf("The usage summary was not submitted since there are no reporters. Set an endpoint to submit it to.")
+			return
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			reportersMutex.Lock()
+			reporter := reporters[name]
+			reportersMutex.Unlock()
+			reportCtx, cancel := context.WithTimeout(ctx, reportTimeout)
+			if err := reporter.Report(reportCtx, usageSummary); err != nil {
+				logrus.Warnf("Failed to submit the usage summary using the reporter %s . Error: %q", name, err)
+			} else {
+				logrus.Debugf("submitted the usage summary using the reporter %s", name)
+			}
+			cancel()
+		}
+	})
+}
+
+// errorHook counts the errors logged in each stage of the run
+type errorHook struct{}
+
+// Fire counts the entry under the current stage
+func (*errorHook) Fire(*logrus.Entry) error {
+	summaryMutex.Lock()
+	defer summaryMutex.Unlock()
+	if mode != Off {
+		summary.ErrorCategories[stage]++
+	}
+	return nil
+}
+
+// Levels returns the levels on which the hook gets called
+func (*errorHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+// HTTPReporter submits the usage summaries as JSON in a POST request to an endpoint
+type HTTPReporter struct {
+	Endpoint string
+}
+
+// Report submits the usage summary to the endpoint
+func (r *HTTPReporter) Report(ctx context.Context, summary UsageSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the usage summary to json. Error: %q", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create the request to %s . Error: %q", r.Endpoint, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to submit the usage summary to %s . Error: %q", r.Endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the endpoint %s responded with the status %s", r.Endpoint, resp.Status)
+	}
+	return nil
+}
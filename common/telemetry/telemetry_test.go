@@ -0,0 +1,187 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+// resetTelemetry restores the state of the package and the hooks of the logger after the test
+func resetTelemetry(t *testing.T) {
+	t.Helper()
+	hooks := logrus.LevelHooks{}
+	for level, levelHooks := range logrus.StandardLogger().Hooks {
+		hooks[level] = append([]logrus.Hook{}, levelHooks...)
+	}
+	resetState := func() {
+		summaryMutex.Lock()
+		mode, summaryPath, stage, summary = Off, "", "", UsageSummarySpec{}
+		finishOnce = sync.Once{}
+		summaryMutex.Unlock()
+		reportersMutex.Lock()
+		reporters = map[string]Reporter{}
+		reportersMutex.Unlock()
+	}
+	resetState()
+	t.Cleanup(func() {
+		resetState()
+		logrus.StandardLogger().ReplaceHooks(hooks)
+	})
+}
+
+type testReporter struct {
+	summaries []UsageSummary
+	err       error
+}
+
+func (r *testReporter) Report(ctx context.Context, summary UsageSummary) error {
+	r.summaries = append(r.summaries, summary)
+	return r.err
+}
+
+func TestStart(t *testing.T) {
+	resetTelemetry(t)
+	if err := Start("always", "transform", filepath.Join(t.TempDir(), common.UsageSummaryFile)); err == nil {
+		t.Fatalf("expected the telemetry mode always to be invalid")
+	}
+	if err := Start(Off, "transform", filepath.Join(t.TempDir(), common.UsageSummaryFile)); err != nil {
+		t.Fatalf("failed to start the telemetry. Error: %q", err)
+	}
+	done := StartTransformerRun("Golang-Dockerfile")
+	done()
+	RecordSourcePlatform("Golang-Dockerfile")
+	if summary.Transformers != nil || summary.SourcePlatforms != nil {
+		t.Fatalf("expected nothing to be recorded when the telemetry is off. Actual: %+v", summary)
+	}
+}
+
+func TestFinish(t *testing.T) {
+	resetTelemetry(t)
+	path := filepath.Join(t.TempDir(), common.UsageSummaryFile)
+	if err := Start(Local, "transform", path); err != nil {
+		t.Fatalf("failed to start the telemetry. Error: %q", err)
+	}
+	reporter := &testReporter{}
+	RegisterReporter("test", reporter)
+	logrus.Errorf("an error before the transformers")
+	doneMaven := StartTransformerRun("Maven")
+	RecordSourcePlatform("Maven")
+	RecordSourcePlatform("Maven")
+	logrus.Errorf("an error in maven")
+	doneDockerfile := StartTransformerRun("Dockerfile")
+	logrus.Errorf("an error in the nested dockerfile")
+	logrus.Warnf("a warning is not counted")
+	doneDockerfile()
+	logrus.Errorf("another error in maven")
+	doneMaven()
+	StartTransformerRun("Maven")()
+	Finish(context.Background())
+	logrus.Errorf("an error after finishing")
+	Finish(context.Background())
+
+	actual := UsageSummary{}
+	if err := common.ReadMove2KubeYaml(path, &actual); err != nil {
+		t.Fatalf("failed to read the usage summary at %s . Error: %q", path, err)
+	}
+	if actual.Kind != string(UsageSummaryKind) {
+		t.Fatalf("wrong kind of the usage summary. Expected: %s Actual: %s", UsageSummaryKind, actual.Kind)
+	}
+	if actual.Spec.Command != "transform" || actual.Spec.OS == "" || actual.Spec.Arch == "" {
+		t.Fatalf("the run is not described in the usage summary. Actual: %+v", actual.Spec)
+	}
+	if diff := cmp.Diff(map[string]int{"Maven": 2, "Dockerfile": 1}, actual.Spec.Transformers); diff != "" {
+		t.Fatalf("wrong transformer runs. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]int{"Maven": 2}, actual.Spec.SourcePlatforms); diff != "" {
+		t.Fatalf("wrong source platforms. Difference:\n%s", diff)
+	}
+	expectedErrors := map[string]int{"transform": 1, "transformer/Maven": 2, "transformer/Dockerfile": 1}
+	if diff := cmp.Diff(expectedErrors, actual.Spec.ErrorCategories); diff != "" {
+		t.Fatalf("wrong error categories. Difference:\n%s", diff)
+	}
+	if len(reporter.summaries) != 0 {
+		t.Fatalf("the usage summary was submitted in the local mode. Actual: %+v", reporter.summaries)
+	}
+}
+
+func TestFinishSubmit(t *testing.T) {
+	resetTelemetry(t)
+	path := filepath.Join(t.TempDir(), common.UsageSummaryFile)
+	if err := Start(Submit, "plan", path); err != nil {
+		t.Fatalf("failed to start the telemetry. Error: %q", err)
+	}
+	failing := &testReporter{err: errors.New("the endpoint is down")}
+	reporter := &testReporter{}
+	RegisterReporter("failing", failing)
+	RegisterReporter("test", reporter)
+	RecordSourcePlatform("CloudFoundry")
+	Finish(context.Background())
+	Finish(context.Background())
+	if len(failing.summaries) != 1 {
+		t.Fatalf("expected the failing reporter to be tried once. Actual: %d", len(failing.summaries))
+	}
+	if len(reporter.summaries) != 1 {
+		t.Fatalf("expected the usage summary to be submitted once after a reporter failed. Actual: %d", len(reporter.summaries))
+	}
+	if diff := cmp.Diff(map[string]int{"CloudFoundry": 1}, reporter.summaries[0].Spec.SourcePlatforms); diff != "" {
+		t.Fatalf("wrong source platforms in the submitted summary. Difference:\n%s", diff)
+	}
+	if err := common.ReadMove2KubeYaml(path, &UsageSummary{}); err != nil {
+		t.Fatalf("the usage summary was not written when submitting. Error: %q", err)
+	}
+}
+
+func TestHTTPReporter(t *testing.T) {
+	var received UsageSummary
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	summary := UsageSummary{Spec: UsageSummarySpec{Command: "transform", Transformers: map[string]int{"Maven": 1}}}
+	if err := (&HTTPReporter{Endpoint: server.URL + "/summaries"}).Report(context.Background(), summary); err != nil {
+		t.Fatalf("failed to submit the usage summary. Error: %q", err)
+	}
+	if diff := cmp.Diff(summary, received); diff != "" {
+		t.Fatalf("wrong usage summary received. Difference:\n%s", diff)
+	}
+	if err := (&HTTPReporter{Endpoint: server.URL + "/down"}).Report(context.Background(), summary); err == nil {
+		t.Fatalf("expected the submission to fail when the endpoint is unavailable")
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package toolchain
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Nodejs is the name of the Node.js toolchain
+	Nodejs = "nodejs"
+	// Golang is the name of the Go toolchain
+	Golang = "golang"
+	// Python is the name of the python toolchain
+	Python = "python"
+	// Ruby is the name of the ruby toolchain
+	Ruby = "ruby"
+	// Java is the name of the java toolchain
+	Java = "java"
+	// toolVersionsFile is the asdf file that pins the versions of several toolchains
+	toolVersionsFile = ".tool-versions"
+)
+
+// versionFile is a file that pins the version of a toolchain, with the regex that finds the version in it
+type versionFile struct {
+	name  string
+	regex *regexp.Regexp
+}
+
+var (
+	firstToken = regexp.MustCompile(`(?m)^\s*([^\s#]+)`)
+	// versionFiles are the toolchain specific files that pin the version, in the order they are looked at
+	versionFiles = map[string][]versionFile{
+		Nodejs: {
+			{name: ".nvmrc", regex: firstToken},
+			{name: ".node-version", regex: firstToken},
+		},
+		Golang: {
+			{name: ".go-version", regex: firstToken},
+		},
+		Python: {
+			{name: "runtime.txt", regex: regexp.MustCompile(`(?m)^\s*python-(\d+\.\d+(?:\.\d+)?)`)},
+			{name: ".python-version", regex: firstToken},
+			{name: "Pipfile", regex: regexp.MustCompile(`(?m)^\s*python_version\s*=\s*["'](\d+\.\d+)`)},
+		},
+		Ruby: {
+			{name: ".ruby-version", regex: firstToken},
+			{name: "Gemfile", regex: regexp.MustCompile(`(?m)^\s*ruby\s+["']([^"']+)["']`)},
+		},
+		Java: {
+			{name: ".java-version", regex: firstToken},
+			{name: ".sdkmanrc", regex: regexp.MustCompile(`(?m)^\s*java\s*=\s*(\S+)`)},
+		},
+	}
+	// toolVersionsNames are the names a toolchain goes by in the .tool-versions file
+	toolVersionsNames = map[string][]string{
+		Nodejs: {"nodejs", "node"},
+		Golang: {"golang", "go"},
+		Python: {"python"},
+		Ruby:   {"ruby"},
+		Java:   {"java"},
+	}
+	versionNumber     = regexp.MustCompile(`\d+(?:\.\d+)*`)
+	javaLegacyVersion = regexp.MustCompile(`^1\.\d+`)
+)
+
+// GetVersion returns the version of the toolchain pinned by the version files in the service directory
+// or in one of its parents up to the root directory, or an empty string if there are none.
+// Java versions are returned as the major version, like 17 or 1.8, the others as they are pinned without any prefix.
+func GetVersion(toolchain, serviceDir, rootDir string) string {
+	for dir := serviceDir; ; dir = filepath.Dir(dir) {
+		if version := getVersionInDir(toolchain, dir); version != "" {
+			logrus.Debugf("found the %s version %s pinned in the directory %s", toolchain, version, dir)
+			return version
+		}
+		if rel, err := filepath.Rel(rootDir, dir); err != nil || rel == "." || strings.HasPrefix(rel, "..") || filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// AskVersion asks for the version of the toolchain of a service that does not pin one
+func AskVersion(serviceName, toolchain, defaultVersion string, versions []string) string {
+	quesKey := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigToolchainVersionKeySegment)
+	desc := fmt.Sprintf("Select the %s version to build and run the service %s :", toolchain, serviceName)
+	hints := []string{fmt.Sprintf("The service %s does not pin a %s version. Pin one in a %s file to skip this question.", serviceName, toolchain, toolVersionsFile)}
+	if len(versions) == 0 {
+		return qaengine.FetchStringAnswer(quesKey, desc, hints, defaultVersion)
+	}
+	return qaengine.FetchSelectAnswer(quesKey, desc, hints, defaultVersion, versions)
+}
+
+func getVersionInDir(toolchain, dir string) string {
+	for _, vf := range versionFiles[toolchain] {
+		contents, err := os.ReadFile(filepath.Join(dir, vf.name))
+		if err != nil {
+			continue
+		}
+		if matches := vf.regex.FindSubmatch(contents); matches != nil {
+			if version := normalizeVersion(toolchain, string(matches[1])); version != "" {
+				return version
+			}
+		}
+	}
+	contents, err := os.ReadFile(filepath.Join(dir, toolVersionsFile))
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !common.IsPresent(toolVersionsNames[toolchain], fields[0]) {
+			continue
+		}
+		// the fields after the first version are fallbacks
+		return normalizeVersion(toolchain, fields[1])
+	}
+	return ""
+}
+
+// normalizeVersion strips the vendor and the prefixes from a pinned version, like ruby-3.1.2 or temurin-17.0.5+8.
+// Aliases like lts/* and system are returned as they are.
+func normalizeVersion(toolchain, version string) string {
+	if !versionNumber.MatchString(version) || strings.Contains(version, "/") {
+		return version
+	}
+	number := versionNumber.FindString(version)
+	if toolchain == Java {
+		if legacy := javaLegacyVersion.FindString(number); legacy != "" {
+			return legacy
+		}
+		return strings.SplitN(number, ".", 2)[0]
+	}
+	return version[strings.Index(version, number):]
+}
@@ -0,0 +1,170 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package toolchain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeVersion(t *testing.T) {
+	testCases := []struct {
+		toolchain string
+		version   string
+		expected  string
+	}{
+		{toolchain: Java, version: "temurin-17.0.5+8", expected: "17"},
+		{toolchain: Java, version: "17", expected: "17"},
+		{toolchain: Java, version: "11.0.2", expected: "11"},
+		{toolchain: Java, version: "1.8", expected: "1.8"},
+		{toolchain: Java, version: "1.8.0_292", expected: "1.8"},
+		{toolchain: Java, version: "adoptopenjdk-8.0.292+10", expected: "8"},
+		{toolchain: Java, version: "system", expected: "system"},
+		{toolchain: Nodejs, version: "v18.12.1", expected: "18.12.1"},
+		{toolchain: Nodejs, version: "lts/*", expected: "lts/*"},
+		{toolchain: Nodejs, version: "lts/hydrogen", expected: "lts/hydrogen"},
+		{toolchain: Nodejs, version: "system", expected: "system"},
+		{toolchain: Golang, version: "go1.21.3", expected: "1.21.3"},
+		{toolchain: Python, version: "3.11.4", expected: "3.11.4"},
+		{toolchain: Ruby, version: "ruby-3.1.2", expected: "3.1.2"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.toolchain+" "+testCase.version, func(t *testing.T) {
+			if actual := normalizeVersion(testCase.toolchain, testCase.version); actual != testCase.expected {
+				t.Fatalf("failed to normalize the version. Expected: %s Actual: %s", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+// writeToolchainTestFiles writes the files, keyed by their paths relative to the directory
+func writeToolchainTestFiles(t *testing.T, dir string, files map[string]string) {
+	for relPath, contents := range files {
+		path := filepath.Join(dir, relPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory of the file %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	testCases := []struct {
+		name      string
+		toolchain string
+		files     map[string]string
+		expected  string
+	}{
+		{
+			name:      "version file of the toolchain",
+			toolchain: Nodejs,
+			files:     map[string]string{"root/svc/.nvmrc": "v18.12.1\n"},
+			expected:  "18.12.1",
+		},
+		{
+			name:      "alias in the version file",
+			toolchain: Nodejs,
+			files:     map[string]string{"root/svc/.nvmrc": "lts/*\n"},
+			expected:  "lts/*",
+		},
+		{
+			name:      "version files in the order they are looked at",
+			toolchain: Python,
+			files:     map[string]string{"root/svc/runtime.txt": "python-3.10.8\n", "root/svc/.python-version": "3.9.1\n"},
+			expected:  "3.10.8",
+		},
+		{
+			name:      "version in a Gemfile",
+			toolchain: Ruby,
+			files:     map[string]string{"root/svc/Gemfile": "source 'https://rubygems.org'\nruby '3.1.2'\n"},
+			expected:  "3.1.2",
+		},
+		{
+			name:      "java version in an sdkmanrc",
+			toolchain: Java,
+			files:     map[string]string{"root/svc/.sdkmanrc": "# pinned by sdkman\njava=17.0.5-tem\n"},
+			expected:  "17",
+		},
+		{
+			name:      "tool-versions with fallbacks",
+			toolchain: Java,
+			files:     map[string]string{"root/svc/.tool-versions": "# the versions of the tools\nnodejs 18.12.1\njava temurin-17.0.5+8 zulu-11.0.2 # fallbacks\n"},
+			expected:  "17",
+		},
+		{
+			name:      "tool-versions with a legacy java version",
+			toolchain: Java,
+			files:     map[string]string{"root/svc/.tool-versions": "java 1.8\n"},
+			expected:  "1.8",
+		},
+		{
+			name:      "tool-versions with another name of the toolchain",
+			toolchain: Golang,
+			files:     map[string]string{"root/svc/.tool-versions": "go 1.21.3\n"},
+			expected:  "1.21.3",
+		},
+		{
+			name:      "system version in the tool-versions",
+			toolchain: Nodejs,
+			files:     map[string]string{"root/svc/.tool-versions": "nodejs system 18.12.1\n"},
+			expected:  "system",
+		},
+		{
+			name:      "version file before the tool-versions",
+			toolchain: Nodejs,
+			files:     map[string]string{"root/svc/.tool-versions": "nodejs 16.0.0\n", "root/svc/.node-version": "18.12.1\n"},
+			expected:  "18.12.1",
+		},
+		{
+			name:      "version in a parent directory",
+			toolchain: Nodejs,
+			files:     map[string]string{"root/.tool-versions": "nodejs 18.12.1\n", "root/svc/package.json": "{}"},
+			expected:  "18.12.1",
+		},
+		{
+			name:      "service directory before its parents",
+			toolchain: Nodejs,
+			files:     map[string]string{"root/.nvmrc": "16\n", "root/svc/.nvmrc": "18\n"},
+			expected:  "18",
+		},
+		{
+			name:      "version above the root directory",
+			toolchain: Nodejs,
+			files:     map[string]string{".nvmrc": "18\n", "root/svc/package.json": "{}"},
+			expected:  "",
+		},
+		{
+			name:      "version of another toolchain",
+			toolchain: Python,
+			files:     map[string]string{"root/svc/.tool-versions": "nodejs 18.12.1\n"},
+			expected:  "",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeToolchainTestFiles(t, dir, testCase.files)
+			actual := GetVersion(testCase.toolchain, filepath.Join(dir, "root", "svc"), filepath.Join(dir, "root"))
+			if actual != testCase.expected {
+				t.Fatalf("wrong %s version. Expected: %s Actual: %s", testCase.toolchain, testCase.expected, actual)
+			}
+		})
+	}
+}
@@ -130,6 +130,12 @@ func GetFilesByExt(inputPath string, exts []string) ([]string, error) {
 					return filepath.SkipDir
 				}
 			}
+			if SkipDirForDetection(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if SkipFileForDetection(path) {
 			return nil
 		}
 		fext := filepath.Ext(path)
@@ -175,7 +181,7 @@ func GetFilesByExtInCurrDir(dir string, exts []string) ([]string, error) {
 		}
 		fext := filepath.Ext(de.Name())
 		for _, ext := range exts {
-			if fext == ext {
+			if fext == ext && !SkipFileForDetection(filepath.Join(dir, de.Name())) {
 				files = append(files, filepath.Join(dir, de.Name()))
 				break
 			}
@@ -217,6 +223,12 @@ func GetFilesByName(inputPath string, names []string, nameRegexes []string) ([]s
 					return filepath.SkipDir
 				}
 			}
+			if SkipDirForDetection(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if SkipFileForDetection(path) {
 			return nil
 		}
 		fname := filepath.Base(path)
@@ -274,6 +286,9 @@ func GetFilesInCurrentDirectory(path string, fileNames, fileNameRegexes []string
 		compiledNameRegexes = append(compiledNameRegexes, compiledNameRegex)
 	}
 	for _, currFileName := range currFileNames {
+		if SkipFileForDetection(filepath.Join(path, currFileName)) {
+			continue
+		}
 		for _, fileName := range fileNames {
 			if fileName == currFileName {
 				matchedFilePaths = append(matchedFilePaths, filepath.Join(path, currFileName))
@@ -326,6 +341,18 @@ func GetImageNameAndTag(image string) (string, string) {
 	return imageName, tag
 }
 
+// TagNewImageName adds the tag of the new images to the image name, unless the tag is latest or the image name has a tag or a digest already
+func TagNewImageName(image string) string {
+	if ImageTag == "" || strings.Contains(image, "@") {
+		return image
+	}
+	parts := strings.Split(image, "/")
+	if strings.Contains(parts[len(parts)-1], ":") {
+		return image
+	}
+	return image + ":" + ImageTag
+}
+
 // ObjectToYamlBytes encodes an object to yaml
 func ObjectToYamlBytes(data interface{}) ([]byte, error) {
 	var b bytes.Buffer
@@ -354,6 +381,9 @@ func WriteYaml(outputPath string, data interface{}) error {
 
 // ReadYaml reads an yaml into an object
 func ReadYaml(file string, data interface{}) error {
+	if SkipFileForDetection(file) {
+		return fmt.Errorf("the yaml file %s is bigger than the maximum size of the files looked at while planning", file)
+	}
 	yamlFile, err := os.ReadFile(file)
 	if err != nil {
 		logrus.Debugf("Error in reading yaml file %s: %s.", file, err)
@@ -512,6 +542,9 @@ func WriteJSON(outputPath string, data interface{}) error {
 
 // ReadJSON reads an json into an object
 func ReadJSON(file string, data interface{}) error {
+	if SkipFileForDetection(file) {
+		return fmt.Errorf("the json file %s is bigger than the maximum size of the files looked at while planning", file)
+	}
 	jsonFile, err := os.ReadFile(file)
 	if err != nil {
 		logrus.Debugf("Error in reading json file %s: %s.", file, err)
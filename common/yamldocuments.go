@@ -0,0 +1,169 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMaxYAMLDocumentSize is the default maximum size in bytes of a document in a multi document yaml
+	DefaultMaxYAMLDocumentSize = 16 * 1024 * 1024
+)
+
+// YAMLDocument is one of the documents in a multi document yaml
+type YAMLDocument struct {
+	// Index is the index of the document among the non empty documents in the yaml
+	Index int
+	// Line is the line at which the document starts
+	Line int
+	// Data is the yaml of the document
+	Data []byte
+}
+
+// StreamYAMLDocuments splits the multi document yaml read from the reader into documents and calls the handler with each of them,
+// without decoding them, so that a document that cannot be decoded does not prevent reading the documents after it.
+// Documents bigger than maxDocumentSize bytes are skipped. Empty documents and documents that only have comments are skipped.
+// It stops at the first error returned by the handler.
+func StreamYAMLDocuments(r io.Reader, maxDocumentSize int, handler func(YAMLDocument) error) error {
+	reader := bufio.NewReader(r)
+	doc := bytes.Buffer{}
+	tooBig := false
+	index, lineNo, startLine := 0, 0, 1
+	flush := func() error {
+		defer func() {
+			doc.Reset()
+			tooBig = false
+			startLine = lineNo + 1
+		}()
+		if tooBig {
+			logrus.Warnf("Skipping the yaml document starting at line %d , since it is bigger than %d bytes", startLine, maxDocumentSize)
+			return nil
+		}
+		if isEmptyYAMLDocument(doc.Bytes()) {
+			return nil
+		}
+		data := make([]byte, doc.Len())
+		copy(data, doc.Bytes())
+		index++
+		return handler(YAMLDocument{Index: index - 1, Line: startLine, Data: data})
+	}
+	for {
+		line, err := readYAMLLine(reader)
+		if len(line) > 0 {
+			lineNo++
+			if rest, ok := cutYAMLDocumentMarker(line, "---"); ok {
+				if err := flush(); err != nil {
+					return err
+				}
+				line = rest
+			} else if _, ok := cutYAMLDocumentMarker(line, "..."); ok {
+				if err := flush(); err != nil {
+					return err
+				}
+				line = nil
+			}
+			if !tooBig {
+				if maxDocumentSize > 0 && doc.Len()+len(line) > maxDocumentSize {
+					tooBig = true
+					doc.Reset()
+				} else {
+					doc.Write(line)
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return flush()
+			}
+			return fmt.Errorf("failed to read the yaml at line %d . Error: %q", lineNo+1, err)
+		}
+	}
+}
+
+// readYAMLLine reads a line including the newline.
+// The line is only valid until the next read, unless it is longer than the buffer of the reader.
+func readYAMLLine(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadSlice('\n')
+	if !errors.Is(err, bufio.ErrBufferFull) {
+		return line, err
+	}
+	longLine := append([]byte{}, line...)
+	for errors.Is(err, bufio.ErrBufferFull) {
+		line, err = reader.ReadSlice('\n')
+		longLine = append(longLine, line...)
+	}
+	return longLine, err
+}
+
+// cutYAMLDocumentMarker returns the rest of the line if the line starts with the document start (---) or end (...) marker
+func cutYAMLDocumentMarker(line []byte, marker string) ([]byte, bool) {
+	if !bytes.HasPrefix(line, []byte(marker)) {
+		return nil, false
+	}
+	rest := line[len(marker):]
+	if len(rest) != 0 && rest[0] != ' ' && rest[0] != '\t' && rest[0] != '\r' && rest[0] != '\n' {
+		return nil, false
+	}
+	return rest, true
+}
+
+// isEmptyYAMLDocument returns true if the document only has whitespace, comments and directives
+func isEmptyYAMLDocument(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) != 0 && line[0] != '#' && line[0] != '%' {
+			return false
+		}
+	}
+	return true
+}
+
+// ProcessFilesInParallel calls process for each of the paths, processing as many files at a time as there are cpus.
+// The results are in the same order as the paths.
+func ProcessFilesInParallel[T interface{}](paths []string, process func(path string) T) []T {
+	results := make([]T, len(paths))
+	workers := runtime.NumCPU()
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				results[index] = process(paths[index])
+			}
+		}()
+	}
+	for index := range paths {
+		indices <- index
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
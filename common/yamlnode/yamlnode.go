@@ -0,0 +1,379 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package yamlnode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	nameKey = "name"
+	// defaultIndent is used for the documents that were not parsed from a file or do not have nested blocks
+	defaultIndent = 2
+)
+
+// ParseDocuments parses all the documents in a (possibly multi document) yaml
+func ParseDocuments(data []byte) ([]*yaml.Node, error) {
+	docs := []*yaml.Node{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &yaml.Node{}
+		if err := decoder.Decode(doc); err != nil {
+			if err == io.EOF {
+				return docs, nil
+			}
+			return docs, fmt.Errorf("failed to decode the yaml document at index %d . Error: %q", len(docs), err)
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// EncodeDocuments encodes the documents into a single multi document yaml.
+// The indentation of the parsed documents is kept, including whether sequences are indented inside mappings.
+func EncodeDocuments(docs []*yaml.Node) ([]byte, error) {
+	ind := indentation{}
+	for _, doc := range docs {
+		ind.detect(doc)
+	}
+	if ind.indent == 0 {
+		ind.indent = defaultIndent
+	}
+	encodedDocs := []string{}
+	for i, doc := range docs {
+		var b bytes.Buffer
+		encoder := yaml.NewEncoder(&b)
+		encoder.SetIndent(ind.indent)
+		if err := encoder.Encode(doc); err != nil {
+			return nil, fmt.Errorf("failed to encode the yaml document at index %d . Error: %q", i, err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close the yaml encoder. Error: %q", err)
+		}
+		encodedDoc := b.String()
+		if ind.compactSeq {
+			var err error
+			if encodedDoc, err = compactSequences(encodedDoc, ind.indent); err != nil {
+				return nil, fmt.Errorf("failed to compact the sequences of the yaml document at index %d . Error: %q", i, err)
+			}
+		}
+		encodedDocs = append(encodedDocs, encodedDoc)
+	}
+	return []byte(strings.Join(encodedDocs, "---\n")), nil
+}
+
+// compactSequences moves the block sequences of the mappings in the encoded document to the indentation of their keys,
+// since the encoder always indents them
+func compactSequences(encodedDoc string, indent int) (string, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(encodedDoc), doc); err != nil {
+		return "", fmt.Errorf("failed to decode the encoded document. Error: %q", err)
+	}
+	lines := strings.Split(encodedDoc, "\n")
+	dedents := make([]int, len(lines))
+	addSequenceDedents(doc, len(lines)+1, lines, dedents, indent)
+	for i, line := range lines {
+		lines[i] = line[dedents[i]:]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// addSequenceDedents adds the indent to the dedents of the lines of each block sequence in a mapping.
+// The lines of a node end before the line of the node that follows it. Only the lines indented at least as much as the
+// sequence are moved, which leaves out the blank lines and the comments of the keys that follow it.
+func addSequenceDedents(node *yaml.Node, endLine int, lines []string, dedents []int, indent int) {
+	for i, child := range node.Content {
+		childEndLine := endLine
+		switch node.Kind {
+		case yaml.MappingNode:
+			if i%2 == 0 {
+				continue
+			}
+			key := node.Content[i-1]
+			if i+1 < len(node.Content) {
+				childEndLine = node.Content[i+1].Line
+			}
+			if child.Kind == yaml.SequenceNode && child.Style&yaml.FlowStyle == 0 && child.Line > key.Line {
+				// the lines start after the key, so that the head comment of the first element is moved with it
+				for line := key.Line + 1; line < childEndLine && line <= len(lines); line++ {
+					text := lines[line-1]
+					if strings.TrimSpace(text) != "" && len(text)-len(strings.TrimLeft(text, " ")) >= child.Column-1 {
+						dedents[line-1] += indent
+					}
+				}
+			}
+		default:
+			if i+1 < len(node.Content) {
+				childEndLine = node.Content[i+1].Line
+			}
+		}
+		addSequenceDedents(child, childEndLine, lines, dedents, indent)
+	}
+}
+
+// Merge updates the node in place so that it represents the value.
+// The value is expected to be made up of map[string]interface{}, []interface{} and scalars, as produced by unmarshalling yaml.
+// Comments, the order of keys and the style of unchanged scalars are preserved.
+// Keys present in the node but missing in the value are removed, while keys with empty values
+// (nil, empty sequences or mappings containing only empty values) that are missing in the node are not added.
+// Elements of sequences are matched by their name field when every element has one, and by index otherwise.
+func Merge(node *yaml.Node, value interface{}) error {
+	return merge(node, value, nil)
+}
+
+// MergeKeepingUnknown is the same as Merge, except that the keys missing both in the value and in the original are retained.
+// The original is what the node decoded into before being changed, so that those keys are the ones unknown to the decoder,
+// like the fields of a schema newer than the one used to decode the node.
+func MergeKeepingUnknown(node *yaml.Node, original, value interface{}) error {
+	return merge(node, value, original)
+}
+
+func merge(node *yaml.Node, value, original interface{}) error {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			newNode, err := toNode(value)
+			if err != nil {
+				return err
+			}
+			node.Content = []*yaml.Node{newNode}
+			return nil
+		}
+		return merge(node.Content[0], value, original)
+	}
+	switch actualValue := value.(type) {
+	case map[string]interface{}:
+		if node.Kind != yaml.MappingNode {
+			return replace(node, value)
+		}
+		originalMap, _ := original.(map[string]interface{})
+		return mergeMapping(node, actualValue, originalMap)
+	case []interface{}:
+		if node.Kind != yaml.SequenceNode {
+			return replace(node, value)
+		}
+		originalSlice, _ := original.([]interface{})
+		return mergeSequence(node, actualValue, originalSlice)
+	}
+	if node.Kind == yaml.ScalarNode {
+		var currentValue interface{}
+		if err := node.Decode(&currentValue); err == nil && reflect.DeepEqual(currentValue, value) {
+			return nil
+		}
+	}
+	return replace(node, value)
+}
+
+func mergeMapping(node *yaml.Node, value, original map[string]interface{}) error {
+	seenKeys := map[string]bool{}
+	content := []*yaml.Node{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		fieldValue, ok := value[key]
+		if !ok {
+			if _, known := original[key]; original != nil && !known {
+				content = append(content, node.Content[i], node.Content[i+1])
+			}
+			continue
+		}
+		seenKeys[key] = true
+		if err := merge(node.Content[i+1], fieldValue, original[key]); err != nil {
+			return fmt.Errorf("failed to merge the key %s . Error: %q", key, err)
+		}
+		content = append(content, node.Content[i], node.Content[i+1])
+	}
+	node.Content = content
+	newKeys := []string{}
+	for key, fieldValue := range value {
+		if seenKeys[key] || isEmpty(fieldValue) {
+			continue
+		}
+		newKeys = append(newKeys, key)
+	}
+	sort.Strings(newKeys)
+	for _, key := range newKeys {
+		valueNode, err := toNode(value[key])
+		if err != nil {
+			return fmt.Errorf("failed to create a node for the key %s . Error: %q", key, err)
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+	}
+	return nil
+}
+
+func mergeSequence(node *yaml.Node, value, original []interface{}) error {
+	indicesByName, matchByName := getIndicesByName(node, value)
+	newContent := []*yaml.Node{}
+	for i, elem := range value {
+		index := -1
+		if matchByName {
+			if nameIndex, ok := indicesByName[elem.(map[string]interface{})[nameKey].(string)]; ok {
+				index = nameIndex
+			}
+		} else if i < len(node.Content) {
+			index = i
+		}
+		if index == -1 {
+			newNode, err := toNode(elem)
+			if err != nil {
+				return fmt.Errorf("failed to create a node for the element at index %d . Error: %q", i, err)
+			}
+			newContent = append(newContent, newNode)
+			continue
+		}
+		var originalElem interface{}
+		if index < len(original) {
+			originalElem = original[index]
+		}
+		elemNode := node.Content[index]
+		if err := merge(elemNode, elem, originalElem); err != nil {
+			return fmt.Errorf("failed to merge the element at index %d . Error: %q", i, err)
+		}
+		newContent = append(newContent, elemNode)
+	}
+	node.Content = newContent
+	return nil
+}
+
+// getIndicesByName returns the indices of the elements of the sequence node keyed by their name,
+// provided both the sequence node and the value have only uniquely named mappings.
+func getIndicesByName(node *yaml.Node, value []interface{}) (map[string]int, bool) {
+	if len(node.Content) == 0 || len(value) == 0 {
+		return nil, false
+	}
+	names := map[string]bool{}
+	for _, elem := range value {
+		elemMap, ok := elem.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := elemMap[nameKey].(string)
+		if !ok || names[name] {
+			return nil, false
+		}
+		names[name] = true
+	}
+	indicesByName := map[string]int{}
+	for i, elemNode := range node.Content {
+		name, ok := getNameOfNode(elemNode)
+		if !ok {
+			return nil, false
+		}
+		if _, ok := indicesByName[name]; ok {
+			return nil, false
+		}
+		indicesByName[name] = i
+	}
+	return indicesByName, true
+}
+
+func getNameOfNode(node *yaml.Node) (string, bool) {
+	if node.Kind != yaml.MappingNode {
+		return "", false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == nameKey && node.Content[i+1].Kind == yaml.ScalarNode {
+			return node.Content[i+1].Value, true
+		}
+	}
+	return "", false
+}
+
+// indentation is the indentation of a parsed document
+type indentation struct {
+	// indent is the number of spaces the nested mappings are indented by
+	indent int
+	// seqSeen is true once a sequence nested in a mapping is found
+	seqSeen bool
+	// compactSeq is true if the sequences are at the same indentation as their keys
+	compactSeq bool
+}
+
+// detect finds the indentation of the nested block mappings and sequences of the node
+func (ind *indentation) detect(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Line == 0 || value.Line <= key.Line || value.Style&yaml.FlowStyle != 0 {
+				continue
+			}
+			switch value.Kind {
+			case yaml.MappingNode:
+				if ind.indent == 0 && value.Column > key.Column {
+					ind.indent = value.Column - key.Column
+				}
+			case yaml.SequenceNode:
+				if !ind.seqSeen {
+					ind.seqSeen = true
+					ind.compactSeq = value.Column == key.Column
+					if ind.indent == 0 && value.Column > key.Column {
+						ind.indent = value.Column - key.Column
+					}
+				}
+			}
+		}
+	}
+	for _, child := range node.Content {
+		if ind.indent != 0 && ind.seqSeen {
+			return
+		}
+		ind.detect(child)
+	}
+}
+
+func replace(node *yaml.Node, value interface{}) error {
+	newNode, err := toNode(value)
+	if err != nil {
+		return err
+	}
+	newNode.HeadComment = node.HeadComment
+	newNode.LineComment = node.LineComment
+	newNode.FootComment = node.FootComment
+	*node = *newNode
+	return nil
+}
+
+func toNode(value interface{}) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode the value %+v as a yaml node. Error: %q", value, err)
+	}
+	return node, nil
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch actualValue := value.(type) {
+	case map[string]interface{}:
+		for _, fieldValue := range actualValue {
+			if !isEmpty(fieldValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		return len(actualValue) == 0
+	}
+	return false
+}
@@ -0,0 +1,326 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package yamlnode_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common/yamlnode"
+	"gopkg.in/yaml.v3"
+)
+
+func mergeAndEncode(t *testing.T, input string, value string) string {
+	docs, err := yamlnode.ParseDocuments([]byte(input))
+	if err != nil {
+		t.Fatalf("failed to parse the input yaml. Error: %q", err)
+	}
+	var values []interface{}
+	valueDocs, err := yamlnode.ParseDocuments([]byte(value))
+	if err != nil {
+		t.Fatalf("failed to parse the value yaml. Error: %q", err)
+	}
+	for _, valueDoc := range valueDocs {
+		var v interface{}
+		if err := valueDoc.Decode(&v); err != nil {
+			t.Fatalf("failed to decode the value yaml. Error: %q", err)
+		}
+		values = append(values, v)
+	}
+	if len(values) != len(docs) {
+		t.Fatalf("expected %d values. Actual: %d", len(docs), len(values))
+	}
+	for i, doc := range docs {
+		if err := yamlnode.Merge(doc, values[i]); err != nil {
+			t.Fatalf("failed to merge the value into the document. Error: %q", err)
+		}
+	}
+	output, err := yamlnode.EncodeDocuments(docs)
+	if err != nil {
+		t.Fatalf("failed to encode the documents. Error: %q", err)
+	}
+	return string(output)
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("preserve comments and order and remove missing keys", func(t *testing.T) {
+		input := `# head comment
+kind: Deployment
+apiVersion: extensions/v1beta1 # old version
+metadata:
+  name: web
+spec:
+  unknown: keep
+  replicas: 2
+`
+		value := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  creationTimestamp: null
+spec:
+  replicas: 2
+  revisionHistoryLimit: 10
+status: {}
+`
+		want := `# head comment
+kind: Deployment
+apiVersion: apps/v1 # old version
+metadata:
+  name: web
+spec:
+  replicas: 2
+  revisionHistoryLimit: 10
+`
+		if got := mergeAndEncode(t, input, value); got != want {
+			t.Fatalf("the merged yaml is incorrect. Difference:\n%s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("match sequence elements by name", func(t *testing.T) {
+		input := `containers:
+  # first
+  - name: a
+    image: a:1
+  # second
+  - name: b
+    image: b:1
+`
+		value := `containers:
+  - name: b
+    image: b:2
+  - name: c
+    image: c:1
+`
+		want := `containers:
+  # second
+  - name: b
+    image: b:2
+  - image: c:1
+    name: c
+`
+		if got := mergeAndEncode(t, input, value); got != want {
+			t.Fatalf("the merged yaml is incorrect. Difference:\n%s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("keep the style of unchanged scalars", func(t *testing.T) {
+		input := `port: 80
+name: 'web'
+`
+		value := `port: 8080
+name: web
+`
+		want := `port: 8080
+name: 'web'
+`
+		if got := mergeAndEncode(t, input, value); got != want {
+			t.Fatalf("the merged yaml is incorrect. Difference:\n%s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("keep compact sequences", func(t *testing.T) {
+		input := `spec:
+  containers:
+  - name: a
+    args:
+    - run
+`
+		value := `spec:
+  containers:
+    - name: a
+      args:
+        - serve
+`
+		want := `spec:
+  containers:
+  - name: a
+    args:
+    - serve
+`
+		if got := mergeAndEncode(t, input, value); got != want {
+			t.Fatalf("the merged yaml is incorrect. Difference:\n%s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("keep the indentation", func(t *testing.T) {
+		input := `spec:
+    ports:
+        - port: 80
+`
+		value := `spec:
+  ports:
+    - port: 8080
+`
+		want := `spec:
+    ports:
+        - port: 8080
+`
+		if got := mergeAndEncode(t, input, value); got != want {
+			t.Fatalf("the merged yaml is incorrect. Difference:\n%s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("merge into multiple documents", func(t *testing.T) {
+		input := `a: 1
+---
+b: 2
+`
+		value := `a: 3
+---
+b: 2
+c: x
+`
+		want := `a: 3
+---
+b: 2
+c: x
+`
+		if got := mergeAndEncode(t, input, value); got != want {
+			t.Fatalf("the merged yaml is incorrect. Difference:\n%s", cmp.Diff(want, got))
+		}
+	})
+}
+
+func TestMergeKeepingUnknown(t *testing.T) {
+	input := `spec:
+  unknown: keep
+  removed: 1
+  kept: 2
+`
+	want := `spec:
+  unknown: keep
+  kept: 3
+`
+	docs, err := yamlnode.ParseDocuments([]byte(input))
+	if err != nil {
+		t.Fatalf("failed to parse the input yaml. Error: %q", err)
+	}
+	original := map[string]interface{}{"spec": map[string]interface{}{"removed": 1, "kept": 2}}
+	value := map[string]interface{}{"spec": map[string]interface{}{"kept": 3}}
+	if err := yamlnode.MergeKeepingUnknown(docs[0], original, value); err != nil {
+		t.Fatalf("failed to merge the value into the document. Error: %q", err)
+	}
+	output, err := yamlnode.EncodeDocuments(docs)
+	if err != nil {
+		t.Fatalf("failed to encode the documents. Error: %q", err)
+	}
+	if got := string(output); got != want {
+		t.Fatalf("the merged yaml is incorrect. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestParseDocuments(t *testing.T) {
+	docs, err := yamlnode.ParseDocuments([]byte("a: 1\n---\nb: 2\n"))
+	if err != nil {
+		t.Fatalf("failed to parse the documents. Error: %q", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents. Actual: %d", len(docs))
+	}
+	if docs[0].Kind != yaml.DocumentNode {
+		t.Fatalf("expected a document node. Actual: %v", docs[0].Kind)
+	}
+}
+
+func TestEncodeDocumentsCompactSequences(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "nested sequences",
+			input: `spec:
+  containers:
+  - name: a
+    args:
+    - run
+    - --port=8080
+    ports:
+    - containerPort: 8080
+  volumes:
+  - name: data
+`,
+		},
+		{
+			name: "comments around the sequences",
+			input: `# the pod
+spec:
+  args:
+  # the first argument
+  - run
+  # the image
+  image: a:1
+  env:
+  - name: MODE
+    value: prod
+# the end
+`,
+		},
+		{
+			name: "block scalars in the sequences",
+			input: `data:
+  scripts:
+  - |
+    #!/bin/sh
+    echo hi
+  - plain
+kind: ConfigMap
+`,
+		},
+		{
+			name: "sequences of sequences",
+			input: `matrix:
+- - 1
+  - 2
+- - 3
+`,
+		},
+		{
+			name: "flow sequences",
+			input: `args: [run, serve]
+env:
+- name: MODE
+`,
+		},
+		{
+			name: "multiple documents",
+			input: `a:
+- 1
+---
+b:
+  c:
+  - 2
+`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			docs, err := yamlnode.ParseDocuments([]byte(testCase.input))
+			if err != nil {
+				t.Fatalf("failed to parse the input yaml. Error: %q", err)
+			}
+			output, err := yamlnode.EncodeDocuments(docs)
+			if err != nil {
+				t.Fatalf("failed to encode the documents. Error: %q", err)
+			}
+			if got := string(output); got != testCase.input {
+				t.Fatalf("the encoded yaml is not the same as the input. Difference:\n%s", cmp.Diff(testCase.input, got))
+			}
+		})
+	}
+}
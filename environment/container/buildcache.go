@@ -0,0 +1,153 @@
+/*
+ *  Copyright IBM Corporation 2020, 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// buildHashLabel is the label on built images that stores the hash of the build context and Dockerfile they were built from
+	buildHashLabel = types.GroupName + "/build-hash"
+)
+
+// getBuildHash returns a hash of the files in the build context and the Dockerfile used to build an image
+func getBuildHash(context, dockerfile string) (string, error) {
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "dockerfile:%s\n", dockerfile)
+	if err := hashDir(hasher, context); err != nil {
+		return "", fmt.Errorf("failed to hash the build context %s . Error: %q", context, err)
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// hashDir writes the paths, types, permissions and contents of the files in the directory to the hasher
+func hashDir(hasher io.Writer, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hasher, "%s:%s:%o\n", filepath.ToSlash(relPath), info.Mode().Type(), info.Mode().Perm())
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(hasher, f)
+		return err
+	})
+}
+
+// getBuildCachePath returns the path of the cached image for the build hash
+func getBuildCachePath(buildHash string) string {
+	return filepath.Join(common.BuildCacheDir, buildHash+".tar")
+}
+
+// hasBuiltImage checks whether the image exists and was built from the same build context and Dockerfile
+func (e *dockerEngine) hasBuiltImage(image, buildHash string) bool {
+	inspectOutput, err := e.InspectImage(image)
+	if err != nil || inspectOutput.Config == nil {
+		return false
+	}
+	return inspectOutput.Config.Labels[buildHashLabel] == buildHash
+}
+
+// loadCachedImage loads the image from the build cache directory if it was built from the same build context and Dockerfile
+func (e *dockerEngine) loadCachedImage(image, buildHash string) bool {
+	cachePath := getBuildCachePath(buildHash)
+	if _, err := os.Stat(cachePath); err != nil {
+		return false
+	}
+	logrus.Infof("Loading the container image %s from the build cache at path %s", image, cachePath)
+	if err := e.loadImageArchive(cachePath); err != nil {
+		logrus.Warnf("Failed to load the image %s from the build cache at path %s . Error: %q", image, cachePath, err)
+		return false
+	}
+	return e.hasBuiltImage(image, buildHash)
+}
+
+// saveCachedImage saves the built image to the build cache directory, so that later runs do not have to build it again
+func (e *dockerEngine) saveCachedImage(image, buildHash string) {
+	if err := e.saveImageArchive(image, common.BuildCacheDir, getBuildCachePath(buildHash)); err != nil {
+		logrus.Warnf("Failed to save the image %s to the build cache. Error: %q", image, err)
+	}
+}
+
+// loadImageArchive loads the images in the archive created by saveImageArchive
+func (e *dockerEngine) loadImageArchive(archivePath string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	resp, err := e.cli.ImageLoad(e.ctx, f, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return jsonmessage.DisplayJSONMessagesStream(resp.Body, io.Discard, 0, false, nil)
+}
+
+// saveImageArchive saves the image to an archive in the directory. Nothing is saved if another run is saving the same archive.
+func (e *dockerEngine) saveImageArchive(image, dir, archivePath string) error {
+	if err := os.MkdirAll(dir, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory at path %s . Error: %q", dir, err)
+	}
+	if err := common.AcquireLock(archivePath); err != nil {
+		logrus.Debugf("not saving the image %s to the path %s . Error: %q", image, archivePath, err)
+		return nil
+	}
+	defer common.ReleaseLock(archivePath)
+	out, err := e.cli.ImageSave(e.ctx, []string{image})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	// write to a temporary file first, so that an interrupted save does not leave a corrupt archive
+	tempPath := archivePath + ".tmp"
+	f, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("failed to create the file at path %s . Error: %q", tempPath, err)
+	}
+	if _, err := io.Copy(f, out); err != nil {
+		f.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write the file at path %s . Error: %q", tempPath, err)
+	}
+	f.Close()
+	if err := os.Rename(tempPath, archivePath); err != nil {
+		return err
+	}
+	logrus.Debugf("saved the image %s to the path %s", image, archivePath)
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetBuildHash(t *testing.T) {
+	context := t.TempDir()
+	if err := os.WriteFile(filepath.Join(context, "Dockerfile"), []byte("FROM alpine\n"), 0644); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	hash1, err := getBuildHash(context, "Dockerfile")
+	if err != nil {
+		t.Fatalf("failed to hash the build context. Error: %q", err)
+	}
+	hash2, err := getBuildHash(context, "Dockerfile")
+	if err != nil || hash1 != hash2 {
+		t.Fatalf("the hash of an unchanged build context changed. Expected: %s Actual: %s Error: %q", hash1, hash2, err)
+	}
+	if err := os.WriteFile(filepath.Join(context, "Dockerfile"), []byte("FROM alpine:3\n"), 0644); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	if hash3, err := getBuildHash(context, "Dockerfile"); err != nil || hash3 == hash1 {
+		t.Fatalf("the hash did not change when the Dockerfile changed. Hash: %s Error: %q", hash3, err)
+	}
+}
+
+func TestBuildImageSkipsUnchangedBuilds(t *testing.T) {
+	buildContext := t.TempDir()
+	if err := os.WriteFile(filepath.Join(buildContext, "Dockerfile"), []byte("FROM alpine\n"), 0644); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	builtHash, err := getBuildHash(buildContext, "Dockerfile")
+	if err != nil {
+		t.Fatalf("failed to hash the build context. Error: %q", err)
+	}
+	builds := []map[string]string{}
+	engine := newTestDockerEngine(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/move2kube-mytransformer:latest/json"):
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"Id": "sha256:0123", "Config": map[string]interface{}{"Labels": map[string]string{buildHashLabel: builtHash}}})
+		case strings.HasSuffix(r.URL.Path, "/build"):
+			_, _ = io.Copy(io.Discard, r.Body)
+			labels := map[string]string{}
+			if err := json.Unmarshal([]byte(r.URL.Query().Get("labels")), &labels); err != nil {
+				t.Errorf("failed to parse the labels of the build. Error: %q", err)
+			}
+			builds = append(builds, labels)
+			_, _ = w.Write([]byte(`{"stream": "Successfully built 0123"}` + "\n"))
+		default:
+			t.Errorf("unexpected request to the docker daemon: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	if err := engine.BuildImage(context.Background(), "move2kube-mytransformer:latest", buildContext, "Dockerfile"); err != nil {
+		t.Fatalf("failed to build the image. Error: %q", err)
+	}
+	if len(builds) != 0 {
+		t.Fatalf("expected the image built from the same build context to not be built again. Actual builds: %+v", builds)
+	}
+	if err := os.WriteFile(filepath.Join(buildContext, "Dockerfile"), []byte("FROM alpine:3\n"), 0644); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	if err := engine.BuildImage(context.Background(), "move2kube-mytransformer:latest", buildContext, "Dockerfile"); err != nil {
+		t.Fatalf("failed to build the image. Error: %q", err)
+	}
+	if len(builds) != 1 || builds[0][buildHashLabel] == "" || builds[0][buildHashLabel] == builtHash {
+		t.Fatalf("expected the image to be rebuilt with the hash of the changed Dockerfile. Actual builds: %+v", builds)
+	}
+}
@@ -17,8 +17,11 @@
 package container
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
+	"os"
+	"time"
 
 	dockertypes "github.com/docker/docker/api/types"
 	"github.com/konveyor/move2kube/common"
@@ -27,55 +30,224 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// cleanupTimeout is the time given to remove a container, since the context of the run may have been cancelled already
+	cleanupTimeout = time.Minute
+)
+
+const (
+	// AutoContainerRuntime uses the first container runtime that is available
+	AutoContainerRuntime = "auto"
+	// DockerContainerRuntime uses the docker daemon
+	DockerContainerRuntime = "docker"
+	// PodmanContainerRuntime uses the podman service
+	PodmanContainerRuntime = "podman"
+	// KubernetesContainerRuntime runs the containers as pods in the cluster in the current kubeconfig
+	KubernetesContainerRuntime = "kubernetes"
+	// NoContainerRuntime disables the transformers that rely on containers
+	NoContainerRuntime = "none"
+)
+
+var (
+	// ContainerRuntimes are the container runtimes that can be selected
+	ContainerRuntimes = []string{AutoContainerRuntime, DockerContainerRuntime, PodmanContainerRuntime, KubernetesContainerRuntime, NoContainerRuntime}
+)
+
 var (
 	inited        bool
 	disabled      bool
 	workingEngine ContainerEngine
+	// engineErr is the reason why there is no working container engine
+	engineErr error
 )
 
-// ContainerEngine defines interface to manage containers
+// ContainerEngine defines interface to manage containers.
+// The methods that take a context stop when it is cancelled. The containers and images are removed even after it is cancelled.
 type ContainerEngine interface {
 	// RunCmdInContainer runs a container
-	RunCmdInContainer(image string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitcode int, err error)
+	RunCmdInContainer(ctx context.Context, image string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitcode int, err error)
 	// InspectImage gets Inspect output for a container
 	InspectImage(image string) (dockertypes.ImageInspect, error)
 	// TODO: Change paths from map to array
-	CopyDirsIntoImage(image, newImageName string, paths map[string]string) (err error)
-	CopyDirsIntoContainer(containerID string, paths map[string]string) (err error)
-	CopyDirsFromContainer(containerID string, paths map[string]string) (err error)
-	BuildImage(image, context, dockerfile string) (err error)
+	CopyDirsIntoImage(ctx context.Context, image, newImageName string, paths map[string]string) (err error)
+	CopyDirsIntoContainer(ctx context.Context, containerID string, paths map[string]string) (err error)
+	CopyDirsFromContainer(ctx context.Context, containerID string, paths map[string]string) (err error)
+	BuildImage(ctx context.Context, image, contextPath, dockerfile string) (err error)
+	// TagImage creates a new tag for an existing image
+	TagImage(image, newImageName string) (err error)
+	// PullImage pulls an image of the platform from its registry using the pull policy. The image of the platform of the host is pulled if the platform is empty.
+	PullImage(ctx context.Context, image string, policy environmenttypes.PullPolicy, platform string) (err error)
+	// PushImage pushes an image to its registry using the credentials in the docker config.json file
+	PushImage(ctx context.Context, image string) (err error)
 	RemoveImage(image string) (err error)
-	CreateContainer(image string) (containerid string, err error)
+	// CreateContainer creates a container that runs until it is removed, with the resource limits and attached to the network.
+	// The mounts are the directories mounted read-only into the container, keyed by their path on the host. They are copied into it when the engine cannot mount them.
+	// The tmpfs are the directories of the container that are kept in memory, so that the files written to them are never stored on disk.
+	CreateContainer(ctx context.Context, image string, resources environmenttypes.ContainerResources, network environmenttypes.ContainerNetwork, mounts map[string]string, tmpfs []string) (containerid string, err error)
 	StopAndRemoveContainer(containerID string) (err error)
 	// RunContainer runs a container from an image
-	RunContainer(image string, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error)
-	Stat(containerID, name string) (fs.FileInfo, error)
+	RunContainer(ctx context.Context, image string, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error)
+	Stat(ctx context.Context, containerID, name string) (fs.FileInfo, error)
 }
 
-func initContainerEngine() (err error) {
-	workingEngine, err = newDockerEngine()
-	if err != nil {
-		return fmt.Errorf("failed to use docker as the container engine. Error: %q", err)
+// initContainerEngine initializes the engine of the runtime.
+// For the auto runtime, it uses the docker daemon at the endpoint if there is one.
+// Else it uses docker if it is available, else podman. The pods of the cluster in the current kubeconfig are only used when the kubernetes runtime is selected.
+func initContainerEngine(runtime string, endpoint dockerEndpointT) (ContainerEngine, error) {
+	if endpoint.Host != "" && (runtime == AutoContainerRuntime || runtime == DockerContainerRuntime) {
+		remoteEngine, err := newRemoteDockerEngine(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to use the docker daemon at %s as the container engine. Error: %q", endpoint.Host, err)
+		}
+		return remoteEngine, nil
 	}
-	//TODO: Add Support for podman
-	if workingEngine == nil {
-		return fmt.Errorf("no working container runtime available")
+	switch runtime {
+	case DockerContainerRuntime:
+		dockerEngine, err := newDockerEngine()
+		if err != nil {
+			return nil, fmt.Errorf("failed to use docker as the container engine. Error: %q", err)
+		}
+		return dockerEngine, nil
+	case PodmanContainerRuntime:
+		podmanEngine, err := newPodmanEngine()
+		if err != nil {
+			return nil, fmt.Errorf("failed to use podman as the container engine. Error: %q", err)
+		}
+		return podmanEngine, nil
+	case KubernetesContainerRuntime:
+		kubernetesEngine, err := newKubernetesEngine()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run the containers as pods in the cluster. Error: %q", err)
+		}
+		logrus.Infof("Running the containers as pods in the namespace %s of the cluster.", kubernetesEngine.namespace)
+		return kubernetesEngine, nil
+	case AutoContainerRuntime:
+	default:
+		return nil, fmt.Errorf("the container runtime %s is not supported. Supported container runtimes are %+v", runtime, ContainerRuntimes)
+	}
+	dockerEngine, dockerErr := newDockerEngine()
+	if dockerErr == nil {
+		return dockerEngine, nil
 	}
-	return nil
+	logrus.Debugf("failed to use docker as the container engine, trying podman. Error: %q", dockerErr)
+	podmanEngine, podmanErr := newPodmanEngine()
+	if podmanErr == nil {
+		logrus.Infof("Docker is not available. Using podman as the container engine.")
+		return podmanEngine, nil
+	}
+	// the kubernetes engine is not tried, since it would create pods in whatever cluster the current kubeconfig points to
+	return nil, fmt.Errorf("no working container runtime available. Select the %s container runtime to run the containers as pods in the cluster of the current kubeconfig. Docker error: %q Podman error: %q", KubernetesContainerRuntime, dockerErr, podmanErr)
 }
 
-// GetContainerEngine gets a working container engine
-func GetContainerEngine() ContainerEngine {
+// GetContainerEngine gets a working container engine.
+// It returns an error if spawning containers is disabled or no container runtime is available.
+// IsDisabled returns true after that, and the transformers that rely on containers are disabled.
+func GetContainerEngine() (ContainerEngine, error) {
 	if !inited {
-		disabled = !qaengine.FetchBoolAnswer(common.ConfigSpawnContainersKey, "Allow spawning containers?", []string{"If this setting is set to false, those transformers that rely on containers will not work."}, false)
-		if !disabled {
-			if err := initContainerEngine(); err != nil {
-				logrus.Fatalf("failed to initialize the container engine. Error: %q", err)
-			}
-		}
 		inited = true
+		runtime := qaengine.FetchSelectAnswer(
+			common.ConfigContainerEngineRuntimeKey,
+			"Select the container runtime to spawn the containers with :",
+			[]string{"auto uses docker if it is available, else podman", "kubernetes runs the containers as pods in the cluster of the current kubeconfig", "none disables the transformers that rely on containers"},
+			AutoContainerRuntime,
+			ContainerRuntimes,
+		)
+		if runtime == NoContainerRuntime {
+			disabled = true
+			engineErr = fmt.Errorf("spawning containers is disabled, since the container runtime is %s", NoContainerRuntime)
+			return nil, engineErr
+		}
+		// spawning containers is allowed by default when a container runtime is selected explicitly
+		if !qaengine.FetchBoolAnswer(common.ConfigSpawnContainersKey, "Allow spawning containers?", []string{"If this setting is set to false, those transformers that rely on containers will not work."}, runtime != AutoContainerRuntime) {
+			disabled = true
+			engineErr = fmt.Errorf("spawning containers is disabled. Set %s to true to enable it", common.ConfigSpawnContainersKey)
+			return nil, engineErr
+		}
+		workingEngine, engineErr = newContainerEngine(runtime)
+		if engineErr != nil {
+			disabled = true
+			logrus.Warnf("The transformers that rely on containers will not work. Error: %q", engineErr)
+		}
+	}
+	if disabled {
+		return nil, engineErr
+	}
+	return workingEngine, nil
+}
+
+// newContainerEngine creates the engine of the runtime, which builds the images using the selected image builder
+func newContainerEngine(runtime string) (ContainerEngine, error) {
+	builder := qaengine.FetchSelectAnswer(
+		common.ConfigContainerEngineImageBuilderKey,
+		"Select the tool to build the container images with :",
+		[]string{"engine builds the images using the container engine. buildkit uses buildctl and the BuildKit daemon in BUILDKIT_HOST", "Use buildkit, buildah or nerdctl on hosts that only have containerd"},
+		engineImageBuilder,
+		imageBuilders,
+	)
+	engine, err := initContainerEngine(runtime, getDockerEndpoint(runtime))
+	if err != nil {
+		if builder == engineImageBuilder {
+			return nil, fmt.Errorf("failed to initialize the container engine. Error: %q", err)
+		}
+		logrus.Warnf("No container engine is available, so the images can be built using %s but the transformers that run containers will not work. Error: %q", builder, err)
+		engine = &unavailableEngine{err: err}
+	}
+	if builder != engineImageBuilder {
+		builderEngine, err := newCLIImageBuilderEngine(engine, builder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize the image builder. Error: %q", err)
+		}
+		engine = builderEngine
+	}
+	return engine, nil
+}
+
+// getDockerEndpoint asks for the docker daemon to spawn the containers in, which can be on another host, like in CI runners.
+// The socket in the config is used if the host is empty, else the daemon in DOCKER_HOST or the socket of a local daemon.
+func getDockerEndpoint(runtime string) dockerEndpointT {
+	endpoint := dockerEndpointT{}
+	if runtime != AutoContainerRuntime && runtime != DockerContainerRuntime {
+		return endpoint
+	}
+	endpoint.Host = qaengine.FetchStringAnswer(
+		common.ConfigContainerEngineHostKey,
+		"Enter the address of the Docker daemon to spawn the containers in :",
+		[]string{"For example tcp://build-host:2376", "Leave it empty to use DOCKER_HOST, the local Docker daemon or podman"},
+		"",
+	)
+	if endpoint.Host == "" {
+		socket := qaengine.FetchStringAnswer(
+			common.ConfigContainerEngineSocketKey,
+			"Enter the path of the socket of the Docker compatible daemon to spawn the containers in :",
+			[]string{"For example ~/.colima/default/docker.sock", "Leave it empty to find the socket of Docker, Colima, Lima, Rancher Desktop or podman"},
+			"",
+		)
+		if socket == "" {
+			return endpoint
+		}
+		host, err := getSocketHost(socket)
+		if err != nil {
+			logrus.Warnf("Finding the socket of the container engine instead. Error: %q", err)
+			return endpoint
+		}
+		endpoint.Host = host
+		return endpoint
+	}
+	endpoint.CertPath = qaengine.FetchStringAnswer(
+		common.ConfigContainerEngineCertPathKey,
+		fmt.Sprintf("Enter the directory with the TLS client certificates (ca.pem, cert.pem and key.pem) of the Docker daemon at %s :", endpoint.Host),
+		[]string{"Leave it empty if the daemon does not use TLS"},
+		os.Getenv("DOCKER_CERT_PATH"),
+	)
+	if endpoint.CertPath != "" {
+		endpoint.TLSVerify = qaengine.FetchBoolAnswer(
+			common.ConfigContainerEngineTLSVerifyKey,
+			fmt.Sprintf("Verify the TLS certificate of the Docker daemon at %s ?", endpoint.Host),
+			[]string{"The certificate is verified using the ca.pem file"},
+			true,
+		)
 	}
-	return workingEngine
+	return endpoint
 }
 
 // IsDisabled returns whether the container environment is disabled
@@ -0,0 +1,104 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+// resetContainerEngine makes GetContainerEngine initialize the engine again using the answers, and restores the engine when the test ends
+func resetContainerEngine(t *testing.T, answers []string) {
+	oldInited, oldDisabled, oldEngine, oldEngineErr := inited, disabled, workingEngine, engineErr
+	inited, disabled, workingEngine, engineErr = false, false, nil, nil
+	t.Cleanup(func() {
+		inited, disabled, workingEngine, engineErr = oldInited, oldDisabled, oldEngine, oldEngineErr
+	})
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", answers, nil, nil, false, false)
+}
+
+func TestGetContainerEngine(t *testing.T) {
+	t.Run("disable spawning containers when the runtime is none", func(t *testing.T) {
+		resetContainerEngine(t, []string{`move2kube.containerengine.runtime="none"`})
+		if engine, err := GetContainerEngine(); err == nil || !IsDisabled() {
+			t.Fatalf("expected spawning containers to be disabled. Actual: %+v", engine)
+		}
+	})
+
+	t.Run("do not spawn containers by default for the auto runtime", func(t *testing.T) {
+		resetContainerEngine(t, []string{`move2kube.containerengine.runtime="auto"`})
+		_, err := GetContainerEngine()
+		if err == nil || !strings.Contains(err.Error(), common.ConfigSpawnContainersKey) || !IsDisabled() {
+			t.Fatalf("expected spawning containers to be disabled until %s is set. Actual: %v", common.ConfigSpawnContainersKey, err)
+		}
+	})
+
+	t.Run("spawn containers by default when the runtime is selected", func(t *testing.T) {
+		resetContainerEngine(t, []string{`move2kube.containerengine.runtime="kubernetes"`})
+		setupTestCluster(t)
+		engine, err := GetContainerEngine()
+		if err != nil {
+			t.Fatalf("failed to get the container engine. Error: %q", err)
+		}
+		if _, ok := engine.(*kubernetesEngine); !ok {
+			t.Fatalf("expected the containers to run as pods. Actual engine: %T", engine)
+		}
+		if sameEngine, err := GetContainerEngine(); err != nil || sameEngine != engine {
+			t.Fatalf("expected the engine to be initialized once. Actual: %T Error: %v", sameEngine, err)
+		}
+	})
+
+	t.Run("degrade when the selected runtime is not available", func(t *testing.T) {
+		resetContainerEngine(t, []string{`move2kube.containerengine.runtime="podman"`})
+		t.Setenv(containerHostEnvVar, "unix://"+filepath.Join(t.TempDir(), "podman.sock"))
+		if _, err := GetContainerEngine(); err == nil || !IsDisabled() {
+			t.Fatalf("expected the transformers that rely on containers to be disabled when podman is not available")
+		}
+	})
+
+	t.Run("build the images without a container engine using an image builder", func(t *testing.T) {
+		resetContainerEngine(t, []string{
+			`move2kube.containerengine.runtime="podman"`,
+			`move2kube.containerengine.imagebuilder="buildah"`,
+		})
+		t.Setenv(containerHostEnvVar, "unix://"+filepath.Join(t.TempDir(), "podman.sock"))
+		setupFakeImageBuilder(t, buildahImageBuilder)
+		engine, err := GetContainerEngine()
+		if err != nil {
+			t.Fatalf("failed to get the container engine. Error: %q", err)
+		}
+		builderEngine, ok := engine.(*cliImageBuilderEngine)
+		if !ok {
+			t.Fatalf("expected the images to be built using buildah. Actual engine: %T", engine)
+		}
+		if _, ok := builderEngine.ContainerEngine.(*unavailableEngine); !ok {
+			t.Fatalf("expected the containers to not be spawned without a container engine. Actual engine: %T", builderEngine.ContainerEngine)
+		}
+	})
+}
+
+func TestInitContainerEngineUnsupportedRuntime(t *testing.T) {
+	if engine, err := initContainerEngine("crio", dockerEndpointT{}); err == nil {
+		t.Fatalf("expected an error for an unsupported container runtime. Actual: %T", engine)
+	}
+}
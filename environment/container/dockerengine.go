@@ -22,65 +22,187 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/konveyor/move2kube/common"
 	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 )
 
 const (
 	testimage = "quay.io/konveyor/hello-world"
+	// rootlessSecurityOption is in the security options of the info of the docker and podman daemons that run as a normal user
+	rootlessSecurityOption   = "name=rootless"
+	dockerHostEnvVar         = "DOCKER_HOST"
+	rootfulDockerSocket      = "/var/run/docker.sock"
+	rootlessDockerSocketName = "docker.sock"
 )
 
 type dockerEngine struct {
 	availableImages map[string]bool
+	imagesMutex     sync.Mutex
 	cli             *client.Client
 	ctx             context.Context
+	// platforms are the platforms that the images were pulled for, which their containers are created for
+	platforms map[string]string
+	// remote is true when the daemon is on another host, so the local directories cannot be mounted into the containers
+	remote bool
+	// rootless is true when the daemon runs as a normal user, so root in the containers is that user on the host
+	rootless bool
+}
+
+// dockerEndpointT is the address of a Docker daemon and the TLS client certificates used to connect to it
+type dockerEndpointT struct {
+	Host string
+	// CertPath is the directory with the ca.pem, cert.pem and key.pem files, like DOCKER_CERT_PATH
+	CertPath  string
+	TLSVerify bool
 }
 
 // newDockerEngine creates a new docker engine instance
 func newDockerEngine() (*dockerEngine, error) {
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host := getLocalDockerHost(); host != "" {
+		logrus.Debugf("using the docker daemon at %s", host)
+		opts = append(opts, client.WithHost(host))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create docker client. Error: %q", err)
 	}
+	return newDockerCompatibleEngine(cli)
+}
+
+// newRemoteDockerEngine creates a new docker engine instance that uses the daemon at the endpoint instead of the one in the environment
+func newRemoteDockerEngine(endpoint dockerEndpointT) (*dockerEngine, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation(), client.WithHost(endpoint.Host)}
+	if endpoint.CertPath != "" {
+		tlsc, err := tlsconfig.Client(tlsconfig.Options{
+			CAFile:             filepath.Join(endpoint.CertPath, "ca.pem"),
+			CertFile:           filepath.Join(endpoint.CertPath, "cert.pem"),
+			KeyFile:            filepath.Join(endpoint.CertPath, "key.pem"),
+			InsecureSkipVerify: !endpoint.TLSVerify,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the TLS client certificates in %s . Error: %q", endpoint.CertPath, err)
+		}
+		opts = append(opts, client.WithHTTPClient(&http.Client{Transport: &http.Transport{TLSClientConfig: tlsc}, CheckRedirect: client.CheckRedirect}))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create docker client for %s . Error: %q", endpoint.Host, err)
+	}
+	return newDockerCompatibleEngine(cli)
+}
+
+// newDockerCompatibleEngine creates a new engine that uses the given client and checks that it can run containers
+func newDockerCompatibleEngine(cli *client.Client) (*dockerEngine, error) {
 	engine := &dockerEngine{
 		availableImages: map[string]bool{},
+		platforms:       map[string]string{},
 		cli:             cli,
-		ctx:             ctx,
+		ctx:             context.Background(),
+		remote:          !strings.HasPrefix(cli.DaemonHost(), "unix://") && !strings.HasPrefix(cli.DaemonHost(), "npipe://"),
 	}
-	_, _, err = engine.RunContainer(testimage, environmenttypes.Command{}, "", "")
+	_, _, err := engine.RunContainer(context.Background(), testimage, environmenttypes.Command{}, "", "")
 	if err != nil {
 		return engine, fmt.Errorf("unable to run test image '%s' as a container. Error: %q", testimage, err)
 	}
+	if info, err := cli.Info(engine.ctx); err != nil {
+		logrus.Debugf("failed to get the info of the daemon at %s . Error: %q", cli.DaemonHost(), err)
+	} else {
+		for _, option := range info.SecurityOptions {
+			if option == rootlessSecurityOption {
+				logrus.Debugf("the daemon at %s is rootless", cli.DaemonHost())
+				engine.rootless = true
+			}
+		}
+	}
 	return engine, nil
 }
 
-func (e *dockerEngine) pullImage(image string) error {
-	if _, ok := e.availableImages[image]; ok {
+// pullImage pulls the image of the platform once in each run, using the pull policy.
+// It uses the credentials in the docker config.json file and asks for the credentials of the registry if they do not work.
+func (e *dockerEngine) pullImage(ctx context.Context, image string, policy environmenttypes.PullPolicy, platform string) error {
+	if err := validatePullPolicy(policy); err != nil {
+		return err
+	}
+	if platform != "" {
+		if _, err := parsePlatform(platform); err != nil {
+			return err
+		}
+	}
+	e.imagesMutex.Lock()
+	_, ok := e.availableImages[image]
+	pulledPlatform := e.platforms[image]
+	e.imagesMutex.Unlock()
+	if ok && (platform == "" || platform == pulledPlatform) {
 		return nil
 	}
+	if common.Offline || policy == environmenttypes.PullIfNotPresent {
+		if inspectOutput, _, err := e.cli.ImageInspectWithRaw(ctx, image); err == nil && ImageMatchesPlatform(inspectOutput, platform) {
+			e.imagesMutex.Lock()
+			e.availableImages[image] = true
+			e.platforms[image] = platform
+			e.imagesMutex.Unlock()
+			return nil
+		} else if common.Offline {
+			if err == nil {
+				err = fmt.Errorf("the local image is for the platform %s/%s", inspectOutput.Os, inspectOutput.Architecture)
+			}
+			return fmt.Errorf("the image '%s' for the platform %s is not available locally and cannot be pulled in offline mode. Pull it using the prefetch command before going offline. Error: %q", image, platform, err)
+		}
+	}
 	logrus.Infof("Pulling container image %s. This could take a few mins.", image)
-	out, err := e.cli.ImagePull(e.ctx, image, types.ImagePullOptions{})
+	registryAuth, err := getRegistryAuth(image)
+	if err != nil {
+		logrus.Debugf("pulling the image %s without credentials. Error: %q", image, err)
+	}
+	out, err := e.cli.ImagePull(ctx, image, types.ImagePullOptions{RegistryAuth: registryAuth, Platform: platform})
+	if err != nil && (errdefs.IsUnauthorized(err) || errdefs.IsForbidden(err) || errdefs.IsNotFound(err)) {
+		// private images are not found when the credentials do not work
+		if explicitAuth, aerr := getExplicitRegistryAuth(image); aerr != nil {
+			logrus.Errorf("failed to get the credentials for the registry of the image %s . Error: %q", image, aerr)
+		} else if explicitAuth != "" {
+			out, err = e.cli.ImagePull(ctx, image, types.ImagePullOptions{RegistryAuth: explicitAuth, Platform: platform})
+		}
+	}
 	if err != nil {
+		e.imagesMutex.Lock()
 		e.availableImages[image] = false
+		e.imagesMutex.Unlock()
 		return fmt.Errorf("failed to pull the image '%s' using the docker client. Error: %q", image, err)
 	}
 	if b, err := io.ReadAll(out); err == nil {
 		logrus.Debug(cast.ToString(b))
 	}
+	e.imagesMutex.Lock()
 	e.availableImages[image] = true
+	e.platforms[image] = platform
+	e.imagesMutex.Unlock()
 	return nil
 }
 
+// PullImage pulls the image of the platform if it has not been pulled already in this run, using the pull policy
+func (e *dockerEngine) PullImage(ctx context.Context, image string, policy environmenttypes.PullPolicy, platform string) error {
+	return e.pullImage(ctx, image, policy, platform)
+}
+
 // RunCmdInContainer executes a container
-func (e *dockerEngine) RunCmdInContainer(containerID string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitCode int, err error) {
+func (e *dockerEngine) RunCmdInContainer(ctx context.Context, containerID string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitCode int, err error) {
 	execConfig := types.ExecConfig{
 		AttachStdout: true,
 		AttachStderr: true,
@@ -88,20 +210,22 @@ func (e *dockerEngine) RunCmdInContainer(containerID string, cmd environmenttype
 		WorkingDir:   workingdir,
 		Env:          env,
 	}
-	cresp, err := e.cli.ContainerExecCreate(e.ctx, containerID, execConfig)
+	cresp, err := e.cli.ContainerExecCreate(ctx, containerID, execConfig)
 	if err != nil {
 		return
 	}
-	aresp, err := e.cli.ContainerExecAttach(e.ctx, cresp.ID, types.ExecStartCheck{})
+	aresp, err := e.cli.ContainerExecAttach(ctx, cresp.ID, types.ExecStartCheck{})
 	if err != nil {
 		return
 	}
 	defer aresp.Close()
 
 	var outBuf, errBuf bytes.Buffer
+	outWriter, errWriter, flushOutput := getOutputWriters(ctx, cmd, &outBuf, &errBuf)
 	outputDone := make(chan error)
 	go func() {
-		_, err = stdcopy.StdCopy(&outBuf, &errBuf, aresp.Reader)
+		_, err = stdcopy.StdCopy(outWriter, errWriter, aresp.Reader)
+		flushOutput()
 		outputDone <- err
 	}()
 
@@ -112,8 +236,8 @@ func (e *dockerEngine) RunCmdInContainer(containerID string, cmd environmenttype
 		}
 		break
 
-	case <-e.ctx.Done():
-		return "", "", 0, e.ctx.Err()
+	case <-ctx.Done():
+		return "", "", 0, ctx.Err()
 	}
 
 	stdoutbytes, err := io.ReadAll(&outBuf)
@@ -124,7 +248,7 @@ func (e *dockerEngine) RunCmdInContainer(containerID string, cmd environmenttype
 	if err != nil {
 		return
 	}
-	res, err := e.cli.ContainerExecInspect(e.ctx, cresp.ID)
+	res, err := e.cli.ContainerExecInspect(ctx, cresp.ID)
 	if err != nil {
 		return
 	}
@@ -134,6 +258,17 @@ func (e *dockerEngine) RunCmdInContainer(containerID string, cmd environmenttype
 	return
 }
 
+// getPlatform returns the platform that the image was pulled for, or nil if it is the platform of the host
+func (e *dockerEngine) getPlatform(image string) (*specs.Platform, error) {
+	e.imagesMutex.Lock()
+	platform := e.platforms[image]
+	e.imagesMutex.Unlock()
+	if platform == "" {
+		return nil, nil
+	}
+	return parsePlatform(platform)
+}
+
 // InspectImage returns inspect output for an image
 func (e *dockerEngine) InspectImage(image string) (types.ImageInspect, error) {
 	inspectOutput, _, err := e.cli.ImageInspectWithRaw(e.ctx, image)
@@ -144,32 +279,65 @@ func (e *dockerEngine) InspectImage(image string) (types.ImageInspect, error) {
 	return inspectOutput, nil
 }
 
-// CreateContainer creates a container
-func (e *dockerEngine) CreateContainer(image string) (containerid string, err error) {
-	if err := e.pullImage(image); err != nil {
+// CreateContainer creates a container.
+// The mounts are bind mounted, except for remote daemons, where they are copied into the container since they are not on the host of the daemon.
+func (e *dockerEngine) CreateContainer(ctx context.Context, image string, resources environmenttypes.ContainerResources, network environmenttypes.ContainerNetwork, mounts map[string]string, tmpfs []string) (containerid string, err error) {
+	network, err = getNetwork(network)
+	if err != nil {
+		return "", err
+	}
+	if err := e.pullImage(ctx, image, "", ""); err != nil {
 		return "", fmt.Errorf("failed to pull the image '%s'. Error: %q", image, err)
 	}
+	dockerResources, err := getDockerResources(resources)
+	if err != nil {
+		return "", fmt.Errorf("failed to limit the resources of the container of the image %s . Error: %q", image, err)
+	}
 	contconfig := &container.Config{
 		Image: image,
 		Cmd:   []string{"sh", "-c", "tail -f /dev/null"},
 	}
-	resp, err := e.cli.ContainerCreate(e.ctx, contconfig, nil, nil, nil, "")
+	hostconfig := &container.HostConfig{Resources: dockerResources, NetworkMode: container.NetworkMode(network)}
+	if !e.remote {
+		for sp, dp := range mounts {
+			hostconfig.Mounts = append(hostconfig.Mounts, mount.Mount{Type: mount.TypeBind, Source: sp, Target: dp, ReadOnly: true})
+		}
+	}
+	if len(tmpfs) > 0 {
+		hostconfig.Tmpfs = map[string]string{}
+		for _, dir := range tmpfs {
+			// the commands in the container may run as any user, and the files they write are only readable by that user
+			hostconfig.Tmpfs[dir] = "rw,nosuid,nodev,mode=1777"
+		}
+	}
+	platform, err := e.getPlatform(image)
+	if err != nil {
+		return "", err
+	}
+	resp, err := e.cli.ContainerCreate(ctx, contconfig, hostconfig, nil, platform, "")
 	if err != nil {
 		logrus.Debugf("Container creation failed with image %s with no volumes", image)
 		return "", err
 	}
-	err = e.cli.ContainerStart(e.ctx, resp.ID, types.ContainerStartOptions{})
+	err = e.cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
 	if err != nil {
 		logrus.Debugf("Container creation failed with image %s with no volumes", image)
 		return "", err
 	}
+	if e.remote && len(mounts) > 0 {
+		logrus.Debugf("copying the directories %+v into the container %s , since they cannot be mounted from the host of a remote daemon", mounts, resp.ID)
+		if err := e.CopyDirsIntoContainer(ctx, resp.ID, mounts); err != nil {
+			e.removeContainer(resp.ID)
+			return "", fmt.Errorf("failed to copy the directories %+v into the container of the image %s . Error: %q", mounts, image, err)
+		}
+	}
 	logrus.Debugf("Container %s created with image %s", resp.ID, image)
 	return resp.ID, nil
 }
 
 // CreateContainer creates a container
 func (e *dockerEngine) StopAndRemoveContainer(containerID string) (err error) {
-	err = e.cli.ContainerRemove(e.ctx, containerID, types.ContainerRemoveOptions{Force: true})
+	err = e.removeContainer(containerID)
 	if err != nil {
 		logrus.Errorf("Unable to delete container with containerid %s : %s", containerID, err)
 		return err
@@ -177,41 +345,74 @@ func (e *dockerEngine) StopAndRemoveContainer(containerID string) (err error) {
 	return nil
 }
 
+// removeContainer removes the container with its own context, so that the containers are removed even after the run is interrupted
+func (e *dockerEngine) removeContainer(containerID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	return e.cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
+}
+
 // CopyDirsIntoImage creates a container
-func (e *dockerEngine) CopyDirsIntoImage(image, newImageName string, paths map[string]string) (err error) {
-	if err := e.pullImage(image); err != nil {
+func (e *dockerEngine) CopyDirsIntoImage(ctx context.Context, image, newImageName string, paths map[string]string) (err error) {
+	if err := e.pullImage(ctx, image, "", ""); err != nil {
 		return fmt.Errorf("failed to pull the image '%s'. Error: %q", image, err)
 	}
-	cid, err := e.CreateContainer(image)
+	// the containers of the new image are created for the platform of the image
+	e.imagesMutex.Lock()
+	e.platforms[newImageName] = e.platforms[image]
+	e.imagesMutex.Unlock()
+	// the images prepared in earlier runs from the same image and directories are reused, from the engine or from the environment cache
+	environmentHash := ""
+	environmentKey := getEnvironmentKey(image, paths)
+	if len(paths) > 0 {
+		if environmentHash, err = e.getEnvironmentHash(image, paths); err != nil {
+			logrus.Warnf("Unable to check whether the image %s was prepared in an earlier run. Error: %q", newImageName, err)
+			environmentHash = ""
+		} else if e.useCachedEnvironment(newImageName, environmentHash) {
+			return nil
+		} else if common.EnvironmentCacheDir != "" && e.loadCachedEnvironment(newImageName, environmentHash) {
+			return nil
+		}
+	}
+	cid, err := e.CreateContainer(ctx, image, environmenttypes.ContainerResources{}, environmenttypes.NetworkNone, nil, nil)
 	if err != nil {
 		logrus.Errorf("Unable to create container with base image %s : %s", image, err)
 		return err
 	}
 	for sp, dp := range paths {
-		err = copyDirToContainer(e.ctx, e.cli, cid, sp, dp)
+		err = copyDirToContainer(ctx, e.cli, cid, sp, dp, getContainerFilesOwner(e.rootless))
 		if err != nil {
 			logrus.Debugf("Container data copy failed for image %s with volume %s:%s : %s", image, sp, dp, err)
 			return err
 		}
 	}
-	_, err = e.cli.ContainerCommit(e.ctx, cid, types.ContainerCommitOptions{
+	commitOptions := types.ContainerCommitOptions{
 		Reference: newImageName,
-	})
+	}
+	if environmentHash != "" {
+		commitOptions.Changes = getEnvironmentLabelChanges(environmentHash, environmentKey)
+	}
+	_, err = e.cli.ContainerCommit(ctx, cid, commitOptions)
 	if err != nil {
 		logrus.Errorf("Unable to commit container as image : %s", err)
 		return err
 	}
+	e.imagesMutex.Lock()
 	e.availableImages[newImageName] = true
+	e.imagesMutex.Unlock()
 	err = e.StopAndRemoveContainer(cid)
 	if err != nil {
 		logrus.Errorf("Unable to stop and remove container %s : %s", cid, err)
 	}
+	if environmentHash != "" {
+		e.cacheEnvironment(newImageName, environmentHash, environmentKey)
+	}
 	return nil
 }
 
-func (e *dockerEngine) CopyDirsIntoContainer(containerID string, paths map[string]string) (err error) {
+func (e *dockerEngine) CopyDirsIntoContainer(ctx context.Context, containerID string, paths map[string]string) (err error) {
 	for sp, dp := range paths {
-		err = copyDirToContainer(e.ctx, e.cli, containerID, sp, dp)
+		err = copyDirToContainer(ctx, e.cli, containerID, sp, dp, getContainerFilesOwner(e.rootless))
 		if err != nil {
 			logrus.Debugf("Container data copy failed for image %s with volume %s:%s : %s", containerID, sp, dp, err)
 			return err
@@ -220,8 +421,8 @@ func (e *dockerEngine) CopyDirsIntoContainer(containerID string, paths map[strin
 	return nil
 }
 
-func (e *dockerEngine) Stat(containerID string, name string) (fs.FileInfo, error) {
-	stat, err := e.cli.ContainerStatPath(e.ctx, containerID, name)
+func (e *dockerEngine) Stat(ctx context.Context, containerID string, name string) (fs.FileInfo, error) {
+	stat, err := e.cli.ContainerStatPath(ctx, containerID, name)
 	if err != nil {
 		return nil, err
 	}
@@ -231,9 +432,9 @@ func (e *dockerEngine) Stat(containerID string, name string) (fs.FileInfo, error
 }
 
 // CopyDirsFromContainer creates a container
-func (e *dockerEngine) CopyDirsFromContainer(containerID string, paths map[string]string) (err error) {
+func (e *dockerEngine) CopyDirsFromContainer(ctx context.Context, containerID string, paths map[string]string) (err error) {
 	for sp, dp := range paths {
-		err = copyFromContainer(e.ctx, e.cli, containerID, sp, dp)
+		err = copyFromContainer(ctx, e.cli, containerID, sp, dp)
 		if err != nil {
 			logrus.Debugf("Container data copy failed for image %s with volume %s:%s : %s", containerID, sp, dp, err)
 			return err
@@ -243,26 +444,80 @@ func (e *dockerEngine) CopyDirsFromContainer(containerID string, paths map[strin
 }
 
 // BuildImage creates a container
-func (e *dockerEngine) BuildImage(image, context, dockerfile string) (err error) {
+func (e *dockerEngine) BuildImage(ctx context.Context, image, contextPath, dockerfile string) (err error) {
+	// images that were already built from the same build context and Dockerfile are not built again
+	buildHash, err := getBuildHash(contextPath, dockerfile)
+	if err != nil {
+		logrus.Warnf("Unable to check whether the image %s has already been built. Error: %q", image, err)
+	} else if e.hasBuiltImage(image, buildHash) || (common.BuildCacheDir != "" && e.loadCachedImage(image, buildHash)) {
+		e.imagesMutex.Lock()
+		e.availableImages[image] = true
+		e.imagesMutex.Unlock()
+		logrus.Infof("Using the already built container image %s", image)
+		return nil
+	}
 	logrus.Infof("Building container image %s. This could take a few mins.", image)
-	reader := readDirAsTar(context, "")
-	resp, err := e.cli.ImageBuild(e.ctx, reader, types.ImageBuildOptions{
+	reader := readDirAsTar(contextPath, "", nil)
+	buildOptions := types.ImageBuildOptions{
 		Dockerfile: dockerfile,
 		Tags:       []string{image},
-	})
+	}
+	if buildHash != "" {
+		buildOptions.Labels = map[string]string{buildHashLabel: buildHash}
+		// the earlier version of the image loaded from the cache provides the layers that did not change
+		buildOptions.CacheFrom = []string{image}
+	}
+	resp, err := e.cli.ImageBuild(ctx, reader, buildOptions)
 	if err != nil {
 		logrus.Infof("Image creation failed with image %s with no volumes : %s", image, err)
 		return err
 	}
 	defer resp.Body.Close()
-	response, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logrus.Errorf("Unable to read data from image build process : %s", err)
-		return err
+	response := bytes.Buffer{}
+	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, &response, 0, false, nil); err != nil {
+		logrus.Debugf("%s", response.String())
+		return fmt.Errorf("failed to build the image %s . Error: %q", image, err)
 	}
-	logrus.Debugf("%s", response)
+	logrus.Debugf("%s", response.String())
+	e.imagesMutex.Lock()
 	e.availableImages[image] = true
+	e.imagesMutex.Unlock()
 	logrus.Debugf("Built image %s", image)
+	if buildHash != "" && common.BuildCacheDir != "" {
+		e.saveCachedImage(image, buildHash)
+	}
+	return nil
+}
+
+// TagImage creates a new tag for an existing image
+func (e *dockerEngine) TagImage(image, newImageName string) (err error) {
+	if err := e.cli.ImageTag(e.ctx, image, newImageName); err != nil {
+		return fmt.Errorf("failed to tag the image %s as %s . Error: %q", image, newImageName, err)
+	}
+	return nil
+}
+
+// PushImage pushes an image to its registry
+func (e *dockerEngine) PushImage(ctx context.Context, image string) (err error) {
+	if common.Offline {
+		return fmt.Errorf("the image %s cannot be pushed in offline mode", image)
+	}
+	logrus.Infof("Pushing container image %s. This could take a few mins.", image)
+	registryAuth, err := getRegistryAuth(image)
+	if err != nil {
+		return fmt.Errorf("failed to get the credentials to push the image %s . Error: %q", image, err)
+	}
+	out, err := e.cli.ImagePush(ctx, image, types.ImagePushOptions{RegistryAuth: registryAuth})
+	if err != nil {
+		return fmt.Errorf("failed to push the image %s . Error: %q", image, err)
+	}
+	defer out.Close()
+	response := bytes.Buffer{}
+	if err := jsonmessage.DisplayJSONMessagesStream(out, &response, 0, false, nil); err != nil {
+		logrus.Debugf("%s", response.String())
+		return fmt.Errorf("failed to push the image %s . Error: %q", image, err)
+	}
+	logrus.Debugf("%s", response.String())
 	return nil
 }
 
@@ -277,21 +532,17 @@ func (e *dockerEngine) RemoveImage(image string) (err error) {
 }
 
 // RunContainer executes a container
-func (e *dockerEngine) RunContainer(image string, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error) {
-	if err := e.pullImage(image); err != nil {
+func (e *dockerEngine) RunContainer(ctx context.Context, image string, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error) {
+	if err := e.pullImage(ctx, image, "", ""); err != nil {
 		return "", false, fmt.Errorf("failed to pull the image '%s'. Error: %q", image, err)
 	}
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-	if err != nil {
-		return "", false, fmt.Errorf("failed to create a docker client. Error: %q", err)
-	}
+	cli := e.cli
 	contconfig := &container.Config{Image: image}
 	if (volsrc == "" && voldest != "") || (volsrc != "" && voldest == "") {
 		logrus.Warnf("Either volume source (%s) or destination (%s) is empty. Ingoring volume mount.", volsrc, voldest)
 	}
 	hostconfig := &container.HostConfig{}
-	if volsrc != "" && voldest != "" {
+	if volsrc != "" && voldest != "" && !e.remote {
 		hostconfig.Mounts = []mount.Mount{
 			{
 				Type:     mount.TypeBind,
@@ -309,17 +560,24 @@ func (e *dockerEngine) RunContainer(image string, cmd environmenttypes.Command,
 			return "", false, fmt.Errorf("container creation failed for image '%s' with no volumes", image)
 		}
 		logrus.Debugf("Container %s created with image %s with no volumes", resp.ID, image)
-		defer cli.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+		defer e.removeContainer(resp.ID)
 		if volsrc != "" && voldest != "" {
-			err = copyDir(ctx, cli, resp.ID, volsrc, voldest)
+			err = copyDir(ctx, cli, resp.ID, volsrc, voldest, getContainerFilesOwner(e.rootless))
 			if err != nil {
 				return "", false, fmt.Errorf("container data copy failed for image '%s' with volume (%s:%s). Error: %q", image, volsrc, voldest, err)
 			}
 			logrus.Debugf("Data copied from (%s) to (%s) in container '%s' with image '%s'", volsrc, voldest, resp.ID, image)
 		}
+	} else if volsrc != "" && voldest != "" && e.remote {
+		// the volume source is not on the host of a remote daemon, so it is copied into the container instead
+		if err := copyDir(ctx, cli, resp.ID, volsrc, voldest, getContainerFilesOwner(e.rootless)); err != nil {
+			e.removeContainer(resp.ID)
+			return "", false, fmt.Errorf("container data copy failed for image '%s' with volume (%s:%s). Error: %q", image, volsrc, voldest, err)
+		}
+		logrus.Debugf("Data copied from (%s) to (%s) in container '%s' with image '%s'", volsrc, voldest, resp.ID, image)
 	}
 	logrus.Debugf("Container %s created with image %s", resp.ID, image)
-	defer cli.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+	defer e.removeContainer(resp.ID)
 	if err = cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
 		return "", false, fmt.Errorf("failed to startup the container '%s' . Error: %q", resp.ID, err)
 	}
@@ -17,8 +17,15 @@
 package container
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/docker/docker/client"
+	"github.com/konveyor/move2kube/common"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
 	"github.com/sirupsen/logrus"
 )
 
@@ -30,7 +37,7 @@ func TestIsBuilderAvailable(t *testing.T) {
 		image := "quay.io/konveyor/move2kube"
 
 		// Test
-		if err := provider.pullImage(image); err != nil {
+		if err := provider.pullImage(context.Background(), image, "", ""); err != nil {
 			t.Fatalf("Failed to find the image '%s' locally and/or pull it. Error: %q", image, err)
 		}
 	})
@@ -40,13 +47,13 @@ func TestIsBuilderAvailable(t *testing.T) {
 		image := "quay.io/konveyor/move2kube"
 
 		// Test
-		if err := provider.pullImage(image); err != nil {
+		if err := provider.pullImage(context.Background(), image, "", ""); err != nil {
 			t.Fatalf("Failed to find the image '%s' locally and/or pull it. Error: %q", image, err)
 		}
 		if !provider.availableImages[image] {
 			t.Fatalf("Failed to add the image %q to the list of available images", image)
 		}
-		if err := provider.pullImage(image); err != nil {
+		if err := provider.pullImage(context.Background(), image, "", ""); err != nil {
 			t.Fatalf("Failed to find the image '%s' locally and/or pull it. Error: %q", image, err)
 		}
 	})
@@ -54,8 +61,73 @@ func TestIsBuilderAvailable(t *testing.T) {
 	t.Run("check for a non existent image", func(t *testing.T) {
 		provider, _ := newDockerEngine()
 		image := "this/doesnotexist:foobar"
-		if err := provider.pullImage(image); err == nil {
+		if err := provider.pullImage(context.Background(), image, "", ""); err == nil {
 			t.Fatalf("Should not have succeeded. The image '%s' does not exist", image)
 		}
 	})
 }
+
+// newTestDockerEngine returns an engine that uses a fake docker daemon which serves the requests using the handler
+func newTestDockerEngine(t *testing.T, handler http.HandlerFunc) *dockerEngine {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_ping") {
+			w.Header().Set("API-Version", "1.41")
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")), client.WithAPIVersionNegotiation())
+	if err != nil {
+		t.Fatalf("failed to create the docker client. Error: %q", err)
+	}
+	return &dockerEngine{availableImages: map[string]bool{}, platforms: map[string]string{}, cli: cli, ctx: context.Background()}
+}
+
+func TestPullImageOffline(t *testing.T) {
+	logrus.SetLevel(logrus.DebugLevel)
+	localImage := "quay.io/acme/local:v1"
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/"+localImage+"/json"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"Id": "sha256:0123", "Os": "linux", "Architecture": "amd64"}`))
+		case strings.HasSuffix(r.URL.Path, "/json"):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "No such image"}`))
+		default:
+			t.Errorf("unexpected request to the docker daemon in offline mode: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+	common.Offline = true
+	t.Cleanup(func() { common.Offline = false })
+
+	t.Run("use the local image", func(t *testing.T) {
+		engine := newTestDockerEngine(t, handler)
+		if err := engine.PullImage(context.Background(), localImage, environmenttypes.PullAlways, ""); err != nil {
+			t.Fatalf("expected the local image to be used in offline mode. Error: %q", err)
+		}
+		if !engine.availableImages[localImage] {
+			t.Fatalf("expected the local image to be marked as available")
+		}
+	})
+
+	t.Run("fail for the images that are not available locally", func(t *testing.T) {
+		engine := newTestDockerEngine(t, handler)
+		if err := engine.PullImage(context.Background(), "quay.io/acme/missing:v1", environmenttypes.PullAlways, ""); err == nil || !strings.Contains(err.Error(), "offline mode") {
+			t.Fatalf("expected pulling a missing image to fail in offline mode. Actual: %v", err)
+		}
+		if err := engine.PullImage(context.Background(), localImage, environmenttypes.PullAlways, "linux/arm64"); err == nil || !strings.Contains(err.Error(), "linux/amd64") {
+			t.Fatalf("expected pulling the image for another platform to fail in offline mode. Actual: %v", err)
+		}
+	})
+
+	t.Run("do not push images", func(t *testing.T) {
+		engine := newTestDockerEngine(t, handler)
+		if err := engine.PushImage(context.Background(), localImage); err == nil {
+			t.Fatalf("expected pushing an image to fail in offline mode")
+		}
+	})
+}
@@ -0,0 +1,197 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// environmentCacheRepo is the repository of the prepared images of the environments, which are kept in the engine across runs
+	environmentCacheRepo = types.AppNameShort + "-environment"
+	// environmentHashLabel is the label on the prepared images that stores the hash of the base image and of the directories copied into it
+	environmentHashLabel = types.GroupName + "/environment-hash"
+	// environmentKeyLabel is the label on the prepared images that identifies the base image and the paths they were prepared from,
+	// so that the images prepared from older versions of the directories can be removed
+	environmentKeyLabel = types.GroupName + "/environment-key"
+)
+
+var (
+	// environmentDirHashes are the hashes of the directories copied into the images of the environments, keyed by their path.
+	// The source directory is copied into the image of each transformer, so it is only hashed once in each run.
+	environmentDirHashes      = map[string][]byte{}
+	environmentDirHashesMutex sync.Mutex
+)
+
+// getEnvironmentHash returns a hash of the base image and of the directories copied into it
+func (e *dockerEngine) getEnvironmentHash(image string, paths map[string]string) (string, error) {
+	inspectOutput, err := e.InspectImage(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect the image %s . Error: %q", image, err)
+	}
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "image:%s\n", inspectOutput.ID)
+	if owner := getContainerFilesOwner(e.rootless); owner != nil {
+		fmt.Fprintf(hasher, "owner:%d:%d\n", owner.UID, owner.GID)
+	}
+	for _, sp := range getSortedSources(paths) {
+		dirHash, err := getEnvironmentDirHash(sp)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hasher, "%s:%x\n", paths[sp], dirHash)
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// getEnvironmentKey returns a hash of the name of the base image and of the paths copied into it, which does not change with their contents
+func getEnvironmentKey(image string, paths map[string]string) string {
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "image:%s\n", image)
+	for _, sp := range getSortedSources(paths) {
+		fmt.Fprintf(hasher, "%s:%s\n", sp, paths[sp])
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil))
+}
+
+// getSortedSources returns the directories to copy in sorted order
+func getSortedSources(paths map[string]string) []string {
+	sources := []string{}
+	for sp := range paths {
+		sources = append(sources, sp)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// getEnvironmentDirHash returns the hash of a directory copied into the image of an environment
+func getEnvironmentDirHash(dir string) ([]byte, error) {
+	environmentDirHashesMutex.Lock()
+	defer environmentDirHashesMutex.Unlock()
+	if dirHash, ok := environmentDirHashes[dir]; ok {
+		return dirHash, nil
+	}
+	hasher := sha256.New()
+	if err := hashDir(hasher, dir); err != nil {
+		return nil, fmt.Errorf("failed to hash the directory %s . Error: %q", dir, err)
+	}
+	dirHash := hasher.Sum(nil)
+	environmentDirHashes[dir] = dirHash
+	return dirHash, nil
+}
+
+// getEnvironmentCacheImage returns the tag of the prepared image of the environment for the hash
+func getEnvironmentCacheImage(environmentHash string) string {
+	return environmentCacheRepo + ":" + environmentHash
+}
+
+// getEnvironmentCachePath returns the path of the cached image of the environment for the hash
+func getEnvironmentCachePath(environmentHash string) string {
+	return filepath.Join(common.EnvironmentCacheDir, environmentHash+".tar")
+}
+
+// getEnvironmentLabelChanges returns the changes that label the prepared image of an environment when committing it
+func getEnvironmentLabelChanges(environmentHash, environmentKey string) []string {
+	return []string{fmt.Sprintf("LABEL %s=%s %s=%s", environmentHashLabel, environmentHash, environmentKeyLabel, environmentKey)}
+}
+
+// useCachedEnvironment tags the image prepared in an earlier run for the hash as the image, if it is still in the engine
+func (e *dockerEngine) useCachedEnvironment(image, environmentHash string) bool {
+	cacheImage := getEnvironmentCacheImage(environmentHash)
+	inspectOutput, err := e.InspectImage(cacheImage)
+	if err != nil || inspectOutput.Config == nil || inspectOutput.Config.Labels[environmentHashLabel] != environmentHash {
+		return false
+	}
+	if err := e.TagImage(cacheImage, image); err != nil {
+		logrus.Warnf("Failed to use the image %s prepared in an earlier run. Error: %q", cacheImage, err)
+		return false
+	}
+	logrus.Debugf("using the image %s prepared in an earlier run for the environment image %s", cacheImage, image)
+	e.imagesMutex.Lock()
+	e.availableImages[image] = true
+	e.imagesMutex.Unlock()
+	return true
+}
+
+// loadCachedEnvironment loads the prepared image for the hash from the environment cache directory and tags it as the image
+func (e *dockerEngine) loadCachedEnvironment(image, environmentHash string) bool {
+	cachePath := getEnvironmentCachePath(environmentHash)
+	if _, err := os.Stat(cachePath); err != nil {
+		return false
+	}
+	logrus.Infof("Loading the environment image %s from the environment cache at path %s", image, cachePath)
+	if err := e.loadImageArchive(cachePath); err != nil {
+		logrus.Warnf("Failed to load the image %s from the environment cache at path %s . Error: %q", image, cachePath, err)
+		return false
+	}
+	return e.useCachedEnvironment(image, environmentHash)
+}
+
+// cacheEnvironment keeps the prepared image of an environment in the engine, and in the environment cache directory if there is one,
+// so that later runs do not have to prepare it again. The images prepared from older versions of the directories are removed.
+func (e *dockerEngine) cacheEnvironment(image, environmentHash, environmentKey string) {
+	cacheImage := getEnvironmentCacheImage(environmentHash)
+	if err := e.TagImage(image, cacheImage); err != nil {
+		logrus.Warnf("Failed to keep the image %s for later runs. Error: %q", image, err)
+		return
+	}
+	e.removeStaleEnvironments(environmentHash, environmentKey)
+	if common.EnvironmentCacheDir == "" {
+		return
+	}
+	cachePath := getEnvironmentCachePath(environmentHash)
+	if _, err := os.Stat(cachePath); err == nil {
+		return
+	}
+	if err := e.saveImageArchive(cacheImage, common.EnvironmentCacheDir, cachePath); err != nil {
+		logrus.Warnf("Failed to save the image %s to the environment cache. Error: %q", image, err)
+	}
+}
+
+// removeStaleEnvironments removes the tags of the images that were prepared from the same base image and paths, but from older versions of the directories
+func (e *dockerEngine) removeStaleEnvironments(environmentHash, environmentKey string) {
+	images, err := e.cli.ImageList(e.ctx, dockertypes.ImageListOptions{Filters: filters.NewArgs(filters.Arg("label", environmentKeyLabel+"="+environmentKey))})
+	if err != nil {
+		logrus.Debugf("failed to list the images prepared in earlier runs. Error: %q", err)
+		return
+	}
+	for _, image := range images {
+		if image.Labels[environmentHashLabel] == environmentHash {
+			continue
+		}
+		for _, tag := range image.RepoTags {
+			if !strings.HasPrefix(tag, environmentCacheRepo+":") {
+				continue
+			}
+			if _, err := e.cli.ImageRemove(e.ctx, tag, dockertypes.ImageRemoveOptions{}); err != nil {
+				logrus.Debugf("failed to remove the image %s prepared in an earlier run. Error: %q", tag, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"testing"
+)
+
+func TestGetEnvironmentKey(t *testing.T) {
+	paths := map[string]string{"/src": "/var/source", "/tmp/out": "/var/output"}
+	key := getEnvironmentKey("quay.io/konveyor/move2kube", paths)
+	if key != getEnvironmentKey("quay.io/konveyor/move2kube", map[string]string{"/tmp/out": "/var/output", "/src": "/var/source"}) {
+		t.Fatalf("the key of the same image and paths changed")
+	}
+	if key == getEnvironmentKey("quay.io/konveyor/move2kube", map[string]string{"/src": "/var/source"}) {
+		t.Fatalf("the key did not change when the paths changed")
+	}
+	if key == getEnvironmentKey("quay.io/konveyor/hello-world", paths) {
+		t.Fatalf("the key did not change when the image changed")
+	}
+}
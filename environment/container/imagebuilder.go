@@ -0,0 +1,247 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/konveyor/move2kube/common"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// engineImageBuilder builds the images using the container engine
+	engineImageBuilder = "engine"
+	// buildKitImageBuilder builds the images using buildctl and the BuildKit daemon in BUILDKIT_HOST
+	buildKitImageBuilder = "buildkit"
+	buildahImageBuilder  = "buildah"
+	nerdctlImageBuilder  = "nerdctl"
+)
+
+var (
+	imageBuilders = []string{engineImageBuilder, buildKitImageBuilder, buildahImageBuilder, nerdctlImageBuilder}
+	// imageBuilderCommands are the commands used by the image builders other than the container engine
+	imageBuilderCommands = map[string]string{
+		buildKitImageBuilder: "buildctl",
+		buildahImageBuilder:  "buildah",
+		nerdctlImageBuilder:  "nerdctl",
+	}
+)
+
+// imageBuildArgsT are the arguments an image was built with
+type imageBuildArgsT struct {
+	context    string
+	dockerfile string
+}
+
+// cliImageBuilderEngine builds, tags and pushes the images using the command line of buildctl, buildah or nerdctl,
+// and uses the container engine for everything else.
+type cliImageBuilderEngine struct {
+	ContainerEngine
+	builder string
+	// builds are the images built using buildctl, since BuildKit can only tag and push an image by building it again from the cache
+	builds      map[string]imageBuildArgsT
+	buildsMutex sync.Mutex
+}
+
+// newCLIImageBuilderEngine creates an engine that builds the images using the builder
+func newCLIImageBuilderEngine(engine ContainerEngine, builder string) (*cliImageBuilderEngine, error) {
+	command, ok := imageBuilderCommands[builder]
+	if !ok {
+		return nil, fmt.Errorf("the image builder %s is not supported. Supported image builders are %+v", builder, imageBuilders)
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("the command %s of the image builder %s was not found. Error: %q", command, builder, err)
+	}
+	return &cliImageBuilderEngine{ContainerEngine: engine, builder: builder, builds: map[string]imageBuildArgsT{}}, nil
+}
+
+// BuildImage builds the image using the builder
+func (e *cliImageBuilderEngine) BuildImage(ctx context.Context, image, contextPath, dockerfile string) (err error) {
+	if dockerfile == "" {
+		dockerfile = common.DefaultDockerfileName
+	}
+	logrus.Infof("Building container image %s using %s. This could take a few mins.", image, e.builder)
+	dockerfilePath := filepath.Join(contextPath, dockerfile)
+	switch e.builder {
+	case buildKitImageBuilder:
+		err = e.runBuildctl(ctx, image, imageBuildArgsT{context: contextPath, dockerfile: dockerfilePath}, false)
+		if err == nil {
+			e.buildsMutex.Lock()
+			e.builds[image] = imageBuildArgsT{context: contextPath, dockerfile: dockerfilePath}
+			e.buildsMutex.Unlock()
+		}
+	case buildahImageBuilder:
+		err = e.run(ctx, "bud", "--layers", "-f", dockerfilePath, "-t", image, contextPath)
+	default:
+		err = e.run(ctx, "build", "-f", dockerfilePath, "-t", image, contextPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build the image %s using %s . Error: %q", image, e.builder, err)
+	}
+	logrus.Debugf("Built image %s using %s", image, e.builder)
+	return nil
+}
+
+// TagImage creates a new tag for an existing image
+func (e *cliImageBuilderEngine) TagImage(image, newImageName string) (err error) {
+	if e.builder == buildKitImageBuilder {
+		e.buildsMutex.Lock()
+		defer e.buildsMutex.Unlock()
+		args, ok := e.builds[image]
+		if !ok {
+			return fmt.Errorf("the image %s was not built using %s , so it cannot be tagged as %s", image, e.builder, newImageName)
+		}
+		e.builds[newImageName] = args
+		return nil
+	}
+	if err := e.run(context.Background(), "tag", image, newImageName); err != nil {
+		return fmt.Errorf("failed to tag the image %s as %s using %s . Error: %q", image, newImageName, e.builder, err)
+	}
+	return nil
+}
+
+// PushImage pushes an image to its registry.
+// buildah and nerdctl use the credentials in their own auth files and in the docker config.json file.
+func (e *cliImageBuilderEngine) PushImage(ctx context.Context, image string) (err error) {
+	if common.Offline {
+		return fmt.Errorf("the image %s cannot be pushed in offline mode", image)
+	}
+	logrus.Infof("Pushing container image %s using %s. This could take a few mins.", image, e.builder)
+	if e.builder == buildKitImageBuilder {
+		e.buildsMutex.Lock()
+		args, ok := e.builds[image]
+		e.buildsMutex.Unlock()
+		if !ok {
+			return fmt.Errorf("the image %s was not built using %s , so it cannot be pushed", image, e.builder)
+		}
+		err = e.runBuildctl(ctx, image, args, true)
+	} else {
+		err = e.run(ctx, "push", image)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to push the image %s using %s . Error: %q", image, e.builder, err)
+	}
+	return nil
+}
+
+// runBuildctl builds the image using BuildKit. The image is kept in the BuildKit worker, unless it is pushed.
+func (e *cliImageBuilderEngine) runBuildctl(ctx context.Context, image string, args imageBuildArgsT, push bool) error {
+	output := "type=image,name=" + image
+	if push {
+		output += ",push=true"
+	}
+	return e.run(
+		ctx,
+		"build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context="+args.context,
+		"--local", "dockerfile="+filepath.Dir(args.dockerfile),
+		"--opt", "filename="+filepath.Base(args.dockerfile),
+		"--output", output,
+	)
+}
+
+func (e *cliImageBuilderEngine) run(ctx context.Context, args ...string) error {
+	command := imageBuilderCommands[e.builder]
+	logrus.Debugf("running %s %s", command, strings.Join(args, " "))
+	output, err := exec.CommandContext(ctx, command, args...).CombinedOutput()
+	logrus.Debugf("%s", output)
+	if err != nil {
+		return fmt.Errorf("%s %s failed with the output %s . Error: %q", command, args[0], lastLines(string(output), 10), err)
+	}
+	return nil
+}
+
+// lastLines returns the last n lines of the output, which usually have the reason for a failure
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unavailableEngine is used along with an image builder when there is no container engine, like on hosts that only have containerd.
+// The images can be built, but the transformers that run containers do not work.
+type unavailableEngine struct {
+	err error
+}
+
+func (e *unavailableEngine) RunCmdInContainer(ctx context.Context, image string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitcode int, err error) {
+	return "", "", 0, e.err
+}
+
+func (e *unavailableEngine) InspectImage(image string) (dockertypes.ImageInspect, error) {
+	return dockertypes.ImageInspect{}, e.err
+}
+
+func (e *unavailableEngine) CopyDirsIntoImage(ctx context.Context, image, newImageName string, paths map[string]string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) CopyDirsIntoContainer(ctx context.Context, containerID string, paths map[string]string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) CopyDirsFromContainer(ctx context.Context, containerID string, paths map[string]string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) BuildImage(ctx context.Context, image, contextPath, dockerfile string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) TagImage(image, newImageName string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) PullImage(ctx context.Context, image string, policy environmenttypes.PullPolicy, platform string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) PushImage(ctx context.Context, image string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) RemoveImage(image string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) CreateContainer(ctx context.Context, image string, resources environmenttypes.ContainerResources, network environmenttypes.ContainerNetwork, mounts map[string]string, tmpfs []string) (containerid string, err error) {
+	return "", e.err
+}
+
+func (e *unavailableEngine) StopAndRemoveContainer(containerID string) (err error) {
+	return e.err
+}
+
+func (e *unavailableEngine) RunContainer(ctx context.Context, image string, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error) {
+	return "", false, e.err
+}
+
+func (e *unavailableEngine) Stat(ctx context.Context, containerID, name string) (fs.FileInfo, error) {
+	return nil, e.err
+}
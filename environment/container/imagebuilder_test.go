@@ -0,0 +1,173 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+// setupFakeImageBuilder puts a fake command of the image builder in the PATH, which logs its arguments to the returned file
+func setupFakeImageBuilder(t *testing.T, builder string) string {
+	binDir := t.TempDir()
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$*\" >> %s\nif [ \"$FAKE_BUILDER_FAIL\" = \"1\" ]; then\n  echo \"step 1\"\n  echo \"error: failed to solve\"\n  exit 1\nfi\n", logPath)
+	if err := os.WriteFile(filepath.Join(binDir, imageBuilderCommands[builder]), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write the fake image builder. Error: %q", err)
+	}
+	t.Setenv("PATH", binDir)
+	t.Setenv("FAKE_BUILDER_FAIL", "")
+	return logPath
+}
+
+func readBuilderCommands(t *testing.T, logPath string) []string {
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read the commands run by the image builder. Error: %q", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestNewCLIImageBuilderEngine(t *testing.T) {
+	engine := &unavailableEngine{err: fmt.Errorf("no container engine")}
+	if _, err := newCLIImageBuilderEngine(engine, "kaniko"); err == nil {
+		t.Fatalf("expected an error for an unsupported image builder")
+	}
+	t.Setenv("PATH", t.TempDir())
+	if _, err := newCLIImageBuilderEngine(engine, buildahImageBuilder); err == nil {
+		t.Fatalf("expected an error when the command of the image builder is missing")
+	}
+	setupFakeImageBuilder(t, buildahImageBuilder)
+	builderEngine, err := newCLIImageBuilderEngine(engine, buildahImageBuilder)
+	if err != nil {
+		t.Fatalf("failed to create the image builder. Error: %q", err)
+	}
+	// everything other than building, tagging and pushing is done by the container engine
+	if _, _, err := builderEngine.RunContainer(context.Background(), "alpine:3", environmenttypes.Command{}, "", ""); err == nil || err.Error() != "no container engine" {
+		t.Fatalf("expected the error of the container engine. Actual: %v", err)
+	}
+}
+
+func TestCLIImageBuilderEngine(t *testing.T) {
+	oldOffline := common.Offline
+	t.Cleanup(func() { common.Offline = oldOffline })
+	engine := &unavailableEngine{err: fmt.Errorf("no container engine")}
+	contextPath := filepath.Join("src", "web")
+
+	t.Run("build, tag and push using buildah", func(t *testing.T) {
+		logPath := setupFakeImageBuilder(t, buildahImageBuilder)
+		builderEngine, err := newCLIImageBuilderEngine(engine, buildahImageBuilder)
+		if err != nil {
+			t.Fatalf("failed to create the image builder. Error: %q", err)
+		}
+		if err := builderEngine.BuildImage(context.Background(), "web:latest", contextPath, ""); err != nil {
+			t.Fatalf("failed to build the image. Error: %q", err)
+		}
+		if err := builderEngine.TagImage("web:latest", "quay.io/shop/web:latest"); err != nil {
+			t.Fatalf("failed to tag the image. Error: %q", err)
+		}
+		if err := builderEngine.PushImage(context.Background(), "quay.io/shop/web:latest"); err != nil {
+			t.Fatalf("failed to push the image. Error: %q", err)
+		}
+		expected := []string{
+			"bud --layers -f " + filepath.Join(contextPath, common.DefaultDockerfileName) + " -t web:latest " + contextPath,
+			"tag web:latest quay.io/shop/web:latest",
+			"push quay.io/shop/web:latest",
+		}
+		if diff := cmp.Diff(expected, readBuilderCommands(t, logPath)); diff != "" {
+			t.Fatalf("wrong commands. Difference:\n%s", diff)
+		}
+	})
+
+	t.Run("build using nerdctl", func(t *testing.T) {
+		logPath := setupFakeImageBuilder(t, nerdctlImageBuilder)
+		builderEngine, err := newCLIImageBuilderEngine(engine, nerdctlImageBuilder)
+		if err != nil {
+			t.Fatalf("failed to create the image builder. Error: %q", err)
+		}
+		if err := builderEngine.BuildImage(context.Background(), "web:latest", contextPath, "Dockerfile.prod"); err != nil {
+			t.Fatalf("failed to build the image. Error: %q", err)
+		}
+		expected := []string{"build -f " + filepath.Join(contextPath, "Dockerfile.prod") + " -t web:latest " + contextPath}
+		if diff := cmp.Diff(expected, readBuilderCommands(t, logPath)); diff != "" {
+			t.Fatalf("wrong commands. Difference:\n%s", diff)
+		}
+	})
+
+	t.Run("tag and push by building again using buildkit", func(t *testing.T) {
+		logPath := setupFakeImageBuilder(t, buildKitImageBuilder)
+		builderEngine, err := newCLIImageBuilderEngine(engine, buildKitImageBuilder)
+		if err != nil {
+			t.Fatalf("failed to create the image builder. Error: %q", err)
+		}
+		if err := builderEngine.TagImage("api:latest", "quay.io/shop/api:latest"); err == nil {
+			t.Fatalf("expected an error when tagging an image that was not built")
+		}
+		if err := builderEngine.BuildImage(context.Background(), "web:latest", contextPath, ""); err != nil {
+			t.Fatalf("failed to build the image. Error: %q", err)
+		}
+		if err := builderEngine.TagImage("web:latest", "quay.io/shop/web:latest"); err != nil {
+			t.Fatalf("failed to tag the image. Error: %q", err)
+		}
+		if err := builderEngine.PushImage(context.Background(), "quay.io/shop/web:latest"); err != nil {
+			t.Fatalf("failed to push the image. Error: %q", err)
+		}
+		buildArgs := "build --frontend dockerfile.v0 --local context=" + contextPath + " --local dockerfile=" + contextPath + " --opt filename=" + common.DefaultDockerfileName
+		expected := []string{
+			buildArgs + " --output type=image,name=web:latest",
+			buildArgs + " --output type=image,name=quay.io/shop/web:latest,push=true",
+		}
+		if diff := cmp.Diff(expected, readBuilderCommands(t, logPath)); diff != "" {
+			t.Fatalf("wrong commands. Difference:\n%s", diff)
+		}
+		common.Offline = true
+		defer func() { common.Offline = false }()
+		if err := builderEngine.PushImage(context.Background(), "quay.io/shop/web:latest"); err == nil {
+			t.Fatalf("expected an error when pushing an image in offline mode")
+		}
+	})
+
+	t.Run("return the end of the output of a failed build", func(t *testing.T) {
+		setupFakeImageBuilder(t, buildahImageBuilder)
+		t.Setenv("FAKE_BUILDER_FAIL", "1")
+		builderEngine, err := newCLIImageBuilderEngine(engine, buildahImageBuilder)
+		if err != nil {
+			t.Fatalf("failed to create the image builder. Error: %q", err)
+		}
+		err = builderEngine.BuildImage(context.Background(), "web:latest", contextPath, "")
+		if err == nil || !strings.Contains(err.Error(), "error: failed to solve") {
+			t.Fatalf("expected the output of the failed build in the error. Actual: %v", err)
+		}
+	})
+}
+
+func TestLastLines(t *testing.T) {
+	if diff := cmp.Diff("c\nd", lastLines("a\nb\nc\nd\n", 2)); diff != "" {
+		t.Fatalf("wrong last lines. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff("a\nb", lastLines("a\nb", 10)); diff != "" {
+		t.Fatalf("wrong last lines. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,521 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dchest/uniuri"
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/konveyor/move2kube/types"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+const (
+	kubernetesEngineContainerName = "move2kube"
+	kubernetesEnginePodPrefix     = "m2k-"
+	kubernetesEngineManagedByKey  = "app.kubernetes.io/managed-by"
+	// kubernetesEngineIsolationKey is the label of the pods without network, which selects them in their network policy
+	kubernetesEngineIsolationKey = "move2kube.konveyor.io/isolation"
+	// kubernetesEnginePodStartTimeout is the time given to the cluster to pull the image and start the pod
+	kubernetesEnginePodStartTimeout = 5 * time.Minute
+	// kubernetesEngineCheckTimeout is the time given to the cluster to respond when checking that pods can be listed
+	kubernetesEngineCheckTimeout = 30 * time.Second
+)
+
+var (
+	// kubernetesEngineIdleCommand keeps the pods running, so that the commands of the transformers can be run in them
+	kubernetesEngineIdleCommand = []string{"/bin/sh", "-c", "trap 'exit 0' TERM; sleep 2147483647 & wait"}
+)
+
+// kubernetesEngine runs the containers as pods in a cluster, using the current kubeconfig or the service account of the pod it runs in.
+// It is only used when it is selected explicitly, since it creates pods in the cluster of the current context, for example where there is no Docker daemon or podman service inside a cluster.
+// The images cannot be built or pushed. The images with data are kept in memory and the data is copied into the pods when they are created.
+type kubernetesEngine struct {
+	clientset  *kubernetes.Clientset
+	restConfig *rest.Config
+	namespace  string
+	// imagesWithData are the images created using CopyDirsIntoImage
+	imagesWithData map[string]kubernetesImageT
+	// pullPolicies are the pull policies of the base images of the pods
+	pullPolicies map[string]corev1.PullPolicy
+	// platforms are the platforms of the images, which select the nodes their pods run on
+	platforms   map[string]string
+	imagesMutex sync.Mutex
+}
+
+// kubernetesImageT is an image with data, which is a base image and the directories to copy into its pods
+type kubernetesImageT struct {
+	baseImage string
+	paths     map[string]string
+}
+
+// newKubernetesEngine creates a new kubernetes engine instance
+func newKubernetesEngine() (*kubernetesEngine, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the kubeconfig or the in-cluster config. Error: %q", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the namespace of the current context. Error: %q", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a kubernetes client. Error: %q", err)
+	}
+	engine := &kubernetesEngine{
+		clientset:      clientset,
+		restConfig:     restConfig,
+		namespace:      namespace,
+		imagesWithData: map[string]kubernetesImageT{},
+		pullPolicies:   map[string]corev1.PullPolicy{},
+		platforms:      map[string]string{},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), kubernetesEngineCheckTimeout)
+	defer cancel()
+	if _, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		return nil, fmt.Errorf("unable to list the pods in the namespace %s of the cluster at %s . Error: %q", namespace, restConfig.Host, err)
+	}
+	logrus.Debugf("using the namespace %s of the cluster at %s to spawn the containers", namespace, restConfig.Host)
+	return engine, nil
+}
+
+// RunCmdInContainer runs a command in the pod
+func (e *kubernetesEngine) RunCmdInContainer(ctx context.Context, podName string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitcode int, err error) {
+	// exec does not support a working directory or environment variables, so the command is run using a shell
+	shellCmd := []string{"/bin/sh", "-c", `if [ -n "$0" ]; then cd "$0" || exit 1; fi; exec env "$@"`, workingdir}
+	shellCmd = append(shellCmd, env...)
+	shellCmd = append(shellCmd, cmd...)
+	var outBuf, errBuf bytes.Buffer
+	outWriter, errWriter, flushOutput := getOutputWriters(ctx, cmd, &outBuf, &errBuf)
+	exitcode, err = e.exec(ctx, podName, shellCmd, nil, outWriter, errWriter)
+	flushOutput()
+	if ctx.Err() != nil {
+		// the buffers may still be written to by the stream
+		return "", "", 0, ctx.Err()
+	}
+	return outBuf.String(), errBuf.String(), exitcode, err
+}
+
+// InspectImage is not supported, since the images are pulled by the nodes of the cluster
+func (e *kubernetesEngine) InspectImage(image string) (dockertypes.ImageInspect, error) {
+	return dockertypes.ImageInspect{}, fmt.Errorf("the image %s cannot be inspected, since the images are pulled by the nodes of the cluster", image)
+}
+
+// CopyDirsIntoImage creates an image with data, whose pods get the directories when they are created
+func (e *kubernetesEngine) CopyDirsIntoImage(ctx context.Context, image, newImageName string, paths map[string]string) (err error) {
+	e.imagesMutex.Lock()
+	defer e.imagesMutex.Unlock()
+	baseImage := image
+	newPaths := map[string]string{}
+	if imageWithData, ok := e.imagesWithData[image]; ok {
+		baseImage = imageWithData.baseImage
+		for sp, dp := range imageWithData.paths {
+			newPaths[sp] = dp
+		}
+	}
+	for sp, dp := range paths {
+		newPaths[sp] = dp
+	}
+	e.imagesWithData[newImageName] = kubernetesImageT{baseImage: baseImage, paths: newPaths}
+	return nil
+}
+
+// CopyDirsIntoContainer copies the directories into the pod
+func (e *kubernetesEngine) CopyDirsIntoContainer(ctx context.Context, podName string, paths map[string]string) (err error) {
+	for sp, dp := range paths {
+		reader := readDirAsTar(sp, dp, getContainerFilesOwner(false))
+		if reader == nil {
+			return fmt.Errorf("error during create tar archive from '%s'", sp)
+		}
+		var errBuf bytes.Buffer
+		exitCode, err := e.exec(ctx, podName, []string{"tar", "xf", "-", "-C", "/"}, reader, io.Discard, &errBuf)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to copy %s to %s in the pod %s . Error: %q", sp, dp, podName, err)
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("failed to copy %s to %s in the pod %s . Exit code: %d Error: %s", sp, dp, podName, exitCode, errBuf.String())
+		}
+	}
+	return nil
+}
+
+// CopyDirsFromContainer copies the directories from the pod
+func (e *kubernetesEngine) CopyDirsFromContainer(ctx context.Context, podName string, paths map[string]string) (err error) {
+	for sp, dp := range paths {
+		stat, err := e.Stat(ctx, podName, sp)
+		if err != nil {
+			return fmt.Errorf("failed to find %s in the pod %s . Error: %q", sp, podName, err)
+		}
+		pr, pw := io.Pipe()
+		var errBuf bytes.Buffer
+		go func() {
+			exitCode, err := e.exec(ctx, podName, []string{"tar", "cf", "-", "-C", path.Dir(sp), path.Base(sp)}, nil, pw, &errBuf)
+			if err == nil && exitCode != 0 {
+				err = fmt.Errorf("exit code: %d Error: %s", exitCode, errBuf.String())
+			}
+			pw.CloseWithError(err)
+		}()
+		_, srcBase := archive.SplitPathDirEntry(sp)
+		preArchive := archive.RebaseArchiveEntries(pr, srcBase, "")
+		err = extractCopy(preArchive, archive.CopyInfo{Path: sp, Exists: true, IsDir: stat.IsDir()}, dp)
+		pr.Close()
+		if err != nil {
+			return fmt.Errorf("failed to copy %s from the pod %s to %s . Error: %q", sp, podName, dp, err)
+		}
+	}
+	return nil
+}
+
+// BuildImage is not supported, since there is no daemon to build the images in
+func (e *kubernetesEngine) BuildImage(ctx context.Context, image, contextPath, dockerfile string) (err error) {
+	return fmt.Errorf("the image %s cannot be built in the cluster. Build and push it to a registry that the cluster can pull from", image)
+}
+
+// TagImage is not supported, since there is no daemon to store the images in
+func (e *kubernetesEngine) TagImage(image, newImageName string) (err error) {
+	return fmt.Errorf("the image %s cannot be tagged in the cluster", image)
+}
+
+// PullImage records the pull policy and the platform of the image for its pods, since the images are pulled by the nodes of the cluster when the pods are created.
+// The nodes use the pull secrets of the service account of the namespace.
+func (e *kubernetesEngine) PullImage(ctx context.Context, image string, policy environmenttypes.PullPolicy, platform string) (err error) {
+	if err := validatePullPolicy(policy); err != nil {
+		return err
+	}
+	if platform != "" {
+		if _, err := parsePlatform(platform); err != nil {
+			return err
+		}
+	}
+	e.imagesMutex.Lock()
+	defer e.imagesMutex.Unlock()
+	if policy != "" {
+		e.pullPolicies[image] = corev1.PullPolicy(policy)
+	}
+	if platform != "" {
+		e.platforms[image] = platform
+	}
+	return nil
+}
+
+// PushImage is not supported, since there is no daemon to push the images from
+func (e *kubernetesEngine) PushImage(ctx context.Context, image string) (err error) {
+	return fmt.Errorf("the image %s cannot be pushed from the cluster", image)
+}
+
+// RemoveImage removes an image with data
+func (e *kubernetesEngine) RemoveImage(image string) (err error) {
+	e.imagesMutex.Lock()
+	defer e.imagesMutex.Unlock()
+	delete(e.imagesWithData, image)
+	return nil
+}
+
+// CreateContainer creates a pod that runs until it is removed, and copies the data of the image into it.
+// The mounts are copied into the pod too, since the directories on the host cannot be mounted into pods.
+// The tmpfs are empty directories in the memory of the node.
+func (e *kubernetesEngine) CreateContainer(ctx context.Context, image string, resources environmenttypes.ContainerResources, network environmenttypes.ContainerNetwork, mounts map[string]string, tmpfs []string) (podName string, err error) {
+	network, err = getNetwork(network)
+	if err != nil {
+		return "", err
+	}
+	e.imagesMutex.Lock()
+	imageWithData, ok := e.imagesWithData[image]
+	e.imagesMutex.Unlock()
+	if !ok {
+		imageWithData = kubernetesImageT{baseImage: image}
+	}
+	podName, err = e.createPod(ctx, imageWithData.baseImage, resources, network, tmpfs)
+	if err != nil {
+		return "", err
+	}
+	paths := map[string]string{}
+	for sp, dp := range imageWithData.paths {
+		paths[sp] = dp
+	}
+	for sp, dp := range mounts {
+		paths[sp] = dp
+	}
+	if err := e.CopyDirsIntoContainer(ctx, podName, paths); err != nil {
+		if rerr := e.StopAndRemoveContainer(podName); rerr != nil {
+			logrus.Errorf("failed to remove the pod %s . Error: %q", podName, rerr)
+		}
+		return "", err
+	}
+	return podName, nil
+}
+
+// removeNetworkPolicy deletes a network policy of a pod that was not created
+func (e *kubernetesEngine) removeNetworkPolicy(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	if err := e.clientset.NetworkingV1().NetworkPolicies(e.namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		logrus.Errorf("failed to remove the network policy %s . Error: %q", name, err)
+	}
+}
+
+// StopAndRemoveContainer deletes the pod
+func (e *kubernetesEngine) StopAndRemoveContainer(podName string) (err error) {
+	gracePeriod := int64(0)
+	// the pod is deleted with its own context, so that the pods are deleted even after the run is interrupted
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	return e.clientset.CoreV1().Pods(e.namespace).Delete(ctx, podName, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+}
+
+// RunContainer runs the command in a new pod of the image and deletes it
+func (e *kubernetesEngine) RunContainer(ctx context.Context, image string, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error) {
+	podName, err := e.CreateContainer(ctx, image, environmenttypes.ContainerResources{}, environmenttypes.NetworkNone, nil, nil)
+	if err != nil {
+		return "", false, err
+	}
+	defer func() {
+		if err := e.StopAndRemoveContainer(podName); err != nil {
+			logrus.Errorf("failed to remove the pod %s . Error: %q", podName, err)
+		}
+	}()
+	if volsrc != "" && voldest != "" {
+		if err := e.CopyDirsIntoContainer(ctx, podName, map[string]string{volsrc: voldest}); err != nil {
+			return "", true, err
+		}
+	}
+	if len(cmd) == 0 {
+		return "", true, nil
+	}
+	stdout, _, exitCode, err := e.RunCmdInContainer(ctx, podName, cmd, "", nil)
+	if err != nil {
+		return stdout, true, err
+	}
+	if exitCode != 0 {
+		return stdout, true, fmt.Errorf("container execution terminated with error code : %d", exitCode)
+	}
+	return stdout, true, nil
+}
+
+// Stat returns the file info of a path in the pod
+func (e *kubernetesEngine) Stat(ctx context.Context, podName, name string) (fs.FileInfo, error) {
+	var outBuf, errBuf bytes.Buffer
+	exitCode, err := e.exec(ctx, podName, []string{"stat", "-c", "%s %f %Y %N", name}, nil, &outBuf, &errBuf)
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("failed to stat %s in the pod %s . Error: %s", name, podName, errBuf.String())
+	}
+	fields := strings.SplitN(strings.TrimSpace(outBuf.String()), " ", 4)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("failed to parse the stat output %q of %s in the pod %s", outBuf.String(), name, podName)
+	}
+	rawMode, err := cast.ToUint32E("0x" + fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the mode %s of %s in the pod %s . Error: %q", fields[1], name, podName, err)
+	}
+	stat := dockertypes.ContainerPathStat{
+		Name:  path.Base(name),
+		Size:  cast.ToInt64(fields[0]),
+		Mode:  getFileModeFromUnixMode(rawMode),
+		Mtime: time.Unix(cast.ToInt64(fields[2]), 0),
+	}
+	// %N is like 'link' -> 'target' for symbolic links
+	if parts := strings.SplitN(fields[3], " -> ", 2); len(parts) == 2 {
+		stat.LinkTarget = strings.Trim(parts[1], `'"`)
+	}
+	return &FileInfo{stat: stat}, nil
+}
+
+// createPod creates a pod of the image with the idle command and waits for it to run
+// The pods without network are isolated using a network policy that denies all their traffic, which is removed along with the pod.
+func (e *kubernetesEngine) createPod(ctx context.Context, image string, resources environmenttypes.ContainerResources, network environmenttypes.ContainerNetwork, tmpfs []string) (string, error) {
+	requirements, err := getKubernetesResources(resources)
+	if err != nil {
+		return "", fmt.Errorf("failed to limit the resources of the pod of the image %s . Error: %q", image, err)
+	}
+	e.imagesMutex.Lock()
+	pullPolicy := e.pullPolicies[image]
+	platform := e.platforms[image]
+	e.imagesMutex.Unlock()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: kubernetesEnginePodPrefix,
+			Labels:       map[string]string{kubernetesEngineManagedByKey: types.AppName},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:            kubernetesEngineContainerName,
+				Image:           image,
+				Command:         kubernetesEngineIdleCommand,
+				Resources:       requirements,
+				ImagePullPolicy: pullPolicy,
+			}},
+		},
+	}
+	for i, dir := range tmpfs {
+		name := fmt.Sprintf("tmpfs-%d", i)
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}}})
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: name, MountPath: dir})
+	}
+	if platform != "" {
+		// the pods run on the nodes of the platform, since the nodes do not emulate the other architectures
+		p, err := parsePlatform(platform)
+		if err != nil {
+			return "", err
+		}
+		pod.Spec.NodeSelector = map[string]string{corev1.LabelOSStable: p.OS, corev1.LabelArchStable: p.Architecture}
+	}
+	var policy *networkingv1.NetworkPolicy
+	switch network {
+	case environmenttypes.NetworkHost:
+		pod.Spec.HostNetwork = true
+	case environmenttypes.NetworkNone:
+		// the policy is created before the pod, so that the pod never has network access
+		isolationID := strings.ToLower(uniuri.NewLen(10))
+		pod.Labels[kubernetesEngineIsolationKey] = isolationID
+		policy = &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   kubernetesEnginePodPrefix + isolationID,
+				Labels: map[string]string{kubernetesEngineManagedByKey: types.AppName},
+			},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{kubernetesEngineIsolationKey: isolationID}},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			},
+		}
+		if policy, err = e.clientset.NetworkingV1().NetworkPolicies(e.namespace).Create(ctx, policy, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to create the network policy that isolates the pod of the image %s in the namespace %s . Use the bridge network for the container if network policies cannot be created. Error: %q", image, e.namespace, err)
+		}
+	}
+	pod, err = e.clientset.CoreV1().Pods(e.namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		if policy != nil {
+			e.removeNetworkPolicy(policy.Name)
+		}
+		return "", fmt.Errorf("failed to create a pod of the image %s in the namespace %s . Error: %q", image, e.namespace, err)
+	}
+	if policy != nil {
+		// the pod owns the policy, so that the policy is garbage collected along with the pod
+		policy.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "Pod", Name: pod.Name, UID: pod.UID}}
+		if _, err := e.clientset.NetworkingV1().NetworkPolicies(e.namespace).Update(ctx, policy, metav1.UpdateOptions{}); err != nil {
+			logrus.Warnf("failed to make the pod %s the owner of the network policy %s , so the policy has to be removed after the run. Error: %q", pod.Name, policy.Name, err)
+		}
+	}
+	logrus.Debugf("Pod %s created with image %s", pod.Name, image)
+	err = wait.PollImmediateWithContext(ctx, time.Second, kubernetesEnginePodStartTimeout, func(ctx context.Context) (bool, error) {
+		pod, err := e.clientset.CoreV1().Pods(e.namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		switch pod.Status.Phase {
+		case corev1.PodRunning:
+			return true, nil
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return false, fmt.Errorf("the pod stopped with the phase %s", pod.Status.Phase)
+		}
+		return false, nil
+	})
+	if err != nil {
+		if rerr := e.StopAndRemoveContainer(pod.Name); rerr != nil {
+			logrus.Errorf("failed to remove the pod %s . Error: %q", pod.Name, rerr)
+		}
+		return "", fmt.Errorf("the pod %s of the image %s did not start. Error: %q", pod.Name, image, err)
+	}
+	return pod.Name, nil
+}
+
+// exec runs a command in the pod and returns its exit code.
+// The stream does not take a context, so it is left to end when the pod is deleted if the context is cancelled.
+func (e *kubernetesEngine) exec(ctx context.Context, podName string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	req := e.clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(e.namespace).Name(podName).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: kubernetesEngineContainerName,
+			Command:   cmd,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(e.restConfig, "POST", req.URL())
+	if err != nil {
+		return 0, fmt.Errorf("failed to exec in the pod %s . Error: %q", podName, err)
+	}
+	streamDone := make(chan error, 1)
+	go func() {
+		streamDone <- executor.Stream(remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: stderr})
+	}()
+	select {
+	case err = <-streamDone:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.Exited() {
+		return exitErr.ExitStatus(), nil
+	}
+	return 0, err
+}
+
+// getFileModeFromUnixMode converts the st_mode of a file to a fs.FileMode
+func getFileModeFromUnixMode(rawMode uint32) fs.FileMode {
+	mode := fs.FileMode(rawMode & 0777)
+	switch rawMode & 0170000 {
+	case 0040000:
+		mode |= fs.ModeDir
+	case 0120000:
+		mode |= fs.ModeSymlink
+	case 0010000:
+		mode |= fs.ModeNamedPipe
+	case 0140000:
+		mode |= fs.ModeSocket
+	case 0020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0060000:
+		mode |= fs.ModeDevice
+	}
+	if rawMode&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if rawMode&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if rawMode&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
@@ -0,0 +1,105 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetFileModeFromUnixMode(t *testing.T) {
+	testCases := map[uint32]fs.FileMode{
+		0100644: 0644,
+		0040755: fs.ModeDir | 0755,
+		0120777: fs.ModeSymlink | 0777,
+		0104755: fs.ModeSetuid | 0755,
+		0041777: fs.ModeDir | fs.ModeSticky | 0777,
+	}
+	for rawMode, want := range testCases {
+		if got := getFileModeFromUnixMode(rawMode); got != want {
+			t.Fatalf("wrong file mode for the raw mode %o . Expected: %s Actual: %s", rawMode, want, got)
+		}
+	}
+}
+
+// setupTestCluster points the kubeconfig to a fake API server and returns the number of requests it got
+func setupTestCluster(t *testing.T) *int32 {
+	t.Helper()
+	requests := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`)
+	}))
+	t.Cleanup(server.Close)
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    namespace: m2k
+current-context: test
+users: []
+`, server.URL)
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfigPath, []byte(kubeconfig), 0644); err != nil {
+		t.Fatalf("failed to write the kubeconfig. Error: %q", err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfigPath)
+	return requests
+}
+
+func TestInitContainerEngineKubernetes(t *testing.T) {
+	t.Run("the auto runtime does not use the cluster", func(t *testing.T) {
+		requests := setupTestCluster(t)
+		engine, err := initContainerEngine(AutoContainerRuntime, dockerEndpointT{})
+		if _, ok := engine.(*kubernetesEngine); ok {
+			t.Fatalf("expected the auto runtime to not run the containers as pods")
+		}
+		if atomic.LoadInt32(requests) != 0 {
+			t.Fatalf("expected the auto runtime to not access the cluster. Requests: %d Error: %v", atomic.LoadInt32(requests), err)
+		}
+	})
+	t.Run("the kubernetes runtime uses the cluster", func(t *testing.T) {
+		requests := setupTestCluster(t)
+		engine, err := initContainerEngine(KubernetesContainerRuntime, dockerEndpointT{})
+		if err != nil {
+			t.Fatalf("failed to use the cluster as the container engine. Error: %q", err)
+		}
+		kubeEngine, ok := engine.(*kubernetesEngine)
+		if !ok {
+			t.Fatalf("expected the containers to run as pods. Actual engine: %T", engine)
+		}
+		if kubeEngine.namespace != "m2k" {
+			t.Fatalf("wrong namespace of the pods. Expected: m2k Actual: %s", kubeEngine.namespace)
+		}
+		if atomic.LoadInt32(requests) == 0 {
+			t.Fatalf("expected the cluster to be checked")
+		}
+	})
+}
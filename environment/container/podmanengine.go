@@ -17,79 +17,64 @@
 package container
 
 import (
-	"encoding/json"
 	"fmt"
-	"os/exec"
+	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
 	"github.com/sirupsen/logrus"
 )
 
-//TOFIX: Support the interface
+const (
+	// containerHostEnvVar is the environment variable used by the podman clients to find the podman service
+	containerHostEnvVar = "CONTAINER_HOST"
+	podmanSocketSubPath = "podman/podman.sock"
+	rootfulPodmanSocket = "/run/podman/podman.sock"
+)
 
+// podmanEngine manages the containers using the Docker compatible REST API of the podman service.
+// The podman service can be started using `podman system service` or the podman.socket systemd unit.
 type podmanEngine struct {
-	availableImages map[string]bool
+	*dockerEngine
+	host string
 }
 
-func newPodmanEngine() *podmanEngine {
-	return &podmanEngine{
-		availableImages: map[string]bool{},
-	}
-}
-
-// InspectImage returns inspect output for an image using Podman
-func (e *podmanEngine) InspectImage(image string) (t types.ImageInspect, err error) {
-	inspectcmd := exec.Command("podman", "inspect", image)
-	logrus.Debugf("Inspecting image %s", image)
-	output, err := inspectcmd.CombinedOutput()
+// newPodmanEngine creates a new podman engine instance
+func newPodmanEngine() (*podmanEngine, error) {
+	host, err := getPodmanHost()
 	if err != nil {
-		logrus.Debugf("Unable to inspect image %s : %s, %s", image, err, output)
-		return t, err
+		return nil, err
 	}
-	t = types.ImageInspect{}
-	err = json.Unmarshal(output, &t)
+	cli, err := client.NewClientWithOpts(client.WithHost(host), client.WithAPIVersionNegotiation())
 	if err != nil {
-		logrus.Debugf("Error in unmarshalling json %s: %s.", output, err)
+		return nil, fmt.Errorf("unable to create a client for the podman service at %s . Error: %q", host, err)
 	}
-	return t, err
-}
-
-func (e *podmanEngine) pullImage(image string) bool {
-	if a, ok := e.availableImages[image]; ok {
-		return a
-	}
-	pullcmd := exec.Command("podman", "pull", image)
-	logrus.Debugf("Pulling image %s", image)
-	output, err := pullcmd.CombinedOutput()
+	engine, err := newDockerCompatibleEngine(cli)
 	if err != nil {
-		logrus.Warnf("Error while pulling builder %s : %s : %s", image, err, output)
-		e.availableImages[image] = false
-		return false
+		return nil, fmt.Errorf("unable to use the podman service at %s . Error: %q", host, err)
 	}
-	e.availableImages[image] = true
-	return true
+	logrus.Debugf("using the podman service at %s", host)
+	return &podmanEngine{dockerEngine: engine, host: host}, nil
 }
 
-// RunContainer executes a container using podman
-func (e *podmanEngine) RunContainer(image string, cmd string, volsrc string, voldest string) (output string, containerStarted bool, err error) {
-	if !e.pullImage(image) {
-		logrus.Debugf("Unable to pull image using podman : %s", image)
-		return "", false, fmt.Errorf("unable to pull image")
+// getPodmanHost returns the address of the podman service.
+// It uses CONTAINER_HOST if it is set, else the rootless socket of the user, the rootful socket and then the socket of the podman machine.
+func getPodmanHost() (string, error) {
+	if host := os.Getenv(containerHostEnvVar); host != "" {
+		if strings.HasPrefix(host, "ssh://") {
+			return "", fmt.Errorf("the podman service at %s is not supported. Only unix sockets and tcp addresses are supported", host)
+		}
+		return host, nil
 	}
-	args := []string{"run", "--rm"}
-	if volsrc != "" && voldest != "" {
-		args = append(args, "-v", volsrc+":"+voldest)
+	sockets := []string{}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		sockets = append(sockets, filepath.Join(runtimeDir, podmanSocketSubPath))
 	}
-	args = append(args, image)
-	if cmd != "" {
-		args = append(args, cmd)
-	}
-	detectcmd := exec.Command("podman", args...)
-	logrus.Debugf("Running detect on image %s", image)
-	o, err := detectcmd.CombinedOutput()
-	if err != nil {
-		logrus.Debugf("Detect failed %s : %s : %s", image, err, output)
-		return string(o), false, err
+	sockets = append(sockets, filepath.Join("/run/user", fmt.Sprint(os.Getuid()), podmanSocketSubPath), rootfulPodmanSocket)
+	sockets = append(sockets, getPodmanMachineSockets()...)
+	if socket := findSocket(sockets); socket != "" {
+		return "unix://" + socket, nil
 	}
-	return string(o), true, nil
+	return "", fmt.Errorf("the podman socket was not found at any of %+v . Set %s or start the podman service or the podman machine", sockets, containerHostEnvVar)
 }
@@ -0,0 +1,88 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetPodmanHost(t *testing.T) {
+	t.Run("use the podman service in CONTAINER_HOST", func(t *testing.T) {
+		t.Setenv(containerHostEnvVar, "tcp://127.0.0.1:8888")
+		host, err := getPodmanHost()
+		if err != nil || host != "tcp://127.0.0.1:8888" {
+			t.Fatalf("wrong podman host. Expected: tcp://127.0.0.1:8888 Actual: %s Error: %v", host, err)
+		}
+	})
+
+	t.Run("fail for a podman service accessed over ssh", func(t *testing.T) {
+		t.Setenv(containerHostEnvVar, "ssh://core@localhost:53685/run/user/501/podman/podman.sock")
+		if host, err := getPodmanHost(); err == nil {
+			t.Fatalf("expected an error for a podman service accessed over ssh. Actual: %s", host)
+		}
+	})
+
+	t.Run("find the rootless socket of the user", func(t *testing.T) {
+		runtimeDir := t.TempDir()
+		socket := filepath.Join(runtimeDir, "podman", "podman.sock")
+		if err := os.MkdirAll(filepath.Dir(socket), 0755); err != nil {
+			t.Fatalf("failed to create the directory of the socket. Error: %q", err)
+		}
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Skipf("unable to listen on a unix socket. Error: %q", err)
+		}
+		defer listener.Close()
+		t.Setenv(containerHostEnvVar, "")
+		t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+		host, err := getPodmanHost()
+		if err != nil || host != "unix://"+socket {
+			t.Fatalf("wrong podman host. Expected: unix://%s Actual: %s Error: %v", socket, host, err)
+		}
+	})
+
+	t.Run("fail when there is no podman socket", func(t *testing.T) {
+		for _, socket := range []string{filepath.Join("/run/user", fmt.Sprint(os.Getuid()), podmanSocketSubPath), rootfulPodmanSocket} {
+			if isSocket(socket) {
+				t.Skipf("the podman service is running at %s", socket)
+			}
+		}
+		t.Setenv(containerHostEnvVar, "")
+		t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+		t.Setenv("HOME", t.TempDir())
+		t.Setenv("TMPDIR", t.TempDir())
+		host, err := getPodmanHost()
+		if err == nil {
+			t.Fatalf("expected an error when there is no podman socket. Actual: %s", host)
+		}
+		if !strings.Contains(err.Error(), containerHostEnvVar) {
+			t.Fatalf("expected the error to mention %s . Actual: %q", containerHostEnvVar, err)
+		}
+	})
+}
+
+func TestNewPodmanEngineWithoutService(t *testing.T) {
+	t.Setenv(containerHostEnvVar, "unix://"+filepath.Join(t.TempDir(), "podman.sock"))
+	if engine, err := newPodmanEngine(); err == nil {
+		t.Fatalf("expected an error when the podman service is not running. Actual: %+v", engine)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+)
+
+// containerPool keeps one warm container for each transformer for the life of the run,
+// so that the containers are not created again for each directory and each run of the transformer
+type containerPool struct {
+	// containers are the IDs of the warm containers, keyed by the image with the data of the transformer
+	containers map[string]string
+	mutex      sync.Mutex
+}
+
+var pool = &containerPool{containers: map[string]string{}}
+
+// GetPooledContainer returns the warm container of the image, creating one with the resource limits, the network, the mounts and the tmpfs directories if there is none
+func GetPooledContainer(ctx context.Context, image string, resources environmenttypes.ContainerResources, network environmenttypes.ContainerNetwork, mounts map[string]string, tmpfs []string) (containerID string, err error) {
+	pool.mutex.Lock()
+	containerID, ok := pool.containers[image]
+	pool.mutex.Unlock()
+	if ok {
+		return containerID, nil
+	}
+	engine, err := GetContainerEngine()
+	if err != nil {
+		return "", err
+	}
+	containerID, err = engine.CreateContainer(ctx, image, resources, network, mounts, tmpfs)
+	if err != nil {
+		return "", fmt.Errorf("failed to create a container of the image %s . Error: %q", image, err)
+	}
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if existingContainerID, ok := pool.containers[image]; ok {
+		// the container was created at the same time by another caller
+		if err := engine.StopAndRemoveContainer(containerID); err != nil {
+			logrus.Errorf("failed to remove the container %s . Error: %q", containerID, err)
+		}
+		return existingContainerID, nil
+	}
+	pool.containers[image] = containerID
+	return containerID, nil
+}
+
+// ReleasePooledContainer stops and removes the warm container of the image
+func ReleasePooledContainer(image string) error {
+	pool.mutex.Lock()
+	containerID, ok := pool.containers[image]
+	delete(pool.containers, image)
+	pool.mutex.Unlock()
+	if !ok {
+		return nil
+	}
+	engine, err := GetContainerEngine()
+	if err != nil {
+		return err
+	}
+	return engine.StopAndRemoveContainer(containerID)
+}
+
+// ReleaseAllPooledContainers stops and removes all the warm containers, so that none are left behind when the run ends
+func ReleaseAllPooledContainers() {
+	pool.mutex.Lock()
+	images := []string{}
+	for image := range pool.containers {
+		images = append(images, image)
+	}
+	pool.mutex.Unlock()
+	for _, image := range images {
+		if err := ReleasePooledContainer(image); err != nil {
+			logrus.Errorf("failed to remove the container of the image %s . Error: %q", image, err)
+		}
+	}
+}
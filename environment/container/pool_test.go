@@ -0,0 +1,168 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+// poolTestEngine counts the containers that are created and removed
+type poolTestEngine struct {
+	ContainerEngine
+	mutex   sync.Mutex
+	created []string
+	removed []string
+	// started is closed to let the container creations return, so that they can be made to race
+	started chan struct{}
+}
+
+func (e *poolTestEngine) CreateContainer(ctx context.Context, image string, resources environmenttypes.ContainerResources, network environmenttypes.ContainerNetwork, mounts map[string]string, tmpfs []string) (string, error) {
+	if e.started != nil {
+		<-e.started
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	containerID := fmt.Sprintf("%s-%d", image, len(e.created))
+	e.created = append(e.created, containerID)
+	return containerID, nil
+}
+
+func (e *poolTestEngine) StopAndRemoveContainer(containerID string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.removed = append(e.removed, containerID)
+	return nil
+}
+
+func setupPoolTestEngine(t *testing.T) *poolTestEngine {
+	engine := &poolTestEngine{}
+	oldInited, oldDisabled, oldEngine, oldContainers := inited, disabled, workingEngine, pool.containers
+	inited, disabled, workingEngine, pool.containers = true, false, engine, map[string]string{}
+	t.Cleanup(func() {
+		inited, disabled, workingEngine, pool.containers = oldInited, oldDisabled, oldEngine, oldContainers
+	})
+	return engine
+}
+
+func getPoolTestContainer(t *testing.T, image string) string {
+	containerID, err := GetPooledContainer(context.Background(), image, environmenttypes.ContainerResources{}, environmenttypes.NetworkNone, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to get the container of the image %s . Error: %q", image, err)
+	}
+	return containerID
+}
+
+func TestGetPooledContainer(t *testing.T) {
+	t.Run("reuse the container of the same image", func(t *testing.T) {
+		engine := setupPoolTestEngine(t)
+		first := getPoolTestContainer(t, "image1")
+		second := getPoolTestContainer(t, "image1")
+		if first != second {
+			t.Fatalf("the container of the image was not reused. Expected: %s Actual: %s", first, second)
+		}
+		if len(engine.created) != 1 {
+			t.Fatalf("expected one container to be created. Actual: %v", engine.created)
+		}
+	})
+	t.Run("create a container for each image", func(t *testing.T) {
+		engine := setupPoolTestEngine(t)
+		first := getPoolTestContainer(t, "image1")
+		second := getPoolTestContainer(t, "image2")
+		if first == second {
+			t.Fatalf("the images got the same container %s", first)
+		}
+		if len(engine.created) != 2 {
+			t.Fatalf("expected two containers to be created. Actual: %v", engine.created)
+		}
+	})
+	t.Run("remove the duplicate containers of concurrent callers", func(t *testing.T) {
+		engine := setupPoolTestEngine(t)
+		engine.started = make(chan struct{})
+		const callers = 4
+		containerIDs := make([]string, callers)
+		errs := make([]error, callers)
+		wg := sync.WaitGroup{}
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				containerIDs[i], errs[i] = GetPooledContainer(context.Background(), "image1", environmenttypes.ContainerResources{}, environmenttypes.NetworkNone, nil, nil)
+			}(i)
+		}
+		close(engine.started)
+		wg.Wait()
+		for i, err := range errs {
+			if err != nil {
+				t.Fatalf("failed to get the container of caller %d . Error: %q", i, err)
+			}
+			if containerIDs[i] != containerIDs[0] {
+				t.Fatalf("the callers got different containers. Expected: %s Actual: %s", containerIDs[0], containerIDs[i])
+			}
+		}
+		if len(engine.created)-len(engine.removed) != 1 {
+			t.Fatalf("expected all the containers but one to be removed. Created: %v Removed: %v", engine.created, engine.removed)
+		}
+		for _, containerID := range engine.removed {
+			if containerID == containerIDs[0] {
+				t.Fatalf("the pooled container %s was removed", containerID)
+			}
+		}
+	})
+}
+
+func TestReleasePooledContainer(t *testing.T) {
+	t.Run("remove the container and create a new one on the next get", func(t *testing.T) {
+		engine := setupPoolTestEngine(t)
+		first := getPoolTestContainer(t, "image1")
+		if err := ReleasePooledContainer("image1"); err != nil {
+			t.Fatalf("failed to release the container. Error: %q", err)
+		}
+		if len(engine.removed) != 1 || engine.removed[0] != first {
+			t.Fatalf("expected the container %s to be removed. Actual: %v", first, engine.removed)
+		}
+		if second := getPoolTestContainer(t, "image1"); second == first {
+			t.Fatalf("the released container %s was reused", first)
+		}
+	})
+	t.Run("release an image without a container", func(t *testing.T) {
+		engine := setupPoolTestEngine(t)
+		if err := ReleasePooledContainer("image1"); err != nil {
+			t.Fatalf("failed to release the image without a container. Error: %q", err)
+		}
+		if len(engine.removed) != 0 {
+			t.Fatalf("expected no containers to be removed. Actual: %v", engine.removed)
+		}
+	})
+}
+
+func TestReleaseAllPooledContainers(t *testing.T) {
+	engine := setupPoolTestEngine(t)
+	getPoolTestContainer(t, "image1")
+	getPoolTestContainer(t, "image2")
+	ReleaseAllPooledContainers()
+	if len(engine.removed) != 2 {
+		t.Fatalf("expected all the containers to be removed. Created: %v Removed: %v", engine.created, engine.removed)
+	}
+	if len(pool.containers) != 0 {
+		t.Fatalf("expected the pool to be empty. Actual: %v", pool.containers)
+	}
+}
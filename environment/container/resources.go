@@ -0,0 +1,95 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// getDockerResources converts the limits of a transformer container to the resources of a docker container
+func getDockerResources(resources environmenttypes.ContainerResources) (container.Resources, error) {
+	dockerResources := container.Resources{}
+	if resources.CPUs != "" {
+		cpus, err := resource.ParseQuantity(resources.CPUs)
+		if err != nil {
+			return dockerResources, fmt.Errorf("the cpus %s are not a valid quantity. Error: %q", resources.CPUs, err)
+		}
+		dockerResources.NanoCPUs = cpus.MilliValue() * 1000000
+	}
+	if resources.Memory != "" {
+		memory, err := resource.ParseQuantity(resources.Memory)
+		if err != nil {
+			return dockerResources, fmt.Errorf("the memory %s is not a valid quantity. Error: %q", resources.Memory, err)
+		}
+		dockerResources.Memory = memory.Value()
+		// the memory is not swapped, otherwise the container would keep running slowly instead of being killed
+		dockerResources.MemorySwap = memory.Value()
+	}
+	if resources.PIDs > 0 {
+		pids := resources.PIDs
+		dockerResources.PidsLimit = &pids
+	}
+	names := []string{}
+	for name := range resources.Ulimits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ulimit, err := units.ParseUlimit(name + "=" + resources.Ulimits[name])
+		if err != nil {
+			return dockerResources, fmt.Errorf("the ulimit %s=%s is invalid. Error: %q", name, resources.Ulimits[name], err)
+		}
+		dockerResources.Ulimits = append(dockerResources.Ulimits, ulimit)
+	}
+	return dockerResources, nil
+}
+
+// getKubernetesResources converts the limits of a transformer container to the limits of a container of a pod.
+// The pids and the ulimits cannot be set for a pod, they are limited by the nodes.
+func getKubernetesResources(resources environmenttypes.ContainerResources) (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{}
+	limits := corev1.ResourceList{}
+	if resources.CPUs != "" {
+		cpus, err := resource.ParseQuantity(resources.CPUs)
+		if err != nil {
+			return requirements, fmt.Errorf("the cpus %s are not a valid quantity. Error: %q", resources.CPUs, err)
+		}
+		limits[corev1.ResourceCPU] = cpus
+	}
+	if resources.Memory != "" {
+		memory, err := resource.ParseQuantity(resources.Memory)
+		if err != nil {
+			return requirements, fmt.Errorf("the memory %s is not a valid quantity. Error: %q", resources.Memory, err)
+		}
+		limits[corev1.ResourceMemory] = memory
+	}
+	if resources.PIDs > 0 || len(resources.Ulimits) > 0 {
+		logrus.Debugf("ignoring the pids and ulimits of the container, since they cannot be set for a pod")
+	}
+	if len(limits) > 0 {
+		requirements.Limits = limits
+	}
+	return requirements, nil
+}
@@ -0,0 +1,43 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"testing"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+func TestGetDockerResources(t *testing.T) {
+	t.Run("limits", func(t *testing.T) {
+		resources, err := getDockerResources(environmenttypes.ContainerResources{CPUs: "1500m", Memory: "512Mi", PIDs: 100, Ulimits: map[string]string{"nofile": "1024:2048"}})
+		if err != nil {
+			t.Fatalf("failed to get the resources. Error: %q", err)
+		}
+		if resources.NanoCPUs != 1500000000 || resources.Memory != 512*1024*1024 || resources.MemorySwap != resources.Memory || *resources.PidsLimit != 100 {
+			t.Fatalf("wrong resources. Actual: %+v", resources)
+		}
+		if len(resources.Ulimits) != 1 || resources.Ulimits[0].Name != "nofile" || resources.Ulimits[0].Soft != 1024 || resources.Ulimits[0].Hard != 2048 {
+			t.Fatalf("wrong ulimits. Actual: %+v", resources.Ulimits)
+		}
+	})
+	t.Run("invalid memory", func(t *testing.T) {
+		if _, err := getDockerResources(environmenttypes.ContainerResources{Memory: "lots"}); err == nil {
+			t.Fatalf("expected an error for an invalid memory")
+		}
+	})
+}
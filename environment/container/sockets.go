@@ -0,0 +1,194 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// dockerContextEnvVar is the environment variable that selects the docker context, instead of the current context in the docker config file
+	dockerContextEnvVar = "DOCKER_CONTEXT"
+	// dockerConfigEnvVar is the environment variable with the directory of the docker config file
+	dockerConfigEnvVar = "DOCKER_CONFIG"
+)
+
+var (
+	// vmDockerSockets are the sockets of the Docker compatible daemons that run in virtual machines, relative to the home directory
+	vmDockerSockets = []string{
+		".docker/run/docker.sock",       // Docker Desktop
+		".colima/default/docker.sock",   // Colima
+		".colima/docker.sock",           // older versions of Colima
+		".lima/docker/sock/docker.sock", // the docker template of Lima
+		".lima/default/sock/docker.sock",
+		".rd/docker.sock",           // Rancher Desktop
+		".orbstack/run/docker.sock", // OrbStack
+	}
+	// podmanMachineSockets are the sockets of the podman machines, relative to the home directory
+	podmanMachineSockets = []string{
+		".local/share/containers/podman/machine/podman.sock",
+		".local/share/containers/podman/machine/podman-machine-default/podman.sock",
+		".local/share/containers/podman/machine/qemu/podman.sock",
+		".local/share/containers/podman/machine/applehv/podman.sock",
+	}
+)
+
+// getLocalDockerHost returns the address of a local Docker compatible daemon, if DOCKER_HOST is not set.
+// It uses the endpoint of the current docker context, else the rootful socket if there is a daemon at it,
+// else the socket of the rootless docker daemon of the user or of Docker Desktop, Colima, Lima, Rancher Desktop or OrbStack.
+// It returns an empty string to use the default socket.
+func getLocalDockerHost() string {
+	if os.Getenv(dockerHostEnvVar) != "" {
+		return ""
+	}
+	if host := getDockerContextHost(); host != "" {
+		return host
+	}
+	if isSocket(rootfulDockerSocket) {
+		return ""
+	}
+	if socket := findSocket(getDockerSockets()); socket != "" {
+		return "unix://" + socket
+	}
+	return ""
+}
+
+// getDockerSockets returns the sockets that Docker compatible daemons other than the rootful one listen on
+func getDockerSockets() []string {
+	sockets := []string{}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		sockets = append(sockets, filepath.Join(runtimeDir, rootlessDockerSocketName))
+	}
+	sockets = append(sockets, filepath.Join("/run/user", fmt.Sprint(os.Getuid()), rootlessDockerSocketName))
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, socket := range vmDockerSockets {
+			sockets = append(sockets, filepath.Join(home, socket))
+		}
+	}
+	return sockets
+}
+
+// getPodmanMachineSockets returns the sockets that the podman machines forward the podman service of the machine to
+func getPodmanMachineSockets() []string {
+	sockets := []string{}
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, socket := range podmanMachineSockets {
+			sockets = append(sockets, filepath.Join(home, socket))
+		}
+	}
+	// newer versions of podman on macOS keep the socket in the temporary directory
+	return append(sockets, filepath.Join(os.TempDir(), "podman", "podman-machine-default-api.sock"))
+}
+
+// getDockerContextHost returns the unix socket of the current docker context, like the ones created by Colima and Rancher Desktop.
+// It returns an empty string for the default context and for contexts of remote daemons, which are configured using the config keys of the container engine.
+func getDockerContextHost() string {
+	configDir := os.Getenv(dockerConfigEnvVar)
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configDir = filepath.Join(home, ".docker")
+	}
+	contextName := os.Getenv(dockerContextEnvVar)
+	if contextName == "" {
+		config := struct {
+			CurrentContext string `json:"currentContext"`
+		}{}
+		data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+		if err != nil {
+			return ""
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			logrus.Debugf("failed to parse the docker config file in %s . Error: %q", configDir, err)
+			return ""
+		}
+		contextName = config.CurrentContext
+	}
+	if contextName == "" || contextName == "default" {
+		return ""
+	}
+	// the metadata of a context is in a directory named after the digest of its name
+	metaPath := filepath.Join(configDir, "contexts", "meta", fmt.Sprintf("%x", sha256.Sum256([]byte(contextName))), "meta.json")
+	meta := struct {
+		Endpoints map[string]struct {
+			Host string `json:"Host"`
+		} `json:"Endpoints"`
+	}{}
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		logrus.Debugf("failed to read the metadata of the docker context %s . Error: %q", contextName, err)
+		return ""
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		logrus.Debugf("failed to parse the metadata of the docker context %s . Error: %q", contextName, err)
+		return ""
+	}
+	host := meta.Endpoints["docker"].Host
+	if !strings.HasPrefix(host, "unix://") || !isSocket(strings.TrimPrefix(host, "unix://")) {
+		logrus.Debugf("not using the endpoint %s of the docker context %s , since it is not a local socket", host, contextName)
+		return ""
+	}
+	logrus.Debugf("using the endpoint of the docker context %s", contextName)
+	return host
+}
+
+// getSocketHost returns the address of the socket at the path, which can start with ~ for the home directory
+func getSocketHost(socket string) (string, error) {
+	if strings.HasPrefix(socket, "unix://") {
+		return socket, nil
+	}
+	if socket == "~" || strings.HasPrefix(socket, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find the home directory for the socket %s . Error: %q", socket, err)
+		}
+		socket = filepath.Join(home, strings.TrimPrefix(socket, "~"))
+	}
+	socket, err := filepath.Abs(socket)
+	if err != nil {
+		return "", fmt.Errorf("failed to make the path of the socket %s absolute. Error: %q", socket, err)
+	}
+	if !isSocket(socket) {
+		return "", fmt.Errorf("there is no socket at %s", socket)
+	}
+	return "unix://" + socket, nil
+}
+
+// findSocket returns the first of the paths that is a socket, or an empty string if none of them are
+func findSocket(sockets []string) string {
+	for _, socket := range sockets {
+		if isSocket(socket) {
+			return socket
+		}
+	}
+	return ""
+}
+
+// isSocket checks whether there is a socket at the path
+func isSocket(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Type() == os.ModeSocket
+}
@@ -0,0 +1,62 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetDockerContextHost(t *testing.T) {
+	configDir := t.TempDir()
+	socket := filepath.Join(configDir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unable to listen on a unix socket. Error: %q", err)
+	}
+	defer listener.Close()
+	t.Setenv(dockerConfigEnvVar, configDir)
+	t.Setenv(dockerContextEnvVar, "")
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext": "colima"}`), 0644); err != nil {
+		t.Fatalf("failed to write the docker config file. Error: %q", err)
+	}
+	if host := getDockerContextHost(); host != "" {
+		t.Fatalf("expected no host for a context without metadata. Actual: %s", host)
+	}
+	metaDir := filepath.Join(configDir, "contexts", "meta", fmt.Sprintf("%x", sha256.Sum256([]byte("colima"))))
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		t.Fatalf("failed to create the metadata directory. Error: %q", err)
+	}
+	meta := fmt.Sprintf(`{"Name": "colima", "Endpoints": {"docker": {"Host": "unix://%s"}}}`, socket)
+	if err := os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0644); err != nil {
+		t.Fatalf("failed to write the metadata of the context. Error: %q", err)
+	}
+	if host := getDockerContextHost(); host != "unix://"+socket {
+		t.Fatalf("wrong host for the context. Expected: unix://%s Actual: %s", socket, host)
+	}
+	if findSocket([]string{filepath.Join(configDir, "config.json"), socket}) != socket {
+		t.Fatalf("failed to find the socket at %s", socket)
+	}
+	t.Setenv(dockerContextEnvVar, "default")
+	if host := getDockerContextHost(); host != "" {
+		t.Fatalf("expected no host for the default context. Actual: %s", host)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+)
+
+// OutputCallback is called with each line written by a command run in a container, as soon as it is written
+type OutputCallback func(line string, isStderr bool)
+
+type outputCallbackKey struct{}
+
+// WithOutputCallback returns a context that streams the output of the commands run in containers using it to the callback
+func WithOutputCallback(ctx context.Context, callback OutputCallback) context.Context {
+	return context.WithValue(ctx, outputCallbackKey{}, callback)
+}
+
+// getOutputCallback returns the callback of the context, or one that logs the output if the output of the commands should be streamed
+func getOutputCallback(ctx context.Context, cmd environmenttypes.Command) OutputCallback {
+	if callback, ok := ctx.Value(outputCallbackKey{}).(OutputCallback); ok {
+		return callback
+	}
+	if !common.StreamCommandOutput || len(cmd) == 0 {
+		return nil
+	}
+	name := filepath.Base(cmd[0])
+	return func(line string, isStderr bool) {
+		if isStderr {
+			logrus.Infof("[%s stderr] %s", name, line)
+			return
+		}
+		logrus.Infof("[%s] %s", name, line)
+	}
+}
+
+// getOutputWriters returns the writers for the output of the command, which write to the buffers and stream each line to the callback of the context.
+// The returned function streams the last lines that do not end with a new line, and has to be called once the command finishes.
+func getOutputWriters(ctx context.Context, cmd environmenttypes.Command, outBuf, errBuf io.Writer) (stdout, stderr io.Writer, flush func()) {
+	callback := getOutputCallback(ctx, cmd)
+	if callback == nil {
+		return outBuf, errBuf, func() {}
+	}
+	mutex := &sync.Mutex{}
+	outLines := &lineWriter{callback: callback, mutex: mutex}
+	errLines := &lineWriter{callback: callback, isStderr: true, mutex: mutex}
+	return io.MultiWriter(outBuf, outLines), io.MultiWriter(errBuf, errLines), func() {
+		outLines.flush()
+		errLines.flush()
+	}
+}
+
+// lineWriter calls the callback with each line written to it
+type lineWriter struct {
+	callback OutputCallback
+	isStderr bool
+	// mutex is shared by the stdout and stderr writers of a command, so that the callback is not called concurrently
+	mutex   *sync.Mutex
+	partial []byte
+}
+
+// Write implements the io.Writer interface
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		idx := bytes.IndexByte(w.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		w.callback(string(bytes.TrimSuffix(w.partial[:idx], []byte("\r"))), w.isStderr)
+		w.partial = w.partial[idx+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if len(w.partial) > 0 {
+		w.callback(string(w.partial), w.isStderr)
+		w.partial = nil
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGetOutputWriters(t *testing.T) {
+	lines := []string{}
+	ctx := WithOutputCallback(context.TODO(), func(line string, isStderr bool) {
+		lines = append(lines, fmt.Sprintf("%t %s", isStderr, line))
+	})
+	var outBuf, errBuf bytes.Buffer
+	stdout, stderr, flush := getOutputWriters(ctx, []string{"gradle", "build"}, &outBuf, &errBuf)
+	fmt.Fprint(stdout, "Task :compile")
+	fmt.Fprint(stderr, "warning\r\n")
+	fmt.Fprint(stdout, "Java\nTask :test\nBUILD")
+	fmt.Fprint(stdout, " SUCCESSFUL")
+	flush()
+	want := []string{"true warning", "false Task :compileJava", "false Task :test", "false BUILD SUCCESSFUL"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("wrong lines streamed. Expected: %+v Actual: %+v", want, lines)
+	}
+	if outBuf.String() != "Task :compileJava\nTask :test\nBUILD SUCCESSFUL" || errBuf.String() != "warning\r\n" {
+		t.Fatalf("the output was not written to the buffers. Stdout: %q Stderr: %q", outBuf.String(), errBuf.String())
+	}
+}
@@ -19,21 +19,36 @@ package container
 import (
 	"archive/tar"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	dockercliconfig "github.com/docker/cli/cli/config"
+	dockerclitypes "github.com/docker/cli/cli/config/types"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/ioutils"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 )
 
-func copyDirToContainer(ctx context.Context, cli *client.Client, containerID, src, dst string) error {
-	reader := readDirAsTar(src, dst)
+const (
+	// dockerHubAuthKey is the key used for the docker hub credentials in the docker config.json file
+	dockerHubAuthKey = "https://index.docker.io/v1/"
+)
+
+func copyDirToContainer(ctx context.Context, cli *client.Client, containerID, src, dst string, owner *idtools.Identity) error {
+	reader := readDirAsTar(src, dst, owner)
 	if reader == nil {
 		err := fmt.Errorf("error during create tar archive from '%s'", src)
 		logrus.Error(err)
@@ -75,14 +90,52 @@ func copyFromContainer(ctx context.Context, cli *client.Client, containerID stri
 	preArchive := content
 	_, srcBase := archive.SplitPathDirEntry(copyInfo.Path)
 	preArchive = archive.RebaseArchiveEntries(content, srcBase, "")
-	return archive.CopyTo(preArchive, copyInfo, destPath)
+	return extractCopy(preArchive, copyInfo, destPath)
+}
+
+// extractCopy extracts the archive copied from a container to the destination path like archive.CopyTo,
+// and makes the output owner the owner of the files when move2kube runs as root
+func extractCopy(content io.Reader, srcInfo archive.CopyInfo, destPath string) error {
+	dstInfo, err := archive.CopyInfoDestinationPath(filepath.FromSlash(destPath))
+	if err != nil {
+		return err
+	}
+	dstDir, copyArchive, err := archive.PrepareArchiveCopy(content, srcInfo, dstInfo)
+	if err != nil {
+		return err
+	}
+	defer copyArchive.Close()
+	options := &archive.TarOptions{NoLchown: true, NoOverwriteDirNonDir: true}
+	if uid, gid, ok := common.GetOutputOwner(); ok {
+		options.NoLchown = false
+		options.ChownOpts = &idtools.Identity{UID: uid, GID: gid}
+	}
+	return archive.Untar(copyArchive, dstDir, options)
+}
+
+// getContainerFilesOwner returns the owner of the directories copied into the containers, or nil if they keep the owner of the files on the host
+func getContainerFilesOwner(rootless bool) *idtools.Identity {
+	if common.ContainerFilesOwner != "" {
+		uid, gid, err := common.ParseOwner(common.ContainerFilesOwner)
+		if err != nil {
+			logrus.Errorf("The directories copied into the containers keep the owner of the files on the host. Error: %q", err)
+			return nil
+		}
+		return &idtools.Identity{UID: uid, GID: gid}
+	}
+	if rootless {
+		// root in the container of a rootless engine is the user running move2kube
+		return &idtools.Identity{UID: 0, GID: 0}
+	}
+	return nil
 }
 
-func readDirAsTar(srcDir, basePath string) io.ReadCloser {
+// readDirAsTar returns the directory as a tar archive, with the files owned by the owner if it is not nil
+func readDirAsTar(srcDir, basePath string, owner *idtools.Identity) io.ReadCloser {
 	errChan := make(chan error)
 	pr, pw := io.Pipe()
 	go func() {
-		err := writeDirToTar(pw, srcDir, basePath)
+		err := writeDirToTar(pw, srcDir, basePath, owner)
 		errChan <- err
 	}()
 	closed := false
@@ -103,7 +156,7 @@ func readDirAsTar(srcDir, basePath string) io.ReadCloser {
 	})
 }
 
-func writeDirToTar(w *io.PipeWriter, srcDir, basePath string) error {
+func writeDirToTar(w *io.PipeWriter, srcDir, basePath string, owner *idtools.Identity) error {
 	defer w.Close()
 	tw := tar.NewWriter(w)
 	defer tw.Close()
@@ -140,6 +193,10 @@ func writeDirToTar(w *io.PipeWriter, srcDir, basePath string) error {
 			return nil
 		}
 		header.Name = filepath.ToSlash(filepath.Join(basePath, relPath))
+		if owner != nil {
+			header.Uid, header.Gid = owner.UID, owner.GID
+			header.Uname, header.Gname = "", ""
+		}
 		if err := tw.WriteHeader(header); err != nil {
 			logrus.Debugf("Error walking folder to copy to container : %s", err)
 			return err
@@ -160,8 +217,8 @@ func writeDirToTar(w *io.PipeWriter, srcDir, basePath string) error {
 	})
 }
 
-func copyDir(ctx context.Context, cli *client.Client, containerID, src, dst string) error {
-	reader := readDirAsTar(src, dst)
+func copyDir(ctx context.Context, cli *client.Client, containerID, src, dst string, owner *idtools.Identity) error {
+	reader := readDirAsTar(src, dst, owner)
 	if reader == nil {
 		err := fmt.Errorf("error during create tar archive from '%s'", src)
 		logrus.Error(err)
@@ -187,3 +244,101 @@ func copyDir(ctx context.Context, cli *client.Client, containerID, src, dst stri
 	}
 	return err
 }
+
+// getRegistry returns the registry of the image, which is docker hub if the image does not have one
+func getRegistry(image string) string {
+	if parts := strings.SplitN(image, "/", 2); len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+	return dockerHubAuthKey
+}
+
+// getRegistryAuth returns the encoded credentials for the registry of the image from the docker config.json file.
+// The credentials can be in the file or in the credential helpers and stores it refers to, like the keychain.
+func getRegistryAuth(image string) (string, error) {
+	registry := getRegistry(image)
+	configFile, err := dockercliconfig.Load(dockercliconfig.Dir())
+	if err != nil {
+		return "", fmt.Errorf("failed to load the docker config.json file. Error: %q", err)
+	}
+	authConfig, err := configFile.GetAuthConfig(registry)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the credentials for the registry %s . Error: %q", registry, err)
+	}
+	return encodeRegistryAuth(registry, authConfig)
+}
+
+// getExplicitRegistryAuth asks for the username and password of the registry of the image.
+// It returns an empty string if there is no username for the registry.
+func getExplicitRegistryAuth(image string) (string, error) {
+	registry := getRegistry(image)
+	quotedRegistry := `"` + strings.TrimSuffix(strings.TrimPrefix(registry, "https://"), "/") + `"`
+	username := qaengine.FetchStringAnswer(
+		fmt.Sprintf(common.ConfigContainerEngineRegistryUserNameKey, quotedRegistry),
+		fmt.Sprintf("[%s] Enter the username to pull the images of the transformers from the registry :", registry),
+		[]string{"The credentials in the docker config.json file did not work. Leave it empty to not login into the registry."},
+		"",
+	)
+	if username == "" {
+		return "", nil
+	}
+	password := qaengine.FetchPasswordAnswer(
+		fmt.Sprintf(common.ConfigContainerEngineRegistryPasswordKey, quotedRegistry),
+		fmt.Sprintf("[%s] Enter the password to pull the images of the transformers from the registry :", registry),
+		nil,
+	)
+	return encodeRegistryAuth(registry, dockerclitypes.AuthConfig{ServerAddress: registry, Username: username, Password: password})
+}
+
+func encodeRegistryAuth(registry string, authConfig dockerclitypes.AuthConfig) (string, error) {
+	authBytes, err := json.Marshal(authConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the credentials for the registry %s . Error: %q", registry, err)
+	}
+	return base64.URLEncoding.EncodeToString(authBytes), nil
+}
+
+// validatePullPolicy returns an error if the pull policy is not one of the supported ones
+func validatePullPolicy(policy environmenttypes.PullPolicy) error {
+	switch policy {
+	case "", environmenttypes.PullAlways, environmenttypes.PullIfNotPresent:
+		return nil
+	}
+	return fmt.Errorf("the pull policy %s is not supported. The supported pull policies are %s and %s", policy, environmenttypes.PullAlways, environmenttypes.PullIfNotPresent)
+}
+
+// getNetwork returns the network of a container, which is none by default, or an error if it is not one of the supported ones
+func getNetwork(network environmenttypes.ContainerNetwork) (environmenttypes.ContainerNetwork, error) {
+	switch network {
+	case "":
+		return environmenttypes.NetworkNone, nil
+	case environmenttypes.NetworkNone, environmenttypes.NetworkHost, environmenttypes.NetworkBridge:
+		return network, nil
+	}
+	return "", fmt.Errorf("the network %s is not supported. The supported networks are %s, %s and %s", network, environmenttypes.NetworkNone, environmenttypes.NetworkHost, environmenttypes.NetworkBridge)
+}
+
+// parsePlatform parses a platform like linux/amd64 or linux/arm/v7
+func parsePlatform(platform string) (*specs.Platform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("the platform %s is invalid. It has to be like linux/amd64 or linux/arm/v7", platform)
+	}
+	p := &specs.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// ImageMatchesPlatform checks whether the image is of the platform. All the images match an empty platform.
+func ImageMatchesPlatform(inspectOutput types.ImageInspect, platform string) bool {
+	if platform == "" {
+		return true
+	}
+	p, err := parsePlatform(platform)
+	if err != nil {
+		return false
+	}
+	return inspectOutput.Os == p.OS && inspectOutput.Architecture == p.Architecture && (p.Variant == "" || inspectOutput.Variant == p.Variant)
+}
@@ -0,0 +1,98 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/idtools"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+func TestGetRegistry(t *testing.T) {
+	testCases := map[string]string{
+		"quay.io/konveyor/hello-world":      "quay.io",
+		"localhost:5000/transformer":        "localhost:5000",
+		"localhost/transformer:v1":          "localhost",
+		"konveyor/move2kube":                dockerHubAuthKey,
+		"golang:1.19":                       dockerHubAuthKey,
+		"registry.example.com:5000/a/b:tag": "registry.example.com:5000",
+	}
+	for image, want := range testCases {
+		if got := getRegistry(image); got != want {
+			t.Fatalf("wrong registry for the image %s . Expected: %s Actual: %s", image, want, got)
+		}
+	}
+}
+
+func TestReadDirAsTarOwner(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("failed to create the test file. Error: %q", err)
+	}
+	reader := readDirAsTar(dir, "/workspace", &idtools.Identity{UID: 1001, GID: 0})
+	defer reader.Close()
+	header, err := tar.NewReader(reader).Next()
+	if err != nil {
+		t.Fatalf("failed to read the tar archive. Error: %q", err)
+	}
+	if header.Name != "/workspace/a.txt" || header.Uid != 1001 || header.Gid != 0 {
+		t.Fatalf("wrong header in the tar archive. Expected /workspace/a.txt owned by 1001:0 Actual: %s owned by %d:%d", header.Name, header.Uid, header.Gid)
+	}
+}
+
+func TestGetNetwork(t *testing.T) {
+	for network, expected := range map[environmenttypes.ContainerNetwork]environmenttypes.ContainerNetwork{
+		"":                             environmenttypes.NetworkNone,
+		environmenttypes.NetworkNone:   environmenttypes.NetworkNone,
+		environmenttypes.NetworkHost:   environmenttypes.NetworkHost,
+		environmenttypes.NetworkBridge: environmenttypes.NetworkBridge,
+	} {
+		actual, err := getNetwork(network)
+		if err != nil {
+			t.Fatalf("failed to get the network %s . Error: %q", network, err)
+		}
+		if actual != expected {
+			t.Fatalf("wrong network for %s . Expected: %s Actual: %s", network, expected, actual)
+		}
+	}
+	if _, err := getNetwork("container:other"); err == nil {
+		t.Fatalf("expected an error for an unsupported network")
+	}
+}
+
+func TestImageMatchesPlatform(t *testing.T) {
+	image := types.ImageInspect{Os: "linux", Architecture: "arm", Variant: "v7"}
+	for platform, expected := range map[string]bool{
+		"":              true,
+		"linux/arm":     true,
+		"linux/arm/v7":  true,
+		"linux/arm/v6":  false,
+		"linux/amd64":   false,
+		"windows/arm":   false,
+		"linux":         false,
+		"linux/arm/v7/": false,
+	} {
+		if actual := ImageMatchesPlatform(image, platform); actual != expected {
+			t.Fatalf("wrong match for the platform %s . Expected: %t Actual: %t", platform, expected, actual)
+		}
+	}
+}
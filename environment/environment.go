@@ -18,6 +18,8 @@ package environment
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"net"
@@ -26,6 +28,7 @@ import (
 	"reflect"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/common/deepcopy"
@@ -79,7 +82,7 @@ type EnvironmentInstance interface {
 	Stat(name string) (fs.FileInfo, error)
 	Download(envpath string) (outpath string, err error)
 	Upload(outpath string) (envpath string, err error)
-	Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error)
+	Exec(ctx context.Context, cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error)
 	Destroy() error
 
 	GetSource() string
@@ -94,12 +97,18 @@ func NewEnvironment(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttypes
 		return env, err
 	}
 	envInfo.TempPath = tempPath
+	if envInfo.Ctx == nil {
+		envInfo.Ctx = context.Background()
+	}
 	env = &Environment{
 		EnvInfo:      envInfo,
 		Children:     []*Environment{},
 		TempPathsMap: map[string]string{},
 		active:       true,
 	}
+	if c.Image == "" && isContainerBuildDeclared(c) {
+		c.Image = getBuiltImageName(envInfo)
+	}
 	if c.Image != "" {
 		envVariableName := common.MakeStringEnvNameCompliant(c.Image)
 		// Check if image is part of the current environment.
@@ -163,7 +172,27 @@ func (e *Environment) Exec(cmd environmenttypes.Command) (stdout string, stderr
 		logrus.Debug(err)
 		return "", "", 0, err
 	}
-	return e.Env.Exec(cmd)
+	return e.Env.Exec(e.Ctx, cmd)
+}
+
+// ExecWithTimeout executes an executable within the environment and kills it if it does not finish within the timeout.
+// A timeout of 0 means that the executable is given as much time as it needs.
+func (e *Environment) ExecWithTimeout(cmd environmenttypes.Command, timeout time.Duration) (stdout string, stderr string, exitcode int, err error) {
+	if timeout <= 0 {
+		return e.Exec(cmd)
+	}
+	if !e.active {
+		err = &EnvironmentNotActiveError{}
+		logrus.Debug(err)
+		return "", "", 0, err
+	}
+	ctx, cancel := context.WithTimeout(e.Ctx, timeout)
+	defer cancel()
+	stdout, stderr, exitcode, err = e.Env.Exec(ctx, cmd)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && e.Ctx.Err() == nil {
+		return stdout, stderr, exitcode, &ExecTimeoutError{Cmd: cmd, Timeout: timeout}
+	}
+	return stdout, stderr, exitcode, err
 }
 
 // Destroy destroys all artifacts specific to the environment
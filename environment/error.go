@@ -16,6 +16,14 @@
 
 package environment
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
 // EnvironmentNotActiveError represents the error when an environment is not active and a function is called on it
 type EnvironmentNotActiveError struct {
 }
@@ -24,3 +32,14 @@ type EnvironmentNotActiveError struct {
 func (e *EnvironmentNotActiveError) Error() string {
 	return "environment Not active. Process is terminating"
 }
+
+// ExecTimeoutError represents the error when a command did not finish within its timeout and was killed
+type ExecTimeoutError struct {
+	Cmd     environmenttypes.Command
+	Timeout time.Duration
+}
+
+// Error implements the Error interface
+func (e *ExecTimeoutError) Error() string {
+	return fmt.Sprintf("the command '%s' did not finish within %s and was killed", strings.Join(e.Cmd, " "), e.Timeout)
+}
@@ -18,6 +18,7 @@ package environment
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -89,7 +90,7 @@ func (e *Local) Stat(name string) (fs.FileInfo, error) {
 }
 
 // Exec executes an executable within the environment
-func (e *Local) Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
+func (e *Local) Exec(ctx context.Context, cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
 	if common.DisableLocalExecution {
 		err := fmt.Errorf("local execution prevented by %s flag", common.DisableLocalExecutionFlag)
 		logrus.Error(err)
@@ -98,7 +99,7 @@ func (e *Local) Exec(cmd environmenttypes.Command) (stdout string, stderr string
 	var outb, errb bytes.Buffer
 	var execcmd *exec.Cmd
 	if len(cmd) > 0 {
-		execcmd = exec.Command(cmd[0], cmd[1:]...)
+		execcmd = exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 	} else {
 		err := fmt.Errorf("no command found to execute")
 		logrus.Errorf("%s", err)
@@ -108,7 +109,19 @@ func (e *Local) Exec(cmd environmenttypes.Command) (stdout string, stderr string
 	execcmd.Stdout = &outb
 	execcmd.Stderr = &errb
 	execcmd.Env = e.getEnv()
-	err = execcmd.Run()
+	if err = execcmd.Start(); err == nil {
+		done := make(chan error, 1)
+		go func() { done <- execcmd.Wait() }()
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			// the processes started by the command may keep its output open after it is killed, so it is not waited for
+			return "", "", 0, ctx.Err()
+		}
+	}
+	if ctx.Err() != nil {
+		return outb.String(), errb.String(), exitcode, ctx.Err()
+	}
 	if err != nil {
 		var ee *exec.ExitError
 		var pe *os.PathError
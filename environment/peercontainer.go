@@ -17,14 +17,18 @@
 package environment
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"net"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/dchest/uniuri"
+	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment/container"
 	"github.com/konveyor/move2kube/types"
 	environmenttypes "github.com/konveyor/move2kube/types/environment"
@@ -35,6 +39,10 @@ import (
 const (
 	// DefaultWorkspaceDir is the default workspace directory
 	DefaultWorkspaceDir = "workspace"
+	// killTimeout is the time given to kill a command that timed out, since its context is done already
+	killTimeout = time.Minute
+	// secretValueEnvName is the environment variable that has the contents of a secret file while it is written into the container
+	secretValueEnvName = "M2K_SECRET_VALUE"
 )
 
 // PeerContainer is supports spawning peer containers to run the environment
@@ -48,7 +56,22 @@ type PeerContainer struct {
 
 	ImageName     string
 	ImageWithData string
+	Resources     environmenttypes.ContainerResources
 	CID           string // A started instance of ImageWithData
+	// Network is the network of the container, which is none by default
+	Network environmenttypes.ContainerNetwork
+	// Mounts are the directories mounted read-only into the container, keyed by their path on the host
+	Mounts map[string]string
+	// ReuseOnReset reuses the warm container on each reset instead of creating a new one
+	ReuseOnReset bool
+	// Tmpfs are the directories of the container that are kept in memory, which have the secret files
+	Tmpfs []string
+	// uploadedDirs are the directories uploaded into the container since the last reset
+	uploadedDirs []string
+	// secretEnvs are the secret environment variables of the commands, like NAME=value
+	secretEnvs []string
+	// secretFiles are the contents of the secret files, keyed by their path in the container
+	secretFiles map[string]string
 }
 
 // NewPeerContainer creates an instance of peer container based environment
@@ -57,6 +80,12 @@ func NewPeerContainer(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttyp
 		EnvInfo:        envInfo,
 		ImageName:      c.Image,
 		GRPCQAReceiver: grpcQAReceiver,
+		Resources:      c.Resources,
+		Network:        c.Network,
+		ReuseOnReset:   c.ReuseOnReset,
+	}
+	if grpcQAReceiver != nil && (c.Network == "" || c.Network == environmenttypes.NetworkNone) {
+		logrus.Warnf("the container of %s has no network, so it cannot ask questions. Set the network of the container to %s to let it ask questions", envInfo.Name, environmenttypes.NetworkBridge)
 	}
 	if c.WorkingDir != "" {
 		peerContainer.WorkspaceContext = c.WorkingDir
@@ -64,21 +93,43 @@ func NewPeerContainer(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttyp
 		peerContainer.WorkspaceContext = filepath.Join(string(filepath.Separator), types.AppNameShort)
 	}
 	peerContainer.WorkspaceSource = filepath.Join(string(filepath.Separator), DefaultWorkspaceDir)
-	cengine := container.GetContainerEngine()
-	if cengine == nil {
-		return ei, fmt.Errorf("no working container runtime found")
+	if err := peerContainer.loadSecrets(c.Secrets); err != nil {
+		return ei, fmt.Errorf("failed to load the secrets of the container of %s . Error: %q", envInfo.Name, err)
+	}
+	cengine, err := container.GetContainerEngine()
+	if err != nil {
+		return ei, fmt.Errorf("no working container runtime found. Error: %q", err)
+	}
+	if isContainerBuildDeclared(c) {
+		// the image is built from the Dockerfile of the transformer, and only rebuilt when the Dockerfile or its context changes
+		buildContext := filepath.Join(envInfo.Context, c.ContainerBuild.Context)
+		dockerfile := c.ContainerBuild.Dockerfile
+		if dockerfile == "" {
+			dockerfile = common.DefaultDockerfileName
+		}
+		if err := cengine.BuildImage(envInfo.Ctx, c.Image, buildContext, dockerfile); err != nil {
+			return ei, fmt.Errorf("failed to build the container image %s using the Dockerfile %s in the context %s . Error: %q", c.Image, dockerfile, buildContext, err)
+		}
+	} else if err := cengine.PullImage(envInfo.Ctx, c.Image, c.PullPolicy, c.Platform); err != nil {
+		return ei, fmt.Errorf("failed to pull the container image %s . Error: %q", c.Image, err)
 	}
 	newImageName := peerContainer.ImageName + strings.ToLower(envInfo.Name+uniuri.NewLen(5))
-	err = cengine.CopyDirsIntoImage(peerContainer.ImageName, newImageName, map[string]string{envInfo.Source: peerContainer.WorkspaceSource})
+	imagePaths := map[string]string{envInfo.Source: peerContainer.WorkspaceSource}
+	if c.MountSource {
+		// the source is mounted into the containers instead of being copied into the image
+		peerContainer.Mounts = imagePaths
+		imagePaths = map[string]string{}
+	}
+	err = cengine.CopyDirsIntoImage(envInfo.Ctx, peerContainer.ImageName, newImageName, imagePaths)
 	if err != nil {
 		logrus.Debugf("Unable to create new container image with new data")
 		if c.ContainerBuild.Context != "" {
-			err = cengine.BuildImage(c.Image, filepath.Join(envInfo.Context, c.ContainerBuild.Context), c.ContainerBuild.Dockerfile)
+			err = cengine.BuildImage(envInfo.Ctx, c.Image, filepath.Join(envInfo.Context, c.ContainerBuild.Context), c.ContainerBuild.Dockerfile)
 			if err != nil {
 				logrus.Errorf("Unable to build new container image for %s : %s", c.Image, err)
 				return ei, err
 			}
-			err = cengine.CopyDirsIntoImage(c.Image, newImageName, map[string]string{envInfo.Source: peerContainer.WorkspaceSource})
+			err = cengine.CopyDirsIntoImage(envInfo.Ctx, c.Image, newImageName, imagePaths)
 			if err != nil {
 				logrus.Errorf("Unable to copy paths to new container image : %s", err)
 			}
@@ -87,53 +138,165 @@ func NewPeerContainer(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttyp
 		}
 	}
 	peerContainer.ImageWithData = newImageName
-	cid, err := cengine.CreateContainer(newImageName)
+	cid, err := container.GetPooledContainer(envInfo.Ctx, newImageName, peerContainer.Resources, peerContainer.Network, peerContainer.Mounts, peerContainer.Tmpfs)
 	if err != nil {
 		logrus.Errorf("Unable to start container with image %s : %s", newImageName, cid)
 		return ei, err
 	}
 	peerContainer.CID = cid
+	if err := peerContainer.writeSecretFiles(); err != nil {
+		return ei, err
+	}
 	return peerContainer, nil
 }
 
-// Reset resets the PeerContainer environment
+// loadSecrets reads the values of the secrets from the host. The secret files are written to directories kept in memory in the container.
+func (e *PeerContainer) loadSecrets(secrets []environmenttypes.ContainerSecret) error {
+	e.secretFiles = map[string]string{}
+	for _, secret := range secrets {
+		value := ""
+		switch {
+		case secret.FromEnv != "":
+			var ok bool
+			if value, ok = os.LookupEnv(secret.FromEnv); !ok {
+				logrus.Warnf("The environment variable %s of a secret of %s is not set, so the secret is not injected into its container", secret.FromEnv, e.Name)
+				continue
+			}
+		case secret.FromFile != "":
+			secretPath := secret.FromFile
+			if !filepath.IsAbs(secretPath) {
+				secretPath = filepath.Join(e.Context, secretPath)
+			}
+			data, err := os.ReadFile(secretPath)
+			if err != nil {
+				return fmt.Errorf("failed to read the secret file %s . Error: %q", secretPath, err)
+			}
+			value = string(data)
+		default:
+			return fmt.Errorf("a secret needs fromEnv or fromFile")
+		}
+		if secret.Env == "" && secret.File == "" {
+			return fmt.Errorf("the secret from %s%s needs env or file", secret.FromEnv, secret.FromFile)
+		}
+		if secret.Env != "" {
+			e.secretEnvs = append(e.secretEnvs, secret.Env+"="+value)
+		}
+		if secret.File != "" {
+			if !strings.HasPrefix(secret.File, "/") {
+				return fmt.Errorf("the path %s of the secret file in the container is not absolute", secret.File)
+			}
+			e.secretFiles[secret.File] = value
+			e.Tmpfs = common.AppendIfNotPresent(e.Tmpfs, path.Dir(secret.File))
+		}
+	}
+	return nil
+}
+
+// writeSecretFiles writes the secret files into the container. The contents are given to the command as an environment variable,
+// since the engines cannot copy files into directories kept in memory.
+func (e *PeerContainer) writeSecretFiles() error {
+	if len(e.secretFiles) == 0 {
+		return nil
+	}
+	cengine, err := container.GetContainerEngine()
+	if err != nil {
+		return err
+	}
+	for file, value := range e.secretFiles {
+		cmd := environmenttypes.Command{"/bin/sh", "-c", `umask 077 && printf '%s' "$` + secretValueEnvName + `" > "$0"`, file}
+		_, stderr, exitCode, err := cengine.RunCmdInContainer(e.Ctx, e.CID, cmd, "/", []string{secretValueEnvName + "=" + value})
+		if err != nil {
+			return fmt.Errorf("failed to write the secret file %s into the container %s . Error: %q", file, e.CID, err)
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("failed to write the secret file %s into the container %s . Exit code: %d Stderr: %s", file, e.CID, exitCode, stderr)
+		}
+	}
+	return nil
+}
+
+// Reset resets the PeerContainer environment.
+// A new container is created from the image with the data, unless ReuseOnReset is set,
+// in which case the warm container is reused after removing the directories uploaded into it.
 func (e *PeerContainer) Reset() error {
-	cengine := container.GetContainerEngine()
-	err := cengine.StopAndRemoveContainer(e.CID)
+	cengine, err := container.GetContainerEngine()
 	if err != nil {
-		logrus.Errorf("Unable to delete image %s : %s", e.ImageWithData, err)
+		return err
+	}
+	if e.ReuseOnReset {
+		if len(e.uploadedDirs) == 0 {
+			return nil
+		}
+		cmd := append(environmenttypes.Command{"rm", "-rf"}, e.uploadedDirs...)
+		_, stderr, exitCode, err := cengine.RunCmdInContainer(e.Ctx, e.CID, cmd, "/", nil)
+		if err == nil && exitCode == 0 {
+			e.uploadedDirs = nil
+			return nil
+		}
+		logrus.Debugf("failed to remove the uploaded directories from the container %s , so it is created again. Exit code: %d Stderr: %s Error: %q", e.CID, exitCode, stderr, err)
+	}
+	if err := container.ReleasePooledContainer(e.ImageWithData); err != nil {
+		logrus.Errorf("Unable to delete the container %s : %s", e.CID, err)
 	}
-	cid, err := cengine.CreateContainer(e.ImageWithData)
+	e.uploadedDirs = nil
+	cid, err := container.GetPooledContainer(e.Ctx, e.ImageWithData, e.Resources, e.Network, e.Mounts, e.Tmpfs)
 	if err != nil {
-		logrus.Errorf("Unable to start container with image %s : %s", e.ImageWithData, cid)
+		logrus.Errorf("Unable to start container with image %s : %s", e.ImageWithData, err)
 		return err
 	}
 	e.CID = cid
-	return nil
+	return e.writeSecretFiles()
 }
 
 // Stat returns stat info of the file/dir in the env
 func (e *PeerContainer) Stat(name string) (fs.FileInfo, error) {
-	cengine := container.GetContainerEngine()
-	return cengine.Stat(e.CID, name)
+	cengine, err := container.GetContainerEngine()
+	if err != nil {
+		return nil, err
+	}
+	return cengine.Stat(e.Ctx, e.CID, name)
 }
 
 // Exec executes a command in the container
-func (e *PeerContainer) Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
-	cengine := container.GetContainerEngine()
+func (e *PeerContainer) Exec(ctx context.Context, cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
+	cengine, err := container.GetContainerEngine()
+	if err != nil {
+		return "", "", 0, err
+	}
 	envs := []string{}
 	if e.GRPCQAReceiver != nil {
 		hostname := getIP()
 		port := cast.ToString(e.GRPCQAReceiver.(*net.TCPAddr).Port)
 		envs = append(envs, GRPCEnvName+"="+hostname+":"+port)
 	}
-	return cengine.RunCmdInContainer(e.CID, cmd, e.WorkspaceContext, envs)
+	envs = append(envs, e.secretEnvs...)
+	if _, ok := ctx.Deadline(); !ok {
+		return cengine.RunCmdInContainer(ctx, e.CID, cmd, e.WorkspaceContext, envs)
+	}
+	// Cancelling the exec does not stop the command in the container,
+	// so the command is run using a shell that records its pid, to kill it if it does not finish in time.
+	pidFile := "/var/tmp/m2k-exec-" + uniuri.NewLen(10) + ".pid"
+	shellCmd := append(environmenttypes.Command{"/bin/sh", "-c", `echo $$ > "$0"; exec "$@"`, pidFile}, cmd...)
+	stdout, stderr, exitcode, err = cengine.RunCmdInContainer(ctx, e.CID, shellCmd, e.WorkspaceContext, envs)
+	cleanupCmd := environmenttypes.Command{"/bin/sh", "-c", `rm -f "$0"`, pidFile}
+	if ctx.Err() != nil {
+		cleanupCmd = environmenttypes.Command{"/bin/sh", "-c", `kill -9 "$(cat "$0")"; rm -f "$0"`, pidFile}
+	}
+	killCtx, cancel := context.WithTimeout(context.Background(), killTimeout)
+	defer cancel()
+	if _, killStderr, killExitCode, killErr := cengine.RunCmdInContainer(killCtx, e.CID, cleanupCmd, "/", nil); killErr != nil || killExitCode != 0 {
+		logrus.Debugf("failed to clean up after the command %v in the container %s . Exit code: %d Stderr: %s Error: %v", cmd, e.CID, killExitCode, killStderr, killErr)
+	}
+	return stdout, stderr, exitcode, err
 }
 
 // Destroy destroys the container instance
 func (e *PeerContainer) Destroy() error {
-	cengine := container.GetContainerEngine()
-	err := cengine.StopAndRemoveContainer(e.CID)
+	cengine, err := container.GetContainerEngine()
+	if err != nil {
+		return err
+	}
+	err = container.ReleasePooledContainer(e.ImageWithData)
 	if err != nil {
 		logrus.Errorf("Unable to stop and remove container %s : %s", e.CID, err)
 	}
@@ -151,8 +314,11 @@ func (e *PeerContainer) Download(path string) (string, error) {
 		logrus.Errorf("Unable to create temp dir : %s", err)
 		return path, err
 	}
-	cengine := container.GetContainerEngine()
-	err = cengine.CopyDirsFromContainer(e.CID, map[string]string{path: output})
+	cengine, err := container.GetContainerEngine()
+	if err != nil {
+		return path, err
+	}
+	err = cengine.CopyDirsFromContainer(e.Ctx, e.CID, map[string]string{path: output})
 	if err != nil {
 		logrus.Errorf("Unable to copy data from container : %s", err)
 		return path, err
@@ -162,13 +328,18 @@ func (e *PeerContainer) Download(path string) (string, error) {
 
 // Upload uploads the path from outside the environment into it
 func (e *PeerContainer) Upload(outpath string) (envpath string, err error) {
-	envpath = "/var/tmp/" + uniuri.NewLen(5) + "/" + filepath.Base(outpath)
-	cengine := container.GetContainerEngine()
-	err = cengine.CopyDirsIntoContainer(e.CID, map[string]string{outpath: envpath})
+	uploadDir := "/var/tmp/" + uniuri.NewLen(5)
+	envpath = uploadDir + "/" + filepath.Base(outpath)
+	cengine, err := container.GetContainerEngine()
+	if err != nil {
+		return outpath, err
+	}
+	err = cengine.CopyDirsIntoContainer(e.Ctx, e.CID, map[string]string{outpath: envpath})
 	if err != nil {
 		logrus.Errorf("Unable to copy data from container : %s", err)
 		return outpath, err
 	}
+	e.uploadedDirs = append(e.uploadedDirs, uploadDir)
 	return envpath, nil
 }
 
@@ -181,3 +352,13 @@ func (e *PeerContainer) GetContext() string {
 func (e *PeerContainer) GetSource() string {
 	return e.WorkspaceSource
 }
+
+// isContainerBuildDeclared checks whether the container image is built from a Dockerfile provided by the transformer
+func isContainerBuildDeclared(c environmenttypes.Container) bool {
+	return c.ContainerBuild.Dockerfile != "" || c.ContainerBuild.Context != ""
+}
+
+// getBuiltImageName returns the name of the image built from the Dockerfile of the transformer, if the transformer does not specify one
+func getBuiltImageName(envInfo EnvInfo) string {
+	return common.MakeStringDNSNameCompliant(types.AppNameShort+"-"+envInfo.Name) + ":latest"
+}
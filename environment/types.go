@@ -16,6 +16,8 @@
 
 package environment
 
+import "context"
+
 // EnvInfo stores the envionment generic info
 type EnvInfo struct {
 	Name        string
@@ -29,4 +31,7 @@ type EnvInfo struct {
 	CurrEnvOutputBasePath string
 	RelTemplatesDir       string
 	TempPath              string
+
+	// Ctx is cancelled when the run is interrupted, which stops the commands and containers of the environment
+	Ctx context.Context `yaml:"-" json:"-"`
 }
@@ -40,6 +40,8 @@ type AddOnConfig struct {
 	OpeningDelimiter string
 	ClosingDelimiter string
 	Config           interface{}
+	// Libraries are the paths of the template library files whose templates can be included
+	Libraries []string
 }
 
 // TemplateCopy copies a directory to another and applies a template config on all files in the directory
@@ -102,7 +104,7 @@ func templateCopyProcessFileCallBack(sourceFilePath, destinationFilePath string,
 	defer destinationWriter.Close()
 	err = writeTemplateToFile(string(src), addOnConfig.Config,
 		destinationFilePath, si.Mode(),
-		addOnConfig.OpeningDelimiter, addOnConfig.ClosingDelimiter)
+		addOnConfig.OpeningDelimiter, addOnConfig.ClosingDelimiter, addOnConfig.Libraries)
 	if err != nil {
 		logrus.Errorf("Unable to copy templated file %s to %s : %s", sourceFilePath, destinationFilePath, err)
 		return err
@@ -147,13 +149,17 @@ func templateCopyDeletionCallBack(source, destination string, addOnConfigAsIface
 
 // writeTemplateToFile writes a templated string to a file
 func writeTemplateToFile(tpl string, config interface{}, writepath string,
-	filemode os.FileMode, openingDelimiter string, closingDelimiter string) error {
+	filemode os.FileMode, openingDelimiter string, closingDelimiter string, libraries []string) error {
 	var tplbuffer bytes.Buffer
 	if openingDelimiter == "" || closingDelimiter == "" {
 		openingDelimiter = "{{"
 		closingDelimiter = "}}"
 	}
-	packageTemplate, err := template.New("").Delims(openingDelimiter, closingDelimiter).Funcs(sprig.TxtFuncMap()).Parse(tpl)
+	packageTemplate, err := newTemplateWithLibraries(libraries)
+	if err != nil {
+		return err
+	}
+	packageTemplate, err = packageTemplate.Delims(openingDelimiter, closingDelimiter).Parse(tpl)
 	if err != nil {
 		logrus.Errorf("Unable to parse the template : %s", err)
 		return err
@@ -174,3 +180,29 @@ func writeTemplateToFile(tpl string, config interface{}, writepath string,
 	}
 	return nil
 }
+
+// newTemplateWithLibraries creates a template that has the templates defined in the template libraries and an include function.
+// The libraries are parsed first, so that the templates defined in a template file take precedence.
+func newTemplateWithLibraries(libraries []string) (*template.Template, error) {
+	tmpl := template.New("")
+	funcs := sprig.TxtFuncMap()
+	// include executes a named template and returns the result, so that it can be piped to other functions like nindent
+	funcs["include"] = func(name string, data interface{}) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	tmpl.Funcs(funcs)
+	for _, library := range libraries {
+		libraryContents, err := os.ReadFile(library)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the template library file at path %s . Error: %q", library, err)
+		}
+		if _, err := tmpl.New(library).Parse(string(libraryContents)); err != nil {
+			return nil, fmt.Errorf("failed to parse the template library file at path %s . Error: %q", library, err)
+		}
+	}
+	return tmpl, nil
+}
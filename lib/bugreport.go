@@ -0,0 +1,242 @@
+/*
+ *  Copyright IBM Corporation 2020, 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	graphtypes "github.com/konveyor/move2kube/types/graph"
+	"github.com/konveyor/move2kube/types/info"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	redactedValue = "REDACTED"
+)
+
+var (
+	// sensitiveKeyRegex matches the keys of the values that are redacted in a bug report
+	sensitiveKeyRegex = regexp.MustCompile(`(?i)(password|passwd|secret|token|apikey|api_key|credential|privatekey|private_key)`)
+	// sensitiveTextRegex matches sensitive key value pairs in text like logs
+	sensitiveTextRegex = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|apikey|api_key|credential|privatekey|private_key)[^\s=:"]*"?\s*[=:]\s*)("[^"]*"|\S+)`)
+	// urlCredentialsRegex matches the credentials in URLs
+	urlCredentialsRegex = regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`)
+	// bugReportEnvVars are the environment variables that are included in a bug report
+	bugReportEnvVars = []string{"DOCKER_HOST", "DOCKER_TLS_VERIFY", "DOCKER_CERT_PATH", "CONTAINER_HOST", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "SHELL", "LANG"}
+)
+
+// BugReportInputs are the paths of the files that are collected in a bug report
+type BugReportInputs struct {
+	PlanPath string
+	// QAPaths are the paths of the QA config, the config key documentation and the QA cache
+	QAPaths []string
+	// OutputPath is the output directory of the transform, which has the logs and the reports
+	OutputPath string
+}
+
+// bugReportEnvironment describes the environment move2kube was run in
+type bugReportEnvironment struct {
+	Version           info.VersionInfo  `yaml:"version"`
+	OS                string            `yaml:"os"`
+	Arch              string            `yaml:"arch"`
+	CPUs              int               `yaml:"cpus"`
+	DockerSocketFound bool              `yaml:"dockerSocketFound"`
+	EnvVars           map[string]string `yaml:"envVars,omitempty"`
+	CreatedAt         string            `yaml:"createdAt"`
+}
+
+// CreateBugReport packages the plan, the transformer configs, the QA files, the logs, the reports and the environment info into an archive.
+// Passwords, tokens and other secrets are redacted. The files that are missing are skipped.
+// It returns the paths of the files in the archive.
+func CreateBugReport(inputs BugReportInputs, archivePath string) ([]string, error) {
+	entries := map[string][]byte{}
+	if inputs.PlanPath != "" {
+		if data, err := os.ReadFile(inputs.PlanPath); err != nil {
+			logrus.Warnf("Skipping the plan in the bug report. Error: %q", err)
+		} else {
+			entries["plan/"+filepath.Base(inputs.PlanPath)] = redactYaml(data)
+			for name, data := range getPlanTransformerConfigs(inputs.PlanPath) {
+				entries["transformers/"+name+".yaml"] = redactYaml(data)
+			}
+		}
+	}
+	for _, qaPath := range inputs.QAPaths {
+		if data, err := os.ReadFile(qaPath); err == nil {
+			entries["qa/"+filepath.Base(qaPath)] = redactYaml(data)
+		}
+	}
+	if inputs.OutputPath != "" {
+		logPaths, _ := filepath.Glob(filepath.Join(inputs.OutputPath, common.RunLogsDir, "*.log"))
+		for _, logPath := range logPaths {
+			if data, err := os.ReadFile(logPath); err == nil {
+				entries["logs/"+filepath.Base(logPath)] = redactText(data)
+			}
+		}
+		if data, err := os.ReadFile(filepath.Join(inputs.OutputPath, ImageBuildReportFile)); err == nil {
+			entries["reports/"+ImageBuildReportFile] = redactText(data)
+		}
+	}
+	if data, err := os.ReadFile(graphtypes.GraphFileName); err == nil {
+		entries["reports/"+graphtypes.GraphFileName] = redactText(data)
+	}
+	envData, err := yaml.Marshal(getBugReportEnvironment())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the environment info to yaml. Error: %q", err)
+	}
+	entries["environment.yaml"] = envData
+	return writeBugReportArchive(archivePath, entries)
+}
+
+// getPlanTransformerConfigs returns the configs of the transformers used by the plan
+func getPlanTransformerConfigs(planPath string) map[string][]byte {
+	configs := map[string][]byte{}
+	plan, err := plantypes.ReadPlan(planPath, "")
+	if err != nil {
+		logrus.Warnf("Skipping the transformer configs in the bug report. Failed to read the plan at path %s . Error: %q", planPath, err)
+		return configs
+	}
+	if plan.Spec.CustomizationsDir != "" {
+		if _, err := os.Stat(plan.Spec.CustomizationsDir); err == nil {
+			if err := CopyCustomizationsAssetsData(plan.Spec.CustomizationsDir); err != nil {
+				logrus.Warnf("The configs of the custom transformers will be missing from the bug report. Error: %q", err)
+			}
+		}
+	}
+	for name, transformerPath := range plan.Spec.Transformers {
+		data, err := os.ReadFile(transformerPath)
+		if err != nil {
+			logrus.Debugf("Skipping the config of the transformer %s in the bug report. Error: %q", name, err)
+			continue
+		}
+		configs[name] = data
+	}
+	return configs
+}
+
+func getBugReportEnvironment() bugReportEnvironment {
+	env := bugReportEnvironment{
+		Version:   info.GetVersionInfo(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		EnvVars:   map[string]string{},
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if _, err := os.Stat("/var/run/docker.sock"); err == nil {
+		env.DockerSocketFound = true
+	}
+	for _, envVar := range bugReportEnvVars {
+		if value, ok := os.LookupEnv(envVar); ok {
+			env.EnvVars[envVar] = string(redactText([]byte(value)))
+		}
+	}
+	return env
+}
+
+// redactYaml redacts the values of the sensitive keys and the answers to password questions in a yaml document.
+// Documents that cannot be parsed are redacted as text.
+func redactYaml(data []byte) []byte {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return redactText(data)
+	}
+	redacted, err := yaml.Marshal(redactYamlValue(doc))
+	if err != nil {
+		return redactText(data)
+	}
+	return redacted
+}
+
+func redactYamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// the answers to password questions in the QA cache
+		if v["type"] == string(qatypes.PasswordSolutionFormType) {
+			if _, ok := v["answer"]; ok {
+				v["answer"] = redactedValue
+			}
+		}
+		for key, val := range v {
+			if _, isMap := val.(map[string]interface{}); !isMap && sensitiveKeyRegex.MatchString(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactYamlValue(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redactYamlValue(val)
+		}
+		return v
+	case string:
+		return string(redactText([]byte(v)))
+	}
+	return value
+}
+
+// redactText redacts sensitive key value pairs and the credentials in URLs
+func redactText(data []byte) []byte {
+	data = sensitiveTextRegex.ReplaceAll(data, []byte("${1}"+redactedValue))
+	return urlCredentialsRegex.ReplaceAll(data, []byte("${1}"+redactedValue+"@"))
+}
+
+func writeBugReportArchive(archivePath string, entries map[string][]byte) ([]string, error) {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the bug report archive at path %s . Error: %q", archivePath, err)
+	}
+	defer f.Close()
+	gzipWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzipWriter)
+	names := []string{}
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	now := time.Now()
+	for _, name := range names {
+		data := entries[name]
+		header := &tar.Header{Name: strings.TrimPrefix(name, "/"), Mode: int64(common.DefaultFilePermission), Size: int64(len(data)), ModTime: now}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to add %s to the bug report archive. Error: %q", name, err)
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to add %s to the bug report archive. Error: %q", name, err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write the bug report archive at path %s . Error: %q", archivePath, err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write the bug report archive at path %s . Error: %q", archivePath, err)
+	}
+	return names, nil
+}
@@ -0,0 +1,286 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment/container"
+	"github.com/konveyor/move2kube/types"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ImageBuildsFile is the file generated in the scripts directory that lists the images to build
+	ImageBuildsFile = "builddockerimages.yaml"
+	// ImageBuildReportFile is the report of the image builds written to the output directory
+	ImageBuildReportFile = "image-build-report.md"
+)
+
+type imageBuildStatus string
+
+const (
+	imageBuilt   imageBuildStatus = "built"
+	imagePushed  imageBuildStatus = "pushed"
+	imageFailed  imageBuildStatus = "failed"
+	imageSkipped imageBuildStatus = "skipped"
+)
+
+// imageBuildsT is the file listing the images that can be built from the transformed output
+type imageBuildsT struct {
+	types.TypeMeta `yaml:",inline"`
+	Spec           imageBuildsSpecT `yaml:"spec,omitempty"`
+}
+
+type imageBuildsSpecT struct {
+	Images []imageBuildT `yaml:"images"`
+}
+
+// imageBuildT is a single image, the context is relative to the output directory
+type imageBuildT struct {
+	Image      string `yaml:"image"`
+	Context    string `yaml:"context"`
+	Dockerfile string `yaml:"dockerfile"`
+}
+
+type imageBuildResultT struct {
+	Image    string
+	Status   imageBuildStatus
+	Duration time.Duration
+	Reason   string
+}
+
+// BuildImages builds the container images of the Dockerfiles in the transformed output and optionally pushes them.
+// Images are built after the images they depend on, and images that do not depend on each other are built in parallel.
+// The outcome of every image is written to a report in the output directory.
+func BuildImages(ctx context.Context, outputPath string, push bool, parallelism int) error {
+	buildsPath := filepath.Join(outputPath, common.ScriptsDir, ImageBuildsFile)
+	if _, err := os.Stat(buildsPath); os.IsNotExist(err) {
+		logrus.Infof("No container images to build.")
+		return nil
+	}
+	builds := imageBuildsT{}
+	if err := common.ReadMove2KubeYaml(buildsPath, &builds); err != nil {
+		return fmt.Errorf("failed to read the list of images to build from the file at path %s . Error: %q", buildsPath, err)
+	}
+	if len(builds.Spec.Images) == 0 {
+		logrus.Infof("No container images to build.")
+		return nil
+	}
+	engine, err := container.GetContainerEngine()
+	if err != nil {
+		return fmt.Errorf("building images needs a container engine. Error: %q", err)
+	}
+	registryPrefix := ""
+	if push {
+		registryPrefix = commonqa.ImageRegistry() + "/" + commonqa.ImageRegistryNamespace() + "/"
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	dependencies := map[string][]string{}
+	for _, image := range builds.Spec.Images {
+		dependencies[image.Image] = getImageDependencies(outputPath, image, builds.Spec.Images)
+	}
+	levels, cyclic := getImageBuildLevels(builds.Spec.Images, dependencies)
+	results := map[string]imageBuildResultT{}
+	for _, image := range cyclic {
+		results[image.Image] = imageBuildResultT{Image: image.Image, Status: imageFailed, Reason: "cyclic dependency between the images"}
+	}
+	resultsMutex := sync.Mutex{}
+	semaphore := make(chan struct{}, parallelism)
+	for _, level := range levels {
+		wg := sync.WaitGroup{}
+		for _, image := range level {
+			// images in the same level do not depend on each other, so the results of the earlier levels are complete
+			resultsMutex.Lock()
+			reason := getFailedDependency(dependencies[image.Image], results)
+			if reason == "" && ctx.Err() != nil {
+				reason = "the build was interrupted"
+			}
+			if reason != "" {
+				results[image.Image] = imageBuildResultT{Image: image.Image, Status: imageSkipped, Reason: reason}
+			}
+			resultsMutex.Unlock()
+			if reason != "" {
+				continue
+			}
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func(image imageBuildT) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				result := buildImage(ctx, engine, outputPath, image, registryPrefix)
+				resultsMutex.Lock()
+				results[image.Image] = result
+				resultsMutex.Unlock()
+			}(image)
+		}
+		wg.Wait()
+	}
+	reportPath := filepath.Join(outputPath, ImageBuildReportFile)
+	failed := writeImageBuildReport(reportPath, results)
+	if failed > 0 {
+		return fmt.Errorf("%d out of %d images could not be built or pushed. The details can be found in %s", failed, len(results), reportPath)
+	}
+	logrus.Infof("Built %d container images. The report can be found at %s", len(results), reportPath)
+	return nil
+}
+
+func buildImage(ctx context.Context, engine container.ContainerEngine, outputPath string, image imageBuildT, registryPrefix string) imageBuildResultT {
+	start := time.Now()
+	result := imageBuildResultT{Image: image.Image, Status: imageBuilt}
+	if err := engine.BuildImage(ctx, image.Image, filepath.Join(outputPath, image.Context), image.Dockerfile); err != nil {
+		logrus.Errorf("Failed to build the image %s . Error: %q", image.Image, err)
+		result.Status, result.Reason = imageFailed, err.Error()
+	} else if registryPrefix != "" {
+		pushImageName := registryPrefix + common.TagNewImageName(image.Image)
+		if err := engine.TagImage(image.Image, pushImageName); err != nil {
+			result.Status, result.Reason = imageFailed, err.Error()
+		} else if err := engine.PushImage(ctx, pushImageName); err != nil {
+			result.Status, result.Reason = imageFailed, err.Error()
+		} else {
+			result.Status, result.Reason = imagePushed, pushImageName
+		}
+		if result.Status == imageFailed {
+			logrus.Errorf("Failed to push the image %s . Error: %s", pushImageName, result.Reason)
+		}
+	}
+	result.Duration = time.Since(start).Round(time.Second)
+	return result
+}
+
+// getImageBuildLevels groups the images such that every image only depends on images in the earlier groups.
+// Images that are part of a dependency cycle are returned separately.
+func getImageBuildLevels(images []imageBuildT, dependencies map[string][]string) (levels [][]imageBuildT, cyclic []imageBuildT) {
+	remaining := map[string][]string{}
+	for _, image := range images {
+		remaining[image.Image] = dependencies[image.Image]
+	}
+	done := map[string]bool{}
+	for len(remaining) > 0 {
+		level := []imageBuildT{}
+		for _, image := range images {
+			dependencies, ok := remaining[image.Image]
+			if !ok {
+				continue
+			}
+			ready := true
+			for _, dependency := range dependencies {
+				if !done[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, image)
+			}
+		}
+		if len(level) == 0 {
+			for _, image := range images {
+				if _, ok := remaining[image.Image]; ok {
+					cyclic = append(cyclic, image)
+				}
+			}
+			break
+		}
+		for _, image := range level {
+			done[image.Image] = true
+			delete(remaining, image.Image)
+		}
+		levels = append(levels, level)
+	}
+	return levels, cyclic
+}
+
+// getImageDependencies returns the other images to be built that are used in the FROM instructions of the Dockerfile
+func getImageDependencies(outputPath string, image imageBuildT, images []imageBuildT) []string {
+	dockerfilePath := filepath.Join(outputPath, image.Context, image.Dockerfile)
+	dockerfile, err := os.Open(dockerfilePath)
+	if err != nil {
+		logrus.Debugf("Failed to open the Dockerfile at path %s . Error: %q", dockerfilePath, err)
+		return nil
+	}
+	defer dockerfile.Close()
+	dependencies := []string{}
+	scanner := bufio.NewScanner(dockerfile)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		baseImage := fields[1]
+		if strings.HasPrefix(baseImage, "--") && len(fields) > 2 {
+			baseImage = fields[2]
+		}
+		for _, other := range images {
+			if other.Image != image.Image && trimLatestTag(other.Image) == trimLatestTag(baseImage) {
+				dependencies = common.AppendIfNotPresent(dependencies, other.Image)
+			}
+		}
+	}
+	return dependencies
+}
+
+func trimLatestTag(image string) string {
+	return strings.TrimSuffix(image, ":latest")
+}
+
+func getFailedDependency(dependencies []string, results map[string]imageBuildResultT) string {
+	for _, dependency := range dependencies {
+		if result, ok := results[dependency]; ok && (result.Status == imageFailed || result.Status == imageSkipped) {
+			return fmt.Sprintf("the image %s it depends on was not built", dependency)
+		}
+	}
+	return ""
+}
+
+// writeImageBuildReport writes the report and returns the number of images that were not built
+func writeImageBuildReport(reportPath string, results map[string]imageBuildResultT) int {
+	images := []string{}
+	for image := range results {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	failed := 0
+	report := "# Container image builds\n\n"
+	if common.RunID != "" {
+		report += fmt.Sprintf("Run ID: %s\n\n", common.RunID)
+	}
+	report += "| Image | Status | Duration | Details |\n| --- | --- | --- | --- |\n"
+	for _, image := range images {
+		result := results[image]
+		if result.Status == imageFailed || result.Status == imageSkipped {
+			failed++
+		}
+		report += fmt.Sprintf("| %s | %s | %s | %s |\n", result.Image, result.Status, result.Duration, strings.ReplaceAll(result.Reason, "|", "\\|"))
+	}
+	if err := os.WriteFile(reportPath, []byte(report), common.DefaultFilePermission); err != nil {
+		logrus.Errorf("Failed to write the image build report to the file at path %s . Error: %q", reportPath, err)
+	}
+	return failed
+}
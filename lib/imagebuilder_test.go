@@ -0,0 +1,102 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestGetImageBuildLevels(t *testing.T) {
+	outputPath := t.TempDir()
+	dockerfiles := map[string]string{
+		"base/Dockerfile":   "FROM registry.access.redhat.com/ubi8/ubi:latest\n",
+		"api/Dockerfile":    "FROM base:latest AS builder\nRUN make\nFROM --platform=linux/amd64 base\n",
+		"web/Dockerfile":    "from base\n",
+		"worker/Dockerfile": "FROM api\n",
+		"a/Dockerfile":      "FROM b\n",
+		"b/Dockerfile":      "FROM a\n",
+	}
+	for path, contents := range dockerfiles {
+		path = filepath.Join(outputPath, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	images := []imageBuildT{}
+	for _, name := range []string{"worker", "api", "web", "base", "a", "b"} {
+		images = append(images, imageBuildT{Image: name, Context: name, Dockerfile: "Dockerfile"})
+	}
+	dependencies := map[string][]string{}
+	for _, image := range images {
+		dependencies[image.Image] = getImageDependencies(outputPath, image, images)
+	}
+	expectedDependencies := map[string][]string{"worker": {"api"}, "api": {"base"}, "web": {"base"}, "base": {}, "a": {"b"}, "b": {"a"}}
+	if diff := cmp.Diff(expectedDependencies, dependencies); diff != "" {
+		t.Fatalf("the dependencies between the images are incorrect. Difference:\n%s", diff)
+	}
+	levels, cyclic := getImageBuildLevels(images, dependencies)
+	actualLevels := [][]string{}
+	for _, level := range levels {
+		names := []string{}
+		for _, image := range level {
+			names = append(names, image.Image)
+		}
+		actualLevels = append(actualLevels, names)
+	}
+	if diff := cmp.Diff([][]string{{"base"}, {"api", "web"}, {"worker"}}, actualLevels); diff != "" {
+		t.Fatalf("the images are not built in the order of their dependencies. Difference:\n%s", diff)
+	}
+	if len(cyclic) != 2 || cyclic[0].Image != "a" || cyclic[1].Image != "b" {
+		t.Fatalf("expected the images a and b to be cyclic. Actual: %+v", cyclic)
+	}
+}
+
+func TestWriteImageBuildReport(t *testing.T) {
+	results := map[string]imageBuildResultT{
+		"api":    {Image: "api", Status: imagePushed, Reason: "quay.io/acme/api:v1"},
+		"base":   {Image: "base", Status: imageFailed, Reason: "exit status 1 | no space left"},
+		"worker": {Image: "worker", Status: imageSkipped, Reason: getFailedDependency([]string{"api", "base"}, map[string]imageBuildResultT{"base": {Status: imageFailed}})},
+		"web":    {Image: "web", Status: imageBuilt},
+	}
+	reportPath := filepath.Join(t.TempDir(), ImageBuildReportFile)
+	if failed := writeImageBuildReport(reportPath, results); failed != 2 {
+		t.Fatalf("expected the failed and the skipped images to be counted. Actual: %d", failed)
+	}
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read the report. Error: %q", err)
+	}
+	expectedRows := []string{
+		"| api | pushed | 0s | quay.io/acme/api:v1 |",
+		`| base | failed | 0s | exit status 1 \| no space left |`,
+		"| web | built | 0s |  |",
+		"| worker | skipped | 0s | the image base it depends on was not built |",
+	}
+	lines := strings.Split(strings.TrimSpace(string(report)), "\n")
+	if diff := cmp.Diff(expectedRows, lines[len(lines)-len(expectedRows):]); diff != "" {
+		t.Fatalf("the rows of the report are incorrect. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/hashicorp/go-version"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	gitSHATagLength = 7
+	dateTagLayout   = "20060102-150405"
+)
+
+var disallowedImageTagCharactersRegex = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// getImageTag asks for the strategy used to tag the new images and returns the tag, using the git metadata of the source directory.
+// It returns an empty string for the latest tag.
+func getImageTag(sourceDir string) string {
+	strategy := qaengine.FetchSelectAnswer(
+		common.ConfigImageTagStrategyKey,
+		"Select the strategy used to tag the new images :",
+		[]string{
+			"git-sha uses the short hash of the current commit of the source repo, semver-from-tag the highest semantic version among its git tags and date the date of the commit.",
+			"The tag is used in the build and push scripts, the CI pipelines, the Helm values and the manifests.",
+		},
+		common.ImageTagStrategyLatest,
+		common.ImageTagStrategies,
+	)
+	if strategy == common.ImageTagStrategyLatest {
+		return ""
+	}
+	tag, err := getImageTagFromGit(sourceDir, strategy)
+	if err != nil {
+		logrus.Warnf("The new images are tagged as latest, since the %s tag could not be found for the source directory %s . Error: %q", strategy, sourceDir, err)
+		return ""
+	}
+	tag = disallowedImageTagCharactersRegex.ReplaceAllLiteralString(tag, "-")
+	logrus.Infof("The new images are tagged as %s", tag)
+	return tag
+}
+
+func getImageTagFromGit(sourceDir, strategy string) (string, error) {
+	repo, err := git.PlainOpenWithOptions(sourceDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to open the directory as a git repo. Error: %q", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the current commit. Error: %q", err)
+	}
+	switch strategy {
+	case common.ImageTagStrategyGitSHA:
+		return head.Hash().String()[:gitSHATagLength], nil
+	case common.ImageTagStrategyDate:
+		commit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return "", fmt.Errorf("failed to get the commit %s . Error: %q", head.Hash(), err)
+		}
+		return commit.Committer.When.UTC().Format(dateTagLayout), nil
+	case common.ImageTagStrategySemverFromTag:
+		return getSemverTag(repo, head.Hash())
+	}
+	return "", fmt.Errorf("the strategy %s is not supported. Supported strategies are %+v", strategy, common.ImageTagStrategies)
+}
+
+// getSemverTag returns the highest semantic version among the git tags of the commit, like v1.2.3
+func getSemverTag(repo *git.Repository, commitHash plumbing.Hash) (string, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return "", fmt.Errorf("failed to list the git tags. Error: %q", err)
+	}
+	var highest *version.Version
+	highestTag := ""
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		// annotated tags point to a tag object instead of the commit
+		if tagObject, err := repo.TagObject(hash); err == nil {
+			hash = tagObject.Target
+		}
+		if hash != commitHash {
+			return nil
+		}
+		v, err := version.NewSemver(ref.Name().Short())
+		if err != nil {
+			logrus.Debugf("the git tag %s is not a semantic version. Error: %q", ref.Name().Short(), err)
+			return nil
+		}
+		if highest == nil || v.GreaterThan(highest) {
+			highest, highestTag = v, ref.Name().Short()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list the git tags. Error: %q", err)
+	}
+	if highestTag == "" {
+		return "", fmt.Errorf("the commit %s does not have a git tag that is a semantic version", commitHash)
+	}
+	return highestTag, nil
+}
@@ -0,0 +1,204 @@
+/*
+ *  Copyright IBM Corporation 2020, 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sirupsen/logrus"
+)
+
+// OutputHashesKind is the kind of the file that stores the hashes of the generated files
+const OutputHashesKind types.Kind = "OutputHashes"
+
+// OutputDriftStatus is the kind of difference between a generated file and the file in the output directory
+type OutputDriftStatus string
+
+const (
+	// OutputDriftAdded means that the file would be generated now, but it is not in the output directory
+	OutputDriftAdded OutputDriftStatus = "added"
+	// OutputDriftObsolete means that the file was generated earlier, but it would not be generated now
+	OutputDriftObsolete OutputDriftStatus = "obsolete"
+	// OutputDriftChanged means that the file would be generated differently now and it was not edited, so it is safe to regenerate it
+	OutputDriftChanged OutputDriftStatus = "changed"
+	// OutputDriftConflict means that the file would be generated differently now and it was edited
+	OutputDriftConflict OutputDriftStatus = "conflict"
+	// OutputDriftEdited means that the file was edited, but it would be generated the same as before
+	OutputDriftEdited OutputDriftStatus = "edited"
+	// OutputDriftDeleted means that the file was deleted, but it would be generated the same as before
+	OutputDriftDeleted OutputDriftStatus = "deleted"
+)
+
+// outputHashes stores the hashes of the generated files
+type outputHashes struct {
+	types.TypeMeta `yaml:",inline"`
+	Files          map[string]string `yaml:"files"` // [relative path]sha256
+}
+
+// OutputFileDrift is the difference between a generated file and the file in the output directory
+type OutputFileDrift struct {
+	Path   string
+	Status OutputDriftStatus
+	// Diff is the unified diff between the file in the output directory and the generated file
+	Diff string
+}
+
+// IsDrift returns true if regenerating the output would change the file
+func (d OutputFileDrift) IsDrift() bool {
+	return d.Status != OutputDriftEdited && d.Status != OutputDriftDeleted
+}
+
+// RecordOutputHashes stores the hashes of the files in the output directory, so that later the edits made by the user can be told apart from the changes in the generated files
+func RecordOutputHashes(outputPath string) error {
+	hashes, err := getOutputFileHashes(outputPath)
+	if err != nil {
+		return err
+	}
+	hashesPath := filepath.Join(outputPath, common.OutputHashesFile)
+	if err := common.WriteYaml(hashesPath, outputHashes{
+		TypeMeta: types.TypeMeta{Kind: string(OutputHashesKind), APIVersion: types.SchemeGroupVersion.String()},
+		Files:    hashes,
+	}); err != nil {
+		return fmt.Errorf("failed to write the hashes of the generated files to %s . Error: %q", hashesPath, err)
+	}
+	return nil
+}
+
+// GetOutputDrift compares the files that were generated now with the files in the output directory.
+// The hashes recorded when the output was generated are used to find the files edited by the user. Files that were not generated are ignored.
+// It also returns false if the output directory does not have recorded hashes, in which case all the differences are reported as changes.
+func GetOutputDrift(generatedPath, outputPath string, withDiffs bool) ([]OutputFileDrift, bool, error) {
+	generated, err := getOutputFileHashes(generatedPath)
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := getOutputFileHashes(outputPath)
+	if err != nil {
+		return nil, false, err
+	}
+	recorded := outputHashes{}
+	hasRecordedHashes := true
+	if err := common.ReadYaml(filepath.Join(outputPath, common.OutputHashesFile), &recorded); err != nil {
+		logrus.Debugf("failed to read the hashes of the generated files in %s . Error: %q", outputPath, err)
+		hasRecordedHashes = false
+	}
+	paths := []string{}
+	for path := range generated {
+		paths = append(paths, path)
+	}
+	for path := range existing {
+		if _, ok := generated[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	drifts := []OutputFileDrift{}
+	for _, path := range paths {
+		generatedHash, isGenerated := generated[path]
+		existingHash, exists := existing[path]
+		recordedHash, isRecorded := recorded.Files[path]
+		status := OutputDriftStatus("")
+		switch {
+		case isGenerated && !exists:
+			status = OutputDriftAdded
+			if isRecorded {
+				status = OutputDriftConflict
+				if generatedHash == recordedHash {
+					status = OutputDriftDeleted
+				}
+			}
+		case !isGenerated && exists:
+			if isRecorded || !hasRecordedHashes {
+				status = OutputDriftObsolete
+			}
+		case generatedHash == existingHash:
+		case !isRecorded || existingHash == recordedHash:
+			status = OutputDriftChanged
+		case generatedHash == recordedHash:
+			status = OutputDriftEdited
+		default:
+			status = OutputDriftConflict
+		}
+		if status == "" {
+			continue
+		}
+		drift := OutputFileDrift{Path: path, Status: status}
+		if withDiffs && drift.IsDrift() {
+			drift.Diff = getFileDiff(filepath.Join(outputPath, path), filepath.Join(generatedPath, path), "current", "generated", path)
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, hasRecordedHashes, nil
+}
+
+// getOutputFileHashes returns the hashes of the files in the output directory.
+// The hashes file, the manifest, its signature and the logs are skipped.
+func getOutputFileHashes(outputPath string) (map[string]string, error) {
+	hashes := map[string]string{}
+	err := filepath.WalkDir(outputPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(outputPath, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if relPath == common.RunLogsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if relPath == common.OutputHashesFile || relPath == common.OutputManifestFile || relPath == common.OutputManifestSignatureFile {
+			return nil
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		hashes[filepath.ToSlash(relPath)] = common.GetSHA256Hash(string(contents))
+		return nil
+	})
+	if err != nil {
+		return hashes, fmt.Errorf("failed to compute the hashes of the files in the directory %s . Error: %q", outputPath, err)
+	}
+	return hashes, nil
+}
+
+// getFileDiff returns the unified diff between two files, labelled using the directories. A missing file is treated as empty.
+func getFileDiff(fromPath, toPath, fromDir, toDir, name string) string {
+	from, _ := os.ReadFile(fromPath)
+	to, _ := os.ReadFile(toPath)
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(from)),
+		B:        difflib.SplitLines(string(to)),
+		FromFile: fromDir + "/" + name,
+		ToFile:   toDir + "/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		logrus.Debugf("failed to compute the diff of the file %s . Error: %q", name, err)
+	}
+	return diff
+}
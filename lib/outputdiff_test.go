@@ -0,0 +1,131 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+func writeOutputFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, contents := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+}
+
+func TestGetOutputDrift(t *testing.T) {
+	outputPath := t.TempDir()
+	writeOutputFiles(t, outputPath, map[string]string{
+		"same.yaml":      "a: 1\n",
+		"changed.yaml":   "a: 1\n",
+		"edited.yaml":    "a: 1\n",
+		"conflict.yaml":  "a: 1\n",
+		"deleted.yaml":   "a: 1\n",
+		"removed.yaml":   "a: 1\n",
+		"obsolete.yaml":  "a: 1\n",
+		"logs/run-1.log": "level=info\n",
+	})
+	if err := RecordOutputHashes(outputPath); err != nil {
+		t.Fatalf("failed to record the hashes of the output. Error: %q", err)
+	}
+	// the edits made by the user after the output was generated
+	writeOutputFiles(t, outputPath, map[string]string{
+		"edited.yaml":   "a: 1\nb: 2\n",
+		"conflict.yaml": "a: 1\nb: 2\n",
+		"notes.txt":     "my notes\n",
+	})
+	for _, path := range []string{"deleted.yaml", "removed.yaml"} {
+		if err := os.Remove(filepath.Join(outputPath, path)); err != nil {
+			t.Fatalf("failed to remove the file %s . Error: %q", path, err)
+		}
+	}
+	generatedPath := t.TempDir()
+	writeOutputFiles(t, generatedPath, map[string]string{
+		"same.yaml":     "a: 1\n",
+		"changed.yaml":  "a: 2\n",
+		"edited.yaml":   "a: 1\n",
+		"conflict.yaml": "a: 2\n",
+		"deleted.yaml":  "a: 1\n",
+		"removed.yaml":  "a: 2\n",
+		"added.yaml":    "a: 1\n",
+	})
+
+	drifts, hasRecordedHashes, err := GetOutputDrift(generatedPath, outputPath, true)
+	if err != nil {
+		t.Fatalf("failed to get the drift of the output. Error: %q", err)
+	}
+	if !hasRecordedHashes {
+		t.Fatalf("expected the output to have recorded hashes")
+	}
+	expected := map[string]OutputDriftStatus{
+		"added.yaml":    OutputDriftAdded,
+		"changed.yaml":  OutputDriftChanged,
+		"conflict.yaml": OutputDriftConflict,
+		"deleted.yaml":  OutputDriftDeleted,
+		"edited.yaml":   OutputDriftEdited,
+		"obsolete.yaml": OutputDriftObsolete,
+		"removed.yaml":  OutputDriftConflict,
+	}
+	actual := map[string]OutputDriftStatus{}
+	for _, drift := range drifts {
+		actual[drift.Path] = drift.Status
+		if drift.IsDrift() != (drift.Diff != "") {
+			t.Fatalf("expected a diff only for the files that would change. Actual: %+v", drift)
+		}
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("wrong drift of the output. Difference:\n%s", diff)
+	}
+	for _, drift := range drifts {
+		if drift.Path == "changed.yaml" && !strings.Contains(drift.Diff, "-a: 1\n+a: 2\n") {
+			t.Fatalf("wrong diff for the changed file. Actual:\n%s", drift.Diff)
+		}
+	}
+}
+
+func TestGetOutputDriftWithoutRecordedHashes(t *testing.T) {
+	outputPath := t.TempDir()
+	writeOutputFiles(t, outputPath, map[string]string{"edited.yaml": "a: 1\nb: 2\n", "old.yaml": "a: 1\n"})
+	generatedPath := t.TempDir()
+	writeOutputFiles(t, generatedPath, map[string]string{"edited.yaml": "a: 1\n"})
+	drifts, hasRecordedHashes, err := GetOutputDrift(generatedPath, outputPath, false)
+	if err != nil {
+		t.Fatalf("failed to get the drift of the output. Error: %q", err)
+	}
+	if hasRecordedHashes {
+		t.Fatalf("expected the output to have no recorded hashes")
+	}
+	expected := []OutputFileDrift{
+		{Path: "edited.yaml", Status: OutputDriftChanged},
+		{Path: "old.yaml", Status: OutputDriftObsolete},
+	}
+	if diff := cmp.Diff(expected, drifts); diff != "" {
+		t.Fatalf("wrong drift of the output. Difference:\n%s", diff)
+	}
+}
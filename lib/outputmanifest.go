@@ -0,0 +1,82 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+// cosignCommand is the command used to sign the output manifest
+const cosignCommand = "cosign"
+
+// WriteOutputManifest writes the sha256 hashes of the files in the output directory to the manifest file, in the format of sha256sum,
+// so that the output can be checked using `sha256sum -c` before it is reviewed or deployed. The logs of the runs are not listed.
+func WriteOutputManifest(outputPath string) error {
+	hashes, err := getOutputFileHashes(outputPath)
+	if err != nil {
+		return err
+	}
+	// the hashes file is generated along with the output, unlike the manifest and its signature
+	if contents, err := os.ReadFile(filepath.Join(outputPath, common.OutputHashesFile)); err == nil {
+		hashes[common.OutputHashesFile] = common.GetSHA256Hash(string(contents))
+	}
+	paths := []string{}
+	for path := range hashes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	manifest := strings.Builder{}
+	for _, path := range paths {
+		manifest.WriteString(hashes[path] + "  " + path + "\n")
+	}
+	manifestPath := filepath.Join(outputPath, common.OutputManifestFile)
+	if err := os.WriteFile(manifestPath, []byte(manifest.String()), common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the manifest of the output to %s . Error: %q", manifestPath, err)
+	}
+	// a signature of an earlier run does not match the new manifest
+	if err := os.Remove(filepath.Join(outputPath, common.OutputManifestSignatureFile)); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove the old signature of the manifest of the output. Error: %q", err)
+	}
+	return nil
+}
+
+// SignOutputManifest signs the manifest file of the output directory using cosign and the key, and writes the signature next to it.
+// The key can be anything supported by cosign, like the path to a key file or a KMS URI. The password of a key file is taken from the COSIGN_PASSWORD environment variable.
+func SignOutputManifest(ctx context.Context, outputPath, key string) error {
+	if _, err := exec.LookPath(cosignCommand); err != nil {
+		return fmt.Errorf("the command %s was not found. Error: %q", cosignCommand, err)
+	}
+	manifestPath := filepath.Join(outputPath, common.OutputManifestFile)
+	signaturePath := filepath.Join(outputPath, common.OutputManifestSignatureFile)
+	args := []string{"sign-blob", "--yes", "--key", key, "--output-signature", signaturePath, manifestPath}
+	logrus.Debugf("running %s %s", cosignCommand, strings.Join(args, " "))
+	output, err := exec.CommandContext(ctx, cosignCommand, args...).CombinedOutput()
+	logrus.Debugf("%s", output)
+	if err != nil {
+		return fmt.Errorf("%s sign-blob failed with the output %s . Error: %q", cosignCommand, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
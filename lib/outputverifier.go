@@ -0,0 +1,393 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// OutputAssertionsKind is the kind of the files in the verify directory that have the assertions about the output
+	OutputAssertionsKind types.Kind = "OutputAssertions"
+	// VerifyExpectedDir is the directory in the verify directory with the files that have to be generated exactly the same
+	VerifyExpectedDir     = "expected"
+	defaultAssertionFiles = "*.yaml"
+)
+
+// outputAssertions is a file with assertions about the transformed output
+type outputAssertions struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             outputAssertionsSpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+type outputAssertionsSpec struct {
+	Assertions []OutputAssertion `yaml:"assertions" json:"assertions"`
+}
+
+// OutputAssertion is an assertion about the files or the kubernetes resources in the transformed output.
+// The resources are selected using the kind and the name, and the files using the files glob.
+// If none of them are set, the assertion is about all the yaml files.
+type OutputAssertion struct {
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Files is a glob matched against the paths relative to the output directory, or against the file names if it does not have a separator
+	Files string `yaml:"files,omitempty" json:"files,omitempty"`
+	Kind  string `yaml:"kind,omitempty" json:"kind,omitempty"`
+	Name  string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Field is the path of a field in the resources, like spec.rules[0].host . The index * matches all the elements of a list.
+	Field string `yaml:"field,omitempty" json:"field,omitempty"`
+	// Exists is whether there is any matching resource or file, true by default
+	Exists *bool `yaml:"exists,omitempty" json:"exists,omitempty"`
+	// Count is the number of matching resources or files
+	Count    *int    `yaml:"count,omitempty" json:"count,omitempty"`
+	Equals   *string `yaml:"equals,omitempty" json:"equals,omitempty"`
+	Matches  string  `yaml:"matches,omitempty" json:"matches,omitempty"`
+	Contains string  `yaml:"contains,omitempty" json:"contains,omitempty"`
+}
+
+// OutputVerificationResult is the result of an assertion or of a comparison with an expected file
+type OutputVerificationResult struct {
+	Name   string
+	Passed bool
+	// Reason is why the assertion failed, or the unified diff with the expected file
+	Reason string
+}
+
+// VerifyOutput checks the transformed output against the files in the expected directory and the assertions in the yaml files of the verify directory
+func VerifyOutput(outputPath, verifyPath string) ([]OutputVerificationResult, error) {
+	results, err := verifyExpectedFiles(outputPath, filepath.Join(verifyPath, VerifyExpectedDir))
+	if err != nil {
+		return results, err
+	}
+	assertionsPaths, err := common.GetYamlsWithTypeMeta(verifyPath, string(OutputAssertionsKind))
+	if err != nil {
+		return results, fmt.Errorf("failed to find the assertions in the directory %s . Error: %q", verifyPath, err)
+	}
+	sort.Strings(assertionsPaths)
+	for _, assertionsPath := range assertionsPaths {
+		if common.IsParent(assertionsPath, filepath.Join(verifyPath, VerifyExpectedDir)) {
+			continue
+		}
+		assertions := outputAssertions{}
+		if err := common.ReadMove2KubeYamlStrict(assertionsPath, &assertions, string(OutputAssertionsKind)); err != nil {
+			return results, fmt.Errorf("failed to read the assertions in the file at path %s . Error: %q", assertionsPath, err)
+		}
+		relPath, err := filepath.Rel(verifyPath, assertionsPath)
+		if err != nil {
+			relPath = assertionsPath
+		}
+		for i, assertion := range assertions.Spec.Assertions {
+			name := assertion.Description
+			if name == "" {
+				name = fmt.Sprintf("%s assertion %d", relPath, i+1)
+			}
+			reason, err := checkOutputAssertion(outputPath, assertion)
+			if err != nil {
+				return results, fmt.Errorf("the assertion %q in the file at path %s is invalid. Error: %q", name, assertionsPath, err)
+			}
+			results = append(results, OutputVerificationResult{Name: name, Passed: reason == "", Reason: reason})
+		}
+	}
+	return results, nil
+}
+
+// UpdateExpectedFiles copies the files in the output that are in the expected directory over them, so that they match the output again
+func UpdateExpectedFiles(outputPath, verifyPath string) (int, error) {
+	expectedPath := filepath.Join(verifyPath, VerifyExpectedDir)
+	updated := 0
+	err := walkFiles(expectedPath, func(relPath string) error {
+		generated, err := os.ReadFile(filepath.Join(outputPath, relPath))
+		if err != nil {
+			logrus.Warnf("The expected file %s is not in the output. Remove it if it is not generated anymore.", relPath)
+			return nil
+		}
+		expectedFilePath := filepath.Join(expectedPath, relPath)
+		if expected, err := os.ReadFile(expectedFilePath); err == nil && string(expected) == string(generated) {
+			return nil
+		}
+		if err := os.WriteFile(expectedFilePath, generated, common.DefaultFilePermission); err != nil {
+			return fmt.Errorf("failed to update the expected file at path %s . Error: %q", expectedFilePath, err)
+		}
+		updated++
+		return nil
+	})
+	return updated, err
+}
+
+// verifyExpectedFiles compares every file in the expected directory with the file at the same path in the output
+func verifyExpectedFiles(outputPath, expectedPath string) ([]OutputVerificationResult, error) {
+	if _, err := os.Stat(expectedPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	results := []OutputVerificationResult{}
+	err := walkFiles(expectedPath, func(relPath string) error {
+		result := OutputVerificationResult{Name: "expected " + relPath, Passed: true}
+		expected, err := os.ReadFile(filepath.Join(expectedPath, relPath))
+		if err != nil {
+			return err
+		}
+		generated, err := os.ReadFile(filepath.Join(outputPath, relPath))
+		if err != nil {
+			result.Passed, result.Reason = false, "the file was not generated"
+		} else if string(generated) != string(expected) {
+			result.Passed, result.Reason = false, getFileDiff(filepath.Join(expectedPath, relPath), filepath.Join(outputPath, relPath), VerifyExpectedDir, "generated", relPath)
+		}
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return results, fmt.Errorf("failed to compare the expected files in the directory %s with the output. Error: %q", expectedPath, err)
+	}
+	return results, nil
+}
+
+// checkOutputAssertion returns why the assertion failed, or an empty string if it passed
+func checkOutputAssertion(outputPath string, assertion OutputAssertion) (string, error) {
+	var matchesRegex *regexp.Regexp
+	if assertion.Matches != "" {
+		var err error
+		if matchesRegex, err = regexp.Compile(assertion.Matches); err != nil {
+			return "", fmt.Errorf("the regex %s is invalid. Error: %q", assertion.Matches, err)
+		}
+	}
+	filesGlob := assertion.Files
+	if filesGlob == "" {
+		filesGlob = defaultAssertionFiles
+	}
+	files := []string{}
+	err := walkFiles(outputPath, func(relPath string) error {
+		pattern, name := filesGlob, relPath
+		if !strings.Contains(pattern, "/") {
+			name = filepath.Base(relPath)
+		}
+		matched, err := filepath.Match(pattern, name)
+		if err != nil {
+			return fmt.Errorf("the glob %s is invalid. Error: %q", filesGlob, err)
+		}
+		if matched || (assertion.Files == "" && filepath.Ext(relPath) == ".yml") {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	what := "files matching " + filesGlob
+	values := []string{}
+	matchCount := len(files)
+	if assertion.Kind != "" || assertion.Name != "" || assertion.Field != "" {
+		what = getAssertionResourcesDescription(assertion)
+		matchCount = 0
+		for _, relPath := range files {
+			for _, resource := range readResources(filepath.Join(outputPath, relPath)) {
+				if !isAssertionResource(resource, assertion) {
+					continue
+				}
+				if assertion.Field == "" {
+					matchCount++
+					continue
+				}
+				fieldValues := getFieldValues(resource, strings.Split(assertion.Field, "."))
+				if len(fieldValues) > 0 {
+					matchCount++
+				}
+				values = append(values, fieldValues...)
+			}
+		}
+	} else {
+		for _, relPath := range files {
+			contents, err := os.ReadFile(filepath.Join(outputPath, relPath))
+			if err == nil {
+				values = append(values, string(contents))
+			}
+		}
+	}
+	if assertion.Count != nil && matchCount != *assertion.Count {
+		return fmt.Sprintf("expected %d %s, found %d", *assertion.Count, what, matchCount), nil
+	}
+	if assertion.Exists != nil && !*assertion.Exists {
+		if matchCount > 0 {
+			return fmt.Sprintf("expected no %s, found %d", what, matchCount), nil
+		}
+		return "", nil
+	}
+	if matchCount == 0 && (assertion.Count == nil || *assertion.Count != 0) {
+		return "found no " + what, nil
+	}
+	if assertion.Equals == nil && matchesRegex == nil && assertion.Contains == "" {
+		return "", nil
+	}
+	for _, value := range values {
+		if (assertion.Equals == nil || value == *assertion.Equals) &&
+			(matchesRegex == nil || matchesRegex.MatchString(value)) &&
+			(assertion.Contains == "" || strings.Contains(value, assertion.Contains)) {
+			return "", nil
+		}
+	}
+	expectations := []string{}
+	if assertion.Equals != nil {
+		expectations = append(expectations, "equal to "+strconv.Quote(*assertion.Equals))
+	}
+	if matchesRegex != nil {
+		expectations = append(expectations, "matching "+assertion.Matches)
+	}
+	if assertion.Contains != "" {
+		expectations = append(expectations, "containing "+strconv.Quote(assertion.Contains))
+	}
+	if assertion.Field != "" {
+		return fmt.Sprintf("none of the values of %s in %s are %s. Found %+v", assertion.Field, what, strings.Join(expectations, " and "), values), nil
+	}
+	return fmt.Sprintf("none of the %s are %s", what, strings.Join(expectations, " and ")), nil
+}
+
+func getAssertionResourcesDescription(assertion OutputAssertion) string {
+	what := "resources"
+	if assertion.Kind != "" {
+		what = assertion.Kind + " resources"
+	}
+	if assertion.Name != "" {
+		what += " named " + assertion.Name
+	}
+	if assertion.Field != "" {
+		what += " with the field " + assertion.Field
+	}
+	if assertion.Files != "" {
+		what += " in files matching " + assertion.Files
+	}
+	return what
+}
+
+func isAssertionResource(resource map[string]interface{}, assertion OutputAssertion) bool {
+	if kind, _ := resource["kind"].(string); assertion.Kind != "" && !strings.EqualFold(kind, assertion.Kind) {
+		return false
+	}
+	if assertion.Name != "" {
+		metadata, _ := resource["metadata"].(map[string]interface{})
+		if name, _ := metadata["name"].(string); name != assertion.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// readResources returns the yaml documents in the file that have a kind
+func readResources(path string) []map[string]interface{} {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	docs, err := common.SplitYAML(contents)
+	if err != nil {
+		logrus.Debugf("failed to split the yaml file at path %s . Error: %q", path, err)
+	}
+	resources := []map[string]interface{}{}
+	for _, doc := range docs {
+		resource := map[string]interface{}{}
+		if err := yaml.Unmarshal(doc, &resource); err != nil {
+			continue
+		}
+		if _, ok := resource["kind"]; ok {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// getFieldValues returns the values of the field at the path, as strings. Maps and lists are returned as yaml.
+func getFieldValues(value interface{}, path []string) []string {
+	if len(path) == 0 {
+		switch value := value.(type) {
+		case map[string]interface{}, []interface{}:
+			contents, err := yaml.Marshal(value)
+			if err != nil {
+				return nil
+			}
+			return []string{strings.TrimSpace(string(contents))}
+		default:
+			return []string{fmt.Sprint(value)}
+		}
+	}
+	key, indices := path[0], []string{}
+	if idx := strings.Index(key, "["); idx >= 0 && strings.HasSuffix(key, "]") {
+		indices = strings.Split(strings.TrimSuffix(key[idx+1:], "]"), "][")
+		key = key[:idx]
+	}
+	if key != "" {
+		mapValue, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = mapValue[key]; !ok {
+			return nil
+		}
+	}
+	return getIndexedFieldValues(value, indices, path[1:])
+}
+
+func getIndexedFieldValues(value interface{}, indices []string, path []string) []string {
+	if len(indices) == 0 {
+		return getFieldValues(value, path)
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	if indices[0] == "*" {
+		values := []string{}
+		for _, element := range list {
+			values = append(values, getIndexedFieldValues(element, indices[1:], path)...)
+		}
+		return values
+	}
+	index, err := strconv.Atoi(indices[0])
+	if err != nil || index < 0 || index >= len(list) {
+		return nil
+	}
+	return getIndexedFieldValues(list[index], indices[1:], path)
+}
+
+// walkFiles calls the function with the path relative to the directory of every file in it, skipping the logs
+func walkFiles(dir string, fn func(relPath string) error) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if relPath == common.RunLogsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(filepath.ToSlash(relPath))
+	})
+}
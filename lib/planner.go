@@ -47,7 +47,7 @@ func CreatePlan(ctx context.Context, inputPath, outputPath string, customization
 	if err != nil {
 		logrus.Errorf("Unable to convert label selector to selector : %s", err)
 	}
-	transformer.Init(common.AssetsPath, inputPath, lblSelector, outputPath, p.Name)
+	transformer.Init(ctx, common.AssetsPath, inputPath, lblSelector, outputPath, p.Name)
 	ts := transformer.GetInitializedTransformers()
 	for _, t := range ts {
 		config, _ := t.GetConfig()
@@ -56,11 +56,19 @@ func CreatePlan(ctx context.Context, inputPath, outputPath string, customization
 	logrus.Infoln("Configuration loading done")
 
 	logrus.Infoln("Start planning")
-	p.Spec.Services, err = transformer.GetServices(p.Name, inputPath)
+	p.Spec.Services, err = transformer.GetServices(ctx, p.Name, inputPath)
+	if ctx.Err() != nil {
+		logrus.Fatalf("The planning was interrupted. The plan was not written. Error: %q", ctx.Err())
+	}
 	if err != nil {
 		logrus.Errorf("Unable to create plan : %s", err)
 	}
 	logrus.Infoln("Planning done")
 	logrus.Infof("No of services identified : %d", len(p.Spec.Services))
+	for _, options := range p.Spec.Services {
+		if len(options) > 0 {
+			recordSourcePlatform(options[0].TransformerName)
+		}
+	}
 	return p
 }
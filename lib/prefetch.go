@@ -0,0 +1,114 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/konveyor/move2kube/environment/container"
+	"github.com/konveyor/move2kube/transformer"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+)
+
+// GetTransformerImages returns the container images that the transformers of the plan need to run
+func GetTransformerImages(plan plantypes.Plan, transformerSelector string) []string {
+	return transformer.GetContainerImages(plan.Spec.Transformers, getPlanTransformerSelector(plan, transformerSelector))
+}
+
+// GetTransformerImagePlatforms returns the platforms that the transformers of the plan need for their container images
+func GetTransformerImagePlatforms(plan plantypes.Plan, transformerSelector string) map[string]string {
+	return transformer.GetContainerImagePlatforms(plan.Spec.Transformers, getPlanTransformerSelector(plan, transformerSelector))
+}
+
+// PrefetchImages pulls the container images that the transformers of the plan need, so that the transform does not stall on slow pulls.
+// The images are pulled in parallel.
+func PrefetchImages(ctx context.Context, plan plantypes.Plan, transformerSelector string, parallelism int) error {
+	images := GetTransformerImages(plan, transformerSelector)
+	if len(images) == 0 {
+		logrus.Infof("The transformers do not need any container images.")
+		return nil
+	}
+	engine, err := container.GetContainerEngine()
+	if err != nil {
+		return fmt.Errorf("pulling images needs a container engine. Error: %q", err)
+	}
+	platforms := GetTransformerImagePlatforms(plan, transformerSelector)
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	failed := []string{}
+	failedMutex := sync.Mutex{}
+	semaphore := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+	for _, image := range images {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(image string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if err := engine.PullImage(ctx, image, environmenttypes.PullAlways, platforms[image]); err != nil {
+				logrus.Errorf("Failed to pull the image %s . Error: %q", image, err)
+				failedMutex.Lock()
+				failed = append(failed, image)
+				failedMutex.Unlock()
+			}
+		}(image)
+	}
+	wg.Wait()
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to pull %d out of %d images: %+v", len(failed), len(images), failed)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("the prefetch was interrupted. Error: %q", ctx.Err())
+	}
+	logrus.Infof("Pulled the %d container images needed by the transformers", len(images))
+	return nil
+}
+
+// GetOfflineViolations returns the reasons the transform would need to access the network, which is not allowed in offline mode
+func GetOfflineViolations(plan plantypes.Plan, transformerSelector string, pushImages bool) []string {
+	violations := []string{}
+	if pushImages {
+		violations = append(violations, "pushing the container images needs access to the registry")
+	}
+	images := GetTransformerImages(plan, transformerSelector)
+	if len(images) == 0 {
+		return violations
+	}
+	engine, err := container.GetContainerEngine()
+	if err != nil {
+		// the transformers that need the images are disabled without a container engine
+		return violations
+	}
+	platforms := GetTransformerImagePlatforms(plan, transformerSelector)
+	for _, image := range images {
+		inspectOutput, err := engine.InspectImage(image)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("the container image %s is not available locally and has to be pulled", image))
+		} else if !container.ImageMatchesPlatform(inspectOutput, platforms[image]) {
+			violations = append(violations, fmt.Sprintf("the local container image %s is not for the platform %s and has to be pulled", image, platforms[image]))
+		}
+	}
+	return violations
+}
@@ -18,14 +18,35 @@ package lib
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/eol"
+	"github.com/konveyor/move2kube/common/telemetry"
+	"github.com/konveyor/move2kube/environment/container"
 	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/qaengine/questionreceivers"
 	"github.com/konveyor/move2kube/transformer"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
 	plantypes "github.com/konveyor/move2kube/types/plan"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// RunReportFile is the report of the run written to the output directory when the run has a budget
+	RunReportFile = "run-report.md"
+	// RuntimeEOLReportFile lists the runtimes of the services that reached or are nearing their end of life
+	RuntimeEOLReportFile = "runtime-eol-report.md"
+	// ServiceDiscoveryReportFile lists the references to other services that were found and whether they were rewritten
+	ServiceDiscoveryReportFile = "service-discovery-report.md"
 )
 
 // Transform transforms the artifacts and writes output
@@ -36,20 +57,18 @@ func Transform(ctx context.Context, plan plantypes.Plan, outputPath string, tran
 	common.ProjectName = plan.Name
 	logrus.Debugf("Temp Dir : %s", common.TempPath)
 
-	transformerSelectorObj, err := common.ConvertStringSelectorsToSelectors(transformerSelector)
-	if err != nil {
-		logrus.Errorf("Unable to parse the transformer selector string : %s", err)
+	transformerSelectorObj := getPlanTransformerSelector(plan, transformerSelector)
+	transformer.InitTransformers(ctx, plan.Spec.Transformers, transformerSelectorObj, plan.Spec.SourceDir, outputPath, plan.Name, true)
+	if common.DevMode {
+		transformer.EnableDevReload(plan.Spec.CustomizationsDir)
 	}
-	selectorsInPlan, err := metav1.LabelSelectorAsSelector(&plan.Spec.TransformerSelector)
-	if err != nil {
-		logrus.Errorf("Unable to convert label selector to selector : %s", err)
-	} else {
-		requirements, _ := selectorsInPlan.Requirements()
-		transformerSelectorObj = transformerSelectorObj.Add(requirements...)
+	if err := transformer.RunPreTransformHooks(plan.Spec.SourceDir, outputPath); err != nil {
+		logrus.Fatalf("Failed to run the hooks before the transform. Error: %q", err)
 	}
-	transformer.InitTransformers(plan.Spec.Transformers, transformerSelectorObj, plan.Spec.SourceDir, outputPath, plan.Name, true)
 	serviceNames := []string{}
 	planServices := map[string]plantypes.PlanArtifact{}
+	// the services in the plan may have been renamed after planning
+	plan.Spec.Services = transformer.NormalizeServiceNames(plan.Spec.Services, false)
 	for sn, st := range plan.Spec.Services {
 		for _, t := range st {
 			if _, err := transformer.GetTransformerByName(t.TransformerName); err == nil {
@@ -65,19 +84,155 @@ func Transform(ctx context.Context, plan plantypes.Plan, outputPath string, tran
 		}
 	}
 	sort.Strings(serviceNames)
+	common.ServiceWaves = map[string]string{}
+	for serviceName, wave := range plan.GetServiceWaves() {
+		// the names of the services are normalized the same way as the services of the plan
+		serviceName = common.NormalizeForMetadataName(serviceName)
+		if _, ok := planServices[serviceName]; !ok {
+			logrus.Debugf("Ignoring the service %s in the wave %s since it is not in the plan", serviceName, wave)
+			continue
+		}
+		common.ServiceWaves[serviceName] = wave
+	}
 	selectedServices := qaengine.FetchMultiSelectAnswer(common.ConfigServicesNamesKey, "Select all services that are needed:", []string{"The services unselected here will be ignored."}, serviceNames, serviceNames)
 	selectedPlanServices := []plantypes.PlanArtifact{}
 	for _, selectedService := range selectedServices {
 		selectedPlanServices = append(selectedPlanServices, planServices[selectedService])
+		recordSourcePlatform(planServices[selectedService].TransformerName)
+	}
+	qaengine.SetServiceNames(selectedServices)
+	common.ImageTag = getImageTag(plan.Spec.SourceDir)
+	qaengine.ReviewAnswers()
+	err := transformer.Transform(ctx, selectedPlanServices, plan.Spec.SourceDir, outputPath)
+	if hookErr := transformer.RunPostTransformHooks(err); hookErr != nil {
+		logrus.Errorf("Failed to run the hooks after the transform. Error: %q", hookErr)
 	}
-	if err := transformer.Transform(selectedPlanServices, plan.Spec.SourceDir, outputPath); err != nil {
+	if err != nil {
+		if budgetErr, ok := err.(*common.BudgetExceededError); ok {
+			writeRunReport(outputPath, fmt.Sprintf("budget exceeded at transformer %s", budgetErr.Transformer), budgetErr.Error())
+			logrus.Fatalf("The run was stopped because %s . The partial output can be found at [%s] and the report at %s", budgetErr, outputPath, filepath.Join(outputPath, RunReportFile))
+		}
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			// the answers given so far are kept, so that they are not asked again when the transform is resumed
+			if err := qaengine.WriteStoresToDisk(); err != nil {
+				logrus.Errorf("Failed to write the answers to disk. Error: %q", err)
+			}
+			logrus.Fatalf("The transform was interrupted. The partial output can be found at [%s] . Use the --resume flag to resume it. Error: %q", outputPath, err)
+		}
 		logrus.Fatalf("Failed to transform the plan. Error: %q", err)
 	}
+	if common.HasRunBudget() {
+		writeRunReport(outputPath, "completed", "")
+	}
+	writeRuntimeEOLReport(outputPath)
+	writeServiceDiscoveryReport(outputPath)
 	logrus.Infof("Transformation done")
 }
 
+// recordSourcePlatform records the class of the transformer that detected a service in the usage summary
+func recordSourcePlatform(transformerName string) {
+	t, err := transformer.GetTransformerByName(transformerName)
+	if err != nil {
+		return
+	}
+	tConfig, _ := t.GetConfig()
+	telemetry.RecordSourcePlatform(tConfig.Spec.Class)
+}
+
+// writeRunReport writes the status of the run and its budgets to the output directory
+func writeRunReport(outputPath, status, details string) {
+	report := "# Run report\n\n"
+	if common.RunID != "" {
+		report += fmt.Sprintf("Run ID: %s\n\n", common.RunID)
+	}
+	report += fmt.Sprintf("Status: %s\n\n", status)
+	if details != "" {
+		report += fmt.Sprintf("Details: %s\n\n", details)
+	}
+	report += fmt.Sprintf("Duration: %s\n\n", common.GetRunDuration().Round(time.Second))
+	report += "| Budget | Limit |\n| --- | --- |\n"
+	if common.MaxRunDuration > 0 {
+		report += fmt.Sprintf("| %s | %s |\n", common.DurationBudget, common.MaxRunDuration)
+	}
+	if common.MaxDiskUsage > 0 {
+		report += fmt.Sprintf("| %s | %s |\n", common.DiskBudget, resource.NewQuantity(common.MaxDiskUsage, resource.BinarySI))
+	}
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("Failed to create the output directory at path %s . Error: %q", outputPath, err)
+	}
+	reportPath := filepath.Join(outputPath, RunReportFile)
+	if err := os.WriteFile(reportPath, []byte(report), common.DefaultFilePermission); err != nil {
+		logrus.Errorf("Failed to write the run report to the file at path %s . Error: %q", reportPath, err)
+	}
+}
+
+// writeRuntimeEOLReport writes the advisories about the runtimes that reached or are nearing their end of life to the output directory
+func writeRuntimeEOLReport(outputPath string) {
+	advisories := eol.GetAdvisories()
+	if len(advisories) == 0 {
+		return
+	}
+	report := "# Runtime end of life report\n\n"
+	report += "| Service | Runtime | Version | End of life | Target version |\n| --- | --- | --- | --- | --- |\n"
+	for _, advisory := range advisories {
+		report += fmt.Sprintf("| %s | %s | %s | %s | %s |\n", advisory.ServiceName, advisory.Runtime, advisory.Version, advisory.EOLDate, advisory.TargetVersion)
+	}
+	reportPath := filepath.Join(outputPath, RuntimeEOLReportFile)
+	if err := os.WriteFile(reportPath, []byte(report), common.DefaultFilePermission); err != nil {
+		logrus.Errorf("Failed to write the runtime end of life report to the file at path %s . Error: %q", reportPath, err)
+		return
+	}
+	logrus.Warnf("Some services use runtimes that reached or are nearing their end of life. See the report at %s", reportPath)
+}
+
+// writeServiceDiscoveryReport writes the references to other services that were found and whether they were rewritten to the output directory
+func writeServiceDiscoveryReport(outputPath string) {
+	rewrites := irpreprocessor.GetServiceDiscoveryRewrites()
+	if len(rewrites) == 0 {
+		return
+	}
+	report := "# Service discovery report\n\n"
+	report += "| Reference | Kubernetes DNS name | Rewritten |\n| --- | --- | --- |\n"
+	for _, rewrite := range rewrites {
+		rewritten := "no"
+		if rewrite.Rewritten {
+			rewritten = "yes"
+		}
+		report += fmt.Sprintf("| %s | %s | %s |\n", rewrite.Host, rewrite.DNSName, rewritten)
+	}
+	reportPath := filepath.Join(outputPath, ServiceDiscoveryReportFile)
+	if err := os.WriteFile(reportPath, []byte(report), common.DefaultFilePermission); err != nil {
+		logrus.Errorf("Failed to write the service discovery report to the file at path %s . Error: %q", reportPath, err)
+		return
+	}
+	logrus.Infof("The references to other services are listed in the report at %s", reportPath)
+}
+
+// WaitForCustomizationChanges blocks until the files in the customizations directory change or the context is cancelled
+func WaitForCustomizationChanges(ctx context.Context, customizationsDir string) error {
+	return transformer.WaitForCustomizationChanges(ctx, customizationsDir)
+}
+
 // Destroy destroys the tranformers
 func Destroy() {
 	logrus.Debugf("Cleaning up!")
+	questionreceivers.StopGRPCReceiver()
 	transformer.Destroy()
+	container.ReleaseAllPooledContainers()
+}
+
+// getPlanTransformerSelector combines the transformer selector with the one in the plan
+func getPlanTransformerSelector(plan plantypes.Plan, transformerSelector string) labels.Selector {
+	transformerSelectorObj, err := common.ConvertStringSelectorsToSelectors(transformerSelector)
+	if err != nil {
+		logrus.Errorf("Unable to parse the transformer selector string : %s", err)
+	}
+	selectorsInPlan, err := metav1.LabelSelectorAsSelector(&plan.Spec.TransformerSelector)
+	if err != nil {
+		logrus.Errorf("Unable to convert label selector to selector : %s", err)
+	} else {
+		requirements, _ := selectorsInPlan.Requirements()
+		transformerSelectorObj = transformerSelectorObj.Add(requirements...)
+	}
+	return transformerSelectorObj
 }
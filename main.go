@@ -40,6 +40,8 @@ func main() {
 	common.TempPath = tempPath
 	common.AssetsPath = assetsPath
 	defer os.RemoveAll(tempPath)
+	// the deferred calls do not run when the process exits on a fatal error or a second interrupt
+	logrus.RegisterExitHandler(func() { os.RemoveAll(tempPath) })
 	if err := rootCmd.Execute(); err != nil {
 		logrus.Fatalf("Error: %q", err)
 	}
@@ -0,0 +1,102 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"sync"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+// qaBroker serializes the questions asked by concurrent goroutines, so that the engines, like the cli, get one question at a time.
+// The questions get their turns in the order in which they were asked.
+// A question that is asked while the same question is waiting or being answered is not asked again, and gets the same answer.
+type qaBroker struct {
+	mutex sync.Mutex
+	// busy is true while a question is being answered
+	busy bool
+	// turns are closed to let the waiting questions be answered, in order
+	turns []chan struct{}
+	// pending are the questions that are waiting or being answered, by their id
+	pending map[string]*pendingAnswerT
+}
+
+// pendingAnswerT is the answer of a question that is waiting or being answered
+type pendingAnswerT struct {
+	probType qatypes.SolutionFormType
+	done     chan struct{}
+	answer   interface{}
+	err      error
+}
+
+var broker = &qaBroker{pending: map[string]*pendingAnswerT{}}
+
+// fetch answers the question using the function when it gets its turn, or waits for the answer of the same question if it is already pending
+func (b *qaBroker) fetch(prob qatypes.Problem, fetch func(qatypes.Problem) (qatypes.Problem, error)) (qatypes.Problem, error) {
+	b.mutex.Lock()
+	if pending, ok := b.pending[prob.ID]; ok && pending.probType == prob.Type {
+		b.mutex.Unlock()
+		logrus.Debugf("waiting for the answer of the question %s , which is already being asked", prob.ID)
+		<-pending.done
+		if pending.err != nil {
+			return prob, pending.err
+		}
+		prob.Answer = pending.answer
+		return prob, nil
+	}
+	var pending *pendingAnswerT
+	if _, ok := b.pending[prob.ID]; !ok && prob.ID != "" {
+		pending = &pendingAnswerT{probType: prob.Type, done: make(chan struct{})}
+		b.pending[prob.ID] = pending
+	}
+	var turn chan struct{}
+	if b.busy {
+		turn = make(chan struct{})
+		b.turns = append(b.turns, turn)
+	}
+	b.busy = true
+	b.mutex.Unlock()
+	if turn != nil {
+		<-turn
+	}
+	answered, err := b.answer(prob, fetch)
+	if pending != nil {
+		pending.answer, pending.err = answered.Answer, err
+		b.mutex.Lock()
+		delete(b.pending, prob.ID)
+		b.mutex.Unlock()
+		close(pending.done)
+	}
+	return answered, err
+}
+
+// answer answers the question and gives the turn to the next question
+func (b *qaBroker) answer(prob qatypes.Problem, fetch func(qatypes.Problem) (qatypes.Problem, error)) (qatypes.Problem, error) {
+	defer func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		if len(b.turns) == 0 {
+			b.busy = false
+			return
+		}
+		next := b.turns[0]
+		b.turns = b.turns[1:]
+		close(next)
+	}()
+	return fetch(prob)
+}
@@ -0,0 +1,105 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestQABroker(t *testing.T) {
+	t.Run("the same question asked concurrently is answered once", func(t *testing.T) {
+		b := &qaBroker{pending: map[string]*pendingAnswerT{}}
+		calls := int32(0)
+		fetch := func(prob qatypes.Problem) (qatypes.Problem, error) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(50 * time.Millisecond)
+			prob.Answer = "answer"
+			return prob, nil
+		}
+		wg := sync.WaitGroup{}
+		answers := make([]interface{}, 5)
+		for i := range answers {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				prob, err := b.fetch(qatypes.Problem{ID: "move2kube.key", Type: qatypes.InputSolutionFormType}, fetch)
+				if err != nil {
+					t.Errorf("failed to fetch the answer. Error: %q", err)
+				}
+				answers[i] = prob.Answer
+			}(i)
+		}
+		wg.Wait()
+		if calls != 1 {
+			t.Fatalf("the question was asked %d times instead of once", calls)
+		}
+		for _, answer := range answers {
+			if answer != "answer" {
+				t.Fatalf("wrong answer. Expected: answer Actual: %v", answer)
+			}
+		}
+	})
+	t.Run("different questions are asked one at a time in order", func(t *testing.T) {
+		b := &qaBroker{pending: map[string]*pendingAnswerT{}}
+		asking := int32(0)
+		order := []string{}
+		release := make(chan struct{})
+		fetch := func(prob qatypes.Problem) (qatypes.Problem, error) {
+			if atomic.AddInt32(&asking, 1) != 1 {
+				t.Errorf("the question %s was asked while another question was being asked", prob.ID)
+			}
+			if prob.ID == "first" {
+				<-release
+			}
+			order = append(order, prob.ID)
+			atomic.AddInt32(&asking, -1)
+			prob.Answer = prob.ID
+			return prob, nil
+		}
+		wg := sync.WaitGroup{}
+		for _, id := range []string{"first", "second", "third"} {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				if _, err := b.fetch(qatypes.Problem{ID: id, Type: qatypes.InputSolutionFormType}, fetch); err != nil {
+					t.Errorf("failed to fetch the answer. Error: %q", err)
+				}
+			}(id)
+			// the questions are asked one after the other
+			for !b.isWaiting(id) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		close(release)
+		wg.Wait()
+		if len(order) != 3 || order[0] != "first" || order[1] != "second" || order[2] != "third" {
+			t.Fatalf("the questions were not asked in order. Actual: %+v", order)
+		}
+	})
+}
+
+func (b *qaBroker) isWaiting(id string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	_, ok := b.pending[id]
+	return ok
+}
@@ -18,25 +18,55 @@ package qaengine
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/konveyor/move2kube/common"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	continueOption = "Answer the current question"
+	backOption     = "Go back to the previous question"
+	reviewOption   = "Review and edit the answers"
+	abortOption    = "Abort"
+	proceedOption  = "Proceed with these answers"
+
+	onlyThisServiceOption = "Only this service"
+	allServicesOption     = "All the remaining services"
+	selectServicesOption  = "Select the services"
+)
+
 // CliEngine handles the CLI based qa
 type CliEngine struct {
+	// answered are the problems answered using the cli, in the order they were first asked
+	answered []qatypes.Problem
+	// applied are the answers to per service questions that are used for other services, keyed by the question key without the service
+	applied map[string]appliedAnswer
+	// offered are the per service questions for which the user was asked whether to use the answer for other services
+	offered map[string]bool
+}
+
+// appliedAnswer is an answer to a per service question that is used for other services
+type appliedAnswer struct {
+	answer interface{}
+	// services are the services the answer is used for. It is used for all the services if services is nil.
+	services []string
 }
 
 // NewCliEngine creates a new instance of cli engine
 func NewCliEngine() Engine {
-	return new(CliEngine)
+	return &CliEngine{applied: map[string]appliedAnswer{}, offered: map[string]bool{}}
 }
 
 // StartEngine starts the cli engine
 func (*CliEngine) StartEngine() error {
+	if restartHandler != nil {
+		logrus.Infof("Press Ctrl+C at any question to go back to the previous question, review and edit the answers, or abort.")
+	}
 	return nil
 }
 
@@ -51,25 +81,265 @@ func (c *CliEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 		logrus.Errorf("the QA problem object is invalid. Error: %q", err)
 		return prob, err
 	}
+	serviceName, subKey, isServiceProblem := splitServiceQAKey(prob.ID)
+	if isServiceProblem {
+		if answered, ok := c.getAppliedAnswer(serviceName, subKey, prob); ok {
+			return answered, nil
+		}
+	}
+	prob, err := c.ask(prob)
+	if err == nil && prob.Answer != nil && isServiceProblem {
+		c.offerApplyToServices(serviceName, subKey, prob)
+	}
+	return prob, err
+}
+
+// ask asks the question using the cli
+func (c *CliEngine) ask(prob qatypes.Problem) (qatypes.Problem, error) {
+	var err error
 	switch prob.Type {
 	case qatypes.SelectSolutionFormType:
-		return c.fetchSelectAnswer(prob)
+		prob, err = c.fetchSelectAnswer(prob)
 	case qatypes.MultiSelectSolutionFormType:
-		return c.fetchMultiSelectAnswer(prob)
+		prob, err = c.fetchMultiSelectAnswer(prob)
 	case qatypes.ConfirmSolutionFormType:
-		return c.fetchConfirmAnswer(prob)
+		prob, err = c.fetchConfirmAnswer(prob)
 	case qatypes.InputSolutionFormType:
-		return c.fetchInputAnswer(prob)
+		prob, err = c.fetchInputAnswer(prob)
 	case qatypes.MultilineInputSolutionFormType:
-		return c.fetchMultilineInputAnswer(prob)
+		prob, err = c.fetchMultilineInputAnswer(prob)
 	case qatypes.PasswordSolutionFormType:
+		// passwords are not stored, so they cannot be edited later
 		return c.fetchPasswordAnswer(prob)
+	default:
+		logrus.Fatalf("unknown QA problem type: %+v", prob)
 	}
-	logrus.Fatalf("unknown QA problem type: %+v", prob)
-	return prob, nil
+	if err == nil && prob.Answer != nil {
+		c.addAnswered(prob)
+	}
+	return prob, err
+}
+
+// getAppliedAnswer answers the problem using the answer the user chose to use for this service while answering the same question for another service
+func (c *CliEngine) getAppliedAnswer(serviceName, subKey string, prob qatypes.Problem) (qatypes.Problem, bool) {
+	applied, ok := c.applied[subKey]
+	if !ok || (applied.services != nil && !common.IsStringPresent(applied.services, serviceName)) {
+		return prob, false
+	}
+	answered := prob
+	answered.Answer = applied.answer
+	if !isValidAnswer(answered) {
+		logrus.Debugf("the answer %v cannot be used for the question with ID %s", applied.answer, prob.ID)
+		return prob, false
+	}
+	logrus.Infof("Using the answer %v for the question with ID %s", applied.answer, prob.ID)
+	return answered, true
+}
+
+// offerApplyToServices asks the user whether the answer should also be used for the same question for the other services.
+// It is asked only once for each question, so that large plans need far fewer answers.
+func (c *CliEngine) offerApplyToServices(serviceName, subKey string, prob qatypes.Problem) {
+	if prob.Type == qatypes.PasswordSolutionFormType || c.offered[subKey] {
+		return
+	}
+	c.offered[subKey] = true
+	otherServices := []string{}
+	for _, otherService := range serviceNames {
+		if otherService != serviceName {
+			otherServices = append(otherServices, otherService)
+		}
+	}
+	if len(otherServices) == 0 {
+		return
+	}
+	selected := ""
+	prompt := &survey.Select{
+		Message: fmt.Sprintf("Use the answer %v for the same question for the other services?", prob.Answer),
+		Options: []string{onlyThisServiceOption, allServicesOption, selectServicesOption},
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		logrus.Debugf("not using the answer for the other services. Error: %q", err)
+		return
+	}
+	switch selected {
+	case allServicesOption:
+		c.applied[subKey] = appliedAnswer{answer: prob.Answer}
+	case selectServicesOption:
+		services := []string{}
+		prompt := &survey.MultiSelect{
+			Message:  fmt.Sprintf("Select the services to use the answer %v for:", prob.Answer),
+			Options:  otherServices,
+			Default:  otherServices,
+			PageSize: 20,
+		}
+		tickIcon := func(icons *survey.IconSet) { icons.MarkedOption.Text = "[\u2713]" }
+		if err := survey.AskOne(prompt, &services, survey.WithIcons(tickIcon)); err != nil {
+			logrus.Debugf("not using the answer for the other services. Error: %q", err)
+			return
+		}
+		c.applied[subKey] = appliedAnswer{answer: prob.Answer, services: services}
+	}
+}
+
+// splitServiceQAKey splits the key of a per service question into the service name and the rest of the key
+func splitServiceQAKey(key string) (serviceName string, subKey string, ok bool) {
+	prefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`)
+	if !strings.HasPrefix(key, prefix) {
+		return "", "", false
+	}
+	rest := strings.TrimPrefix(key, prefix)
+	end := strings.Index(rest, `".`)
+	if end <= 0 {
+		return "", "", false
+	}
+	return rest[:end], rest[end+2:], true
+}
+
+// isValidAnswer checks that the answer has the type expected by the problem and is one of its options
+func isValidAnswer(prob qatypes.Problem) bool {
+	switch prob.Type {
+	case qatypes.SelectSolutionFormType:
+		answer, ok := prob.Answer.(string)
+		return ok && (common.IsStringPresent(prob.Options, answer) || common.IsStringPresent(prob.Options, qatypes.OtherAnswer))
+	case qatypes.MultiSelectSolutionFormType:
+		answers, ok := prob.Answer.([]string)
+		if !ok {
+			return false
+		}
+		if common.IsStringPresent(prob.Options, qatypes.OtherAnswer) {
+			return true
+		}
+		for _, answer := range answers {
+			if !common.IsStringPresent(prob.Options, answer) {
+				return false
+			}
+		}
+		return true
+	case qatypes.ConfirmSolutionFormType:
+		_, ok := prob.Answer.(bool)
+		return ok
+	case qatypes.InputSolutionFormType, qatypes.MultilineInputSolutionFormType:
+		_, ok := prob.Answer.(string)
+		return ok
+	}
+	return false
 }
 
-func (*CliEngine) fetchSelectAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+// addAnswered records the answered problem, replacing an earlier answer to the same problem
+func (c *CliEngine) addAnswered(prob qatypes.Problem) {
+	for i, answered := range c.answered {
+		if answered.ID == prob.ID {
+			c.answered[i] = prob
+			return
+		}
+	}
+	c.answered = append(c.answered, prob)
+}
+
+// handleAskError lets the user go back to the previous question, or review and edit the earlier answers, when a question is interrupted.
+// Since the earlier answers may already have been used, the transformation is restarted using the edited answers.
+func (c *CliEngine) handleAskError(prob qatypes.Problem, err error) (qatypes.Problem, error) {
+	if err != terminal.InterruptErr {
+		logrus.Fatalf("Error while asking a question : %s", err)
+	}
+	options := []string{continueOption}
+	if restartHandler != nil && len(c.answered) > 0 {
+		options = append(options, backOption, reviewOption)
+	}
+	options = append(options, abortOption)
+	selected := ""
+	if err := survey.AskOne(&survey.Select{Message: "The question was interrupted. What would you like to do?", Options: options}, &selected); err != nil || selected == abortOption {
+		logrus.Fatalf("Aborted while answering the question with ID %s", prob.ID)
+	}
+	switch selected {
+	case backOption:
+		if c.editAnswer(len(c.answered) - 1) {
+			restartHandler()
+		}
+	case reviewOption:
+		if c.reviewAnswers() {
+			restartHandler()
+		}
+	}
+	prob.Answer = nil
+	return c.FetchAnswer(prob)
+}
+
+// reviewAnswers shows the answers given so far and lets the user edit them.
+// It returns true if any of the answers were changed.
+func (c *CliEngine) reviewAnswers() bool {
+	edited := false
+	for {
+		options := []string{proceedOption}
+		for _, answered := range c.answered {
+			options = append(options, fmt.Sprintf("%s : %v", answered.ID, answered.Answer))
+		}
+		selected := ""
+		prompt := &survey.Select{Message: "These are the answers given so far. Select an answer to edit it:", Options: options, PageSize: 20}
+		if err := survey.AskOne(prompt, &selected); err != nil {
+			logrus.Fatalf("Aborted while reviewing the answers")
+		}
+		idx := common.FindIndex(options, func(option string) bool { return option == selected })
+		if idx <= 0 {
+			return edited
+		}
+		if c.editAnswer(idx - 1) {
+			edited = true
+		}
+	}
+}
+
+// editAnswer asks the answered problem again and stores the new answer.
+// It returns true if the answer was changed.
+func (c *CliEngine) editAnswer(idx int) bool {
+	prob := c.answered[idx]
+	oldAnswer := prob.Answer
+	prob.Default = getDefaultFromAnswer(prob)
+	prob.Answer = nil
+	prob, err := c.ask(prob)
+	if err != nil || prob.Answer == nil {
+		logrus.Errorf("Failed to edit the answer to the question with ID %s . Error: %q", prob.ID, err)
+		return false
+	}
+	if reflect.DeepEqual(oldAnswer, prob.Answer) {
+		return false
+	}
+	for _, writeStore := range writeStores {
+		writeStore.AddSolution(prob)
+	}
+	return true
+}
+
+// getDefaultFromAnswer returns the answer to the problem in the form of a default for asking it again
+func getDefaultFromAnswer(prob qatypes.Problem) interface{} {
+	switch prob.Type {
+	case qatypes.SelectSolutionFormType:
+		if answer, ok := prob.Answer.(string); ok && common.IsPresent(prob.Options, answer) {
+			return answer
+		}
+	case qatypes.MultiSelectSolutionFormType:
+		if answers, err := common.ConvertInterfaceToSliceOfStrings(prob.Answer); err == nil {
+			def := []string{}
+			for _, answer := range answers {
+				if common.IsPresent(prob.Options, answer) {
+					def = append(def, answer)
+				}
+			}
+			return def
+		}
+	case qatypes.ConfirmSolutionFormType:
+		if answer, ok := prob.Answer.(bool); ok {
+			return answer
+		}
+	case qatypes.InputSolutionFormType, qatypes.MultilineInputSolutionFormType:
+		if answer, ok := prob.Answer.(string); ok {
+			return answer
+		}
+	}
+	return prob.Default
+}
+
+func (c *CliEngine) fetchSelectAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	var ans, def string
 	if prob.Default != nil {
 		def = prob.Default.(string)
@@ -82,13 +352,13 @@ func (*CliEngine) fetchSelectAnswer(prob qatypes.Problem) (qatypes.Problem, erro
 		Default: def,
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
-		logrus.Fatalf("Error while asking a question : %s", err)
+		return c.handleAskError(prob, err)
 	}
 	prob.Answer = ans
 	return prob, nil
 }
 
-func (*CliEngine) fetchMultiSelectAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (c *CliEngine) fetchMultiSelectAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	ans := []string{}
 	prompt := &survey.MultiSelect{
 		Message: getQAMessage(prob),
@@ -97,7 +367,7 @@ func (*CliEngine) fetchMultiSelectAnswer(prob qatypes.Problem) (qatypes.Problem,
 	}
 	tickIcon := func(icons *survey.IconSet) { icons.MarkedOption.Text = "[\u2713]" }
 	if err := survey.AskOne(prompt, &ans, survey.WithIcons(tickIcon)); err != nil {
-		logrus.Fatalf("Error while asking a question : %s", err)
+		return c.handleAskError(prob, err)
 	}
 	otherAnsPresent := false
 	newAns := []string{}
@@ -115,7 +385,7 @@ func (*CliEngine) fetchMultiSelectAnswer(prob qatypes.Problem) (qatypes.Problem,
 			Default: "",
 		}
 		if err := survey.AskOne(prompt, &multilineAns); err != nil {
-			logrus.Fatalf("Error while asking a question : %s", err)
+			return c.handleAskError(prob, err)
 		}
 		for _, lineAns := range strings.Split(multilineAns, "\n") {
 			lineAns = strings.TrimSpace(lineAns)
@@ -128,7 +398,7 @@ func (*CliEngine) fetchMultiSelectAnswer(prob qatypes.Problem) (qatypes.Problem,
 	return prob, nil
 }
 
-func (*CliEngine) fetchConfirmAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (c *CliEngine) fetchConfirmAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	var ans, def bool
 	if prob.Default != nil {
 		def = prob.Default.(bool)
@@ -138,13 +408,13 @@ func (*CliEngine) fetchConfirmAnswer(prob qatypes.Problem) (qatypes.Problem, err
 		Default: def,
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
-		logrus.Fatalf("Error while asking a question : %s", err)
+		return c.handleAskError(prob, err)
 	}
 	prob.Answer = ans
 	return prob, nil
 }
 
-func (*CliEngine) fetchInputAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (c *CliEngine) fetchInputAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	var ans, def string
 	if prob.Default != nil {
 		def = prob.Default.(string)
@@ -154,13 +424,13 @@ func (*CliEngine) fetchInputAnswer(prob qatypes.Problem) (qatypes.Problem, error
 		Default: def,
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
-		logrus.Fatalf("Error while asking a question : %s", err)
+		return c.handleAskError(prob, err)
 	}
 	prob.Answer = ans
 	return prob, nil
 }
 
-func (*CliEngine) fetchMultilineInputAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (c *CliEngine) fetchMultilineInputAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	var ans, def string
 	if prob.Default != nil {
 		def = prob.Default.(string)
@@ -170,19 +440,19 @@ func (*CliEngine) fetchMultilineInputAnswer(prob qatypes.Problem) (qatypes.Probl
 		Default: def,
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
-		logrus.Fatalf("Error while asking a question : %s", err)
+		return c.handleAskError(prob, err)
 	}
 	prob.Answer = ans
 	return prob, nil
 }
 
-func (*CliEngine) fetchPasswordAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (c *CliEngine) fetchPasswordAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	var ans string
 	prompt := &survey.Password{
 		Message: getQAMessage(prob),
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
-		logrus.Fatalf("Error while asking a question : %s", err)
+		return c.handleAskError(prob, err)
 	}
 	prob.Answer = ans
 	return prob, nil
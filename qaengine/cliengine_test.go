@@ -0,0 +1,53 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestAppliedAnswers(t *testing.T) {
+	t.Run("split per service question keys", func(t *testing.T) {
+		key := common.JoinQASubKeys(common.ConfigServicesKey, `"svc1"`, common.ConfigPortForServiceKeySegment)
+		serviceName, subKey, ok := splitServiceQAKey(key)
+		if !ok || serviceName != "svc1" || subKey != common.ConfigPortForServiceKeySegment {
+			t.Fatalf("failed to split the key %s . Actual: %s %s %v", key, serviceName, subKey, ok)
+		}
+		if _, _, ok := splitServiceQAKey(common.ConfigServicesNamesKey); ok {
+			t.Fatalf("the key %s is not a per service question key", common.ConfigServicesNamesKey)
+		}
+	})
+
+	t.Run("use the applied answer only for the chosen services", func(t *testing.T) {
+		c := NewCliEngine().(*CliEngine)
+		c.applied["expose"] = appliedAnswer{answer: "Option B", services: []string{"svc2"}}
+		prob := qatypes.Problem{ID: "expose", Type: qatypes.SelectSolutionFormType, Options: []string{"Option A", "Option B"}}
+		if answered, ok := c.getAppliedAnswer("svc2", "expose", prob); !ok || answered.Answer != "Option B" {
+			t.Fatalf("failed to use the applied answer. Actual: %+v", answered)
+		}
+		if _, ok := c.getAppliedAnswer("svc3", "expose", prob); ok {
+			t.Fatalf("the applied answer should not be used for a service that was not chosen")
+		}
+		prob.Options = []string{"Option A"}
+		if _, ok := c.getAppliedAnswer("svc2", "expose", prob); ok {
+			t.Fatalf("the applied answer should not be used when it is not one of the options")
+		}
+	})
+}
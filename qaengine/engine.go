@@ -18,7 +18,9 @@ package qaengine
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
@@ -36,8 +38,49 @@ var (
 	engines       []Engine
 	writeStores   []qatypes.Store
 	defaultEngine = NewDefaultEngine()
+	// restartHandler restarts the run, so that edited answers are used by all the steps that depend on them
+	restartHandler func()
+	// serviceNames are the names of the services being transformed
+	serviceNames []string
+	// configStore is the config used to answer the questions
+	configStore *qatypes.Config
+	// userConfig has only the answers in the config files and strings given by the user
+	userConfig *qatypes.Config
 )
 
+const (
+	maxKeySuggestions = 3
+)
+
+// SetServiceNames sets the names of the services being transformed.
+// The answers to per service questions can then be used for the other services.
+func SetServiceNames(names []string) {
+	serviceNames = names
+}
+
+// SetRestartHandler sets the handler used to restart the run when the user edits an earlier answer.
+// Going back to earlier questions is only possible when a restart handler is set.
+func SetRestartHandler(handler func()) {
+	restartHandler = handler
+}
+
+// ReviewAnswers lets the user review the answers given using the cli and edit them before proceeding.
+// The run is restarted if any of the answers were changed.
+func ReviewAnswers() {
+	if restartHandler == nil {
+		return
+	}
+	for _, e := range engines {
+		c, ok := e.(*CliEngine)
+		if !ok || len(c.answered) == 0 {
+			continue
+		}
+		if c.reviewAnswers() {
+			restartHandler()
+		}
+	}
+}
+
 // StartEngine starts the QA Engines
 func StartEngine(qaskip bool, qaport int, qadisablecli bool) {
 	var e Engine
@@ -83,39 +126,103 @@ func AddCaches(cacheFiles ...string) {
 	}
 }
 
-// SetupWriteCacheFile adds write cache
-func SetupWriteCacheFile(writeCachePath string, persistPasswords bool) {
+// SetupWriteCacheFile adds write cache.
+// If resume is true, the answers in an existing cache file are loaded and kept.
+func SetupWriteCacheFile(writeCachePath string, persistPasswords bool, resume bool) {
+	if err := common.AcquireLock(writeCachePath); err != nil {
+		logrus.Fatalf("Unable to use the cache file at path %s . Error: %q", writeCachePath, err)
+	}
 	cache := qatypes.NewCache(writeCachePath, persistPasswords)
+	if resume {
+		if _, err := os.Stat(writeCachePath); err == nil {
+			if err := cache.Load(); err != nil {
+				logrus.Warnf("Unable to resume using the answers in the cache file at path %s . Error: %q", writeCachePath, err)
+			} else {
+				logrus.Infof("Resuming using the %d answers in the cache file at path %s", len(cache.Spec.Problems), writeCachePath)
+			}
+		}
+	}
 	cache.Write()
 	writeStores = append(writeStores, cache)
 	AddCaches(writeCachePath)
 }
 
-// SetupConfigFile adds config responders - should be called only once
-func SetupConfigFile(writeConfigFile string, configStrings, configFiles, presets []string, persistPasswords bool) {
+// SetupConfigFile adds config responders - should be called only once.
+// If resume is true, the answers in an existing output config file are used and kept.
+func SetupConfigFile(writeConfigFile string, configStrings, configFiles, presets []string, persistPasswords bool, resume bool) {
 	presetPaths := []string{}
 	for _, preset := range presets {
 		presetPath := filepath.Join(common.AssetsPath, "built-in", "presets", preset+".yaml")
 		presetPaths = append(presetPaths, presetPath)
 	}
+	if writeConfigFile != "" {
+		if err := common.AcquireLock(writeConfigFile); err != nil {
+			logrus.Fatalf("Unable to use the config file at path %s . Error: %q", writeConfigFile, err)
+		}
+	}
+	resumeFromConfig := false
+	if resume && writeConfigFile != "" {
+		if _, err := os.Stat(writeConfigFile); err == nil {
+			// the config files given by the user override the answers from the previous run
+			presetPaths = append(presetPaths, writeConfigFile)
+			resumeFromConfig = true
+		}
+	}
+	userConfig = qatypes.NewConfig("", configStrings, configFiles, false)
+	if err := userConfig.Load(); err != nil {
+		logrus.Debugf("failed to load the config files and strings given by the user. Error: %q", err)
+		userConfig = nil
+	}
 	configFiles = append(presetPaths, configFiles...)
 	writeConfig := qatypes.NewConfig(writeConfigFile, configStrings, configFiles, persistPasswords)
+	configStore = writeConfig
 	if writeConfigFile != "" {
 		writeStores = append(writeStores, writeConfig)
 	}
 	e := &StoreEngine{store: writeConfig}
 	if err := AddEngineHighestPriority(e); err != nil {
 		logrus.Errorf("Ignoring engine %T due to error : %s", e, err)
+		return
+	}
+	if resumeFromConfig {
+		if err := writeConfig.Resume(); err != nil {
+			logrus.Warnf("Unable to resume using the answers in the config file at path %s . Error: %q", writeConfigFile, err)
+		} else {
+			logrus.Infof("Resuming using the answers in the config file at path %s", writeConfigFile)
+		}
+	}
+}
+
+// WarnUnusedConfigKeys warns about the keys in the config files and strings that did not match any of the questions asked.
+// These are usually typos, so the closest keys that were asked are suggested.
+func WarnUnusedConfigKeys() {
+	if configStore == nil || userConfig == nil {
+		return
+	}
+	requestedKeys := configStore.GetRequestedKeys()
+	for _, key := range configStore.GetUnmatchedKeys(userConfig.GetKeys()) {
+		suggestions := common.GetClosestMatches(key, requestedKeys, maxKeySuggestions)
+		if len(suggestions) == 0 {
+			logrus.Warnf("the config key %s did not match any of the questions asked", key)
+			continue
+		}
+		logrus.Warnf("the config key %s did not match any of the questions asked. Did you mean: %s", key, strings.Join(suggestions, " , "))
 	}
 }
 
-// FetchAnswer fetches the answer for the question
+// FetchAnswer fetches the answer for the question.
+// It is safe to call it from concurrent goroutines, like the transformers that run in parallel.
+// The questions are asked one at a time in the order in which they were asked, and a question that is asked again while it is being answered gets the same answer.
 func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	logrus.Debugf("Fetching answer for problem:\n%v", prob)
 	if prob.Answer != nil {
 		logrus.Debugf("Problem already solved.")
 		return prob, nil
 	}
+	return broker.fetch(prob, fetchAnswer)
+}
+
+func fetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	var err error
 	for _, e := range engines {
 		if prob.Desc == "" && e.IsInteractiveEngine() {
@@ -17,9 +17,12 @@
 package qaengine
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/sirupsen/logrus"
 )
 
@@ -46,3 +49,55 @@ func TestEngine(t *testing.T) {
 	})
 
 }
+
+func TestSetupWriteCacheFile(t *testing.T) {
+	logrus.SetLevel(logrus.DebugLevel)
+	problemID := "move2kube.target.imageregistry.namespace"
+
+	setup := func(t *testing.T, resume bool) string {
+		cachePath := filepath.Join(t.TempDir(), "m2kqacache.yaml")
+		previous := qatypes.NewCache(cachePath, false)
+		if err := previous.AddSolution(qatypes.Problem{ID: problemID, Type: qatypes.InputSolutionFormType, Desc: "Enter the namespace :", Answer: "previous"}); err != nil {
+			t.Fatalf("failed to write the cache of the previous run. Error: %q", err)
+		}
+		engines = []Engine{}
+		writeStores = []qatypes.Store{}
+		AddEngine(NewDefaultEngine())
+		SetupWriteCacheFile(cachePath, false, resume)
+		t.Cleanup(func() {
+			common.ReleaseLocks()
+			engines = []Engine{}
+			writeStores = []qatypes.Store{}
+		})
+		return cachePath
+	}
+
+	t.Run("resume using the answers in the cache", func(t *testing.T) {
+		cachePath := setup(t, true)
+		if answer := FetchStringAnswer(problemID, "Enter the namespace :", nil, "default"); answer != "previous" {
+			t.Fatalf("expected the answer of the previous run. Actual: %s", answer)
+		}
+		FetchStringAnswer("move2kube.target.imageregistry.url", "Enter the url :", nil, "quay.io")
+		cache := qatypes.NewCache(cachePath, false)
+		if err := cache.Load(); err != nil {
+			t.Fatalf("failed to load the cache. Error: %q", err)
+		}
+		if len(cache.Spec.Problems) != 2 {
+			t.Fatalf("expected the cache to keep the answers of both runs. Actual: %+v", cache.Spec.Problems)
+		}
+	})
+
+	t.Run("start over without resuming", func(t *testing.T) {
+		cachePath := setup(t, false)
+		if answer := FetchStringAnswer(problemID, "Enter the namespace :", nil, "default"); answer != "default" {
+			t.Fatalf("expected the default answer. Actual: %s", answer)
+		}
+		cache := qatypes.NewCache(cachePath, false)
+		if err := cache.Load(); err != nil {
+			t.Fatalf("failed to load the cache. Error: %q", err)
+		}
+		if len(cache.Spec.Problems) != 1 || cache.Spec.Problems[0].Answer != "default" {
+			t.Fatalf("expected the cache to only have the answer of this run. Actual: %+v", cache.Spec.Problems)
+		}
+	})
+}
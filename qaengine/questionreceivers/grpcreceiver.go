@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync"
 
 	"github.com/konveyor/move2kube/qaengine"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
@@ -32,7 +33,9 @@ import (
 )
 
 var (
-	grpcReceiver net.Addr
+	grpcReceiver      net.Addr
+	grpcServer        *grpc.Server
+	grpcReceiverMutex sync.Mutex
 )
 
 type server struct {
@@ -61,6 +64,8 @@ func (s *server) FetchAnswer(ctx context.Context, prob *qagrpc.Problem) (a *qagr
 
 // StartGRPCReceiver starts the GRPC receiver for QA Engine
 func StartGRPCReceiver() (addr net.Addr, err error) {
+	grpcReceiverMutex.Lock()
+	defer grpcReceiverMutex.Unlock()
 	if grpcReceiver != nil {
 		return grpcReceiver, nil
 	}
@@ -86,5 +91,20 @@ func StartGRPCReceiver() (addr net.Addr, err error) {
 	}(listener)
 	logrus.Info("Started QA GPRC Receiver engine on: " + listener.Addr().String())
 	grpcReceiver = listener.Addr()
+	grpcServer = s
 	return grpcReceiver, nil
 }
+
+// StopGRPCReceiver stops the GRPC receiver and closes the connections of the transformers that are still asking questions
+func StopGRPCReceiver() {
+	grpcReceiverMutex.Lock()
+	defer grpcReceiverMutex.Unlock()
+	if grpcServer == nil {
+		return
+	}
+	// the pending questions may never be answered, so the server is not stopped gracefully
+	grpcServer.Stop()
+	grpcServer = nil
+	grpcReceiver = nil
+	logrus.Debugf("Stopped the QA GRPC Receiver engine")
+}
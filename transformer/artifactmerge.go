@@ -0,0 +1,109 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/common/deepcopy"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+// applyMergeRules combines the artifacts of the same type and name using the merge rules of the transformer that consumes them,
+// so that the transformer does not run on near duplicates produced by overlapping transformers.
+// The artifacts without a merge rule, or with the KeepAll rule, are all kept, even if they are exact duplicates.
+func applyMergeRules(artifactsToProcess []transformertypes.Artifact, tConfig transformertypes.Transformer) []transformertypes.Artifact {
+	mergedArtifacts := []transformertypes.Artifact{}
+	for _, artifact := range artifactsToProcess {
+		rule := tConfig.Spec.ConsumedArtifacts[artifact.Type].MergeRule
+		if rule == "" || rule == transformertypes.KeepAll {
+			mergedArtifacts = append(mergedArtifacts, artifact)
+			continue
+		}
+		index := -1
+		for i, mergedArtifact := range mergedArtifacts {
+			if mergedArtifact.Type == artifact.Type && mergedArtifact.Name == artifact.Name {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			mergedArtifacts = append(mergedArtifacts, artifact)
+			continue
+		}
+		logrus.Debugf("Combining the artifacts %s of type %s for the transformer %s using the merge rule %s", artifact.Name, artifact.Type, tConfig.Name, rule)
+		mergedArtifacts[index] = mergeArtifactsUsingRule(mergedArtifacts[index], artifact, rule)
+	}
+	return mergedArtifacts
+}
+
+// mergeArtifactsUsingRule combines two artifacts of the same type and name using the merge rule
+func mergeArtifactsUsingRule(a, b transformertypes.Artifact, rule transformertypes.ArtifactMergeRule) transformertypes.Artifact {
+	switch rule {
+	case transformertypes.UnionPaths:
+		a.Paths = mergePathSliceMaps(copyPaths(a.Paths), b.Paths)
+		configs := copyConfigs(a.Configs)
+		for configType, config := range b.Configs {
+			if _, ok := configs[configType]; !ok {
+				configs[configType] = config
+			}
+		}
+		a.Configs = configs
+	case transformertypes.DeepMerge:
+		a.Paths = mergePathSliceMaps(copyPaths(a.Paths), b.Paths)
+		configs, merged := mergeConfigs(copyConfigs(a.Configs), b.Configs)
+		if !merged {
+			// the typed configs refused to merge, so they are merged as plain objects
+			configs = copyConfigs(a.Configs)
+			for configType, config := range b.Configs {
+				configs[configType] = deepcopy.Merge(configs[configType], config)
+			}
+		}
+		a.Configs = configs
+	case transformertypes.PreferHigherPriority:
+		if getProducerPriority(b) > getProducerPriority(a) {
+			return b
+		}
+	}
+	return a
+}
+
+// getProducerPriority returns the priority of the transformer that produced the artifact
+func getProducerPriority(artifact transformertypes.Artifact) int {
+	if t, ok := transformerMap[artifact.ProducedBy]; ok {
+		tConfig, _ := t.GetConfig()
+		return tConfig.Spec.Priority
+	}
+	return 0
+}
+
+// copyPaths returns a shallow copy of the paths, so that merging them does not change the artifacts that were already seen
+func copyPaths(paths map[transformertypes.PathType][]string) map[transformertypes.PathType][]string {
+	copied := map[transformertypes.PathType][]string{}
+	for pathType, ps := range paths {
+		copied[pathType] = append([]string{}, ps...)
+	}
+	return copied
+}
+
+// copyConfigs returns a shallow copy of the configs, so that merging them does not change the artifacts that were already seen
+func copyConfigs(configs map[transformertypes.ConfigType]interface{}) map[transformertypes.ConfigType]interface{} {
+	copied := map[transformertypes.ConfigType]interface{}{}
+	for configType, config := range configs {
+		copied[configType] = config
+	}
+	return copied
+}
@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+const (
+	testArtifactType transformertypes.ArtifactType = "TestArtifact"
+	testConfigType   transformertypes.ConfigType   = "TestMergeConfig"
+)
+
+// priorityTransformer is a transformer that only has a priority, used to test the PreferHigherPriority merge rule
+type priorityTransformer struct {
+	tConfig transformertypes.Transformer
+}
+
+func (t *priorityTransformer) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.tConfig = tc
+	return nil
+}
+
+func (t *priorityTransformer) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.tConfig, nil
+}
+
+func (t *priorityTransformer) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	return nil, nil
+}
+
+func (t *priorityTransformer) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	return nil, nil, nil
+}
+
+func getTestMergeTransformer(rule transformertypes.ArtifactMergeRule) transformertypes.Transformer {
+	tConfig := transformertypes.NewTransformer()
+	tConfig.Name = "consumer"
+	tConfig.Spec.ConsumedArtifacts = map[transformertypes.ArtifactType]transformertypes.ArtifactProcessConfig{
+		testArtifactType: {MergeRule: rule},
+	}
+	return tConfig
+}
+
+func getTestArtifact(name, producedBy string, paths []string, config map[string]interface{}) transformertypes.Artifact {
+	return transformertypes.Artifact{
+		Name:       name,
+		Type:       testArtifactType,
+		ProducedBy: producedBy,
+		Paths:      map[transformertypes.PathType][]string{"Dir": paths},
+		Configs:    map[transformertypes.ConfigType]interface{}{testConfigType: config},
+	}
+}
+
+func TestApplyMergeRules(t *testing.T) {
+	transformerMap["low"] = &priorityTransformer{tConfig: transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Priority: 1}}}
+	transformerMap["high"] = &priorityTransformer{tConfig: transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Priority: 10}}}
+	defer delete(transformerMap, "low")
+	defer delete(transformerMap, "high")
+
+	testcases := []struct {
+		name      string
+		rule      transformertypes.ArtifactMergeRule
+		artifacts []transformertypes.Artifact
+		want      []transformertypes.Artifact
+	}{
+		{
+			name: "no rule keeps the exact duplicates",
+			artifacts: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+			},
+			want: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+			},
+		},
+		{
+			name: "keep all keeps the near duplicates",
+			rule: transformertypes.KeepAll,
+			artifacts: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+				getTestArtifact("svc1", "high", []string{"b"}, map[string]interface{}{"port": 9090}),
+			},
+			want: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+				getTestArtifact("svc1", "high", []string{"b"}, map[string]interface{}{"port": 9090}),
+			},
+		},
+		{
+			name: "union paths keeps the configs of the first artifact",
+			rule: transformertypes.UnionPaths,
+			artifacts: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+				getTestArtifact("svc2", "low", []string{"c"}, map[string]interface{}{"port": 7070}),
+				getTestArtifact("svc1", "high", []string{"a", "b"}, map[string]interface{}{"port": 9090}),
+			},
+			want: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a", "b"}, map[string]interface{}{"port": 8080}),
+				getTestArtifact("svc2", "low", []string{"c"}, map[string]interface{}{"port": 7070}),
+			},
+		},
+		{
+			name: "deep merge merges the configs",
+			rule: transformertypes.DeepMerge,
+			artifacts: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+				getTestArtifact("svc1", "high", []string{"b"}, map[string]interface{}{"image": "web"}),
+			},
+			want: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a", "b"}, map[string]interface{}{"port": 8080, "image": "web"}),
+			},
+		},
+		{
+			name: "prefer higher priority keeps the artifact of the transformer with the highest priority",
+			rule: transformertypes.PreferHigherPriority,
+			artifacts: []transformertypes.Artifact{
+				getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080}),
+				getTestArtifact("svc1", "high", []string{"b"}, map[string]interface{}{"port": 9090}),
+				getTestArtifact("svc1", "low", []string{"c"}, map[string]interface{}{"port": 7070}),
+			},
+			want: []transformertypes.Artifact{
+				getTestArtifact("svc1", "high", []string{"b"}, map[string]interface{}{"port": 9090}),
+			},
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			first := getTestArtifact("svc1", "low", []string{"a"}, map[string]interface{}{"port": 8080})
+			actual := applyMergeRules(testcase.artifacts, getTestMergeTransformer(testcase.rule))
+			if !cmp.Equal(actual, testcase.want) {
+				t.Fatalf("the merged artifacts are incorrect. Difference:\n%s", cmp.Diff(testcase.want, actual))
+			}
+			if !cmp.Equal(testcase.artifacts[0], first) {
+				t.Fatalf("the artifacts that were merged should not be changed. Difference:\n%s", cmp.Diff(first, testcase.artifacts[0]))
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBackstageDir          = common.DeployDir + string(os.PathSeparator) + "backstage"
+	backstageCatalogFileName     = "catalog-info.yaml"
+	backstageAPIVersion          = "backstage.io/v1alpha1"
+	backstageDefaultOwner        = "user:guest"
+	backstageLabelSelectorKey    = "backstage.io/kubernetes-label-selector"
+	backstageServiceSelectorKey  = types.GroupName + "/service"
+	backstageOpenAPIType         = "openapi"
+	backstageGRPCType            = "grpc"
+	backstageExternalServiceType = "external-service"
+)
+
+var (
+	backstageLifecycles = []string{"experimental", "production", "deprecated"}
+)
+
+// BackstageCatalogGenerator implements Transformer interface
+type BackstageCatalogGenerator struct {
+	Config          transformertypes.Transformer
+	Env             *environment.Environment
+	BackstageConfig *BackstageYamlConfig
+	specs           []openAPISpec
+}
+
+// BackstageYamlConfig stores the yaml configuration for the Backstage catalog transformer
+type BackstageYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+}
+
+// backstageEntity is an entity of the Backstage software catalog
+type backstageEntity struct {
+	APIVersion string                  `yaml:"apiVersion"`
+	Kind       string                  `yaml:"kind"`
+	Metadata   backstageEntityMetadata `yaml:"metadata"`
+	Spec       map[string]interface{}  `yaml:"spec"`
+}
+
+type backstageEntityMetadata struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Init Initializes the transformer
+func (t *BackstageCatalogGenerator) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.BackstageConfig = &BackstageYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.BackstageConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.BackstageConfig, err)
+		return err
+	}
+	if t.BackstageConfig.OutputPath == "" {
+		t.BackstageConfig.OutputPath = defaultBackstageDir
+	}
+	t.specs, err = findOpenAPISpecs(env.GetEnvironmentSource())
+	return err
+}
+
+// GetConfig returns the transformer config
+func (t *BackstageCatalogGenerator) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *BackstageCatalogGenerator) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform generates a Backstage component for each service, along with the APIs it provides and the services and external services it depends on
+func (t *BackstageCatalogGenerator) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	for _, a := range newArtifacts {
+		if a.Type != irtypes.IRArtifactType {
+			continue
+		}
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		if len(ir.Services) == 0 {
+			continue
+		}
+		system := common.NormalizeForMetadataName(qaengine.FetchStringAnswer(
+			common.ConfigBackstageSystemKey,
+			"Enter the name of the Backstage system the services belong to :",
+			[]string{"The services are registered as components of this system in the Backstage catalog"},
+			t.Env.GetProjectName(),
+		))
+		owner := qaengine.FetchStringAnswer(
+			common.ConfigBackstageOwnerKey,
+			"Enter the owner of the services in the Backstage catalog :",
+			[]string{"A reference to a user or a group, like group:team-a"},
+			backstageDefaultOwner,
+		)
+		lifecycle := qaengine.FetchSelectAnswer(
+			common.ConfigBackstageLifecycleKey,
+			"Select the lifecycle of the services in the Backstage catalog :",
+			nil,
+			backstageLifecycles[1],
+			backstageLifecycles,
+		)
+		newPathMappings, err := t.writeBackstageCatalog(ir, system, owner, lifecycle)
+		if err != nil {
+			logrus.Errorf("failed to write the Backstage catalog. Error: %q", err)
+		}
+		pathMappings = append(pathMappings, newPathMappings...)
+	}
+	return pathMappings, nil, nil
+}
+
+// writeBackstageCatalog writes the entities of each service to its own catalog file,
+// and the system, the external services and a location pointing to the files of the services to the root catalog file
+func (t *BackstageCatalogGenerator) writeBackstageCatalog(ir irtypes.IR, system, owner, lifecycle string) ([]transformertypes.PathMapping, error) {
+	tempDest := filepath.Join(t.Env.TempPath, t.BackstageConfig.OutputPath)
+	serviceNames := []string{}
+	for sn := range ir.Services {
+		serviceNames = append(serviceNames, sn)
+	}
+	sort.Strings(serviceNames)
+	pathMappings := []transformertypes.PathMapping{}
+	targets := []string{}
+	for _, sn := range serviceNames {
+		service := ir.Services[sn]
+		component := backstageEntity{
+			APIVersion: backstageAPIVersion,
+			Kind:       "Component",
+			Metadata: backstageEntityMetadata{
+				Name:        sn,
+				Annotations: map[string]string{backstageLabelSelectorKey: backstageServiceSelectorKey + "=" + sn},
+			},
+			Spec: map[string]interface{}{"type": "service", "lifecycle": lifecycle, "owner": owner, "system": system},
+		}
+		entities := []backstageEntity{}
+		providedAPIs := []string{}
+		if spec, ok := getServiceOpenAPISpec(t.specs, ir, sn, service); ok {
+			apiName := sn + "-" + backstageOpenAPIType
+			entities = append(entities, newBackstageAPI(apiName, spec.Info.Title, backstageOpenAPIType, filepath.Base(spec.path), system, owner, lifecycle))
+			providedAPIs = append(providedAPIs, apiName)
+			newPathMapping, err := t.copyBackstageDefinition(spec.path, sn)
+			if err != nil {
+				return pathMappings, err
+			}
+			pathMappings = append(pathMappings, newPathMapping)
+		}
+		if protoPath := getServiceProtoFile(ir, service); protoPath != "" {
+			apiName := sn + "-" + backstageGRPCType
+			entities = append(entities, newBackstageAPI(apiName, "", backstageGRPCType, filepath.Base(protoPath), system, owner, lifecycle))
+			providedAPIs = append(providedAPIs, apiName)
+			newPathMapping, err := t.copyBackstageDefinition(protoPath, sn)
+			if err != nil {
+				return pathMappings, err
+			}
+			pathMappings = append(pathMappings, newPathMapping)
+		}
+		if len(providedAPIs) > 0 {
+			component.Spec["providesApis"] = providedAPIs
+		}
+		if dependencies := getBackstageDependencies(ir, sn, serviceNames); len(dependencies) > 0 {
+			component.Spec["dependsOn"] = dependencies
+		}
+		entities = append([]backstageEntity{component}, entities...)
+		catalogPath := filepath.Join(tempDest, sn, backstageCatalogFileName)
+		if err := writeBackstageEntities(catalogPath, entities); err != nil {
+			return pathMappings, err
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  catalogPath,
+			DestPath: filepath.Join(t.BackstageConfig.OutputPath, sn, backstageCatalogFileName),
+		})
+		targets = append(targets, "./"+sn+"/"+backstageCatalogFileName)
+	}
+	entities := []backstageEntity{{
+		APIVersion: backstageAPIVersion,
+		Kind:       "System",
+		Metadata:   backstageEntityMetadata{Name: system},
+		Spec:       map[string]interface{}{"owner": owner},
+	}}
+	externalServiceNames := []string{}
+	for name := range ir.ExternalServices {
+		externalServiceNames = append(externalServiceNames, name)
+	}
+	sort.Strings(externalServiceNames)
+	for _, name := range externalServiceNames {
+		externalService := ir.ExternalServices[name]
+		address := externalService.Host
+		if address == "" {
+			address = strings.Join(externalService.IPs, ", ")
+		}
+		entities = append(entities, backstageEntity{
+			APIVersion: backstageAPIVersion,
+			Kind:       "Resource",
+			Metadata:   backstageEntityMetadata{Name: name, Description: fmt.Sprintf("Dependency outside the cluster at %s", address)},
+			Spec:       map[string]interface{}{"type": backstageExternalServiceType, "owner": owner, "system": system},
+		})
+	}
+	entities = append(entities, backstageEntity{
+		APIVersion: backstageAPIVersion,
+		Kind:       "Location",
+		Metadata:   backstageEntityMetadata{Name: system + "-services"},
+		Spec:       map[string]interface{}{"targets": targets},
+	})
+	catalogPath := filepath.Join(tempDest, backstageCatalogFileName)
+	if err := writeBackstageEntities(catalogPath, entities); err != nil {
+		return pathMappings, err
+	}
+	pathMappings = append(pathMappings, transformertypes.PathMapping{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  catalogPath,
+		DestPath: filepath.Join(t.BackstageConfig.OutputPath, backstageCatalogFileName),
+	})
+	return pathMappings, nil
+}
+
+// newBackstageAPI returns an API whose definition is read from a file next to the catalog file
+func newBackstageAPI(name, description, apiType, definitionFileName, system, owner, lifecycle string) backstageEntity {
+	return backstageEntity{
+		APIVersion: backstageAPIVersion,
+		Kind:       "API",
+		Metadata:   backstageEntityMetadata{Name: name, Description: description},
+		Spec: map[string]interface{}{
+			"type":       apiType,
+			"lifecycle":  lifecycle,
+			"owner":      owner,
+			"system":     system,
+			"definition": map[string]string{"$text": "./" + definitionFileName},
+		},
+	}
+}
+
+// copyBackstageDefinition copies the definition of an API next to the catalog file of the service
+func (t *BackstageCatalogGenerator) copyBackstageDefinition(definitionPath, serviceName string) (transformertypes.PathMapping, error) {
+	destPath := filepath.Join(t.BackstageConfig.OutputPath, serviceName, filepath.Base(definitionPath))
+	tempPath := filepath.Join(t.Env.TempPath, destPath)
+	if err := os.MkdirAll(filepath.Dir(tempPath), common.DefaultDirectoryPermission); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to create the directory %s . Error: %q", filepath.Dir(tempPath), err)
+	}
+	if err := common.CopyFile(tempPath, definitionPath); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to copy the API definition at path %s to %s . Error: %q", definitionPath, tempPath, err)
+	}
+	return transformertypes.PathMapping{Type: transformertypes.DefaultPathMappingType, SrcPath: tempPath, DestPath: destPath}, nil
+}
+
+// getServiceProtoFile returns the first proto file in the build contexts of the images of the gRPC service, or an empty string if there is none
+func getServiceProtoFile(ir irtypes.IR, service irtypes.Service) string {
+	isGRPC := false
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		if forwarding.AppProtocol == irtypes.H2CAppProtocol {
+			isGRPC = true
+		}
+	}
+	if !isGRPC {
+		return ""
+	}
+	for _, container := range service.Containers {
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok || image.Build.ContextPath == "" {
+			continue
+		}
+		protoPaths, err := common.GetFilesByExt(image.Build.ContextPath, []string{protoExt})
+		if err != nil || len(protoPaths) == 0 {
+			continue
+		}
+		sort.Strings(protoPaths)
+		return protoPaths[0]
+	}
+	return ""
+}
+
+// getBackstageDependencies returns the references to the services that are mentioned in the environment variables of the service
+// and to the external services that the service is a client of
+func getBackstageDependencies(ir irtypes.IR, serviceName string, serviceNames []string) []string {
+	dependencies := []string{}
+	for _, sn := range getReferencedServices(ir, serviceName, serviceNames) {
+		dependencies = append(dependencies, "component:"+sn)
+	}
+	externalServiceNames := []string{}
+	for name, externalService := range ir.ExternalServices {
+		if common.IsStringPresent(externalService.Clients, serviceName) {
+			externalServiceNames = append(externalServiceNames, name)
+		}
+	}
+	sort.Strings(externalServiceNames)
+	for _, name := range externalServiceNames {
+		dependencies = append(dependencies, "resource:"+name)
+	}
+	return dependencies
+}
+
+// writeBackstageEntities writes the entities to a multi document yaml file
+func writeBackstageEntities(path string, entities []backstageEntity) error {
+	if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+	}
+	documents := []string{}
+	for _, entity := range entities {
+		entityBytes, err := common.ObjectToYamlBytes(entity)
+		if err != nil {
+			return fmt.Errorf("failed to convert the Backstage entity %+v to yaml. Error: %q", entity, err)
+		}
+		documents = append(documents, string(entityBytes))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(documents, "---\n")), common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the Backstage catalog to the file at path %s . Error: %q", path, err)
+	}
+	return nil
+}
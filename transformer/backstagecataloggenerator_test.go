@@ -0,0 +1,209 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestBackstageCatalogGeneratorTransform(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.backstage.system="Shop Platform"`,
+		`move2kube.backstage.owner="group:team-a"`,
+	}, nil, nil, false, false)
+
+	sourceDir := t.TempDir()
+	files := map[string]string{
+		"orders/openapi.yaml":         "openapi: 3.0.0\ninfo:\n  title: Orders API\npaths:\n  /orders: {}\n",
+		"greeter/proto/greeter.proto": "syntax = \"proto3\";\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(sourceDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	ir := irtypes.NewIR()
+	for _, sn := range []string{"orders", "greeter"} {
+		ir.ContainerImages[sn+":latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{ContextPath: filepath.Join(sourceDir, sn)}}
+		s := irtypes.NewServiceWithName(sn)
+		s.Containers = []core.Container{{Name: sn, Image: sn + ":latest"}}
+		ir.Services[sn] = s
+	}
+	orders := ir.Services["orders"]
+	orders.Containers[0].Env = []core.EnvVar{{Name: "GREETER_ADDR", Value: "greeter:50051"}}
+	ir.Services["orders"] = orders
+	greeter := ir.Services["greeter"]
+	greeter.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{AppProtocol: irtypes.H2CAppProtocol}}
+	ir.Services["greeter"] = greeter
+	ir.ExternalServices = map[string]irtypes.ExternalService{
+		"payments-db": {Name: "payments-db", Host: "db.example.com", Clients: []string{"orders"}},
+		"legacy-mq":   {Name: "legacy-mq", IPs: []string{"10.0.0.1", "10.0.0.2"}},
+	}
+
+	envInfo := environment.EnvInfo{Name: "test", ProjectName: "shop", Source: sourceDir, TempPath: t.TempDir()}
+	local, err := environment.NewLocal(envInfo, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	backstageGenerator := &BackstageCatalogGenerator{}
+	if err := backstageGenerator.Init(transformertypes.Transformer{}, &environment.Environment{EnvInfo: envInfo, Env: local}); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	artifact := transformertypes.Artifact{Type: irtypes.IRArtifactType, Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}}
+	pathMappings, _, err := backstageGenerator.Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the IR. Error: %q", err)
+	}
+
+	destPaths := []string{}
+	for _, pathMapping := range pathMappings {
+		destPaths = append(destPaths, pathMapping.DestPath)
+	}
+	expectedDestPaths := []string{
+		filepath.Join("deploy", "backstage", "greeter", "greeter.proto"),
+		filepath.Join("deploy", "backstage", "greeter", "catalog-info.yaml"),
+		filepath.Join("deploy", "backstage", "orders", "openapi.yaml"),
+		filepath.Join("deploy", "backstage", "orders", "catalog-info.yaml"),
+		filepath.Join("deploy", "backstage", "catalog-info.yaml"),
+	}
+	if diff := cmp.Diff(expectedDestPaths, destPaths); diff != "" {
+		t.Fatalf("wrong path mappings. Difference:\n%s", diff)
+	}
+
+	expectedCatalogs := map[string]string{
+		"catalog-info.yaml": `apiVersion: backstage.io/v1alpha1
+kind: System
+metadata:
+  name: shop-platform
+spec:
+  owner: group:team-a
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  name: legacy-mq
+  description: Dependency outside the cluster at 10.0.0.1, 10.0.0.2
+spec:
+  owner: group:team-a
+  system: shop-platform
+  type: external-service
+---
+apiVersion: backstage.io/v1alpha1
+kind: Resource
+metadata:
+  name: payments-db
+  description: Dependency outside the cluster at db.example.com
+spec:
+  owner: group:team-a
+  system: shop-platform
+  type: external-service
+---
+apiVersion: backstage.io/v1alpha1
+kind: Location
+metadata:
+  name: shop-platform-services
+spec:
+  targets:
+    - ./greeter/catalog-info.yaml
+    - ./orders/catalog-info.yaml
+`,
+		"greeter/catalog-info.yaml": `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: greeter
+  annotations:
+    backstage.io/kubernetes-label-selector: move2kube.konveyor.io/service=greeter
+spec:
+  lifecycle: production
+  owner: group:team-a
+  providesApis:
+    - greeter-grpc
+  system: shop-platform
+  type: service
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  name: greeter-grpc
+spec:
+  definition:
+    $text: ./greeter.proto
+  lifecycle: production
+  owner: group:team-a
+  system: shop-platform
+  type: grpc
+`,
+		"orders/catalog-info.yaml": `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: orders
+  annotations:
+    backstage.io/kubernetes-label-selector: move2kube.konveyor.io/service=orders
+spec:
+  dependsOn:
+    - component:greeter
+    - resource:payments-db
+  lifecycle: production
+  owner: group:team-a
+  providesApis:
+    - orders-openapi
+  system: shop-platform
+  type: service
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  name: orders-openapi
+  description: Orders API
+spec:
+  definition:
+    $text: ./openapi.yaml
+  lifecycle: production
+  owner: group:team-a
+  system: shop-platform
+  type: openapi
+`,
+	}
+	for path, expected := range expectedCatalogs {
+		actual, err := os.ReadFile(filepath.Join(envInfo.TempPath, "deploy", "backstage", path))
+		if err != nil {
+			t.Fatalf("failed to read the catalog %s . Error: %q", path, err)
+		}
+		if diff := cmp.Diff(expected, string(actual)); diff != "" {
+			t.Fatalf("wrong catalog %s . Difference:\n%s", path, diff)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(envInfo.TempPath, "deploy", "backstage", "orders", "openapi.yaml")); err != nil {
+		t.Fatalf("expected the OpenAPI spec to be copied next to the catalog. Error: %q", err)
+	}
+}
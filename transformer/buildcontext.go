@@ -0,0 +1,165 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+// buildContextOverride is the build context and Dockerfile of a service set in the plan
+type buildContextOverride struct {
+	contextPath    string
+	dockerfilePath string
+}
+
+var (
+	buildContextOverrides = map[string]buildContextOverride{} // [serviceName]
+	buildSourceDir        string
+	buildOutputDir        string
+)
+
+// setBuildContextOverrides stores the build contexts and Dockerfiles set in the plan, for the Dockerfiles created during the transformation
+func setBuildContextOverrides(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath string) {
+	buildContextOverrides = map[string]buildContextOverride{}
+	buildSourceDir = sourceDir
+	buildOutputDir = outputPath
+	for _, planArtifact := range planArtifacts {
+		override := buildContextOverride{}
+		if contextPaths := planArtifact.Paths[artifacts.DockerfileContextPathType]; len(contextPaths) > 0 {
+			override.contextPath = contextPaths[0]
+		}
+		if dockerfilePaths := planArtifact.Paths[artifacts.DockerfilePathType]; len(dockerfilePaths) > 0 {
+			override.dockerfilePath = dockerfilePaths[0]
+		}
+		if override.contextPath != "" || override.dockerfilePath != "" {
+			buildContextOverrides[planArtifact.ServiceName] = override
+		}
+	}
+}
+
+// overrideBuildContexts sets the build context and Dockerfile of the Dockerfile artifacts to the ones in the plan or the ones given by the user.
+// The paths are relative to the source directory. For Dockerfiles generated in the output directory, they are relative to the copy of the source directory in the output.
+func overrideBuildContexts(newArtifacts []transformertypes.Artifact) []transformertypes.Artifact {
+	for i, newArtifact := range newArtifacts {
+		if newArtifact.Type != artifacts.DockerfileArtifactType || len(newArtifact.Paths[artifacts.DockerfilePathType]) == 0 {
+			continue
+		}
+		serviceName := newArtifact.Name
+		serviceConfig := artifacts.ServiceConfig{}
+		if err := newArtifact.GetConfig(artifacts.ServiceConfigType, &serviceConfig); err == nil && serviceConfig.ServiceName != "" {
+			serviceName = serviceConfig.ServiceName
+		}
+		dockerfilePath := newArtifact.Paths[artifacts.DockerfilePathType][0]
+		contextPath := filepath.Dir(dockerfilePath)
+		if contextPaths := newArtifact.Paths[artifacts.DockerfileContextPathType]; len(contextPaths) > 0 {
+			contextPath = contextPaths[0]
+		}
+		// the paths of the generated Dockerfiles are relative to the output directory
+		relativeToOutput := !filepath.IsAbs(dockerfilePath)
+		if relativeToOutput {
+			dockerfilePath = filepath.Join(buildOutputDir, dockerfilePath)
+		}
+		if !filepath.IsAbs(contextPath) {
+			contextPath = filepath.Join(buildOutputDir, contextPath)
+		}
+		baseDir := buildSourceDir
+		if buildOutputDir != "" && common.IsParent(dockerfilePath, buildOutputDir) {
+			baseDir = filepath.Join(buildOutputDir, common.DefaultSourceDir)
+			// the context may have been copied from a service artifact in the source directory
+			if common.IsParent(contextPath, buildSourceDir) && !common.IsParent(contextPath, buildOutputDir) {
+				contextPath = rebaseBuildPath(contextPath, baseDir)
+			}
+		}
+		if baseDir == "" || !common.IsParent(dockerfilePath, baseDir) || !common.IsParent(contextPath, baseDir) {
+			continue
+		}
+		if override, ok := buildContextOverrides[serviceName]; ok {
+			if override.contextPath != "" {
+				contextPath = rebaseBuildPath(override.contextPath, baseDir)
+			}
+			if override.dockerfilePath != "" {
+				dockerfilePath = rebaseBuildPath(override.dockerfilePath, baseDir)
+			}
+		}
+		qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`)
+		relContextPath := getRelBuildPath(contextPath, baseDir)
+		relContextPath = qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, common.ConfigBuildContextKeySegment),
+			fmt.Sprintf("Enter the build context directory for the image of the service %s :", serviceName),
+			[]string{"The path is relative to the source directory", "The build scripts, CI pipelines and Kubernetes build resources use this directory as the build context"},
+			relContextPath,
+		)
+		relDockerfilePath := getRelBuildPath(dockerfilePath, baseDir)
+		relDockerfilePath = qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, common.ConfigDockerfileKeySegment),
+			fmt.Sprintf("Enter the path of the Dockerfile for the image of the service %s :", serviceName),
+			[]string{"The path is relative to the source directory"},
+			relDockerfilePath,
+		)
+		newContextPath := filepath.Join(baseDir, relContextPath)
+		newDockerfilePath := filepath.Join(baseDir, relDockerfilePath)
+		if !common.IsParent(newContextPath, baseDir) || !common.IsParent(newDockerfilePath, baseDir) {
+			logrus.Errorf("the build context %s and the Dockerfile %s of the service %s have to be inside the source directory. Ignoring them.", relContextPath, relDockerfilePath, serviceName)
+			continue
+		}
+		if newDockerfilePath != dockerfilePath {
+			if _, err := os.Stat(newDockerfilePath); err != nil {
+				logrus.Errorf("failed to find the Dockerfile %s of the service %s . Ignoring it. Error: %q", relDockerfilePath, serviceName, err)
+				newDockerfilePath = dockerfilePath
+			}
+		}
+		if !common.IsParent(newDockerfilePath, newContextPath) {
+			logrus.Warnf("the Dockerfile %s of the service %s is outside its build context %s", relDockerfilePath, serviceName, relContextPath)
+		}
+		if relativeToOutput {
+			newDockerfilePath = getRelBuildPath(newDockerfilePath, buildOutputDir)
+			newContextPath = getRelBuildPath(newContextPath, buildOutputDir)
+		}
+		newArtifact.Paths[artifacts.DockerfilePathType][0] = newDockerfilePath
+		newArtifact.Paths[artifacts.DockerfileContextPathType] = []string{newContextPath}
+		newArtifacts[i] = newArtifact
+	}
+	return newArtifacts
+}
+
+// rebaseBuildPath moves a path in the source directory to the same path in the base directory
+func rebaseBuildPath(path, baseDir string) string {
+	relPath, err := filepath.Rel(buildSourceDir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.Join(baseDir, relPath)
+}
+
+// getRelBuildPath returns the path relative to the base directory
+func getRelBuildPath(path, baseDir string) string {
+	relPath, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		logrus.Errorf("failed to make the path %s relative to the base path %s . Error: %q", path, baseDir, err)
+		return path
+	}
+	return relPath
+}
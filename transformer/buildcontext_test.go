@@ -0,0 +1,108 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+func TestOverrideBuildContexts(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.services."web".buildcontext="."`,
+		`move2kube.services."worker".buildcontext="../.."`,
+		`move2kube.services."jobs".dockerfile="jobs/Missing.Dockerfile"`,
+	}, nil, nil, false, false)
+	t.Cleanup(func() { setBuildContextOverrides(nil, "", "") })
+
+	sourceDir := t.TempDir()
+	outputDir := t.TempDir()
+	for _, path := range []string{
+		filepath.Join(sourceDir, "api", "Dockerfile"),
+		filepath.Join(sourceDir, "api", "docker", "Dockerfile.prod"),
+		filepath.Join(sourceDir, "worker", "Dockerfile"),
+		filepath.Join(sourceDir, "jobs", "Dockerfile"),
+		filepath.Join(outputDir, common.DefaultSourceDir, "web", "Dockerfile"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte("FROM scratch\n"), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	setBuildContextOverrides([]plantypes.PlanArtifact{{
+		ServiceName: "api",
+		Artifact: transformertypes.Artifact{Paths: map[transformertypes.PathType][]string{
+			artifacts.DockerfileContextPathType: {sourceDir},
+			artifacts.DockerfilePathType:        {filepath.Join(sourceDir, "api", "docker", "Dockerfile.prod")},
+		}},
+	}}, sourceDir, outputDir)
+
+	newDockerfileArtifact := func(name, dockerfilePath string) transformertypes.Artifact {
+		return transformertypes.Artifact{
+			Name:  name,
+			Type:  artifacts.DockerfileArtifactType,
+			Paths: map[transformertypes.PathType][]string{artifacts.DockerfilePathType: {dockerfilePath}},
+		}
+	}
+	newArtifacts := overrideBuildContexts([]transformertypes.Artifact{
+		newDockerfileArtifact("api", filepath.Join(sourceDir, "api", "Dockerfile")),
+		newDockerfileArtifact("web", filepath.Join(common.DefaultSourceDir, "web", "Dockerfile")),
+		newDockerfileArtifact("worker", filepath.Join(sourceDir, "worker", "Dockerfile")),
+		newDockerfileArtifact("jobs", filepath.Join(sourceDir, "jobs", "Dockerfile")),
+		{Name: "other", Type: artifacts.ServiceArtifactType},
+	})
+
+	expected := []map[transformertypes.PathType][]string{
+		// the plan overrides the build context and the Dockerfile
+		{
+			artifacts.DockerfilePathType:        {filepath.Join(sourceDir, "api", "docker", "Dockerfile.prod")},
+			artifacts.DockerfileContextPathType: {sourceDir},
+		},
+		// the paths of generated Dockerfiles stay relative to the output directory
+		{
+			artifacts.DockerfilePathType:        {filepath.Join(common.DefaultSourceDir, "web", "Dockerfile")},
+			artifacts.DockerfileContextPathType: {common.DefaultSourceDir},
+		},
+		// a build context outside the source directory is ignored
+		{
+			artifacts.DockerfilePathType: {filepath.Join(sourceDir, "worker", "Dockerfile")},
+		},
+		// a missing Dockerfile is ignored
+		{
+			artifacts.DockerfilePathType:        {filepath.Join(sourceDir, "jobs", "Dockerfile")},
+			artifacts.DockerfileContextPathType: {filepath.Join(sourceDir, "jobs")},
+		},
+		nil,
+	}
+	for i, newArtifact := range newArtifacts {
+		if diff := cmp.Diff(expected[i], newArtifact.Paths); diff != "" {
+			t.Fatalf("wrong paths for the artifact %s . Difference:\n%s", newArtifact.Name, diff)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"code.cloudfoundry.org/cli/util/manifest"
 	"github.com/cloudfoundry/bosh-cli/director/template"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/healthcheck"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/qaengine"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
@@ -43,6 +44,10 @@ import (
 const (
 	// ResourceRequestKey is the config key for resource requests
 	ResourceRequestKey = "ResourceRequest"
+	// cfHealthCheckTimeout is the default time in seconds that Cloud Foundry gives the applications to start
+	cfHealthCheckTimeout = 60
+	// cfStartupCheckPeriod is the time in seconds between the health checks of Cloud Foundry while the applications start
+	cfStartupCheckPeriod = 2
 )
 
 // variableLiteralPattern to identify variable literals in environment names
@@ -200,8 +205,10 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 			}
 			if cfinstanceapp.Application.Instances != 0 {
 				serviceConfig.Replicas = cfinstanceapp.Application.Instances
+				serviceConfig.ReplicasSource = "running instances collected from Cloud Foundry"
 			} else if application.Instances.IsSet {
 				serviceConfig.Replicas = application.Instances.Value
+				serviceConfig.ReplicasSource = "instances in the Cloud Foundry manifest"
 			}
 			secretName := config.ServiceName + common.VcapCfSecretSuffix
 			envList, vcapEnvMap := t.prioritizeAndAddEnvironmentVariables(cfinstanceapp, application.EnvironmentVariables,
@@ -218,6 +225,7 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 				servicePort := podPort
 				serviceConfig.AddPortForwarding(servicePort, podPort, "")
 			}
+			setCfHealthCheck(&serviceContainer, application, cfinstanceapp)
 			serviceConfig.Containers = []core.Container{serviceContainer}
 			ir.Services[sConfig.ServiceName] = serviceConfig
 		}
@@ -365,6 +373,55 @@ func flattenVcapServiceVariables(vcapService string, serviceName string) []core.
 	return flattenedEnvList
 }
 
+// setCfHealthCheck translates the health check of the application into the probes of the container.
+// The health check of the running instance takes precedence over the one in the manifest.
+func setCfHealthCheck(serviceContainer *core.Container, application manifest.Application, cfinstanceapp collecttypes.CfApp) {
+	healthCheckType := cfinstanceapp.Application.HealthCheckType
+	if healthCheckType == "" {
+		healthCheckType = application.HealthCheckType
+	}
+	endpoint := cfinstanceapp.Application.HealthCheckHttpEndpoint
+	if endpoint == "" {
+		endpoint = application.HealthCheckHTTPEndpoint
+	}
+	timeout := cfinstanceapp.Application.HealthCheckTimeout
+	if timeout == 0 {
+		timeout = int(application.HealthCheckTimeout)
+	}
+	if timeout == 0 {
+		timeout = cfHealthCheckTimeout
+	}
+	port := common.DefaultServicePort
+	if len(serviceContainer.Ports) > 0 {
+		port = serviceContainer.Ports[0].ContainerPort
+	}
+	var handler *core.ProbeHandler
+	switch healthCheckType {
+	case "http":
+		handler = healthcheck.GetHTTPHandler(endpoint, port)
+	case "port":
+		handler = healthcheck.GetTCPHandler(port)
+	case "":
+		// the port health check is the default, but the port of the application is not known
+		if len(serviceContainer.Ports) == 0 {
+			return
+		}
+		handler = healthcheck.GetTCPHandler(port)
+	default:
+		// the process and none health checks only check that the process is running, which Kubernetes already does
+		return
+	}
+	healthcheck.SetProbes(serviceContainer, &core.Probe{ProbeHandler: *handler})
+	if serviceContainer.StartupProbe == nil {
+		// the probes start after the application has started within the timeout of the health check
+		serviceContainer.StartupProbe = &core.Probe{
+			ProbeHandler:     *handler,
+			PeriodSeconds:    cfStartupCheckPeriod,
+			FailureThreshold: int32((timeout + cfStartupCheckPeriod - 1) / cfStartupCheckPeriod),
+		}
+	}
+}
+
 // readApplicationManifest reads an application manifest
 func (t *CloudFoundry) readApplicationManifest(path string, serviceName string) ([]manifest.Application, []string, error) { // manifest, parameters
 	trimmedvariables, err := getMissingVariables(path)
@@ -59,6 +59,7 @@ func (t *CNBContainerizer) Init(tc transformertypes.Transformer, env *environmen
 		Name:        tc.Name,
 		ProjectName: t.Env.GetProjectName(),
 		Source:      t.Env.GetEnvironmentSource(),
+		Ctx:         t.Env.Ctx,
 	}
 	t.CNBEnv, err = environment.NewEnvironment(envInfo, nil, environmenttypes.Container{
 		Image:      t.BuilderImageNameCfg.ImageName,
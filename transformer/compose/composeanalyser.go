@@ -40,6 +40,10 @@ const (
 	composeFilePathType transformertypes.PathType = "DockerCompose"
 	// imageInfoPathType defines the source artifact type of image info
 	imageInfoPathType transformertypes.PathType = "ImageInfo"
+	// NewComposeEngineFeatureGate is the feature gate of the redesigned compose loading
+	NewComposeEngineFeatureGate = "NewComposeEngine"
+	// composeSpecVersion is the version used to load Compose Specification files that do not have one
+	composeSpecVersion = "3.8"
 )
 
 // ComposeAnalyser implements Transformer interface
@@ -53,6 +57,13 @@ type ComposeConfig struct {
 	ServiceName string `yaml:"serviceName,omitempty"`
 }
 
+func init() {
+	common.RegisterFeatureGate(common.FeatureGate{Name: NewComposeEngineFeatureGate, Description: "Load Compose Specification files that do not have a version with the version 3 loader"})
+	if err := artifacts.RegisterConfigType(ComposeServiceConfigType, ComposeConfig{}, "Docker compose service details"); err != nil {
+		logrus.Errorf("failed to register the config type %s . Error: %q", ComposeServiceConfigType, err)
+	}
+}
+
 // Init Initializes the transformer
 func (t *ComposeAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
 	t.Config = tc
@@ -20,9 +20,12 @@ import (
 	"fmt"
 	"hash/fnv"
 	"os"
+	"path"
+	"regexp"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
 	core "k8s.io/kubernetes/pkg/apis/core"
 )
@@ -34,6 +37,11 @@ const (
 	envFile               string = "env_file"
 )
 
+var (
+	// nvidiaGPUDeviceRegex matches the devices of the individual GPUs, like /dev/nvidia0
+	nvidiaGPUDeviceRegex = regexp.MustCompile(`^/dev/nvidia[0-9]+$`)
+)
+
 /*
 // IsV3 returns if the docker-compose yaml is version 3
 func IsV3(path string) (bool, error) {
@@ -101,8 +109,36 @@ func isPath(substring string) bool {
 	return strings.Contains(substring, "/") || substring == "."
 }
 
+// getDockerVolumePath returns the path where docker stores the data of a named volume
+func getDockerVolumePath(volumeName string) string {
+	return path.Join("/var/lib/docker/volumes", volumeName, "_data")
+}
+
 func getHash(data []byte) uint64 {
 	hasher := fnv.New64a()
 	hasher.Write(data)
 	return hasher.Sum64()
 }
+
+// getDeviceRequirements returns the GPUs that a service needs from the devices mounted in its containers
+func getDeviceRequirements(devices []string) []irtypes.DeviceRequirement {
+	gpus := int64(0)
+	nvidiaDevices := []string{}
+	for _, device := range devices {
+		hostDevice := strings.SplitN(device, ":", 2)[0]
+		if !strings.HasPrefix(hostDevice, "/dev/nvidia") {
+			continue
+		}
+		nvidiaDevices = append(nvidiaDevices, hostDevice)
+		if nvidiaGPUDeviceRegex.MatchString(hostDevice) {
+			gpus++
+		}
+	}
+	if len(nvidiaDevices) == 0 {
+		return nil
+	}
+	if gpus == 0 {
+		gpus = 1
+	}
+	return []irtypes.DeviceRequirement{{Resource: irtypes.NvidiaGPUResource, Count: gpus, Source: "devices " + strings.Join(nvidiaDevices, ", ")}}
+}
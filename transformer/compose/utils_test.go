@@ -0,0 +1,58 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+func TestGetDeviceRequirements(t *testing.T) {
+	testCases := []struct {
+		name     string
+		devices  []string
+		expected []irtypes.DeviceRequirement
+	}{
+		{
+			name:    "count the mounted gpus",
+			devices: []string{"/dev/nvidia0:/dev/nvidia0", "/dev/nvidia1", "/dev/nvidiactl", "/dev/snd:/dev/snd"},
+			expected: []irtypes.DeviceRequirement{{
+				Resource: irtypes.NvidiaGPUResource,
+				Count:    2,
+				Source:   "devices /dev/nvidia0, /dev/nvidia1, /dev/nvidiactl",
+			}},
+		},
+		{
+			name:     "request a gpu when only the control devices are mounted",
+			devices:  []string{"/dev/nvidiactl", "/dev/nvidia-uvm:/dev/nvidia-uvm:rwm"},
+			expected: []irtypes.DeviceRequirement{{Resource: irtypes.NvidiaGPUResource, Count: 1, Source: "devices /dev/nvidiactl, /dev/nvidia-uvm"}},
+		},
+		{
+			name:    "ignore the other devices",
+			devices: []string{"/dev/ttyUSB0:/dev/ttyUSB0", "/dev/fuse"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, getDeviceRequirements(testCase.devices)); diff != "" {
+				t.Fatalf("wrong device requirements. Difference:\n%s", diff)
+			}
+		})
+	}
+}
@@ -245,6 +245,8 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 			serviceContainer.Resources.Limits = resourceLimit
 		}
 
+		serviceConfig.DeviceRequirements = getDeviceRequirements(composeServiceConfig.Devices)
+
 		restart := composeServiceConfig.Restart
 		if restart == "unless-stopped" {
 			logrus.Warnf("Restart policy 'unless-stopped' in service %s is not supported, convert it to 'always'", name)
@@ -256,6 +258,9 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 				if value.Name != "default" {
 					serviceConfig.Networks = append(serviceConfig.Networks, value.RealName)
 				}
+				for _, alias := range value.Aliases {
+					serviceConfig.NetworkAliases = common.AppendIfNotPresent(serviceConfig.NetworkAliases, common.NormalizeForMetadataName(alias))
+				}
 			}
 		}
 
@@ -314,7 +319,7 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 					if vol.AccessMode == modeReadOnly {
 						accessMode = core.ReadOnlyMany
 					}
-					storageObj := irtypes.Storage{StorageType: irtypes.PVCKind, Name: vol.Source, Content: nil}
+					storageObj := irtypes.Storage{StorageType: irtypes.PVCKind, Name: vol.Source, Content: nil, SourcePath: getDockerVolumePath(vol.Source)}
 					storageObj.PersistentVolumeClaimSpec = core.PersistentVolumeClaimSpec{
 						AccessModes: []core.PersistentVolumeAccessMode{accessMode},
 					}
@@ -20,17 +20,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/docker/cli/cli/compose/loader"
 	"github.com/docker/cli/cli/compose/types"
 	libcomposeyaml "github.com/docker/libcompose/yaml"
 	"github.com/google/go-cmp/cmp"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/healthcheck"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/konveyor/move2kube/types/qaengine/commonqa"
-	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -103,6 +103,10 @@ func parseV3(path string) (*types.Config, error) {
 		logrus.Debug(err)
 		return nil, err
 	}
+	if _, ok := parsedComposeFile["version"]; !ok && common.IsFeatureEnabled(NewComposeEngineFeatureGate) {
+		logrus.Debugf("the compose file at path %s does not have a version. Loading it as version %s", path, composeSpecVersion)
+		parsedComposeFile["version"] = composeSpecVersion
+	}
 	parsedComposeFile = removeNonExistentEnvFilesV3(path, parsedComposeFile)
 	// Config details
 	configDetails := types.ConfigDetails{
@@ -242,7 +246,11 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 			serviceConfig.Daemon = true
 		}
 
-		serviceConfig.Networks = c.getNetworks(composeServiceConfig, composeObject)
+		serviceConfig.Networks, serviceConfig.NetworkAliases, serviceConfig.Internal = c.getNetworks(composeServiceConfig, composeObject)
+		if serviceConfig.Internal {
+			// the containers on internal networks cannot reach the hosts outside them
+			serviceConfig.EgressPolicy = true
+		}
 
 		if (composeServiceConfig.Deploy.Resources != types.Resources{}) {
 			if composeServiceConfig.Deploy.Resources.Limits != nil {
@@ -282,6 +290,19 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 				serviceContainer.Resources.Requests = resourceRequests
 			}
 		}
+		serviceConfig.DeviceRequirements = getDeviceRequirements(composeServiceConfig.Devices)
+		if composeServiceConfig.Deploy.Resources.Reservations != nil {
+			for _, genericResource := range composeServiceConfig.Deploy.Resources.Reservations.GenericResources {
+				if genericResource.DiscreteResourceSpec == nil || !strings.Contains(strings.ToLower(genericResource.DiscreteResourceSpec.Kind), "gpu") || len(serviceConfig.DeviceRequirements) > 0 {
+					continue
+				}
+				serviceConfig.DeviceRequirements = append(serviceConfig.DeviceRequirements, irtypes.DeviceRequirement{
+					Resource: irtypes.NvidiaGPUResource,
+					Count:    genericResource.DiscreteResourceSpec.Value,
+					Source:   "generic resource " + genericResource.DiscreteResourceSpec.Kind,
+				})
+			}
+		}
 
 		// HealthCheck
 		if composeServiceConfig.HealthCheck != nil && !composeServiceConfig.HealthCheck.Disable {
@@ -289,7 +310,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 			if err != nil {
 				logrus.Warnf("Unable to parse health check : %s", err)
 			} else {
-				serviceContainer.LivenessProbe = &probe
+				healthcheck.SetProbes(&serviceContainer, probe)
 			}
 		}
 		restart := composeServiceConfig.Restart
@@ -303,6 +324,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		// replicas:
 		if composeServiceConfig.Deploy.Replicas != nil {
 			serviceConfig.Replicas = int(*composeServiceConfig.Deploy.Replicas)
+			serviceConfig.ReplicasSource = "deploy.replicas in the docker compose file"
 		}
 		serviceContainer.Env = c.getEnvs(composeServiceConfig)
 
@@ -435,6 +457,9 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 					},
 				})
 				storageObj := irtypes.Storage{StorageType: irtypes.PVCKind, Name: volumeName, Content: nil}
+				if vol.Source != "" {
+					storageObj.SourcePath = getDockerVolumePath(vol.Source)
+				}
 				ir.AddStorage(storageObj)
 			}
 		}
@@ -576,57 +601,48 @@ func (*v3Loader) addPorts(ports []types.ServicePortConfig, expose []string, serv
 	}
 }
 
-func (c *v3Loader) getNetworks(composeServiceConfig types.ServiceConfig, composeObject types.Config) (networks []string) {
+// getNetworks returns the networks of the service and its aliases on them.
+// The service is internal if all its networks are internal.
+func (c *v3Loader) getNetworks(composeServiceConfig types.ServiceConfig, composeObject types.Config) (networks []string, aliases []string, internal bool) {
 	networks = []string{}
-	for key := range composeServiceConfig.Networks {
+	aliases = []string{}
+	internal = len(composeServiceConfig.Networks) > 0
+	for key, serviceNetwork := range composeServiceConfig.Networks {
 		netName := composeObject.Networks[key].Name
 		if netName == "" {
 			netName = key
 		}
 		networks = append(networks, netName)
+		if !composeObject.Networks[key].Internal {
+			internal = false
+		}
+		if serviceNetwork == nil {
+			continue
+		}
+		for _, alias := range serviceNetwork.Aliases {
+			aliases = common.AppendIfNotPresent(aliases, common.NormalizeForMetadataName(alias))
+		}
 	}
-	return networks
+	sort.Strings(networks)
+	return networks, aliases, internal
 }
 
-func (c *v3Loader) getHealthCheck(composeHealthCheck types.HealthCheckConfig) (core.Probe, error) {
-	probe := core.Probe{}
-
-	if len(composeHealthCheck.Test) > 1 {
-		probe.ProbeHandler = core.ProbeHandler{
-			Exec: &core.ExecAction{
-				// docker/cli adds "CMD-SHELL" to the struct, hence we remove the first element of composeHealthCheck.Test
-				Command: composeHealthCheck.Test[1:],
-			},
-		}
-	} else {
-		logrus.Warnf("Could not find command to execute in probe : %s", composeHealthCheck.Test)
-	}
+// getHealthCheck translates the health check of the compose service into a probe
+func (c *v3Loader) getHealthCheck(composeHealthCheck types.HealthCheckConfig) (*core.Probe, error) {
+	check := healthcheck.Check{Test: composeHealthCheck.Test}
 	if composeHealthCheck.Timeout != nil {
-		parse, err := time.ParseDuration(composeHealthCheck.Timeout.String())
-		if err != nil {
-			return probe, errors.Wrap(err, "unable to parse health check timeout variable")
-		}
-		probe.TimeoutSeconds = int32(parse.Seconds())
+		check.Timeout = composeHealthCheck.Timeout.String()
 	}
 	if composeHealthCheck.Interval != nil {
-		parse, err := time.ParseDuration(composeHealthCheck.Interval.String())
-		if err != nil {
-			return probe, errors.Wrap(err, "unable to parse health check interval variable")
-		}
-		probe.PeriodSeconds = int32(parse.Seconds())
+		check.Interval = composeHealthCheck.Interval.String()
 	}
 	if composeHealthCheck.Retries != nil {
-		probe.FailureThreshold = int32(*composeHealthCheck.Retries)
+		check.Retries = int(*composeHealthCheck.Retries)
 	}
 	if composeHealthCheck.StartPeriod != nil {
-		parse, err := time.ParseDuration(composeHealthCheck.StartPeriod.String())
-		if err != nil {
-			return probe, errors.Wrap(err, "unable to parse health check startPeriod variable")
-		}
-		probe.InitialDelaySeconds = int32(parse.Seconds())
+		check.StartPeriod = composeHealthCheck.StartPeriod.String()
 	}
-
-	return probe, nil
+	return healthcheck.GetProbe(check)
 }
 
 func (c *v3Loader) getEnvs(composeServiceConfig types.ServiceConfig) (envs []core.EnvVar) {
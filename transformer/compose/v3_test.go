@@ -0,0 +1,104 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+func TestParseV3VersionlessFile(t *testing.T) {
+	composePath := filepath.Join(t.TempDir(), "compose.yaml")
+	if err := os.WriteFile(composePath, []byte("services:\n  web:\n    image: nginx:1.21\n    ports:\n      - \"8080:80\"\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	t.Cleanup(func() { common.SetFeatureGates([]string{NewComposeEngineFeatureGate + "=false"}) })
+
+	if _, err := parseV3(composePath); err == nil {
+		t.Fatalf("expected an error for a compose file without a version when the %s feature gate is disabled", NewComposeEngineFeatureGate)
+	}
+	if err := common.SetFeatureGates([]string{NewComposeEngineFeatureGate + "=true"}); err != nil {
+		t.Fatalf("failed to set the feature gates. Error: %q", err)
+	}
+	config, err := parseV3(composePath)
+	if err != nil {
+		t.Fatalf("failed to parse the compose file without a version. Error: %q", err)
+	}
+	if len(config.Services) != 1 || config.Services[0].Name != "web" || config.Services[0].Image != "nginx:1.21" {
+		t.Fatalf("wrong services in the compose file. Actual: %+v", config.Services)
+	}
+}
+
+func TestV3ConvertToIRDeviceRequirements(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", nil, nil, nil, false, false)
+	composePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	compose := `version: "3.8"
+services:
+  trainer:
+    image: pytorch/pytorch:1.12.0-cuda11.3-cudnn8-runtime
+    devices:
+      - /dev/nvidia0:/dev/nvidia0
+      - /dev/nvidiactl:/dev/nvidiactl
+    deploy:
+      resources:
+        reservations:
+          generic_resources:
+            - discrete_resource_spec:
+                kind: NVIDIA-GPU
+                value: 4
+  inference:
+    image: nvcr.io/nvidia/tritonserver:22.06-py3
+    deploy:
+      resources:
+        reservations:
+          generic_resources:
+            - discrete_resource_spec:
+                kind: SSD
+                value: 1
+            - discrete_resource_spec:
+                kind: NVIDIA-GPU
+                value: 2
+  web:
+    image: nginx:1.21
+`
+	if err := os.WriteFile(composePath, []byte(compose), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	expected := map[string][]irtypes.DeviceRequirement{
+		// the mounted devices take precedence over the generic resources
+		"trainer":   {{Resource: irtypes.NvidiaGPUResource, Count: 1, Source: "devices /dev/nvidia0, /dev/nvidiactl"}},
+		"inference": {{Resource: irtypes.NvidiaGPUResource, Count: 2, Source: "generic resource NVIDIA-GPU"}},
+		"web":       nil,
+	}
+	for serviceName, expectedDeviceRequirements := range expected {
+		ir, err := new(v3Loader).ConvertToIR(composePath, serviceName)
+		if err != nil {
+			t.Fatalf("failed to convert the compose service %s to IR. Error: %q", serviceName, err)
+		}
+		if diff := cmp.Diff(expectedDeviceRequirements, ir.Services[serviceName].DeviceRequirements); diff != "" {
+			t.Fatalf("wrong device requirements for the service %s . Difference:\n%s", serviceName, diff)
+		}
+	}
+}
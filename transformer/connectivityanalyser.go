@@ -0,0 +1,348 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultConnectivityDir         = "assessment"
+	connectivityReportFileName     = "connectivity.md"
+	connectivityInventoryFileName  = "connectivity.yaml"
+	maxConnectivityScanFileSize    = 1024 * 1024
+	soapEndpointKind               = "soap"
+	wcfEndpointKind                = "wcf"
+	jmsEndpointKind                = "jms"
+	ibmMQEndpointKind              = "ibmmq"
+	amqpEndpointKind               = "amqp"
+	ejbEndpointKind                = "ejb"
+	ejbRemoteInterfaceEndpointKind = "ejbremoteinterface"
+)
+
+// connectivityRule finds the endpoints of one kind. The first group of the pattern is the URL,
+// and the optional named groups host and port are the host and the port of the endpoint.
+type connectivityRule struct {
+	kind    string
+	pattern *regexp.Regexp
+	// exts are the extensions of the files in which the pattern is searched, all the scanned files if it is empty
+	exts []string
+}
+
+var (
+	connectivityScanExts = []string{".xml", ".wsdl", ".config", ".properties", ".yaml", ".yml", ".json", ".conf", ".ini", ".env", ".java", ".cs", ".vb", ".py", ".js", ".ts", ".go", ".rb", ".php"}
+	connectivityRules    = []connectivityRule{
+		{kind: soapEndpointKind, pattern: regexp.MustCompile(`(?i)<(?:soap|soap12|wsdlsoap):address\s+location\s*=\s*["']((?P<scheme>https?)://(?P<host>[A-Za-z0-9.-]+)(?::(?P<port>\d+))?[^"']*)["']`), exts: []string{".wsdl", ".xml"}},
+		{kind: wcfEndpointKind, pattern: regexp.MustCompile(`(?i)<endpoint\b[^>]*\baddress\s*=\s*["']((?P<scheme>https?|net\.tcp)://(?P<host>[A-Za-z0-9.-]+)(?::(?P<port>\d+))?[^"']*)["']`), exts: []string{".config", ".xml"}},
+		{kind: soapEndpointKind, pattern: regexp.MustCompile(`(?i)["'=\s]((?P<scheme>https?)://(?P<host>[A-Za-z0-9.-]+)(?::(?P<port>\d+))?/[^"'\s<>]*(?:\?wsdl|\.svc|\.asmx)[^"'\s<>]*)`)},
+		{kind: jmsEndpointKind, pattern: regexp.MustCompile(`(?i)\b((?P<scheme>tcp|ssl|nio|stomp)://(?P<host>[A-Za-z0-9.-]+):(?P<port>\d+)[^"'\s<>,)]*)`)},
+		{kind: amqpEndpointKind, pattern: regexp.MustCompile(`(?i)\b((?P<scheme>amqps?)://(?:[^@/\s"']+@)?(?P<host>[A-Za-z0-9.-]+)(?::(?P<port>\d+))?[^"'\s<>]*)`)},
+		{kind: ibmMQEndpointKind, pattern: regexp.MustCompile(`(?i)conn(?:ection)?[._-]?name\s*[=:]\s*["']?((?P<host>[A-Za-z0-9.-]+)\((?P<port>\d+)\))`)},
+		{kind: jmsEndpointKind, pattern: regexp.MustCompile(`\b((?:java:(?:comp/env/)?)?jms/[A-Za-z0-9_./-]*(?:ConnectionFactory|CF)[A-Za-z0-9_]*)`)},
+		{kind: ejbEndpointKind, pattern: regexp.MustCompile(`(?i)\b((?P<scheme>iiops?|t3s?|remote\+https?|https?-remoting|corbaloc:iiop|corbaloc:)::?/?/?(?:1\.\d@)?(?P<host>[A-Za-z0-9.-]+):(?P<port>\d+)[^"'\s<>]*)`)},
+	}
+	ejbRemoteInterfaceRegex = regexp.MustCompile(`@Remote\b(?:\([^)]*\))?\s+(?:public\s+)?interface\s+([A-Za-z0-9_]+)`)
+	defaultSchemePorts      = map[string]int32{"http": 80, "https": 443, "amqp": 5672, "amqps": 5671}
+	localHosts              = []string{"localhost", "127.0.0.1", "0.0.0.0"}
+)
+
+// ConnectivityAnalyser implements Transformer interface
+type ConnectivityAnalyser struct {
+	Config             transformertypes.Transformer
+	Env                *environment.Environment
+	ConnectivityConfig *ConnectivityYamlConfig
+}
+
+// ConnectivityYamlConfig stores the yaml configuration for the connectivity transformer
+type ConnectivityYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+}
+
+// Init Initializes the transformer
+func (t *ConnectivityAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.ConnectivityConfig = &ConnectivityYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.ConnectivityConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.ConnectivityConfig, err)
+		return err
+	}
+	if t.ConnectivityConfig.OutputPath == "" {
+		t.ConnectivityConfig.OutputPath = defaultConnectivityDir
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *ConnectivityAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *ConnectivityAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform finds the SOAP endpoints, message brokers and remote EJBs that the services connect to.
+// They are added to the IR for the generators of the network policies and the external services, and are written to an inventory and a report.
+func (t *ConnectivityAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		serviceNames := []string{}
+		for sn := range ir.Services {
+			serviceNames = append(serviceNames, sn)
+		}
+		sort.Strings(serviceNames)
+		inventory := map[string][]irtypes.ExternalEndpoint{}
+		for _, sn := range serviceNames {
+			s := ir.Services[sn]
+			for _, endpoint := range findExternalEndpoints(ir, s) {
+				if !common.IsPresent(s.ExternalEndpoints, endpoint) {
+					s.ExternalEndpoints = append(s.ExternalEndpoints, endpoint)
+				}
+			}
+			if len(s.ExternalEndpoints) == 0 {
+				continue
+			}
+			inventory[sn] = s.ExternalEndpoints
+			if len(getExternalEndpointPorts(s.ExternalEndpoints)) > 0 {
+				s.EgressPolicy = qaengine.FetchBoolAnswer(
+					common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`, common.ConfigEgressPolicyKeySegment),
+					fmt.Sprintf("Should the egress of the service %s be restricted to the cluster and the %d external endpoints it connects to?", sn, len(s.ExternalEndpoints)),
+					[]string{"A network policy allows connections to the pods in the namespace, to DNS and to the ports of the endpoints found in the sources. Endpoints that were not found will be blocked."},
+					false,
+				)
+			}
+			ir.Services[sn] = s
+		}
+		if len(inventory) > 0 {
+			newPathMappings, err := t.writeConnectivityInventory(inventory)
+			if err != nil {
+				logrus.Errorf("failed to write the connectivity inventory. Error: %q", err)
+			}
+			pathMappings = append(pathMappings, newPathMappings...)
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return pathMappings, artifactsCreated, nil
+}
+
+// writeConnectivityInventory writes the endpoints of the services to a yaml inventory and a markdown report
+func (t *ConnectivityAnalyser) writeConnectivityInventory(inventory map[string][]irtypes.ExternalEndpoint) ([]transformertypes.PathMapping, error) {
+	tempDest := filepath.Join(t.Env.TempPath, t.ConnectivityConfig.OutputPath)
+	if err := os.MkdirAll(tempDest, common.DefaultDirectoryPermission); err != nil {
+		return nil, fmt.Errorf("failed to create the directory %s . Error: %q", tempDest, err)
+	}
+	inventoryPath := filepath.Join(tempDest, connectivityInventoryFileName)
+	if err := common.WriteYaml(inventoryPath, map[string]interface{}{"services": inventory}); err != nil {
+		return nil, fmt.Errorf("failed to write the connectivity inventory to the file at path %s . Error: %q", inventoryPath, err)
+	}
+	report := strings.Builder{}
+	report.WriteString("# Connectivity inventory\n\n")
+	report.WriteString("The services connect to these SOAP and WCF endpoints, message brokers and remote EJBs. They have to be reachable from the cluster, " +
+		"either by migrating them too or by allowing the egress to them.\n")
+	serviceNames := []string{}
+	for sn := range inventory {
+		serviceNames = append(serviceNames, sn)
+	}
+	sort.Strings(serviceNames)
+	for _, sn := range serviceNames {
+		report.WriteString(fmt.Sprintf("\n## %s\n\n| Kind | Endpoint | Source |\n| --- | --- | --- |\n", sn))
+		for _, endpoint := range inventory[sn] {
+			location := endpoint.URL
+			if endpoint.Kind == ejbRemoteInterfaceEndpointKind {
+				location = "remote interface " + endpoint.URL + " exposed to remote EJB clients"
+			} else if endpoint.Host != "" && endpoint.Port != 0 && !strings.Contains(endpoint.URL, endpoint.Host) {
+				location = fmt.Sprintf("%s (%s:%d)", endpoint.URL, endpoint.Host, endpoint.Port)
+			}
+			report.WriteString(fmt.Sprintf("| %s | %s | %s |\n", endpoint.Kind, location, endpoint.Source))
+		}
+	}
+	reportPath := filepath.Join(tempDest, connectivityReportFileName)
+	if err := os.WriteFile(reportPath, []byte(report.String()), common.DefaultFilePermission); err != nil {
+		return nil, fmt.Errorf("failed to write the connectivity report to the file at path %s . Error: %q", reportPath, err)
+	}
+	pathMappings := []transformertypes.PathMapping{}
+	for _, path := range []string{inventoryPath, reportPath} {
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  path,
+			DestPath: filepath.Join(t.ConnectivityConfig.OutputPath, filepath.Base(path)),
+		})
+	}
+	return pathMappings, nil
+}
+
+// findExternalEndpoints scans the build contexts of the images and the environment variables of the containers of the service for endpoints
+func findExternalEndpoints(ir irtypes.IR, service irtypes.Service) []irtypes.ExternalEndpoint {
+	endpoints := []irtypes.ExternalEndpoint{}
+	add := func(newEndpoints []irtypes.ExternalEndpoint) {
+		for _, endpoint := range newEndpoints {
+			duplicate := false
+			for _, existing := range endpoints {
+				if existing.Kind == endpoint.Kind && existing.URL == endpoint.URL {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+	}
+	contextPaths := []string{}
+	for _, container := range service.Containers {
+		for _, envVar := range container.Env {
+			add(findExternalEndpointsInLine(envVar.Name+"="+envVar.Value, "", fmt.Sprintf("environment variable %s of the container %s", envVar.Name, container.Name)))
+		}
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok || image.Build.ContextPath == "" || common.IsStringPresent(contextPaths, image.Build.ContextPath) {
+			continue
+		}
+		contextPaths = append(contextPaths, image.Build.ContextPath)
+	}
+	for _, contextPath := range contextPaths {
+		paths, err := common.GetFilesByExt(contextPath, connectivityScanExts)
+		if err != nil {
+			logrus.Debugf("failed to list the files in the build context %s . Error: %q", contextPath, err)
+			continue
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			add(findExternalEndpointsInFile(contextPath, path))
+		}
+	}
+	return endpoints
+}
+
+func findExternalEndpointsInFile(contextPath, path string) []irtypes.ExternalEndpoint {
+	if info, err := os.Stat(path); err != nil || info.Size() > maxConnectivityScanFileSize {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		logrus.Debugf("failed to open the file at path %s . Error: %q", path, err)
+		return nil
+	}
+	defer file.Close()
+	relPath, err := filepath.Rel(contextPath, path)
+	if err != nil {
+		relPath = path
+	}
+	endpoints := []irtypes.ExternalEndpoint{}
+	if strings.ToLower(filepath.Ext(path)) == ".java" {
+		endpoints = append(endpoints, findEJBRemoteInterfaces(path, relPath)...)
+	}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		endpoints = append(endpoints, findExternalEndpointsInLine(scanner.Text(), strings.ToLower(filepath.Ext(path)), fmt.Sprintf("%s:%d", relPath, lineNumber))...)
+	}
+	return endpoints
+}
+
+// findEJBRemoteInterfaces returns the remote interfaces of the EJBs in the java file, which remote EJB clients connect to
+func findEJBRemoteInterfaces(path, relPath string) []irtypes.ExternalEndpoint {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		logrus.Debugf("failed to read the file at path %s . Error: %q", path, err)
+		return nil
+	}
+	endpoints := []irtypes.ExternalEndpoint{}
+	for _, match := range ejbRemoteInterfaceRegex.FindAllSubmatchIndex(contents, -1) {
+		endpoints = append(endpoints, irtypes.ExternalEndpoint{
+			Kind:   ejbRemoteInterfaceEndpointKind,
+			URL:    string(contents[match[2]:match[3]]),
+			Source: fmt.Sprintf("%s:%d", relPath, strings.Count(string(contents[:match[0]]), "\n")+1),
+		})
+	}
+	return endpoints
+}
+
+// findExternalEndpointsInLine returns the endpoints in the line. Endpoints on the local host are skipped, since they are not external.
+func findExternalEndpointsInLine(line, ext, source string) []irtypes.ExternalEndpoint {
+	endpoints := []irtypes.ExternalEndpoint{}
+	for _, rule := range connectivityRules {
+		if ext != "" && len(rule.exts) > 0 && !common.IsStringPresent(rule.exts, ext) {
+			continue
+		}
+		if ext == "" && len(rule.exts) > 0 {
+			continue
+		}
+		for _, match := range rule.pattern.FindAllStringSubmatch(line, -1) {
+			endpoint := irtypes.ExternalEndpoint{Kind: rule.kind, URL: match[1], Source: source}
+			scheme := ""
+			for i, name := range rule.pattern.SubexpNames() {
+				switch name {
+				case "scheme":
+					scheme = strings.ToLower(match[i])
+				case "host":
+					endpoint.Host = match[i]
+				case "port":
+					if port, err := strconv.ParseInt(match[i], 10, 32); err == nil {
+						endpoint.Port = int32(port)
+					}
+				}
+			}
+			if endpoint.URL == "" {
+				continue
+			}
+			if endpoint.Host != "" && common.IsStringPresent(localHosts, strings.ToLower(endpoint.Host)) {
+				continue
+			}
+			if endpoint.Host != "" && endpoint.Port == 0 {
+				endpoint.Port = defaultSchemePorts[scheme]
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// getExternalEndpointPorts returns the ports of the endpoints in sorted order
+func getExternalEndpointPorts(endpoints []irtypes.ExternalEndpoint) []int32 {
+	ports := []int32{}
+	for _, endpoint := range endpoints {
+		if endpoint.Port != 0 && !common.IsPresent(ports, endpoint.Port) {
+			ports = append(ports, endpoint.Port)
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	return ports
+}
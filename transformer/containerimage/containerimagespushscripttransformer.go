@@ -17,10 +17,12 @@
 package containerimage
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/scripts"
 	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
@@ -31,6 +33,13 @@ const (
 	pushImagesFileName = "pushimages"
 )
 
+// pushScriptPathTypes are the path types used for the script in each script format
+var pushScriptPathTypes = map[string]transformertypes.PathType{
+	common.ShExt:  artifacts.ContainerImagesPushShScriptPathType,
+	common.BatExt: artifacts.ContainerImagesPushBatScriptPathType,
+	common.Ps1Ext: artifacts.ContainerImagesPushPs1ScriptPathType,
+}
+
 // ContainerImagesPushScript implements Transformer interface
 type ContainerImagesPushScript struct {
 	Config transformertypes.Transformer
@@ -75,7 +84,9 @@ func (t *ContainerImagesPushScript) Transform(newArtifacts []transformertypes.Ar
 		if err != nil {
 			logrus.Errorf("Unable to read Image config : %s", err)
 		}
-		ipt.Images = common.MergeSlices(ipt.Images, images.ImageNames)
+		for _, imageName := range images.ImageNames {
+			ipt.Images = common.AppendIfNotPresent(ipt.Images, common.TagNewImageName(imageName))
+		}
 	}
 	if len(ipt.Images) == 0 {
 		return nil, nil, nil
@@ -83,17 +94,22 @@ func (t *ContainerImagesPushScript) Transform(newArtifacts []transformertypes.Ar
 	ipt.RegistryURL = commonqa.ImageRegistry()
 	ipt.RegistryNamespace = commonqa.ImageRegistryNamespace()
 	ipt.ContainerRuntime = commonqa.GetContainerRuntime()
-	pathMappings = append(pathMappings, transformertypes.PathMapping{
-		Type:           transformertypes.TemplatePathMappingType,
-		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
-		DestPath:       common.ScriptsDir,
-		TemplateConfig: ipt,
-	})
+	selectedFormats := scripts.GetSelectedFormats()
+	scriptPathMappings, err := scripts.GetPathMappings(filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir), common.ScriptsDir, ipt, selectedFormats)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create the path mappings for the image push scripts. Error: %q", err)
+	}
+	pathMappings = append(pathMappings, scriptPathMappings...)
+	scriptPaths := map[transformertypes.PathType][]string{}
+	for _, format := range selectedFormats {
+		if pathType, ok := pushScriptPathTypes[format.Extension]; ok {
+			scriptPaths[pathType] = []string{filepath.Join(common.ScriptsDir, pushImagesFileName+format.Extension)}
+		}
+	}
 	artifacts := []transformertypes.Artifact{{
-		Name: string(artifacts.ContainerImagesPushScriptArtifactType),
-		Type: artifacts.ContainerImagesPushScriptArtifactType,
-		Paths: map[transformertypes.PathType][]string{artifacts.ContainerImagesPushShScriptPathType: {filepath.Join(common.ScriptsDir, pushImagesFileName+common.ShExt)},
-			artifacts.ContainerImagesPushBatScriptPathType: {filepath.Join(common.ScriptsDir, pushImagesFileName+common.ShExt)}},
+		Name:  string(artifacts.ContainerImagesPushScriptArtifactType),
+		Type:  artifacts.ContainerImagesPushScriptArtifactType,
+		Paths: scriptPaths,
 	}}
 	return pathMappings, artifacts, nil
 }
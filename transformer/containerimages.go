@@ -0,0 +1,84 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/external"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// GetContainerImages returns the container images that the transformers need to run.
+// Images that are built from a Dockerfile of the transformer are not included, since they cannot be pulled.
+func GetContainerImages(transformerPaths map[string]string, selector labels.Selector) []string {
+	images := []string{}
+	for _, tc := range getFilteredTransformers(transformerPaths, selector, false) {
+		if image := getContainerImage(tc); image != "" {
+			images = common.AppendIfNotPresent(images, image)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// GetContainerImagePlatforms returns the platforms that the transformers have specified for their container images
+func GetContainerImagePlatforms(transformerPaths map[string]string, selector labels.Selector) map[string]string {
+	platforms := map[string]string{}
+	for _, tc := range getFilteredTransformers(transformerPaths, selector, false) {
+		if transformerTypes[tc.Spec.Class] != reflect.TypeOf(external.Executable{}) {
+			continue
+		}
+		execConfig := external.ExecutableYamlConfig{}
+		if err := common.GetObjFromInterface(tc.Spec.Config, &execConfig); err != nil {
+			continue
+		}
+		if image := getContainerImage(tc); image != "" && execConfig.Container.Platform != "" {
+			platforms[image] = execConfig.Container.Platform
+		}
+	}
+	return platforms
+}
+
+// getContainerImage returns the container image used by the transformer, or an empty string if it does not use one
+func getContainerImage(tc transformertypes.Transformer) string {
+	switch transformerTypes[tc.Spec.Class] {
+	case reflect.TypeOf(external.Executable{}):
+		execConfig := external.ExecutableYamlConfig{}
+		if err := common.GetObjFromInterface(tc.Spec.Config, &execConfig); err != nil {
+			logrus.Debugf("unable to load config for Transformer %+v into %T : %s", tc.Spec.Config, execConfig, err)
+			return ""
+		}
+		if execConfig.Container.ContainerBuild.Dockerfile != "" || execConfig.Container.ContainerBuild.Context != "" {
+			return ""
+		}
+		return execConfig.Container.Image
+	case reflect.TypeOf(CNBContainerizer{}):
+		builderImage := artifacts.ImageName{}
+		if err := common.GetObjFromInterface(tc.Spec.Config, &builderImage); err != nil {
+			logrus.Debugf("unable to load config for Transformer %+v into %T : %s", tc.Spec.Config, builderImage, err)
+			return ""
+		}
+		return builderImage.ImageName
+	}
+	return ""
+}
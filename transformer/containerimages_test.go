@@ -0,0 +1,102 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestGetContainerImages(t *testing.T) {
+	dir := t.TempDir()
+	transformerYamls := map[string]string{
+		"pulled": `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Transformer
+metadata:
+  name: Pulled
+spec:
+  class: Executable
+  config:
+    container:
+      image: quay.io/acme/pulled:v1
+      platform: linux/arm64
+`,
+		"built": `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Transformer
+metadata:
+  name: Built
+spec:
+  class: Executable
+  config:
+    container:
+      image: quay.io/acme/built:v1
+      build:
+        dockerfile: Dockerfile
+`,
+		"cnb": `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Transformer
+metadata:
+  name: CNB
+  labels:
+    move2kube.konveyor.io/task: containerization
+spec:
+  class: CNBContainerizer
+  config:
+    ImageName: paketobuildpacks/builder:full
+`,
+		"local": `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Transformer
+metadata:
+  name: Local
+spec:
+  class: Executable
+  config:
+    transformCMD: ["echo"]
+`,
+	}
+	transformerPaths := map[string]string{}
+	for name, contents := range transformerYamls {
+		path := filepath.Join(dir, name, "transformer.yaml")
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the transformer yaml %s . Error: %q", path, err)
+		}
+		transformerPaths[name] = path
+	}
+	images := GetContainerImages(transformerPaths, labels.Everything())
+	if diff := cmp.Diff([]string{"paketobuildpacks/builder:full", "quay.io/acme/pulled:v1"}, images); diff != "" {
+		t.Fatalf("expected only the images that can be pulled. Difference:\n%s", diff)
+	}
+	platforms := GetContainerImagePlatforms(transformerPaths, labels.Everything())
+	if diff := cmp.Diff(map[string]string{"quay.io/acme/pulled:v1": "linux/arm64"}, platforms); diff != "" {
+		t.Fatalf("the platforms of the images are incorrect. Difference:\n%s", diff)
+	}
+	selector, err := labels.Parse("move2kube.konveyor.io/task=containerization")
+	if err != nil {
+		t.Fatalf("failed to parse the selector. Error: %q", err)
+	}
+	if images := GetContainerImages(transformerPaths, selector); !cmp.Equal(images, []string{"paketobuildpacks/builder:full"}) {
+		t.Fatalf("expected only the images of the selected transformers. Actual: %+v", images)
+	}
+}
@@ -0,0 +1,505 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCronJobsDir = common.DeployDir + string(os.PathSeparator) + "cronjobs"
+	crontabFileName    = "crontab"
+	cronExt            = ".cron"
+	cronDDirName       = "cron.d"
+	systemdTimerExt    = ".timer"
+	systemdServiceExt  = ".service"
+	// cronJobServiceLabel is the label that the other resources of the service use
+	cronJobServiceLabel = types.GroupName + "/service"
+)
+
+var (
+	cronEnvRegex           = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+	quartzCronTriggerRegex = regexp.MustCompile(`(?s)<cron>(.*?)</cron>`)
+	quartzElementRegex     = regexp.MustCompile(`(?s)<(name|job-name|cron-expression|time-zone)>\s*(.*?)\s*</(?:name|job-name|cron-expression|time-zone)>`)
+)
+
+// CronJobAnalyser implements Transformer interface
+type CronJobAnalyser struct {
+	Config        transformertypes.Transformer
+	Env           *environment.Environment
+	CronJobConfig *CronJobYamlConfig
+}
+
+// CronJobYamlConfig stores the yaml configuration for the cron job transformer
+type CronJobYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+}
+
+// scheduledJob is a job that a scheduler in the source runs periodically
+type scheduledJob struct {
+	name     string
+	schedule string
+	syntax   string
+	timeZone string
+	command  []string
+	env      map[string]string
+	path     string
+}
+
+// Init Initializes the transformer
+func (t *CronJobAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.CronJobConfig = &CronJobYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.CronJobConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.CronJobConfig, err)
+		return err
+	}
+	if t.CronJobConfig.OutputPath == "" {
+		t.CronJobConfig.OutputPath = defaultCronJobsDir
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *CronJobAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *CronJobAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform generates cron jobs for the crontabs, systemd timers and Quartz triggers in the build contexts of the services
+func (t *CronJobAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		artifactsCreated = append(artifactsCreated, a)
+		serviceNames := []string{}
+		for sn := range ir.Services {
+			serviceNames = append(serviceNames, sn)
+		}
+		sort.Strings(serviceNames)
+		for _, sn := range serviceNames {
+			s := ir.Services[sn]
+			if len(s.Containers) == 0 {
+				continue
+			}
+			jobs := getScheduledJobs(ir, s)
+			if len(jobs) == 0 {
+				continue
+			}
+			qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`, common.ConfigCronJobsKeySegment)
+			jobNames := []string{}
+			for _, job := range jobs {
+				jobNames = append(jobNames, job.name)
+			}
+			selectedJobNames := qaengine.FetchMultiSelectAnswer(
+				common.JoinQASubKeys(qaKeyPrefix, common.Special, "enable"),
+				fmt.Sprintf("Select the scheduled jobs of the service %s that should be converted to cron jobs:", sn),
+				[]string{"The jobs were found in crontabs, systemd timers and Quartz triggers", "The cron jobs run the image of the service with the command of the job"},
+				jobNames,
+				jobNames,
+			)
+			tempDest := filepath.Join(t.Env.TempPath, t.CronJobConfig.OutputPath)
+			if err := os.MkdirAll(tempDest, common.DefaultDirectoryPermission); err != nil {
+				logrus.Errorf("failed to create the directory %s for the cron jobs. Error: %q", tempDest, err)
+				continue
+			}
+			for _, job := range jobs {
+				if !common.IsStringPresent(selectedJobNames, job.name) {
+					continue
+				}
+				jobQAKeyPrefix := common.JoinQASubKeys(qaKeyPrefix, `"`+job.name+`"`)
+				schedule, timeZone, ok := getCronJobSchedule(jobQAKeyPrefix, job)
+				if !ok {
+					continue
+				}
+				command := job.command
+				if len(command) == 0 {
+					commandStr := qaengine.FetchStringAnswer(
+						common.JoinQASubKeys(jobQAKeyPrefix, common.ConfigCommandKeySegment),
+						fmt.Sprintf("Enter the command that the cron job %s of the service %s should run:", job.name, sn),
+						[]string{fmt.Sprintf("The job was found at path %s , which does not have the command of the job", job.path), "Leave it empty to skip the job"},
+						"",
+					)
+					if strings.TrimSpace(commandStr) == "" {
+						logrus.Warnf("skipping the scheduled job %s of the service %s since it does not have a command", job.name, sn)
+						continue
+					}
+					command = []string{"/bin/sh", "-c", commandStr}
+				}
+				obj := getCronJobObject(sn, s, job, schedule, timeZone, command)
+				file := filepath.Join(tempDest, common.NormalizeForMetadataName(sn+"-"+job.name)+"-cronjob.yaml")
+				if err := common.WriteYaml(file, obj); err != nil {
+					logrus.Errorf("failed to write the cron job %s of the service %s to the file at path %s . Error: %q", job.name, sn, file, err)
+					continue
+				}
+				destPath, err := filepath.Rel(t.Env.TempPath, file)
+				if err != nil {
+					logrus.Errorf("failed to make the yaml path %s relative to the temporary directory %s . Error: %q", file, t.Env.TempPath, err)
+					continue
+				}
+				pathMappings = append(pathMappings, transformertypes.PathMapping{
+					Type:     transformertypes.DefaultPathMappingType,
+					SrcPath:  file,
+					DestPath: destPath,
+				})
+			}
+		}
+	}
+	return pathMappings, artifactsCreated, nil
+}
+
+// getCronJobSchedule converts the schedule of the job into a cron schedule and validates its timezone.
+// The user is asked for the schedule or the timezone when they cannot be converted unambiguously.
+func getCronJobSchedule(qaKeyPrefix string, job scheduledJob) (string, string, bool) {
+	schedule, timeZone, err := convertSchedule(job.schedule, job.syntax)
+	if job.timeZone != "" {
+		timeZone = job.timeZone
+	}
+	if err != nil {
+		logrus.Warnf("the schedule %s of the job %s at path %s cannot be converted to a cron schedule. Error: %q", job.schedule, job.name, job.path, err)
+		answer := qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, common.ConfigScheduleKeySegment),
+			fmt.Sprintf("Enter the cron schedule for the job %s , whose %s schedule is %s :", job.name, job.syntax, job.schedule),
+			[]string{err.Error(), "A cron schedule (like 0 4 * * 1-5), Heroku scheduler frequency (like every day at 4:00) or systemd calendar event (like Mon..Fri 04:00) can be entered", "Leave it empty to skip the job"},
+			"",
+		)
+		if strings.TrimSpace(answer) == "" {
+			logrus.Warnf("skipping the scheduled job %s since it does not have a cron schedule", job.name)
+			return "", "", false
+		}
+		answerTimeZone := ""
+		schedule, answerTimeZone, err = convertAnySchedule(answer)
+		if err != nil {
+			logrus.Errorf("skipping the scheduled job %s . Error: %q", job.name, err)
+			return "", "", false
+		}
+		if answerTimeZone != "" {
+			timeZone = answerTimeZone
+		}
+	}
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			logrus.Warnf("the timezone %s of the job %s at path %s is invalid. Error: %q", timeZone, job.name, job.path, err)
+			timeZone = qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(qaKeyPrefix, common.ConfigTimeZoneKeySegment),
+				fmt.Sprintf("Enter the timezone for the schedule of the job %s :", job.name),
+				[]string{fmt.Sprintf("The timezone %s is not in the IANA timezone database", timeZone), "Leave it empty to use the timezone of the kube-controller-manager"},
+				"",
+			)
+			if _, err := time.LoadLocation(timeZone); err != nil {
+				logrus.Warnf("ignoring the invalid timezone %s of the job %s . Error: %q", timeZone, job.name, err)
+				timeZone = ""
+			}
+		}
+	}
+	return schedule, timeZone, true
+}
+
+// getCronJobObject returns a cron job that runs the command of the job in the first container of the service
+func getCronJobObject(serviceName string, service irtypes.Service, job scheduledJob, schedule, timeZone string, command []string) map[string]interface{} {
+	name := common.NormalizeForMetadataName(serviceName + "-" + job.name)
+	container := map[string]interface{}{
+		"name":    name,
+		"image":   service.Containers[0].Image,
+		"command": command,
+	}
+	if len(job.env) > 0 {
+		envNames := []string{}
+		for envName := range job.env {
+			envNames = append(envNames, envName)
+		}
+		sort.Strings(envNames)
+		env := []interface{}{}
+		for _, envName := range envNames {
+			env = append(env, map[string]interface{}{"name": envName, "value": job.env[envName]})
+		}
+		container["env"] = env
+	}
+	spec := map[string]interface{}{
+		"schedule":          schedule,
+		"concurrencyPolicy": "Forbid",
+		"jobTemplate": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]interface{}{cronJobServiceLabel: serviceName},
+					},
+					"spec": map[string]interface{}{
+						"restartPolicy": "OnFailure",
+						"containers":    []interface{}{container},
+					},
+				},
+			},
+		},
+	}
+	if timeZone != "" {
+		spec["timeZone"] = timeZone
+	}
+	return map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": map[string]interface{}{cronJobServiceLabel: serviceName},
+		},
+		"spec": spec,
+	}
+}
+
+// getScheduledJobs returns the scheduled jobs in the build contexts of the images of the service
+func getScheduledJobs(ir irtypes.IR, service irtypes.Service) []scheduledJob {
+	jobs := []scheduledJob{}
+	seenContexts := map[string]bool{}
+	for _, container := range service.Containers {
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok || image.Build.ContextPath == "" || seenContexts[image.Build.ContextPath] {
+			continue
+		}
+		seenContexts[image.Build.ContextPath] = true
+		err := filepath.Walk(image.Build.ContextPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if path != image.Build.ContextPath && (strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			switch {
+			case info.Name() == crontabFileName || filepath.Ext(path) == cronExt || filepath.Base(filepath.Dir(path)) == cronDDirName:
+				jobs = append(jobs, getCrontabJobs(path)...)
+			case filepath.Ext(path) == systemdTimerExt:
+				jobs = append(jobs, getSystemdTimerJobs(path)...)
+			case filepath.Ext(path) == ".xml" && strings.Contains(strings.ToLower(info.Name()), "quartz"):
+				jobs = append(jobs, getQuartzJobs(path)...)
+			}
+			return nil
+		})
+		if err != nil {
+			logrus.Errorf("failed to walk the build context %s for scheduled jobs. Error: %q", image.Build.ContextPath, err)
+		}
+	}
+	names := map[string]int{}
+	for i, job := range jobs {
+		names[job.name]++
+		if names[job.name] > 1 {
+			jobs[i].name = job.name + "-" + strconv.Itoa(names[job.name])
+		}
+	}
+	return jobs
+}
+
+// getCrontabJobs parses a crontab. The files in cron.d directories and the system crontab have a user field before the command.
+func getCrontabJobs(path string) []scheduledJob {
+	file, err := os.Open(path)
+	if err != nil {
+		logrus.Errorf("failed to open the crontab at path %s . Error: %q", path, err)
+		return nil
+	}
+	defer file.Close()
+	hasUserField := filepath.Base(filepath.Dir(path)) == cronDDirName || filepath.Base(filepath.Dir(path)) == "etc"
+	baseName := common.NormalizeForMetadataName(strings.TrimSuffix(filepath.Base(path), cronExt))
+	jobs := []scheduledJob{}
+	env := map[string]string{}
+	timeZone := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if matches := cronEnvRegex.FindStringSubmatch(line); matches != nil {
+			value := strings.Trim(matches[2], `"'`)
+			if matches[1] == "CRON_TZ" || matches[1] == "TZ" {
+				timeZone = value
+			}
+			if matches[1] != "CRON_TZ" {
+				env[matches[1]] = value
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		scheduleFieldCount := 5
+		if strings.HasPrefix(fields[0], "@") {
+			scheduleFieldCount = 1
+		}
+		if hasUserField {
+			scheduleFieldCount++
+		}
+		if len(fields) <= scheduleFieldCount {
+			logrus.Warnf("ignoring the crontab line without a command at path %s : %s", path, line)
+			continue
+		}
+		schedule := strings.Join(fields[:scheduleFieldCount], " ")
+		if hasUserField {
+			schedule = strings.Join(fields[:scheduleFieldCount-1], " ")
+		}
+		if schedule == "@reboot" {
+			logrus.Warnf("ignoring the crontab line that runs at boot at path %s : %s", path, line)
+			continue
+		}
+		jobEnv := map[string]string{}
+		for k, v := range env {
+			jobEnv[k] = v
+		}
+		jobs = append(jobs, scheduledJob{
+			name:     fmt.Sprintf("%s-%d", baseName, len(jobs)+1),
+			schedule: schedule,
+			syntax:   cronSyntax,
+			timeZone: timeZone,
+			command:  []string{"/bin/sh", "-c", strings.Join(fields[scheduleFieldCount:], " ")},
+			env:      jobEnv,
+			path:     path,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		logrus.Errorf("failed to read the crontab at path %s . Error: %q", path, err)
+	}
+	return jobs
+}
+
+// getSystemdTimerJobs returns the calendar events of a systemd timer, with the command of the service unit that it activates
+func getSystemdTimerJobs(path string) []scheduledJob {
+	timer, err := readSystemdUnit(path)
+	if err != nil {
+		logrus.Errorf("failed to read the systemd timer at path %s . Error: %q", path, err)
+		return nil
+	}
+	unitName := strings.TrimSuffix(filepath.Base(path), systemdTimerExt) + systemdServiceExt
+	if units := timer["Timer.Unit"]; len(units) > 0 {
+		unitName = units[0]
+	}
+	command := []string{}
+	unitPath := filepath.Join(filepath.Dir(path), unitName)
+	if unit, err := readSystemdUnit(unitPath); err != nil {
+		logrus.Debugf("failed to read the systemd service %s of the timer at path %s . Error: %q", unitPath, path, err)
+	} else if execStarts := unit["Service.ExecStart"]; len(execStarts) > 0 {
+		// the prefixes of ExecStart change how errors and privileges are handled, which does not apply in a container
+		command = []string{"/bin/sh", "-c", strings.TrimLeft(execStarts[len(execStarts)-1], "-@:+!")}
+	}
+	baseName := common.NormalizeForMetadataName(strings.TrimSuffix(filepath.Base(path), systemdTimerExt))
+	jobs := []scheduledJob{}
+	for i, event := range timer["Timer.OnCalendar"] {
+		name := baseName
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", baseName, i+1)
+		}
+		jobs = append(jobs, scheduledJob{name: name, schedule: event, syntax: systemdSyntax, command: command, path: path})
+	}
+	if len(jobs) == 0 {
+		logrus.Debugf("the systemd timer at path %s does not have calendar events", path)
+	}
+	return jobs
+}
+
+// readSystemdUnit returns the values of the keys of a systemd unit file, keyed by section.key
+func readSystemdUnit(path string) (map[string][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	values := map[string][]string{}
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = section + "." + strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			// an empty value resets the list of values
+			delete(values, key)
+			continue
+		}
+		values[key] = append(values[key], value)
+	}
+	return values, scanner.Err()
+}
+
+// getQuartzJobs returns the cron triggers of a Quartz job scheduling data xml. The jobs run in the JVM, so they do not have a command.
+func getQuartzJobs(path string) []scheduledJob {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		logrus.Errorf("failed to read the Quartz jobs at path %s . Error: %q", path, err)
+		return nil
+	}
+	jobs := []scheduledJob{}
+	for _, trigger := range quartzCronTriggerRegex.FindAllStringSubmatch(string(contents), -1) {
+		elements := map[string]string{}
+		for _, element := range quartzElementRegex.FindAllStringSubmatch(trigger[1], -1) {
+			if _, ok := elements[element[1]]; !ok {
+				elements[element[1]] = element[2]
+			}
+		}
+		if elements["cron-expression"] == "" {
+			continue
+		}
+		name := elements["job-name"]
+		if name == "" {
+			name = elements["name"]
+		}
+		if name == "" {
+			name = fmt.Sprintf("quartz-%d", len(jobs)+1)
+		}
+		jobs = append(jobs, scheduledJob{
+			name:     common.NormalizeForMetadataName(name),
+			schedule: elements["cron-expression"],
+			syntax:   quartzSyntax,
+			timeZone: elements["time-zone"],
+			path:     path,
+		})
+	}
+	return jobs
+}
@@ -0,0 +1,419 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	cronSyntax    = "cron"
+	quartzSyntax  = "quartz"
+	systemdSyntax = "systemd"
+	herokuSyntax  = "heroku"
+)
+
+var (
+	cronMacros       = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+	cronMonthNames   = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	cronWeekdayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+	// cronFieldBounds are the minimum and maximum values of the minute, hour, day of month, month and day of week fields
+	cronFieldBounds = [][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	// systemdShorthands maps the calendar shorthands of systemd to cron schedules
+	systemdShorthands = map[string]string{
+		"minutely":     "* * * * *",
+		"hourly":       "0 * * * *",
+		"daily":        "0 0 * * *",
+		"weekly":       "0 0 * * 1",
+		"monthly":      "0 0 1 * *",
+		"yearly":       "0 0 1 1 *",
+		"annually":     "0 0 1 1 *",
+		"quarterly":    "0 0 1 1,4,7,10 *",
+		"semiannually": "0 0 1 1,7 *",
+	}
+	systemdWeekdays       = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+	herokuEveryNRegex     = regexp.MustCompile(`^every (\d+) minutes?$`)
+	herokuHourlyRegex     = regexp.MustCompile(`^(?:hourly|every hour)(?: at :?(\d{1,2}))?$`)
+	herokuDailyRegex      = regexp.MustCompile(`^(?:daily|every day)(?: at (\d{1,2}):(\d{2})(?: ?(am|pm))?)?$`)
+	systemdRepetitionRegx = regexp.MustCompile(`^(\d+)/(\d+)$`)
+)
+
+// convertSchedule converts a schedule in the syntax of the source scheduler into a kubernetes cron schedule.
+// The timezone is returned if the schedule has one. An error is returned if the schedule cannot be converted unambiguously.
+func convertSchedule(schedule, syntax string) (string, string, error) {
+	schedule = strings.TrimSpace(schedule)
+	var converted, timeZone string
+	var err error
+	switch syntax {
+	case quartzSyntax:
+		converted, err = convertQuartzSchedule(schedule)
+	case systemdSyntax:
+		converted, timeZone, err = convertSystemdSchedule(schedule)
+	case herokuSyntax:
+		converted, err = convertHerokuSchedule(schedule)
+		timeZone = "UTC"
+	default:
+		converted = strings.Join(strings.Fields(schedule), " ")
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if err := validateCronSchedule(converted); err != nil {
+		return "", "", err
+	}
+	return converted, timeZone, nil
+}
+
+// convertAnySchedule converts a schedule that is in cron, Heroku scheduler or systemd calendar syntax, like the answers to the QA
+func convertAnySchedule(schedule string) (string, string, error) {
+	for _, syntax := range []string{cronSyntax, herokuSyntax, systemdSyntax} {
+		if converted, timeZone, err := convertSchedule(schedule, syntax); err == nil {
+			if syntax == herokuSyntax {
+				timeZone = ""
+			}
+			return converted, timeZone, nil
+		}
+	}
+	return "", "", fmt.Errorf("the schedule %s is not a valid cron schedule, Heroku scheduler frequency or systemd calendar event", schedule)
+}
+
+// validateCronSchedule checks that the schedule is a valid five field cron schedule or one of the macros supported by kubernetes
+func validateCronSchedule(schedule string) error {
+	if strings.HasPrefix(schedule, "@") {
+		for _, macro := range cronMacros {
+			if schedule == macro {
+				return nil
+			}
+		}
+		return fmt.Errorf("the cron macro %s is not supported", schedule)
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFieldBounds) {
+		return fmt.Errorf("the cron schedule %s has %d fields instead of %d", schedule, len(fields), len(cronFieldBounds))
+	}
+	for i, field := range fields {
+		for _, item := range strings.Split(field, ",") {
+			if err := validateCronItem(item, i); err != nil {
+				return fmt.Errorf("the field %s of the cron schedule %s is invalid. Error: %q", field, schedule, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateCronItem(item string, fieldIdx int) error {
+	rangePart := item
+	if idx := strings.Index(item, "/"); idx >= 0 {
+		step, err := strconv.Atoi(item[idx+1:])
+		if err != nil || step <= 0 {
+			return fmt.Errorf("invalid step %s", item[idx+1:])
+		}
+		rangePart = item[:idx]
+	}
+	if rangePart == "*" || rangePart == "?" {
+		return nil
+	}
+	bounds := strings.SplitN(rangePart, "-", 2)
+	values := []int{}
+	for _, bound := range bounds {
+		value, err := getCronValue(bound, fieldIdx)
+		if err != nil {
+			return err
+		}
+		if value < cronFieldBounds[fieldIdx][0] || value > cronFieldBounds[fieldIdx][1] {
+			return fmt.Errorf("the value %s is out of the range %d-%d", bound, cronFieldBounds[fieldIdx][0], cronFieldBounds[fieldIdx][1])
+		}
+		values = append(values, value)
+	}
+	if len(values) == 2 && values[0] > values[1] {
+		return fmt.Errorf("the range %s is reversed", rangePart)
+	}
+	return nil
+}
+
+func getCronValue(value string, fieldIdx int) (int, error) {
+	names := []string{}
+	switch fieldIdx {
+	case 3:
+		names = cronMonthNames
+	case 4:
+		names = cronWeekdayNames
+	}
+	for i, name := range names {
+		if strings.EqualFold(value, name) {
+			if fieldIdx == 3 {
+				return i + 1, nil
+			}
+			return i, nil
+		}
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %s", value)
+	}
+	return number, nil
+}
+
+// convertQuartzSchedule converts a Quartz cron expression (seconds minutes hours day-of-month month day-of-week [year])
+func convertQuartzSchedule(schedule string) (string, error) {
+	fields := strings.Fields(schedule)
+	if len(fields) != 6 && len(fields) != 7 {
+		return "", fmt.Errorf("the Quartz cron expression %s has %d fields instead of 6 or 7", schedule, len(fields))
+	}
+	if fields[0] != "0" {
+		return "", fmt.Errorf("the Quartz cron expression %s runs at the seconds %s , but kubernetes cron jobs run at most once a minute", schedule, fields[0])
+	}
+	if len(fields) == 7 && fields[6] != "*" && fields[6] != "?" {
+		return "", fmt.Errorf("the Quartz cron expression %s is limited to the years %s , which is not supported by kubernetes cron jobs", schedule, fields[6])
+	}
+	for _, field := range fields[1:6] {
+		if strings.ContainsAny(field, "LW#") && !isCronName(field) {
+			return "", fmt.Errorf("the special character in the field %s of the Quartz cron expression %s is not supported by kubernetes cron jobs", field, schedule)
+		}
+	}
+	converted := []string{}
+	for _, field := range fields[1:5] {
+		converted = append(converted, strings.ReplaceAll(field, "?", "*"))
+	}
+	// Quartz numbers the days of the week from 1 (Sunday) to 7 (Saturday)
+	weekdayItems := []string{}
+	for _, item := range strings.Split(strings.ReplaceAll(fields[5], "?", "*"), ",") {
+		step := ""
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			item, step = item[:idx], item[idx:]
+		}
+		bounds := strings.Split(item, "-")
+		for i, bound := range bounds {
+			if number, err := strconv.Atoi(bound); err == nil {
+				if number < 1 || number > 7 {
+					return "", fmt.Errorf("the day of week %s of the Quartz cron expression %s is out of the range 1-7", bound, schedule)
+				}
+				bounds[i] = strconv.Itoa(number - 1)
+			}
+		}
+		weekdayItems = append(weekdayItems, strings.Join(bounds, "-")+step)
+	}
+	converted = append(converted, strings.Join(weekdayItems, ","))
+	if converted[2] != "*" && converted[4] != "*" {
+		return "", fmt.Errorf("the Quartz cron expression %s sets both the day of month and the day of week, which cron treats as either of them", schedule)
+	}
+	return strings.Join(converted, " "), nil
+}
+
+func isCronName(field string) bool {
+	for _, item := range strings.FieldsFunc(field, func(r rune) bool { return r == ',' || r == '-' || r == '/' }) {
+		isName := false
+		for _, name := range append(append([]string{}, cronMonthNames...), cronWeekdayNames...) {
+			if strings.EqualFold(item, name) {
+				isName = true
+			}
+		}
+		if !isName && strings.ContainsAny(item, "LW#") {
+			return false
+		}
+	}
+	return true
+}
+
+// convertHerokuSchedule converts the frequencies of the Heroku scheduler, like "every 10 minutes", "every hour at :30" or "every day at 4:30 pm"
+func convertHerokuSchedule(schedule string) (string, error) {
+	frequency := strings.ToLower(strings.Join(strings.Fields(schedule), " "))
+	if matches := herokuEveryNRegex.FindStringSubmatch(frequency); matches != nil {
+		minutes, _ := strconv.Atoi(matches[1])
+		if minutes <= 0 || minutes > 59 || 60%minutes != 0 {
+			return "", fmt.Errorf("the frequency %s cannot be expressed as a cron schedule that runs at even intervals", schedule)
+		}
+		return fmt.Sprintf("*/%d * * * *", minutes), nil
+	}
+	if matches := herokuHourlyRegex.FindStringSubmatch(frequency); matches != nil {
+		minute := "0"
+		if matches[1] != "" {
+			minute = strings.TrimLeft(matches[1], "0")
+			if minute == "" {
+				minute = "0"
+			}
+		}
+		return minute + " * * * *", nil
+	}
+	if matches := herokuDailyRegex.FindStringSubmatch(frequency); matches != nil {
+		if matches[1] == "" {
+			return "0 0 * * *", nil
+		}
+		hour, _ := strconv.Atoi(matches[1])
+		minute, _ := strconv.Atoi(matches[2])
+		switch matches[3] {
+		case "am":
+			if hour == 12 {
+				hour = 0
+			}
+		case "pm":
+			if hour != 12 {
+				hour += 12
+			}
+		}
+		return fmt.Sprintf("%d %d * * *", minute, hour), nil
+	}
+	return "", fmt.Errorf("the Heroku scheduler frequency %s is not supported", schedule)
+}
+
+// convertSystemdSchedule converts a systemd OnCalendar event, like "Mon..Fri *-*-* 04:00:00 Europe/Berlin"
+func convertSystemdSchedule(schedule string) (string, string, error) {
+	tokens := strings.Fields(schedule)
+	if len(tokens) == 0 {
+		return "", "", fmt.Errorf("the calendar event is empty")
+	}
+	if len(tokens) == 1 {
+		if converted, ok := systemdShorthands[strings.ToLower(tokens[0])]; ok {
+			return converted, "", nil
+		}
+	}
+	timeZone := ""
+	if last := tokens[len(tokens)-1]; len(tokens) > 1 && isSystemdTimeZone(last) {
+		timeZone = last
+		tokens = tokens[:len(tokens)-1]
+	}
+	weekday, month, day, hour, minute := "*", "*", "*", "0", "0"
+	for _, token := range tokens {
+		var err error
+		switch {
+		case strings.Contains(token, ":"):
+			timeParts := strings.Split(token, ":")
+			if len(timeParts) == 3 && timeParts[2] != "00" && timeParts[2] != "0" {
+				return "", "", fmt.Errorf("the calendar event %s runs at the seconds %s , but kubernetes cron jobs run at most once a minute", schedule, timeParts[2])
+			}
+			if hour, err = convertSystemdComponent(timeParts[0], 23); err == nil {
+				minute, err = convertSystemdComponent(timeParts[1], 59)
+			}
+		case strings.Contains(token, "-"):
+			dateParts := strings.Split(token, "-")
+			if len(dateParts) == 2 {
+				dateParts = append([]string{"*"}, dateParts...)
+			}
+			if len(dateParts) != 3 || dateParts[0] != "*" {
+				return "", "", fmt.Errorf("the date %s of the calendar event %s is limited to some years, which is not supported by kubernetes cron jobs", token, schedule)
+			}
+			if month, err = convertSystemdComponent(dateParts[1], 12); err == nil {
+				day, err = convertSystemdComponent(dateParts[2], 31)
+			}
+		default:
+			weekday, err = convertSystemdWeekdays(token)
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to convert the calendar event %s . Error: %q", schedule, err)
+		}
+	}
+	if day != "*" && weekday != "*" {
+		return "", "", fmt.Errorf("the calendar event %s sets both the day of month and the day of week, which cron treats as either of them", schedule)
+	}
+	if timeZone != "" {
+		if _, err := time.LoadLocation(timeZone); err != nil {
+			return "", "", fmt.Errorf("the timezone %s of the calendar event %s is invalid. Error: %q", timeZone, schedule, err)
+		}
+	}
+	return strings.Join([]string{minute, hour, day, month, weekday}, " "), timeZone, nil
+}
+
+// isSystemdTimeZone returns true if the token of a calendar event is a timezone, like UTC, Europe/Berlin or Etc/GMT+5, instead of a date, a time or weekdays
+func isSystemdTimeZone(token string) bool {
+	if token == "" || !unicode.IsLetter(rune(token[0])) || strings.ContainsAny(token, ":*,.") {
+		return false
+	}
+	if strings.Contains(token, "/") || token == "UTC" {
+		return true
+	}
+	_, err := time.LoadLocation(token)
+	return err == nil
+}
+
+// convertSystemdComponent converts a component of the date or time of a calendar event, like *, 5, 1,15, 1..5 or 0/15
+func convertSystemdComponent(component string, max int) (string, error) {
+	items := []string{}
+	for _, item := range strings.Split(component, ",") {
+		if matches := systemdRepetitionRegx.FindStringSubmatch(item); matches != nil {
+			if matches[1] == "0" || matches[1] == "00" {
+				items = append(items, "*/"+matches[2])
+			} else {
+				items = append(items, fmt.Sprintf("%s-%d/%s", strings.TrimLeft(matches[1], "0"), max, matches[2]))
+			}
+			continue
+		}
+		if strings.Contains(item, "~") {
+			return "", fmt.Errorf("the last days of the month %s are not supported", item)
+		}
+		item = strings.ReplaceAll(item, "..", "-")
+		bounds := strings.Split(item, "-")
+		for i, bound := range bounds {
+			if bound == "*" {
+				continue
+			}
+			number, err := strconv.Atoi(bound)
+			if err != nil {
+				return "", fmt.Errorf("invalid value %s", bound)
+			}
+			bounds[i] = strconv.Itoa(number)
+		}
+		items = append(items, strings.Join(bounds, "-"))
+	}
+	return strings.Join(items, ","), nil
+}
+
+// convertSystemdWeekdays converts the weekdays of a calendar event, like Mon..Fri, Sat..Sun or Sat,Sun.
+// The weeks of systemd start on Monday, so the ranges that end on Sunday end on 7 in cron, and the other ranges that wrap around the end of the week are split.
+func convertSystemdWeekdays(weekdays string) (string, error) {
+	items := []string{}
+	for _, item := range strings.Split(weekdays, ",") {
+		bounds := strings.Split(item, "..")
+		if len(bounds) > 2 {
+			return "", fmt.Errorf("invalid weekdays %s", item)
+		}
+		converted := []int{}
+		for _, bound := range bounds {
+			if len(bound) < 3 {
+				return "", fmt.Errorf("invalid weekday %s", bound)
+			}
+			weekday, ok := systemdWeekdays[strings.ToLower(bound)[:3]]
+			if !ok {
+				return "", fmt.Errorf("invalid weekday %s", bound)
+			}
+			converted = append(converted, weekday)
+		}
+		if len(converted) == 1 {
+			items = append(items, strconv.Itoa(converted[0]))
+			continue
+		}
+		start, end := converted[0], converted[1]
+		if end == 0 && start != 0 {
+			end = 7
+		}
+		if start <= end {
+			items = append(items, fmt.Sprintf("%d-%d", start, end))
+			continue
+		}
+		items = append(items, fmt.Sprintf("%d-7", start))
+		if end == 1 {
+			items = append(items, "1")
+		} else {
+			items = append(items, fmt.Sprintf("1-%d", end))
+		}
+	}
+	return strings.Join(items, ","), nil
+}
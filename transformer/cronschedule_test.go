@@ -0,0 +1,123 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+)
+
+func TestConvertSchedule(t *testing.T) {
+	testCases := []struct {
+		name             string
+		schedule         string
+		syntax           string
+		expectedSchedule string
+		expectedTimeZone string
+		expectedError    bool
+	}{
+		{name: "cron", schedule: " */5  *  * * * ", syntax: cronSyntax, expectedSchedule: "*/5 * * * *"},
+		{name: "cron with names", schedule: "0 4 * JAN-MAR mon-fri", syntax: cronSyntax, expectedSchedule: "0 4 * JAN-MAR mon-fri"},
+		{name: "cron macro", schedule: "@daily", syntax: cronSyntax, expectedSchedule: "@daily"},
+		{name: "cron with sunday as 7", schedule: "0 0 * * 5-7", syntax: cronSyntax, expectedSchedule: "0 0 * * 5-7"},
+		{name: "unsupported cron macro", schedule: "@reboot", syntax: cronSyntax, expectedError: true},
+		{name: "cron with too many fields", schedule: "0 0 0 * * *", syntax: cronSyntax, expectedError: true},
+		{name: "cron out of range", schedule: "60 * * * *", syntax: cronSyntax, expectedError: true},
+		{name: "cron reversed range", schedule: "0 0 * * 5-1", syntax: cronSyntax, expectedError: true},
+		{name: "cron invalid step", schedule: "*/0 * * * *", syntax: cronSyntax, expectedError: true},
+		{name: "quartz", schedule: "0 30 4 ? * 2-6", syntax: quartzSyntax, expectedSchedule: "30 4 * * 1-5"},
+		{name: "quartz with a year", schedule: "0 0 12 1 * ? *", syntax: quartzSyntax, expectedSchedule: "0 12 1 * *"},
+		{name: "quartz weekday names", schedule: "0 0 8 ? * MON,WED", syntax: quartzSyntax, expectedSchedule: "0 8 * * MON,WED"},
+		{name: "quartz seconds", schedule: "30 * * * * ?", syntax: quartzSyntax, expectedError: true},
+		{name: "quartz limited years", schedule: "0 0 0 1 1 ? 2030", syntax: quartzSyntax, expectedError: true},
+		{name: "quartz last day of month", schedule: "0 0 0 L * ?", syntax: quartzSyntax, expectedError: true},
+		{name: "quartz day of month and day of week", schedule: "0 0 0 1 * 2", syntax: quartzSyntax, expectedError: true},
+		{name: "quartz day of week out of range", schedule: "0 0 0 ? * 8", syntax: quartzSyntax, expectedError: true},
+		{name: "heroku every 10 minutes", schedule: "every 10 minutes", syntax: herokuSyntax, expectedSchedule: "*/10 * * * *", expectedTimeZone: "UTC"},
+		{name: "heroku hourly", schedule: "Every hour at :30", syntax: herokuSyntax, expectedSchedule: "30 * * * *", expectedTimeZone: "UTC"},
+		{name: "heroku hourly on the hour", schedule: "hourly at :00", syntax: herokuSyntax, expectedSchedule: "0 * * * *", expectedTimeZone: "UTC"},
+		{name: "heroku daily pm", schedule: "every day at 4:30 pm", syntax: herokuSyntax, expectedSchedule: "30 16 * * *", expectedTimeZone: "UTC"},
+		{name: "heroku daily midnight", schedule: "daily at 12:00 am", syntax: herokuSyntax, expectedSchedule: "0 0 * * *", expectedTimeZone: "UTC"},
+		{name: "heroku uneven interval", schedule: "every 7 minutes", syntax: herokuSyntax, expectedError: true},
+		{name: "heroku unsupported", schedule: "every week", syntax: herokuSyntax, expectedError: true},
+		{name: "systemd shorthand", schedule: "weekly", syntax: systemdSyntax, expectedSchedule: "0 0 * * 1"},
+		{name: "systemd weekdays", schedule: "Mon..Fri *-*-* 04:00:00", syntax: systemdSyntax, expectedSchedule: "0 4 * * 1-5"},
+		{name: "systemd weekend range", schedule: "Sat..Sun 10:00", syntax: systemdSyntax, expectedSchedule: "0 10 * * 6-7"},
+		{name: "systemd range wrapping the week", schedule: "Fri..Tue 10:00", syntax: systemdSyntax, expectedSchedule: "0 10 * * 5-7,1-2"},
+		{name: "systemd range wrapping to monday", schedule: "Sat..Mon 10:00", syntax: systemdSyntax, expectedSchedule: "0 10 * * 6-7,1"},
+		{name: "systemd weekday list", schedule: "Sat,Sun 08:15", syntax: systemdSyntax, expectedSchedule: "15 8 * * 6,0"},
+		{name: "systemd date and repetition", schedule: "*-*-01 00/6:00", syntax: systemdSyntax, expectedSchedule: "0 */6 1 * *"},
+		{name: "systemd month range", schedule: "*-01..03-15 12:30", syntax: systemdSyntax, expectedSchedule: "30 12 15 1-3 *"},
+		{name: "systemd timezone", schedule: "Mon..Fri 04:00 Europe/Berlin", syntax: systemdSyntax, expectedSchedule: "0 4 * * 1-5", expectedTimeZone: "Europe/Berlin"},
+		{name: "systemd timezone with an offset", schedule: "*-*-* 04:00 Etc/GMT+5", syntax: systemdSyntax, expectedSchedule: "0 4 * * *", expectedTimeZone: "Etc/GMT+5"},
+		{name: "systemd shorthand with a time", schedule: "daily 04:00 UTC", syntax: systemdSyntax, expectedError: true},
+		{name: "systemd UTC time", schedule: "04:00 UTC", syntax: systemdSyntax, expectedSchedule: "0 4 * * *", expectedTimeZone: "UTC"},
+		{name: "systemd invalid timezone", schedule: "04:00 Europe/Nowhere", syntax: systemdSyntax, expectedError: true},
+		{name: "systemd seconds", schedule: "*-*-* 04:00:30", syntax: systemdSyntax, expectedError: true},
+		{name: "systemd limited years", schedule: "2030-01-01 00:00", syntax: systemdSyntax, expectedError: true},
+		{name: "systemd last days of the month", schedule: "*-*~01 00:00", syntax: systemdSyntax, expectedError: true},
+		{name: "systemd day of month and day of week", schedule: "Mon *-*-01 00:00", syntax: systemdSyntax, expectedError: true},
+		{name: "systemd invalid weekday", schedule: "Someday 00:00", syntax: systemdSyntax, expectedError: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			schedule, timeZone, err := convertSchedule(testCase.schedule, testCase.syntax)
+			if testCase.expectedError {
+				if err == nil {
+					t.Fatalf("expected the schedule %s to fail to convert. Actual: %s %s", testCase.schedule, schedule, timeZone)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to convert the schedule %s . Error: %q", testCase.schedule, err)
+			}
+			if schedule != testCase.expectedSchedule || timeZone != testCase.expectedTimeZone {
+				t.Fatalf("the schedule %s was converted wrongly. Expected: %s %s Actual: %s %s", testCase.schedule, testCase.expectedSchedule, testCase.expectedTimeZone, schedule, timeZone)
+			}
+		})
+	}
+}
+
+func TestConvertAnySchedule(t *testing.T) {
+	testCases := []struct {
+		schedule         string
+		expectedSchedule string
+		expectedTimeZone string
+		expectedError    bool
+	}{
+		{schedule: "0 4 * * *", expectedSchedule: "0 4 * * *"},
+		{schedule: "every 30 minutes", expectedSchedule: "*/30 * * * *"},
+		{schedule: "Sat..Sun 10:00 Etc/GMT-2", expectedSchedule: "0 10 * * 6-7", expectedTimeZone: "Etc/GMT-2"},
+		{schedule: "sometimes", expectedError: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.schedule, func(t *testing.T) {
+			schedule, timeZone, err := convertAnySchedule(testCase.schedule)
+			if testCase.expectedError {
+				if err == nil {
+					t.Fatalf("expected the schedule %s to fail to convert. Actual: %s %s", testCase.schedule, schedule, timeZone)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to convert the schedule %s . Error: %q", testCase.schedule, err)
+			}
+			if schedule != testCase.expectedSchedule || timeZone != testCase.expectedTimeZone {
+				t.Fatalf("the schedule %s was converted wrongly. Expected: %s %s Actual: %s %s", testCase.schedule, testCase.expectedSchedule, testCase.expectedTimeZone, schedule, timeZone)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/filesystem"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	debugConsumedArtifactsFile = "consumed-artifacts.yaml"
+	debugProducedArtifactsFile = "produced-artifacts.yaml"
+	debugPathMappingsFile      = "path-mappings.yaml"
+	debugErrorFile             = "error.txt"
+	// debugEnvironmentDir has the copy of the temporary files of the environment of the transformer
+	debugEnvironmentDir = "environment"
+)
+
+var (
+	// debugSnapshotCount numbers the snapshots, since a transformer can run more than once in an iteration
+	debugSnapshotCount      int
+	debugSnapshotCountMutex sync.Mutex
+)
+
+// writeDebugSnapshot writes the artifacts that a transformer run consumed and produced, and the path mappings it created, to the debug artifacts directory.
+// The temporary files of the environment are copied as well if common.DebugArtifactsEnvironment is set.
+func writeDebugSnapshot(iteration int, tConfig transformertypes.Transformer, env *environment.Environment, consumedArtifacts, producedArtifacts []transformertypes.Artifact, pathMappings []transformertypes.PathMapping, transformErr error) {
+	if common.DebugArtifactsDir == "" {
+		return
+	}
+	debugSnapshotCountMutex.Lock()
+	debugSnapshotCount++
+	snapshotName := fmt.Sprintf("%03d-%s", debugSnapshotCount, tConfig.Name)
+	debugSnapshotCountMutex.Unlock()
+	snapshotDir := filepath.Join(common.DebugArtifactsDir, fmt.Sprintf("iteration-%d", iteration), snapshotName)
+	if err := os.MkdirAll(snapshotDir, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("failed to create the debug artifacts directory at path %s . Error: %q", snapshotDir, err)
+		return
+	}
+	files := map[string]interface{}{
+		debugConsumedArtifactsFile: consumedArtifacts,
+		debugProducedArtifactsFile: producedArtifacts,
+		debugPathMappingsFile:      pathMappings,
+	}
+	for fileName, data := range files {
+		if err := common.WriteYaml(filepath.Join(snapshotDir, fileName), data); err != nil {
+			logrus.Errorf("failed to write the debug artifacts of the transformer %s to the directory %s . Error: %q", tConfig.Name, snapshotDir, err)
+		}
+	}
+	if transformErr != nil {
+		if err := os.WriteFile(filepath.Join(snapshotDir, debugErrorFile), []byte(transformErr.Error()+"\n"), common.DefaultFilePermission); err != nil {
+			logrus.Errorf("failed to write the error of the transformer %s to the directory %s . Error: %q", tConfig.Name, snapshotDir, err)
+		}
+	}
+	if common.DebugArtifactsEnvironment && env != nil && env.TempPath != "" {
+		if err := filesystem.Replicate(env.TempPath, filepath.Join(snapshotDir, debugEnvironmentDir)); err != nil {
+			logrus.Errorf("failed to copy the environment of the transformer %s to the directory %s . Error: %q", tConfig.Name, snapshotDir, err)
+		}
+	}
+	logrus.Debugf("Wrote the debug artifacts of the transformer %s to %s", tConfig.Name, snapshotDir)
+}
@@ -0,0 +1,190 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// devWatchInterval is the time between two checks for changes in the customizations directory in watch mode
+	devWatchInterval = 2 * time.Second
+)
+
+var (
+	// devCustomizationsDir is the customizations directory that the custom transformers are reloaded from in dev mode
+	devCustomizationsDir string
+	// devTransformerHashes are the hashes of the directories of the custom transformers when they were last loaded
+	devTransformerHashes = map[string]string{}
+)
+
+// EnableDevReload reloads the custom transformers whenever their yaml, templates or scripts change in the customizations directory.
+// The changes are detected before the transformer processes the next set of artifacts.
+func EnableDevReload(customizationsDir string) {
+	if customizationsDir == "" {
+		logrus.Warnf("there is no customizations directory. There are no custom transformers to reload in dev mode.")
+		return
+	}
+	devCustomizationsDir = customizationsDir
+	for _, t := range transformers {
+		tc, _ := t.GetConfig()
+		if !isCustomTransformer(tc.Spec.FilePath) {
+			continue
+		}
+		hash, err := getDirHash(getCustomizationsSourceDir(tc.Spec.FilePath))
+		if err != nil {
+			logrus.Warnf("unable to watch the transformer %s for changes. Error: %q", tc.Name, err)
+			continue
+		}
+		devTransformerHashes[tc.Name] = hash
+	}
+	logrus.Infof("Dev mode: the custom transformers in %s will be reloaded when they change", customizationsDir)
+}
+
+// reloadIfChanged returns the transformer reinitialized from its files in the customizations directory if they changed since it was loaded
+func reloadIfChanged(t Transformer) Transformer {
+	if devCustomizationsDir == "" {
+		return t
+	}
+	tc, env := t.GetConfig()
+	oldHash, ok := devTransformerHashes[tc.Name]
+	if !ok {
+		return t
+	}
+	hash, err := getDirHash(getCustomizationsSourceDir(tc.Spec.FilePath))
+	if err != nil || hash == oldHash {
+		return t
+	}
+	devTransformerHashes[tc.Name] = hash
+	if err := filesystem.Replicate(devCustomizationsDir, getCustomAssetsPath()); err != nil {
+		logrus.Errorf("failed to copy the changes in the customizations directory %s . Using the earlier config of the transformer %s . Error: %q", devCustomizationsDir, tc.Name, err)
+		return t
+	}
+	logrus.Infof("Dev mode: reloading the transformer %s since its files changed", tc.Name)
+	// the template libraries in the customizations may have changed as well
+	templateLibraries = nil
+	newTc, err := getTransformerConfig(tc.Spec.FilePath)
+	if err != nil {
+		logrus.Errorf("failed to reload the transformer config at path %s . Using the earlier config. Error: %q", tc.Spec.FilePath, err)
+		return t
+	}
+	if newTc.Name != tc.Name {
+		logrus.Errorf("the name of the transformer %s cannot be changed to %s in dev mode. Using the earlier config.", tc.Name, newTc.Name)
+		return t
+	}
+	newT, err := newTransformer(env.Ctx, newTc, env.Source, env.Output, env.ProjectName)
+	if err != nil {
+		logrus.Errorf("failed to reload the transformer %s . Using the earlier config. Error: %q", tc.Name, err)
+		return t
+	}
+	if err := env.Destroy(); err != nil {
+		logrus.Errorf("Unable to destroy environment : %s", err)
+	}
+	for i, existing := range transformers {
+		if existing == t {
+			transformers[i] = newT
+		}
+	}
+	transformerMap[tc.Name] = newT
+	return newT
+}
+
+// WaitForCustomizationChanges blocks until the files in the customizations directory change or the context is cancelled
+func WaitForCustomizationChanges(ctx context.Context, customizationsDir string) error {
+	oldHash, err := getDirHash(customizationsDir)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Watch mode: waiting for changes in %s . Press Ctrl+C to stop.", customizationsDir)
+	ticker := time.NewTicker(devWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			hash, err := getDirHash(customizationsDir)
+			if err != nil {
+				logrus.Debugf("failed to check the customizations directory %s for changes. Error: %q", customizationsDir, err)
+				continue
+			}
+			if hash != oldHash {
+				return nil
+			}
+		}
+	}
+}
+
+func getCustomAssetsPath() string {
+	assetsPath, err := filepath.Abs(common.AssetsPath)
+	if err != nil {
+		assetsPath = common.AssetsPath
+	}
+	return filepath.Join(assetsPath, "custom")
+}
+
+// getCustomizationsSourceDir returns the directory in the customizations directory that the custom transformer was copied from
+func getCustomizationsSourceDir(transformerFilePath string) string {
+	relPath, err := filepath.Rel(getCustomAssetsPath(), filepath.Dir(transformerFilePath))
+	if err != nil {
+		return filepath.Dir(transformerFilePath)
+	}
+	return filepath.Join(devCustomizationsDir, relPath)
+}
+
+func isCustomTransformer(transformerFilePath string) bool {
+	return common.IsParent(transformerFilePath, getCustomAssetsPath())
+}
+
+// getDirHash returns a hash of the paths and the contents of the files in a directory
+func getDirHash(dir string) (string, error) {
+	hasher := sha256.New()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hasher, "%s:%o\n", filepath.ToSlash(relPath), info.Mode())
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(hasher, f)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash the directory %s . Error: %q", dir, err)
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
@@ -0,0 +1,108 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestGetDirHash(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "transformer.yaml"), []byte("kind: Transformer\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the transformer yaml. Error: %q", err)
+	}
+	hash, err := getDirHash(dir)
+	if err != nil {
+		t.Fatalf("failed to hash the directory. Error: %q", err)
+	}
+	if unchangedHash, err := getDirHash(dir); err != nil || unchangedHash != hash {
+		t.Fatalf("the hash of an unchanged directory changed. Expected: %s Actual: %s Error: %v", hash, unchangedHash, err)
+	}
+	changes := []struct {
+		name   string
+		change func() error
+	}{
+		{name: "change a file", change: func() error {
+			return os.WriteFile(filepath.Join(dir, "transformer.yaml"), []byte("kind: Transformer\nspec: {}\n"), common.DefaultFilePermission)
+		}},
+		{name: "add a file", change: func() error {
+			return os.WriteFile(filepath.Join(dir, "detect.sh"), []byte("echo\n"), common.DefaultFilePermission)
+		}},
+		{name: "make a script executable", change: func() error {
+			return os.Chmod(filepath.Join(dir, "detect.sh"), common.DefaultExecutablePermission)
+		}},
+		{name: "rename a file", change: func() error {
+			return os.Rename(filepath.Join(dir, "detect.sh"), filepath.Join(dir, "transform.sh"))
+		}},
+	}
+	for _, change := range changes {
+		if err := change.change(); err != nil {
+			t.Fatalf("failed to %s . Error: %q", change.name, err)
+		}
+		newHash, err := getDirHash(dir)
+		if err != nil {
+			t.Fatalf("failed to hash the directory. Error: %q", err)
+		}
+		if newHash == hash {
+			t.Fatalf("expected the hash to change when we %s", change.name)
+		}
+		hash = newHash
+	}
+}
+
+func TestGetCustomizationsSourceDir(t *testing.T) {
+	oldAssetsPath, oldCustomizationsDir := common.AssetsPath, devCustomizationsDir
+	t.Cleanup(func() { common.AssetsPath, devCustomizationsDir = oldAssetsPath, oldCustomizationsDir })
+	common.AssetsPath = t.TempDir()
+	devCustomizationsDir = filepath.Join(t.TempDir(), "customizations")
+	transformerFilePath := filepath.Join(common.AssetsPath, "custom", "mytransformer", "transformer.yaml")
+	if !isCustomTransformer(transformerFilePath) {
+		t.Fatalf("expected the transformer at path %s to be a custom transformer", transformerFilePath)
+	}
+	if isCustomTransformer(filepath.Join(common.AssetsPath, "built-in", "transformers", "transformer.yaml")) {
+		t.Fatalf("expected the built-in transformer to not be a custom transformer")
+	}
+	if dir := getCustomizationsSourceDir(transformerFilePath); dir != filepath.Join(devCustomizationsDir, "mytransformer") {
+		t.Fatalf("the source directory of the custom transformer is incorrect. Actual: %s", dir)
+	}
+}
+
+func TestWaitForCustomizationChanges(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := WaitForCustomizationChanges(ctx, dir); err != context.DeadlineExceeded {
+		t.Fatalf("expected the wait to stop when the context is done. Actual: %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 3*devWatchInterval)
+	defer cancel()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		if err := os.WriteFile(filepath.Join(dir, "transformer.yaml"), []byte("kind: Transformer\n"), common.DefaultFilePermission); err != nil {
+			t.Errorf("failed to write the transformer yaml. Error: %q", err)
+		}
+	}()
+	if err := WaitForCustomizationChanges(ctx, dir); err != nil {
+		t.Fatalf("expected the wait to stop when the customizations change. Error: %q", err)
+	}
+}
@@ -17,16 +17,29 @@
 package dockerfile
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/scripts"
 	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	buildImagesFileName = "builddockerimages"
+)
+
+// buildScriptPathTypes are the path types used for the script and its context in each script format
+var buildScriptPathTypes = map[string][2]transformertypes.PathType{
+	common.ShExt:  {artifacts.ContainerImageBuildShScriptPathType, artifacts.ContainerImageBuildShScriptContextPathType},
+	common.BatExt: {artifacts.ContainerImageBuildBatScriptPathType, artifacts.ContainerImageBuildBatScriptContextPathType},
+	common.Ps1Ext: {artifacts.ContainerImageBuildPs1ScriptPathType, artifacts.ContainerImageBuildPs1ScriptContextPathType},
+}
+
 // DockerfileImageBuildScript implements Transformer interface
 type DockerfileImageBuildScript struct {
 	Config transformertypes.Transformer
@@ -37,6 +50,7 @@ type DockerfileImageBuildScript struct {
 type DockerfileImageBuildScriptTemplateConfig struct {
 	DockerfileName   string
 	ImageName        string
+	TaggedImageName  string
 	ContextUnix      string
 	ContextWindows   string
 	ContainerRuntime string
@@ -93,28 +107,30 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 					continue
 				}
 			}
-			if common.IsParent(dockerfilePath, t.Env.GetEnvironmentSource()) {
-				relDockerContextPath, err := filepath.Rel(t.Env.GetEnvironmentSource(), filepath.Dir(dockerfilePath))
+			if common.IsParent(dockerContextPath, t.Env.GetEnvironmentSource()) {
+				relDockerContextPath, err := filepath.Rel(t.Env.GetEnvironmentSource(), dockerContextPath)
 				if err != nil {
-					logrus.Errorf("failed to make the path %s relative to the base path %s . Error: %q", filepath.Dir(dockerfilePath), t.Env.GetEnvironmentSource(), err)
+					logrus.Errorf("failed to make the path %s relative to the base path %s . Error: %q", dockerContextPath, t.Env.GetEnvironmentSource(), err)
 					continue
 				}
 				t1 := DockerfileImageBuildScriptTemplateConfig{
 					ImageName:        imageName.ImageName,
+					TaggedImageName:  common.TagNewImageName(imageName.ImageName),
 					ContextUnix:      common.GetUnixPath(filepath.Join(common.DefaultSourceDir, relDockerContextPath)),
 					ContextWindows:   common.GetWindowsPath(filepath.Join(common.DefaultSourceDir, relDockerContextPath)),
 					DockerfileName:   relDockerfilePath,
 					ContainerRuntime: commonqa.GetContainerRuntime(),
 				}
 				dockerfiles = append(dockerfiles, t1)
-			} else if common.IsParent(dockerfilePath, t.Env.GetEnvironmentOutput()) {
-				relDockerContextPath, err := filepath.Rel(t.Env.GetEnvironmentOutput(), filepath.Dir(dockerfilePath))
+			} else if common.IsParent(dockerContextPath, t.Env.GetEnvironmentOutput()) {
+				relDockerContextPath, err := filepath.Rel(t.Env.GetEnvironmentOutput(), dockerContextPath)
 				if err != nil {
-					logrus.Errorf("failed to make the path %s relative to the base path %s . Error: %q", filepath.Dir(dockerfilePath), t.Env.GetEnvironmentOutput(), err)
+					logrus.Errorf("failed to make the path %s relative to the base path %s . Error: %q", dockerContextPath, t.Env.GetEnvironmentOutput(), err)
 					continue
 				}
 				t2 := DockerfileImageBuildScriptTemplateConfig{
 					ImageName:        imageName.ImageName,
+					TaggedImageName:  common.TagNewImageName(imageName.ImageName),
 					ContextUnix:      common.GetUnixPath(relDockerContextPath),
 					ContextWindows:   common.GetWindowsPath(relDockerContextPath),
 					DockerfileName:   relDockerfilePath,
@@ -124,6 +140,7 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 			} else {
 				t3 := DockerfileImageBuildScriptTemplateConfig{
 					ImageName:        imageName.ImageName,
+					TaggedImageName:  common.TagNewImageName(imageName.ImageName),
 					ContextUnix:      common.GetUnixPath(filepath.Join(common.DefaultSourceDir, dockerContextPath)),
 					ContextWindows:   common.GetWindowsPath(filepath.Join(common.DefaultSourceDir, dockerContextPath)),
 					DockerfileName:   relDockerfilePath,
@@ -145,20 +162,25 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 	if len(dockerfiles) == 0 {
 		return nil, nil, nil
 	}
-	pathMappings = append(pathMappings, transformertypes.PathMapping{
-		Type:           transformertypes.TemplatePathMappingType,
-		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
-		DestPath:       common.ScriptsDir,
-		TemplateConfig: dockerfiles,
-	})
+	selectedFormats := scripts.GetSelectedFormats()
+	scriptPathMappings, err := scripts.GetPathMappings(filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir), common.ScriptsDir, dockerfiles, selectedFormats)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create the path mappings for the image build scripts. Error: %q", err)
+	}
+	pathMappings = append(pathMappings, scriptPathMappings...)
+	scriptPaths := map[transformertypes.PathType][]string{}
+	for _, format := range selectedFormats {
+		pathTypes, ok := buildScriptPathTypes[format.Extension]
+		if !ok {
+			continue
+		}
+		scriptPaths[pathTypes[0]] = []string{filepath.Join(common.ScriptsDir, buildImagesFileName+format.Extension)}
+		scriptPaths[pathTypes[1]] = []string{"."}
+	}
 	createdArtifacts = append(createdArtifacts, transformertypes.Artifact{
-		Name: string(artifacts.ContainerImageBuildScriptArtifactType),
-		Type: artifacts.ContainerImageBuildScriptArtifactType,
-		Paths: map[transformertypes.PathType][]string{artifacts.ContainerImageBuildShScriptPathType: {filepath.Join(common.ScriptsDir, "builddockerimages.sh")},
-			artifacts.ContainerImageBuildShScriptContextPathType:  {"."},
-			artifacts.ContainerImageBuildBatScriptPathType:        {filepath.Join(common.ScriptsDir, "builddockerimages.bat")},
-			artifacts.ContainerImageBuildBatScriptContextPathType: {"."},
-		},
+		Name:  string(artifacts.ContainerImageBuildScriptArtifactType),
+		Type:  artifacts.ContainerImageBuildScriptArtifactType,
+		Paths: scriptPaths,
 	})
 	return pathMappings, createdArtifacts, nil
 }
@@ -17,11 +17,13 @@
 package dockerfile
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/healthcheck"
 	"github.com/konveyor/move2kube/environment"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
@@ -148,6 +150,11 @@ func (t *DockerfileParser) getIRFromDockerfile(dockerfilepath, contextPath, imag
 		irService.AddPortForwarding(servicePort, podPort, "")
 	}
 	serviceContainer.Ports = serviceContainerPorts
+	if probe, err := t.getHealthCheck(df); err != nil {
+		logrus.Warnf("Unable to parse the HEALTHCHECK in Dockerfile %s . Error: %q", dockerfilepath, err)
+	} else {
+		healthcheck.SetProbes(&serviceContainer, probe)
+	}
 	irService.Containers = []core.Container{serviceContainer}
 	if t.isWindowsContainer(df) {
 		irService.Annotations = map[string]string{common.WindowsAnnotation: common.AnnotationLabelValue}
@@ -184,6 +191,47 @@ func (t *DockerfileParser) getDockerFileAST(path string) (*dockerparser.Result,
 	return res, err
 }
 
+// getHealthCheck translates the last HEALTHCHECK of the Dockerfile into a probe
+func (t *DockerfileParser) getHealthCheck(df *dockerparser.Result) (*core.Probe, error) {
+	var node *dockerparser.Node
+	for _, dfchild := range df.AST.Children {
+		if strings.EqualFold(dfchild.Value, "HEALTHCHECK") {
+			node = dfchild
+		}
+	}
+	if node == nil || node.Next == nil {
+		return nil, nil
+	}
+	check := healthcheck.Check{Test: []string{strings.ToUpper(node.Next.Value)}}
+	args := []string{}
+	for arg := node.Next.Next; arg != nil; arg = arg.Next {
+		args = append(args, arg.Value)
+	}
+	if check.Test[0] == "CMD" && !node.Attributes["json"] {
+		check.Test = []string{"CMD-SHELL", strings.Join(args, " ")}
+	} else {
+		check.Test = append(check.Test, args...)
+	}
+	for _, flag := range node.Flags {
+		name, value, _ := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		switch name {
+		case "interval":
+			check.Interval = value
+		case "timeout":
+			check.Timeout = value
+		case "start-period":
+			check.StartPeriod = value
+		case "retries":
+			retries, err := cast.ToIntE(value)
+			if err != nil {
+				return nil, fmt.Errorf("the retries %s are not a number. Error: %q", value, err)
+			}
+			check.Retries = retries
+		}
+	}
+	return healthcheck.GetProbe(check)
+}
+
 func (t *DockerfileParser) isWindowsContainer(df *dockerparser.Result) bool {
 	for _, dfchild := range df.AST.Children {
 		if strings.EqualFold(dfchild.Value, "FROM") {
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/toolchain"
 	"github.com/konveyor/move2kube/environment"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/konveyor/move2kube/types/qaengine/commonqa"
@@ -143,9 +144,12 @@ func (t *GolangDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 			logrus.Errorf("Error while parsing the go.mod file : %s", err)
 			return nil, nil, nil
 		}
-		if modFile.Go == nil {
-			logrus.Debugf("Didn't find the Go version in the go.mod file at path %s, selecting Go version %s", a.Paths[GolangModFilePathType][0], t.GolangConfig.DefaultGoVersion)
-			modFile.Go.Version = t.GolangConfig.DefaultGoVersion
+		goVersion := ""
+		if modFile.Go != nil {
+			goVersion = modFile.Go.Version
+		} else if goVersion = toolchain.GetVersion(toolchain.Golang, a.Paths[artifacts.ServiceDirPathType][0], t.Env.GetEnvironmentSource()); goVersion == "" {
+			logrus.Debugf("Didn't find the Go version in the go.mod file at path %s", a.Paths[GolangModFilePathType][0])
+			goVersion = toolchain.AskVersion(a.Name, toolchain.Golang, t.GolangConfig.DefaultGoVersion, nil)
 		}
 		detectedPorts := ir.GetAllServicePorts()
 		if len(detectedPorts) == 0 {
@@ -155,7 +159,7 @@ func (t *GolangDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 		var golangConfig GolangTemplateConfig
 		golangConfig.AppName = a.Name
 		golangConfig.Ports = detectedPorts
-		golangConfig.GoVersion = modFile.Go.Version
+		golangConfig.GoVersion = goVersion
 
 		if sImageName.ImageName == "" {
 			sImageName.ImageName = common.MakeStringContainerImageNameCompliant(sConfig.ServiceName)
@@ -25,6 +25,8 @@ import (
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/eol"
+	"github.com/konveyor/move2kube/common/toolchain"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/transformer/dockerfilegenerator/java/gradle"
@@ -341,6 +343,8 @@ func (t *GradleAnalyser) TransformArtifact(newArtifact transformertypes.Artifact
 	lowestJavaVersion := ""
 	imageToCopyFrom := gradleConfig.RootProjectName + "-" + buildStageC
 	serviceRootDir := newArtifact.Paths[artifacts.ServiceRootDirPathType][0]
+	// the java version pinned by the version files is used for the child modules that do not set one in their build.gradle
+	pinnedJavaVersion := toolchain.GetVersion(toolchain.Java, serviceRootDir, t.Env.GetEnvironmentSource())
 
 	for _, childModule := range gradleConfig.ChildModules {
 
@@ -367,6 +371,14 @@ func (t *GradleAnalyser) TransformArtifact(newArtifact transformertypes.Artifact
 			continue
 		}
 
+		if childModuleInfo.JavaVersion == "" {
+			if pinnedJavaVersion == "" {
+				pinnedJavaVersion = toolchain.AskVersion(gradleConfig.RootProjectName, toolchain.Java, t.GradleConfig.JavaVersion, nil)
+			}
+			childModuleInfo.JavaVersion = pinnedJavaVersion
+		}
+		childModuleInfo.JavaVersion = eol.CheckRuntimeVersion(childModule.Name, eol.Java, childModuleInfo.JavaVersion)
+
 		// Find the lowest java version among all of the child modules.
 		// We will use this java version while doing the build.
 
@@ -185,7 +185,7 @@ func (t *JarAnalyser) Transform(newArtifacts []transformertypes.Artifact, alread
 			BuildContainerName: buildContainerName,
 			DeploymentFilePath: jarArtifactConfig.DeploymentFilePath,
 			DeploymentFilename: filepath.Base(jarArtifactConfig.DeploymentFilePath),
-			EnvVariables:       jarArtifactConfig.EnvVariables,
+			EnvVariables:       addJVMOptions(jarArtifactConfig.EnvVariables, javaPackage),
 		}
 		// Fill the Dockerfile template using a pathmapping.
 		writeDockerfilePathMapping := transformertypes.PathMapping{
@@ -133,7 +133,7 @@ func (t *Jboss) Transform(newArtifacts []transformertypes.Artifact, alreadySeenA
 			templateData.JavaPackageName = javaPackage
 			templateData.DeploymentFilePath = warConfig.DeploymentFilePath
 			templateData.Port = defaultJbossPort
-			templateData.EnvVariables = warConfig.EnvVariables
+			templateData.EnvVariables = addJVMOptions(warConfig.EnvVariables, javaPackage)
 			templateData.BuildContainerName = warConfig.BuildContainerName
 		} else {
 			// EAR
@@ -150,7 +150,7 @@ func (t *Jboss) Transform(newArtifacts []transformertypes.Artifact, alreadySeenA
 			templateData.JavaPackageName = javaPackage
 			templateData.DeploymentFilePath = earConfig.DeploymentFilePath
 			templateData.Port = defaultJbossPort
-			templateData.EnvVariables = earConfig.EnvVariables
+			templateData.EnvVariables = addJVMOptions(earConfig.EnvVariables, javaPackage)
 			templateData.BuildContainerName = earConfig.BuildContainerName
 		}
 		pathMappings = append(pathMappings, transformertypes.PathMapping{
@@ -155,7 +155,7 @@ func (t *Liberty) Transform(newArtifacts []transformertypes.Artifact, alreadySee
 			templateData.JavaPackageName = javaPackage
 			templateData.JavaVersion = warConfig.JavaVersion
 			templateData.Port = defaultLibertyPort
-			templateData.EnvVariables = warConfig.EnvVariables
+			templateData.EnvVariables = addJVMOptions(warConfig.EnvVariables, javaPackage)
 			templateData.DeploymentFilePath = warConfig.DeploymentFilePath
 			templateData.BuildContainerName = warConfig.BuildContainerName
 		} else {
@@ -176,7 +176,7 @@ func (t *Liberty) Transform(newArtifacts []transformertypes.Artifact, alreadySee
 			templateData.JavaPackageName = javaPackage
 			templateData.JavaVersion = earConfig.JavaVersion
 			templateData.Port = defaultLibertyPort
-			templateData.EnvVariables = earConfig.EnvVariables
+			templateData.EnvVariables = addJVMOptions(earConfig.EnvVariables, javaPackage)
 			templateData.DeploymentFilePath = earConfig.DeploymentFilePath
 			templateData.BuildContainerName = earConfig.BuildContainerName
 		}
@@ -23,6 +23,8 @@ import (
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/eol"
+	"github.com/konveyor/move2kube/common/toolchain"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/qaengine"
 	irtypes "github.com/konveyor/move2kube/types/ir"
@@ -285,6 +287,8 @@ func (t *MavenAnalyser) TransformArtifact(newArtifact transformertypes.Artifact,
 	lowestJavaVersion := ""
 	imageToCopyFrom := mavenConfig.MavenAppName + "-" + buildStageC
 	serviceRootDir := newArtifact.Paths[artifacts.ServiceRootDirPathType][0]
+	// the java version pinned by the version files is used for the child modules that do not set one in their pom.xml
+	pinnedJavaVersion := toolchain.GetVersion(toolchain.Java, serviceRootDir, t.Env.GetEnvironmentSource())
 
 	for _, childModule := range mavenConfig.ChildModules {
 
@@ -311,6 +315,14 @@ func (t *MavenAnalyser) TransformArtifact(newArtifact transformertypes.Artifact,
 			continue
 		}
 
+		if childModuleInfo.JavaVersion == "" {
+			if pinnedJavaVersion == "" {
+				pinnedJavaVersion = toolchain.AskVersion(mavenConfig.MavenAppName, toolchain.Java, t.MavenConfig.JavaVersion, nil)
+			}
+			childModuleInfo.JavaVersion = pinnedJavaVersion
+		}
+		childModuleInfo.JavaVersion = eol.CheckRuntimeVersion(childModule.Name, eol.Java, childModuleInfo.JavaVersion)
+
 		// Find the lowest java version among all of the child modules.
 		// We will use this java version while doing the build.
 
@@ -526,6 +538,10 @@ func getInfoFromPom(pom, parentPom *maven.Pom, pomFilePath string, mavenConfig *
 		}
 	}
 	javaVersion := getJavaVersionFromPom(pom)
+	if javaVersion == "" {
+		// the child modules inherit the properties and the plugins of the parent pom.xml
+		javaVersion = getJavaVersionFromPom(parentPom)
+	}
 	mavenProfiles := []string{}
 	if pom.Profiles != nil {
 		for _, profile := range *pom.Profiles {
@@ -604,12 +620,14 @@ func getDeploymentFilePathFromPom(pom *maven.Pom, pomFileDir string) (string, er
 		if pom.Build.FinalName != "" {
 			return filepath.Join(pomFileDir, MAVEN_DEFAULT_BUILD_DIR, pom.Build.FinalName+"."+packaging), nil
 		}
-		for _, plugin := range *pom.Build.Plugins {
-			if plugin.ArtifactID != MAVEN_COMPILER_PLUGIN {
-				continue
-			}
-			if plugin.Configuration.FinalName != "" {
-				return filepath.Join(pomFileDir, MAVEN_DEFAULT_BUILD_DIR, plugin.Configuration.FinalName+"."+packaging), nil
+		if pom.Build.Plugins != nil {
+			for _, plugin := range *pom.Build.Plugins {
+				if plugin.ArtifactID != MAVEN_COMPILER_PLUGIN {
+					continue
+				}
+				if plugin.Configuration.FinalName != "" {
+					return filepath.Join(pomFileDir, MAVEN_DEFAULT_BUILD_DIR, plugin.Configuration.FinalName+"."+packaging), nil
+				}
 			}
 		}
 	}
@@ -625,27 +643,20 @@ func getJavaVersionFromPom(pom *maven.Pom) string {
 		return ""
 	}
 	if pom.Properties != nil {
-		jv, ok := pom.Properties.Entries["java.version"]
-		if ok && jv != "" {
-			return jv
-		}
-		jv, ok = pom.Properties.Entries["maven.compiler.target"]
-		if ok && jv != "" {
-			return jv
-		}
-		jv, ok = pom.Properties.Entries["maven.compiler.source"]
-		if ok && jv != "" {
-			return jv
+		// the release takes precedence over the source and the target, the same as in the compiler plugin
+		for _, property := range []string{"maven.compiler.release", "java.version", "maven.compiler.target", "maven.compiler.source"} {
+			if jv := resolvePomProperty(pom, pom.Properties.Entries[property]); jv != "" {
+				return jv
+			}
 		}
 	}
-	if pom.Build.Plugins != nil {
+	if pom.Build != nil && pom.Build.Plugins != nil {
 		for _, plugin := range *pom.Build.Plugins {
 			if plugin.ArtifactID == MAVEN_COMPILER_PLUGIN {
-				if plugin.Configuration.Target != "" {
-					return plugin.Configuration.Target
-				}
-				if plugin.Configuration.Source != "" {
-					return plugin.Configuration.Source
+				for _, jv := range []string{plugin.Configuration.Release, plugin.Configuration.Target, plugin.Configuration.Source} {
+					if jv = resolvePomProperty(pom, jv); jv != "" {
+						return jv
+					}
 				}
 			}
 		}
@@ -653,6 +664,17 @@ func getJavaVersionFromPom(pom *maven.Pom) string {
 	return ""
 }
 
+// resolvePomProperty returns the value of a property reference like ${java.version}, or the value itself if it is not a reference
+func resolvePomProperty(pom *maven.Pom, value string) string {
+	if !strings.HasPrefix(value, "${") || !strings.HasSuffix(value, "}") {
+		return value
+	}
+	if pom.Properties == nil {
+		return ""
+	}
+	return pom.Properties.Entries[strings.TrimSuffix(strings.TrimPrefix(value, "${"), "}")]
+}
+
 func packagingToArtifactType(packaging artifacts.JavaPackaging) (transformertypes.ArtifactType, error) {
 	switch strings.ToLower(string(packaging)) {
 	case string(artifacts.JarPackaging):
@@ -148,7 +148,7 @@ func (t *Tomcat) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 			JavaVersion:        warConfig.JavaVersion,
 			DeploymentFilePath: warConfig.DeploymentFilePath,
 			Port:               tomcatDefaultPort,
-			EnvVariables:       warConfig.EnvVariables,
+			EnvVariables:       addJVMOptions(warConfig.EnvVariables, javaPackage),
 			BuildContainerName: warConfig.BuildContainerName,
 		}
 		pathMappings = append(pathMappings, transformertypes.PathMapping{
@@ -18,6 +18,7 @@ package java
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/qaengine"
@@ -41,6 +42,26 @@ const (
 	defaultJavaPackage        = "java-17-openjdk-devel"
 )
 
+// addJVMOptions adds the container aware JVM flags for the installed java package to the environment variables of the Dockerfile.
+// The flags can be overridden in the cluster, where they are set from the memory limits of the containers.
+func addJVMOptions(envVariables map[string]string, javaPackage string) map[string]string {
+	if _, ok := envVariables[common.JavaToolOptionsEnvName]; ok {
+		return envVariables
+	}
+	// the packages are named like java-1.8.0-openjdk-devel and java-17-openjdk-devel
+	javaVersion := strings.TrimPrefix(javaPackage, "java-")
+	memoryOptions := common.GetJVMMemoryOptions(javaVersion, 0)
+	if memoryOptions == "" {
+		logrus.Warnf("java %s is not container aware, so the heap of the JVM needs to be set using -Xmx to stay within the memory limit of the container", javaVersion)
+		return envVariables
+	}
+	newEnvVariables := map[string]string{common.JavaToolOptionsEnvName: memoryOptions}
+	for k, v := range envVariables {
+		newEnvVariables[k] = v
+	}
+	return newEnvVariables
+}
+
 func getJavaPackage(mappingFile string, version string) (pkg string, err error) {
 	var javaPackageNamesMapping JavaPackageNamesMapping
 	if err := common.ReadMove2KubeYaml(mappingFile, &javaPackageNamesMapping); err != nil {
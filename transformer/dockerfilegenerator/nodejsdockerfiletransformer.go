@@ -25,6 +25,8 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/eol"
+	"github.com/konveyor/move2kube/common/toolchain"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/types"
 	irtypes "github.com/konveyor/move2kube/types/ir"
@@ -203,13 +205,17 @@ func (t *NodejsDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 			build = true
 		}
 		nodeVersion := t.NodejsConfig.DefaultNodejsVersion
+		supportedVersions := common.Map(t.Spec.NodeVersions, func(x map[string]string) string { return x[versionKey] })
 		if nodeVersionConstraint, ok := packageJSON.Engines["node"]; ok {
-			nodeVersion = getNodeVersion(
-				nodeVersionConstraint,
-				t.NodejsConfig.DefaultNodejsVersion,
-				common.Map(t.Spec.NodeVersions, func(x map[string]string) string { return x[versionKey] }),
-			)
+			nodeVersion = getNodeVersion(nodeVersionConstraint, t.NodejsConfig.DefaultNodejsVersion, supportedVersions)
 			logrus.Debugf("Selected nodeVersion is - %s", nodeVersion)
+			nodeVersion = eol.CheckRuntimeVersion(newArtifact.Name, eol.Nodejs, nodeVersion)
+		} else if pinnedVersion := toolchain.GetVersion(toolchain.Nodejs, serviceDir, t.Env.GetEnvironmentSource()); pinnedVersion != "" {
+			nodeVersion = getPinnedNodeVersion(pinnedVersion, t.NodejsConfig.DefaultNodejsVersion, supportedVersions)
+			logrus.Debugf("Selected nodeVersion is - %s", nodeVersion)
+			nodeVersion = eol.CheckRuntimeVersion(newArtifact.Name, eol.Nodejs, nodeVersion)
+		} else {
+			nodeVersion = toolchain.AskVersion(newArtifact.Name, toolchain.Nodejs, t.NodejsConfig.DefaultNodejsVersion, supportedVersions)
 		}
 		packageManager := t.NodejsConfig.DefaultPackageManager
 		if packageJSON.PackageManager != "" {
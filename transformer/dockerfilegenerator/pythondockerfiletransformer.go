@@ -25,7 +25,10 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/hashicorp/go-version"
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/eol"
+	"github.com/konveyor/move2kube/common/toolchain"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/qaengine"
 	irtypes "github.com/konveyor/move2kube/types/ir"
@@ -39,6 +42,10 @@ const (
 	pythonExt           = ".py"
 	django              = "django"
 	requirementsTxtFile = "requirements.txt"
+	// defaultPythonVersion is offered to the projects that do not pin their python version
+	defaultPythonVersion = "3.6"
+	// defaultPythonImageTag is used when the python version cannot be parsed
+	defaultPythonImageTag = "36"
 	//RequirementsTxtPathType points to the requirements.txt file if it's present
 	RequirementsTxtPathType transformertypes.PathType = "RequirementsTxtPathType"
 	// PythonServiceConfigType points to python config
@@ -62,6 +69,8 @@ type PythonTemplateConfig struct {
 	StartingScriptRelPath string
 	RequirementsTxt       string
 	IsDjango              bool
+	// PythonImageTag is the version in the name of the python base image, like 36 for python 3.6
+	PythonImageTag string
 }
 
 // PythonConfig implements python config interface
@@ -69,8 +78,16 @@ type PythonConfig struct {
 	IsDjango bool `json:"IsDjango" yaml:"IsDjango"`
 }
 
+func init() {
+	if err := artifacts.RegisterConfigType(PythonServiceConfigType, PythonConfig{}, "Python application details"); err != nil {
+		logrus.Errorf("failed to register the config type %s . Error: %q", PythonServiceConfigType, err)
+	}
+}
+
 var (
 	pythonMainRegex = regexp.MustCompile(`^if\s+__name__\s*==\s*['"]__main__['"]\s*:\s+$`)
+	// pythonImageVersions are the python versions that have UBI base images, in increasing order
+	pythonImageVersions = []string{"2.7", "3.6", "3.8", "3.9", "3.11", "3.12"}
 )
 
 // Init Initializes the transformer
@@ -232,6 +249,12 @@ func (t *PythonDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 			logrus.Debugf("unable to load config for Transformer into %T : %s", sImageName, err)
 		}
 		pythonTemplateConfig.IsDjango = pythonConfig.IsDjango
+		pythonVersion := toolchain.GetVersion(toolchain.Python, a.Paths[artifacts.ServiceDirPathType][0], t.Env.GetEnvironmentSource())
+		if pythonVersion == "" {
+			pythonVersion = toolchain.AskVersion(a.Name, toolchain.Python, defaultPythonVersion, pythonImageVersions)
+		}
+		pythonVersion = eol.CheckRuntimeVersion(a.Name, eol.Python, pythonVersion)
+		pythonTemplateConfig.PythonImageTag = getPythonImageTag(pythonVersion)
 		ports := ir.GetAllServicePorts()
 		if len(ports) == 0 {
 			ports = []int32{common.DefaultServicePort}
@@ -284,3 +307,18 @@ func (t *PythonDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 	}
 	return pathMappings, artifactsCreated, nil
 }
+
+// getPythonImageTag returns the tag of the oldest python base image that is at least the python version
+func getPythonImageTag(pythonVersion string) string {
+	wanted, err := version.NewVersion(pythonVersion)
+	if err != nil {
+		logrus.Debugf("failed to parse the python version %s . Error: %q", pythonVersion, err)
+		return defaultPythonImageTag
+	}
+	for _, imageVersion := range pythonImageVersions {
+		if v, err := version.NewVersion(imageVersion); err == nil && !v.LessThan(wanted) {
+			return strings.ReplaceAll(imageVersion, ".", "")
+		}
+	}
+	return strings.ReplaceAll(pythonImageVersions[len(pythonImageVersions)-1], ".", "")
+}
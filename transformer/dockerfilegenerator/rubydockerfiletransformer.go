@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/toolchain"
 	"github.com/konveyor/move2kube/environment"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/konveyor/move2kube/types/qaengine/commonqa"
@@ -31,6 +32,8 @@ import (
 
 const (
 	rubyFileExt = ".rb"
+	// defaultRubyVersion is offered to the projects that do not pin their ruby version
+	defaultRubyVersion = "2.5"
 )
 
 // RubyDockerfileGenerator implements the Transformer interface
@@ -43,6 +46,8 @@ type RubyDockerfileGenerator struct {
 type RubyTemplateConfig struct {
 	Port    int32
 	AppName string
+	// RubyVersion is the tag of the ruby base image, like 3.1 or 3.1.2
+	RubyVersion string
 }
 
 // Init Initializes the transformer
@@ -119,6 +124,10 @@ func (t *RubyDockerfileGenerator) Transform(newArtifacts []transformertypes.Arti
 		}
 		rubyConfig.Port = commonqa.GetPortForService(detectedPorts, `"`+a.Name+`"`)
 		rubyConfig.AppName = a.Name
+		rubyConfig.RubyVersion = toolchain.GetVersion(toolchain.Ruby, a.Paths[artifacts.ServiceDirPathType][0], t.Env.GetEnvironmentSource())
+		if rubyConfig.RubyVersion == "" {
+			rubyConfig.RubyVersion = toolchain.AskVersion(a.Name, toolchain.Ruby, defaultRubyVersion, nil)
+		}
 		if sImageName.ImageName == "" {
 			sImageName.ImageName = common.MakeStringContainerImageNameCompliant(sConfig.ServiceName)
 		}
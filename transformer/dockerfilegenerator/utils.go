@@ -17,6 +17,8 @@
 package dockerfilegenerator
 
 import (
+	"strings"
+
 	"github.com/hashicorp/go-version"
 	"github.com/sirupsen/logrus"
 )
@@ -44,3 +46,16 @@ func getNodeVersion(versionConstraint, defaultNodejsVersion string, supportedVer
 	logrus.Infof("no supported Node version detected in package.json. Selecting default Node version- %s", defaultNodejsVersion)
 	return defaultNodejsVersion
 }
+
+// getPinnedNodeVersion returns the Node version to be used for a service that pins its version in a file like .nvmrc
+func getPinnedNodeVersion(pinnedVersion, defaultNodejsVersion string, supportedVersions []string) string {
+	if strings.HasPrefix(pinnedVersion, "lts") || !strings.ContainsAny(pinnedVersion, "0123456789") {
+		logrus.Debugf("the pinned Node version %s is an alias. Selecting default Node version- %s", pinnedVersion, defaultNodejsVersion)
+		return defaultNodejsVersion
+	}
+	if strings.Count(pinnedVersion, ".") < 2 {
+		// a partial version like 18 or 18.2 matches the supported versions with the same prefix
+		return getNodeVersion("~> "+pinnedVersion+".0", defaultNodejsVersion, supportedVersions)
+	}
+	return getNodeVersion(pinnedVersion, defaultNodejsVersion, supportedVersions)
+}
@@ -253,6 +253,12 @@ func (t *WinConsoleAppDockerfileGenerator) Transform(newArtifacts []transformert
 			logrus.Errorf("unable to load config for Transformer into %T : %s", sConfig, err)
 			continue
 		}
+		target, reportPathMappings := askDotNetTarget(t.Env, newArtifact.Name, serviceDir)
+		pathMappings = append(pathMappings, reportPathMappings...)
+		if target == modernizeTarget {
+			logrus.Infof("the dot net framework app %s is to be modernized. Not generating a Windows Dockerfile for it", newArtifact.Name)
+			continue
+		}
 		sImageName := artifacts.ImageName{}
 		err = newArtifact.GetConfig(artifacts.ImageNameConfigType, &sImageName)
 		if err != nil {
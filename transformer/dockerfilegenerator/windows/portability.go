@@ -0,0 +1,264 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package windows
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	dotnetutils "github.com/konveyor/move2kube/transformer/dockerfilegenerator/dotnet"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	windowsContainerTarget = "windowscontainer"
+	modernizeTarget        = "modernize"
+	// portableScore is the score above which a project is likely to be ported to .NET 8 on Linux with little effort
+	portableScore         = 80
+	portabilityReportDir  = "assessment" + string(os.PathSeparator) + "dotnet"
+	vbProjFileExt         = ".vbproj"
+	gacPublicKeyTokenAttr = "PublicKeyToken="
+	gacRuleName           = "Global assembly cache"
+)
+
+// portabilityRule is an API or a feature of the .NET Framework that does not work in .NET 8 on Linux
+type portabilityRule struct {
+	name    string
+	pattern *regexp.Regexp
+	// exts are the extensions of the files in which the pattern is searched
+	exts       []string
+	penalty    int
+	suggestion string
+}
+
+// portabilityFinding is a use of an API that does not work on Linux
+type portabilityFinding struct {
+	rule *portabilityRule
+	path string
+	line int
+}
+
+// projectPortability is the portability of a project to .NET 8 on Linux
+type projectPortability struct {
+	name     string
+	score    int
+	findings []portabilityFinding
+}
+
+var (
+	codeExts         = []string{".cs", ".vb"}
+	portabilityRules = []portabilityRule{
+		{name: "Windows Forms or WPF", pattern: regexp.MustCompile(`\bSystem\.Windows\.Forms\b|\bPresentationFramework\b|\bSystem\.Windows\.Controls\b`), exts: append([]string{dotnetutils.CSPROJ_FILE_EXT, vbProjFileExt}, codeExts...), penalty: 50, suggestion: "Desktop UIs do not run in containers. Move the logic that has to run in the cluster into a separate service."},
+		{name: "ASP.NET Web Forms", pattern: regexp.MustCompile(`(?i)<%@\s*Page\b`), exts: []string{".aspx", ".ascx", ".master"}, penalty: 40, suggestion: "Web Forms are not available in ASP.NET Core. Rewrite the pages with Razor Pages, MVC or Blazor."},
+		{name: "System.Web", pattern: regexp.MustCompile(`\bSystem\.Web\b|\bHttpContext\.Current\b`), exts: append([]string{dotnetutils.CSPROJ_FILE_EXT, vbProjFileExt}, codeExts...), penalty: 20, suggestion: "Migrate the application from System.Web to ASP.NET Core. The System.Web adapters can help to migrate incrementally."},
+		{name: "WCF services", pattern: regexp.MustCompile(`\bServiceHost\b|\[ServiceContract\]|<%@\s*ServiceHost\b`), exts: append([]string{".svc"}, codeExts...), penalty: 25, suggestion: "Hosting WCF services is not supported in .NET 8. Use CoreWCF, or move to gRPC or REST."},
+		{name: "Windows registry", pattern: regexp.MustCompile(`\bMicrosoft\.Win32\.Registry\b|\bRegistryKey\b|\bRegistry\.(LocalMachine|CurrentUser)\b`), exts: codeExts, penalty: 15, suggestion: "The registry does not exist on Linux. Move the settings to configuration files or environment variables, which can come from ConfigMaps."},
+		{name: gacRuleName, pattern: regexp.MustCompile(`\bSystem\.EnterpriseServices\b|\bgacutil\b|\bAssembly\.LoadWithPartialName\b`), exts: append([]string{dotnetutils.CSPROJ_FILE_EXT, vbProjFileExt}, codeExts...), penalty: 15, suggestion: "There is no GAC in .NET 8. Reference the assemblies as NuGet packages or ship them with the application."},
+		{name: "System.Drawing", pattern: regexp.MustCompile(`\bSystem\.Drawing\b`), exts: append([]string{dotnetutils.CSPROJ_FILE_EXT, vbProjFileExt}, codeExts...), penalty: 10, suggestion: "System.Drawing.Common is only supported on Windows since .NET 6. Use ImageSharp or SkiaSharp."},
+		{name: "Native interop and COM", pattern: regexp.MustCompile(`\[DllImport\(|\[ComImport\]|\bMarshal\.GetActiveObject\b`), exts: codeExts, penalty: 15, suggestion: "Native Windows libraries and COM components are not available on Linux. Replace them or keep the service in a Windows container."},
+		{name: "Windows services and management APIs", pattern: regexp.MustCompile(`\bSystem\.ServiceProcess\b|\bSystem\.Management\b|\bEventLog\b|\bPerformanceCounter\b|\bSystem\.DirectoryServices\b`), exts: append([]string{dotnetutils.CSPROJ_FILE_EXT, vbProjFileExt}, codeExts...), penalty: 10, suggestion: "Windows services, WMI, the event log, performance counters and Active Directory are not available on Linux. Use a worker service, logging to stdout, metrics and LDAP instead."},
+		{name: ".NET Remoting and app domains", pattern: regexp.MustCompile(`\bSystem\.Runtime\.Remoting\b|\bAppDomain\.CreateDomain\b`), exts: codeExts, penalty: 15, suggestion: "Remoting and app domains are not supported in .NET 8. Use gRPC or REST between services and AssemblyLoadContext for isolation."},
+	}
+)
+
+// assessPortability scores the portability of each project in the service directory to .NET 8 on Linux.
+// Each rule reduces the score once per project, however often the API is used.
+func assessPortability(serviceDir string) []projectPortability {
+	projPaths, err := common.GetFilesByExt(serviceDir, []string{dotnetutils.CSPROJ_FILE_EXT, vbProjFileExt})
+	if err != nil {
+		logrus.Errorf("failed to find the dot net projects in the directory %s . Error: %q", serviceDir, err)
+		return nil
+	}
+	sort.Strings(projPaths)
+	projects := []projectPortability{}
+	for _, projPath := range projPaths {
+		project := projectPortability{name: dotnetutils.GetChildProjectName(projPath), score: 100}
+		projDir := filepath.Dir(projPath)
+		exts := []string{}
+		for _, rule := range portabilityRules {
+			exts = append(exts, rule.exts...)
+		}
+		paths, err := common.GetFilesByExt(projDir, common.UniqueStrings(exts))
+		if err != nil {
+			logrus.Errorf("failed to find the source files of the dot net project at path %s . Error: %q", projPath, err)
+			continue
+		}
+		sort.Strings(paths)
+		found := map[string]bool{}
+		for _, path := range paths {
+			for _, finding := range findPortabilityIssues(path) {
+				relPath, err := filepath.Rel(serviceDir, finding.path)
+				if err == nil {
+					finding.path = relPath
+				}
+				project.findings = append(project.findings, finding)
+				if !found[finding.rule.name] {
+					found[finding.rule.name] = true
+					project.score -= finding.rule.penalty
+				}
+			}
+		}
+		if isGACReferenced(projPath) && !found[gacRuleName] {
+			relProjPath, err := filepath.Rel(serviceDir, projPath)
+			if err != nil {
+				relProjPath = projPath
+			}
+			for i, rule := range portabilityRules {
+				if rule.name == gacRuleName {
+					project.findings = append(project.findings, portabilityFinding{rule: &portabilityRules[i], path: relProjPath})
+					project.score -= rule.penalty
+				}
+			}
+		}
+		if project.score < 0 {
+			project.score = 0
+		}
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+// findPortabilityIssues returns the first use of each API in the file
+func findPortabilityIssues(path string) []portabilityFinding {
+	ext := strings.ToLower(filepath.Ext(path))
+	rules := []*portabilityRule{}
+	for i, rule := range portabilityRules {
+		if common.IsStringPresent(rule.exts, ext) {
+			rules = append(rules, &portabilityRules[i])
+		}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		logrus.Debugf("failed to open the file at path %s . Error: %q", path, err)
+		return nil
+	}
+	defer file.Close()
+	findings := []portabilityFinding{}
+	found := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		for _, rule := range rules {
+			if !found[rule.name] && rule.pattern.MatchString(line) {
+				found[rule.name] = true
+				findings = append(findings, portabilityFinding{rule: rule, path: path, line: lineNumber})
+			}
+		}
+	}
+	return findings
+}
+
+// isGACReferenced checks if the project references strong named assemblies that are not from the framework and are not shipped with the project
+func isGACReferenced(projPath string) bool {
+	configuration, err := dotnetutils.ParseCSProj(projPath)
+	if err != nil {
+		logrus.Debugf("failed to parse the dot net project file at path %s . Error: %q", projPath, err)
+		return false
+	}
+	for _, itemGroup := range configuration.ItemGroups {
+		for _, reference := range itemGroup.References {
+			if reference.HintPath != "" || !strings.Contains(reference.Include, gacPublicKeyTokenAttr) {
+				continue
+			}
+			if !strings.HasPrefix(reference.Include, "System") && !strings.HasPrefix(reference.Include, "Microsoft") && !strings.HasPrefix(reference.Include, "mscorlib") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// askDotNetTarget asks whether the service should run in a Windows container or be modernized to .NET 8 on Linux.
+// A report of the portability of the projects is written in either case and its path mapping is returned.
+func askDotNetTarget(env *environment.Environment, appName, serviceDir string) (string, []transformertypes.PathMapping) {
+	projects := assessPortability(serviceDir)
+	if len(projects) == 0 {
+		return windowsContainerTarget, nil
+	}
+	minScore := 100
+	for _, project := range projects {
+		if project.score < minScore {
+			minScore = project.score
+		}
+	}
+	def := windowsContainerTarget
+	if minScore >= portableScore {
+		def = modernizeTarget
+	}
+	target := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, `"`+appName+`"`, common.ConfigDotNetTargetKeySegment),
+		fmt.Sprintf("The dot net framework app %s has a Linux portability score of %d out of 100. Select how it should be containerized:", appName, minScore),
+		[]string{
+			windowsContainerTarget + " : run the app unchanged in a Windows container, which needs Windows nodes in the cluster",
+			modernizeTarget + " : port the app to .NET 8 on Linux. No Dockerfile is generated, the gaps to fix are listed in the report",
+		},
+		def,
+		[]string{windowsContainerTarget, modernizeTarget},
+	)
+	reportPath := filepath.Join(env.TempPath, portabilityReportDir, common.MakeFileNameCompliant(appName)+".md")
+	if err := os.MkdirAll(filepath.Dir(reportPath), common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("failed to create the directory for the portability report at path %s . Error: %q", reportPath, err)
+		return target, nil
+	}
+	if err := os.WriteFile(reportPath, []byte(getPortabilityReport(appName, target, projects)), common.DefaultFilePermission); err != nil {
+		logrus.Errorf("failed to write the portability report to the file at path %s . Error: %q", reportPath, err)
+		return target, nil
+	}
+	return target, []transformertypes.PathMapping{{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  reportPath,
+		DestPath: filepath.Join(portabilityReportDir, filepath.Base(reportPath)),
+	}}
+}
+
+// getPortabilityReport returns the markdown report with the findings of each project
+func getPortabilityReport(appName, target string, projects []projectPortability) string {
+	report := strings.Builder{}
+	report.WriteString(fmt.Sprintf("# Portability of %s to .NET 8 on Linux\n\n", appName))
+	if target == modernizeTarget {
+		report.WriteString("The app is to be modernized. Fix the gaps below, retarget the projects to net8.0 and run Move2Kube again to containerize it.\n")
+	} else {
+		report.WriteString("The app runs in a Windows container, so it needs Windows nodes in the cluster. The gaps below have to be fixed to run it on Linux.\n")
+	}
+	for _, project := range projects {
+		report.WriteString(fmt.Sprintf("\n## %s\n\nScore: %d/100\n\n", project.name, project.score))
+		if len(project.findings) == 0 {
+			report.WriteString("No APIs that are unavailable on Linux were found.\n")
+			continue
+		}
+		report.WriteString("| API | Location | Suggestion |\n| --- | --- | --- |\n")
+		for _, finding := range project.findings {
+			location := finding.path
+			if finding.line > 0 {
+				location = fmt.Sprintf("%s:%d", finding.path, finding.line)
+			}
+			report.WriteString(fmt.Sprintf("| %s | %s | %s |\n", finding.rule.name, location, finding.rule.suggestion))
+		}
+	}
+	return report.String()
+}
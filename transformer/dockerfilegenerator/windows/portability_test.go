@@ -0,0 +1,165 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package windows
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+const (
+	webFormsProj = `<Project ToolsVersion="15.0">
+  <ItemGroup>
+    <Reference Include="System.Web" />
+    <Reference Include="Acme.Licensing, Version=1.0.0.0, Culture=neutral, PublicKeyToken=abcdef0123456789" />
+  </ItemGroup>
+</Project>
+`
+	portableProj = `<Project ToolsVersion="15.0">
+  <ItemGroup>
+    <Reference Include="Newtonsoft.Json, Version=13.0.0.0, Culture=neutral, PublicKeyToken=30ad4fe6b2a6aeed">
+      <HintPath>..\packages\Newtonsoft.Json.13.0.1\lib\net45\Newtonsoft.Json.dll</HintPath>
+    </Reference>
+  </ItemGroup>
+</Project>
+`
+)
+
+func writePortabilityFixture(t *testing.T) string {
+	serviceDir := t.TempDir()
+	files := map[string]string{
+		"Web/Web.csproj":      webFormsProj,
+		"Web/Default.aspx":    "<%@ Page Language=\"C#\" CodeBehind=\"Default.aspx.cs\" %>\n",
+		"Web/Global.asax.cs":  "using System.Web;\n\npublic class Global\n{\n    var context = HttpContext.Current;\n}\n",
+		"Api/Api.csproj":      portableProj,
+		"Api/Controller.cs":   "using Newtonsoft.Json;\n",
+		"Api/DesktopNotes.md": "System.Windows.Forms is not used\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(serviceDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	return serviceDir
+}
+
+func TestAssessPortability(t *testing.T) {
+	type project struct {
+		Name     string
+		Score    int
+		Findings []string
+	}
+	serviceDir := writePortabilityFixture(t)
+	actual := []project{}
+	for _, p := range assessPortability(serviceDir) {
+		actualProject := project{Name: p.name, Score: p.score}
+		for _, finding := range p.findings {
+			actualProject.Findings = append(actualProject.Findings, fmt.Sprintf("%s %s:%d", finding.rule.name, finding.path, finding.line))
+		}
+		actual = append(actual, actualProject)
+	}
+	expected := []project{
+		{Name: "Api", Score: 100},
+		{
+			// each rule reduces the score once, and the GAC reference is found in the project file
+			Name:  "Web",
+			Score: 25,
+			Findings: []string{
+				"ASP.NET Web Forms " + filepath.Join("Web", "Default.aspx") + ":1",
+				"System.Web " + filepath.Join("Web", "Global.asax.cs") + ":1",
+				"System.Web " + filepath.Join("Web", "Web.csproj") + ":3",
+				"Global assembly cache " + filepath.Join("Web", "Web.csproj") + ":0",
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("wrong portability. Difference:\n%s", diff)
+	}
+}
+
+func TestAskDotNetTarget(t *testing.T) {
+	testCases := []struct {
+		name           string
+		projectDir     string
+		answers        []string
+		expectedTarget string
+		expectedReport []string
+	}{
+		{
+			name:           "default to a windows container for a project that is hard to port",
+			projectDir:     "Web",
+			expectedTarget: windowsContainerTarget,
+			expectedReport: []string{"# Portability of web to .NET 8 on Linux", "needs Windows nodes", "Score: 25/100", "| ASP.NET Web Forms | Default.aspx:1 |", "| Global assembly cache | Web.csproj |"},
+		},
+		{
+			name:           "default to modernizing a portable project",
+			projectDir:     "Api",
+			expectedTarget: modernizeTarget,
+			expectedReport: []string{"The app is to be modernized", "Score: 100/100", "No APIs that are unavailable on Linux were found."},
+		},
+		{
+			name:           "modernize the project when the user chooses to",
+			projectDir:     "Web",
+			answers:        []string{`move2kube.services."web".dotnettarget="modernize"`},
+			expectedTarget: modernizeTarget,
+			expectedReport: []string{"The app is to be modernized", "Score: 25/100"},
+		},
+	}
+	serviceDir := writePortabilityFixture(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			common.TempPath = t.TempDir()
+			qaengine.StartEngine(true, 0, true)
+			qaengine.SetupConfigFile("", testCase.answers, nil, nil, false, false)
+			env := &environment.Environment{EnvInfo: environment.EnvInfo{TempPath: t.TempDir()}}
+			target, pathMappings := askDotNetTarget(env, "web", filepath.Join(serviceDir, testCase.projectDir))
+			if target != testCase.expectedTarget {
+				t.Fatalf("wrong target. Expected: %s Actual: %s", testCase.expectedTarget, target)
+			}
+			expectedPathMappings := []transformertypes.PathMapping{{
+				Type:     transformertypes.DefaultPathMappingType,
+				SrcPath:  filepath.Join(env.TempPath, "assessment", "dotnet", "web.md"),
+				DestPath: filepath.Join("assessment", "dotnet", "web.md"),
+			}}
+			if diff := cmp.Diff(expectedPathMappings, pathMappings); diff != "" {
+				t.Fatalf("wrong path mappings. Difference:\n%s", diff)
+			}
+			report, err := os.ReadFile(pathMappings[0].SrcPath)
+			if err != nil {
+				t.Fatalf("failed to read the portability report. Error: %q", err)
+			}
+			for _, expected := range testCase.expectedReport {
+				if !strings.Contains(string(report), expected) {
+					t.Fatalf("expected the report to contain %q . Actual:\n%s", expected, report)
+				}
+			}
+		})
+	}
+}
@@ -241,6 +241,12 @@ func (t *WinWebAppDockerfileGenerator) Transform(newArtifacts []transformertypes
 			logrus.Errorf("the service directory is missing from the dot net artifact %+v", newArtifact)
 			continue
 		}
+		target, reportPathMappings := askDotNetTarget(t.Env, dotNetConfig.DotNetAppName, newArtifact.Paths[artifacts.ServiceDirPathType][0])
+		pathMappings = append(pathMappings, reportPathMappings...)
+		if target == modernizeTarget {
+			logrus.Infof("the dot net framework app %s is to be modernized. Not generating a Windows Dockerfile for it", dotNetConfig.DotNetAppName)
+			continue
+		}
 		selectedBuildOption, err := dotnetutils.AskUserForDockerfileType(dotNetConfig.DotNetAppName)
 		if err != nil {
 			logrus.Errorf("failed to ask the user what type of dockerfile they prefer. Error: %q", err)
@@ -0,0 +1,144 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// dockerfileTemplatesDir is the directory of the customizations with the Dockerfile templates
+	dockerfileTemplatesDir = "dockerfiletemplates"
+	// builtInDockerfileTemplate is the option for using the template of the transformer
+	builtInDockerfileTemplate       = "built-in"
+	dockerfileTransformerNameSuffix = "-dockerfile"
+)
+
+// getDockerfileTemplates returns the paths of the Dockerfile templates in the customizations, keyed by their names
+func getDockerfileTemplates() map[string]string {
+	templates := map[string]string{}
+	templatesDir := filepath.Join(common.AssetsPath, "custom", dockerfileTemplatesDir)
+	entries, err := os.ReadDir(templatesDir)
+	if err != nil {
+		return templates
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		templates[entry.Name()] = filepath.Join(templatesDir, entry.Name())
+	}
+	return templates
+}
+
+// getDockerfileLanguage returns the language of the Dockerfiles generated by the transformer, like golang for the Golang-Dockerfile transformer
+func getDockerfileLanguage(tc transformertypes.Transformer) string {
+	name := strings.ToLower(tc.Name)
+	return strings.TrimSuffix(name, dockerfileTransformerNameSuffix)
+}
+
+// overrideDockerfileTemplates renders the Dockerfiles generated by the transformer using the templates in the customizations.
+// The template of each service is asked for, and defaults to the template named after the language, like golang.Dockerfile.
+// It is filled in with the same config as the template of the transformer, so the templates can use the same values, like the ports.
+func overrideDockerfileTemplates(pathMappings []transformertypes.PathMapping, newArtifacts []transformertypes.Artifact, tc transformertypes.Transformer) []transformertypes.PathMapping {
+	templates := getDockerfileTemplates()
+	if len(templates) == 0 {
+		return pathMappings
+	}
+	templateNames := []string{}
+	for name := range templates {
+		templateNames = append(templateNames, name)
+	}
+	sort.Strings(templateNames)
+	defaultTemplate := builtInDockerfileTemplate
+	language := getDockerfileLanguage(tc)
+	for _, name := range templateNames {
+		if strings.TrimSuffix(name, filepath.Ext(name)) == language {
+			defaultTemplate = name
+			break
+		}
+	}
+	overridden := map[string]bool{}
+	for _, newArtifact := range newArtifacts {
+		if (newArtifact.Type != artifacts.DockerfileArtifactType && newArtifact.Type != artifacts.DockerfileForServiceArtifactType) || len(newArtifact.Paths[artifacts.DockerfilePathType]) == 0 {
+			continue
+		}
+		dockerfilePath := filepath.Clean(newArtifact.Paths[artifacts.DockerfilePathType][0])
+		if overridden[dockerfilePath] {
+			continue
+		}
+		// only the Dockerfiles generated from a template can use a custom template, since it needs the template config
+		generatingPathMapping, ok := getDockerfileTemplatePathMapping(pathMappings, dockerfilePath)
+		if !ok {
+			continue
+		}
+		overridden[dockerfilePath] = true
+		serviceName := newArtifact.Name
+		serviceConfig := artifacts.ServiceConfig{}
+		if err := newArtifact.GetConfig(artifacts.ServiceConfigType, &serviceConfig); err == nil && serviceConfig.ServiceName != "" {
+			serviceName = serviceConfig.ServiceName
+		}
+		selectedTemplate := qaengine.FetchSelectAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigDockerfileTemplateKeySegment),
+			fmt.Sprintf("Select the template for the Dockerfile of the service %s :", serviceName),
+			[]string{fmt.Sprintf("The templates are in the %s directory of the customizations", dockerfileTemplatesDir), fmt.Sprintf("Select %s to use the template of the %s transformer", builtInDockerfileTemplate, tc.Name)},
+			defaultTemplate,
+			append([]string{builtInDockerfileTemplate}, templateNames...),
+		)
+		if selectedTemplate == builtInDockerfileTemplate {
+			continue
+		}
+		templatePath, ok := templates[selectedTemplate]
+		if !ok {
+			logrus.Errorf("There is no Dockerfile template named %s in the customizations. Using the template of the transformer %s for the service %s", selectedTemplate, tc.Name, serviceName)
+			continue
+		}
+		logrus.Debugf("using the Dockerfile template %s for the service %s", selectedTemplate, serviceName)
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           generatingPathMapping.Type,
+			SrcPath:        templatePath,
+			DestPath:       dockerfilePath,
+			TemplateConfig: generatingPathMapping.TemplateConfig,
+		})
+	}
+	return pathMappings
+}
+
+// getDockerfileTemplatePathMapping returns the last template path mapping that writes the Dockerfile or its directory
+func getDockerfileTemplatePathMapping(pathMappings []transformertypes.PathMapping, dockerfilePath string) (transformertypes.PathMapping, bool) {
+	for i := len(pathMappings) - 1; i >= 0; i-- {
+		pathMapping := pathMappings[i]
+		if !strings.EqualFold(string(pathMapping.Type), string(transformertypes.TemplatePathMappingType)) && !strings.EqualFold(string(pathMapping.Type), string(transformertypes.SpecialTemplatePathMappingType)) {
+			continue
+		}
+		destPath := filepath.Clean(pathMapping.DestPath)
+		if destPath == dockerfilePath || destPath == filepath.Dir(dockerfilePath) {
+			return pathMapping, true
+		}
+	}
+	return transformertypes.PathMapping{}, false
+}
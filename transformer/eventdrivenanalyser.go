@@ -0,0 +1,261 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/environment"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// eventSourceDependencies maps the dependency files of each language to the client libraries of each type of event source
+	eventSourceDependencies = map[string]map[string][]string{
+		"go.mod": {
+			irtypes.KafkaEventSourceType:    {"github.com/segmentio/kafka-go", "github.com/Shopify/sarama", "github.com/IBM/sarama", "confluent-kafka-go"},
+			irtypes.RabbitMQEventSourceType: {"github.com/streadway/amqp", "github.com/rabbitmq/amqp091-go"},
+			irtypes.SQSEventSourceType:      {"aws-sdk-go/service/sqs", "aws-sdk-go-v2/service/sqs"},
+		},
+		"package.json": {
+			irtypes.KafkaEventSourceType:    {`"kafkajs"`, `"node-rdkafka"`},
+			irtypes.RabbitMQEventSourceType: {`"amqplib"`, `"amqp-connection-manager"`},
+			irtypes.SQSEventSourceType:      {`"@aws-sdk/client-sqs"`, `"sqs-consumer"`},
+		},
+		"requirements.txt": {
+			irtypes.KafkaEventSourceType:    {"kafka-python", "confluent-kafka", "aiokafka"},
+			irtypes.RabbitMQEventSourceType: {"pika", "aio-pika", "kombu"},
+		},
+		"Pipfile": {
+			irtypes.KafkaEventSourceType:    {"kafka-python", "confluent-kafka", "aiokafka"},
+			irtypes.RabbitMQEventSourceType: {"pika", "aio-pika", "kombu"},
+		},
+		"pyproject.toml": {
+			irtypes.KafkaEventSourceType:    {"kafka-python", "confluent-kafka", "aiokafka"},
+			irtypes.RabbitMQEventSourceType: {"pika", "aio-pika", "kombu"},
+		},
+		"pom.xml": {
+			irtypes.KafkaEventSourceType:    {"kafka-clients", "spring-kafka"},
+			irtypes.RabbitMQEventSourceType: {"amqp-client", "spring-boot-starter-amqp", "spring-rabbit"},
+			irtypes.SQSEventSourceType:      {"aws-java-sdk-sqs", "<artifactId>sqs</artifactId>"},
+		},
+		"build.gradle": {
+			irtypes.KafkaEventSourceType:    {"kafka-clients", "spring-kafka"},
+			irtypes.RabbitMQEventSourceType: {"amqp-client", "spring-boot-starter-amqp", "spring-rabbit"},
+			irtypes.SQSEventSourceType:      {"aws-java-sdk-sqs", "software.amazon.awssdk:sqs"},
+		},
+		"build.gradle.kts": {
+			irtypes.KafkaEventSourceType:    {"kafka-clients", "spring-kafka"},
+			irtypes.RabbitMQEventSourceType: {"amqp-client", "spring-boot-starter-amqp", "spring-rabbit"},
+			irtypes.SQSEventSourceType:      {"aws-java-sdk-sqs", "software.amazon.awssdk:sqs"},
+		},
+		"Gemfile": {
+			irtypes.KafkaEventSourceType:    {"ruby-kafka", "rdkafka"},
+			irtypes.RabbitMQEventSourceType: {"bunny", "sneakers"},
+			irtypes.SQSEventSourceType:      {"aws-sdk-sqs", "shoryuken"},
+		},
+		"Cargo.toml": {
+			irtypes.KafkaEventSourceType:    {"rdkafka"},
+			irtypes.RabbitMQEventSourceType: {"lapin"},
+			irtypes.SQSEventSourceType:      {"aws-sdk-sqs"},
+		},
+	}
+	// eventSourceImages are the images of the brokers of each type of event source, used to find the brokers in compose files
+	eventSourceImages = map[string][]string{
+		irtypes.KafkaEventSourceType:    {"kafka", "redpanda"},
+		irtypes.RabbitMQEventSourceType: {"rabbitmq"},
+		irtypes.SQSEventSourceType:      {"localstack", "elasticmq"},
+	}
+	// eventSourceEndpointKinds maps the kinds of the external endpoints found in the sources to the type of event source
+	eventSourceEndpointKinds = map[string]string{
+		amqpEndpointKind: irtypes.RabbitMQEventSourceType,
+	}
+	eventSourceBrokerPorts = map[string]string{
+		irtypes.KafkaEventSourceType:    "9092",
+		irtypes.RabbitMQEventSourceType: "5672",
+	}
+)
+
+// EventDrivenAnalyser implements Transformer interface
+type EventDrivenAnalyser struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// Init Initializes the transformer
+func (t *EventDrivenAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *EventDrivenAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *EventDrivenAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform finds the Kafka topics, RabbitMQ queues and SQS queues that the services consume,
+// using the client libraries in their dependencies, the AMQP endpoints in their sources and the brokers they refer to in compose files.
+// The Kubernetes transformer uses them to scale the services with KEDA.
+func (t *EventDrivenAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		serviceNames := []string{}
+		for sn := range ir.Services {
+			serviceNames = append(serviceNames, sn)
+		}
+		sort.Strings(serviceNames)
+		brokers := getEventSourceBrokers(ir)
+		for _, sn := range serviceNames {
+			if _, ok := brokers[sn]; ok {
+				continue
+			}
+			s := ir.Services[sn]
+			for _, eventSource := range findEventSources(ir, sn, serviceNames, brokers) {
+				if !hasEventSource(s.EventSources, eventSource.Type) {
+					logrus.Debugf("the service %s consumes events from %s (%s)", sn, eventSource.Type, eventSource.Source)
+					s.EventSources = append(s.EventSources, eventSource)
+				}
+			}
+			ir.Services[sn] = s
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return nil, artifactsCreated, nil
+}
+
+// getEventSourceBrokers returns the types of event sources of the services that run a broker, like the kafka and rabbitmq services of a compose file
+func getEventSourceBrokers(ir irtypes.IR) map[string]string {
+	brokers := map[string]string{}
+	for sn, s := range ir.Services {
+		for _, container := range s.Containers {
+			image := strings.ToLower(container.Image)
+			if idx := strings.LastIndex(image, "/"); idx >= 0 {
+				image = image[idx+1:]
+			}
+			for eventSourceType, brokerImages := range eventSourceImages {
+				for _, brokerImage := range brokerImages {
+					if strings.HasPrefix(image, brokerImage) {
+						brokers[sn] = eventSourceType
+					}
+				}
+			}
+		}
+	}
+	return brokers
+}
+
+// findEventSources finds the event sources of a service
+func findEventSources(ir irtypes.IR, serviceName string, serviceNames []string, brokers map[string]string) []irtypes.EventSource {
+	service := ir.Services[serviceName]
+	eventSources := []irtypes.EventSource{}
+	for _, referenced := range getReferencedServices(ir, serviceName, serviceNames) {
+		if eventSourceType, ok := brokers[referenced]; ok {
+			host := referenced
+			if port, ok := eventSourceBrokerPorts[eventSourceType]; ok {
+				host += ":" + port
+			}
+			eventSources = append(eventSources, irtypes.EventSource{Type: eventSourceType, Host: host, Source: "service " + referenced})
+		}
+	}
+	for _, endpoint := range service.ExternalEndpoints {
+		if eventSourceType, ok := eventSourceEndpointKinds[endpoint.Kind]; ok {
+			host := endpoint.Host
+			if endpoint.Port != 0 {
+				host = fmt.Sprintf("%s:%d", endpoint.Host, endpoint.Port)
+			}
+			eventSources = append(eventSources, irtypes.EventSource{Type: eventSourceType, Host: host, Source: endpoint.Source})
+		}
+	}
+	for _, container := range service.Containers {
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok || image.Build.ContextPath == "" {
+			continue
+		}
+		fileNames := []string{}
+		for fileName := range eventSourceDependencies {
+			fileNames = append(fileNames, fileName)
+		}
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			contents, err := os.ReadFile(filepath.Join(image.Build.ContextPath, fileName))
+			if err != nil {
+				continue
+			}
+			for _, eventSourceType := range []string{irtypes.KafkaEventSourceType, irtypes.RabbitMQEventSourceType, irtypes.SQSEventSourceType} {
+				for _, dependency := range eventSourceDependencies[fileName][eventSourceType] {
+					if strings.Contains(string(contents), dependency) {
+						eventSources = append(eventSources, irtypes.EventSource{Type: eventSourceType, Source: fileName + " " + strings.Trim(dependency, `"`)})
+						break
+					}
+				}
+			}
+		}
+	}
+	return eventSources
+}
+
+func hasEventSource(eventSources []irtypes.EventSource, eventSourceType string) bool {
+	for _, eventSource := range eventSources {
+		if eventSource.Type == eventSourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// getReferencedServices returns the other services whose names are used as host names in the environment variables of the service
+func getReferencedServices(ir irtypes.IR, serviceName string, serviceNames []string) []string {
+	envValues := []string{}
+	for _, container := range ir.Services[serviceName].Containers {
+		for _, env := range container.Env {
+			envValues = append(envValues, env.Value)
+		}
+	}
+	referenced := []string{}
+	for _, sn := range serviceNames {
+		if sn == serviceName {
+			continue
+		}
+		hostRegex := regexp.MustCompile(`(^|[^A-Za-z0-9-])` + regexp.QuoteMeta(sn) + `([^A-Za-z0-9-]|$)`)
+		for _, value := range envValues {
+			if hostRegex.MatchString(value) {
+				referenced = append(referenced, sn)
+				break
+			}
+		}
+	}
+	return referenced
+}
@@ -0,0 +1,99 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestEventDrivenAnalyserTransform(t *testing.T) {
+	sourceDir := t.TempDir()
+	files := map[string]string{
+		"orders/package.json":      `{"dependencies": {"kafkajs": "^2.0.0"}}`,
+		"billing/requirements.txt": "flask\npika==1.3.0\n",
+		"worker/go.mod":            "module worker\n\nrequire github.com/aws/aws-sdk-go-v2/service/sqs v1.18.0\n",
+		"reports/pom.xml":          "<dependency><artifactId>spring-kafka</artifactId></dependency>\n",
+		"web/package.json":         `{"dependencies": {"express": "^4.0.0"}}`,
+	}
+	for path, contents := range files {
+		path = filepath.Join(sourceDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	ir := irtypes.NewIR()
+	for _, sn := range []string{"orders", "billing", "worker", "reports", "web"} {
+		ir.ContainerImages[sn+":latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{ContextPath: filepath.Join(sourceDir, sn)}}
+		s := irtypes.NewServiceWithName(sn)
+		s.Containers = []core.Container{{Name: sn, Image: sn + ":latest"}}
+		ir.Services[sn] = s
+	}
+	broker := irtypes.NewServiceWithName("kafka")
+	broker.Containers = []core.Container{{Name: "kafka", Image: "docker.io/bitnami/kafka:3.2"}}
+	ir.Services["kafka"] = broker
+	orders := ir.Services["orders"]
+	orders.Containers[0].Env = []core.EnvVar{{Name: "KAFKA_BROKERS", Value: "kafka:9092"}}
+	ir.Services["orders"] = orders
+	billing := ir.Services["billing"]
+	billing.ExternalEndpoints = []irtypes.ExternalEndpoint{{Kind: amqpEndpointKind, Host: "mq.example.com", Port: 5672, Source: "app.py:12"}}
+	ir.Services["billing"] = billing
+	reports := ir.Services["reports"]
+	reports.EventSources = []irtypes.EventSource{{Type: irtypes.KafkaEventSourceType, Host: "events:9092", Source: "user"}}
+	ir.Services["reports"] = reports
+
+	eventDrivenAnalyser := &EventDrivenAnalyser{}
+	if err := eventDrivenAnalyser.Init(transformertypes.Transformer{}, nil); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	artifact := transformertypes.Artifact{Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}}
+	_, createdArtifacts, err := eventDrivenAnalyser.Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the IR. Error: %q", err)
+	}
+	if len(createdArtifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", createdArtifacts)
+	}
+	ir = createdArtifacts[0].Configs[irtypes.IRConfigType].(irtypes.IR)
+	expected := map[string][]irtypes.EventSource{
+		// the broker found through the environment takes precedence over the client library
+		"orders":  {{Type: irtypes.KafkaEventSourceType, Host: "kafka:9092", Source: "service kafka"}},
+		"billing": {{Type: irtypes.RabbitMQEventSourceType, Host: "mq.example.com:5672", Source: "app.py:12"}},
+		"worker":  {{Type: irtypes.SQSEventSourceType, Source: "go.mod aws-sdk-go-v2/service/sqs"}},
+		// the event sources that are already known are kept
+		"reports": {{Type: irtypes.KafkaEventSourceType, Host: "events:9092", Source: "user"}},
+	}
+	actual := map[string][]irtypes.EventSource{}
+	for sn, s := range ir.Services {
+		if len(s.EventSources) > 0 {
+			actual[sn] = s.EventSources
+		}
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("wrong event sources. Difference:\n%s", diff)
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
@@ -39,6 +41,16 @@ type Executable struct {
 	Config     transformertypes.Transformer
 	Env        *environment.Environment
 	ExecConfig *ExecutableYamlConfig
+
+	directoryDetectTimeout time.Duration
+	transformTimeout       time.Duration
+
+	envInfo           environment.EnvInfo
+	qaRPCReceiverAddr net.Addr
+	// workerEnvs are the environments of the concurrent transforms other than the first one, which uses Env
+	workerEnvs []*environment.Environment
+	// newWorkerEnv creates the environment of a concurrent transform
+	newWorkerEnv func(name string) (*environment.Environment, error)
 }
 
 // ExecutableYamlConfig is the format of executable yaml config
@@ -48,6 +60,12 @@ type ExecutableYamlConfig struct {
 	DirectoryDetectCMD environmenttypes.Command   `yaml:"directoryDetectCMD"`
 	TransformCMD       environmenttypes.Command   `yaml:"transformCMD"`
 	Container          environmenttypes.Container `yaml:"container,omitempty"`
+	// DirectoryDetectTimeout is how long the detect command can run for each directory, like 30s. It is killed if it takes longer.
+	DirectoryDetectTimeout string `yaml:"directoryDetectTimeout,omitempty"`
+	// TransformTimeout is how long the transform command can run for each artifact, like 10m. It is killed if it takes longer.
+	TransformTimeout string `yaml:"transformTimeout,omitempty"`
+	// TransformConcurrency is the number of artifacts transformed at the same time. By default they are transformed one after the other.
+	TransformConcurrency int `yaml:"transformConcurrency,omitempty"`
 }
 
 // Init Initializes the transformer
@@ -59,6 +77,12 @@ func (t *Executable) Init(tc transformertypes.Transformer, env *environment.Envi
 		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.ExecConfig, err)
 		return err
 	}
+	if t.directoryDetectTimeout, err = parseTimeout(t.ExecConfig.DirectoryDetectTimeout); err != nil {
+		return fmt.Errorf("invalid directoryDetectTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
+	if t.transformTimeout, err = parseTimeout(t.ExecConfig.TransformTimeout); err != nil {
+		return fmt.Errorf("invalid transformTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
 	var qaRPCReceiverAddr net.Addr = nil
 	if t.ExecConfig.EnableQA {
 		qaRPCReceiverAddr, err = questionreceivers.StartGRPCReceiver()
@@ -67,7 +91,7 @@ func (t *Executable) Init(tc transformertypes.Transformer, env *environment.Envi
 			logrus.Infof("Starting transformer that requires QA without QA.")
 		}
 	}
-	if !common.IsPresent(t.ExecConfig.Platforms, runtime.GOOS) && t.ExecConfig.Container.Image == "" {
+	if !common.IsPresent(t.ExecConfig.Platforms, runtime.GOOS) && t.ExecConfig.Container.Image == "" && t.ExecConfig.Container.ContainerBuild.Dockerfile == "" && t.ExecConfig.Container.ContainerBuild.Context == "" {
 		return fmt.Errorf("platform %s not supported by transformer %s", runtime.GOOS, tc.Name)
 	}
 	t.Env, err = environment.NewEnvironment(env.EnvInfo, qaRPCReceiverAddr, t.ExecConfig.Container)
@@ -75,9 +99,47 @@ func (t *Executable) Init(tc transformertypes.Transformer, env *environment.Envi
 		logrus.Errorf("Unable to create Exec environment : %s", err)
 		return err
 	}
+	t.envInfo = env.EnvInfo
+	t.qaRPCReceiverAddr = qaRPCReceiverAddr
+	if t.newWorkerEnv == nil {
+		t.newWorkerEnv = t.createWorkerEnv
+	}
 	return nil
 }
 
+// createWorkerEnv creates an isolated environment like Env, so that the concurrent transforms do not share the workspace
+func (t *Executable) createWorkerEnv(name string) (*environment.Environment, error) {
+	envInfo := t.envInfo
+	envInfo.Name = name
+	envInfo.Isolated = true
+	envInfo.TempPath = ""
+	envInfo.CurrEnvOutputBasePath = ""
+	return environment.NewEnvironment(envInfo, t.qaRPCReceiverAddr, t.ExecConfig.Container)
+}
+
+// getTransformEnvs returns the environments of the concurrent transforms, creating the missing ones.
+// The first one is Env. The others are children of Env, so they are destroyed with it.
+func (t *Executable) getTransformEnvs(concurrency int) []*environment.Environment {
+	for len(t.workerEnvs) < concurrency-1 {
+		name := fmt.Sprintf("%s-%d", t.envInfo.Name, len(t.workerEnvs)+1)
+		workerEnv, err := t.newWorkerEnv(name)
+		if err != nil {
+			logrus.Errorf("failed to create the environment %s for the concurrent transforms of the transformer %s . Transforming %d artifacts at the same time. Error: %q", name, t.Config.Name, len(t.workerEnvs)+1, err)
+			break
+		}
+		t.Env.AddChild(workerEnv)
+		t.workerEnvs = append(t.workerEnvs, workerEnv)
+	}
+	envs := []*environment.Environment{t.Env}
+	for _, workerEnv := range t.workerEnvs {
+		if len(envs) == concurrency {
+			break
+		}
+		envs = append(envs, workerEnv)
+	}
+	return envs
+}
+
 // GetConfig returns the transformer config
 func (t *Executable) GetConfig() (transformertypes.Transformer, *environment.Environment) {
 	return t.Config, t.Env
@@ -108,67 +170,130 @@ func (t *Executable) DirectoryDetect(dir string) (services map[string][]transfor
 
 const (
 	// TemplateConfigType represents the template config type
-	TemplateConfigType transformertypes.ConfigType = "TemplateConfig"
+	TemplateConfigType = artifacts.TemplateConfigType
 )
 
-// Transform transforms the artifacts
+// Transform transforms the artifacts. Up to TransformConcurrency artifacts are transformed at the same time.
 func (t *Executable) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) (pathMappings []transformertypes.PathMapping, createdArtifacts []transformertypes.Artifact, err error) {
-	pathMappings = []transformertypes.PathMapping{}
-	createdArtifacts = []transformertypes.Artifact{}
-	for _, a := range newArtifacts {
-		if t.ExecConfig.TransformCMD == nil {
-			relSrcPath, err := filepath.Rel(t.Env.GetEnvironmentSource(), a.Paths[artifacts.ServiceDirPathType][0])
-			if err != nil {
-				logrus.Errorf("Unable to convert source path %s to be relative : %s", a.Paths[artifacts.ServiceDirPathType][0], err)
-				continue
-			}
-			var config interface{}
-			if a.Configs != nil {
-				config = a.Configs[TemplateConfigType]
-			}
-			pathMappings = append(pathMappings, transformertypes.PathMapping{
-				Type:           transformertypes.TemplatePathMappingType,
-				SrcPath:        filepath.Join(t.Env.Context, t.Env.RelTemplatesDir),
-				DestPath:       filepath.Join(common.DefaultSourceDir, relSrcPath),
-				TemplateConfig: config,
-			}, transformertypes.PathMapping{
-				Type:     transformertypes.SourcePathMappingType,
-				SrcPath:  "",
-				DestPath: common.DefaultSourceDir,
-			})
-		} else {
-			path := ""
-			if a.Paths != nil && a.Paths[artifacts.ServiceDirPathType] != nil {
-				path = a.Paths[artifacts.ServiceDirPathType][0]
-			}
-			stdout, stderr, exitcode, err := t.Env.Exec(append(t.ExecConfig.TransformCMD, path))
-			if err != nil {
-				if errors.Is(err, &environment.EnvironmentNotActiveError{}) {
-					logrus.Debugf("%s", err)
-					continue
-				}
-				logrus.Errorf("Transform failed %s : %s : %d : %s", stdout, stderr, exitcode, err)
-				continue
-			} else if exitcode != 0 {
-				logrus.Debugf("Transform did not succeed %s : %s : %d : %s", stdout, stderr, exitcode, err)
+	concurrency := t.ExecConfig.TransformConcurrency
+	if concurrency > len(newArtifacts) {
+		concurrency = len(newArtifacts)
+	}
+	if concurrency < 1 || t.ExecConfig.TransformCMD == nil {
+		concurrency = 1
+	}
+	// each concurrent transform runs in its own environment, which is taken from the pool and given back when it is done
+	envs := t.getTransformEnvs(concurrency)
+	pool := make(chan *environment.Environment, len(envs))
+	for _, env := range envs {
+		if env != t.Env {
+			// Env is reset before each transform, the other environments have to be reset here
+			if err := env.Reset(); err != nil {
+				logrus.Errorf("failed to reset the environment %s of the transformer %s . Error: %q", env.Name, t.Config.Name, err)
 				continue
 			}
-			logrus.Debugf("%s Transform succeeded in %s : %s, %s, %d", t.Config.Name, t.Env.Decode(path), stdout, stderr, exitcode)
-			stdout = strings.TrimSpace(stdout)
-			var output transformertypes.TransformOutput
-			err = json.Unmarshal([]byte(stdout), &output)
-			if err != nil {
-				logrus.Errorf("Error in unmarshalling json %s: %s.", stdout, err)
-			}
-			pathMappings = append(pathMappings, output.PathMappings...)
-			createdArtifacts = append(createdArtifacts, output.CreatedArtifacts...)
 		}
+		pool <- env
+	}
+	results := make([]executableTransformResultT, len(newArtifacts))
+	wg := sync.WaitGroup{}
+	for i, a := range newArtifacts {
+		wg.Add(1)
+		env := <-pool
+		go func(i int, a transformertypes.Artifact, env *environment.Environment) {
+			defer wg.Done()
+			defer func() { pool <- env }()
+			results[i] = t.transformArtifactInEnv(a, env)
+		}(i, a, env)
+	}
+	wg.Wait()
+	// the results are merged in the order of the artifacts, so that the output does not depend on which artifact finished first
+	pathMappings = []transformertypes.PathMapping{}
+	createdArtifacts = []transformertypes.Artifact{}
+	for _, result := range results {
+		pathMappings = append(pathMappings, result.pathMappings...)
+		createdArtifacts = append(createdArtifacts, result.createdArtifacts...)
 	}
 	return pathMappings, createdArtifacts, nil
 }
 
+type executableTransformResultT struct {
+	pathMappings     []transformertypes.PathMapping
+	createdArtifacts []transformertypes.Artifact
+}
+
+// transformArtifactInEnv transforms the artifact in the given environment.
+// The artifact is encoded for Env, so it is re-encoded for the other environments and their outputs are downloaded.
+func (t *Executable) transformArtifactInEnv(a transformertypes.Artifact, env *environment.Environment) (result executableTransformResultT) {
+	if env == t.Env {
+		return t.transformArtifact(a, env)
+	}
+	decoded := t.Env.Decode(&a).(*transformertypes.Artifact)
+	result = t.transformArtifact(*env.Encode(decoded).(*transformertypes.Artifact), env)
+	if result.pathMappings != nil {
+		result.pathMappings = *env.DownloadAndDecode(&result.pathMappings, true).(*[]transformertypes.PathMapping)
+	}
+	if result.createdArtifacts != nil {
+		result.createdArtifacts = *env.DownloadAndDecode(&result.createdArtifacts, false).(*[]transformertypes.Artifact)
+	}
+	return result
+}
+
+func (t *Executable) transformArtifact(a transformertypes.Artifact, env *environment.Environment) (result executableTransformResultT) {
+	if t.ExecConfig.TransformCMD == nil {
+		relSrcPath, err := filepath.Rel(t.Env.GetEnvironmentSource(), a.Paths[artifacts.ServiceDirPathType][0])
+		if err != nil {
+			logrus.Errorf("Unable to convert source path %s to be relative : %s", a.Paths[artifacts.ServiceDirPathType][0], err)
+			return result
+		}
+		var config interface{}
+		if a.Configs != nil {
+			config = a.Configs[TemplateConfigType]
+		}
+		result.pathMappings = []transformertypes.PathMapping{{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        filepath.Join(t.Env.Context, t.Env.RelTemplatesDir),
+			DestPath:       filepath.Join(common.DefaultSourceDir, relSrcPath),
+			TemplateConfig: config,
+		}, {
+			Type:     transformertypes.SourcePathMappingType,
+			SrcPath:  "",
+			DestPath: common.DefaultSourceDir,
+		}}
+		return result
+	}
+	path := ""
+	if a.Paths != nil && a.Paths[artifacts.ServiceDirPathType] != nil {
+		path = a.Paths[artifacts.ServiceDirPathType][0]
+	}
+	// the command is copied, since the artifacts may be transformed concurrently
+	cmd := append(append(environmenttypes.Command{}, t.ExecConfig.TransformCMD...), path)
+	stdout, stderr, exitcode, err := env.ExecWithTimeout(cmd, t.transformTimeout)
+	if err != nil {
+		if errors.Is(err, &environment.EnvironmentNotActiveError{}) {
+			logrus.Debugf("%s", err)
+			return result
+		}
+		logrus.Errorf("Transform failed %s : %s : %d : %s", stdout, stderr, exitcode, err)
+		return result
+	} else if exitcode != 0 {
+		logrus.Debugf("Transform did not succeed %s : %s : %d : %s", stdout, stderr, exitcode, err)
+		return result
+	}
+	logrus.Debugf("%s Transform succeeded in %s : %s, %s, %d", t.Config.Name, env.Decode(path), stdout, stderr, exitcode)
+	stdout = strings.TrimSpace(stdout)
+	var output transformertypes.TransformOutput
+	err = json.Unmarshal([]byte(stdout), &output)
+	if err != nil {
+		logrus.Errorf("Error in unmarshalling json %s: %s.", stdout, err)
+	}
+	result.pathMappings = output.PathMappings
+	result.createdArtifacts = output.CreatedArtifacts
+	return result
+}
+
 func (t *Executable) executeDetect(cmd environmenttypes.Command, dir string) (services map[string][]transformertypes.Artifact, err error) {
-	stdout, stderr, exitcode, err := t.Env.Exec(append(cmd, dir))
+	stdout, stderr, exitcode, err := t.Env.ExecWithTimeout(append(cmd, dir), t.directoryDetectTimeout)
 	if err != nil {
 		if errors.Is(err, &environment.EnvironmentNotActiveError{}) {
 			logrus.Debugf("%s", err)
@@ -205,3 +330,18 @@ func (t *Executable) executeDetect(cmd environmenttypes.Command, dir string) (se
 	}
 	return map[string][]transformertypes.Artifact{"": {trans}}, nil
 }
+
+// parseTimeout parses a timeout of the config, like 5m. An empty timeout is 0, which means no timeout.
+func parseTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("the timeout %s is negative", timeout)
+	}
+	return duration, nil
+}
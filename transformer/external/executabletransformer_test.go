@@ -0,0 +1,103 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/external"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+// testTransformScript finishes the transforms of the first artifacts last.
+// It reports in the type of the created artifact whether another transform was running in the same directory.
+const testTransformScript = `name=$(basename "$1")
+case "$name" in
+  a) sleep 0.4 ;;
+  b) sleep 0.2 ;;
+esac
+if [ -e marker ]; then shared=shared; else shared=isolated; fi
+touch marker
+sleep 0.6
+rm -f marker
+echo "{\"artifacts\": [{\"name\": \"$name\", \"type\": \"$shared\", \"paths\": {\"ServiceDirectories\": [\"$1\"]}}]}"
+`
+
+func TestExecutableTransformConcurrency(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the transform command is a shell script")
+	}
+	names := []string{"a", "b", "c"}
+	for _, concurrency := range []int{1, 2, 3} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			common.TempPath = t.TempDir()
+			source, context, output := t.TempDir(), t.TempDir(), t.TempDir()
+			if err := os.WriteFile(filepath.Join(context, "transform.sh"), []byte(testTransformScript), 0755); err != nil {
+				t.Fatalf("failed to write the transform script. Error: %q", err)
+			}
+			newArtifacts := []transformertypes.Artifact{}
+			for _, name := range names {
+				if err := os.MkdirAll(filepath.Join(source, name), 0755); err != nil {
+					t.Fatalf("failed to create the service directory %s . Error: %q", name, err)
+				}
+				newArtifacts = append(newArtifacts, transformertypes.Artifact{
+					Name:  name,
+					Paths: map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {filepath.Join(source, name)}},
+				})
+			}
+			tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Config: map[string]interface{}{
+				"platforms":            []interface{}{runtime.GOOS},
+				"transformCMD":         []interface{}{"sh", "transform.sh"},
+				"transformConcurrency": concurrency,
+			}}}
+			tc.Name = "test"
+			env := &environment.Environment{EnvInfo: environment.EnvInfo{Name: "test", Source: source, Output: output, Context: context}}
+			transformer := &external.Executable{}
+			if err := transformer.Init(tc, env); err != nil {
+				t.Fatalf("failed to initialize the transformer. Error: %q", err)
+			}
+			defer transformer.Env.Destroy()
+			encoded := *transformer.Env.Encode(&newArtifacts).(*[]transformertypes.Artifact)
+			_, createdArtifacts, err := transformer.Transform(encoded, encoded)
+			if err != nil {
+				t.Fatalf("failed to transform the artifacts. Error: %q", err)
+			}
+			if len(createdArtifacts) != len(names) {
+				t.Fatalf("expected %d artifacts. Actual: %+v", len(names), createdArtifacts)
+			}
+			for i, name := range names {
+				createdArtifact := createdArtifacts[i]
+				if createdArtifact.Name != name {
+					t.Errorf("expected the artifact %s at the index %d. Actual: %s", name, i, createdArtifact.Name)
+				}
+				if createdArtifact.Type != "isolated" {
+					t.Errorf("expected the artifact %s to be transformed in its own environment", name)
+				}
+				if paths := createdArtifact.Paths[artifacts.ServiceDirPathType]; len(paths) != 1 || paths[0] != filepath.Join(source, name) {
+					t.Errorf("expected the path of the artifact %s to be %s . Actual: %+v", name, filepath.Join(source, name), paths)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine/questionreceivers"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/konveyor/move2kube/types/transformer/transformergrpc"
+	"github.com/phayes/freeport"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultGRPCStartupTimeout = 30 * time.Second
+)
+
+// GRPCTransformer implements transformer interface and is used to write external transformers that serve the Transformer gRPC service.
+// Unlike the Executable transformers, the server is started once and serves all the calls, so it can keep state between them.
+type GRPCTransformer struct {
+	Config     transformertypes.Transformer
+	Env        *environment.Environment
+	GRPCConfig *GRPCYamlConfig
+
+	directoryDetectTimeout time.Duration
+	transformTimeout       time.Duration
+	startupTimeout         time.Duration
+
+	qaRPCReceiverAddr net.Addr
+	startOnce         sync.Once
+	startErr          error
+	conn              *grpc.ClientConn
+	client            transformergrpc.TransformerClient
+	server            *exec.Cmd
+	serverDone        chan struct{}
+}
+
+// GRPCYamlConfig is the format of the config of the gRPC transformers
+type GRPCYamlConfig struct {
+	EnableQA  bool     `yaml:"enableQA"`
+	Platforms []string `yaml:"platforms"`
+	// ServerCMD starts the gRPC server. The address the server should listen on, like localhost:50051, is appended to it.
+	// It is started when the transformer is first used and stopped at the end of the run.
+	ServerCMD environmenttypes.Command `yaml:"serverCMD,omitempty"`
+	// Address is the address of the server. By default a free port on localhost is used.
+	// If ServerCMD is not set, the server is expected to be already running at this address.
+	Address string `yaml:"address,omitempty"`
+	// StartupTimeout is how long to wait for the server to start accepting connections, like 1m. The default is 30s.
+	StartupTimeout string `yaml:"startupTimeout,omitempty"`
+	// DirectoryDetectTimeout is how long the detection of each directory can take, like 30s.
+	DirectoryDetectTimeout string `yaml:"directoryDetectTimeout,omitempty"`
+	// TransformTimeout is how long the transformation of the artifacts can take, like 10m.
+	TransformTimeout string `yaml:"transformTimeout,omitempty"`
+}
+
+// Init Initializes the transformer
+func (t *GRPCTransformer) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.GRPCConfig = &GRPCYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.GRPCConfig); err != nil {
+		return fmt.Errorf("unable to load config for Transformer %+v into %T . Error: %q", t.Config.Spec.Config, t.GRPCConfig, err)
+	}
+	if t.directoryDetectTimeout, err = parseTimeout(t.GRPCConfig.DirectoryDetectTimeout); err != nil {
+		return fmt.Errorf("invalid directoryDetectTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
+	if t.transformTimeout, err = parseTimeout(t.GRPCConfig.TransformTimeout); err != nil {
+		return fmt.Errorf("invalid transformTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
+	if t.startupTimeout, err = parseTimeout(t.GRPCConfig.StartupTimeout); err != nil {
+		return fmt.Errorf("invalid startupTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
+	if t.startupTimeout == 0 {
+		t.startupTimeout = defaultGRPCStartupTimeout
+	}
+	if len(t.GRPCConfig.ServerCMD) == 0 && t.GRPCConfig.Address == "" {
+		return fmt.Errorf("the transformer %s has neither a serverCMD nor the address of a running server", tc.Name)
+	}
+	if len(t.GRPCConfig.ServerCMD) != 0 && !common.IsPresent(t.GRPCConfig.Platforms, runtime.GOOS) {
+		return fmt.Errorf("platform %s not supported by transformer %s", runtime.GOOS, tc.Name)
+	}
+	if t.GRPCConfig.EnableQA {
+		t.qaRPCReceiverAddr, err = questionreceivers.StartGRPCReceiver()
+		if err != nil {
+			logrus.Errorf("Unable to start QA RPC Receiver engine : %s", err)
+			logrus.Infof("Starting transformer that requires QA without QA.")
+		}
+	}
+	t.Env, err = environment.NewEnvironment(env.EnvInfo, t.qaRPCReceiverAddr, environmenttypes.Container{})
+	if err != nil {
+		return fmt.Errorf("unable to create the environment of the transformer %s . Error: %q", tc.Name, err)
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *GRPCTransformer) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect asks the server to detect the services in the directory
+func (t *GRPCTransformer) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	client, err := t.getClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := getRPCContext(t.Env.Ctx, t.directoryDetectTimeout)
+	defer cancel()
+	resp, err := client.DirectoryDetect(ctx, &transformergrpc.DirectoryDetectRequest{Dir: dir})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("the transformer %s failed to detect the directory %s . Error: %q", t.Config.Name, dir, err)
+	}
+	if resp.Services == "" {
+		return nil, nil
+	}
+	if err := json.Unmarshal([]byte(resp.Services), &services); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the services detected by the transformer %s in the directory %s . Error: %q", t.Config.Name, dir, err)
+	}
+	for _, ns := range services {
+		for nsi, nst := range ns {
+			if len(nst.Paths) == 0 {
+				nst.Paths = map[transformertypes.PathType][]string{
+					artifacts.ServiceDirPathType: {dir},
+				}
+				ns[nsi] = nst
+			}
+		}
+	}
+	return services, nil
+}
+
+// Transform asks the server to transform the artifacts and collects the path mappings and artifacts it streams back
+func (t *GRPCTransformer) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) (pathMappings []transformertypes.PathMapping, createdArtifacts []transformertypes.Artifact, err error) {
+	client, err := t.getClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	newArtifactsJSON, err := json.Marshal(newArtifacts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal the artifacts to json. Error: %q", err)
+	}
+	alreadySeenArtifactsJSON, err := json.Marshal(alreadySeenArtifacts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal the already seen artifacts to json. Error: %q", err)
+	}
+	ctx, cancel := getRPCContext(t.Env.Ctx, t.transformTimeout)
+	defer cancel()
+	stream, err := client.Transform(ctx, &transformergrpc.TransformRequest{NewArtifacts: string(newArtifactsJSON), AlreadySeenArtifacts: string(alreadySeenArtifactsJSON)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("the transformer %s failed to transform the artifacts. Error: %q", t.Config.Name, err)
+	}
+	pathMappings = []transformertypes.PathMapping{}
+	createdArtifacts = []transformertypes.Artifact{}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return pathMappings, createdArtifacts, nil
+			}
+			if status.Code(err) == codes.Unimplemented {
+				return nil, nil, nil
+			}
+			return pathMappings, createdArtifacts, fmt.Errorf("the transformer %s failed while transforming the artifacts. Error: %q", t.Config.Name, err)
+		}
+		if resp.PathMappings != "" {
+			currPathMappings := []transformertypes.PathMapping{}
+			if err := json.Unmarshal([]byte(resp.PathMappings), &currPathMappings); err != nil {
+				logrus.Errorf("failed to unmarshal the path mappings from the transformer %s . Error: %q", t.Config.Name, err)
+			}
+			pathMappings = append(pathMappings, currPathMappings...)
+		}
+		if resp.CreatedArtifacts != "" {
+			currCreatedArtifacts := []transformertypes.Artifact{}
+			if err := json.Unmarshal([]byte(resp.CreatedArtifacts), &currCreatedArtifacts); err != nil {
+				logrus.Errorf("failed to unmarshal the created artifacts from the transformer %s . Error: %q", t.Config.Name, err)
+			}
+			createdArtifacts = append(createdArtifacts, currCreatedArtifacts...)
+		}
+	}
+}
+
+// Stop stops the server and closes the connection to it
+func (t *GRPCTransformer) Stop() {
+	if t.conn != nil {
+		if err := t.conn.Close(); err != nil {
+			logrus.Debugf("failed to close the connection to the server of the transformer %s . Error: %q", t.Config.Name, err)
+		}
+	}
+	if t.server != nil {
+		if err := t.server.Process.Kill(); err != nil {
+			logrus.Debugf("failed to kill the server of the transformer %s . Error: %q", t.Config.Name, err)
+		}
+		<-t.serverDone
+	}
+}
+
+// getClient starts the server on the first call and connects to it
+func (t *GRPCTransformer) getClient() (transformergrpc.TransformerClient, error) {
+	t.startOnce.Do(func() {
+		t.startErr = t.start()
+		if t.startErr != nil {
+			logrus.Errorf("failed to start the transformer %s . Error: %q", t.Config.Name, t.startErr)
+		}
+	})
+	return t.client, t.startErr
+}
+
+func (t *GRPCTransformer) start() error {
+	address := t.GRPCConfig.Address
+	if address == "" {
+		port, err := freeport.GetFreePort()
+		if err != nil {
+			return fmt.Errorf("unable to find a free port for the server. Error: %q", err)
+		}
+		address = fmt.Sprintf("localhost:%d", port)
+	}
+	dialCtx, cancelDial := context.WithTimeout(t.Env.Ctx, t.startupTimeout)
+	defer cancelDial()
+	if len(t.GRPCConfig.ServerCMD) != 0 {
+		if err := t.startServer(address, cancelDial); err != nil {
+			return err
+		}
+	}
+	conn, err := grpc.DialContext(dialCtx, address, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("failed to connect to the server at %s within %s . Error: %q", address, t.startupTimeout, err)
+	}
+	t.conn = conn
+	t.client = transformergrpc.NewTransformerClient(conn)
+	return nil
+}
+
+// startServer runs the server command in the context directory of the transformer.
+// The server is started directly instead of through the environment, so that it can be killed and waited for when the transformer is stopped.
+func (t *GRPCTransformer) startServer(address string, onExit func()) error {
+	if common.DisableLocalExecution {
+		return fmt.Errorf("local execution prevented by %s flag", common.DisableLocalExecutionFlag)
+	}
+	cmd := t.GRPCConfig.ServerCMD
+	server := exec.Command(cmd[0], append(append([]string{}, cmd[1:]...), address)...)
+	server.Dir = t.Env.Env.GetContext()
+	server.Env = os.Environ()
+	if t.qaRPCReceiverAddr != nil {
+		server.Env = append(server.Env, environment.GRPCEnvName+"="+t.qaRPCReceiverAddr.String())
+	}
+	var output bytes.Buffer
+	server.Stdout = &output
+	server.Stderr = &output
+	logrus.Debugf("starting the server of the transformer %s at %s using the command %+v", t.Config.Name, address, server.Args)
+	if err := server.Start(); err != nil {
+		return fmt.Errorf("failed to start the server using the command %+v . Error: %q", server.Args, err)
+	}
+	t.server = server
+	t.serverDone = make(chan struct{})
+	go func() {
+		defer close(t.serverDone)
+		err := server.Wait()
+		logrus.Debugf("the server of the transformer %s stopped. Error: %v Output:\n%s", t.Config.Name, err, output.String())
+		// stop waiting for a server that will not start
+		onExit()
+	}()
+	return nil
+}
+
+// getRPCContext returns the context of a call. A timeout of 0 means that the call can take as long as it needs.
+func getRPCContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
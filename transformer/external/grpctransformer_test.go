@@ -0,0 +1,204 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/external"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/konveyor/move2kube/types/transformer/transformergrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testTransformerServer detects a service in each directory and transforms each artifact into a path mapping and an artifact,
+// streaming one response for each artifact
+type testTransformerServer struct {
+	transformergrpc.UnimplementedTransformerServer
+	delay time.Duration
+	// failAfter fails the transform after the responses of that many artifacts, if it is not 0
+	failAfter int
+}
+
+func (s *testTransformerServer) DirectoryDetect(ctx context.Context, req *transformergrpc.DirectoryDetectRequest) (*transformergrpc.DirectoryDetectResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(s.delay):
+	}
+	return &transformergrpc.DirectoryDetectResponse{Services: `{"svc1": [{"name": "svc1", "type": "Service"}]}`}, nil
+}
+
+func (s *testTransformerServer) Transform(req *transformergrpc.TransformRequest, stream transformergrpc.Transformer_TransformServer) error {
+	newArtifacts := []transformertypes.Artifact{}
+	if err := json.Unmarshal([]byte(req.NewArtifacts), &newArtifacts); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid artifacts. Error: %q", err)
+	}
+	for i, newArtifact := range newArtifacts {
+		if s.failAfter != 0 && i == s.failAfter {
+			return status.Errorf(codes.Internal, "failed to transform the artifact %s", newArtifact.Name)
+		}
+		pathMappings, _ := json.Marshal([]transformertypes.PathMapping{{Type: transformertypes.DefaultPathMappingType, SrcPath: newArtifact.Name, DestPath: newArtifact.Name}})
+		createdArtifacts, _ := json.Marshal([]transformertypes.Artifact{{Name: newArtifact.Name, Type: "Transformed"}})
+		if err := stream.Send(&transformergrpc.TransformResponse{PathMappings: string(pathMappings), CreatedArtifacts: string(createdArtifacts)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startTestGRPCServer serves the transformer on a free port of localhost until the end of the test
+func startTestGRPCServer(t *testing.T, server transformergrpc.TransformerServer) string {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen on a free port. Error: %q", err)
+	}
+	grpcServer := grpc.NewServer()
+	transformergrpc.RegisterTransformerServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	return listener.Addr().String()
+}
+
+func newTestGRPCTransformer(t *testing.T, config map[string]interface{}) *external.GRPCTransformer {
+	common.TempPath = t.TempDir()
+	tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Config: config}}
+	tc.Name = "test"
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{Name: "test", Source: t.TempDir(), Output: t.TempDir(), Context: t.TempDir()}}
+	transformer := &external.GRPCTransformer{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	t.Cleanup(func() {
+		transformer.Stop()
+		transformer.Env.Destroy()
+	})
+	return transformer
+}
+
+func TestGRPCTransformerInit(t *testing.T) {
+	tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Config: map[string]interface{}{}}}
+	tc.Name = "test"
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{Name: "test", Source: t.TempDir(), Output: t.TempDir(), Context: t.TempDir()}}
+	if err := (&external.GRPCTransformer{}).Init(tc, env); err == nil {
+		t.Fatalf("expected the transformer without a serverCMD and an address to fail to initialize")
+	}
+}
+
+func TestGRPCTransformerDirectoryDetect(t *testing.T) {
+	t.Run("detect the services of the directory", func(t *testing.T) {
+		address := startTestGRPCServer(t, &testTransformerServer{})
+		transformer := newTestGRPCTransformer(t, map[string]interface{}{"address": address})
+		dir := t.TempDir()
+		services, err := transformer.DirectoryDetect(dir)
+		if err != nil {
+			t.Fatalf("failed to detect the directory. Error: %q", err)
+		}
+		expected := map[string][]transformertypes.Artifact{"svc1": {{
+			Name:  "svc1",
+			Type:  "Service",
+			Paths: map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {dir}},
+		}}}
+		if diff := cmp.Diff(expected, services); diff != "" {
+			t.Fatalf("the detected services are wrong. Difference:\n%s", diff)
+		}
+	})
+	t.Run("skip the detection that is not implemented", func(t *testing.T) {
+		address := startTestGRPCServer(t, &transformergrpc.UnimplementedTransformerServer{})
+		transformer := newTestGRPCTransformer(t, map[string]interface{}{"address": address})
+		services, err := transformer.DirectoryDetect(t.TempDir())
+		if err != nil || services != nil {
+			t.Fatalf("expected no services and no error. Services: %+v Error: %q", services, err)
+		}
+	})
+	t.Run("fail the detection that takes too long", func(t *testing.T) {
+		address := startTestGRPCServer(t, &testTransformerServer{delay: 5 * time.Second})
+		transformer := newTestGRPCTransformer(t, map[string]interface{}{"address": address, "directoryDetectTimeout": "100ms"})
+		start := time.Now()
+		if _, err := transformer.DirectoryDetect(t.TempDir()); err == nil {
+			t.Fatalf("expected the detection to time out")
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("the detection did not stop at its timeout. Elapsed: %s", elapsed)
+		}
+	})
+	t.Run("fail when the server is not running", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			t.Fatalf("failed to listen on a free port. Error: %q", err)
+		}
+		address := listener.Addr().String()
+		listener.Close()
+		transformer := newTestGRPCTransformer(t, map[string]interface{}{"address": address, "startupTimeout": "200ms"})
+		if _, err := transformer.DirectoryDetect(t.TempDir()); err == nil {
+			t.Fatalf("expected the detection to fail without a server")
+		}
+	})
+}
+
+func TestGRPCTransformerTransform(t *testing.T) {
+	newArtifacts := []transformertypes.Artifact{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	t.Run("collect the streamed responses", func(t *testing.T) {
+		address := startTestGRPCServer(t, &testTransformerServer{})
+		transformer := newTestGRPCTransformer(t, map[string]interface{}{"address": address})
+		pathMappings, createdArtifacts, err := transformer.Transform(newArtifacts, nil)
+		if err != nil {
+			t.Fatalf("failed to transform the artifacts. Error: %q", err)
+		}
+		expectedPathMappings := []transformertypes.PathMapping{}
+		expectedArtifacts := []transformertypes.Artifact{}
+		for _, newArtifact := range newArtifacts {
+			expectedPathMappings = append(expectedPathMappings, transformertypes.PathMapping{Type: transformertypes.DefaultPathMappingType, SrcPath: newArtifact.Name, DestPath: newArtifact.Name})
+			expectedArtifacts = append(expectedArtifacts, transformertypes.Artifact{Name: newArtifact.Name, Type: "Transformed"})
+		}
+		if diff := cmp.Diff(expectedPathMappings, pathMappings); diff != "" {
+			t.Fatalf("the path mappings are wrong. Difference:\n%s", diff)
+		}
+		if diff := cmp.Diff(expectedArtifacts, createdArtifacts); diff != "" {
+			t.Fatalf("the created artifacts are wrong. Difference:\n%s", diff)
+		}
+	})
+	t.Run("return the responses before the error", func(t *testing.T) {
+		address := startTestGRPCServer(t, &testTransformerServer{failAfter: 2})
+		transformer := newTestGRPCTransformer(t, map[string]interface{}{"address": address})
+		pathMappings, createdArtifacts, err := transformer.Transform(newArtifacts, nil)
+		if err == nil {
+			t.Fatalf("expected the transform to fail")
+		}
+		if len(pathMappings) != 2 || len(createdArtifacts) != 2 {
+			t.Fatalf("expected the responses of the first 2 artifacts. Path mappings: %+v Artifacts: %+v", pathMappings, createdArtifacts)
+		}
+	})
+	t.Run("skip the transform that is not implemented", func(t *testing.T) {
+		address := startTestGRPCServer(t, &transformergrpc.UnimplementedTransformerServer{})
+		transformer := newTestGRPCTransformer(t, map[string]interface{}{"address": address})
+		pathMappings, createdArtifacts, err := transformer.Transform(newArtifacts, nil)
+		if err != nil || pathMappings != nil || createdArtifacts != nil {
+			t.Fatalf("expected no output and no error. Path mappings: %+v Artifacts: %+v Error: %q", pathMappings, createdArtifacts, err)
+		}
+	})
+}
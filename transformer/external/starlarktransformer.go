@@ -27,6 +27,7 @@ import (
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
 	"github.com/konveyor/move2kube/types"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
@@ -41,6 +42,7 @@ const (
 	baseDirectoryDetectFnName = "base_directory_detect"
 	directoryDetectFnName     = "directory_detect"
 	transformFnName           = "transform"
+	decorateFnName            = "decorate"
 
 	sourceDirVarName         = "source_dir"
 	contextDirVarName        = "context_dir"
@@ -72,6 +74,7 @@ type Starlark struct {
 
 	detectFn    *starlark.Function
 	transformFn *starlark.Function
+	decorateFn  *starlark.Function
 }
 
 // StarYamlConfig defines yaml config for Starlark transformers
@@ -138,8 +141,12 @@ func (t *Starlark) Init(tc transformertypes.Transformer, env *environment.Enviro
 	err = t.loadFunctions()
 	if err != nil {
 		logrus.Errorf("Unable to load required functions : %s", err)
+		return err
+	}
+	if t.decorateFn != nil {
+		apiresource.RegisterObjectDecorator(tc.Name, t.decorate)
 	}
-	return err
+	return nil
 }
 
 // GetConfig returns the transformer config
@@ -154,6 +161,9 @@ func (t *Starlark) DirectoryDetect(dir string) (services map[string][]transforme
 
 // Transform transforms the artifacts
 func (t *Starlark) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) (pathMappings []transformertypes.PathMapping, createdArtifacts []transformertypes.Artifact, err error) {
+	if t.transformFn == nil {
+		return nil, nil, nil
+	}
 	naObj, err := common.GetMapInterfaceFromObj(newArtifacts)
 	if err != nil {
 		logrus.Errorf("Unable to convert new artifacts to map[string]interface{}")
@@ -338,6 +348,11 @@ func (t *Starlark) loadFunctions() (err error) {
 		logrus.Errorf("Unable to load detect function : %s", err)
 		return err
 	}
+	err = t.loadDecorateFn()
+	if err != nil {
+		logrus.Errorf("Unable to load decorate function : %s", err)
+		return err
+	}
 	err = t.loadTransformFn()
 	if err != nil {
 		logrus.Errorf("Unable to load transform function : %s", err)
@@ -366,8 +381,57 @@ func (t *Starlark) loadDetectFn() (err error) {
 	return nil
 }
 
+func (t *Starlark) loadDecorateFn() (err error) {
+	if !t.StarGlobals.Has(decorateFnName) {
+		return nil
+	}
+	decorateFn := t.StarGlobals[decorateFnName]
+	fn, ok := decorateFn.(*starlark.Function)
+	if !ok {
+		err = fmt.Errorf("%s is not a function", decorateFn)
+		logrus.Errorf("%s", err)
+		return err
+	}
+	if fn.NumParams() != 1 {
+		err = fmt.Errorf("%s does not have the required number of paramters. It has %d, expected %d", decorateFn, fn.NumParams(), 1)
+		logrus.Errorf("%s", err)
+		return err
+	}
+	t.decorateFn = fn
+	return nil
+}
+
+// decorate calls the decorate function of the starlark file on a generated Kubernetes object.
+// The function returns the decorated object, or None if the object should not be written.
+func (t *Starlark) decorate(obj map[string]interface{}) (map[string]interface{}, error) {
+	starObj, err := starutil.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert the object to a starlark value. Error: %q", err)
+	}
+	val, err := starlark.Call(t.StarThread, t.decorateFn, starlark.Tuple{starObj}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call the starlark function %s . Error: %q", t.decorateFn.String(), err)
+	}
+	if val == starlark.None {
+		return nil, nil
+	}
+	valI, err := starutil.Unmarshal(val)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the result of the starlark function. Error: %q", err)
+	}
+	decoratedObj, ok := valI.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the starlark function %s returned a %T instead of a dict with string keys", t.decorateFn.String(), valI)
+	}
+	return decoratedObj, nil
+}
+
 func (t *Starlark) loadTransformFn() (err error) {
 	if !t.StarGlobals.Has(transformFnName) {
+		if t.decorateFn != nil {
+			// transformers that only decorate the generated objects do not need a transform function
+			return nil
+		}
 		err = fmt.Errorf("no %s function found", transformFnName)
 		logrus.Errorf("%s", err)
 		return err
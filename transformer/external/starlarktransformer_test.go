@@ -0,0 +1,89 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/external"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+const decorateStarFile = `
+def decorate(obj):
+    if obj["metadata"]["name"] == "debug":
+        return None
+    obj["metadata"]["labels"] = {"team": "payments"}
+    obj["metadata"]["name"] = "acme-" + obj["metadata"]["name"]
+    return obj
+`
+
+func TestStarlarkDecorate(t *testing.T) {
+	common.TempPath = t.TempDir()
+	context := t.TempDir()
+	if err := os.WriteFile(filepath.Join(context, "decorate.star"), []byte(decorateStarFile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the starlark file. Error: %q", err)
+	}
+	env, err := environment.NewEnvironment(environment.EnvInfo{Name: "test", Source: t.TempDir(), Output: t.TempDir(), Context: context}, nil, environmenttypes.Container{})
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	t.Cleanup(func() { env.Destroy() })
+	tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Config: map[string]interface{}{"starFile": "decorate.star"}}}
+	tc.Name = "decorator"
+	transformer := &external.Starlark{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	// transformers that only decorate objects do nothing during the transform
+	if pathMappings, createdArtifacts, err := transformer.Transform(nil, nil); err != nil || pathMappings != nil || createdArtifacts != nil {
+		t.Fatalf("expected the transform to do nothing. Actual: %+v %+v Error: %q", pathMappings, createdArtifacts, err)
+	}
+
+	inputDir, outputDir := t.TempDir(), t.TempDir()
+	deployments := map[string]string{
+		"web":   "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+		"debug": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: debug\n",
+	}
+	for name, deployment := range deployments {
+		if err := os.WriteFile(filepath.Join(inputDir, name+"-deployment.yaml"), []byte(deployment), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the deployment %s . Error: %q", name, err)
+		}
+	}
+	files, err := apiresource.TransformObjsAndPersist(inputDir, outputDir, []apiresource.IAPIResource{new(apiresource.Deployment)}, collecttypes.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to transform the objects. Error: %q", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected the decorator to remove the debug deployment. Actual: %+v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read the decorated deployment. Error: %q", err)
+	}
+	if !strings.Contains(string(data), "name: acme-web") || !strings.Contains(string(data), "team: payments") {
+		t.Fatalf("expected the deployment to be renamed and labelled. Actual:\n%s", data)
+	}
+}
@@ -0,0 +1,25 @@
+;; echo.wasm is built from this file using wat2wasm.
+;; directoryDetect and transform read their input from stdin and write an artifact
+;; with the input in its Input config to stdout.
+(module
+  (import "wasi_snapshot_preview1" "fd_read" (func $fd_read (param i32 i32 i32 i32) (result i32)))
+  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  ;; the iovec of stdin, and the number of bytes read at 8
+  (data (i32.const 0) "\00\04\00\00\70\17\00\00")
+  ;; the iovecs of the output of directoryDetect at 16 and of transform at 48: the prefix, the input and the suffix
+  (data (i32.const 16) "\00\20\00\00\3b\00\00\00\00\04\00\00\00\00\00\00\3b\20\00\00\04\00\00\00")
+  (data (i32.const 48) "\3f\20\00\00\93\00\00\00\00\04\00\00\00\00\00\00\d2\20\00\00\04\00\00\00")
+  ;; the prefixes and the suffixes
+  (data (i32.const 8192) "{\"svc1\":[{\"name\":\"svc1\",\"type\":\"Service\",\"config\":{\"Input\":}}]}{\"pathMappings\":[{\"type\":\"Default\",\"sourcePath\":\"src\",\"destinationPath\":\"dest\"}],\"artifacts\":[{\"name\":\"out\",\"type\":\"Transformed\",\"config\":{\"Input\":}}]}")
+  (func (export "_initialize"))
+  (func (export "directoryDetect") (result i32)
+    (drop (call $fd_read (i32.const 0) (i32.const 0) (i32.const 1) (i32.const 8)))
+    (i32.store (i32.const 28) (i32.load (i32.const 8)))
+    (drop (call $fd_write (i32.const 1) (i32.const 16) (i32.const 3) (i32.const 40)))
+    (i32.const 0))
+  (func (export "transform") (result i32)
+    (drop (call $fd_read (i32.const 0) (i32.const 0) (i32.const 1) (i32.const 8)))
+    (i32.store (i32.const 60) (i32.load (i32.const 8)))
+    (drop (call $fd_write (i32.const 1) (i32.const 48) (i32.const 3) (i32.const 40)))
+    (i32.const 0)))
@@ -0,0 +1,5 @@
+;; grow.wasm is built from this file using wat2wasm. directoryDetect returns 1 if it cannot grow the memory by 16 pages.
+(module
+  (memory (export "memory") 1)
+  (func (export "directoryDetect") (result i32)
+    (i32.eq (memory.grow (i32.const 16)) (i32.const -1))))
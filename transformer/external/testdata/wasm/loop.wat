@@ -0,0 +1,6 @@
+;; loop.wasm is built from this file using wat2wasm. directoryDetect never returns.
+(module
+  (memory (export "memory") 1)
+  (func (export "directoryDetect") (result i32)
+    (loop (br 0))
+    (i32.const 0)))
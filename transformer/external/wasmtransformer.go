@@ -0,0 +1,258 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	wasmDirectoryDetectFunction = "directoryDetect"
+	wasmTransformFunction       = "transform"
+	// wasmInitializeFunction initializes the WASI reactor modules before their exported functions are called
+	wasmInitializeFunction = "_initialize"
+	wasmPageSize           = 64 * 1024
+)
+
+// WASM implements transformer interface and is used to write external transformers as WebAssembly modules.
+// The module runs in a sandbox inside move2kube, so it works on every platform without a container.
+type WASM struct {
+	Config     transformertypes.Transformer
+	Env        *environment.Environment
+	WASMConfig *WASMYamlConfig
+
+	directoryDetectTimeout time.Duration
+	transformTimeout       time.Duration
+	memoryLimitPages       uint32
+
+	compileOnce sync.Once
+	compileErr  error
+	runtime     wazero.Runtime
+	module      wazero.CompiledModule
+}
+
+// WASMYamlConfig is the format of the config of the WASM transformers
+type WASMYamlConfig struct {
+	// Module is the path of the WebAssembly module, relative to the directory of the transformer yaml.
+	// It is a WASI module that exports the directoryDetect and transform functions, which take no parameters.
+	Module string `yaml:"module"`
+	// MemoryLimit is the maximum memory of the module, like 256Mi. By default the module can use up to 4Gi.
+	MemoryLimit string `yaml:"memoryLimit,omitempty"`
+	// DirectoryDetectTimeout is how long the detection of each directory can take, like 30s. The module is stopped if it takes longer.
+	DirectoryDetectTimeout string `yaml:"directoryDetectTimeout,omitempty"`
+	// TransformTimeout is how long the transformation of the artifacts can take, like 10m. The module is stopped if it takes longer.
+	TransformTimeout string `yaml:"transformTimeout,omitempty"`
+}
+
+// wasmDirectoryDetectInput is written to the stdin of the directoryDetect function
+type wasmDirectoryDetectInput struct {
+	Dir string `json:"dir"`
+}
+
+// wasmTransformInput is written to the stdin of the transform function
+type wasmTransformInput struct {
+	NewArtifacts         []transformertypes.Artifact `json:"newArtifacts"`
+	AlreadySeenArtifacts []transformertypes.Artifact `json:"alreadySeenArtifacts"`
+}
+
+// Init Initializes the transformer
+func (t *WASM) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.WASMConfig = &WASMYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.WASMConfig); err != nil {
+		return fmt.Errorf("unable to load config for Transformer %+v into %T . Error: %q", t.Config.Spec.Config, t.WASMConfig, err)
+	}
+	if t.WASMConfig.Module == "" {
+		return fmt.Errorf("the transformer %s has no WebAssembly module", tc.Name)
+	}
+	if t.directoryDetectTimeout, err = parseTimeout(t.WASMConfig.DirectoryDetectTimeout); err != nil {
+		return fmt.Errorf("invalid directoryDetectTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
+	if t.transformTimeout, err = parseTimeout(t.WASMConfig.TransformTimeout); err != nil {
+		return fmt.Errorf("invalid transformTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
+	if t.WASMConfig.MemoryLimit != "" {
+		quantity, err := resource.ParseQuantity(t.WASMConfig.MemoryLimit)
+		if err != nil {
+			return fmt.Errorf("invalid memoryLimit in the config of the transformer %s . Error: %q", tc.Name, err)
+		}
+		pages := quantity.Value() / wasmPageSize
+		if pages < 1 || pages > 65536 {
+			return fmt.Errorf("the memoryLimit %s of the transformer %s is not between 64Ki and 4Gi", t.WASMConfig.MemoryLimit, tc.Name)
+		}
+		t.memoryLimitPages = uint32(pages)
+	}
+	t.Env, err = environment.NewEnvironment(env.EnvInfo, nil, environmenttypes.Container{})
+	if err != nil {
+		return fmt.Errorf("unable to create the environment of the transformer %s . Error: %q", tc.Name, err)
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *WASM) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect calls the directoryDetect function of the module
+func (t *WASM) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	stdout, ok, err := t.call(wasmDirectoryDetectFunction, wasmDirectoryDetectInput{Dir: dir}, t.directoryDetectTimeout)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(stdout, &services); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the services detected by the transformer %s in the directory %s . Error: %q", t.Config.Name, dir, err)
+	}
+	for _, ns := range services {
+		for nsi, nst := range ns {
+			if len(nst.Paths) == 0 {
+				nst.Paths = map[transformertypes.PathType][]string{
+					artifacts.ServiceDirPathType: {dir},
+				}
+				ns[nsi] = nst
+			}
+		}
+	}
+	return services, nil
+}
+
+// Transform calls the transform function of the module
+func (t *WASM) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) (pathMappings []transformertypes.PathMapping, createdArtifacts []transformertypes.Artifact, err error) {
+	stdout, ok, err := t.call(wasmTransformFunction, wasmTransformInput{NewArtifacts: newArtifacts, AlreadySeenArtifacts: alreadySeenArtifacts}, t.transformTimeout)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+	var output transformertypes.TransformOutput
+	if len(bytes.TrimSpace(stdout)) != 0 {
+		if err := json.Unmarshal(stdout, &output); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal the output of the transformer %s . Error: %q", t.Config.Name, err)
+		}
+	}
+	return output.PathMappings, output.CreatedArtifacts, nil
+}
+
+// Stop releases the compiled module and the runtime
+func (t *WASM) Stop() {
+	if t.runtime != nil {
+		if err := t.runtime.Close(context.Background()); err != nil {
+			logrus.Debugf("failed to close the WebAssembly runtime of the transformer %s . Error: %q", t.Config.Name, err)
+		}
+	}
+}
+
+// compile compiles the module on the first call, so that the transformers that are never used are not compiled
+func (t *WASM) compile() error {
+	t.compileOnce.Do(func() {
+		modulePath := filepath.Join(t.Env.GetEnvironmentContext(), t.WASMConfig.Module)
+		moduleBytes, err := os.ReadFile(modulePath)
+		if err != nil {
+			t.compileErr = fmt.Errorf("failed to read the WebAssembly module at path %s . Error: %q", modulePath, err)
+			return
+		}
+		config := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+		if t.memoryLimitPages != 0 {
+			config = config.WithMemoryLimitPages(t.memoryLimitPages)
+		}
+		ctx := context.Background()
+		t.runtime = wazero.NewRuntimeWithConfig(ctx, config)
+		if _, err := wasi_snapshot_preview1.Instantiate(ctx, t.runtime); err != nil {
+			t.compileErr = fmt.Errorf("failed to instantiate WASI. Error: %q", err)
+			return
+		}
+		t.module, err = t.runtime.CompileModule(ctx, moduleBytes)
+		if err != nil {
+			t.compileErr = fmt.Errorf("failed to compile the WebAssembly module at path %s . Error: %q", modulePath, err)
+		}
+	})
+	return t.compileErr
+}
+
+// call instantiates the module and calls the function with the input as json on stdin, returning what the function wrote to stdout.
+// Each call gets a new instance of the module, so the calls can not affect each other.
+// The source and context directories of the environment are mounted read only, and its temp directory is mounted read write, at the same paths.
+// ok is false if the module does not export the function.
+func (t *WASM) call(functionName string, input interface{}, timeout time.Duration) (stdout []byte, ok bool, err error) {
+	if err := t.compile(); err != nil {
+		return nil, false, fmt.Errorf("failed to load the transformer %s . Error: %q", t.Config.Name, err)
+	}
+	if _, ok := t.module.ExportedFunctions()[functionName]; !ok {
+		return nil, false, nil
+	}
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal the input of the %s function to json. Error: %q", functionName, err)
+	}
+	ctx, cancel := getRPCContext(t.Env.Ctx, timeout)
+	defer cancel()
+	var stdoutBuf, stderrBuf bytes.Buffer
+	source, contextDir, temp := t.Env.GetEnvironmentSource(), t.Env.GetEnvironmentContext(), t.Env.TempPath
+	fsConfig := wazero.NewFSConfig().WithReadOnlyDirMount(source, filepath.ToSlash(source)).WithReadOnlyDirMount(contextDir, filepath.ToSlash(contextDir)).WithDirMount(temp, filepath.ToSlash(temp))
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions(wasmInitializeFunction).
+		WithStdin(bytes.NewReader(inputJSON)).
+		WithStdout(&stdoutBuf).
+		WithStderr(&stderrBuf).
+		WithFSConfig(fsConfig).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithEnv(environment.ProjectNameEnvName, t.Env.GetProjectName()).
+		WithEnv(environment.SourceEnvName, source).
+		WithEnv(environment.ContextEnvName, contextDir).
+		WithEnv(environment.TempPathEnvName, temp)
+	module, err := t.runtime.InstantiateModule(ctx, t.module, moduleConfig)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to instantiate the WebAssembly module of the transformer %s . Error: %q", t.Config.Name, err)
+	}
+	defer module.Close(context.Background())
+	results, err := module.ExportedFunction(functionName).Call(ctx)
+	logrus.Debugf("the %s function of the transformer %s wrote to stderr:\n%s", functionName, t.Config.Name, stderrBuf.String())
+	if err != nil {
+		exitErr := &sys.ExitError{}
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 0 {
+			return nil, false, fmt.Errorf("the %s function of the transformer %s failed. Error: %q Stderr: %s", functionName, t.Config.Name, err, strings.TrimSpace(stderrBuf.String()))
+		}
+	}
+	if len(results) != 0 && api.DecodeI32(results[0]) != 0 {
+		return nil, false, fmt.Errorf("the %s function of the transformer %s returned %d . Stderr: %s", functionName, t.Config.Name, api.DecodeI32(results[0]), strings.TrimSpace(stderrBuf.String()))
+	}
+	return stdoutBuf.Bytes(), true, nil
+}
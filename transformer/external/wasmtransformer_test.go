@@ -0,0 +1,160 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/external"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+// newTestWASM initializes a WASM transformer with a module of testdata/wasm.
+// echo.wasm puts its input into the config of the artifacts it outputs, loop.wasm never returns from directoryDetect
+// and the directoryDetect of grow.wasm fails if it cannot grow its memory by 1Mi.
+func newTestWASM(t *testing.T, module string, config map[string]interface{}) *external.WASM {
+	common.TempPath = t.TempDir()
+	context := t.TempDir()
+	moduleBytes, err := os.ReadFile(filepath.Join("testdata", "wasm", module))
+	if err != nil {
+		t.Fatalf("failed to read the module %s . Error: %q", module, err)
+	}
+	if err := os.WriteFile(filepath.Join(context, module), moduleBytes, 0644); err != nil {
+		t.Fatalf("failed to write the module %s . Error: %q", module, err)
+	}
+	config["module"] = module
+	tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Config: config}}
+	tc.Name = "test"
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{Name: "test", Source: t.TempDir(), Output: t.TempDir(), Context: context}}
+	transformer := &external.WASM{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	t.Cleanup(func() {
+		transformer.Stop()
+		transformer.Env.Destroy()
+	})
+	return transformer
+}
+
+func TestWASMInit(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config map[string]interface{}
+	}{
+		{name: "no module", config: map[string]interface{}{}},
+		{name: "invalid memory limit", config: map[string]interface{}{"module": "echo.wasm", "memoryLimit": "lots"}},
+		{name: "memory limit below a page", config: map[string]interface{}{"module": "echo.wasm", "memoryLimit": "1Ki"}},
+		{name: "memory limit above 4Gi", config: map[string]interface{}{"module": "echo.wasm", "memoryLimit": "8Gi"}},
+		{name: "invalid timeout", config: map[string]interface{}{"module": "echo.wasm", "transformTimeout": "soon"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			common.TempPath = t.TempDir()
+			tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Config: testCase.config}}
+			tc.Name = "test"
+			env := &environment.Environment{EnvInfo: environment.EnvInfo{Name: "test", Source: t.TempDir(), Output: t.TempDir(), Context: t.TempDir()}}
+			if err := (&external.WASM{}).Init(tc, env); err == nil {
+				t.Fatalf("expected the transformer to fail to initialize")
+			}
+		})
+	}
+}
+
+func TestWASMDirectoryDetect(t *testing.T) {
+	transformer := newTestWASM(t, "echo.wasm", map[string]interface{}{})
+	dir := t.TempDir()
+	services, err := transformer.DirectoryDetect(dir)
+	if err != nil {
+		t.Fatalf("failed to detect the directory. Error: %q", err)
+	}
+	expected := map[string][]transformertypes.Artifact{"svc1": {{
+		Name:    "svc1",
+		Type:    "Service",
+		Paths:   map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {dir}},
+		Configs: map[transformertypes.ConfigType]interface{}{"Input": map[string]interface{}{"dir": dir}},
+	}}}
+	if diff := cmp.Diff(expected, services); diff != "" {
+		t.Fatalf("the detected services are wrong. Difference:\n%s", diff)
+	}
+}
+
+func TestWASMTransform(t *testing.T) {
+	t.Run("transform the artifacts", func(t *testing.T) {
+		transformer := newTestWASM(t, "echo.wasm", map[string]interface{}{})
+		pathMappings, createdArtifacts, err := transformer.Transform([]transformertypes.Artifact{{Name: "svc1"}}, nil)
+		if err != nil {
+			t.Fatalf("failed to transform the artifacts. Error: %q", err)
+		}
+		expectedPathMappings := []transformertypes.PathMapping{{Type: transformertypes.DefaultPathMappingType, SrcPath: "src", DestPath: "dest"}}
+		if diff := cmp.Diff(expectedPathMappings, pathMappings); diff != "" {
+			t.Fatalf("the path mappings are wrong. Difference:\n%s", diff)
+		}
+		expectedArtifacts := []transformertypes.Artifact{{
+			Name: "out",
+			Type: "Transformed",
+			Configs: map[transformertypes.ConfigType]interface{}{"Input": map[string]interface{}{
+				"newArtifacts":         []interface{}{map[string]interface{}{"name": "svc1", "processWith": map[string]interface{}{}}},
+				"alreadySeenArtifacts": nil,
+			}},
+		}}
+		if diff := cmp.Diff(expectedArtifacts, createdArtifacts); diff != "" {
+			t.Fatalf("the created artifacts are wrong. Difference:\n%s", diff)
+		}
+	})
+	t.Run("skip the functions that are not exported", func(t *testing.T) {
+		transformer := newTestWASM(t, "grow.wasm", map[string]interface{}{})
+		pathMappings, createdArtifacts, err := transformer.Transform([]transformertypes.Artifact{{Name: "svc1"}}, nil)
+		if err != nil || pathMappings != nil || createdArtifacts != nil {
+			t.Fatalf("expected no output and no error. Path mappings: %+v Artifacts: %+v Error: %q", pathMappings, createdArtifacts, err)
+		}
+	})
+}
+
+func TestWASMLimits(t *testing.T) {
+	t.Run("stop the function at its timeout", func(t *testing.T) {
+		transformer := newTestWASM(t, "loop.wasm", map[string]interface{}{"directoryDetectTimeout": "100ms"})
+		start := time.Now()
+		if _, err := transformer.DirectoryDetect(t.TempDir()); err == nil {
+			t.Fatalf("expected the detection to be stopped at its timeout")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("the detection did not stop at its timeout. Elapsed: %s", elapsed)
+		}
+	})
+	t.Run("grow the memory without a memory limit", func(t *testing.T) {
+		transformer := newTestWASM(t, "grow.wasm", map[string]interface{}{})
+		if _, err := transformer.DirectoryDetect(t.TempDir()); err != nil {
+			t.Fatalf("failed to detect the directory. Error: %q", err)
+		}
+	})
+	t.Run("do not grow the memory above the memory limit", func(t *testing.T) {
+		transformer := newTestWASM(t, "grow.wasm", map[string]interface{}{"memoryLimit": "128Ki"})
+		_, err := transformer.DirectoryDetect(t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), "returned 1") {
+			t.Fatalf("expected the detection to fail to grow the memory. Error: %v", err)
+		}
+	})
+}
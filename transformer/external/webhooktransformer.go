@@ -0,0 +1,406 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultWebhookRetries = 3
+	webhookRetryBackoff   = time.Second
+	// maxWebhookFileSize is the size of the biggest file sent to a webhook
+	maxWebhookFileSize = 1024 * 1024
+	// maxWebhookResponseSize is the size of the biggest response read from a webhook
+	maxWebhookResponseSize = 64 * 1024 * 1024
+)
+
+// Webhook implements transformer interface and is used to write external transformers served by an HTTPS endpoint.
+// The artifacts are sent as json in POST requests and the endpoint responds with the path mappings and the created artifacts,
+// so transformation services run by a central team can be used without shipping binaries or images.
+type Webhook struct {
+	Config        transformertypes.Transformer
+	Env           *environment.Environment
+	WebhookConfig *WebhookYamlConfig
+
+	directoryDetectTimeout time.Duration
+	transformTimeout       time.Duration
+	client                 *http.Client
+}
+
+// WebhookYamlConfig is the format of the config of the webhook transformers
+type WebhookYamlConfig struct {
+	// DirectoryDetectURL is called with each directory. Directories are not detected if it is empty.
+	DirectoryDetectURL string `yaml:"directoryDetectURL,omitempty"`
+	// TransformURL is called with the artifacts consumed by the transformer.
+	TransformURL string `yaml:"transformURL,omitempty"`
+	// Headers are added to every request, like the Authorization header
+	Headers []WebhookHeader `yaml:"headers,omitempty"`
+	// SendFiles are the glob patterns of the files whose contents are sent along with the directory or the artifacts,
+	// relative to the directory or the directories of the artifacts, like pom.xml or src/main/resources/*.properties
+	SendFiles []string `yaml:"sendFiles,omitempty"`
+	// Retries is the number of times a request is retried when it fails with a network error, 429 or a 5xx status. The default is 3.
+	Retries *int `yaml:"retries,omitempty"`
+	// CACert is the path of the PEM encoded certificates of the CAs of the endpoint, relative to the directory of the transformer yaml
+	CACert string `yaml:"caCert,omitempty"`
+	// AllowHTTP allows URLs that are not https, like the ones of a local test server
+	AllowHTTP bool `yaml:"allowHTTP,omitempty"`
+	// DirectoryDetectTimeout is how long the detection of each directory can take, like 30s, including the retries.
+	DirectoryDetectTimeout string `yaml:"directoryDetectTimeout,omitempty"`
+	// TransformTimeout is how long the transformation of the artifacts can take, like 10m, including the retries.
+	TransformTimeout string `yaml:"transformTimeout,omitempty"`
+}
+
+// WebhookHeader is a header of the requests. The ${ENV} references in the value are replaced by the environment variables of the host,
+// so that the credentials do not have to be in the transformer yaml.
+type WebhookHeader struct {
+	Name     string `yaml:"name"`
+	Value    string `yaml:"value,omitempty"`
+	FromFile string `yaml:"fromFile,omitempty"` // file whose contents is the value, relative to the directory of the transformer yaml
+}
+
+// webhookDirectoryDetectRequest is the body of the requests to the directoryDetectURL
+type webhookDirectoryDetectRequest struct {
+	Dir     string            `json:"dir"`
+	Entries []string          `json:"entries"`
+	Files   map[string][]byte `json:"files,omitempty"`
+}
+
+// webhookTransformRequest is the body of the requests to the transformURL
+type webhookTransformRequest struct {
+	NewArtifacts         []transformertypes.Artifact `json:"newArtifacts"`
+	AlreadySeenArtifacts []transformertypes.Artifact `json:"alreadySeenArtifacts"`
+	Files                map[string][]byte           `json:"files,omitempty"`
+}
+
+// webhookTransformResponse is the body of the responses of the transformURL.
+// The files are written to a temporary directory, and the relative source paths of the path mappings refer to them.
+type webhookTransformResponse struct {
+	transformertypes.TransformOutput
+	Files map[string][]byte `json:"files,omitempty"`
+}
+
+// Init Initializes the transformer
+func (t *Webhook) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.WebhookConfig = &WebhookYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.WebhookConfig); err != nil {
+		return fmt.Errorf("unable to load config for Transformer %+v into %T . Error: %q", t.Config.Spec.Config, t.WebhookConfig, err)
+	}
+	if t.WebhookConfig.DirectoryDetectURL == "" && t.WebhookConfig.TransformURL == "" {
+		return fmt.Errorf("the transformer %s has neither a directoryDetectURL nor a transformURL", tc.Name)
+	}
+	for _, endpoint := range []string{t.WebhookConfig.DirectoryDetectURL, t.WebhookConfig.TransformURL} {
+		if endpoint == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid url %s in the config of the transformer %s . Error: %q", endpoint, tc.Name, err)
+		}
+		if u.Scheme != "https" && !(t.WebhookConfig.AllowHTTP && u.Scheme == "http") {
+			return fmt.Errorf("the url %s in the config of the transformer %s is not https. Set allowHTTP to use it", endpoint, tc.Name)
+		}
+	}
+	if t.directoryDetectTimeout, err = parseTimeout(t.WebhookConfig.DirectoryDetectTimeout); err != nil {
+		return fmt.Errorf("invalid directoryDetectTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
+	if t.transformTimeout, err = parseTimeout(t.WebhookConfig.TransformTimeout); err != nil {
+		return fmt.Errorf("invalid transformTimeout in the config of the transformer %s . Error: %q", tc.Name, err)
+	}
+	t.Env, err = environment.NewEnvironment(env.EnvInfo, nil, environmenttypes.Container{})
+	if err != nil {
+		return fmt.Errorf("unable to create the environment of the transformer %s . Error: %q", tc.Name, err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t.WebhookConfig.CACert != "" {
+		caCertPath := filepath.Join(t.Env.GetEnvironmentContext(), t.WebhookConfig.CACert)
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return fmt.Errorf("failed to read the CA certificates of the transformer %s at path %s . Error: %q", tc.Name, caCertPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("the file at path %s has no PEM encoded certificates", caCertPath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	t.client = &http.Client{Transport: transport}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *Webhook) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect sends the directory to the directoryDetectURL
+func (t *Webhook) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	if t.WebhookConfig.DirectoryDetectURL == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the directory %s . Error: %q", dir, err)
+	}
+	request := webhookDirectoryDetectRequest{Dir: dir, Entries: []string{}, Files: t.getFilesToSend([]string{dir})}
+	for _, entry := range entries {
+		request.Entries = append(request.Entries, entry.Name())
+	}
+	response, err := t.post(t.WebhookConfig.DirectoryDetectURL, request, t.directoryDetectTimeout)
+	if err != nil || len(bytes.TrimSpace(response)) == 0 {
+		return nil, err
+	}
+	if err := json.Unmarshal(response, &services); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the services detected by the transformer %s in the directory %s . Error: %q", t.Config.Name, dir, err)
+	}
+	for _, ns := range services {
+		for nsi, nst := range ns {
+			if len(nst.Paths) == 0 {
+				nst.Paths = map[transformertypes.PathType][]string{
+					artifacts.ServiceDirPathType: {dir},
+				}
+				ns[nsi] = nst
+			}
+		}
+	}
+	return services, nil
+}
+
+// Transform sends the artifacts to the transformURL
+func (t *Webhook) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) (pathMappings []transformertypes.PathMapping, createdArtifacts []transformertypes.Artifact, err error) {
+	if t.WebhookConfig.TransformURL == "" {
+		return nil, nil, nil
+	}
+	dirs := []string{}
+	for _, a := range newArtifacts {
+		dirs = append(dirs, a.Paths[artifacts.ServiceDirPathType]...)
+	}
+	request := webhookTransformRequest{NewArtifacts: newArtifacts, AlreadySeenArtifacts: alreadySeenArtifacts, Files: t.getFilesToSend(dirs)}
+	responseBytes, err := t.post(t.WebhookConfig.TransformURL, request, t.transformTimeout)
+	if err != nil || len(bytes.TrimSpace(responseBytes)) == 0 {
+		return nil, nil, err
+	}
+	response := webhookTransformResponse{}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal the output of the transformer %s . Error: %q", t.Config.Name, err)
+	}
+	if len(response.Files) != 0 {
+		filesDir, err := t.writeResponseFiles(response.Files)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, pathMapping := range response.PathMappings {
+			if pathMapping.Type == transformertypes.PathTemplatePathMappingType || pathMapping.SrcPath == "" || filepath.IsAbs(pathMapping.SrcPath) {
+				continue
+			}
+			if srcPath := filepath.Join(filesDir, pathMapping.SrcPath); common.IsParent(srcPath, filesDir) {
+				if _, err := os.Stat(srcPath); err == nil {
+					response.PathMappings[i].SrcPath = srcPath
+				}
+			}
+		}
+	}
+	return response.PathMappings, response.CreatedArtifacts, nil
+}
+
+// writeResponseFiles writes the files returned by the endpoint to a new temporary directory
+func (t *Webhook) writeResponseFiles(files map[string][]byte) (string, error) {
+	filesDir, err := os.MkdirTemp(t.Env.TempPath, "webhook-files-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a temporary directory for the files returned by the transformer %s . Error: %q", t.Config.Name, err)
+	}
+	for relPath, content := range files {
+		path := filepath.Join(filesDir, filepath.FromSlash(relPath))
+		if filepath.IsAbs(relPath) || !common.IsParent(path, filesDir) || path == filesDir {
+			logrus.Warnf("Ignoring the file %s returned by the transformer %s since its path is not relative", relPath, t.Config.Name)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			return "", fmt.Errorf("failed to create the directory of the file at path %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, content, common.DefaultFilePermission); err != nil {
+			return "", fmt.Errorf("failed to write the file returned by the transformer %s to the path %s . Error: %q", t.Config.Name, path, err)
+		}
+	}
+	return filesDir, nil
+}
+
+// getFilesToSend returns the contents of the files in the directories that match the sendFiles patterns, keyed by their paths
+func (t *Webhook) getFilesToSend(dirs []string) map[string][]byte {
+	files := map[string][]byte{}
+	if len(t.WebhookConfig.SendFiles) == 0 {
+		return files
+	}
+	for _, dir := range dirs {
+		if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != dir && (common.IsStringPresent(common.SkippedDetectionDirNames, d.Name()) || common.SkipDirForDetection(path)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil || !t.isFileToSend(filepath.ToSlash(relPath)) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if info.Size() > maxWebhookFileSize {
+				logrus.Warnf("Not sending the file at path %s to the transformer %s since it is bigger than %d bytes", path, t.Config.Name, maxWebhookFileSize)
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				logrus.Debugf("failed to read the file at path %s . Error: %q", path, err)
+				return nil
+			}
+			files[path] = content
+			return nil
+		}); err != nil {
+			logrus.Debugf("failed to walk the directory %s . Error: %q", dir, err)
+		}
+	}
+	return files
+}
+
+func (t *Webhook) isFileToSend(relPath string) bool {
+	for _, pattern := range t.WebhookConfig.SendFiles {
+		if matched, err := filepath.Match(pattern, relPath); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// post sends the body as json and returns the body of the response.
+// The request is retried with an exponential backoff, or after the time in the Retry-After header, on network errors, 429 and 5xx statuses.
+func (t *Webhook) post(endpoint string, body interface{}, timeout time.Duration) ([]byte, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the request to json. Error: %q", err)
+	}
+	headers, err := t.getHeaders()
+	if err != nil {
+		return nil, err
+	}
+	retries := defaultWebhookRetries
+	if t.WebhookConfig.Retries != nil {
+		retries = *t.WebhookConfig.Retries
+	}
+	ctx, cancel := getRPCContext(t.Env.Ctx, timeout)
+	defer cancel()
+	backoff := webhookRetryBackoff
+	for attempt := 0; ; attempt++ {
+		response, retryAfter, err := t.postOnce(ctx, endpoint, bodyBytes, headers)
+		if err == nil || retryAfter < 0 || attempt >= retries {
+			return response, err
+		}
+		if retryAfter == 0 {
+			retryAfter = backoff
+			backoff *= 2
+		}
+		logrus.Debugf("retrying the request to %s of the transformer %s in %s . Error: %q", endpoint, t.Config.Name, retryAfter, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("the request to %s of the transformer %s did not succeed in time. Last error: %q", endpoint, t.Config.Name, err)
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// postOnce sends the request once. retryAfter is negative if the request should not be retried,
+// and 0 if it should be retried after the backoff.
+func (t *Webhook) postOnce(ctx context.Context, endpoint string, body []byte, headers http.Header) (response []byte, retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to create the request to %s . Error: %q", endpoint, err)
+	}
+	req.Header = headers.Clone()
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, -1, fmt.Errorf("the request to %s of the transformer %s did not succeed in time. Error: %q", endpoint, t.Config.Name, err)
+		}
+		return nil, 0, fmt.Errorf("failed to send the request to %s of the transformer %s . Error: %q", endpoint, t.Config.Name, err)
+	}
+	defer resp.Body.Close()
+	response, err = io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseSize+1))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read the response of %s of the transformer %s . Error: %q", endpoint, t.Config.Name, err)
+	}
+	if len(response) > maxWebhookResponseSize {
+		return nil, -1, fmt.Errorf("the response of %s of the transformer %s is bigger than %d bytes", endpoint, t.Config.Name, maxWebhookResponseSize)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return response, 0, nil
+	}
+	err = fmt.Errorf("the request to %s of the transformer %s failed with the status %s . Response: %s", endpoint, t.Config.Name, resp.Status, strings.TrimSpace(string(response)))
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return nil, -1, err
+	}
+	if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+		return nil, time.Duration(seconds) * time.Second, err
+	}
+	return nil, 0, err
+}
+
+// getHeaders returns the headers of the requests. They are read for every request, so that rotated credentials are picked up.
+func (t *Webhook) getHeaders() (http.Header, error) {
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("Accept", "application/json")
+	for _, header := range t.WebhookConfig.Headers {
+		value := os.ExpandEnv(header.Value)
+		if header.FromFile != "" {
+			path := filepath.Join(t.Env.GetEnvironmentContext(), header.FromFile)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the value of the header %s of the transformer %s from the file at path %s . Error: %q", header.Name, t.Config.Name, path, err)
+			}
+			value = strings.TrimSpace(string(content))
+		}
+		headers.Set(header.Name, value)
+	}
+	return headers, nil
+}
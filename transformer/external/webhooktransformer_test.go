@@ -0,0 +1,202 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external_test
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/external"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+// newTestWebhook initializes a webhook transformer that trusts the certificate of the server
+func newTestWebhook(t *testing.T, server *httptest.Server, config map[string]interface{}) (*external.Webhook, string) {
+	common.TempPath = t.TempDir()
+	source, context, output := t.TempDir(), t.TempDir(), t.TempDir()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(context, "ca.pem"), caCert, 0644); err != nil {
+		t.Fatalf("failed to write the CA certificate. Error: %q", err)
+	}
+	config["caCert"] = "ca.pem"
+	tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Config: config}}
+	tc.Name = "test"
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{Name: "test", Source: source, Output: output, Context: context}}
+	transformer := &external.Webhook{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	t.Cleanup(func() { transformer.Env.Destroy() })
+	return transformer, context
+}
+
+func TestWebhookRetries(t *testing.T) {
+	testCases := []struct {
+		name          string
+		statuses      []int
+		retries       int
+		expectedCalls int32
+		expectedError bool
+	}{
+		{name: "retry after a 503", statuses: []int{http.StatusServiceUnavailable}, retries: 3, expectedCalls: 2},
+		{name: "retry after a 429", statuses: []int{http.StatusTooManyRequests}, retries: 3, expectedCalls: 2},
+		{name: "do not retry a 400", statuses: []int{http.StatusBadRequest}, retries: 3, expectedCalls: 1, expectedError: true},
+		{name: "do not retry a 404", statuses: []int{http.StatusNotFound}, retries: 3, expectedCalls: 1, expectedError: true},
+		{name: "stop after the retries", statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}, retries: 1, expectedCalls: 2, expectedError: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			calls := int32(0)
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := atomic.AddInt32(&calls, 1)
+				if int(call) <= len(testCase.statuses) {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(testCase.statuses[call-1])
+					return
+				}
+				w.Write([]byte(`{"svc1": [{"name": "svc1", "type": "Service"}]}`))
+			}))
+			defer server.Close()
+			transformer, _ := newTestWebhook(t, server, map[string]interface{}{
+				"directoryDetectURL": server.URL + "/detect",
+				"retries":            testCase.retries,
+			})
+			services, err := transformer.DirectoryDetect(t.TempDir())
+			if calls != testCase.expectedCalls {
+				t.Fatalf("expected %d requests. Actual: %d", testCase.expectedCalls, calls)
+			}
+			if testCase.expectedError {
+				if err == nil {
+					t.Fatalf("expected the detection to fail. Actual: %+v", services)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to detect the directory. Error: %q", err)
+			}
+			if len(services["svc1"]) != 1 || services["svc1"][0].Name != "svc1" {
+				t.Fatalf("expected the service svc1 to be detected. Actual: %+v", services)
+			}
+		})
+	}
+}
+
+func TestWebhookHeaders(t *testing.T) {
+	t.Setenv("M2K_TEST_WEBHOOK_TENANT", "tenant1")
+	headers := make(chan http.Header, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+	}))
+	defer server.Close()
+	transformer, context := newTestWebhook(t, server, map[string]interface{}{
+		"directoryDetectURL": server.URL,
+		"headers": []interface{}{
+			map[string]interface{}{"name": "Authorization", "fromFile": "token"},
+			map[string]interface{}{"name": "X-Tenant", "value": "${M2K_TEST_WEBHOOK_TENANT}"},
+		},
+	})
+	if err := os.WriteFile(filepath.Join(context, "token"), []byte("Bearer token1\n"), 0600); err != nil {
+		t.Fatalf("failed to write the token. Error: %q", err)
+	}
+	if _, err := transformer.DirectoryDetect(t.TempDir()); err != nil {
+		t.Fatalf("failed to detect the directory. Error: %q", err)
+	}
+	header := <-headers
+	if actual := header.Get("Authorization"); actual != "Bearer token1" {
+		t.Fatalf("the Authorization header was not read from the file. Expected: Bearer token1 Actual: %s", actual)
+	}
+	if actual := header.Get("X-Tenant"); actual != "tenant1" {
+		t.Fatalf("the environment variable in the header was not expanded. Expected: tenant1 Actual: %s", actual)
+	}
+	if err := os.Remove(filepath.Join(context, "token")); err != nil {
+		t.Fatalf("failed to remove the token. Error: %q", err)
+	}
+	if _, err := transformer.DirectoryDetect(t.TempDir()); err == nil {
+		t.Fatalf("expected the detection to fail when the file of the header is missing")
+	}
+}
+
+func TestWebhookResponseFiles(t *testing.T) {
+	response := map[string]interface{}{
+		"pathMappings": []interface{}{
+			map[string]interface{}{"sourcePath": "Dockerfile", "destinationPath": "svc1/Dockerfile"},
+			map[string]interface{}{"sourcePath": "../escape", "destinationPath": "svc1/escape"},
+		},
+		"files": map[string][]byte{
+			"Dockerfile":      []byte("FROM alpine\n"),
+			"../escape":       []byte("escaped\n"),
+			"sub/../../other": []byte("escaped\n"),
+			"/abs":            []byte("escaped\n"),
+		},
+	}
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("failed to marshal the response. Error: %q", err)
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(responseBytes)
+	}))
+	defer server.Close()
+	transformer, _ := newTestWebhook(t, server, map[string]interface{}{"transformURL": server.URL})
+	pathMappings, _, err := transformer.Transform(nil, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the artifacts. Error: %q", err)
+	}
+	if len(pathMappings) != 2 {
+		t.Fatalf("expected 2 path mappings. Actual: %+v", pathMappings)
+	}
+	filesDir := filepath.Dir(pathMappings[0].SrcPath)
+	if !strings.HasPrefix(filesDir, common.TempPath) || filepath.Base(pathMappings[0].SrcPath) != "Dockerfile" {
+		t.Fatalf("the source path of the returned file was not resolved to the temporary directory. Actual: %s", pathMappings[0].SrcPath)
+	}
+	if content, err := os.ReadFile(pathMappings[0].SrcPath); err != nil || string(content) != "FROM alpine\n" {
+		t.Fatalf("the returned file was not written. Content: %s Error: %q", content, err)
+	}
+	if pathMappings[1].SrcPath != "../escape" {
+		t.Fatalf("the source path outside the temporary directory was resolved. Actual: %s", pathMappings[1].SrcPath)
+	}
+	for _, path := range []string{filepath.Join(filesDir, "..", "escape"), filepath.Join(filesDir, "..", "other"), filepath.Join(filesDir, "abs")} {
+		if _, err := os.Stat(path); err == nil {
+			t.Fatalf("the file with a path that is not relative was written to %s", path)
+		}
+	}
+}
+
+func TestWebhookResponseSize(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunk := []byte(strings.Repeat(" ", 1024*1024))
+		for i := 0; i <= 64; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	transformer, _ := newTestWebhook(t, server, map[string]interface{}{"transformURL": server.URL})
+	if _, _, err := transformer.Transform(nil, nil); err == nil || !strings.Contains(err.Error(), "bigger than") {
+		t.Fatalf("expected the transform to fail with the size of the response. Error: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// externalServiceNameSuffix is added to the name of an external service that has the same name as a service in the cluster
+	externalServiceNameSuffix = "-external"
+)
+
+// ExternalServiceAnalyser implements Transformer interface
+type ExternalServiceAnalyser struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// Init Initializes the transformer
+func (t *ExternalServiceAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *ExternalServiceAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *ExternalServiceAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform asks which dependencies stay outside the cluster, like mainframes, managed databases and SaaS, and adds them to the IR.
+// The detected external endpoints of the services are offered, and dependencies that were not detected can be added.
+// The services in the cluster reach them using stable names, so they can be migrated in phases.
+func (t *ExternalServiceAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		externalServices := []irtypes.ExternalService{}
+		detected := getDetectedExternalServices(ir)
+		if len(detected) > 0 {
+			hosts := []string{}
+			for _, externalService := range detected {
+				hosts = append(hosts, getExternalServiceAddress(externalService))
+			}
+			selected := qaengine.FetchMultiSelectAnswer(
+				common.ConfigExternalServicesDetectedKey,
+				"Select the dependencies that will stay outside the cluster :",
+				[]string{"A service with a stable name in the cluster is created for each selected dependency, so the services can keep using it while the migration is in progress"},
+				hosts,
+				hosts,
+			)
+			for _, externalService := range detected {
+				if common.IsPresent(selected, getExternalServiceAddress(externalService)) {
+					externalServices = append(externalServices, externalService)
+				}
+			}
+		}
+		additional := qaengine.FetchMultilineInputAnswer(
+			common.ConfigExternalServicesAdditionalKey,
+			"Enter the other dependencies that will stay outside the cluster, one per line :",
+			[]string{"Use the format [name=]host:port[,port...] , for example db2=mainframe.example.com:50000 . The host can be a DNS name or an IPv4 address."},
+			"",
+		)
+		externalServices = append(externalServices, parseExternalServices(additional)...)
+		names := map[string]string{}
+		for i, externalService := range externalServices {
+			if host, ok := names[externalService.Name]; ok && host != getExternalServiceAddress(externalService) {
+				// Hosts in different domains can have the same first label
+				externalService.Name = common.NormalizeForMetadataName(getExternalServiceAddress(externalService))
+			}
+			if _, ok := ir.Services[externalService.Name]; ok {
+				externalService.Name += externalServiceNameSuffix
+			}
+			names[externalService.Name] = getExternalServiceAddress(externalService)
+			externalServices[i] = externalService
+		}
+		if len(externalServices) == 0 {
+			a.Configs[irtypes.IRConfigType] = ir
+			artifactsCreated = append(artifactsCreated, a)
+			continue
+		}
+		externalServiceNames := []string{}
+		for _, externalService := range externalServices {
+			if !common.IsPresent(externalServiceNames, externalService.Name) {
+				externalServiceNames = append(externalServiceNames, externalService.Name)
+			}
+		}
+		restricted := qaengine.FetchMultiSelectAnswer(
+			common.ConfigExternalServicesEgressPolicyKey,
+			"Select the external dependencies whose clients should have their egress restricted to the dependency and the cluster :",
+			[]string{"A network policy allows connections to the pods in the namespace, to DNS and to the dependency. All the pods are restricted when the clients of the dependency are not known."},
+			[]string{},
+			externalServiceNames,
+		)
+		for _, externalService := range externalServices {
+			externalService.EgressPolicy = common.IsPresent(restricted, externalService.Name)
+			ir.AddExternalService(externalService)
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return nil, artifactsCreated, nil
+}
+
+// getDetectedExternalServices groups the external endpoints of the services by host, in sorted order
+func getDetectedExternalServices(ir irtypes.IR) []irtypes.ExternalService {
+	externalServices := map[string]irtypes.ExternalService{}
+	for sn, s := range ir.Services {
+		for _, endpoint := range s.ExternalEndpoints {
+			if endpoint.Host == "" {
+				continue
+			}
+			host := strings.ToLower(endpoint.Host)
+			externalService, ok := externalServices[host]
+			if !ok {
+				externalService = newExternalService("", host)
+			}
+			if endpoint.Port != 0 && !common.IsPresent(externalService.Ports, endpoint.Port) {
+				externalService.Ports = append(externalService.Ports, endpoint.Port)
+			}
+			if !common.IsPresent(externalService.Clients, sn) {
+				externalService.Clients = append(externalService.Clients, sn)
+			}
+			externalServices[host] = externalService
+		}
+	}
+	hosts := []string{}
+	for host := range externalServices {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	sorted := []irtypes.ExternalService{}
+	for _, host := range hosts {
+		externalService := externalServices[host]
+		sort.Slice(externalService.Ports, func(i, j int) bool { return externalService.Ports[i] < externalService.Ports[j] })
+		sort.Strings(externalService.Clients)
+		sorted = append(sorted, externalService)
+	}
+	return sorted
+}
+
+// parseExternalServices parses the dependencies entered by the user in the format [name=]host:port[,port...]
+func parseExternalServices(input string) []irtypes.ExternalService {
+	externalServices := []irtypes.ExternalService{}
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := ""
+		if idx := strings.Index(line, "="); idx >= 0 {
+			name, line = strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])
+		}
+		host, portsStr := line, ""
+		if idx := strings.LastIndex(line, ":"); idx >= 0 {
+			host, portsStr = line[:idx], line[idx+1:]
+		}
+		if host == "" {
+			logrus.Errorf("the external dependency %q does not have a host. Skipping it.", line)
+			continue
+		}
+		externalService := newExternalService(name, strings.ToLower(host))
+		for _, portStr := range strings.Split(portsStr, ",") {
+			portStr = strings.TrimSpace(portStr)
+			if portStr == "" {
+				continue
+			}
+			port, err := strconv.ParseInt(portStr, 10, 32)
+			if err != nil || port <= 0 || port > 65535 {
+				logrus.Errorf("the port %q of the external dependency %s is not valid. Ignoring it.", portStr, host)
+				continue
+			}
+			if !common.IsPresent(externalService.Ports, int32(port)) {
+				externalService.Ports = append(externalService.Ports, int32(port))
+			}
+		}
+		externalServices = append(externalServices, externalService)
+	}
+	return externalServices
+}
+
+// newExternalService creates an external service for a host. IPv4 addresses are stored as IPs, other hosts are DNS names.
+// The name is taken from the first label of the DNS name when it is not given.
+func newExternalService(name, host string) irtypes.ExternalService {
+	externalService := irtypes.ExternalService{Name: name}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() != nil {
+		externalService.IPs = []string{host}
+		if externalService.Name == "" {
+			externalService.Name = "external-" + strings.ReplaceAll(host, ".", "-")
+		}
+	} else {
+		externalService.Host = host
+		if externalService.Name == "" {
+			externalService.Name = strings.Split(host, ".")[0]
+		}
+	}
+	externalService.Name = common.NormalizeForMetadataName(externalService.Name)
+	return externalService
+}
+
+// getExternalServiceAddress returns the DNS name or the IP address of an external service
+func getExternalServiceAddress(externalService irtypes.ExternalService) string {
+	if externalService.Host != "" {
+		return externalService.Host
+	}
+	return strings.Join(externalService.IPs, ",")
+}
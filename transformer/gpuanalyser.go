@@ -0,0 +1,145 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/environment"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// gpuDependencies are the libraries in the dependency files of each language that use CUDA
+	gpuDependencies = map[string][]string{
+		"requirements.txt": {"tensorflow-gpu", "torch", "jax[cuda", "cupy", "nvidia-cuda", "nvidia-cudnn", "pycuda", "onnxruntime-gpu", "tensorrt"},
+		"Pipfile":          {"tensorflow-gpu", "torch", "jax[cuda", "cupy", "nvidia-cuda", "nvidia-cudnn", "pycuda", "onnxruntime-gpu", "tensorrt"},
+		"pyproject.toml":   {"tensorflow-gpu", "torch", "jax[cuda", "cupy", "nvidia-cuda", "nvidia-cudnn", "pycuda", "onnxruntime-gpu", "tensorrt"},
+		"environment.yml":  {"cudatoolkit", "pytorch-cuda", "tensorflow-gpu", "cupy"},
+		"package.json":     {`"@tensorflow/tfjs-node-gpu"`, `"onnxruntime-node-gpu"`},
+		"pom.xml":          {"nd4j-cuda", "<artifactId>jcuda", "tensorflow-core-api</artifactId>", "onnxruntime_gpu"},
+		"build.gradle":     {"nd4j-cuda", "jcuda", "onnxruntime_gpu"},
+		"go.mod":           {"github.com/NVIDIA/go-nvml", "gorgonia.org/cu"},
+		"Cargo.toml":       {"cudarc", "candle-kernels"},
+	}
+	// gpuBaseImageRegex matches the CUDA base images used in Dockerfiles, like nvidia/cuda, pytorch/pytorch:*-cuda* and tensorflow/tensorflow:*-gpu
+	gpuBaseImageRegex = regexp.MustCompile(`(?im)^\s*FROM\s+(?:--platform=\S+\s+)?(\S*(?:nvidia/|cuda|-gpu)\S*)`)
+)
+
+// GPUAnalyser implements Transformer interface
+type GPUAnalyser struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// Init Initializes the transformer
+func (t *GPUAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *GPUAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *GPUAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform finds the services that use GPUs, using the CUDA libraries in their dependencies and the CUDA base images of their Dockerfiles.
+// The devices mounted in compose files are found by the compose transformers.
+// The Kubernetes transformer requests the GPUs for the services and schedules them on the GPU nodes.
+func (t *GPUAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		for sn, s := range ir.Services {
+			if hasDeviceRequirement(s.DeviceRequirements, irtypes.NvidiaGPUResource) {
+				continue
+			}
+			if source := findGPUUsage(ir, s); source != "" {
+				logrus.Debugf("the service %s uses GPUs (%s)", sn, source)
+				s.DeviceRequirements = append(s.DeviceRequirements, irtypes.DeviceRequirement{Resource: irtypes.NvidiaGPUResource, Count: 1, Source: source})
+				ir.Services[sn] = s
+			}
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return nil, artifactsCreated, nil
+}
+
+// findGPUUsage returns the dependency or the base image that shows that the service uses GPUs, or an empty string
+func findGPUUsage(ir irtypes.IR, service irtypes.Service) string {
+	fileNames := []string{}
+	for fileName := range gpuDependencies {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, container := range service.Containers {
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok {
+			continue
+		}
+		for _, dockerfilePath := range image.Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue] {
+			contents, err := os.ReadFile(dockerfilePath)
+			if err != nil {
+				continue
+			}
+			if matches := gpuBaseImageRegex.FindStringSubmatch(string(contents)); matches != nil {
+				return "base image " + matches[1]
+			}
+		}
+		if image.Build.ContextPath == "" {
+			continue
+		}
+		for _, fileName := range fileNames {
+			contents, err := os.ReadFile(filepath.Join(image.Build.ContextPath, fileName))
+			if err != nil {
+				continue
+			}
+			for _, dependency := range gpuDependencies[fileName] {
+				if strings.Contains(strings.ToLower(string(contents)), strings.ToLower(dependency)) {
+					return fileName + " " + strings.Trim(dependency, `"`)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func hasDeviceRequirement(deviceRequirements []irtypes.DeviceRequirement, resource string) bool {
+	for _, deviceRequirement := range deviceRequirements {
+		if deviceRequirement.Resource == resource {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGPUAnalyserTransform(t *testing.T) {
+	sourceDir := t.TempDir()
+	files := map[string]string{
+		"trainer/requirements.txt": "numpy\nTorch==1.12.0\n",
+		"trainer/Dockerfile":       "FROM python:3.10\n",
+		"inference/Dockerfile":     "ARG BASE=runtime\nFROM --platform=linux/amd64 nvidia/cuda:11.7.0-runtime-ubuntu22.04 AS base\n",
+		"embeddings/package.json":  `{"dependencies": {"@tensorflow/tfjs-node-gpu": "^3.0.0"}}`,
+		"web/requirements.txt":     "flask\n",
+		"web/Dockerfile":           "FROM python:3.10-slim\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(sourceDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	ir := irtypes.NewIR()
+	for _, sn := range []string{"trainer", "inference", "embeddings", "web", "renderer"} {
+		contextPath := filepath.Join(sourceDir, sn)
+		ir.ContainerImages[sn+":latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{
+			ContextPath: contextPath,
+			Artifacts:   map[irtypes.ContainerBuildArtifactTypeValue][]string{irtypes.DockerfileContainerBuildArtifactTypeValue: {filepath.Join(contextPath, "Dockerfile")}},
+		}}
+		s := irtypes.NewServiceWithName(sn)
+		s.Containers = []core.Container{{Name: sn, Image: sn + ":latest"}}
+		ir.Services[sn] = s
+	}
+	renderer := ir.Services["renderer"]
+	renderer.DeviceRequirements = []irtypes.DeviceRequirement{{Resource: irtypes.NvidiaGPUResource, Count: 2, Source: "devices /dev/nvidia0, /dev/nvidia1"}}
+	ir.Services["renderer"] = renderer
+
+	gpuAnalyser := &GPUAnalyser{}
+	if err := gpuAnalyser.Init(transformertypes.Transformer{}, nil); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	artifact := transformertypes.Artifact{Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}}
+	_, createdArtifacts, err := gpuAnalyser.Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the IR. Error: %q", err)
+	}
+	if len(createdArtifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", createdArtifacts)
+	}
+	ir = createdArtifacts[0].Configs[irtypes.IRConfigType].(irtypes.IR)
+	expected := map[string][]irtypes.DeviceRequirement{
+		"trainer":    {{Resource: irtypes.NvidiaGPUResource, Count: 1, Source: "requirements.txt torch"}},
+		"inference":  {{Resource: irtypes.NvidiaGPUResource, Count: 1, Source: "base image nvidia/cuda:11.7.0-runtime-ubuntu22.04"}},
+		"embeddings": {{Resource: irtypes.NvidiaGPUResource, Count: 1, Source: "package.json @tensorflow/tfjs-node-gpu"}},
+		// the devices found in the compose file are kept
+		"renderer": {{Resource: irtypes.NvidiaGPUResource, Count: 2, Source: "devices /dev/nvidia0, /dev/nvidia1"}},
+	}
+	actual := map[string][]irtypes.DeviceRequirement{}
+	for sn, s := range ir.Services {
+		if len(s.DeviceRequirements) > 0 {
+			actual[sn] = s.DeviceRequirements
+		}
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("wrong device requirements. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// defaultGRPCPort is the port conventionally used by gRPC servers
+	defaultGRPCPort = 50051
+	protoExt        = ".proto"
+
+	nativeGRPCProbe     = "native"
+	grpcHealthProbe     = "grpc_health_probe"
+	noGRPCProbe         = "none"
+	grpcHealthProbePath = "/bin/grpc_health_probe"
+)
+
+var (
+	// grpcDependencies maps the dependency files of each language to the dependencies that indicate a gRPC server
+	grpcDependencies = map[string][]string{
+		"go.mod":           {"google.golang.org/grpc"},
+		"package.json":     {"@grpc/grpc-js", `"grpc"`},
+		"requirements.txt": {"grpcio"},
+		"Pipfile":          {"grpcio"},
+		"pyproject.toml":   {"grpcio"},
+		"pom.xml":          {"io.grpc"},
+		"build.gradle":     {"io.grpc"},
+		"build.gradle.kts": {"io.grpc"},
+		"Gemfile":          {"grpc"},
+		"Cargo.toml":       {"tonic"},
+	}
+)
+
+// GRPCAnalyser implements Transformer interface
+type GRPCAnalyser struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// Init Initializes the transformer
+func (t *GRPCAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *GRPCAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *GRPCAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform marks the ports of the gRPC services as HTTP/2 and adds gRPC health probes to them
+func (t *GRPCAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		for sn, s := range ir.Services {
+			idx := getGRPCPortForwarding(s, isGRPCService(ir, s))
+			if idx < 0 {
+				continue
+			}
+			logrus.Debugf("the service %s is a gRPC service", sn)
+			s.ServiceToPodPortForwardings[idx].AppProtocol = irtypes.H2CAppProtocol
+			qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`)
+			s.Headless = qaengine.FetchBoolAnswer(
+				common.JoinQASubKeys(qaKeyPrefix, common.ConfigGRPCHeadlessKeySuffix),
+				fmt.Sprintf("Should the gRPC service %s be headless?", sn),
+				[]string{"gRPC keeps connections open, so a headless service is needed to load balance on the client side"},
+				false,
+			)
+			probe := qaengine.FetchSelectAnswer(
+				common.JoinQASubKeys(qaKeyPrefix, common.ConfigGRPCProbeKeySuffix),
+				fmt.Sprintf("Select the health probes for the gRPC service %s :", sn),
+				[]string{"The native gRPC probes need Kubernetes 1.24 or later", "The " + grpcHealthProbe + " binary needs to be added to the image at " + grpcHealthProbePath},
+				nativeGRPCProbe,
+				[]string{nativeGRPCProbe, grpcHealthProbe, noGRPCProbe},
+			)
+			if probe != noGRPCProbe {
+				setGRPCProbes(&s, s.ServiceToPodPortForwardings[idx].PodPort.Number, probe)
+			}
+			ir.Services[sn] = s
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return nil, artifactsCreated, nil
+}
+
+// isGRPCService checks the build contexts of the images of the service for proto files and gRPC dependencies
+func isGRPCService(ir irtypes.IR, service irtypes.Service) bool {
+	for _, container := range service.Containers {
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok || image.Build.ContextPath == "" {
+			continue
+		}
+		if protoPaths, err := common.GetFilesByExt(image.Build.ContextPath, []string{protoExt}); err == nil && len(protoPaths) > 0 {
+			return true
+		}
+		for fileName, dependencies := range grpcDependencies {
+			contents, err := os.ReadFile(filepath.Join(image.Build.ContextPath, fileName))
+			if err != nil {
+				continue
+			}
+			for _, dependency := range dependencies {
+				if strings.Contains(string(contents), dependency) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// getGRPCPortForwarding returns the index of the port forwarding that serves gRPC, or -1 if there is none.
+// Ports named grpc and the conventional gRPC port are used even if the service was not detected as a gRPC service.
+func getGRPCPortForwarding(service irtypes.Service, detected bool) int {
+	for i, forwarding := range service.ServiceToPodPortForwardings {
+		if strings.Contains(strings.ToLower(forwarding.ServicePort.Name), "grpc") || forwarding.PodPort.Number == defaultGRPCPort {
+			return i
+		}
+	}
+	if detected && len(service.ServiceToPodPortForwardings) > 0 {
+		return 0
+	}
+	return -1
+}
+
+// setGRPCProbes adds gRPC liveness and readiness probes to the containers that expose the port and do not have probes
+func setGRPCProbes(service *irtypes.Service, port int32, probe string) {
+	handler := core.ProbeHandler{GRPC: &core.GRPCAction{Port: port}}
+	if probe == grpcHealthProbe {
+		handler = core.ProbeHandler{Exec: &core.ExecAction{Command: []string{grpcHealthProbePath, fmt.Sprintf("-addr=:%d", port)}}}
+	}
+	for i, container := range service.Containers {
+		exposed := len(service.Containers) == 1
+		for _, containerPort := range container.Ports {
+			if containerPort.ContainerPort == port {
+				exposed = true
+			}
+		}
+		if !exposed {
+			continue
+		}
+		if container.LivenessProbe == nil {
+			service.Containers[i].LivenessProbe = &core.Probe{ProbeHandler: handler, InitialDelaySeconds: 10}
+		}
+		if container.ReadinessProbe == nil {
+			service.Containers[i].ReadinessProbe = &core.Probe{ProbeHandler: handler, InitialDelaySeconds: 5}
+		}
+	}
+}
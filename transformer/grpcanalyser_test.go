@@ -0,0 +1,102 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestGRPCAnalyserTransform(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.services."greeter".grpcheadless=true`,
+		`move2kube.services."payments".grpcprobe="grpc_health_probe"`,
+	}, nil, nil, false, false)
+
+	greeterDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(greeterDir, "go.mod"), []byte("module greeter\n\nrequire google.golang.org/grpc v1.45.0\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the go.mod . Error: %q", err)
+	}
+	ir := irtypes.NewIR()
+	ir.ContainerImages["greeter:latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{ContextPath: greeterDir}}
+	greeter := irtypes.NewServiceWithName("greeter")
+	greeter.Containers = []core.Container{{Name: "greeter", Image: "greeter:latest", Ports: []core.ContainerPort{{ContainerPort: 8080}}}}
+	greeter.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 8080}},
+	}
+	payments := irtypes.NewServiceWithName("payments")
+	payments.Containers = []core.Container{
+		{Name: "payments", Image: "payments:latest", Ports: []core.ContainerPort{{ContainerPort: 9000}}},
+		{Name: "sidecar", Image: "envoy:latest"},
+	}
+	payments.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Name: "http", Number: 80}, PodPort: networking.ServiceBackendPort{Number: 8080}},
+		{ServicePort: networking.ServiceBackendPort{Name: "grpc-api", Number: 9000}, PodPort: networking.ServiceBackendPort{Number: 9000}},
+	}
+	web := irtypes.NewServiceWithName("web")
+	web.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Number: 80}, PodPort: networking.ServiceBackendPort{Number: 8080}},
+	}
+	ir.Services = map[string]irtypes.Service{"greeter": greeter, "payments": payments, "web": web}
+	artifact := transformertypes.Artifact{Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}}
+
+	_, createdArtifacts, err := (&GRPCAnalyser{}).Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the IR. Error: %q", err)
+	}
+	if len(createdArtifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", createdArtifacts)
+	}
+	ir = createdArtifacts[0].Configs[irtypes.IRConfigType].(irtypes.IR)
+
+	greeter = ir.Services["greeter"]
+	if !greeter.Headless || greeter.ServiceToPodPortForwardings[0].AppProtocol != irtypes.H2CAppProtocol {
+		t.Fatalf("expected the detected gRPC service to be a headless HTTP/2 service. Actual: %+v", greeter)
+	}
+	wantNativeProbe := &core.Probe{ProbeHandler: core.ProbeHandler{GRPC: &core.GRPCAction{Port: 8080}}, InitialDelaySeconds: 10}
+	if diff := cmp.Diff(wantNativeProbe, greeter.Containers[0].LivenessProbe); diff != "" {
+		t.Fatalf("the liveness probe of the service greeter is incorrect. Difference:\n%s", diff)
+	}
+
+	payments = ir.Services["payments"]
+	if payments.Headless || payments.ServiceToPodPortForwardings[0].AppProtocol != "" || payments.ServiceToPodPortForwardings[1].AppProtocol != irtypes.H2CAppProtocol {
+		t.Fatalf("expected only the port named grpc to be an HTTP/2 port. Actual: %+v", payments.ServiceToPodPortForwardings)
+	}
+	wantExecProbe := &core.Probe{ProbeHandler: core.ProbeHandler{Exec: &core.ExecAction{Command: []string{grpcHealthProbePath, "-addr=:9000"}}}, InitialDelaySeconds: 5}
+	if diff := cmp.Diff(wantExecProbe, payments.Containers[0].ReadinessProbe); diff != "" {
+		t.Fatalf("the readiness probe of the service payments is incorrect. Difference:\n%s", diff)
+	}
+	if payments.Containers[1].LivenessProbe != nil || payments.Containers[1].ReadinessProbe != nil {
+		t.Fatalf("expected the container that does not expose the gRPC port to not get probes. Actual: %+v", payments.Containers[1])
+	}
+
+	web = ir.Services["web"]
+	if web.ServiceToPodPortForwardings[0].AppProtocol != "" || web.Headless {
+		t.Fatalf("expected the service web to not be a gRPC service. Actual: %+v", web)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PreTransformHookEvent is the event of the hooks run before the transform
+	PreTransformHookEvent = "preTransform"
+	// PostTransformHookEvent is the event of the hooks run after the transform
+	PostTransformHookEvent = "postTransform"
+	// PreTransformerHookEvent is the event of the hooks run before a transformer
+	PreTransformerHookEvent = "preTransformer"
+	// PostTransformerHookEvent is the event of the hooks run after a transformer
+	PostTransformerHookEvent = "postTransformer"
+	// hookTimeout is the time given to a hook to finish
+	hookTimeout = 5 * time.Minute
+	// hookEventEnvVar is the environment variable that has the event of the hook for commands
+	hookEventEnvVar = "M2K_HOOK_EVENT"
+)
+
+var (
+	transformHooks transformertypes.HooksSpec
+	hookSourcePath string
+	hookOutputPath string
+)
+
+// HookPayload is the metadata of the run that is given to the hooks
+type HookPayload struct {
+	Event        string `json:"event"`
+	RunID        string `json:"runID,omitempty"`
+	ProjectName  string `json:"projectName"`
+	SourcePath   string `json:"sourcePath"`
+	OutputPath   string `json:"outputPath"`
+	Transformer  string `json:"transformer,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Error        string `json:"error,omitempty"`
+	Artifacts    int    `json:"artifacts,omitempty"`
+	PathMappings int    `json:"pathMappings,omitempty"`
+	Time         string `json:"time"`
+}
+
+// loadHooks finds the hooks in the assets. Hooks in different files are run in the order of the files.
+func loadHooks(assetsPath string) transformertypes.HooksSpec {
+	spec := transformertypes.HooksSpec{Transformers: map[string]transformertypes.TransformerHooks{}}
+	filePaths, err := common.GetFilesByExt(assetsPath, []string{".yml", ".yaml"})
+	if err != nil {
+		logrus.Errorf("failed to look for yaml files in the directory %s . Error: %q", assetsPath, err)
+		return spec
+	}
+	for _, filePath := range filePaths {
+		h := transformertypes.NewHooks()
+		if err := common.ReadMove2KubeYaml(filePath, &h); err != nil || h.Kind != transformertypes.HooksKind {
+			continue
+		}
+		logrus.Debugf("found the hooks %s at path %s", h.Name, filePath)
+		spec.PreTransform = append(spec.PreTransform, h.Spec.PreTransform...)
+		spec.PostTransform = append(spec.PostTransform, h.Spec.PostTransform...)
+		for transformerName, transformerHooks := range h.Spec.Transformers {
+			existing := spec.Transformers[transformerName]
+			existing.Pre = append(existing.Pre, transformerHooks.Pre...)
+			existing.Post = append(existing.Post, transformerHooks.Post...)
+			spec.Transformers[transformerName] = existing
+		}
+	}
+	return spec
+}
+
+// RunPreTransformHooks loads the hooks and runs the ones that are run before the transform.
+// An error is returned if a hook that has failOnError set fails.
+func RunPreTransformHooks(source, output string) error {
+	transformHooks = loadHooks(common.AssetsPath)
+	hookSourcePath, hookOutputPath = source, output
+	return runHooks(transformHooks.PreTransform, newHookPayload(PreTransformHookEvent, ""))
+}
+
+// RunPostTransformHooks runs the hooks that are run after the transform with the result of the transform
+func RunPostTransformHooks(transformErr error) error {
+	payload := newHookPayload(PostTransformHookEvent, "")
+	setHookPayloadStatus(&payload, transformErr)
+	return runHooks(transformHooks.PostTransform, payload)
+}
+
+// runPreTransformerHooks runs the hooks that are run before the transformer
+func runPreTransformerHooks(transformerName string, artifacts int) error {
+	payload := newHookPayload(PreTransformerHookEvent, transformerName)
+	payload.Artifacts = artifacts
+	return runHooks(transformHooks.Transformers[transformerName].Pre, payload)
+}
+
+// runPostTransformerHooks runs the hooks that are run after the transformer with the result of the transformer
+func runPostTransformerHooks(transformerName string, artifacts, pathMappings int, transformErr error) {
+	payload := newHookPayload(PostTransformerHookEvent, transformerName)
+	payload.Artifacts = artifacts
+	payload.PathMappings = pathMappings
+	setHookPayloadStatus(&payload, transformErr)
+	if err := runHooks(transformHooks.Transformers[transformerName].Post, payload); err != nil {
+		logrus.Errorf("the hooks run after the transformer %s failed. Error: %q", transformerName, err)
+	}
+}
+
+func newHookPayload(event, transformerName string) HookPayload {
+	return HookPayload{
+		Event:       event,
+		RunID:       common.RunID,
+		ProjectName: common.ProjectName,
+		SourcePath:  hookSourcePath,
+		OutputPath:  hookOutputPath,
+		Transformer: transformerName,
+		Time:        time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func setHookPayloadStatus(payload *HookPayload, err error) {
+	payload.Status = "succeeded"
+	if err != nil {
+		payload.Status = "failed"
+		payload.Error = err.Error()
+	}
+}
+
+// runHooks runs the hooks in order. The first error of a hook that has failOnError set is returned, the other errors are logged.
+func runHooks(hooksToRun []transformertypes.Hook, payload HookPayload) error {
+	if len(hooksToRun) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the payload of the hooks %+v to json. Error: %q", payload, err)
+	}
+	for i, hook := range hooksToRun {
+		name := hook.Name
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", payload.Event, i)
+		}
+		logrus.Debugf("running the hook %s", name)
+		var hookErr error
+		if len(hook.Command) > 0 {
+			hookErr = runCommandHook(hook, payload.Event, data)
+		} else if hook.URL != "" {
+			hookErr = runWebhook(hook, data)
+		} else {
+			hookErr = fmt.Errorf("the hook has neither a command nor a url")
+		}
+		if hookErr == nil {
+			continue
+		}
+		if hook.FailOnError {
+			return fmt.Errorf("the hook %s failed. Error: %q", name, hookErr)
+		}
+		logrus.Errorf("the hook %s failed. Error: %q", name, hookErr)
+	}
+	return nil
+}
+
+// runCommandHook runs the command with the payload on stdin
+func runCommandHook(hook transformertypes.Hook, event string, data []byte) error {
+	if common.DisableLocalExecution {
+		logrus.Warnf("Skipping the hook command %+v since local execution is disabled", hook.Command)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), hookEventEnvVar+"="+event)
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		logrus.Debugf("output of the hook command %+v :\n%s", hook.Command, output)
+	}
+	if err != nil {
+		return fmt.Errorf("the command %+v failed with the output %s . Error: %q", hook.Command, output, err)
+	}
+	return nil
+}
+
+// runWebhook posts the payload to the url of the hook
+func runWebhook(hook transformertypes.Hook, data []byte) error {
+	if common.Offline {
+		logrus.Warnf("Skipping the webhook %s since the network must not be accessed in offline mode", hook.URL)
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create the request to the webhook %s . Error: %q", hook.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range hook.Headers {
+		req.Header.Set(key, common.ExpandEnvVars(value))
+	}
+	resp, err := (&http.Client{Timeout: hookTimeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call the webhook %s . Error: %q", hook.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the webhook %s returned the status %s", hook.URL, resp.Status)
+	}
+	return nil
+}
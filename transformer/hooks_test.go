@@ -0,0 +1,262 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+// setupHooksTest restores the globals that the hooks use when the test ends
+func setupHooksTest(t *testing.T) {
+	oldAssetsPath, oldRunID, oldProjectName := common.AssetsPath, common.RunID, common.ProjectName
+	oldOffline, oldDisableLocalExecution := common.Offline, common.DisableLocalExecution
+	t.Cleanup(func() {
+		common.AssetsPath, common.RunID, common.ProjectName = oldAssetsPath, oldRunID, oldProjectName
+		common.Offline, common.DisableLocalExecution = oldOffline, oldDisableLocalExecution
+		transformHooks = transformertypes.HooksSpec{}
+		hookSourcePath, hookOutputPath = "", ""
+	})
+}
+
+func writeHooksFile(t *testing.T, dir, name, contents string) {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the hooks file %s . Error: %q", name, err)
+	}
+}
+
+func TestLoadHooks(t *testing.T) {
+	assetsPath := t.TempDir()
+	writeHooksFile(t, assetsPath, "a.yaml", `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Hooks
+metadata:
+  name: a
+spec:
+  preTransform:
+    - name: notify
+      url: http://example.com/start
+  transformers:
+    Kubernetes:
+      post:
+        - name: lint
+          command: ["kube-linter", "lint", "."]
+`)
+	writeHooksFile(t, assetsPath, "b.yaml", `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Hooks
+metadata:
+  name: b
+spec:
+  preTransform:
+    - name: check
+      command: ["true"]
+      failOnError: true
+  postTransform:
+    - name: done
+      url: http://example.com/done
+  transformers:
+    Kubernetes:
+      pre:
+        - name: prepare
+          command: ["true"]
+`)
+	writeHooksFile(t, assetsPath, "other.yaml", `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Sidecar
+metadata:
+  name: other
+spec:
+  preTransform:
+    - name: ignored
+      command: ["false"]
+`)
+	expected := transformertypes.HooksSpec{
+		PreTransform: []transformertypes.Hook{
+			{Name: "notify", URL: "http://example.com/start"},
+			{Name: "check", Command: []string{"true"}, FailOnError: true},
+		},
+		PostTransform: []transformertypes.Hook{{Name: "done", URL: "http://example.com/done"}},
+		Transformers: map[string]transformertypes.TransformerHooks{
+			"Kubernetes": {
+				Pre:  []transformertypes.Hook{{Name: "prepare", Command: []string{"true"}}},
+				Post: []transformertypes.Hook{{Name: "lint", Command: []string{"kube-linter", "lint", "."}}},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, loadHooks(assetsPath)); diff != "" {
+		t.Fatalf("wrong hooks loaded. Difference:\n%s", diff)
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	setupHooksTest(t)
+	payload := HookPayload{Event: PreTransformHookEvent, ProjectName: "shop", Time: "2022-06-01T00:00:00Z"}
+	expectedData, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal the payload. Error: %q", err)
+	}
+
+	t.Run("give the payload to a command on stdin", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "payload.json")
+		hook := transformertypes.Hook{Command: []string{"sh", "-c", `cat > "$1" && echo "$M2K_HOOK_EVENT" > "$1.event"`, "hook", outputPath}}
+		if err := runHooks([]transformertypes.Hook{hook}, payload); err != nil {
+			t.Fatalf("failed to run the hooks. Error: %q", err)
+		}
+		data, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("failed to read the payload written by the command. Error: %q", err)
+		}
+		if diff := cmp.Diff(string(expectedData), string(data)); diff != "" {
+			t.Fatalf("wrong payload. Difference:\n%s", diff)
+		}
+		event, err := os.ReadFile(outputPath + ".event")
+		if err != nil {
+			t.Fatalf("failed to read the event written by the command. Error: %q", err)
+		}
+		if strings.TrimSpace(string(event)) != PreTransformHookEvent {
+			t.Fatalf("wrong event in the environment of the command. Actual: %s", event)
+		}
+	})
+
+	t.Run("post the payload to a webhook with the expanded headers", func(t *testing.T) {
+		t.Setenv("HOOK_TOKEN", "secret")
+		var body []byte
+		var authorization, contentType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			authorization, contentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		}))
+		defer server.Close()
+		hook := transformertypes.Hook{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer ${HOOK_TOKEN}"}}
+		if err := runHooks([]transformertypes.Hook{hook}, payload); err != nil {
+			t.Fatalf("failed to run the hooks. Error: %q", err)
+		}
+		if string(body) != string(expectedData) || authorization != "Bearer secret" || contentType != "application/json" {
+			t.Fatalf("wrong request to the webhook. Body: %s Authorization: %s Content-Type: %s", body, authorization, contentType)
+		}
+	})
+
+	t.Run("only fail on the errors of the hooks that have fail on error set", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		markerPath := filepath.Join(t.TempDir(), "marker")
+		hooks := []transformertypes.Hook{
+			{Name: "ignored", Command: []string{"false"}},
+			{},
+			{Name: "webhook", URL: server.URL},
+			{Name: "marker", Command: []string{"touch", markerPath}},
+		}
+		if err := runHooks(hooks, payload); err != nil {
+			t.Fatalf("expected the errors of the hooks to be ignored. Error: %q", err)
+		}
+		if _, err := os.Stat(markerPath); err != nil {
+			t.Fatalf("expected the hooks after the failed hooks to run. Error: %q", err)
+		}
+		hooks[2].FailOnError = true
+		hooks[3].Command = []string{"touch", markerPath + "2"}
+		err := runHooks(hooks, payload)
+		if err == nil || !strings.Contains(err.Error(), "webhook") {
+			t.Fatalf("expected the failure of the webhook to be returned. Actual: %v", err)
+		}
+		if _, err := os.Stat(markerPath + "2"); err == nil {
+			t.Fatalf("expected the hooks after the failed hook to be skipped")
+		}
+	})
+
+	t.Run("skip commands and webhooks when they are disabled", func(t *testing.T) {
+		common.DisableLocalExecution, common.Offline = true, true
+		defer func() { common.DisableLocalExecution, common.Offline = false, false }()
+		hooks := []transformertypes.Hook{
+			{Command: []string{"false"}, FailOnError: true},
+			{URL: "http://127.0.0.1:1/unreachable", FailOnError: true},
+		}
+		if err := runHooks(hooks, payload); err != nil {
+			t.Fatalf("expected the hooks to be skipped. Error: %q", err)
+		}
+	})
+}
+
+func TestTransformHooks(t *testing.T) {
+	setupHooksTest(t)
+	payloads := []HookPayload{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := HookPayload{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		payload.Time = ""
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+	common.AssetsPath = t.TempDir()
+	common.RunID, common.ProjectName = "run-1", "shop"
+	writeHooksFile(t, common.AssetsPath, "hooks.yaml", fmt.Sprintf(`apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Hooks
+metadata:
+  name: notify
+spec:
+  preTransform:
+    - url: %[1]s
+  postTransform:
+    - url: %[1]s
+  transformers:
+    Kubernetes:
+      pre:
+        - url: %[1]s
+      post:
+        - url: %[1]s
+    Blocked:
+      pre:
+        - command: ["false"]
+          failOnError: true
+`, server.URL))
+
+	if err := RunPreTransformHooks("src", "out"); err != nil {
+		t.Fatalf("failed to run the hooks before the transform. Error: %q", err)
+	}
+	if err := runPreTransformerHooks("Kubernetes", 2); err != nil {
+		t.Fatalf("failed to run the hooks before the transformer. Error: %q", err)
+	}
+	runPostTransformerHooks("Kubernetes", 3, 5, nil)
+	if err := runPreTransformerHooks("Blocked", 1); err == nil {
+		t.Fatalf("expected the failing hook to skip the transformer")
+	}
+	if err := RunPostTransformHooks(fmt.Errorf("the transform failed")); err != nil {
+		t.Fatalf("failed to run the hooks after the transform. Error: %q", err)
+	}
+	expected := []HookPayload{
+		{Event: PreTransformHookEvent, RunID: "run-1", ProjectName: "shop", SourcePath: "src", OutputPath: "out"},
+		{Event: PreTransformerHookEvent, RunID: "run-1", ProjectName: "shop", SourcePath: "src", OutputPath: "out", Transformer: "Kubernetes", Artifacts: 2},
+		{Event: PostTransformerHookEvent, RunID: "run-1", ProjectName: "shop", SourcePath: "src", OutputPath: "out", Transformer: "Kubernetes", Status: "succeeded", Artifacts: 3, PathMappings: 5},
+		{Event: PostTransformHookEvent, RunID: "run-1", ProjectName: "shop", SourcePath: "src", OutputPath: "out", Status: "failed", Error: "the transform failed"},
+	}
+	if diff := cmp.Diff(expected, payloads); diff != "" {
+		t.Fatalf("wrong payloads posted to the webhook. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,301 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	terraformIaCTool  = "terraform"
+	crossplaneIaCTool = "crossplane"
+
+	databaseCloudResource = "database"
+	bucketCloudResource   = "bucket"
+	queueCloudResource    = "queue"
+
+	defaultIaCDir                = common.DeployDir + string(os.PathSeparator) + "iac"
+	defaultIaCSecretStore        = "default"
+	defaultCrossplaneClaimGroup  = "platform.example.org/v1alpha1"
+	connectionSecretNameSuffix   = "-connection"
+	externalSecretsTemplateName  = "externalsecret.yaml"
+	crossplaneClaimFileSuffix    = "-claim.yaml"
+	externalSecretFileNameSuffix = "-externalsecret.yaml"
+)
+
+var (
+	// cloudResourcePorts maps the well known ports of the dependencies to the type of cloud resource and the engine
+	cloudResourcePorts = map[int32][2]string{
+		5432:  {databaseCloudResource, "postgres"},
+		3306:  {databaseCloudResource, "mysql"},
+		1433:  {databaseCloudResource, "sqlserver"},
+		1521:  {databaseCloudResource, "oracle"},
+		50000: {databaseCloudResource, "db2"},
+		27017: {databaseCloudResource, "mongodb"},
+		6379:  {databaseCloudResource, "redis"},
+		5672:  {queueCloudResource, "rabbitmq"},
+		5671:  {queueCloudResource, "rabbitmq"},
+		9092:  {queueCloudResource, "kafka"},
+		1414:  {queueCloudResource, "ibmmq"},
+		61616: {queueCloudResource, "activemq"},
+	}
+	// cloudResourceEndpointKinds maps the kinds of the external endpoints found in the sources to the type of cloud resource
+	cloudResourceEndpointKinds = map[string]string{
+		jmsEndpointKind:   queueCloudResource,
+		amqpEndpointKind:  queueCloudResource,
+		ibmMQEndpointKind: queueCloudResource,
+	}
+	bucketHostKeywords = []string{"s3", "bucket", "blob", "storage"}
+)
+
+// IaCGenerator implements Transformer interface
+type IaCGenerator struct {
+	Config    transformertypes.Transformer
+	Env       *environment.Environment
+	IaCConfig *IaCYamlConfig
+}
+
+// IaCYamlConfig stores the yaml configuration for the infrastructure as code transformer
+type IaCYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+	// CrossplaneClaimAPIVersion is the api version of the claims, which depends on the composite resource definitions of the platform
+	CrossplaneClaimAPIVersion string `yaml:"crossplaneClaimAPIVersion" json:"crossplaneClaimAPIVersion"`
+}
+
+// IaCTemplateConfig is the config used by the templates of the Terraform modules, the Crossplane claims and the external secrets
+type IaCTemplateConfig struct {
+	Name            string
+	ProjectName     string
+	Type            string
+	Engine          string
+	Host            string
+	Port            int32
+	Clients         []string
+	SecretName      string
+	SecretStore     string
+	RemoteKey       string
+	ClaimAPIVersion string
+}
+
+// Init Initializes the transformer
+func (t *IaCGenerator) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.IaCConfig = &IaCYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.IaCConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.IaCConfig, err)
+		return err
+	}
+	if t.IaCConfig.OutputPath == "" {
+		t.IaCConfig.OutputPath = defaultIaCDir
+	}
+	if t.IaCConfig.CrossplaneClaimAPIVersion == "" {
+		t.IaCConfig.CrossplaneClaimAPIVersion = defaultCrossplaneClaimGroup
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *IaCGenerator) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *IaCGenerator) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform generates Terraform modules or Crossplane claims for the external services that are chosen to be provisioned in the cloud.
+// The connection details of the provisioned resources are fetched from a secret store by an external secret,
+// and the client services get them as environment variables.
+func (t *IaCGenerator) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		externalServiceNames := []string{}
+		for name := range ir.ExternalServices {
+			externalServiceNames = append(externalServiceNames, name)
+		}
+		sort.Strings(externalServiceNames)
+		selected := []string{}
+		if len(externalServiceNames) > 0 {
+			selected = qaengine.FetchMultiSelectAnswer(
+				common.ConfigIaCProvisionKey,
+				"Select the external services that should be provisioned in the cloud:",
+				[]string{"Infrastructure as code is generated for them, and the services get their connection details from a secret store"},
+				[]string{},
+				externalServiceNames,
+			)
+		}
+		if len(selected) > 0 {
+			tool := qaengine.FetchSelectAnswer(
+				common.ConfigIaCToolKey,
+				"Select the infrastructure as code tool to provision the external services with:",
+				[]string{terraformIaCTool + " : a Terraform module for each service", crossplaneIaCTool + " : a Crossplane claim for each service"},
+				terraformIaCTool,
+				[]string{terraformIaCTool, crossplaneIaCTool},
+			)
+			secretStore := qaengine.FetchStringAnswer(
+				common.ConfigIaCSecretStoreKey,
+				"Enter the name of the secret store that has the connection details of the provisioned services:",
+				[]string{"The External Secrets Operator fetches the connection details from this secret store"},
+				defaultIaCSecretStore,
+			)
+			for _, name := range selected {
+				externalService, ok := ir.ExternalServices[name]
+				if !ok {
+					continue
+				}
+				config := t.getIaCTemplateConfig(ir, externalService, secretStore)
+				newPathMappings, err := t.getIaCPathMappings(tool, config)
+				if err != nil {
+					logrus.Errorf("failed to generate the infrastructure as code for the external service %s . Error: %q", name, err)
+					continue
+				}
+				pathMappings = append(pathMappings, newPathMappings...)
+				addConnectionSecretToClients(&ir, externalService, config.SecretName)
+			}
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return pathMappings, artifactsCreated, nil
+}
+
+// getIaCTemplateConfig asks for the type of cloud resource the external service is provisioned as, using the detected type as the default
+func (t *IaCGenerator) getIaCTemplateConfig(ir irtypes.IR, externalService irtypes.ExternalService, secretStore string) IaCTemplateConfig {
+	resourceType, engine := getCloudResourceType(ir, externalService)
+	resourceType = qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(common.ConfigIaCResourcesKey, `"`+externalService.Name+`"`, common.ConfigIaCResourceTypeKeySegment),
+		fmt.Sprintf("Select the type of cloud resource the external service %s should be provisioned as:", externalService.Name),
+		nil,
+		resourceType,
+		[]string{databaseCloudResource, bucketCloudResource, queueCloudResource},
+	)
+	config := IaCTemplateConfig{
+		Name:            externalService.Name,
+		ProjectName:     t.Env.GetProjectName(),
+		Type:            resourceType,
+		Engine:          engine,
+		Host:            externalService.Host,
+		Clients:         externalService.Clients,
+		SecretName:      externalService.Name + connectionSecretNameSuffix,
+		SecretStore:     secretStore,
+		RemoteKey:       t.Env.GetProjectName() + "/" + externalService.Name,
+		ClaimAPIVersion: t.IaCConfig.CrossplaneClaimAPIVersion,
+	}
+	if config.Host == "" && len(externalService.IPs) > 0 {
+		config.Host = externalService.IPs[0]
+	}
+	if len(externalService.Ports) > 0 {
+		config.Port = externalService.Ports[0]
+	}
+	return config
+}
+
+// getIaCPathMappings returns the template path mappings for the Terraform module or the Crossplane claim of the external service, and for its external secret
+func (t *IaCGenerator) getIaCPathMappings(tool string, config IaCTemplateConfig) ([]transformertypes.PathMapping, error) {
+	templatesDir := filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir)
+	pathMappings := []transformertypes.PathMapping{}
+	if tool == crossplaneIaCTool {
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        filepath.Join(templatesDir, crossplaneIaCTool, config.Type+".yaml"),
+			DestPath:       filepath.Join(t.IaCConfig.OutputPath, crossplaneIaCTool, config.Name+crossplaneClaimFileSuffix),
+			TemplateConfig: config,
+		})
+	} else {
+		moduleTemplatesDir := filepath.Join(templatesDir, terraformIaCTool, config.Type)
+		if _, err := os.Stat(moduleTemplatesDir); err != nil {
+			return nil, fmt.Errorf("the templates of the Terraform module for the type %s are missing at path %s . Error: %q", config.Type, moduleTemplatesDir, err)
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        moduleTemplatesDir,
+			DestPath:       filepath.Join(t.IaCConfig.OutputPath, terraformIaCTool, config.Name),
+			TemplateConfig: config,
+		})
+	}
+	pathMappings = append(pathMappings, transformertypes.PathMapping{
+		Type:           transformertypes.TemplatePathMappingType,
+		SrcPath:        filepath.Join(templatesDir, externalSecretsTemplateName),
+		DestPath:       filepath.Join(t.IaCConfig.OutputPath, "externalsecrets", config.Name+externalSecretFileNameSuffix),
+		TemplateConfig: config,
+	})
+	return pathMappings, nil
+}
+
+// getCloudResourceType guesses the type of cloud resource and the engine of the external service from its ports,
+// the external endpoints of its clients and its host name. Unknown dependencies are assumed to be databases.
+func getCloudResourceType(ir irtypes.IR, externalService irtypes.ExternalService) (string, string) {
+	for _, port := range externalService.Ports {
+		if resource, ok := cloudResourcePorts[port]; ok {
+			return resource[0], resource[1]
+		}
+	}
+	for _, client := range externalService.Clients {
+		for _, endpoint := range ir.Services[client].ExternalEndpoints {
+			if resourceType, ok := cloudResourceEndpointKinds[endpoint.Kind]; ok && endpoint.Host == externalService.Host {
+				return resourceType, endpoint.Kind
+			}
+		}
+	}
+	host := strings.ToLower(externalService.Host)
+	for _, keyword := range bucketHostKeywords {
+		if strings.Contains(host, keyword) {
+			return bucketCloudResource, ""
+		}
+	}
+	return databaseCloudResource, ""
+}
+
+// addConnectionSecretToClients adds the connection secret to the environment of the containers of the clients of the external service.
+// The secret is optional, so the pods start before the resource is provisioned.
+func addConnectionSecretToClients(ir *irtypes.IR, externalService irtypes.ExternalService, secretName string) {
+	optional := true
+	prefix := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(externalService.Name)) + "_"
+	for _, client := range externalService.Clients {
+		service, ok := ir.Services[client]
+		if !ok {
+			continue
+		}
+		for i := range service.Containers {
+			service.Containers[i].EnvFrom = append(service.Containers[i].EnvFrom, core.EnvFromSource{
+				Prefix:    prefix,
+				SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: secretName}, Optional: &optional},
+			})
+		}
+		ir.Services[client] = service
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetCloudResourceType(t *testing.T) {
+	ir := irtypes.NewIR()
+	orders := irtypes.NewServiceWithName("orders")
+	orders.ExternalEndpoints = []irtypes.ExternalEndpoint{{Kind: jmsEndpointKind, Host: "mq.example.com"}}
+	ir.Services["orders"] = orders
+	testCases := []struct {
+		name            string
+		externalService irtypes.ExternalService
+		expectedType    string
+		expectedEngine  string
+	}{
+		{name: "a database from its port", externalService: irtypes.ExternalService{Name: "db", Host: "db.example.com", Ports: []int32{8080, 5432}}, expectedType: databaseCloudResource, expectedEngine: "postgres"},
+		{name: "a queue from its port", externalService: irtypes.ExternalService{Name: "kafka", Host: "kafka.example.com", Ports: []int32{9092}}, expectedType: queueCloudResource, expectedEngine: "kafka"},
+		{name: "a queue from the endpoints of its clients", externalService: irtypes.ExternalService{Name: "mq", Host: "mq.example.com", Clients: []string{"orders"}}, expectedType: queueCloudResource, expectedEngine: jmsEndpointKind},
+		{name: "a bucket from its host", externalService: irtypes.ExternalService{Name: "uploads", Host: "uploads.S3.amazonaws.com"}, expectedType: bucketCloudResource},
+		{name: "a database by default", externalService: irtypes.ExternalService{Name: "legacy", IPs: []string{"10.0.0.1"}}, expectedType: databaseCloudResource},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resourceType, engine := getCloudResourceType(ir, testCase.externalService)
+			if resourceType != testCase.expectedType || engine != testCase.expectedEngine {
+				t.Fatalf("wrong cloud resource type. Expected: %s %s Actual: %s %s", testCase.expectedType, testCase.expectedEngine, resourceType, engine)
+			}
+		})
+	}
+}
+
+func TestIaCGeneratorTransform(t *testing.T) {
+	transformerDir, err := filepath.Abs(filepath.Join("..", "assets", "built-in", "transformers", "iacgenerator"))
+	if err != nil {
+		t.Fatalf("failed to get the path of the transformer. Error: %q", err)
+	}
+	getIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		orders := irtypes.NewServiceWithName("orders")
+		orders.Containers = []core.Container{{Name: "orders"}, {Name: "proxy"}}
+		ir.Services["orders"] = orders
+		ir.ExternalServices = map[string]irtypes.ExternalService{
+			"orders-db": {Name: "orders-db", Host: "db.internal", Ports: []int32{5432}, Clients: []string{"orders"}},
+			"uploads":   {Name: "uploads", Host: "uploads.s3.amazonaws.com", Clients: []string{"orders"}},
+			"legacy-mq": {Name: "legacy-mq", Host: "mq.internal", Ports: []int32{1414}, Clients: []string{"orders"}},
+		}
+		return ir
+	}
+	testCases := []struct {
+		name                 string
+		answers              []string
+		expectedPathMappings []transformertypes.PathMapping
+	}{
+		{
+			name:    "generate terraform modules for the selected external services",
+			answers: []string{`move2kube.iac.provision=["orders-db","uploads"]`},
+			expectedPathMappings: []transformertypes.PathMapping{
+				{SrcPath: filepath.Join("terraform", "database"), DestPath: filepath.Join("deploy", "iac", "terraform", "orders-db")},
+				{SrcPath: "externalsecret.yaml", DestPath: filepath.Join("deploy", "iac", "externalsecrets", "orders-db-externalsecret.yaml")},
+				{SrcPath: filepath.Join("terraform", "bucket"), DestPath: filepath.Join("deploy", "iac", "terraform", "uploads")},
+				{SrcPath: "externalsecret.yaml", DestPath: filepath.Join("deploy", "iac", "externalsecrets", "uploads-externalsecret.yaml")},
+			},
+		},
+		{
+			name: "generate crossplane claims for the selected external services",
+			answers: []string{
+				`move2kube.iac.provision=["orders-db","uploads"]`,
+				`move2kube.iac.tool="crossplane"`,
+				`move2kube.iac.secretstore="vault"`,
+				`move2kube.iac.resources."uploads".type="database"`,
+			},
+			expectedPathMappings: []transformertypes.PathMapping{
+				{SrcPath: filepath.Join("crossplane", "database.yaml"), DestPath: filepath.Join("deploy", "iac", "crossplane", "orders-db-claim.yaml")},
+				{SrcPath: "externalsecret.yaml", DestPath: filepath.Join("deploy", "iac", "externalsecrets", "orders-db-externalsecret.yaml")},
+				{SrcPath: filepath.Join("crossplane", "database.yaml"), DestPath: filepath.Join("deploy", "iac", "crossplane", "uploads-claim.yaml")},
+				{SrcPath: "externalsecret.yaml", DestPath: filepath.Join("deploy", "iac", "externalsecrets", "uploads-externalsecret.yaml")},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			common.TempPath = t.TempDir()
+			qaengine.StartEngine(true, 0, true)
+			qaengine.SetupConfigFile("", testCase.answers, nil, nil, false, false)
+			iacGenerator := &IaCGenerator{}
+			env := &environment.Environment{EnvInfo: environment.EnvInfo{ProjectName: "shop", Context: transformerDir}}
+			tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{TemplatesDir: "templates"}}
+			if err := iacGenerator.Init(tc, env); err != nil {
+				t.Fatalf("failed to initialize the transformer. Error: %q", err)
+			}
+			artifact := transformertypes.Artifact{Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: getIR()}}
+			pathMappings, createdArtifacts, err := iacGenerator.Transform([]transformertypes.Artifact{artifact}, nil)
+			if err != nil {
+				t.Fatalf("failed to transform the IR. Error: %q", err)
+			}
+			actualPathMappings := []transformertypes.PathMapping{}
+			for _, pathMapping := range pathMappings {
+				if pathMapping.Type != transformertypes.TemplatePathMappingType {
+					t.Fatalf("expected a template path mapping. Actual: %+v", pathMapping)
+				}
+				if _, err := os.Stat(pathMapping.SrcPath); err != nil {
+					t.Fatalf("the template is missing. Error: %q", err)
+				}
+				srcPath, err := filepath.Rel(filepath.Join(transformerDir, "templates"), pathMapping.SrcPath)
+				if err != nil {
+					t.Fatalf("failed to make the template path relative. Error: %q", err)
+				}
+				actualPathMappings = append(actualPathMappings, transformertypes.PathMapping{SrcPath: srcPath, DestPath: pathMapping.DestPath})
+			}
+			if diff := cmp.Diff(testCase.expectedPathMappings, actualPathMappings); diff != "" {
+				t.Fatalf("wrong path mappings. Difference:\n%s", diff)
+			}
+
+			ir := createdArtifacts[0].Configs[irtypes.IRConfigType].(irtypes.IR)
+			optional := true
+			expectedEnvFrom := []core.EnvFromSource{
+				{Prefix: "ORDERS_DB_", SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "orders-db-connection"}, Optional: &optional}},
+				{Prefix: "UPLOADS_", SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "uploads-connection"}, Optional: &optional}},
+			}
+			for _, container := range ir.Services["orders"].Containers {
+				if diff := cmp.Diff(expectedEnvFrom, container.EnvFrom); diff != "" {
+					t.Fatalf("wrong connection secrets in the container %s . Difference:\n%s", container.Name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestIaCTemplates(t *testing.T) {
+	templatesDir := filepath.Join("..", "assets", "built-in", "transformers", "iacgenerator", "templates")
+	config := IaCTemplateConfig{
+		Name:            "orders-db",
+		ProjectName:     "shop",
+		Type:            databaseCloudResource,
+		Engine:          "mysql",
+		Host:            "db.internal",
+		Port:            3306,
+		Clients:         []string{"orders", "reports"},
+		SecretName:      "orders-db-connection",
+		SecretStore:     "vault",
+		RemoteKey:       "shop/orders-db",
+		ClaimAPIVersion: defaultCrossplaneClaimGroup,
+	}
+	testCases := map[string][]string{
+		"externalsecret.yaml": {
+			"which the services orders, reports get as environment variables.",
+			"  name: orders-db-connection\n",
+			"    name: vault\n",
+			"        key: shop/orders-db\n",
+		},
+		filepath.Join("crossplane", "database.yaml"): {
+			"# Claim for the database orders-db, which currently runs at db.internal:3306.",
+			"apiVersion: platform.example.org/v1alpha1\n",
+			"    engine: mysql\n",
+			"      move2kube.konveyor.io/project: shop\n",
+		},
+	}
+	for templatePath, expectedLines := range testCases {
+		tpl, err := os.ReadFile(filepath.Join(templatesDir, templatePath))
+		if err != nil {
+			t.Fatalf("failed to read the template %s . Error: %q", templatePath, err)
+		}
+		output, err := common.GetStringFromTemplate(string(tpl), config)
+		if err != nil {
+			t.Fatalf("failed to fill the template %s . Error: %q", templatePath, err)
+		}
+		for _, expectedLine := range expectedLines {
+			if !strings.Contains(output, expectedLine) {
+				t.Fatalf("expected the template %s to contain %q . Actual:\n%s", templatePath, expectedLine, output)
+			}
+		}
+	}
+}
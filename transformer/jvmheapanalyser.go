@@ -0,0 +1,177 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	javaOptsEnvName = "JAVA_OPTS"
+)
+
+var (
+	// dockerfileJavaRegex matches the java base images and packages in a Dockerfile, like openjdk:8u151-jre, eclipse-temurin:17 and java-1.8.0-openjdk
+	dockerfileJavaRegex = regexp.MustCompile(`(?i)(?:openjdk|jdk|jre|java|temurin|corretto|zulu)[-:]?((?:1\.)?\d+(?:\.\d+)*(?:[u_]\d+)?)`)
+	dockerfileEnvRegex  = regexp.MustCompile(`(?i)^ENV\s+` + common.JavaToolOptionsEnvName + `(?:\s+|=)(.*)$`)
+)
+
+// JVMHeapAnalyser implements Transformer interface
+type JVMHeapAnalyser struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// Init Initializes the transformer
+func (t *JVMHeapAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *JVMHeapAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *JVMHeapAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform sets the JVM options of the java containers so that the heap stays within the memory limits of the containers
+func (t *JVMHeapAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		for sn, s := range ir.Services {
+			for i, container := range s.Containers {
+				javaVersion, imageOptions, ok := getDockerfileJavaInfo(ir, container.Image)
+				if !ok {
+					continue
+				}
+				memoryLimit, memoryLimitStr := int64(0), ""
+				if limit, ok := container.Resources.Limits[core.ResourceMemory]; ok {
+					memoryLimit, memoryLimitStr = limit.Value(), limit.String()
+				}
+				options := imageOptions
+				for _, env := range container.Env {
+					if env.Name == common.JavaToolOptionsEnvName {
+						options = env.Value
+					}
+					if env.Name == javaOptsEnvName && memoryLimit > 0 && common.GetJVMMaxHeap(env.Value) >= memoryLimit {
+						logrus.Warnf("the max heap in the %s of the container %s of the service %s is not less than its memory limit %d , so the container will be OOMKilled", javaOptsEnvName, container.Name, sn, memoryLimit)
+					}
+				}
+				memoryOptions := common.GetJVMMemoryOptions(javaVersion, memoryLimit)
+				if memoryOptions == "" {
+					logrus.Warnf("java %s in the container %s of the service %s is not container aware and the container does not have a memory limit, so the heap of the JVM is not bounded", javaVersion, container.Name, sn)
+				}
+				qaKey := common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`, common.ConfigJVMOptionsKeySegment)
+				if len(s.Containers) > 1 {
+					qaKey = common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`, common.ConfigContainersKeySegment, `"`+container.Name+`"`, common.ConfigJVMOptionsKeySegment)
+				}
+				hints := []string{fmt.Sprintf("The options are set in the %s environment variable, which all JVMs read", common.JavaToolOptionsEnvName)}
+				if memoryLimit > 0 {
+					hints = append(hints, fmt.Sprintf("The memory limit of the container is %s , of which %d%% is used for the heap", memoryLimitStr, common.DefaultMaxRAMPercentage))
+				}
+				options = qaengine.FetchStringAnswer(
+					qaKey,
+					fmt.Sprintf("Enter the JVM options for the container %s of the service %s :", container.Name, sn),
+					hints,
+					common.ReplaceJVMMemoryOptions(options, memoryOptions),
+				)
+				if maxHeap := common.GetJVMMaxHeap(options); memoryLimit > 0 && maxHeap >= memoryLimit {
+					logrus.Warnf("the max heap %d of the container %s of the service %s is not less than its memory limit %d , so the container will be OOMKilled", maxHeap, container.Name, sn, memoryLimit)
+				}
+				s.Containers[i].Env = setJVMOptionsEnv(container.Env, options)
+			}
+			ir.Services[sn] = s
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return nil, artifactsCreated, nil
+}
+
+// getDockerfileJavaInfo returns the java version and the JVM options in the Dockerfile of the image, if it runs java
+func getDockerfileJavaInfo(ir irtypes.IR, imageName string) (string, string, bool) {
+	image, ok := ir.ContainerImages[imageName]
+	if !ok || len(image.Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue]) == 0 {
+		return "", "", false
+	}
+	dockerfilePath := image.Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue][0]
+	file, err := os.Open(dockerfilePath)
+	if err != nil {
+		logrus.Debugf("failed to open the Dockerfile at path %s . Error: %q", dockerfilePath, err)
+		return "", "", false
+	}
+	defer file.Close()
+	javaVersion, options, isJava := "", "", false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(strings.ToUpper(line), "FROM ") {
+			// only the last stage is run
+			javaVersion, options, isJava = "", "", false
+		}
+		if matches := dockerfileEnvRegex.FindStringSubmatch(line); matches != nil {
+			options = strings.Trim(strings.TrimSpace(matches[1]), `"'`)
+			isJava = true
+		}
+		if strings.Contains(line, `"java"`) || strings.Contains(line, "java -") {
+			isJava = true
+		}
+		if matches := dockerfileJavaRegex.FindStringSubmatch(line); matches != nil {
+			isJava = true
+			if javaVersion == "" {
+				javaVersion = matches[1]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logrus.Debugf("failed to read the Dockerfile at path %s . Error: %q", dockerfilePath, err)
+	}
+	return javaVersion, options, isJava
+}
+
+// setJVMOptionsEnv sets the JVM options in the environment variables of the container
+func setJVMOptionsEnv(envs []core.EnvVar, options string) []core.EnvVar {
+	for i, env := range envs {
+		if env.Name == common.JavaToolOptionsEnvName {
+			envs[i].Value = options
+			return envs
+		}
+	}
+	return append(envs, core.EnvVar{Name: common.JavaToolOptionsEnvName, Value: options})
+}
@@ -0,0 +1,99 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestJVMHeapAnalyserTransform(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.services."shop".containers."worker".jvmoptions="-Xmx256m"`,
+	}, nil, nil, false, false)
+
+	dockerfilesDir := t.TempDir()
+	dockerfiles := map[string]string{
+		"legacy":  "FROM maven:3-openjdk-17 AS build\nRUN mvn package\nFROM openjdk:8u151-jre\nENV JAVA_TOOL_OPTIONS=\"-Xmx2g -Dfile.encoding=UTF-8\"\nCMD [\"java\", \"-jar\", \"app.jar\"]\n",
+		"modern":  "FROM eclipse-temurin:17\nCMD java -jar app.jar\n",
+		"nginx":   "FROM nginx:1.21\n",
+		"worker":  "FROM registry.access.redhat.com/ubi8/ubi\nRUN yum install -y java-1.8.0-openjdk\n",
+		"builder": "FROM openjdk:11 AS build\nFROM nginx:1.21\n",
+	}
+	ir := irtypes.NewIR()
+	for name, contents := range dockerfiles {
+		dockerfilePath := filepath.Join(dockerfilesDir, name+".Dockerfile")
+		if err := os.WriteFile(dockerfilePath, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the Dockerfile %s . Error: %q", name, err)
+		}
+		ir.ContainerImages[name+":latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{
+			Artifacts: map[irtypes.ContainerBuildArtifactTypeValue][]string{irtypes.DockerfileContainerBuildArtifactTypeValue: {dockerfilePath}},
+		}}
+	}
+	limits := func(memory string) core.ResourceRequirements {
+		return core.ResourceRequirements{Limits: core.ResourceList{core.ResourceMemory: resource.MustParse(memory)}}
+	}
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "legacy:latest", Resources: limits("1Gi")}}
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web", Image: "modern:latest", Env: []core.EnvVar{{Name: "PORT", Value: "8080"}}}}
+	shop := irtypes.NewServiceWithName("shop")
+	shop.Containers = []core.Container{
+		{Name: "proxy", Image: "nginx:latest"},
+		{Name: "worker", Image: "worker:latest", Resources: limits("512Mi")},
+		{Name: "static", Image: "builder:latest"},
+	}
+	ir.Services = map[string]irtypes.Service{"api": api, "web": web, "shop": shop}
+	artifact := transformertypes.Artifact{Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}}
+
+	_, createdArtifacts, err := (&JVMHeapAnalyser{}).Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the IR. Error: %q", err)
+	}
+	if len(createdArtifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", createdArtifacts)
+	}
+	ir = createdArtifacts[0].Configs[irtypes.IRConfigType].(irtypes.IR)
+
+	expectedEnvs := map[string][][]core.EnvVar{
+		// the heap of java 8u151 is set explicitly from the memory limit, keeping the other options of the image
+		"api": {{{Name: common.JavaToolOptionsEnvName, Value: "-Dfile.encoding=UTF-8 -Xmx768m"}}},
+		"web": {{{Name: "PORT", Value: "8080"}, {Name: common.JavaToolOptionsEnvName, Value: "-XX:+UseContainerSupport -XX:MaxRAMPercentage=75.0"}}},
+		// only the java containers get the options, and the user can override them for each container
+		"shop": {nil, {{Name: common.JavaToolOptionsEnvName, Value: "-Xmx256m"}}, nil},
+	}
+	for sn, expected := range expectedEnvs {
+		actual := [][]core.EnvVar{}
+		for _, container := range ir.Services[sn].Containers {
+			actual = append(actual, container.Env)
+		}
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Fatalf("wrong environment variables for the service %s . Difference:\n%s", sn, diff)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+// exposeIntents are the services in the source yamls that are exposed and unexposed using the move2kube annotations
+type exposeIntents struct {
+	exposed   map[string]irtypes.Service
+	unexposed map[string]bool
+	// targeted are the services that are the backends of the ingresses and routes in the source yamls
+	targeted map[string]bool
+}
+
+func newExposeIntents() exposeIntents {
+	return exposeIntents{exposed: map[string]irtypes.Service{}, unexposed: map[string]bool{}, targeted: map[string]bool{}}
+}
+
+// isAnnotationSet returns the boolean value of the annotation, and false if the annotation is missing or is not a boolean
+func isAnnotationSet(annotations map[string]string, annotation string) (value bool, ok bool) {
+	annotationValue, ok := annotations[annotation]
+	if !ok {
+		return false, false
+	}
+	value, err := strconv.ParseBool(strings.TrimSpace(annotationValue))
+	if err != nil {
+		logrus.Warnf("Ignoring the annotation %s since its value %q is not true or false", annotation, annotationValue)
+		return false, false
+	}
+	return value, true
+}
+
+// isIgnoredDoc returns true if the resource is annotated to be left out of the output
+func isIgnoredDoc(doc *yaml.Node) bool {
+	ignore, _ := isAnnotationSet(getAnnotationsFromDoc(doc), common.IgnoreAnnotation)
+	return ignore
+}
+
+// isUntransformedDoc returns true if the resource is annotated to be written as it is
+func isUntransformedDoc(doc *yaml.Node) bool {
+	transform, ok := isAnnotationSet(getAnnotationsFromDoc(doc), common.TransformAnnotation)
+	return ok && !transform
+}
+
+// collectExposeIntents finds the services to expose and unexpose, and the services targeted by ingresses and routes.
+// The unnamed ports of the services to expose are named, since the generated ingresses and routes refer to them by name.
+func (intents exposeIntents) collectExposeIntents(doc *yaml.Node) {
+	if isIgnoredDoc(doc) || isUntransformedDoc(doc) {
+		return
+	}
+	kind, name := getKindAndNameFromDoc(doc)
+	switch kind {
+	case common.ServiceKind:
+		annotations := getAnnotationsFromDoc(doc)
+		expose, ok := isAnnotationSet(annotations, common.ExposeAnnotation)
+		if !ok || name == "" {
+			return
+		}
+		if !expose {
+			intents.unexposed[name] = true
+			return
+		}
+		service, err := getServiceToExpose(doc, name, annotations)
+		if err != nil {
+			logrus.Errorf("Failed to expose the service %s . Error: %q", name, err)
+			return
+		}
+		intents.exposed[name] = service
+	case common.IngressKind:
+		for _, backend := range getIngressBackendNodes(doc) {
+			if serviceName := getIngressBackendServiceName(backend); serviceName != "" {
+				intents.targeted[serviceName] = true
+			}
+		}
+	case routeKind:
+		for _, serviceName := range getRouteServiceNames(doc) {
+			intents.targeted[serviceName] = true
+		}
+	}
+}
+
+// applyExposeIntents removes the exposure of the unexposed services from the document.
+// It returns false if nothing remains of the resource and the document should be dropped.
+func (intents exposeIntents) applyExposeIntents(doc *yaml.Node) bool {
+	if len(intents.unexposed) == 0 {
+		return true
+	}
+	kind, name := getKindAndNameFromDoc(doc)
+	switch kind {
+	case common.ServiceKind:
+		if intents.unexposed[name] {
+			unexposeService(doc)
+		}
+	case common.IngressKind:
+		if !removeIngressBackends(doc, intents.unexposed) {
+			logrus.Infof("Dropping the ingress %s since all its backends are unexposed services", name)
+			return false
+		}
+	case routeKind:
+		for _, serviceName := range getRouteServiceNames(doc) {
+			if intents.unexposed[serviceName] {
+				logrus.Infof("Dropping the route %s to the unexposed service %s", name, serviceName)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// getIRForExposedServices returns the IR with the services to expose that are not already targeted by an ingress or route
+func (intents exposeIntents) getIRForExposedServices(inputPath string) (irtypes.EnhancedIR, bool) {
+	ir := irtypes.NewIR()
+	ir.Name = common.NormalizeForMetadataName(filepath.Base(inputPath))
+	for name, service := range intents.exposed {
+		if intents.targeted[name] {
+			logrus.Debugf("Not exposing the service %s since there is already an ingress or route for it", name)
+			continue
+		}
+		ir.Services[name] = service
+	}
+	return irtypes.NewEnhancedIRFromIR(ir), len(ir.Services) != 0
+}
+
+// getServiceToExpose returns a service that creates only the ingress for the port selected by the annotations
+func getServiceToExpose(doc *yaml.Node, name string, annotations map[string]string) (irtypes.Service, error) {
+	portNode, err := getServicePortNode(doc, annotations[common.ServicePortAnnotation])
+	if err != nil {
+		return irtypes.Service{}, err
+	}
+	portNumber, err := strconv.ParseInt(getMappingScalar(portNode, "port"), 10, 32)
+	if err != nil {
+		return irtypes.Service{}, fmt.Errorf("the port of the service is not a number. Error: %q", err)
+	}
+	portName := getMappingScalar(portNode, "name")
+	if portName == "" {
+		portName = fmt.Sprintf("port-%d", portNumber)
+		setMappingScalar(portNode, "name", portName)
+	}
+	path := strings.TrimSpace(annotations[common.ExposePathAnnotation])
+	if path == "" {
+		path = "/" + name
+	}
+	service := irtypes.NewServiceWithName(name)
+	service.OnlyIngress = true
+	service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+		ServicePort:    networking.ServiceBackendPort{Name: portName, Number: int32(portNumber)},
+		ServiceRelPath: path,
+		ServiceType:    core.ServiceTypeClusterIP,
+	}}
+	return service, nil
+}
+
+// getServicePortNode returns the port of the service with the given name or number, or the first port if it is empty
+func getServicePortNode(doc *yaml.Node, port string) (*yaml.Node, error) {
+	portsNode := getMappingValue(doc, "spec", "ports")
+	if portsNode == nil || portsNode.Kind != yaml.SequenceNode || len(portsNode.Content) == 0 {
+		return nil, fmt.Errorf("the service has no ports")
+	}
+	port = strings.TrimSpace(port)
+	if port == "" {
+		return portsNode.Content[0], nil
+	}
+	for _, portNode := range portsNode.Content {
+		if getMappingScalar(portNode, "name") == port || getMappingScalar(portNode, "port") == port {
+			return portNode, nil
+		}
+	}
+	return nil, fmt.Errorf("the service has no port with the name or number %s", port)
+}
+
+// unexposeService makes the service a ClusterIP service, removing the fields that expose it outside the cluster
+func unexposeService(doc *yaml.Node) {
+	specNode := getMappingValue(doc, "spec")
+	if specNode == nil {
+		return
+	}
+	deleteMappingKey(specNode, "externalIPs")
+	serviceType := getMappingScalar(specNode, "type")
+	if serviceType != string(core.ServiceTypeNodePort) && serviceType != string(core.ServiceTypeLoadBalancer) {
+		return
+	}
+	setMappingScalar(specNode, "type", string(core.ServiceTypeClusterIP))
+	for _, key := range []string{"externalTrafficPolicy", "healthCheckNodePort", "loadBalancerIP", "loadBalancerSourceRanges", "loadBalancerClass", "allocateLoadBalancerNodePorts"} {
+		deleteMappingKey(specNode, key)
+	}
+	if portsNode := getMappingValue(specNode, "ports"); portsNode != nil && portsNode.Kind == yaml.SequenceNode {
+		for _, portNode := range portsNode.Content {
+			deleteMappingKey(portNode, "nodePort")
+		}
+	}
+}
+
+// removeIngressBackends removes the default backend, paths and rules of the ingress that target the services.
+// It returns false if the ingress has no backends left.
+func removeIngressBackends(doc *yaml.Node, services map[string]bool) bool {
+	specNode := getMappingValue(doc, "spec")
+	if specNode == nil {
+		return true
+	}
+	// the default backend was called backend in the older versions of ingress
+	for _, key := range []string{"defaultBackend", "backend"} {
+		if backend := getMappingValue(specNode, key); backend != nil && services[getIngressBackendServiceName(backend)] {
+			deleteMappingKey(specNode, key)
+		}
+	}
+	if rulesNode := getMappingValue(specNode, "rules"); rulesNode != nil && rulesNode.Kind == yaml.SequenceNode {
+		rules := []*yaml.Node{}
+		for _, ruleNode := range rulesNode.Content {
+			pathsNode := getMappingValue(ruleNode, "http", "paths")
+			if pathsNode == nil || pathsNode.Kind != yaml.SequenceNode {
+				rules = append(rules, ruleNode)
+				continue
+			}
+			paths := []*yaml.Node{}
+			for _, pathNode := range pathsNode.Content {
+				if !services[getIngressBackendServiceName(getMappingValue(pathNode, "backend"))] {
+					paths = append(paths, pathNode)
+				}
+			}
+			pathsNode.Content = paths
+			if len(paths) != 0 {
+				rules = append(rules, ruleNode)
+			}
+		}
+		rulesNode.Content = rules
+	}
+	return len(getIngressBackendNodes(doc)) != 0
+}
+
+// getIngressBackendNodes returns the default backend and the backends of the paths of the ingress
+func getIngressBackendNodes(doc *yaml.Node) []*yaml.Node {
+	backends := []*yaml.Node{}
+	specNode := getMappingValue(doc, "spec")
+	if specNode == nil {
+		return backends
+	}
+	for _, key := range []string{"defaultBackend", "backend"} {
+		if backend := getMappingValue(specNode, key); backend != nil {
+			backends = append(backends, backend)
+		}
+	}
+	if rulesNode := getMappingValue(specNode, "rules"); rulesNode != nil && rulesNode.Kind == yaml.SequenceNode {
+		for _, ruleNode := range rulesNode.Content {
+			pathsNode := getMappingValue(ruleNode, "http", "paths")
+			if pathsNode == nil || pathsNode.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, pathNode := range pathsNode.Content {
+				if backend := getMappingValue(pathNode, "backend"); backend != nil {
+					backends = append(backends, backend)
+				}
+			}
+		}
+	}
+	return backends
+}
+
+// getIngressBackendServiceName returns the name of the service of an ingress backend of any version
+func getIngressBackendServiceName(backend *yaml.Node) string {
+	if name := getMappingScalar(getMappingValue(backend, "service"), "name"); name != "" {
+		return name
+	}
+	return getMappingScalar(backend, "serviceName")
+}
+
+// getRouteServiceNames returns the names of the services the route sends traffic to
+func getRouteServiceNames(doc *yaml.Node) []string {
+	names := []string{}
+	if name := getMappingScalar(getMappingValue(doc, "spec", "to"), "name"); name != "" {
+		names = append(names, name)
+	}
+	if backendsNode := getMappingValue(doc, "spec", "alternateBackends"); backendsNode != nil && backendsNode.Kind == yaml.SequenceNode {
+		for _, backendNode := range backendsNode.Content {
+			if name := getMappingScalar(backendNode, "name"); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func getKindAndNameFromDoc(doc *yaml.Node) (kind string, name string) {
+	return getMappingScalar(doc, "kind"), getMappingScalar(getMappingValue(doc, "metadata"), "name")
+}
+
+func getAnnotationsFromDoc(doc *yaml.Node) map[string]string {
+	annotations := map[string]string{}
+	annotationsNode := getMappingValue(doc, "metadata", "annotations")
+	if annotationsNode == nil || annotationsNode.Kind != yaml.MappingNode {
+		return annotations
+	}
+	for i := 0; i+1 < len(annotationsNode.Content); i += 2 {
+		annotations[annotationsNode.Content[i].Value] = annotationsNode.Content[i+1].Value
+	}
+	return annotations
+}
+
+// getMappingValue returns the node at the path of keys in the mapping, or nil if there is none
+func getMappingValue(node *yaml.Node, keys ...string) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	for _, key := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				value = node.Content[i+1]
+				break
+			}
+		}
+		node = value
+	}
+	return node
+}
+
+func getMappingScalar(node *yaml.Node, key string) string {
+	value := getMappingValue(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return value.Value
+}
+
+func setMappingScalar(node *yaml.Node, key, value string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	if valueNode := getMappingValue(node, key); valueNode != nil {
+		valueNode.Kind, valueNode.Tag, valueNode.Style, valueNode.Value, valueNode.Content = yaml.ScalarNode, "!!str", 0, value, nil
+		return
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+func deleteMappingKey(node *yaml.Node, key string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/yamlnode"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExposeIntents(t *testing.T) {
+	input := `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    ` + common.ExposeAnnotation + `: "true"
+    ` + common.ExposePathAnnotation + `: /shop
+spec:
+  ports:
+  - port: 9090
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+  annotations:
+    ` + common.ExposeAnnotation + `: "false"
+spec:
+  type: LoadBalancer
+  loadBalancerIP: 10.0.0.1
+  ports:
+  - name: sql
+    port: 5432
+    nodePort: 30432
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: main
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /db
+        backend:
+          service:
+            name: db
+            port:
+              name: sql
+      - path: /api
+        backend:
+          service:
+            name: api
+            port:
+              number: 8080
+---
+apiVersion: route.openshift.io/v1
+kind: Route
+metadata:
+  name: db
+spec:
+  to:
+    kind: Service
+    name: db
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: scratch
+  annotations:
+    ` + common.IgnoreAnnotation + `: "true"
+`
+	docs, err := yamlnode.ParseDocuments([]byte(input))
+	if err != nil {
+		t.Fatalf("failed to parse the documents. Error: %q", err)
+	}
+	intents := newExposeIntents()
+	for _, doc := range docs {
+		intents.collectExposeIntents(doc)
+	}
+	if !intents.unexposed["db"] || !intents.targeted["db"] || !intents.targeted["api"] || intents.targeted["web"] {
+		t.Fatalf("unexpected intents. Unexposed: %+v Targeted: %+v", intents.unexposed, intents.targeted)
+	}
+	web, ok := intents.exposed["web"]
+	if !ok || len(web.ServiceToPodPortForwardings) != 1 || !web.OnlyIngress {
+		t.Fatalf("expected the service web to be exposed. Actual: %+v", intents.exposed)
+	}
+	if forwarding := web.ServiceToPodPortForwardings[0]; forwarding.ServicePort.Name != "port-9090" || forwarding.ServicePort.Number != 9090 || forwarding.ServiceRelPath != "/shop" {
+		t.Fatalf("unexpected port forwarding for the service web: %+v", forwarding)
+	}
+	if _, ok := intents.getIRForExposedServices("/src/my_app"); !ok {
+		t.Fatalf("expected an IR with the service web")
+	}
+	kept := []*yaml.Node{}
+	for _, doc := range docs {
+		if !isIgnoredDoc(doc) && intents.applyExposeIntents(doc) {
+			kept = append(kept, doc)
+		}
+	}
+	if len(kept) != 3 {
+		t.Fatalf("expected the route and the ignored config map to be dropped. Actual number of documents: %d", len(kept))
+	}
+	outputBytes, err := yamlnode.EncodeDocuments(kept)
+	if err != nil {
+		t.Fatalf("failed to encode the documents. Error: %q", err)
+	}
+	output := string(outputBytes)
+	for _, expected := range []string{"name: port-9090", "type: ClusterIP", "path: /api"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected the output to contain %q . Actual:\n%s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"loadBalancerIP", "nodePort", "path: /db"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("expected the output to not contain %q . Actual:\n%s", unexpected, output)
+		}
+	}
+}
@@ -108,11 +108,11 @@ func (*BuildConfig) getBuildSource(irBuildConfig irtypes.BuildConfig, ir irtypes
 		}
 	}
 	if repoDir != "" {
-		relDockerfilePath, err := filepath.Rel(repoDir, contextPath)
+		relContextPath, err := filepath.Rel(repoDir, contextPath)
 		if err != nil {
 			logrus.Debugf("Failed to make the path %s relative to the path %s Error %q", contextPath, repoDir, err)
 		} else {
-			contextPath = filepath.Dir(relDockerfilePath)
+			contextPath = relContextPath
 		}
 	}
 	gitRepoURL := gitRepoURLPlaceholder
@@ -142,11 +142,15 @@ func (*BuildConfig) getBuildStrategy(irBuildConfig irtypes.BuildConfig, ir irtyp
 	}
 	dockerfilePath := dockerfilePathPlaceholder
 	if repoDir != "" {
-		relDockerfilePath, err := filepath.Rel(repoDir, irBuildConfig.ContainerBuild.ContextPath)
-		if err != nil {
-			logrus.Debugf("Failed to make the path %s relative to the path %s Error %q", irBuildConfig.ContainerBuild.ContextPath, repoDir, err)
-		} else {
-			dockerfilePath = relDockerfilePath
+		dockerfilePath = common.DefaultDockerfileName
+		// the path of the Dockerfile is relative to the context directory
+		if dockerfilePaths := irBuildConfig.ContainerBuild.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue]; len(dockerfilePaths) != 0 {
+			relDockerfilePath, err := filepath.Rel(irBuildConfig.ContainerBuild.ContextPath, dockerfilePaths[0])
+			if err != nil {
+				logrus.Debugf("Failed to make the path %s relative to the path %s Error %q", dockerfilePaths[0], irBuildConfig.ContainerBuild.ContextPath, err)
+			} else {
+				dockerfilePath = relDockerfilePath
+			}
 		}
 	}
 	strategy := okdbuildv1.BuildStrategy{}
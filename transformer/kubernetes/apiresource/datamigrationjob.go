@@ -0,0 +1,119 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// dataMigrationJobSuffix is the suffix of the name of data migration jobs
+	dataMigrationJobSuffix = "-data-migration"
+	// dataMigrationImage is the image used to copy the data into the persistent volume claims
+	dataMigrationImage = "docker.io/rclone/rclone:latest"
+	// dataMigrationSourceEnvName is the env var storing the rclone remote path to copy the data from
+	dataMigrationSourceEnvName = "SOURCE"
+	// dataMigrationMountPath is the path where the persistent volume claim is mounted in the data migration job
+	dataMigrationMountPath = "/data"
+	// dataMigrationSourceHostPlaceholder should be replaced with the host containing the data on the source platform
+	dataMigrationSourceHostPlaceholder = "SOURCE_HOST"
+)
+
+// DataMigrationJob handles the jobs that copy the data of the source platform into persistent volume claims
+type DataMigrationJob struct {
+}
+
+// getSupportedKinds returns kinds supported by DataMigrationJob
+func (*DataMigrationJob) getSupportedKinds() []string {
+	return []string{jobKind}
+}
+
+// createNewResources creates a data migration job for each persistent volume claim that has data on the source platform
+func (d *DataMigrationJob) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	objs := []runtime.Object{}
+	if !common.IsPresent(supportedKinds, jobKind) {
+		logrus.Debugf("Could not find a valid resource type in cluster to create a data migration Job")
+		return objs
+	}
+	for _, stObj := range ir.Storages {
+		if stObj.StorageType != irtypes.PVCKind || stObj.SourcePath == "" {
+			continue
+		}
+		objs = append(objs, d.createDataMigrationJob(stObj))
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (d *DataMigrationJob) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(d.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// GetDataMigrationJobName returns the name of the data migration job of a persistent volume claim
+func GetDataMigrationJobName(claimName string) string {
+	return common.MakeStringDNSSubdomainNameCompliant(claimName + dataMigrationJobSuffix)
+}
+
+func (*DataMigrationJob) createDataMigrationJob(st irtypes.Storage) *batch.Job {
+	var backoffLimit int32 = 3
+	volumeName := common.MakeStringDNSLabelNameCompliant(st.Name)
+	return &batch.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       jobKind,
+			APIVersion: batch.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: GetDataMigrationJobName(st.Name),
+		},
+		Spec: batch.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: core.PodTemplateSpec{
+				Spec: core.PodSpec{
+					RestartPolicy: core.RestartPolicyOnFailure,
+					Containers: []core.Container{{
+						Name:  "data-migration",
+						Image: dataMigrationImage,
+						Args:  []string{"copy", fmt.Sprintf("$(%s)", dataMigrationSourceEnvName), dataMigrationMountPath, "--progress"},
+						Env: []core.EnvVar{{
+							Name:  dataMigrationSourceEnvName,
+							Value: fmt.Sprintf(":sftp,host=%s:%s", dataMigrationSourceHostPlaceholder, st.SourcePath),
+						}},
+						VolumeMounts: []core.VolumeMount{{Name: volumeName, MountPath: dataMigrationMountPath}},
+					}},
+					Volumes: []core.Volume{{
+						Name: volumeName,
+						VolumeSource: core.VolumeSource{
+							PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: st.Name},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
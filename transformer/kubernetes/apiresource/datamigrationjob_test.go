@@ -0,0 +1,63 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/kubernetes/pkg/apis/batch"
+)
+
+func TestCreateDataMigrationJobs(t *testing.T) {
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	ir.Storages = []irtypes.Storage{
+		{Name: "logs", StorageType: irtypes.PVCKind, SourcePath: "/srv/logs"},
+		{Name: "cache", StorageType: irtypes.PVCKind},
+		{Name: "config", StorageType: irtypes.ConfigMapKind, SourcePath: "/srv/config"},
+	}
+	d := &DataMigrationJob{}
+	if objs := d.createNewResources(ir, []string{common.DeploymentKind}, collection.ClusterMetadata{}); len(objs) != 0 {
+		t.Fatalf("expected no jobs when the cluster does not support jobs. Actual: %+v", objs)
+	}
+	objs := d.createNewResources(ir, []string{jobKind}, collection.ClusterMetadata{})
+	if len(objs) != 1 {
+		t.Fatalf("expected a single data migration job for the claim that has a source path. Actual: %+v", objs)
+	}
+	job, ok := objs[0].(*batch.Job)
+	if !ok {
+		t.Fatalf("expected a job. Actual: %T", objs[0])
+	}
+	if job.Name != GetDataMigrationJobName("logs") || job.Name != "logs-data-migration" {
+		t.Fatalf("the name of the data migration job is incorrect. Actual: %s", job.Name)
+	}
+	podSpec := job.Spec.Template.Spec
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].PersistentVolumeClaim == nil || podSpec.Volumes[0].PersistentVolumeClaim.ClaimName != "logs" {
+		t.Fatalf("expected the job to mount the claim logs. Actual: %+v", podSpec.Volumes)
+	}
+	container := podSpec.Containers[0]
+	wantSource := ":sftp,host=" + dataMigrationSourceHostPlaceholder + ":/srv/logs"
+	if len(container.Env) != 1 || container.Env[0].Value != wantSource {
+		t.Fatalf("the source of the data migration is incorrect. Differences:\n%s", cmp.Diff(wantSource, container.Env))
+	}
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != dataMigrationMountPath {
+		t.Fatalf("expected the claim to be mounted at %s . Actual: %+v", dataMigrationMountPath, container.VolumeMounts)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+)
+
+// ObjectDecorator decorates or renames a generated Kubernetes object just before it is written.
+// It returns the decorated object, or nil if the object should not be written.
+type ObjectDecorator func(obj map[string]interface{}) (map[string]interface{}, error)
+
+type namedObjectDecorator struct {
+	name      string
+	decorator ObjectDecorator
+}
+
+var (
+	objectDecorators      = []namedObjectDecorator{}
+	objectDecoratorsMutex sync.Mutex
+)
+
+// RegisterObjectDecorator adds a decorator that is run on every generated Kubernetes object, in the order of registration.
+// A decorator with the same name replaces the existing one.
+func RegisterObjectDecorator(name string, decorator ObjectDecorator) {
+	objectDecoratorsMutex.Lock()
+	defer objectDecoratorsMutex.Unlock()
+	for i, existing := range objectDecorators {
+		if existing.name == name {
+			objectDecorators[i].decorator = decorator
+			return
+		}
+	}
+	objectDecorators = append(objectDecorators, namedObjectDecorator{name: name, decorator: decorator})
+}
+
+// decorateObjects runs the decorators on the objects.
+// Objects that a decorator fails on are written as they were before that decorator.
+func decorateObjects(objs []runtime.Object) []runtime.Object {
+	objectDecoratorsMutex.Lock()
+	decorators := append([]namedObjectDecorator{}, objectDecorators...)
+	objectDecoratorsMutex.Unlock()
+	if len(decorators) == 0 {
+		return objs
+	}
+	decoratedObjs := []runtime.Object{}
+	for _, obj := range objs {
+		decoratedObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			logrus.Errorf("failed to convert the object to decorate it. Writing it as is. Object:\n%+v\nError: %q", obj, err)
+			decoratedObjs = append(decoratedObjs, obj)
+			continue
+		}
+		for _, d := range decorators {
+			newObj, err := d.decorator(decoratedObj)
+			if err != nil {
+				logrus.Errorf("the object decorator %s failed on the object %s . Ignoring the decorator for this object. Error: %q", d.name, decoratedObj["metadata"], err)
+				continue
+			}
+			decoratedObj = newObj
+			if decoratedObj == nil {
+				logrus.Debugf("the object decorator %s removed the object %+v", d.name, obj)
+				break
+			}
+		}
+		if decoratedObj == nil {
+			continue
+		}
+		newObj, err := decodeDecoratedObject(obj, decoratedObj)
+		if err != nil {
+			logrus.Errorf("failed to decode the decorated object. Writing it as it was before the decorators. Object:\n%+v\nError: %q", decoratedObj, err)
+			decoratedObjs = append(decoratedObjs, obj)
+			continue
+		}
+		decoratedObjs = append(decoratedObjs, newObj)
+	}
+	return decoratedObjs
+}
+
+// decodeDecoratedObject converts the decorated object back to a typed object.
+// Objects that kept their kind are converted to the type of the original object, since the kinds of some of them are not in the schema.
+func decodeDecoratedObject(obj runtime.Object, decoratedObj map[string]interface{}) (runtime.Object, error) {
+	objJSONBytes, err := json.Marshal(decoratedObj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the object to json. Error: %q", err)
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if decoratedObj["apiVersion"] == gvk.GroupVersion().String() && decoratedObj["kind"] == gvk.Kind {
+		newObj := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+		if err := json.Unmarshal(objJSONBytes, newObj); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the object into a %T . Error: %q", newObj, err)
+		}
+		return newObj, nil
+	}
+	newObj, _, err := serializer.NewCodecFactory(k8sschema.GetSchema()).UniversalDeserializer().Decode(objJSONBytes, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the object. Error: %q", err)
+	}
+	return newObj, nil
+}
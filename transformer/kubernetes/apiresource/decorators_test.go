@@ -0,0 +1,107 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func resetObjectDecorators(t *testing.T) {
+	objectDecoratorsMutex.Lock()
+	oldDecorators := objectDecorators
+	objectDecorators = []namedObjectDecorator{}
+	objectDecoratorsMutex.Unlock()
+	t.Cleanup(func() {
+		objectDecoratorsMutex.Lock()
+		objectDecorators = oldDecorators
+		objectDecoratorsMutex.Unlock()
+	})
+}
+
+func TestDecorateObjects(t *testing.T) {
+	resetObjectDecorators(t)
+	newConfigMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string]string{"key": "value"},
+		}
+	}
+	objs := []runtime.Object{
+		&appsv1.Deployment{TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}, ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+		newConfigMap("drop-me"),
+		newConfigMap("credentials"),
+	}
+	if actual := decorateObjects(objs); len(actual) != len(objs) {
+		t.Fatalf("expected the objects to be unchanged without decorators. Actual: %+v", actual)
+	}
+
+	RegisterObjectDecorator("labels", func(obj map[string]interface{}) (map[string]interface{}, error) {
+		return nil, fmt.Errorf("replaced by the decorator registered later")
+	})
+	RegisterObjectDecorator("failing", func(obj map[string]interface{}) (map[string]interface{}, error) {
+		return nil, fmt.Errorf("always fails")
+	})
+	RegisterObjectDecorator("drop", func(obj map[string]interface{}) (map[string]interface{}, error) {
+		if obj["metadata"].(map[string]interface{})["name"] == "drop-me" {
+			return nil, nil
+		}
+		return obj, nil
+	})
+	RegisterObjectDecorator("secrets", func(obj map[string]interface{}) (map[string]interface{}, error) {
+		if obj["kind"] != "ConfigMap" || obj["metadata"].(map[string]interface{})["name"] != "credentials" {
+			return obj, nil
+		}
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   obj["metadata"],
+			"stringData": obj["data"],
+		}, nil
+	})
+	RegisterObjectDecorator("labels", func(obj map[string]interface{}) (map[string]interface{}, error) {
+		metadata := obj["metadata"].(map[string]interface{})
+		metadata["labels"] = map[string]interface{}{"team": "payments"}
+		return obj, nil
+	})
+
+	actual := decorateObjects(objs)
+	if len(actual) != 2 {
+		t.Fatalf("expected the dropped object to be removed. Actual: %+v", actual)
+	}
+	deployment, ok := actual[0].(*appsv1.Deployment)
+	if !ok {
+		t.Fatalf("expected the decorated deployment to keep its type. Actual: %T", actual[0])
+	}
+	if diff := cmp.Diff(map[string]string{"team": "payments"}, deployment.Labels); diff != "" {
+		t.Fatalf("wrong labels on the decorated deployment. Difference:\n%s", diff)
+	}
+	secret, ok := actual[1].(*corev1.Secret)
+	if !ok {
+		t.Fatalf("expected the renamed config map to be decoded as a secret. Actual: %T", actual[1])
+	}
+	if secret.Name != "credentials" || secret.StringData["key"] != "value" || secret.Labels["team"] != "payments" {
+		t.Fatalf("wrong decorated secret. Actual: %+v", secret)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	apps "k8s.io/kubernetes/pkg/apis/apps"
 	batch "k8s.io/kubernetes/pkg/apis/batch"
 	core "k8s.io/kubernetes/pkg/apis/core"
@@ -174,7 +175,17 @@ func (d *Deployment) createDeployment(service irtypes.Service, cluster collectty
 	podSpec = irtypes.PodSpec(d.convertVolumesKindsByPolicy(core.PodSpec(podSpec), cluster))
 	podSpec.RestartPolicy = core.RestartPolicyAlways
 	logrus.Debugf("Created deployment for %s", service.Name)
-	return d.toDeployment(meta, core.PodSpec(podSpec), int32(service.Replicas), cluster)
+	deployment := d.toDeployment(meta, core.PodSpec(podSpec), int32(service.Replicas), cluster)
+	if service.MaxSurge != "" || service.MaxUnavailable != "" {
+		deployment.Spec.Strategy = apps.DeploymentStrategy{
+			Type: apps.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &apps.RollingUpdateDeployment{
+				MaxSurge:       intstr.Parse(service.MaxSurge),
+				MaxUnavailable: intstr.Parse(service.MaxUnavailable),
+			},
+		}
+	}
+	return deployment
 }
 
 func (d *Deployment) createDeploymentConfig(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *okdappsv1.DeploymentConfig {
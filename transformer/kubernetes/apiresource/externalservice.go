@@ -0,0 +1,122 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	discovery "k8s.io/kubernetes/pkg/apis/discovery"
+)
+
+const (
+	endpointSliceKind = "EndpointSlice"
+	endpointsKind     = "Endpoints"
+	// endpointSliceServiceNameLabel links an endpoint slice to its service
+	endpointSliceServiceNameLabel = "kubernetes.io/service-name"
+)
+
+// createExternalServiceResources creates a service with a stable name in the cluster for a dependency that stays outside the cluster.
+// Dependencies with a DNS name become ExternalName services. Dependencies with IP addresses become services without a selector with an endpoint slice.
+func (d *Service) createExternalServiceResources(externalService irtypes.ExternalService, supportedKinds []string) []runtime.Object {
+	name := common.NormalizeForMetadataName(externalService.Name)
+	ports := []core.ServicePort{}
+	for _, port := range externalService.Ports {
+		ports = append(ports, core.ServicePort{
+			Name:       fmt.Sprintf("port-%d", port),
+			Port:       port,
+			Protocol:   core.ProtocolTCP,
+			TargetPort: intstr.FromInt(int(port)),
+		})
+	}
+	svc := &core.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       common.ServiceKind,
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getServiceLabels(name),
+		},
+		Spec: core.ServiceSpec{
+			Ports: ports,
+		},
+	}
+	if externalService.Host != "" {
+		svc.Spec.Type = core.ServiceTypeExternalName
+		svc.Spec.ExternalName = externalService.Host
+		return []runtime.Object{svc}
+	}
+	svc.Spec.Type = core.ServiceTypeClusterIP
+	objs := []runtime.Object{svc}
+	if len(externalService.IPs) == 0 {
+		return objs
+	}
+	ips := append([]string{}, externalService.IPs...)
+	sort.Strings(ips)
+	if !common.IsPresent(supportedKinds, endpointSliceKind) {
+		// Clusters without endpoint slices still support the older endpoints
+		return append(objs, d.createExternalServiceEndpoints(name, ips, ports))
+	}
+	tcp := core.ProtocolTCP
+	endpointPorts := []discovery.EndpointPort{}
+	for i := range ports {
+		endpointPorts = append(endpointPorts, discovery.EndpointPort{Name: &ports[i].Name, Port: &externalService.Ports[i], Protocol: &tcp})
+	}
+	endpointSlice := &discovery.EndpointSlice{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       endpointSliceKind,
+			APIVersion: discovery.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{endpointSliceServiceNameLabel: name},
+		},
+		AddressType: discovery.AddressTypeIPv4,
+		Endpoints:   []discovery.Endpoint{{Addresses: ips}},
+		Ports:       endpointPorts,
+	}
+	return append(objs, endpointSlice)
+}
+
+// createExternalServiceEndpoints creates the endpoints of a service without a selector
+func (d *Service) createExternalServiceEndpoints(name string, ips []string, ports []core.ServicePort) *core.Endpoints {
+	subset := core.EndpointSubset{}
+	for _, ip := range ips {
+		subset.Addresses = append(subset.Addresses, core.EndpointAddress{IP: ip})
+	}
+	for _, port := range ports {
+		subset.Ports = append(subset.Ports, core.EndpointPort{Name: port.Name, Port: port.Port, Protocol: port.Protocol})
+	}
+	return &core.Endpoints{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       endpointsKind,
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getServiceLabels(name),
+		},
+		Subsets: []core.EndpointSubset{subset},
+	}
+}
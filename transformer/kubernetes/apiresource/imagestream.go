@@ -54,6 +54,9 @@ func (imageStream *ImageStream) createNewResources(ir irtypes.EnhancedIR, suppor
 	}
 	// Create an imagestream for each image that we are using
 	for in, irContainer := range ir.ContainerImages {
+		if irContainer.Build.ContainerBuildType != "" {
+			in = common.TagNewImageName(in)
+		}
 		imageStreamName, imageStreamTag := imageStream.GetImageStreamNameAndTag(in)
 		imageStream := imageStream.createImageStream(imageStreamName, imageStreamTag, in, irContainer, ir)
 		objs = append(objs, &imageStream)
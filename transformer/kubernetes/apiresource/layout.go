@@ -0,0 +1,217 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/yamlnode"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputLayout is the way the Kubernetes resources are organized into yaml files
+type OutputLayout string
+
+const (
+	// PerResourceOutputLayout writes each resource to its own file. This is the default.
+	PerResourceOutputLayout OutputLayout = "resource"
+	// PerKindOutputLayout writes the resources of each kind to a file
+	PerKindOutputLayout OutputLayout = "kind"
+	// PerServiceOutputLayout writes the resources of each service to a file, and the resources not belonging to a single service to a shared file
+	PerServiceOutputLayout OutputLayout = "service"
+	// SingleFileOutputLayout writes all the resources to a single multi document file
+	SingleFileOutputLayout OutputLayout = "single"
+)
+
+// kindOrder is the order in which the resources of the kinds are applied, so that the resources that the others depend on are created first.
+// Kinds missing in it, like custom resources, come last.
+var kindOrder = []string{
+	"Namespace", "CustomResourceDefinition",
+	"ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding",
+	"NetworkPolicy", "ResourceQuota", "LimitRange", "PodSecurityPolicy", "PodDisruptionBudget",
+	"Secret", "ConfigMap", "StorageClass", "PersistentVolume", "PersistentVolumeClaim",
+	"Service", "DaemonSet", "Pod", "ReplicationController", "ReplicaSet", "Deployment", "DeploymentConfig",
+	"HorizontalPodAutoscaler", "StatefulSet", "Job", "CronJob", "IngressClass", "Ingress", "Route", "APIService",
+}
+
+// layoutDocument is a document in one of the yamls being organized
+type layoutDocument struct {
+	node    *yaml.Node
+	kind    string
+	name    string
+	service string
+}
+
+// GetOutputLayout asks for the layout of the yaml files of the Kubernetes resources
+func GetOutputLayout() OutputLayout {
+	return OutputLayout(qaengine.FetchSelectAnswer(
+		common.ConfigOutputLayoutKey,
+		"How should the Kubernetes resources be organized into yaml files?",
+		[]string{
+			"resource: a file for each resource",
+			"kind: a file for each kind, prefixed with the order in which the kinds should be applied",
+			"service: a file for each service, and a shared file for the resources not belonging to a single service",
+			"single: a single multi document file",
+			"The resources in a file are ordered by their kind, with namespaces, custom resource definitions and RBAC resources first",
+		},
+		string(PerResourceOutputLayout),
+		[]string{string(PerResourceOutputLayout), string(PerKindOutputLayout), string(PerServiceOutputLayout), string(SingleFileOutputLayout)},
+	))
+}
+
+// ApplyOutputLayout reorganizes the yamls directly in the directory, which have a resource each, into files as given by the layout.
+// The shared file of the per service layout and the single file are named after defaultName.
+// Files with documents that are not Kubernetes resources are left as they are. It returns the files in the directory after the reorganization.
+func ApplyOutputLayout(dir string, layout OutputLayout, defaultName string) ([]string, error) {
+	filePaths, err := common.GetFilesByExtInCurrDir(dir, []string{".yaml"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the yaml files in the directory %s . Error: %q", dir, err)
+	}
+	if layout == "" || layout == PerResourceOutputLayout {
+		return filePaths, nil
+	}
+	files := []string{}
+	docs := []layoutDocument{}
+	readFilePaths := []string{}
+	for _, filePath := range filePaths {
+		fileDocs, err := readLayoutDocuments(filePath)
+		if err != nil {
+			logrus.Debugf("Leaving the file %s as it is, since it does not have only Kubernetes resources. Error: %q", filePath, err)
+			files = append(files, filePath)
+			continue
+		}
+		docs = append(docs, fileDocs...)
+		readFilePaths = append(readFilePaths, filePath)
+	}
+	setServicesOfDocuments(docs)
+	sort.SliceStable(docs, func(i, j int) bool {
+		if ri, rj := getKindRank(docs[i].kind), getKindRank(docs[j].kind); ri != rj {
+			return ri < rj
+		}
+		if docs[i].kind != docs[j].kind {
+			return docs[i].kind < docs[j].kind
+		}
+		return docs[i].name < docs[j].name
+	})
+	fileNames := []string{}
+	fileDocs := map[string][]*yaml.Node{}
+	for _, doc := range docs {
+		fileName := getLayoutFileName(doc, layout, defaultName)
+		if _, ok := fileDocs[fileName]; !ok {
+			fileNames = append(fileNames, fileName)
+		}
+		fileDocs[fileName] = append(fileDocs[fileName], doc.node)
+	}
+	for _, filePath := range readFilePaths {
+		if err := os.Remove(filePath); err != nil {
+			return files, fmt.Errorf("failed to remove the file %s . Error: %q", filePath, err)
+		}
+	}
+	for _, fileName := range fileNames {
+		data, err := yamlnode.EncodeDocuments(fileDocs[fileName])
+		if err != nil {
+			return files, fmt.Errorf("failed to encode the resources of the file %s . Error: %q", fileName, err)
+		}
+		filePath := filepath.Join(dir, fileName)
+		if err := os.WriteFile(filePath, data, common.DefaultFilePermission); err != nil {
+			return files, fmt.Errorf("failed to write the file %s . Error: %q", filePath, err)
+		}
+		files = append(files, filePath)
+	}
+	return files, nil
+}
+
+func readLayoutDocuments(filePath string) ([]layoutDocument, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := yamlnode.ParseDocuments(data)
+	if err != nil {
+		return nil, err
+	}
+	docs := []layoutDocument{}
+	for _, node := range nodes {
+		resource := struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name   string            `yaml:"name"`
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"metadata"`
+		}{}
+		if err := node.Decode(&resource); err != nil {
+			return nil, err
+		}
+		if resource.Kind == "" || resource.Metadata.Name == "" {
+			return nil, fmt.Errorf("a document does not have a kind and a name")
+		}
+		docs = append(docs, layoutDocument{node: node, kind: resource.Kind, name: resource.Metadata.Name, service: resource.Metadata.Labels[selector]})
+	}
+	return docs, nil
+}
+
+// setServicesOfDocuments sets the service of the resources without the service label.
+// A resource belongs to a service if it is named after the service or its name starts with the name of the service and a dash.
+func setServicesOfDocuments(docs []layoutDocument) {
+	serviceNames := []string{}
+	for _, doc := range docs {
+		if doc.service != "" && !common.IsStringPresent(serviceNames, doc.service) {
+			serviceNames = append(serviceNames, doc.service)
+		}
+	}
+	// longer names first, so that the resources of the service a-b are not given to the service a
+	sort.Slice(serviceNames, func(i, j int) bool { return len(serviceNames[i]) > len(serviceNames[j]) })
+	for i, doc := range docs {
+		if doc.service != "" {
+			continue
+		}
+		for _, serviceName := range serviceNames {
+			if doc.name == serviceName || strings.HasPrefix(doc.name, serviceName+"-") {
+				docs[i].service = serviceName
+				break
+			}
+		}
+	}
+}
+
+func getKindRank(kind string) int {
+	for i, k := range kindOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(kindOrder)
+}
+
+func getLayoutFileName(doc layoutDocument, layout OutputLayout, defaultName string) string {
+	switch layout {
+	case PerKindOutputLayout:
+		return fmt.Sprintf("%02d-%s.yaml", getKindRank(doc.kind), strings.ToLower(doc.kind))
+	case PerServiceOutputLayout:
+		if doc.service != "" {
+			return doc.service + ".yaml"
+		}
+	}
+	return defaultName + ".yaml"
+}
@@ -0,0 +1,83 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplyOutputLayout(t *testing.T) {
+	resources := map[string]string{
+		"api-deployment.yaml":    "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n  labels:\n    " + selector + ": api\n",
+		"api-service.yaml":       "apiVersion: v1\nkind: Service\nmetadata:\n  name: api\n",
+		"api-db-service.yaml":    "apiVersion: v1\nkind: Service\nmetadata:\n  name: api-db\n  labels:\n    " + selector + ": api-db\n",
+		"myproject-ingress.yaml": "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: myproject\n",
+		"team-namespace.yaml":    "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: team\n",
+	}
+	testcases := []struct {
+		layout OutputLayout
+		files  map[string][]string
+	}{
+		{layout: PerResourceOutputLayout, files: map[string][]string{
+			"api-deployment.yaml": {"api"}, "api-service.yaml": {"api"}, "api-db-service.yaml": {"api-db"},
+			"myproject-ingress.yaml": {"myproject"}, "team-namespace.yaml": {"team"},
+		}},
+		{layout: PerKindOutputLayout, files: map[string][]string{
+			"00-namespace.yaml": {"team"}, "17-service.yaml": {"api", "api-db"}, "22-deployment.yaml": {"api"}, "29-ingress.yaml": {"myproject"},
+		}},
+		{layout: PerServiceOutputLayout, files: map[string][]string{
+			"api.yaml": {"api", "api"}, "api-db.yaml": {"api-db"}, "myproject.yaml": {"team", "myproject"},
+		}},
+		{layout: SingleFileOutputLayout, files: map[string][]string{
+			"myproject.yaml": {"team", "api", "api-db", "api", "myproject"},
+		}},
+	}
+	for _, testcase := range testcases {
+		t.Run(string(testcase.layout), func(t *testing.T) {
+			dir := t.TempDir()
+			for fileName, resource := range resources {
+				if err := os.WriteFile(filepath.Join(dir, fileName), []byte(resource), 0644); err != nil {
+					t.Fatalf("failed to write the file %s . Error: %q", fileName, err)
+				}
+			}
+			files, err := ApplyOutputLayout(dir, testcase.layout, "myproject")
+			if err != nil {
+				t.Fatalf("failed to apply the output layout. Error: %q", err)
+			}
+			actual := map[string][]string{}
+			for _, file := range files {
+				docs, err := readLayoutDocuments(file)
+				if err != nil {
+					t.Fatalf("failed to read the file %s . Error: %q", file, err)
+				}
+				for _, doc := range docs {
+					actual[filepath.Base(file)] = append(actual[filepath.Base(file)], doc.name)
+				}
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != len(files) {
+				t.Fatalf("expected only the files of the layout in the directory. Actual: %d files", len(entries))
+			}
+			if !cmp.Equal(actual, testcase.files) {
+				t.Fatalf("wrong layout. Differences:\n%s", cmp.Diff(testcase.files, actual))
+			}
+		})
+	}
+}
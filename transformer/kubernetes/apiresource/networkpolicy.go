@@ -24,12 +24,17 @@ import (
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
 	networking "k8s.io/kubernetes/pkg/apis/networking"
 )
 
 const (
 	networkPolicyKind = "NetworkPolicy"
 	networkSelector   = types.GroupName + "/network"
+	// egressPolicyNameSuffix is the suffix of the names of the network policies that restrict the egress of the services
+	egressPolicyNameSuffix = "-egress"
+	dnsPortNumber          = 53
 )
 
 // NetworkPolicy handles NetworkPolicy objects
@@ -49,9 +54,14 @@ func (d *NetworkPolicy) createNewResources(ir irtypes.EnhancedIR, supportedKinds
 		return nil
 	}
 
+	networks := map[string]bool{}
 	for _, service := range ir.Services {
-		// Create services depending on whether the service needs to be externally exposed
+		// Create one network policy for each network, which lets the pods on the network connect to each other
 		for _, net := range service.Networks {
+			if networks[net] {
+				continue
+			}
+			networks[net] = true
 			logrus.Debugf("Network %s is detected at Source, shall be converted to equivalent NetworkPolicy at Destination", net)
 			obj, err := d.createNetworkPolicy(net)
 			if err != nil {
@@ -60,6 +70,14 @@ func (d *NetworkPolicy) createNewResources(ir irtypes.EnhancedIR, supportedKinds
 			}
 			objs = append(objs, obj)
 		}
+		if service.EgressPolicy {
+			objs = append(objs, d.createEgressNetworkPolicy(service))
+		}
+	}
+	for _, externalService := range ir.ExternalServices {
+		if externalService.EgressPolicy {
+			objs = append(objs, d.createExternalServiceEgressNetworkPolicy(externalService))
+		}
 	}
 	return objs
 }
@@ -100,6 +118,94 @@ func (d *NetworkPolicy) createNetworkPolicy(networkName string) (*networking.Net
 	return np, nil
 }
 
+// createEgressNetworkPolicy allows the service to connect to the pods in the namespace, to DNS and to the ports of its external endpoints
+func (d *NetworkPolicy) createEgressNetworkPolicy(service irtypes.Service) *networking.NetworkPolicy {
+	tcp, udp := core.ProtocolTCP, core.ProtocolUDP
+	dnsPort := intstr.FromInt(dnsPortNumber)
+	endpointPorts := []networking.NetworkPolicyPort{}
+	for _, endpoint := range service.ExternalEndpoints {
+		if endpoint.Port == 0 {
+			continue
+		}
+		port := intstr.FromInt(int(endpoint.Port))
+		duplicate := false
+		for _, existing := range endpointPorts {
+			if *existing.Port == port {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			endpointPorts = append(endpointPorts, networking.NetworkPolicyPort{Protocol: &tcp, Port: &port})
+		}
+	}
+	egress := []networking.NetworkPolicyEgressRule{
+		{To: []networking.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}},
+		{Ports: []networking.NetworkPolicyPort{{Protocol: &udp, Port: &dnsPort}, {Protocol: &tcp, Port: &dnsPort}}},
+	}
+	if len(endpointPorts) > 0 {
+		egress = append(egress, networking.NetworkPolicyEgressRule{Ports: endpointPorts})
+	}
+	return &networking.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       networkPolicyKind,
+			APIVersion: networking.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: common.NormalizeForMetadataName(service.Name + egressPolicyNameSuffix),
+		},
+		Spec: networking.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: getServiceLabels(service.Name)},
+			PolicyTypes: []networking.PolicyType{networking.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+}
+
+// createExternalServiceEgressNetworkPolicy allows the clients of an external service to connect to it, to the pods in the namespace and to DNS.
+// All the pods in the namespace are selected when the clients are not known.
+func (d *NetworkPolicy) createExternalServiceEgressNetworkPolicy(externalService irtypes.ExternalService) *networking.NetworkPolicy {
+	tcp, udp := core.ProtocolTCP, core.ProtocolUDP
+	dnsPort := intstr.FromInt(dnsPortNumber)
+	externalServiceRule := networking.NetworkPolicyEgressRule{}
+	for _, port := range externalService.Ports {
+		port := intstr.FromInt(int(port))
+		externalServiceRule.Ports = append(externalServiceRule.Ports, networking.NetworkPolicyPort{Protocol: &tcp, Port: &port})
+	}
+	for _, ip := range externalService.IPs {
+		externalServiceRule.To = append(externalServiceRule.To, networking.NetworkPolicyPeer{IPBlock: &networking.IPBlock{CIDR: ip + "/32"}})
+	}
+	egress := []networking.NetworkPolicyEgressRule{
+		{To: []networking.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}},
+		{Ports: []networking.NetworkPolicyPort{{Protocol: &udp, Port: &dnsPort}, {Protocol: &tcp, Port: &dnsPort}}},
+	}
+	if len(externalServiceRule.Ports) > 0 || len(externalServiceRule.To) > 0 {
+		egress = append(egress, externalServiceRule)
+	}
+	podSelector := metav1.LabelSelector{}
+	if len(externalService.Clients) > 0 {
+		podSelector.MatchExpressions = []metav1.LabelSelectorRequirement{{
+			Key:      selector,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   externalService.Clients,
+		}}
+	}
+	return &networking.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       networkPolicyKind,
+			APIVersion: networking.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: common.NormalizeForMetadataName(externalService.Name + egressPolicyNameSuffix),
+		},
+		Spec: networking.NetworkPolicySpec{
+			PodSelector: podSelector,
+			PolicyTypes: []networking.PolicyType{networking.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+}
+
 func getNetworkPolicyLabels(networks []string) map[string]string {
 	networklabels := map[string]string{}
 	for _, network := range networks {
@@ -172,6 +172,78 @@ func TestCreateNewResources(t *testing.T) {
 			}
 		}
 	})
+	t.Run("IR with some services on the same network", func(t *testing.T) {
+		// Setup
+		netPolicy := NetworkPolicy{}
+		ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+		svc1 := irtypes.NewServiceWithName("svc1")
+		svc1.Networks = []string{"backend"}
+		svc2 := irtypes.NewServiceWithName("svc2")
+		svc2.Networks = []string{"backend"}
+		ir.Services = map[string]irtypes.Service{svc1.Name: svc1, svc2.Name: svc2}
+		// Test
+		actual := netPolicy.createNewResources(ir, []string{"NetworkPolicy"}, collection.ClusterMetadata{})
+		if len(actual) != 1 {
+			t.Fatalf("Expected one network policy for the network. Actual: %v", actual)
+		}
+		if np := actual[0].(*networking.NetworkPolicy); np.Name != "backend" {
+			t.Fatalf("Expected the network policy of the network backend. Actual: %v", np)
+		}
+	})
+	t.Run("IR with a service that restricts its egress to its external endpoints", func(t *testing.T) {
+		// Setup
+		netPolicy := NetworkPolicy{}
+		oldir := irtypes.NewIR()
+		ir := irtypes.NewEnhancedIRFromIR(oldir)
+		svc1Name := "svc1"
+		svc1 := irtypes.NewServiceWithName(svc1Name)
+		svc1.EgressPolicy = true
+		svc1.ExternalEndpoints = []irtypes.ExternalEndpoint{
+			{Kind: "jms", URL: "tcp://mq.example.com:61616", Host: "mq.example.com", Port: 61616},
+			{Kind: "jms", URL: "ssl://mq.example.com:61616", Host: "mq.example.com", Port: 61616},
+			{Kind: "jms", URL: "java:comp/env/jms/OrdersConnectionFactory"},
+		}
+		ir.Services = map[string]irtypes.Service{svc1Name: svc1}
+		supKinds := []string{"NetworkPolicy"}
+		// Test
+		actual := netPolicy.createNewResources(ir, supKinds, collection.ClusterMetadata{})
+		if len(actual) != 1 {
+			t.Fatalf("Expected 1 network policy to be created. Actual: %v", actual)
+		}
+		np, ok := actual[0].(*networking.NetworkPolicy)
+		if !ok {
+			t.Fatalf("Expected a network policy. Actual: %T", actual[0])
+		}
+		if np.Name != svc1Name+"-egress" || len(np.Spec.PolicyTypes) != 1 || np.Spec.PolicyTypes[0] != networking.PolicyTypeEgress {
+			t.Fatalf("Expected an egress network policy named %s-egress. Actual: %+v", svc1Name, np)
+		}
+		if len(np.Spec.Egress) != 3 || len(np.Spec.Egress[2].Ports) != 1 || np.Spec.Egress[2].Ports[0].Port.IntValue() != 61616 {
+			t.Fatalf("Expected the egress to the namespace, to DNS and to the port 61616 only once. Actual: %+v", np.Spec.Egress)
+		}
+	})
+	t.Run("IR with an external service that restricts the egress of its clients", func(t *testing.T) {
+		// Setup
+		netPolicy := NetworkPolicy{}
+		ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+		ir.AddExternalService(irtypes.ExternalService{Name: "db2", IPs: []string{"10.1.2.3"}, Ports: []int32{50000}, Clients: []string{"orders"}, EgressPolicy: true})
+		ir.AddExternalService(irtypes.ExternalService{Name: "saas", Host: "api.example.com", Ports: []int32{443}})
+		supKinds := []string{"NetworkPolicy"}
+		// Test
+		actual := netPolicy.createNewResources(ir, supKinds, collection.ClusterMetadata{})
+		if len(actual) != 1 {
+			t.Fatalf("Expected 1 network policy to be created. Actual: %v", actual)
+		}
+		np, ok := actual[0].(*networking.NetworkPolicy)
+		if !ok {
+			t.Fatalf("Expected a network policy. Actual: %T", actual[0])
+		}
+		if np.Name != "db2-egress" || len(np.Spec.PodSelector.MatchExpressions) != 1 || np.Spec.PodSelector.MatchExpressions[0].Values[0] != "orders" {
+			t.Fatalf("Expected an egress network policy named db2-egress that selects the pods of orders. Actual: %+v", np)
+		}
+		if len(np.Spec.Egress) != 3 || len(np.Spec.Egress[2].To) != 1 || np.Spec.Egress[2].To[0].IPBlock.CIDR != "10.1.2.3/32" || np.Spec.Egress[2].Ports[0].Port.IntValue() != 50000 {
+			t.Fatalf("Expected the egress to the namespace, to DNS and to 10.1.2.3:50000 . Actual: %+v", np.Spec.Egress)
+		}
+	})
 }
 
 func TestConvertToClusterSupportedKinds(t *testing.T) {
@@ -79,6 +79,9 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 		if container.Build.ContainerBuildType == "" {
 			continue
 		}
+		if len(irpipeline.ImageNames) > 0 && !common.IsPresent(irpipeline.ImageNames, imageName) {
+			continue
+		}
 		i++
 		if container.Build.ContainerBuildType == irtypes.DockerfileContainerBuildType {
 			_, repoDir, _, gitRepoURL, branchName, err := common.GatherGitInfo(container.Build.ContextPath)
@@ -143,7 +146,7 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 					{Name: "source", Workspace: irpipeline.WorkspaceName},
 				},
 				Params: []v1beta1.Param{
-					{Name: "IMAGE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "$(params.image-registry-url)/" + imageName}},
+					{Name: "IMAGE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "$(params.image-registry-url)/" + common.TagNewImageName(imageName)}},
 					{Name: "DOCKERFILE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: dockerfilePath}},
 					{Name: "CONTEXT", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: contextPath}},
 				},
@@ -37,6 +37,8 @@ import (
 
 const (
 	routeKind = "Route"
+	// grpcBackendProtocolAnnotation makes the nginx ingress controller use gRPC to talk to the backends
+	grpcBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
 )
 
 // Service handles all objects related to a service
@@ -45,13 +47,14 @@ type Service struct {
 
 // getSupportedKinds returns supported kinds
 func (d *Service) getSupportedKinds() []string {
-	return []string{common.ServiceKind, common.IngressKind, routeKind}
+	return []string{common.ServiceKind, common.IngressKind, routeKind, endpointSliceKind, endpointsKind}
 }
 
 // createNewResources converts IR to runtime objects
 func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
 	ingressEnabled := false
+	aliases := map[string]bool{}
 	for _, service := range ir.Services {
 		exposeobjectcreated := false
 		if _, _, _, st := d.getExposeInfo(service); st != "" || service.OnlyIngress {
@@ -79,14 +82,28 @@ func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 		}
 		obj := d.createService(service)
 		objs = append(objs, obj)
+		for _, alias := range service.NetworkAliases {
+			if _, ok := ir.Services[alias]; ok || aliases[alias] {
+				logrus.Debugf("not creating a service for the alias %s of the service %s , since there is another service with that name", alias, service.Name)
+				continue
+			}
+			aliases[alias] = true
+			objs = append(objs, d.createAliasService(service, alias))
+		}
+	}
+	for _, externalService := range ir.ExternalServices {
+		objs = append(objs, d.createExternalServiceResources(externalService, supportedKinds)...)
 	}
 
-	// Create one ingress for all services
+	// Create one ingress for all services, and one for the gRPC services since they need a different backend protocol
 	if ingressEnabled {
-		obj := d.createIngress(ir, targetCluster)
+		obj := d.createIngress(ir, targetCluster, false)
 		if obj != nil {
 			objs = append(objs, obj)
 		}
+		if grpcObj := d.createIngress(ir, targetCluster, true); grpcObj != nil {
+			objs = append(objs, grpcObj)
+		}
 	}
 
 	return objs
@@ -324,8 +341,9 @@ func (d *Service) createRoute(irName string, service irtypes.Service, port core.
 	return route
 }
 
-// createIngress creates a single ingress for all services
-func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) *networking.Ingress {
+// createIngress creates a single ingress for all services.
+// If grpc is true, the ingress only has the ports serving HTTP/2 over cleartext, otherwise it only has the other ports.
+func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata, grpc bool) *networking.Ingress {
 	pathType := networking.PathTypePrefix
 
 	hostHTTPIngressPaths := map[string][]networking.HTTPIngressPath{} //[hostprefix]
@@ -339,6 +357,9 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 			if relPaths[i] == "" {
 				continue
 			}
+			if isH2C := servicePort.AppProtocol != nil && *servicePort.AppProtocol == irtypes.H2CAppProtocol; isH2C != grpc {
+				continue
+			}
 			backendPort := networking.ServiceBackendPort{Name: servicePort.Name}
 			if servicePort.Name == "" {
 				backendPort = networking.ServiceBackendPort{Number: servicePort.Port}
@@ -370,6 +391,11 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 	quesKeyClass := common.JoinQASubKeys(qaId, common.ConfigIngressClassNameKeySuffix)
 	descClass := "Provide the Ingress class name for ingress"
 	ingressClassName := qaengine.FetchStringAnswer(quesKeyClass, descClass, []string{"Leave empty to use the cluster default"}, "")
+	if grpc {
+		quesKeyClass = common.JoinQASubKeys(qaId, common.ConfigIngressGRPCClassNameKeySuffix)
+		descClass = "Provide the Ingress class name for the ingress of the gRPC services"
+		ingressClassName = qaengine.FetchStringAnswer(quesKeyClass, descClass, []string{"The ingress controller must support gRPC backends", "Leave empty to use the cluster default"}, ingressClassName)
+	}
 
 	// Configure the rule with the above fan-out paths
 	rules := []networking.IngressRule{}
@@ -405,14 +431,20 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 	}
 
 	ingressName := ir.Name
+	annotations := map[string]string{}
+	if grpc {
+		ingressName = ir.Name + "-grpc"
+		annotations[grpcBackendProtocolAnnotation] = "GRPC"
+	}
 	ingress := networking.Ingress{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       common.IngressKind,
 			APIVersion: networking.SchemeGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   ingressName,
-			Labels: getServiceLabels(ingressName),
+			Name:        ingressName,
+			Labels:      getServiceLabels(ingressName),
+			Annotations: annotations,
 		},
 		Spec: networking.IngressSpec{
 			Rules: rules,
@@ -445,7 +477,21 @@ func (d *Service) createService(service irtypes.Service) *core.Service {
 			Ports:    ports,
 		},
 	}
-	if len(ports) == 0 {
+	if len(ports) == 0 || (service.Headless && serviceType == core.ServiceTypeClusterIP) {
+		svc.Spec.ClusterIP = "None"
+	}
+	if service.SessionAffinity {
+		svc.Spec.SessionAffinity = core.ServiceAffinityClientIP
+	}
+	return svc
+}
+
+// createAliasService creates a service with another name for the pods of the service, which is only reachable in the cluster
+func (d *Service) createAliasService(service irtypes.Service, alias string) *core.Service {
+	svc := d.createService(service)
+	svc.Name = alias
+	svc.Spec.Type = core.ServiceTypeClusterIP
+	if len(svc.Spec.Ports) == 0 || service.Headless {
 		svc.Spec.ClusterIP = "None"
 	}
 	return svc
@@ -472,6 +518,10 @@ func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.Se
 			Port:       forwarding.ServicePort.Number,
 			TargetPort: targetPort,
 		}
+		if forwarding.AppProtocol != "" {
+			appProtocol := forwarding.AppProtocol
+			servicePort.AppProtocol = &appProtocol
+		}
 		switch forwarding.ServiceType {
 		case core.ServiceTypeLoadBalancer:
 			serviceType = forwarding.ServiceType
@@ -0,0 +1,52 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestCreateGRPCService(t *testing.T) {
+	service := irtypes.NewServiceWithName("greeter")
+	service.Headless = true
+	service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Name: "grpc", Number: 50051}, PodPort: networking.ServiceBackendPort{Number: 50051}, ServiceType: core.ServiceTypeClusterIP, AppProtocol: irtypes.H2CAppProtocol},
+		{ServicePort: networking.ServiceBackendPort{Name: "metrics", Number: 9090}, PodPort: networking.ServiceBackendPort{Number: 9090}, ServiceType: core.ServiceTypeClusterIP},
+	}
+	svc := (&Service{}).createService(service)
+	if svc.Spec.ClusterIP != "None" {
+		t.Fatalf("expected the gRPC service to be headless. Actual cluster ip: %q", svc.Spec.ClusterIP)
+	}
+	if len(svc.Spec.Ports) != 2 {
+		t.Fatalf("expected both ports in the service. Actual: %+v", svc.Spec.Ports)
+	}
+	if appProtocol := svc.Spec.Ports[0].AppProtocol; appProtocol == nil || *appProtocol != irtypes.H2CAppProtocol {
+		t.Fatalf("expected the gRPC port to be an HTTP/2 port. Actual: %v", appProtocol)
+	}
+	if appProtocol := svc.Spec.Ports[1].AppProtocol; appProtocol != nil {
+		t.Fatalf("expected the metrics port to not have an application protocol. Actual: %s", *appProtocol)
+	}
+
+	service.ServiceToPodPortForwardings[0].ServiceType = core.ServiceTypeLoadBalancer
+	if svc := (&Service{}).createService(service); svc.Spec.ClusterIP == "None" {
+		t.Fatalf("expected a load balancer service to not be headless. Actual: %+v", svc.Spec)
+	}
+}
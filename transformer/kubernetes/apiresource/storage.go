@@ -20,6 +20,7 @@ import (
 	"github.com/konveyor/move2kube/common"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -38,6 +39,22 @@ func (s *Storage) getSupportedKinds() []string {
 // createNewResources converts IR objects to runtime objects
 func (s *Storage) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
+	storageClassName := ""
+	perClaimStorageClass := false
+	if storageClasses := targetCluster.Spec.StorageClasses; len(storageClasses) > 0 {
+		pvcCount := 0
+		for _, stObj := range ir.Storages {
+			if stObj.StorageType == irtypes.PVCKind && stObj.StorageClassName == nil {
+				pvcCount++
+			}
+		}
+		if pvcCount > 1 && len(storageClasses) > 1 {
+			perClaimStorageClass = commonqa.PerClaimStorageClass()
+		}
+		if pvcCount > 0 && !perClaimStorageClass {
+			storageClassName = commonqa.StorageClass("", storageClasses)
+		}
+	}
 	for _, stObj := range ir.Storages {
 		if stObj.StorageType == irtypes.ConfigMapKind {
 			objs = append(objs, s.createConfigMap(stObj))
@@ -46,6 +63,15 @@ func (s *Storage) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 			objs = append(objs, s.createSecret(stObj))
 		}
 		if stObj.StorageType == irtypes.PVCKind {
+			if stObj.StorageClassName == nil {
+				if perClaimStorageClass {
+					claimStorageClassName := commonqa.StorageClass(stObj.Name, targetCluster.Spec.StorageClasses)
+					stObj.StorageClassName = &claimStorageClassName
+				} else if storageClassName != "" {
+					claimStorageClassName := storageClassName
+					stObj.StorageClassName = &claimStorageClassName
+				}
+			}
 			objs = append(objs, s.createPVC(stObj))
 		}
 	}
@@ -189,7 +215,7 @@ func convertVolumeBySupportedKind(volume core.Volume, cluster collecttypes.Clust
 		}
 		return volume
 	}
-	if volume.VolumeSource.HostPath != nil || volume.VolumeSource.EmptyDir != nil {
+	if volume.VolumeSource.HostPath != nil || volume.VolumeSource.EmptyDir != nil || volume.VolumeSource.CSI != nil {
 		return volume
 	}
 	logrus.Warnf("Unsupported storage type (volume) detected")
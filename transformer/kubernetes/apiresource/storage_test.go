@@ -0,0 +1,66 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestConvertVolumeBySupportedKind(t *testing.T) {
+	readOnly := true
+	testCases := []struct {
+		name     string
+		volume   core.Volume
+		expected core.Volume
+	}{
+		{
+			name:     "keep an empty dir volume",
+			volume:   core.Volume{Name: "cache", VolumeSource: core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}}},
+			expected: core.Volume{Name: "cache", VolumeSource: core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}}},
+		},
+		{
+			name: "keep a csi volume",
+			volume: core.Volume{Name: "vault-secrets", VolumeSource: core.VolumeSource{CSI: &core.CSIVolumeSource{
+				Driver:           "secrets-store.csi.k8s.io",
+				ReadOnly:         &readOnly,
+				VolumeAttributes: map[string]string{"secretProviderClass": "api-vault"},
+			}}},
+			expected: core.Volume{Name: "vault-secrets", VolumeSource: core.VolumeSource{CSI: &core.CSIVolumeSource{
+				Driver:           "secrets-store.csi.k8s.io",
+				ReadOnly:         &readOnly,
+				VolumeAttributes: map[string]string{"secretProviderClass": "api-vault"},
+			}}},
+		},
+		{
+			name:     "drop an unsupported volume",
+			volume:   core.Volume{Name: "shared", VolumeSource: core.VolumeSource{NFS: &core.NFSVolumeSource{Server: "nfs.example.com", Path: "/shared"}}},
+			expected: core.Volume{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := convertVolumeBySupportedKind(testCase.volume, collecttypes.ClusterMetadataSpec{})
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Fatalf("wrong volume. Difference:\n%s", diff)
+			}
+		})
+	}
+}
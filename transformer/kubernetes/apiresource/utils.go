@@ -17,6 +17,7 @@
 package apiresource
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -24,13 +25,18 @@ import (
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/yamlnode"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema/fixer"
+	"github.com/konveyor/move2kube/types"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 )
 
 // TransformIRAndPersist transforms IR to yamls and writes to filesystem
@@ -58,8 +64,152 @@ func TransformIRAndPersist(ir irtypes.EnhancedIR, outputPath string, apis []IAPI
 
 // TransformObjsAndPersist transforms versions of yamls in current directory and writes to filesystem
 func TransformObjsAndPersist(inputPath, outputPath string, apis []IAPIResource, targetCluster collecttypes.ClusterMetadata) (files []string, err error) {
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("Unable to create deploy directory at path %s Error: %q", outputPath, err)
+	}
+	convertedObjs := transformObjs(k8sschema.GetKubernetesObjsInDir(inputPath), apis, targetCluster)
+	filesWritten, err := writeObjects(outputPath, convertedObjs)
+	if err != nil {
+		logrus.Errorf("Failed to write the transformed objects to the directory at path %s . Error: %q", outputPath, err)
+		return nil, err
+	}
+	return filesWritten, nil
+}
+
+// TransformObjsInSourceAndPersist transforms versions of yamls in current directory and writes to filesystem.
+// The transformed objects are merged back into the documents they were read from, so the comments, field order,
+// fields unknown to the schema and file names of the source yamls are preserved.
+// The move2kube annotations on the resources are used to ignore, keep as is, expose and unexpose them.
+func TransformObjsInSourceAndPersist(inputPath, outputPath string, apis []IAPIResource, targetCluster collecttypes.ClusterMetadata) (files []string, err error) {
+	filePaths, err := common.GetFilesByExtInCurrDir(inputPath, []string{".yml", ".yaml"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the yaml files in the directory %s . Error: %q", inputPath, err)
+	}
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return nil, fmt.Errorf("failed to create the output directory at path %s . Error: %q", outputPath, err)
+	}
+	filesWritten := []string{}
+	filesDocs := map[string][]*yaml.Node{}
+	crds := k8sschema.NewCRDs(targetCluster.Spec)
+	intents := newExposeIntents()
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			logrus.Debugf("Failed to read the yaml file at path %q Error: %q", filePath, err)
+			continue
+		}
+		docs, err := yamlnode.ParseDocuments(data)
+		if err != nil {
+			logrus.Debugf("Failed to parse the yaml file at path %q Error: %q", filePath, err)
+			continue
+		}
+		filesDocs[filePath] = docs
+		for _, doc := range docs {
+			intents.collectExposeIntents(doc)
+			if resource, ok := decodeK8sResourceFromDoc(doc); ok {
+				crds.AddFromResources([]k8sschema.K8sResourceT{resource})
+			}
+		}
+	}
+	for _, filePath := range filePaths {
+		docs, ok := filesDocs[filePath]
+		if !ok {
+			continue
+		}
+		foundK8sObjs := false
+		docsToWrite := []*yaml.Node{}
+		objsToWrite := []runtime.Object{}
+		for _, doc := range docs {
+			if isIgnoredDoc(doc) {
+				kind, name := getKindAndNameFromDoc(doc)
+				logrus.Infof("Ignoring the resource %s of kind %s in the file at path %s since it has the annotation %s", name, kind, filePath, common.IgnoreAnnotation)
+				foundK8sObjs = true
+				continue
+			}
+			if isUntransformedDoc(doc) {
+				foundK8sObjs = true
+				docsToWrite = append(docsToWrite, doc)
+				continue
+			}
+			if !intents.applyExposeIntents(doc) {
+				foundK8sObjs = true
+				continue
+			}
+			obj, err := decodeK8sObjFromDoc(doc)
+			if err != nil {
+				resource, ok := decodeK8sResourceFromDoc(doc)
+				if !ok {
+					logrus.Debugf("Failed to decode a document in the file at path %q as a k8s object. Error: %q", filePath, err)
+					docsToWrite = append(docsToWrite, doc)
+					continue
+				}
+				// custom resources and their definitions
+				foundK8sObjs = true
+				if !k8sschema.IsCRD(resource) {
+					transformCustomResource(doc, resource, crds, targetCluster)
+				}
+				docsToWrite = append(docsToWrite, doc)
+				continue
+			}
+			foundK8sObjs = true
+			originalValue, err := objToValue(obj)
+			if err != nil {
+				logrus.Debugf("Failed to get the fields of the object in the file at path %s . Error: %q", filePath, err)
+			}
+			convertedObjs := transformObjs([]runtime.Object{obj}, apis, targetCluster)
+			if len(convertedObjs) != 1 || convertedObjs[0].GetObjectKind().GroupVersionKind().Kind != obj.GetObjectKind().GroupVersionKind().Kind {
+				// the object was converted into other kinds, so there is no source document to merge into
+				objsToWrite = append(objsToWrite, convertedObjs...)
+				continue
+			}
+			if err := mergeObjIntoDoc(doc, originalValue, convertedObjs[0]); err != nil {
+				logrus.Errorf("Failed to merge the transformed object into its source document in the file at path %s . Writing it as a new file. Error: %q", filePath, err)
+				objsToWrite = append(objsToWrite, convertedObjs...)
+				continue
+			}
+			docsToWrite = append(docsToWrite, doc)
+		}
+		if !foundK8sObjs {
+			continue
+		}
+		if len(docsToWrite) != 0 {
+			docsBytes, err := yamlnode.EncodeDocuments(docsToWrite)
+			if err != nil {
+				logrus.Errorf("Failed to encode the transformed documents of the file at path %s . Error: %q", filePath, err)
+				continue
+			}
+			yamlPath := filepath.Join(outputPath, filepath.Base(filePath))
+			if err := os.WriteFile(yamlPath, docsBytes, common.DefaultFilePermission); err != nil {
+				logrus.Errorf("failed to write the yaml to file at path %s . Error: %q", yamlPath, err)
+				continue
+			}
+			filesWritten = append(filesWritten, yamlPath)
+		}
+		newFilesWritten, err := writeObjects(outputPath, objsToWrite)
+		if err != nil {
+			logrus.Errorf("Failed to write the transformed objects to the directory at path %s . Error: %q", outputPath, err)
+			continue
+		}
+		filesWritten = append(filesWritten, newFilesWritten...)
+	}
+	if ir, ok := intents.getIRForExposedServices(inputPath); ok {
+		exposeObjs := (&APIResource{IAPIResource: new(Service)}).convertIRToObjects(ir, targetCluster)
+		convertedObjs, err := convertVersion(exposeObjs, targetCluster.Spec)
+		if err != nil {
+			logrus.Errorf("Failed to fix, convert and transform the objects exposing the annotated services. Error: %q", err)
+		}
+		newFilesWritten, err := writeObjects(outputPath, convertedObjs)
+		if err != nil {
+			return filesWritten, fmt.Errorf("failed to write the objects exposing the annotated services to the directory at path %s . Error: %q", outputPath, err)
+		}
+		filesWritten = append(filesWritten, newFilesWritten...)
+	}
+	return filesWritten, nil
+}
+
+func transformObjs(pendingObjs []runtime.Object, apis []IAPIResource, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	targetObjs := []runtime.Object{}
-	if pendingObjs := k8sschema.GetKubernetesObjsInDir(inputPath); len(pendingObjs) != 0 {
+	if len(pendingObjs) != 0 {
 		for _, apiResource := range apis {
 			var newObjs []runtime.Object
 			newObjs, pendingObjs = (&APIResource{IAPIResource: apiResource}).convertObjectsToSupportedVersion(pendingObjs, targetCluster)
@@ -67,20 +217,91 @@ func TransformObjsAndPersist(inputPath, outputPath string, apis []IAPIResource,
 		}
 		targetObjs = append(targetObjs, pendingObjs...)
 	}
-	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
-		logrus.Errorf("Unable to create deploy directory at path %s Error: %q", outputPath, err)
-	}
 	logrus.Debugf("Total %d services to be serialized.", len(targetObjs))
 	convertedObjs, err := convertVersion(targetObjs, targetCluster.Spec)
 	if err != nil {
 		logrus.Errorf("Failed to fix, convert and transform the objects. Error: %q", err)
 	}
-	filesWritten, err := writeObjects(outputPath, convertedObjs)
+	return convertedObjs
+}
+
+// transformCustomResource validates the custom resource and converts its document to the version supported by the target cluster
+func transformCustomResource(doc *yaml.Node, resource k8sschema.K8sResourceT, crds *k8sschema.CRDs, targetCluster collecttypes.ClusterMetadata) {
+	kind, apiVersion, name, err := k8sschema.GetInfoFromK8sResource(resource)
+	if err != nil {
+		logrus.Debugf("failed to get the kind, apiVersion and name of the custom resource. Error: %q", err)
+		return
+	}
+	if _, ok := crds.Get(apiVersion, kind); !ok {
+		logrus.Warnf("There is no custom resource definition for the custom resource %s of kind %s and apiVersion %s . Writing as is without validation.", name, kind, apiVersion)
+		return
+	}
+	if err := crds.ValidateCustomResource(resource); err != nil {
+		logrus.Warnf("%s", err)
+	}
+	newAPIVersion, err := crds.ConvertCustomResourceToSupportedVersion(resource, targetCluster.Spec)
+	if err != nil {
+		logrus.Warnf("Unable to convert the custom resource %s of kind %s to a version supported by the cluster. Writing as is. Error: %q", name, kind, err)
+		return
+	}
+	if newAPIVersion == apiVersion {
+		return
+	}
+	logrus.Debugf("converting the custom resource %s of kind %s from the version %s to %s", name, kind, apiVersion, newAPIVersion)
+	setMappingScalar(getMappingValue(doc), "apiVersion", newAPIVersion)
+}
+
+// decodeK8sResourceFromDoc decodes a document that has an apiVersion and kind into a map
+func decodeK8sResourceFromDoc(doc *yaml.Node) (k8sschema.K8sResourceT, bool) {
+	resource := k8sschema.K8sResourceT{}
+	if err := doc.Decode(&resource); err != nil {
+		return nil, false
+	}
+	_, apiVersion, _, err := k8sschema.GetInfoFromK8sResource(resource)
+	if err != nil {
+		return nil, false
+	}
+	if gv, err := schema.ParseGroupVersion(apiVersion); err != nil || gv.Group == types.GroupName {
+		return nil, false
+	}
+	return resource, true
+}
+
+func decodeK8sObjFromDoc(doc *yaml.Node) (runtime.Object, error) {
+	docBytes, err := yamlnode.EncodeDocuments([]*yaml.Node{doc})
 	if err != nil {
-		logrus.Errorf("Failed to write the transformed objects to the directory at path %s . Error: %q", outputPath, err)
 		return nil, err
 	}
-	return filesWritten, nil
+	obj, _, err := serializer.NewCodecFactory(k8sschema.GetSchema()).UniversalDeserializer().Decode(docBytes, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if obj.GetObjectKind().GroupVersionKind().Group == types.GroupName {
+		return nil, fmt.Errorf("the document is a %s object and not a k8s object", types.AppName)
+	}
+	return obj, nil
+}
+
+// mergeObjIntoDoc merges the object into its source document.
+// The fields of the document that were not decoded into the original object, since they are unknown to the schema, are kept.
+func mergeObjIntoDoc(doc *yaml.Node, originalValue interface{}, obj runtime.Object) error {
+	objValue, err := objToValue(obj)
+	if err != nil {
+		return err
+	}
+	return yamlnode.MergeKeepingUnknown(doc, originalValue, objValue)
+}
+
+func objToValue(obj runtime.Object) (interface{}, error) {
+	objJSONBytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the object to json. Error: %q", err)
+	}
+	var objValue interface{}
+	if err := yaml.Unmarshal(objJSONBytes, &objValue); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the json of the object. Error: %q", err)
+	}
+	return objValue, nil
 }
 
 // writeObjects writes the runtime objects to yaml files
@@ -89,7 +310,7 @@ func writeObjects(outputPath string, objs []runtime.Object) ([]string, error) {
 		return nil, err
 	}
 	filesWritten := []string{}
-	for _, obj := range objs {
+	for _, obj := range decorateObjects(objs) {
 		objYamlBytes, err := common.MarshalObjToYaml(obj)
 		if err != nil {
 			logrus.Errorf("failed to marshal the runtime.Object to yaml. Object:\n%+v\nError: %q", obj, err)
@@ -0,0 +1,87 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+)
+
+func TestTransformObjsInSourceAndPersist(t *testing.T) {
+	t.Run("convert an ingress to networking.k8s.io/v1 and keep the unknown fields", func(t *testing.T) {
+		input := `# the ingress of the web app
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  futureField: keep
+  rules:
+  - host: web.example.com
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: web # the frontend
+          servicePort: 8080
+`
+		want := `# the ingress of the web app
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  futureField: keep
+  rules:
+  - host: web.example.com
+    http:
+      paths:
+      - path: /
+        backend:
+          service:
+            name: web
+            port:
+              number: 8080
+        pathType: Prefix
+`
+		targetCluster := collecttypes.ClusterMetadata{Spec: collecttypes.ClusterMetadataSpec{
+			APIKindVersionMap: map[string][]string{"Ingress": {"networking.k8s.io/v1"}},
+		}}
+		inputDir, outputDir := t.TempDir(), t.TempDir()
+		if err := os.WriteFile(filepath.Join(inputDir, "web-ingress.yaml"), []byte(input), 0644); err != nil {
+			t.Fatalf("failed to write the input yaml. Error: %q", err)
+		}
+		files, err := TransformObjsInSourceAndPersist(inputDir, outputDir, []IAPIResource{new(Deployment), new(Service)}, targetCluster)
+		if err != nil {
+			t.Fatalf("failed to transform the objects. Error: %q", err)
+		}
+		if len(files) != 1 {
+			t.Fatalf("expected a single file to be written. Actual: %+v", files)
+		}
+		got, err := os.ReadFile(files[0])
+		if err != nil {
+			t.Fatalf("failed to read the output yaml. Error: %q", err)
+		}
+		if string(got) != want {
+			t.Fatalf("the converted ingress is incorrect. Difference:\n%s", cmp.Diff(want, string(got)))
+		}
+	})
+}
@@ -151,5 +151,9 @@ func (t *ArgoCD) setupEnhancedIR(oldir irtypes.IR, projectName string) irtypes.E
 	ir.ArgoCDResources = irtypes.ArgoCDResources{
 		Applications: []irtypes.Application{{Name: appName}},
 	}
+	// Each migration wave gets an application of its own, so the waves can be synced separately
+	for _, wave := range common.GetWaves() {
+		ir.ArgoCDResources.Applications = append(ir.ArgoCDResources.Applications, irtypes.Application{Name: p(baseAppName + "-" + wave)})
+	}
 	return ir
 }
@@ -0,0 +1,145 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	veleroAPIVersion          = "velero.io/v1"
+	veleroNamespace           = "velero"
+	veleroBackupVolumesAnnot  = "backup.velero.io/backup-volumes"
+	serviceLabel              = types.GroupName + "/service"
+	defaultBackupSchedule     = "0 2 * * *"
+	defaultBackupTTL          = "720h0m0s"
+	defaultBackupBucket       = "move2kube-backups"
+	backupStorageLocationName = "default"
+)
+
+// wireBackup annotates the stateful services for Velero backups and returns the Velero resources to generate
+func wireBackup(ir irtypes.IR, projectName string) (irtypes.IR, []customResourceT) {
+	customResources := []customResourceT{}
+	claims := map[string]bool{}
+	for _, storage := range ir.Storages {
+		if storage.StorageType == irtypes.PVCKind {
+			claims[storage.Name] = true
+		}
+	}
+	statefulServiceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if len(getBackupVolumes(service, claims)) > 0 {
+			statefulServiceNames = append(statefulServiceNames, serviceName)
+		}
+	}
+	if len(statefulServiceNames) == 0 {
+		return ir, customResources
+	}
+	sort.Strings(statefulServiceNames)
+	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
+		common.ConfigBackupServicesKey,
+		"Select the stateful services whose volumes should be backed up using Velero :",
+		[]string{"The pods are annotated for Velero file system backups and a backup schedule is generated"},
+		statefulServiceNames,
+		statefulServiceNames,
+	)
+	if len(selectedServiceNames) == 0 {
+		return ir, customResources
+	}
+	for _, serviceName := range selectedServiceNames {
+		service, ok := ir.Services[serviceName]
+		if !ok {
+			continue
+		}
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		service.Annotations[veleroBackupVolumesAnnot] = strings.Join(getBackupVolumes(service, claims), ",")
+		ir.Services[serviceName] = service
+	}
+	schedule := qaengine.FetchStringAnswer(
+		common.ConfigBackupScheduleKey,
+		"Enter the cron schedule for the backups :",
+		[]string{"Ex : " + defaultBackupSchedule + " (every day at 2 AM)"},
+		defaultBackupSchedule,
+	)
+	ttl := qaengine.FetchStringAnswer(
+		common.ConfigBackupTTLKey,
+		"Enter how long the backups should be retained :",
+		[]string{"Ex : " + defaultBackupTTL + " (30 days)"},
+		defaultBackupTTL,
+	)
+	customResources = append(customResources, customResourceT{
+		APIVersion: veleroAPIVersion,
+		Kind:       "Schedule",
+		Metadata:   map[string]interface{}{"name": common.MakeStringDNSSubdomainNameCompliant(projectName + "-backup"), "namespace": veleroNamespace},
+		Spec: map[string]interface{}{
+			"schedule": schedule,
+			"template": map[string]interface{}{
+				"ttl": ttl,
+				"labelSelector": map[string]interface{}{"matchExpressions": []interface{}{
+					map[string]interface{}{"key": serviceLabel, "operator": "In", "values": selectedServiceNames},
+				}},
+				"defaultVolumesToFsBackup": false,
+				"storageLocation":          backupStorageLocationName,
+			},
+		},
+	})
+	if qaengine.FetchBoolAnswer(
+		common.ConfigBackupStorageLocationKey,
+		"Generate a Velero backup storage location stub?",
+		[]string{"Skip this if Velero is already configured with a backup storage location in the target cluster"},
+		false,
+	) {
+		bucket := qaengine.FetchStringAnswer(
+			common.ConfigBackupBucketKey,
+			"Enter the name of the bucket the backups should be stored in :",
+			[]string{"The provider and credentials of the backup storage location need to be filled in"},
+			defaultBackupBucket,
+		)
+		customResources = append(customResources, customResourceT{
+			APIVersion: veleroAPIVersion,
+			Kind:       "BackupStorageLocation",
+			Metadata:   map[string]interface{}{"name": backupStorageLocationName, "namespace": veleroNamespace},
+			Spec: map[string]interface{}{
+				"provider":      "aws",
+				"objectStorage": map[string]interface{}{"bucket": bucket},
+				"config":        map[string]interface{}{"region": "us-east-1"},
+			},
+		})
+	}
+	logrus.Debugf("Configured Velero backups for the services %+v", selectedServiceNames)
+	return ir, customResources
+}
+
+// getBackupVolumes returns the names of the volumes of the service that are backed by persistent volume claims
+func getBackupVolumes(service irtypes.Service, claims map[string]bool) []string {
+	volumes := []string{}
+	for _, volume := range service.Volumes {
+		if volume.PersistentVolumeClaim != nil && claims[volume.PersistentVolumeClaim.ClaimName] {
+			volumes = append(volumes, volume.Name)
+		}
+	}
+	return volumes
+}
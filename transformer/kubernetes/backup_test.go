@@ -0,0 +1,96 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestWireBackup(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.backup.schedule="0 4 * * *"`,
+		`move2kube.backup.storagelocation=true`,
+		`move2kube.backup.bucket="acme-backups"`,
+	}, nil, nil, false, false)
+
+	ir := irtypes.NewIR()
+	ir.Storages = []irtypes.Storage{{Name: "db-data", StorageType: irtypes.PVCKind}}
+	db := irtypes.NewServiceWithName("db")
+	db.Volumes = []core.Volume{
+		{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "db-data"}}},
+		{Name: "tmp", VolumeSource: core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}}},
+	}
+	ir.Services["db"] = db
+	ir.Services["web"] = irtypes.NewServiceWithName("web")
+
+	ir, customResources := wireBackup(ir, "My Project")
+	if annotation := ir.Services["db"].Annotations[veleroBackupVolumesAnnot]; annotation != "data" {
+		t.Fatalf("expected only the volume backed by a claim to be backed up. Actual: %q", annotation)
+	}
+	if _, ok := ir.Services["web"].Annotations[veleroBackupVolumesAnnot]; ok {
+		t.Fatalf("expected the stateless service to not be annotated. Actual: %+v", ir.Services["web"].Annotations)
+	}
+	if len(customResources) != 2 || customResources[0].Kind != "Schedule" || customResources[1].Kind != "BackupStorageLocation" {
+		t.Fatalf("expected a schedule and a backup storage location. Actual: %+v", customResources)
+	}
+	schedule := customResources[0]
+	if schedule.Metadata["name"] != "my-project-backup" || schedule.Spec["schedule"] != "0 4 * * *" {
+		t.Fatalf("the schedule is incorrect. Actual: %+v", schedule)
+	}
+	template := schedule.Spec["template"].(map[string]interface{})
+	if template["ttl"] != defaultBackupTTL {
+		t.Fatalf("expected the default retention. Actual: %v", template["ttl"])
+	}
+	expectedSelector := map[string]interface{}{"matchExpressions": []interface{}{
+		map[string]interface{}{"key": serviceLabel, "operator": "In", "values": []string{"db"}},
+	}}
+	if diff := cmp.Diff(expectedSelector, template["labelSelector"]); diff != "" {
+		t.Fatalf("the schedule does not select the backed up services. Difference:\n%s", diff)
+	}
+	if bucket := customResources[1].Spec["objectStorage"].(map[string]interface{})["bucket"]; bucket != "acme-backups" {
+		t.Fatalf("the bucket of the backup storage location is incorrect. Actual: %v", bucket)
+	}
+
+	outputPath := t.TempDir()
+	files, err := persistCustomResources(outputPath, customResources)
+	if err != nil {
+		t.Fatalf("failed to write the Velero resources. Error: %q", err)
+	}
+	expectedFiles := []string{filepath.Join(outputPath, "my-project-backup-schedule.yaml"), filepath.Join(outputPath, "default-backupstoragelocation.yaml")}
+	if diff := cmp.Diff(expectedFiles, files); diff != "" {
+		t.Fatalf("the Velero resources were written to the wrong files. Difference:\n%s", diff)
+	}
+}
+
+func TestWireBackupWithoutStatefulServices(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	ir := irtypes.NewIR()
+	ir.Services["web"] = irtypes.NewServiceWithName("web")
+	if _, customResources := wireBackup(ir, "myproject"); len(customResources) != 0 {
+		t.Fatalf("expected no Velero resources when there are no stateful services. Actual: %+v", customResources)
+	}
+}
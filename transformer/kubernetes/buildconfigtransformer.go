@@ -171,7 +171,7 @@ func (t *BuildConfig) setupEnhancedIR(oldir irtypes.IR, planName string) irtypes
 		if irContainer.Build.ContextPath == "" {
 			continue
 		}
-		imageStreamName, imageStreamTag := new(apiresource.ImageStream).GetImageStreamNameAndTag(imageName)
+		imageStreamName, imageStreamTag := new(apiresource.ImageStream).GetImageStreamNameAndTag(common.TagNewImageName(imageName))
 		_, _, gitHostName, gitURL, _, _ := common.GatherGitInfo(irContainer.Build.ContextPath)
 		if gitURL == "" {
 			// No git repo. Create build config and secrets anyway with placeholders.
@@ -24,6 +24,7 @@ import (
 	"github.com/konveyor/move2kube/qaengine"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
 
 	"github.com/sirupsen/logrus"
 )
@@ -41,6 +42,12 @@ const (
 	ClusterMetadata transformertypes.ConfigType = "ClusterMetadata"
 )
 
+func init() {
+	if err := artifacts.RegisterConfigType(ClusterMetadata, collecttypes.ClusterMetadata{}, "Metadata of the selected target cluster"); err != nil {
+		logrus.Errorf("failed to register the config type %s . Error: %q", ClusterMetadata, err)
+	}
+}
+
 // ClusterSelectorTransformer implements Transformer interface
 type ClusterSelectorTransformer struct {
 	Config   transformertypes.Transformer
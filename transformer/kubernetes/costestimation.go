@@ -0,0 +1,194 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// PriceSheetKind is the kind for PriceSheet yamls
+	PriceSheetKind = "PriceSheet"
+
+	defaultCostEstimationOutputPath = common.DeployDir + string(os.PathSeparator) + "cost-estimation"
+	costEstimationReportFileName    = "README.md"
+	noPriceSheet                    = "none"
+	hoursPerMonth                   = 730
+	bytesPerGiB                     = 1024 * 1024 * 1024
+)
+
+var (
+	// defaultCPURequest is assumed for containers without a cpu request
+	defaultCPURequest = resource.MustParse("100m")
+	// defaultMemoryRequest is assumed for containers without a memory request
+	defaultMemoryRequest = resource.MustParse("128Mi")
+)
+
+// PriceSheetT is the file format for the price sheets used to estimate the cost of the generated workloads
+type PriceSheetT struct {
+	metav1.TypeMeta   `yaml:",inline" json:",inline"`
+	metav1.ObjectMeta `yaml:"metadata" json:"metadata"`
+	Spec              PriceSheetSpecT `yaml:"spec" json:"spec"`
+}
+
+// PriceSheetSpecT stores the prices of the compute and storage resources
+type PriceSheetSpecT struct {
+	Description     string  `yaml:"description,omitempty" json:"description,omitempty"`
+	Currency        string  `yaml:"currency" json:"currency"`
+	CPUCoreHour     float64 `yaml:"cpuCoreHour" json:"cpuCoreHour"`
+	MemoryGiBHour   float64 `yaml:"memoryGiBHour" json:"memoryGiBHour"`
+	StorageGiBMonth float64 `yaml:"storageGiBMonth" json:"storageGiBMonth"`
+}
+
+// collectPriceSheets returns the price sheets in the directory
+func collectPriceSheets(dir string) []PriceSheetT {
+	priceSheets := []PriceSheetT{}
+	yamlPaths, err := common.GetFilesByExt(dir, []string{".yaml", ".yml"})
+	if err != nil {
+		logrus.Debugf("Unable to find the price sheets in the directory %s . Error: %q", dir, err)
+		return priceSheets
+	}
+	for _, yamlPath := range yamlPaths {
+		priceSheet := PriceSheetT{}
+		if err := common.ReadMove2KubeYamlStrict(yamlPath, &priceSheet, PriceSheetKind); err != nil {
+			continue
+		}
+		logrus.Debugf("found price sheet yaml at path %s", yamlPath)
+		priceSheets = append(priceSheets, priceSheet)
+	}
+	sort.Slice(priceSheets, func(i, j int) bool { return priceSheets[i].Name < priceSheets[j].Name })
+	return priceSheets
+}
+
+// persistCostEstimation writes the estimated monthly cost of each service using the selected price sheet.
+// It returns false if no price sheet was selected.
+func persistCostEstimation(ir irtypes.IR, priceSheets []PriceSheetT, outputPath string) (bool, error) {
+	if len(priceSheets) == 0 || len(ir.Services) == 0 {
+		return false, nil
+	}
+	priceSheetNames := []string{}
+	hints := []string{"Select " + noPriceSheet + " to skip the cost estimation"}
+	for _, priceSheet := range priceSheets {
+		priceSheetNames = append(priceSheetNames, priceSheet.Name)
+		if priceSheet.Spec.Description != "" {
+			hints = append(hints, priceSheet.Name+" : "+priceSheet.Spec.Description)
+		}
+	}
+	selectedPriceSheetName := qaengine.FetchSelectAnswer(
+		common.ConfigCostEstimationPriceSheetKey,
+		"Select the price sheet to estimate the monthly cost of the services with :",
+		hints,
+		priceSheetNames[0],
+		append(priceSheetNames, noPriceSheet),
+	)
+	var priceSheet *PriceSheetT
+	for i := range priceSheets {
+		if priceSheets[i].Name == selectedPriceSheetName {
+			priceSheet = &priceSheets[i]
+		}
+	}
+	if priceSheet == nil {
+		return false, nil
+	}
+	claimSizes := map[string]float64{}
+	for _, storage := range ir.Storages {
+		if storage.StorageType != irtypes.PVCKind {
+			continue
+		}
+		if size, ok := storage.Resources.Requests[core.ResourceStorage]; ok {
+			claimSizes[storage.Name] = float64(size.Value()) / bytesPerGiB
+		}
+	}
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	currency := priceSheet.Spec.Currency
+	report := []string{
+		"# Cost estimation", "",
+		fmt.Sprintf("Estimated monthly cost using the %s price sheet : %s", priceSheet.Name, priceSheet.Spec.Description), "",
+		fmt.Sprintf("Containers without resource requests are assumed to request %s cpu and %s memory. Persistent volume claims are counted once for the first service that mounts them. Edit the price sheets to match the negotiated rates.", defaultCPURequest.String(), defaultMemoryRequest.String()), "",
+		fmt.Sprintf("| Service | Replicas | CPU cores | Memory (GiB) | Storage (GiB) | Monthly cost (%s) |", currency),
+		"| --- | --- | --- | --- | --- | --- |",
+	}
+	countedClaims := map[string]bool{}
+	total := 0.0
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		replicas := service.Replicas
+		if replicas == 0 {
+			replicas = 1
+		}
+		cpu, memory := getPodRequests(service)
+		storage := 0.0
+		for _, volume := range service.Volumes {
+			if volume.PersistentVolumeClaim == nil || countedClaims[volume.PersistentVolumeClaim.ClaimName] {
+				continue
+			}
+			countedClaims[volume.PersistentVolumeClaim.ClaimName] = true
+			storage += claimSizes[volume.PersistentVolumeClaim.ClaimName]
+		}
+		cpu, memory = cpu*float64(replicas), memory*float64(replicas)
+		cost := (cpu*priceSheet.Spec.CPUCoreHour+memory*priceSheet.Spec.MemoryGiBHour)*hoursPerMonth + storage*priceSheet.Spec.StorageGiBMonth
+		total += cost
+		report = append(report, fmt.Sprintf("| %s | %d | %.2f | %.2f | %.2f | %.2f |", serviceName, replicas, cpu, memory, storage, cost))
+	}
+	report = append(report, fmt.Sprintf("| **Total** | | | | | **%.2f** |", total), "")
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return false, fmt.Errorf("failed to create the directory %s . Error: %q", outputPath, err)
+	}
+	reportPath := filepath.Join(outputPath, costEstimationReportFileName)
+	if err := os.WriteFile(reportPath, []byte(strings.Join(report, "\n")), common.DefaultFilePermission); err != nil {
+		return false, fmt.Errorf("failed to write the cost estimation to %s . Error: %q", reportPath, err)
+	}
+	return true, nil
+}
+
+// getPodRequests returns the cpu cores and memory GiB requested by a single pod of the service
+func getPodRequests(service irtypes.Service) (float64, float64) {
+	cpu, memory := 0.0, 0.0
+	for _, container := range service.Containers {
+		containerCPU, ok := container.Resources.Requests[core.ResourceCPU]
+		if !ok {
+			if containerCPU, ok = container.Resources.Limits[core.ResourceCPU]; !ok {
+				containerCPU = defaultCPURequest
+			}
+		}
+		containerMemory, ok := container.Resources.Requests[core.ResourceMemory]
+		if !ok {
+			if containerMemory, ok = container.Resources.Limits[core.ResourceMemory]; !ok {
+				containerMemory = defaultMemoryRequest
+			}
+		}
+		cpu += float64(containerCPU.MilliValue()) / 1000
+		memory += float64(containerMemory.Value()) / bytesPerGiB
+	}
+	return cpu, memory
+}
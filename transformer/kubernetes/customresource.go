@@ -0,0 +1,46 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+// customResourceT stores a resource whose type is not known to the k8s scheme, like a ServiceMonitor or a Velero Schedule
+type customResourceT struct {
+	APIVersion string                 `yaml:"apiVersion"`
+	Kind       string                 `yaml:"kind"`
+	Metadata   map[string]interface{} `yaml:"metadata"`
+	Spec       map[string]interface{} `yaml:"spec"`
+}
+
+// persistCustomResources writes the custom resources to the output directory
+func persistCustomResources(outputPath string, customResources []customResourceT) ([]string, error) {
+	files := []string{}
+	for _, customResource := range customResources {
+		customResourcePath := filepath.Join(outputPath, fmt.Sprintf("%s-%s.yaml", customResource.Metadata["name"], strings.ToLower(customResource.Kind)))
+		if err := common.WriteYaml(customResourcePath, customResource); err != nil {
+			return files, fmt.Errorf("failed to write the %s to %s . Error: %q", customResource.Kind, customResourcePath, err)
+		}
+		files = append(files, customResourcePath)
+	}
+	return files, nil
+}
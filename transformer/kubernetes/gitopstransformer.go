@@ -0,0 +1,298 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultGitOpsOutputPath  = "gitops"
+	defaultGitOpsRepoRef     = "HEAD"
+	gitOpsRepoURLPlaceholder = "<TODO: fill in the url of the GitOps repo>"
+	argoCDApplicationVersion = "argoproj.io/v1alpha1"
+	argoCDApplicationKind    = "Application"
+	kustomizationFileName    = "kustomization.yaml"
+	argoCDNamespace          = "argocd"
+	gitOpsDestinationServer  = "https://kubernetes.default.svc"
+	defaultGitOpsEnv         = "default"
+)
+
+// GitOps implements Transformer interface
+type GitOps struct {
+	Config       transformertypes.Transformer
+	Env          *environment.Environment
+	GitOpsConfig *GitOpsYamlConfig
+}
+
+// GitOpsYamlConfig stores the GitOps repo related information
+type GitOpsYamlConfig struct {
+	OutputPath string   `yaml:"outputPath"`
+	YamlsPath  string   `yaml:"yamlsPath"`
+	Envs       []string `yaml:"envs"`
+}
+
+// Init Initializes the transformer
+func (t *GitOps) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.Config = tc
+	t.Env = env
+	t.GitOpsConfig = &GitOpsYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.GitOpsConfig); err != nil {
+		return fmt.Errorf("unable to load the config for the GitOps tranformer. Actual: %+v . Error: %q", t.Config.Spec.Config, err)
+	}
+	if t.GitOpsConfig.OutputPath == "" {
+		t.GitOpsConfig.OutputPath = defaultGitOpsOutputPath
+	}
+	if t.GitOpsConfig.YamlsPath == "" {
+		t.GitOpsConfig.YamlsPath = defaultK8sYamlsOutputPath
+	}
+	if len(t.GitOpsConfig.Envs) == 0 {
+		t.GitOpsConfig.Envs = []string{defaultGitOpsEnv}
+	}
+	return nil
+}
+
+// GetConfig returns the configuration
+func (t *GitOps) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each subdirectory
+func (*GitOps) DirectoryDetect(dir string) (map[string][]transformertypes.Artifact, error) {
+	return nil, nil
+}
+
+// Transform lays out the kubernetes yamls as a GitOps repo with a root ArgoCD application for each environment
+func (t *GitOps) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	yamlsPaths := []string{}
+	for _, newArtifact := range newArtifacts {
+		for _, yamlsPath := range newArtifact.Paths[artifacts.KubernetesYamlsPathType] {
+			if relPath, err := t.Env.OutputRel(yamlsPath); err == nil && filepath.Clean(relPath) == filepath.Clean(t.GitOpsConfig.YamlsPath) {
+				yamlsPaths = append(yamlsPaths, yamlsPath)
+			}
+		}
+	}
+	if len(yamlsPaths) == 0 || !qaengine.FetchBoolAnswer(
+		common.ConfigGitOpsEnableKey,
+		"Lay out the Kubernetes yamls as a GitOps repo?",
+		[]string{"The services are placed in base and overlays directories, with an ArgoCD app of apps for each environment"},
+		false,
+	) {
+		return nil, nil, nil
+	}
+	repoURL := qaengine.FetchStringAnswer(
+		common.ConfigGitOpsRepoURLKey,
+		"Enter the URL of the git repo the GitOps output will be pushed to :",
+		[]string{"The ArgoCD applications sync from this repo"},
+		"",
+	)
+	if repoURL == "" {
+		repoURL = gitOpsRepoURLPlaceholder
+	}
+	repoRef := qaengine.FetchStringAnswer(
+		common.ConfigGitOpsRepoRefKey,
+		"Enter the branch, tag or commit of the git repo that should be deployed :",
+		[]string{"Ex : main"},
+		defaultGitOpsRepoRef,
+	)
+	tempDest := filepath.Join(t.Env.TempPath, "gitops-"+common.GetRandomString())
+	apps := map[string]*gitOpsApp{}
+	for _, yamlsPath := range yamlsPaths {
+		if err := t.placeYamls(yamlsPath, filepath.Join(tempDest, "base"), apps); err != nil {
+			logrus.Errorf("Failed to place the kubernetes yamls at %s in the GitOps repo. Error: %q", yamlsPath, err)
+		}
+	}
+	if err := t.writeGitOpsRepo(tempDest, apps, repoURL, repoRef); err != nil {
+		logrus.Errorf("Failed to generate the GitOps repo. Error: %q", err)
+		return nil, nil, err
+	}
+	pathMappings := []transformertypes.PathMapping{{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  tempDest,
+		DestPath: t.GitOpsConfig.OutputPath,
+	}}
+	return pathMappings, nil, nil
+}
+
+// gitOpsApp stores the yamls of a service in the GitOps repo
+type gitOpsApp struct {
+	files     []string
+	namespace string
+}
+
+// placeYamls copies each yaml into the base directory of the service it belongs to.
+// Yamls that do not belong to a service, like the ingress, go to the app of the project.
+func (t *GitOps) placeYamls(yamlsPath, baseDir string, apps map[string]*gitOpsApp) error {
+	pathedKs, err := k8sschema.GetK8sResourcesWithPaths(yamlsPath)
+	if err != nil {
+		return err
+	}
+	projectApp := common.MakeStringDNSLabelNameCompliant(t.Env.GetProjectName())
+	serviceNames := map[string]bool{}
+	for _, ks := range pathedKs {
+		for _, k := range ks {
+			if serviceName := getGitOpsServiceLabel(k); serviceName != "" {
+				serviceNames[serviceName] = true
+			}
+		}
+	}
+	for _, kPath := range getSortedYamlPaths(pathedKs) {
+		appName := projectApp
+		namespace := ""
+		for _, k := range pathedKs[kPath] {
+			metadata, _ := k["metadata"].(map[string]interface{})
+			name, _ := metadata["name"].(string)
+			if serviceName := getGitOpsServiceLabel(k); serviceName != "" {
+				appName = serviceName
+			} else if serviceNames[name] {
+				appName = name
+			}
+			if objNamespace, ok := metadata["namespace"].(string); ok && objNamespace != "" {
+				namespace = objNamespace
+			}
+		}
+		app, ok := apps[appName]
+		if !ok {
+			app = &gitOpsApp{}
+			apps[appName] = app
+		}
+		if app.namespace == "" {
+			app.namespace = namespace
+		}
+		destPath := filepath.Join(baseDir, appName, kPath)
+		if err := os.MkdirAll(filepath.Dir(destPath), common.DefaultDirectoryPermission); err != nil {
+			return err
+		}
+		if err := common.CopyFile(destPath, filepath.Join(yamlsPath, kPath)); err != nil {
+			return err
+		}
+		app.files = append(app.files, filepath.ToSlash(kPath))
+	}
+	return nil
+}
+
+// writeGitOpsRepo writes the kustomizations of the bases and overlays, an application for each service and environment,
+// and a root application for each environment in the clusters directory.
+// The applications of the services in a migration wave are in a directory of the wave with a root application of their own, so each wave can be cut over separately.
+func (t *GitOps) writeGitOpsRepo(repoDir string, apps map[string]*gitOpsApp, repoURL, repoRef string) error {
+	appNames := []string{}
+	for appName := range apps {
+		appNames = append(appNames, appName)
+	}
+	sort.Strings(appNames)
+	projectName := common.MakeStringDNSLabelNameCompliant(t.Env.GetProjectName())
+	waves := []string{}
+	for _, appName := range appNames {
+		app := apps[appName]
+		wave := common.ServiceWaves[appName]
+		if wave != "" && !common.IsPresent(waves, wave) {
+			waves = append(waves, wave)
+		}
+		namespace := app.namespace
+		if namespace == "" {
+			namespace = projectName
+		}
+		baseKustomizationPath := filepath.Join(repoDir, "base", appName, kustomizationFileName)
+		if err := writeGitOpsYaml(baseKustomizationPath, map[string]interface{}{"resources": app.files}); err != nil {
+			return err
+		}
+		for _, env := range t.GitOpsConfig.Envs {
+			overlayKustomizationPath := filepath.Join(repoDir, "overlays", env, appName, kustomizationFileName)
+			if err := writeGitOpsYaml(overlayKustomizationPath, map[string]interface{}{"resources": []string{"../../../base/" + appName}}); err != nil {
+				return err
+			}
+			application := getArgoCDApplication(appName+"-"+env, repoURL, repoRef, filepath.ToSlash(filepath.Join(t.GitOpsConfig.OutputPath, "overlays", env, appName)), namespace)
+			applicationPath := filepath.Join(repoDir, "apps", env, wave, appName+"-application.yaml")
+			if err := writeGitOpsYaml(applicationPath, application); err != nil {
+				return err
+			}
+		}
+	}
+	for _, env := range t.GitOpsConfig.Envs {
+		rootApplication := getArgoCDApplication(projectName+"-"+env+"-root", repoURL, repoRef, filepath.ToSlash(filepath.Join(t.GitOpsConfig.OutputPath, "apps", env)), argoCDNamespace)
+		rootApplicationPath := filepath.Join(repoDir, "clusters", env, projectName+"-root-application.yaml")
+		if err := writeGitOpsYaml(rootApplicationPath, rootApplication); err != nil {
+			return err
+		}
+		sort.Strings(waves)
+		for _, wave := range waves {
+			waveName := common.MakeStringDNSLabelNameCompliant(wave)
+			waveRootApplication := getArgoCDApplication(projectName+"-"+env+"-"+waveName+"-root", repoURL, repoRef, filepath.ToSlash(filepath.Join(t.GitOpsConfig.OutputPath, "apps", env, wave)), argoCDNamespace)
+			waveRootApplicationPath := filepath.Join(repoDir, "clusters", env, projectName+"-"+waveName+"-root-application.yaml")
+			if err := writeGitOpsYaml(waveRootApplicationPath, waveRootApplication); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeGitOpsYaml writes the yaml, creating the parent directories
+func writeGitOpsYaml(outputPath string, data interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	return common.WriteYaml(outputPath, data)
+}
+
+// getArgoCDApplication returns an ArgoCD application that syncs the directory of the GitOps repo
+func getArgoCDApplication(name, repoURL, repoRef, repoPath, namespace string) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": argoCDApplicationVersion,
+		"kind":       argoCDApplicationKind,
+		"metadata":   map[string]interface{}{"name": common.MakeStringDNSSubdomainNameCompliant(name), "namespace": argoCDNamespace},
+		"spec": map[string]interface{}{
+			"project":     "default",
+			"source":      map[string]interface{}{"repoURL": repoURL, "targetRevision": repoRef, "path": repoPath},
+			"destination": map[string]interface{}{"server": gitOpsDestinationServer, "namespace": namespace},
+			"syncPolicy": map[string]interface{}{
+				"automated":   map[string]interface{}{"prune": true, "selfHeal": true},
+				"syncOptions": []string{"CreateNamespace=true"},
+			},
+		},
+	}
+}
+
+// getGitOpsServiceLabel returns the service that the resource was generated for
+func getGitOpsServiceLabel(k k8sschema.K8sResourceT) string {
+	metadata, _ := k["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	serviceName, _ := labels[serviceLabel].(string)
+	return strings.TrimSpace(serviceName)
+}
+
+// getSortedYamlPaths returns the paths of the yamls in a stable order
+func getSortedYamlPaths(pathedKs map[string][]k8sschema.K8sResourceT) []string {
+	kPaths := []string{}
+	for kPath := range pathedKs {
+		kPaths = append(kPaths, kPath)
+	}
+	sort.Strings(kPaths)
+	return kPaths
+}
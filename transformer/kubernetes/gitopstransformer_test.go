@@ -0,0 +1,117 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+func TestGitOpsTransform(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.gitops.enable=true`,
+		`move2kube.gitops.repourl="https://github.com/acme/shop-gitops.git"`,
+	}, nil, nil, false, false)
+	oldServiceWaves := common.ServiceWaves
+	common.ServiceWaves = map[string]string{"catalog": "Wave 1"}
+	t.Cleanup(func() { common.ServiceWaves = oldServiceWaves })
+
+	outputPath := t.TempDir()
+	yamlsPath := filepath.Join(outputPath, defaultK8sYamlsOutputPath)
+	yamls := map[string]string{
+		"orders-deployment.yaml":  "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: orders\n  namespace: team-a\n  labels:\n    " + serviceLabel + ": orders\n",
+		"orders-service.yaml":     "apiVersion: v1\nkind: Service\nmetadata:\n  name: orders\n",
+		"catalog-deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: catalog\n  labels:\n    " + serviceLabel + ": catalog\n",
+		"shop-ingress.yaml":       "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: shop\n",
+	}
+	if err := os.MkdirAll(yamlsPath, common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the yamls directory. Error: %q", err)
+	}
+	for fileName, contents := range yamls {
+		if err := os.WriteFile(filepath.Join(yamlsPath, fileName), []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the yaml %s . Error: %q", fileName, err)
+		}
+	}
+
+	gitOps := &GitOps{}
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{ProjectName: "shop", CurrEnvOutputBasePath: outputPath, TempPath: t.TempDir()}}
+	tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Config: map[string]interface{}{"envs": []interface{}{"dev", "prod"}}}}
+	if err := gitOps.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the GitOps transformer. Error: %q", err)
+	}
+	pathMappings, _, err := gitOps.Transform([]transformertypes.Artifact{
+		{Paths: map[transformertypes.PathType][]string{artifacts.KubernetesYamlsPathType: {yamlsPath}}},
+		{Paths: map[transformertypes.PathType][]string{artifacts.KubernetesYamlsPathType: {filepath.Join(outputPath, "other")}}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform. Error: %q", err)
+	}
+	if len(pathMappings) != 1 || pathMappings[0].DestPath != defaultGitOpsOutputPath {
+		t.Fatalf("expected a single path mapping to the GitOps output. Actual: %+v", pathMappings)
+	}
+	repoDir := pathMappings[0].SrcPath
+
+	kustomizations := map[string]interface{}{
+		"base/orders/kustomization.yaml":          []interface{}{"orders-deployment.yaml", "orders-service.yaml"},
+		"base/catalog/kustomization.yaml":         []interface{}{"catalog-deployment.yaml"},
+		"base/shop/kustomization.yaml":            []interface{}{"shop-ingress.yaml"},
+		"overlays/prod/orders/kustomization.yaml": []interface{}{"../../../base/orders"},
+	}
+	for path, expected := range kustomizations {
+		kustomization := map[string]interface{}{}
+		if err := common.ReadYaml(filepath.Join(repoDir, path), &kustomization); err != nil {
+			t.Fatalf("failed to read the kustomization %s . Error: %q", path, err)
+		}
+		if diff := cmp.Diff(expected, kustomization["resources"]); diff != "" {
+			t.Fatalf("wrong resources in the kustomization %s . Difference:\n%s", path, diff)
+		}
+	}
+
+	applications := map[string][]string{ // [path]{source path, destination namespace}
+		"apps/dev/orders-application.yaml":                {"gitops/overlays/dev/orders", "team-a"},
+		"apps/dev/shop-application.yaml":                  {"gitops/overlays/dev/shop", "shop"},
+		"apps/prod/Wave 1/catalog-application.yaml":       {"gitops/overlays/prod/catalog", "shop"},
+		"clusters/dev/shop-root-application.yaml":         {"gitops/apps/dev", argoCDNamespace},
+		"clusters/prod/shop-wave-1-root-application.yaml": {"gitops/apps/prod/Wave 1", argoCDNamespace},
+	}
+	for path, expected := range applications {
+		application := map[string]interface{}{}
+		if err := common.ReadYaml(filepath.Join(repoDir, path), &application); err != nil {
+			t.Fatalf("failed to read the application %s . Error: %q", path, err)
+		}
+		spec := application["spec"].(map[string]interface{})
+		source := spec["source"].(map[string]interface{})
+		destination := spec["destination"].(map[string]interface{})
+		actual := []string{source["path"].(string), destination["namespace"].(string)}
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Fatalf("wrong application %s . Difference:\n%s", path, diff)
+		}
+		if source["repoURL"] != "https://github.com/acme/shop-gitops.git" || source["targetRevision"] != defaultGitOpsRepoRef {
+			t.Fatalf("wrong source for the application %s . Actual: %+v", path, source)
+		}
+	}
+}
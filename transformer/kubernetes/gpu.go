@@ -0,0 +1,107 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	defaultGPURuntimeClass = "nvidia"
+)
+
+// wireGPUs asks whether the services that use GPUs should request them.
+// The GPUs are requested by the first container of the pod, which is scheduled on the GPU nodes using the runtime class and a toleration of their taint.
+func wireGPUs(ir irtypes.IR, clusterConfig collecttypes.ClusterMetadata) irtypes.IR {
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if len(service.DeviceRequirements) > 0 && len(service.Containers) > 0 {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigGPUKeySegment)
+		// only one kind of GPU is requested for each service
+		deviceRequirement := service.DeviceRequirements[0]
+		if !qaengine.FetchBoolAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, "enable"),
+			fmt.Sprintf("The service %s uses GPUs (%s). Should it request %s and be scheduled on the GPU nodes?", serviceName, deviceRequirement.Source, deviceRequirement.Resource),
+			[]string{"The device plugin of the GPUs has to be installed in the target cluster"},
+			true,
+		) {
+			continue
+		}
+		defaultCount := deviceRequirement.Count
+		if defaultCount <= 0 {
+			defaultCount = 1
+		}
+		count, err := cast.ToInt64E(qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, "count"),
+			fmt.Sprintf("Enter the number of GPUs for the service %s :", serviceName),
+			[]string{"GPUs cannot be shared between containers, so the requests and limits are the same"},
+			cast.ToString(defaultCount),
+		))
+		if err != nil || count <= 0 {
+			logrus.Errorf("the number of GPUs for the service %s has to be a positive integer. Using %d. Error: %q", serviceName, defaultCount, err)
+			count = defaultCount
+		}
+		if clusterConfig.Spec.NodeResources != nil && clusterConfig.Spec.NodeResources[deviceRequirement.Resource] < count {
+			logrus.Warnf("The service %s requests %d %s, but the target cluster %s has %d. The pods will stay pending until GPU nodes are added to the cluster.",
+				serviceName, count, deviceRequirement.Resource, clusterConfig.Name, clusterConfig.Spec.NodeResources[deviceRequirement.Resource])
+		}
+		container := service.Containers[0]
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = core.ResourceList{}
+		}
+		container.Resources.Limits[core.ResourceName(deviceRequirement.Resource)] = *resource.NewQuantity(count, resource.DecimalSI)
+		if container.Resources.Requests != nil {
+			container.Resources.Requests[core.ResourceName(deviceRequirement.Resource)] = *resource.NewQuantity(count, resource.DecimalSI)
+		}
+		service.Containers[0] = container
+		runtimeClass := qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, "runtimeclass"),
+			fmt.Sprintf("Enter the runtime class for the GPU containers of the service %s :", serviceName),
+			[]string{"Leave it empty if the default container runtime of the GPU nodes supports GPUs, like with the NVIDIA GPU operator"},
+			defaultGPURuntimeClass,
+		)
+		if runtimeClass != "" {
+			service.RuntimeClassName = &runtimeClass
+		}
+		if qaengine.FetchBoolAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, "toleration"),
+			fmt.Sprintf("Should the service %s tolerate the %s taint of the GPU nodes?", serviceName, deviceRequirement.Resource),
+			[]string{"GPU nodes are usually tainted so that only the pods that need GPUs are scheduled on them"},
+			true,
+		) {
+			service.Tolerations = append(service.Tolerations, core.Toleration{Key: deviceRequirement.Resource, Operator: core.TolerationOpExists, Effect: core.TaintEffectNoSchedule})
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir
+}
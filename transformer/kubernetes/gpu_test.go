@@ -0,0 +1,91 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestWireGPUs(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.services."inference".gpu.count="3"`,
+		`move2kube.services."inference".gpu.runtimeclass=""`,
+		`move2kube.services."inference".gpu.toleration=false`,
+		`move2kube.services."renderer".gpu.enable=false`,
+	}, nil, nil, false, false)
+
+	deviceRequirements := []irtypes.DeviceRequirement{{Resource: irtypes.NvidiaGPUResource, Count: 2, Source: "requirements.txt torch"}}
+	ir := irtypes.NewIR()
+	trainer := irtypes.NewServiceWithName("trainer")
+	trainer.DeviceRequirements = deviceRequirements
+	trainer.Containers = []core.Container{
+		{Name: "trainer", Resources: core.ResourceRequirements{Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("2")}}},
+		{Name: "exporter"},
+	}
+	inference := irtypes.NewServiceWithName("inference")
+	inference.DeviceRequirements = deviceRequirements
+	inference.Containers = []core.Container{{Name: "inference"}}
+	renderer := irtypes.NewServiceWithName("renderer")
+	renderer.DeviceRequirements = deviceRequirements
+	renderer.Containers = []core.Container{{Name: "renderer"}}
+	ir.Services = map[string]irtypes.Service{"trainer": trainer, "inference": inference, "renderer": renderer, "web": irtypes.NewServiceWithName("web")}
+
+	clusterConfig := collecttypes.ClusterMetadata{Spec: collecttypes.ClusterMetadataSpec{NodeResources: map[string]int64{irtypes.NvidiaGPUResource: 2}}}
+	ir = wireGPUs(ir, clusterConfig)
+
+	gpus := func(count int64) resource.Quantity { return *resource.NewQuantity(count, resource.DecimalSI) }
+	runtimeClass := defaultGPURuntimeClass
+	expectedTrainer := []core.Container{
+		{Name: "trainer", Resources: core.ResourceRequirements{
+			Limits:   core.ResourceList{irtypes.NvidiaGPUResource: gpus(2)},
+			Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("2"), irtypes.NvidiaGPUResource: gpus(2)},
+		}},
+		{Name: "exporter"},
+	}
+	if diff := cmp.Diff(expectedTrainer, ir.Services["trainer"].Containers); diff != "" {
+		t.Fatalf("wrong gpu resources for the service trainer. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff(&runtimeClass, ir.Services["trainer"].RuntimeClassName); diff != "" {
+		t.Fatalf("wrong runtime class for the service trainer. Difference:\n%s", diff)
+	}
+	expectedTolerations := []core.Toleration{{Key: irtypes.NvidiaGPUResource, Operator: core.TolerationOpExists, Effect: core.TaintEffectNoSchedule}}
+	if diff := cmp.Diff(expectedTolerations, ir.Services["trainer"].Tolerations); diff != "" {
+		t.Fatalf("wrong tolerations for the service trainer. Difference:\n%s", diff)
+	}
+
+	expectedInference := []core.Container{{Name: "inference", Resources: core.ResourceRequirements{Limits: core.ResourceList{irtypes.NvidiaGPUResource: gpus(3)}}}}
+	if diff := cmp.Diff(expectedInference, ir.Services["inference"].Containers); diff != "" {
+		t.Fatalf("wrong gpu resources for the service inference. Difference:\n%s", diff)
+	}
+	if ir.Services["inference"].RuntimeClassName != nil || len(ir.Services["inference"].Tolerations) != 0 {
+		t.Fatalf("expected no runtime class and no tolerations for the service inference. Actual: %+v", ir.Services["inference"])
+	}
+
+	if diff := cmp.Diff(renderer.Containers, ir.Services["renderer"].Containers); diff != "" {
+		t.Fatalf("expected the service renderer not to request gpus. Difference:\n%s", diff)
+	}
+}
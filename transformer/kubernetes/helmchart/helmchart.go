@@ -0,0 +1,214 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package helmchart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ChartFileName is the name of the file containing the chart metadata
+	ChartFileName = "Chart.yaml"
+	// ValuesFileName is the name of the file containing the default values of the chart
+	ValuesFileName   = "values.yaml"
+	templatesDirName = "templates"
+	chartsDirName    = "charts"
+	globalValuesKey  = "global"
+)
+
+// Metadata is the metadata of a helm chart stored in the Chart.yaml
+type Metadata struct {
+	Name         string            `yaml:"name"`
+	Home         string            `yaml:"home,omitempty"`
+	Sources      []string          `yaml:"sources,omitempty"`
+	Version      string            `yaml:"version"`
+	Description  string            `yaml:"description,omitempty"`
+	Keywords     []string          `yaml:"keywords,omitempty"`
+	Icon         string            `yaml:"icon,omitempty"`
+	APIVersion   string            `yaml:"apiVersion"`
+	Condition    string            `yaml:"condition,omitempty"`
+	Tags         string            `yaml:"tags,omitempty"`
+	AppVersion   string            `yaml:"appVersion,omitempty"`
+	Deprecated   bool              `yaml:"deprecated,omitempty"`
+	Annotations  map[string]string `yaml:"annotations,omitempty"`
+	KubeVersion  string            `yaml:"kubeVersion,omitempty"`
+	Dependencies []Dependency      `yaml:"dependencies,omitempty"`
+	Type         string            `yaml:"type,omitempty"`
+}
+
+// Dependency is a dependency of a helm chart on a sub chart
+type Dependency struct {
+	Name      string `yaml:"name"`
+	Version   string `yaml:"version,omitempty"`
+	Condition string `yaml:"condition,omitempty"`
+	Alias     string `yaml:"alias,omitempty"`
+}
+
+// Chart is a helm chart loaded from a directory
+type Chart struct {
+	// Metadata is the contents of the Chart.yaml
+	Metadata Metadata
+	// Values are the default values from the values.yaml
+	Values map[string]interface{}
+	// Templates maps the paths of the templates (relative to the chart directory) to their contents
+	Templates map[string]string
+	// Files maps the paths of the non template files (relative to the chart directory) to their contents
+	Files map[string][]byte
+	// SubCharts are the unpacked charts in the charts directory
+	SubCharts []*Chart
+}
+
+// IsChartDir returns true if the directory contains a helm chart
+func IsChartDir(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, ChartFileName)); err != nil || info.IsDir() {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(dir, templatesDirName))
+	return err == nil && info.IsDir()
+}
+
+// LoadChart loads the helm chart in the directory along with its unpacked sub charts
+func LoadChart(dir string) (*Chart, error) {
+	chart := &Chart{Values: map[string]interface{}{}, Templates: map[string]string{}, Files: map[string][]byte{}}
+	if err := common.ReadYaml(filepath.Join(dir, ChartFileName), &chart.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to read the chart metadata in the directory %s . Error: %q", dir, err)
+	}
+	if chart.Metadata.Name == "" {
+		return nil, fmt.Errorf("the chart in the directory %s does not have a name", dir)
+	}
+	valuesPath := filepath.Join(dir, ValuesFileName)
+	if _, err := os.Stat(valuesPath); err == nil {
+		if err := common.ReadYaml(valuesPath, &chart.Values); err != nil {
+			return nil, fmt.Errorf("failed to read the values of the chart %s . Error: %q", chart.Metadata.Name, err)
+		}
+		if chart.Values == nil {
+			chart.Values = map[string]interface{}{}
+		}
+	}
+	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if info.IsDir() {
+			if relPath == chartsDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(relPath, templatesDirName+"/") {
+			chart.Templates[relPath] = string(data)
+			return nil
+		}
+		chart.Files[relPath] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the files of the chart %s . Error: %q", chart.Metadata.Name, err)
+	}
+	subChartsDir := filepath.Join(dir, chartsDirName)
+	dirEntries, err := os.ReadDir(subChartsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Warnf("failed to read the sub charts directory %s . Error: %q", subChartsDir, err)
+		}
+		return chart, nil
+	}
+	for _, dirEntry := range dirEntries {
+		subChartDir := filepath.Join(subChartsDir, dirEntry.Name())
+		if !dirEntry.IsDir() {
+			logrus.Warnf("Ignoring the packed sub chart %s . Only unpacked sub charts are supported.", subChartDir)
+			continue
+		}
+		if !IsChartDir(subChartDir) {
+			continue
+		}
+		subChart, err := LoadChart(subChartDir)
+		if err != nil {
+			logrus.Errorf("failed to load the sub chart in the directory %s . Error: %q", subChartDir, err)
+			continue
+		}
+		chart.SubCharts = append(chart.SubCharts, subChart)
+	}
+	return chart, nil
+}
+
+// GetValuesFiles returns the paths (relative to the chart directory) of the values files in the chart directory
+func GetValuesFiles(dir string) []string {
+	valuesFiles := []string{}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		logrus.Errorf("failed to read the chart directory %s . Error: %q", dir, err)
+		return valuesFiles
+	}
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		ext := filepath.Ext(name)
+		if dirEntry.IsDir() || (ext != ".yaml" && ext != ".yml") || !strings.HasPrefix(name, "values") {
+			continue
+		}
+		valuesFiles = append(valuesFiles, name)
+	}
+	sort.Strings(valuesFiles)
+	return valuesFiles
+}
+
+// CoalesceValues deep merges the values, with the later values taking precedence over the earlier ones
+func CoalesceValues(valuesList ...map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, values := range valuesList {
+		result = coalesce(result, values)
+	}
+	return result
+}
+
+func coalesce(dst, src map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range dst {
+		result[k] = v
+	}
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := result[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			result[k] = coalesce(dstMap, srcMap)
+			continue
+		}
+		if v == nil {
+			// helm uses null to delete a key from the default values
+			delete(result, k)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package helmchart_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/transformer/kubernetes/helmchart"
+)
+
+const (
+	testChartYaml = `apiVersion: v2
+name: mychart
+version: 0.1.0
+`
+	testValuesYaml = `replicaCount: 1
+image:
+  repository: nginx
+  tag: "1.19"
+`
+	testDeploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-{{ .Chart.Name }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  template:
+    spec:
+      containers:
+        - name: {{ .Chart.Name }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+`
+)
+
+func createTestChart(t *testing.T) string {
+	chartDir := t.TempDir()
+	files := map[string]string{
+		helmchart.ChartFileName:     testChartYaml,
+		helmchart.ValuesFileName:    testValuesYaml,
+		"templates/deployment.yaml": testDeploymentTemplate,
+	}
+	for path, contents := range files {
+		path = filepath.Join(chartDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	return chartDir
+}
+
+func TestRender(t *testing.T) {
+	chart, err := helmchart.LoadChart(createTestChart(t))
+	if err != nil {
+		t.Fatalf("failed to load the chart. Error: %q", err)
+	}
+	values := map[string]interface{}{"replicaCount": 3}
+	rendered, err := helmchart.Render(chart, values, helmchart.RenderOptions{ReleaseName: "myrelease", Namespace: "default"})
+	if err != nil {
+		t.Fatalf("failed to render the chart. Error: %q", err)
+	}
+	manifest, ok := rendered["mychart/templates/deployment.yaml"]
+	if !ok {
+		t.Fatalf("the deployment template was not rendered. Actual: %+v", rendered)
+	}
+	for _, expected := range []string{"name: myrelease-mychart", "replicas: 3", `image: "nginx:1.19"`} {
+		if !strings.Contains(manifest, expected) {
+			t.Errorf("expected the rendered manifest to contain %q . Actual:\n%s", expected, manifest)
+		}
+	}
+	parameterizers := helmchart.GetParameterizers(chart, values, helmchart.RenderOptions{ReleaseName: "myrelease", Namespace: "default"}, rendered)
+	templates := map[string]string{}
+	for _, p := range parameterizers {
+		templates[p.Target] = p.Template
+	}
+	if templates["spec.replicas"] != "${replicaCount}" {
+		t.Errorf("expected spec.replicas to be parameterized with replicaCount. Actual: %+v", parameterizers)
+	}
+	if templates[`spec.template.spec.containers.[0].image`] != "${image.repository}:${image.tag}" {
+		t.Errorf("expected the container image to be parameterized with image.repository and image.tag. Actual: %+v", parameterizers)
+	}
+}
+
+func TestRenderChartWithSubCharts(t *testing.T) {
+	chart, err := helmchart.LoadChart(filepath.Join("testdata", "webapp"))
+	if err != nil {
+		t.Fatalf("failed to load the chart. Error: %q", err)
+	}
+	rendered, err := helmchart.Render(chart, map[string]interface{}{"replicaCount": 2}, helmchart.RenderOptions{ReleaseName: "myrelease", Namespace: "default"})
+	if err != nil {
+		t.Fatalf("failed to render the chart. Error: %q", err)
+	}
+	expected := map[string]string{
+		"webapp/templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myrelease-webapp
+  labels:
+    app.kubernetes.io/name: webapp
+    app.kubernetes.io/instance: myrelease
+    app.kubernetes.io/version: "2.0.0"
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: webapp
+          image: "nginx:1.19"
+          env:
+            - name: GREETING
+              value: "hello from myrelease-webapp"
+            - name: DATA_PATH
+              value: "data/1/2/3"
+            - name: ENVIRONMENT
+              value: dev
+`,
+		"webapp/templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myrelease-config
+data:
+  app.properties: |
+    log.level=info
+`,
+		"webapp/charts/cache/templates/service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: myrelease-cache
+  labels:
+    environment: dev
+    parent: cache
+spec:
+  ports:
+    - port: 6380
+`,
+	}
+	if diff := cmp.Diff(expected, rendered); diff != "" {
+		t.Fatalf("the chart was not rendered correctly. Difference:\n%s", diff)
+	}
+}
+
+func TestRenderRecursiveInclude(t *testing.T) {
+	chart, err := helmchart.LoadChart(filepath.Join("testdata", "recursive"))
+	if err != nil {
+		t.Fatalf("failed to load the chart. Error: %q", err)
+	}
+	if _, err := helmchart.Render(chart, nil, helmchart.RenderOptions{ReleaseName: "myrelease"}); err == nil || !strings.Contains(err.Error(), "nested reference name: recursive.loop") {
+		t.Fatalf("expected the endless include to fail the rendering. Actual: %v", err)
+	}
+}
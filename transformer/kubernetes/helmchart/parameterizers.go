@@ -0,0 +1,227 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package helmchart
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/common/yamlnode"
+	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	markerRegex = regexp.MustCompile(`m2kvalue(\d+)m2k`)
+)
+
+// GetParameterizers recovers the parameterization of the chart.
+// The chart is rendered a second time with a unique marker in place of each scalar value.
+// Wherever a marker shows up in the rendered manifests, a parameterizer is created that
+// maps that field back to the value it came from.
+func GetParameterizers(chart *Chart, values map[string]interface{}, options RenderOptions, rendered map[string]string) []parameterizer.ParameterizerT {
+	markedValues, markedPaths := addMarkers(CoalesceValues(chart.Values, values), nil, nil)
+	markedRendered, err := Render(chart, markedValues, options)
+	if err != nil {
+		logrus.Debugf("failed to render the chart %s with the marked values. Error: %q", chart.Metadata.Name, err)
+	}
+	parameterizers := []parameterizer.ParameterizerT{}
+	names := []string{}
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		markedManifest, ok := markedRendered[name]
+		if !ok {
+			continue
+		}
+		docs, err := decodeDocuments(rendered[name])
+		if err != nil {
+			logrus.Debugf("failed to decode the rendered template %s . Error: %q", name, err)
+			continue
+		}
+		markedDocs, err := decodeDocuments(markedManifest)
+		if err != nil || len(markedDocs) != len(docs) {
+			logrus.Debugf("skipping the parameterization of the template %s since its structure changes with the values", name)
+			continue
+		}
+		for i, doc := range docs {
+			docMap, ok := doc.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			kind, _ := docMap["kind"].(string)
+			metadata, _ := docMap["metadata"].(map[string]interface{})
+			metadataName, _ := metadata["name"].(string)
+			if kind == "" || metadataName == "" {
+				continue
+			}
+			filters := []parameterizer.FilterT{{Kind: regexp.QuoteMeta(kind), Name: regexp.QuoteMeta(metadataName)}}
+			for _, marked := range findMarkers(markedDocs[i], nil) {
+				if p, ok := getParameterizer(marked, doc, markedPaths); ok {
+					p.Filters = filters
+					parameterizers = append(parameterizers, p)
+				}
+			}
+		}
+	}
+	return parameterizers
+}
+
+type markedField struct {
+	path  []string
+	value string
+}
+
+func getParameterizer(marked markedField, doc interface{}, markedPaths map[int][]string) (parameterizer.ParameterizerT, bool) {
+	target := strings.Join(marked.path, ".")
+	matches := markerRegex.FindAllStringSubmatchIndex(marked.value, -1)
+	template := ""
+	regex := ""
+	last := 0
+	for _, match := range matches {
+		idx, err := strconv.Atoi(marked.value[match[2]:match[3]])
+		if err != nil {
+			return parameterizer.ParameterizerT{}, false
+		}
+		valuePath, ok := markedPaths[idx]
+		if !ok {
+			return parameterizer.ParameterizerT{}, false
+		}
+		literal := marked.value[last:match[0]]
+		template += literal + "${" + joinKeys(valuePath) + "}"
+		regex += regexp.QuoteMeta(literal) + "(.+)"
+		last = match[1]
+	}
+	template += marked.value[last:]
+	regex += regexp.QuoteMeta(marked.value[last:])
+	if len(matches) == 1 {
+		if matches[0][0] != 0 || matches[0][1] != len(marked.value) {
+			logrus.Debugf("skipping the parameterization of %s since the value is only partially templated", target)
+			return parameterizer.ParameterizerT{}, false
+		}
+		return parameterizer.ParameterizerT{Target: target, Template: template}, true
+	}
+	// multiple parameters require the rendered value to be a string
+	if _, ok := getValueAtPath(doc, marked.path).(string); !ok {
+		return parameterizer.ParameterizerT{}, false
+	}
+	return parameterizer.ParameterizerT{Target: target, Template: template, Regex: regex}, true
+}
+
+// addMarkers replaces all the scalar values (except booleans and values inside lists) with unique markers
+func addMarkers(values map[string]interface{}, path []string, markedPaths map[int][]string) (map[string]interface{}, map[int][]string) {
+	if markedPaths == nil {
+		markedPaths = map[int][]string{}
+	}
+	marked := map[string]interface{}{}
+	for key, value := range values {
+		currentPath := append(append([]string{}, path...), key)
+		switch actualValue := value.(type) {
+		case map[string]interface{}:
+			marked[key], _ = addMarkers(actualValue, currentPath, markedPaths)
+		case string, int, int64, float64:
+			idx := len(markedPaths)
+			markedPaths[idx] = currentPath
+			marked[key] = fmt.Sprintf("m2kvalue%dm2k", idx)
+		default:
+			marked[key] = value
+		}
+	}
+	return marked, markedPaths
+}
+
+func findMarkers(value interface{}, path []string) []markedField {
+	found := []markedField{}
+	switch actualValue := value.(type) {
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range actualValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			found = append(found, findMarkers(actualValue[key], append(append([]string{}, path...), quoteKey(key)))...)
+		}
+	case []interface{}:
+		for i, elem := range actualValue {
+			found = append(found, findMarkers(elem, append(append([]string{}, path...), fmt.Sprintf("[%d]", i)))...)
+		}
+	case string:
+		if markerRegex.MatchString(actualValue) {
+			found = append(found, markedField{path: path, value: actualValue})
+		}
+	}
+	return found
+}
+
+func getValueAtPath(value interface{}, path []string) interface{} {
+	current := value
+	for _, subKey := range path {
+		switch actualValue := current.(type) {
+		case map[string]interface{}:
+			current = actualValue[strings.Trim(subKey, `"`)]
+		case []interface{}:
+			idx, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(subKey, "["), "]"))
+			if err != nil || idx < 0 || idx >= len(actualValue) {
+				return nil
+			}
+			current = actualValue[idx]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func joinKeys(keys []string) string {
+	quoted := []string{}
+	for _, key := range keys {
+		quoted = append(quoted, quoteKey(key))
+	}
+	return strings.Join(quoted, ".")
+}
+
+func quoteKey(key string) string {
+	if strings.ContainsAny(key, `.[]"$ `) {
+		return `"` + key + `"`
+	}
+	return key
+}
+
+func decodeDocuments(manifest string) ([]interface{}, error) {
+	nodes, err := yamlnode.ParseDocuments([]byte(manifest))
+	if err != nil {
+		return nil, err
+	}
+	docs := []interface{}{}
+	for _, node := range nodes {
+		var doc interface{}
+		if err := node.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
@@ -0,0 +1,399 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package helmchart
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultKubeVersion = "v1.23.0"
+	notesFileName      = "NOTES.txt"
+	// includeRecursionMaxNums is the maximum depth of the nested includes of a named template, same as helm
+	includeRecursionMaxNums = 1000
+)
+
+// RenderOptions are the release details used while rendering a chart
+type RenderOptions struct {
+	ReleaseName string
+	Namespace   string
+	// KubeVersion is the version of the target cluster. Defaults to v1.23.0
+	KubeVersion string
+	// APIVersions are the group versions (and group version kinds) supported by the target cluster.
+	// When empty, all the versions known to move2kube are treated as supported.
+	APIVersions []string
+}
+
+// KubeVersion is the version of kubernetes available to the templates
+type KubeVersion struct {
+	Version    string
+	Major      string
+	Minor      string
+	GitVersion string
+}
+
+// String returns the version
+func (kv KubeVersion) String() string {
+	return kv.Version
+}
+
+// VersionSet is the set of api versions available to the templates
+type VersionSet []string
+
+// Has returns true if the api version (or api version and kind) is supported
+func (vs VersionSet) Has(apiVersion string) bool {
+	if len(vs) != 0 {
+		for _, v := range vs {
+			if v == apiVersion {
+				return true
+			}
+		}
+		return false
+	}
+	parts := strings.Split(apiVersion, "/")
+	last := parts[len(parts)-1]
+	if len(parts) > 1 && last != "" && strings.ToUpper(last[:1]) == last[:1] {
+		gv, err := schema.ParseGroupVersion(strings.Join(parts[:len(parts)-1], "/"))
+		if err != nil {
+			return false
+		}
+		return k8sschema.GetSchema().Recognizes(gv.WithKind(last))
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return false
+	}
+	return k8sschema.GetSchema().IsVersionRegistered(gv)
+}
+
+// Capabilities are the capabilities of the target cluster available to the templates
+type Capabilities struct {
+	KubeVersion KubeVersion
+	APIVersions VersionSet
+}
+
+// Files provides access to the non template files of a chart from within the templates
+type Files map[string][]byte
+
+// Get returns the contents of the file as a string
+func (f Files) Get(name string) string {
+	return string(f[name])
+}
+
+// GetBytes returns the contents of the file
+func (f Files) GetBytes(name string) []byte {
+	return f[name]
+}
+
+// Glob returns the files matching the pattern
+func (f Files) Glob(pattern string) Files {
+	matched := Files{}
+	for name, data := range f {
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			matched[name] = data
+		}
+	}
+	return matched
+}
+
+// Lines returns the lines of the file
+func (f Files) Lines(name string) []string {
+	data, ok := f[name]
+	if !ok {
+		return []string{}
+	}
+	return strings.Split(string(data), "\n")
+}
+
+// AsConfig returns the files as the data of a ConfigMap
+func (f Files) AsConfig() string {
+	data := map[string]string{}
+	for name, content := range f {
+		data[path.Base(name)] = string(content)
+	}
+	return toYAML(data)
+}
+
+// AsSecrets returns the files as the data of a Secret
+func (f Files) AsSecrets() string {
+	data := map[string]string{}
+	for name, content := range f {
+		data[path.Base(name)] = base64.StdEncoding.EncodeToString(content)
+	}
+	return toYAML(data)
+}
+
+type chartToRender struct {
+	chart    *Chart
+	metadata Metadata
+	prefix   string
+	values   map[string]interface{}
+}
+
+// Render renders the templates of the chart and its sub charts.
+// The given values are merged over the default values of the chart.
+// The rendered manifests are keyed by the path of their template,
+// prefixed with the chart name, for example mychart/templates/deployment.yaml
+//
+// This is not the helm engine, only the subset of it used by most charts is supported:
+// the .Values, .Release, .Chart, .Capabilities, .Files and .Template objects, the sprig functions,
+// and the helm functions include, tpl, required, lookup (which never finds any resources),
+// toYaml, fromYaml, fromYamlArray, toJson, fromJson and fromJsonArray.
+// Unpacked sub charts are rendered with their values, the global values, the aliases and the conditions of the dependencies.
+// The tags and import-values of the dependencies, values.schema.json, .helmignore and the hooks are not supported,
+// the hooks are rendered like the other templates.
+func Render(chart *Chart, values map[string]interface{}, options RenderOptions) (map[string]string, error) {
+	if options.KubeVersion == "" {
+		options.KubeVersion = defaultKubeVersion
+	}
+	charts := getChartsToRender(chart, chart.Metadata, chart.Metadata.Name, CoalesceValues(chart.Values, values))
+	t := template.New("gotpl").Option("missingkey=zero")
+	t.Funcs(getFuncMap(t, map[string]int{}))
+	templateData := map[string]map[string]interface{}{}
+	for _, c := range charts {
+		for relPath, content := range c.chart.Templates {
+			name := path.Join(c.prefix, relPath)
+			if _, err := t.New(name).Parse(content); err != nil {
+				return nil, fmt.Errorf("failed to parse the template %s . Error: %q", name, err)
+			}
+			templateData[name] = map[string]interface{}{
+				"Values":       c.values,
+				"Release":      getReleaseData(options),
+				"Chart":        c.metadata,
+				"Capabilities": getCapabilities(options),
+				"Files":        Files(c.chart.Files),
+				"Template":     map[string]interface{}{"Name": name, "BasePath": path.Join(c.prefix, templatesDirName)},
+			}
+		}
+	}
+	names := []string{}
+	for name := range templateData {
+		base := path.Base(name)
+		if strings.HasPrefix(base, "_") || base == notesFileName {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rendered := map[string]string{}
+	for _, name := range names {
+		var b bytes.Buffer
+		if err := t.ExecuteTemplate(&b, name, templateData[name]); err != nil {
+			return rendered, fmt.Errorf("failed to render the template %s . Error: %q", name, err)
+		}
+		rendered[name] = strings.ReplaceAll(b.String(), "<no value>", "")
+	}
+	return rendered, nil
+}
+
+func getChartsToRender(chart *Chart, metadata Metadata, prefix string, values map[string]interface{}) []chartToRender {
+	charts := []chartToRender{{chart: chart, metadata: metadata, prefix: prefix, values: values}}
+	globalValues, _ := values[globalValuesKey].(map[string]interface{})
+	for _, subChart := range chart.SubCharts {
+		valuesKey := subChart.Metadata.Name
+		enabled := true
+		for _, dependency := range chart.Metadata.Dependencies {
+			if dependency.Name != subChart.Metadata.Name {
+				continue
+			}
+			if dependency.Alias != "" {
+				valuesKey = dependency.Alias
+			}
+			if dependency.Condition != "" {
+				if condition, ok := getValue(values, dependency.Condition).(bool); ok {
+					enabled = condition
+				}
+			}
+			break
+		}
+		if !enabled {
+			logrus.Debugf("skipping the disabled sub chart %s of the chart %s", subChart.Metadata.Name, chart.Metadata.Name)
+			continue
+		}
+		subChartValues, _ := values[valuesKey].(map[string]interface{})
+		subValues := CoalesceValues(subChart.Values, subChartValues)
+		subGlobalValues, _ := subValues[globalValuesKey].(map[string]interface{})
+		subValues[globalValuesKey] = CoalesceValues(subGlobalValues, globalValues)
+		// same as helm, an aliased sub chart is rendered under its alias
+		subMetadata := subChart.Metadata
+		subMetadata.Name = valuesKey
+		charts = append(charts, getChartsToRender(subChart, subMetadata, path.Join(prefix, chartsDirName, valuesKey), subValues)...)
+	}
+	return charts
+}
+
+func getValue(values map[string]interface{}, key string) interface{} {
+	var current interface{} = values
+	for _, subKey := range strings.Split(key, ".") {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = currentMap[subKey]
+	}
+	return current
+}
+
+func getReleaseData(options RenderOptions) map[string]interface{} {
+	return map[string]interface{}{
+		"Name":      options.ReleaseName,
+		"Namespace": options.Namespace,
+		"Service":   "Helm",
+		"IsInstall": true,
+		"IsUpgrade": false,
+		"Revision":  1,
+	}
+}
+
+func getCapabilities(options RenderOptions) Capabilities {
+	version := options.KubeVersion
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	major, minor := "", ""
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) > 0 {
+		major = parts[0]
+	}
+	if len(parts) > 1 {
+		minor = parts[1]
+	}
+	return Capabilities{
+		KubeVersion: KubeVersion{Version: version, Major: major, Minor: minor, GitVersion: version},
+		APIVersions: VersionSet(options.APIVersions),
+	}
+}
+
+// getFuncMap returns the functions available to the templates.
+// The included names count the nested includes of each named template that is being rendered.
+func getFuncMap(t *template.Template, includedNames map[string]int) template.FuncMap {
+	funcMap := sprig.TxtFuncMap()
+	// same as helm, do not allow access to the environment variables
+	delete(funcMap, "env")
+	delete(funcMap, "expandenv")
+	funcMap["toYaml"] = toYAML
+	funcMap["fromYaml"] = fromYAML
+	funcMap["fromYamlArray"] = fromYAMLArray
+	funcMap["toJson"] = toJSON
+	funcMap["fromJson"] = fromJSON
+	funcMap["fromJsonArray"] = fromJSONArray
+	funcMap["include"] = func(name string, data interface{}) (string, error) {
+		if includedNames[name] >= includeRecursionMaxNums {
+			return "", fmt.Errorf("rendering template has a nested reference name: %s: unable to execute template", name)
+		}
+		includedNames[name]++
+		defer func() { includedNames[name]-- }()
+		var b bytes.Buffer
+		if err := t.ExecuteTemplate(&b, name, data); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	funcMap["tpl"] = func(tpl string, data interface{}) (string, error) {
+		clone, err := t.Clone()
+		if err != nil {
+			return "", err
+		}
+		// the named templates defined in the tpl string can be included from within it
+		clone.Funcs(getFuncMap(clone, includedNames))
+		parsed, err := clone.New("tpl").Parse(tpl)
+		if err != nil {
+			return "", err
+		}
+		var b bytes.Buffer
+		if err := parsed.Execute(&b, data); err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(b.String(), "<no value>", ""), nil
+	}
+	funcMap["required"] = func(message string, value interface{}) (interface{}, error) {
+		if value == nil {
+			return value, errors.New(message)
+		}
+		if s, ok := value.(string); ok && s == "" {
+			return value, errors.New(message)
+		}
+		return value, nil
+	}
+	funcMap["lookup"] = func(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+		// there is no cluster to look up the resources in
+		return map[string]interface{}{}, nil
+	}
+	return funcMap
+}
+
+func toYAML(v interface{}) string {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+func fromYAML(s string) map[string]interface{} {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(s), &m); err != nil {
+		m["Error"] = err.Error()
+	}
+	return m
+}
+
+func fromYAMLArray(s string) []interface{} {
+	a := []interface{}{}
+	if err := yaml.Unmarshal([]byte(s), &a); err != nil {
+		a = []interface{}{err.Error()}
+	}
+	return a
+}
+
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func fromJSON(s string) map[string]interface{} {
+	m := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		m["Error"] = err.Error()
+	}
+	return m
+}
+
+func fromJSONArray(s string) []interface{} {
+	a := []interface{}{}
+	if err := json.Unmarshal([]byte(s), &a); err != nil {
+		a = []interface{}{err.Error()}
+	}
+	return a
+}
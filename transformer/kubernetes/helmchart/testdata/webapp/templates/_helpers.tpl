@@ -0,0 +1,21 @@
+{{- define "webapp.name" -}}
+{{- .Chart.Name | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
+
+{{- define "webapp.fullname" -}}
+{{- printf "%s-%s" .Release.Name (include "webapp.name" .) | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
+
+{{- define "webapp.labels" -}}
+app.kubernetes.io/name: {{ include "webapp.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+{{- end -}}
+
+{{- define "webapp.path" -}}
+{{- if gt (int .depth) 0 -}}
+{{- include "webapp.path" (dict "depth" (sub (int .depth) 1) "name" .name) -}}/{{ .depth }}
+{{- else -}}
+{{ .name }}
+{{- end -}}
+{{- end -}}
@@ -0,0 +1,244 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/helmchart"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	defaultHelmChartLoaderOutputPath = "{{ $rel := Rel .YamlsPath }}source/{{ $rel }}{{ if ne $rel \".\" }}/..{{end}}/{{ FilePathBase .YamlsPath }}-rendered/"
+	defaultHelmChartNamespace        = "default"
+)
+
+// HelmChartLoader implements Transformer interface
+type HelmChartLoader struct {
+	Config    transformertypes.Transformer
+	Env       *environment.Environment
+	HCLConfig *HelmChartLoaderYamlConfig
+}
+
+// HelmChartLoaderYamlConfig stores the config
+type HelmChartLoaderYamlConfig struct {
+	OutputPath  string `yaml:"outputPath"`
+	ReleaseName string `yaml:"releaseName"`
+	Namespace   string `yaml:"namespace"`
+	KubeVersion string `yaml:"kubeVersion"`
+}
+
+// Init Initializes the transformer
+func (t *HelmChartLoader) Init(tc transformertypes.Transformer, e *environment.Environment) error {
+	t.Config = tc
+	t.Env = e
+	t.HCLConfig = &HelmChartLoaderYamlConfig{}
+	err := common.GetObjFromInterface(t.Config.Spec.Config, t.HCLConfig)
+	if err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.HCLConfig, err)
+		return err
+	}
+	if t.HCLConfig.OutputPath == "" {
+		t.HCLConfig.OutputPath = defaultHelmChartLoaderOutputPath
+	}
+	if t.HCLConfig.Namespace == "" {
+		t.HCLConfig.Namespace = defaultHelmChartNamespace
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *HelmChartLoader) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each subdirectory
+func (t *HelmChartLoader) DirectoryDetect(dir string) (namedServices map[string][]transformertypes.Artifact, err error) {
+	if !helmchart.IsChartDir(dir) {
+		return nil, nil
+	}
+	na := transformertypes.Artifact{
+		Type: artifacts.HelmChartInSourceArtifactType,
+		Paths: map[transformertypes.PathType][]string{
+			artifacts.HelmChartPathType:  {dir},
+			artifacts.ServiceDirPathType: {dir},
+		},
+	}
+	return map[string][]transformertypes.Artifact{"": {na}}, nil
+}
+
+// Transform transforms artifacts
+func (t *HelmChartLoader) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) (pathMappings []transformertypes.PathMapping, createdArtifacts []transformertypes.Artifact, err error) {
+	pathMappings = []transformertypes.PathMapping{}
+	apis := []apiresource.IAPIResource{new(apiresource.Deployment), new(apiresource.Service)}
+	for _, a := range newArtifacts {
+		if len(a.Paths[artifacts.HelmChartPathType]) == 0 {
+			logrus.Errorf("the artifact %s of type %s does not have the path to a helm chart", a.Name, a.Type)
+			continue
+		}
+		chartPath := a.Paths[artifacts.HelmChartPathType][0]
+		var clusterConfig collecttypes.ClusterMetadata
+		if err := a.GetConfig(ClusterMetadata, &clusterConfig); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", clusterConfig, err)
+			continue
+		}
+		chart, err := helmchart.LoadChart(chartPath)
+		if err != nil {
+			logrus.Errorf("failed to load the helm chart at path %s . Error: %q", chartPath, err)
+			continue
+		}
+		values, err := t.getValues(chart, chartPath)
+		if err != nil {
+			logrus.Errorf("failed to get the values for the helm chart at path %s . Error: %q", chartPath, err)
+			continue
+		}
+		options := helmchart.RenderOptions{
+			ReleaseName: t.HCLConfig.ReleaseName,
+			Namespace:   t.HCLConfig.Namespace,
+			KubeVersion: t.HCLConfig.KubeVersion,
+			APIVersions: getAPIVersions(clusterConfig),
+		}
+		if options.ReleaseName == "" {
+			options.ReleaseName = chart.Metadata.Name
+		}
+		rendered, err := helmchart.Render(chart, values, options)
+		if err != nil {
+			logrus.Errorf("failed to render the helm chart at path %s . Error: %q", chartPath, err)
+			continue
+		}
+		renderedPath := filepath.Join(t.Env.TempPath, "helm-chart-rendered-"+common.GetRandomString())
+		if err := writeRenderedManifests(chart.Metadata.Name, rendered, renderedPath); err != nil {
+			logrus.Errorf("failed to write the rendered manifests of the helm chart at path %s . Error: %q", chartPath, err)
+			continue
+		}
+		tempDest := filepath.Join(t.Env.TempPath, "helm-chart-versionchanged-"+common.GetRandomString())
+		if _, err := apiresource.TransformObjsInSourceAndPersist(renderedPath, tempDest, apis, clusterConfig); err != nil {
+			logrus.Errorf("Unable to transform objs at %s : %s", renderedPath, err)
+			continue
+		}
+		parameterizers := helmchart.GetParameterizers(chart, values, options, rendered)
+		logrus.Debugf("recovered %d parameterizers from the templates of the helm chart %s", len(parameterizers), chart.Metadata.Name)
+		outputPathKey := outputPathTemplateName + common.GetRandomString()
+		outputPath := fmt.Sprintf("{{ .%s }}", outputPathKey)
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.PathTemplatePathMappingType,
+			SrcPath:        t.HCLConfig.OutputPath,
+			TemplateConfig: OutputPathParams{PathTemplateName: outputPathKey, YamlsPath: chartPath},
+		})
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  tempDest,
+			DestPath: outputPath,
+		})
+		na := transformertypes.Artifact{
+			Name: chart.Metadata.Name,
+			Type: artifacts.KubernetesYamlsInSourceArtifactType,
+			Paths: map[transformertypes.PathType][]string{
+				artifacts.KubernetesYamlsPathType: {outputPath},
+			},
+			Configs: map[transformertypes.ConfigType]interface{}{
+				ParameterizersConfigType: parameterizers,
+			},
+		}
+		createdArtifacts = append(createdArtifacts, na)
+	}
+	return pathMappings, createdArtifacts, nil
+}
+
+// getValues merges the values files selected by the user
+func (t *HelmChartLoader) getValues(chart *helmchart.Chart, chartPath string) (map[string]interface{}, error) {
+	valuesFiles := helmchart.GetValuesFiles(chartPath)
+	if len(valuesFiles) > 1 {
+		def := []string{}
+		if common.IsPresent(valuesFiles, helmchart.ValuesFileName) {
+			def = append(def, helmchart.ValuesFileName)
+		}
+		quesKey := common.JoinQASubKeys(common.ConfigHelmChartsKey, `"`+chart.Metadata.Name+`"`, common.ConfigHelmChartValuesFilesKeySegment)
+		valuesFiles = qaengine.FetchMultiSelectAnswer(
+			quesKey,
+			fmt.Sprintf("Select the values files to use for rendering the helm chart %s :", chart.Metadata.Name),
+			[]string{"The values files are merged in order, with the values in the later files taking precedence."},
+			def,
+			valuesFiles,
+		)
+	}
+	valuesList := []map[string]interface{}{}
+	for _, valuesFile := range valuesFiles {
+		values := map[string]interface{}{}
+		valuesPath := filepath.Join(chartPath, valuesFile)
+		if err := common.ReadYaml(valuesPath, &values); err != nil {
+			return nil, fmt.Errorf("failed to read the values file at path %s . Error: %q", valuesPath, err)
+		}
+		valuesList = append(valuesList, values)
+	}
+	return helmchart.CoalesceValues(valuesList...), nil
+}
+
+// getAPIVersions returns the api versions and kinds supported by the target cluster
+func getAPIVersions(clusterConfig collecttypes.ClusterMetadata) []string {
+	apiVersions := []string{}
+	for kind, groupVersions := range clusterConfig.Spec.APIKindVersionMap {
+		for _, groupVersion := range groupVersions {
+			if _, err := schema.ParseGroupVersion(groupVersion); err != nil {
+				continue
+			}
+			if !common.IsPresent(apiVersions, groupVersion) {
+				apiVersions = append(apiVersions, groupVersion)
+			}
+			apiVersions = append(apiVersions, groupVersion+"/"+kind)
+		}
+	}
+	return apiVersions
+}
+
+// writeRenderedManifests writes the non empty rendered manifests to files named after their templates
+func writeRenderedManifests(chartName string, rendered map[string]string, outputPath string) error {
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	for name, manifest := range rendered {
+		ext := path.Ext(name)
+		if (ext != ".yaml" && ext != ".yml") || strings.TrimSpace(manifest) == "" {
+			continue
+		}
+		parts := []string{}
+		for _, part := range strings.Split(strings.TrimPrefix(name, chartName+"/"), "/") {
+			if part == "templates" || part == "charts" {
+				continue
+			}
+			parts = append(parts, part)
+		}
+		manifestPath := filepath.Join(outputPath, strings.Join(parts, "-"))
+		if err := os.WriteFile(manifestPath, []byte(manifest), common.DefaultFilePermission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
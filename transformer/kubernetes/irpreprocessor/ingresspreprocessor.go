@@ -23,6 +23,7 @@ import (
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/qaengine"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 	core "k8s.io/kubernetes/pkg/apis/core"
 )
@@ -38,6 +39,14 @@ func (opt *ingressPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 			if portForwarding.ServicePort.Number == 0 {
 				continue
 			}
+			if service.Internal {
+				// the services on internal networks are only reachable in the cluster
+				logrus.Debugf("not exposing the port %d of the service %s , since it is only on internal networks", portForwarding.ServicePort.Number, serviceName)
+				portForwarding.ServiceType = core.ServiceTypeClusterIP
+				portForwarding.ServiceRelPath = ""
+				tempService.ServiceToPodPortForwardings[portForwardingIdx] = portForwarding
+				continue
+			}
 			if portForwarding.ServiceRelPath == "" {
 				portForwarding.ServiceRelPath = "/" + serviceName
 			}
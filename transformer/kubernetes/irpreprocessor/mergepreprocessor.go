@@ -33,7 +33,7 @@ func (opt *mergePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 		pfs := service.ServiceToPodPortForwardings
 		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{}
 		for _, pf := range pfs {
-			service.AddPortForwarding(pf.ServicePort, pf.PodPort, pf.ServiceRelPath)
+			service.AddServiceToPodPortForwarding(pf)
 		}
 		for _, c := range service.Containers {
 			for _, p := range c.Ports {
@@ -64,6 +64,8 @@ func (opt *mergePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 
 func (opt *mergePreprocessor) mergeContainers(sContainers []core.Container) []core.Container {
 	containers := map[string]core.Container{}
+	// the containers are kept in the order in which they first appear
+	containerNames := []string{}
 	for _, coreContainer := range sContainers {
 		var container core.Container
 		var ok bool
@@ -71,6 +73,7 @@ func (opt *mergePreprocessor) mergeContainers(sContainers []core.Container) []co
 			continue
 		}
 		if container, ok = containers[coreContainer.Name]; !ok {
+			containerNames = append(containerNames, coreContainer.Name)
 			container = coreContainer
 			uniquePorts := []core.ContainerPort{}
 			for _, ccp := range coreContainer.Ports {
@@ -130,11 +133,10 @@ func (opt *mergePreprocessor) mergeContainers(sContainers []core.Container) []co
 		}
 		container.Env = uniqueEnvVars
 		containers[coreContainer.Name] = container
-		break
 	}
 	sContainers = []core.Container{}
-	for _, c := range containers {
-		sContainers = append(sContainers, c)
+	for _, containerName := range containerNames {
+		sContainers = append(sContainers, containers[containerName])
 	}
 	return sContainers
 }
@@ -171,7 +171,7 @@ func (p registryPreProcessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 	for serviceName, service := range ir.Services {
 		for i, container := range service.Containers {
 			if common.IsPresent(newImageNames, container.Image) {
-				image, tag := common.GetImageNameAndTag(container.Image)
+				image, tag := common.GetImageNameAndTag(common.TagNewImageName(container.Image))
 				if registryToPushImagesTo != "" && registryNamespace != "" {
 					container.Image = registryToPushImagesTo + "/" + registryNamespace + "/" + image + ":" + tag
 				} else if registryNamespace != "" {
@@ -17,6 +17,9 @@
 package irpreprocessor
 
 import (
+	"fmt"
+	"sort"
+
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	"github.com/sirupsen/logrus"
@@ -38,12 +41,35 @@ func (ep replicaPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 		logrus.Errorf("Replica count %s is not a number. Reverting to default %d.", replicaCountStr, minReplicas)
 		replicaCount = minReplicas
 	}
+	useInferredReplicas := false
+	if inferredReplicas := getInferredReplicas(ir); len(inferredReplicas) > 0 {
+		useInferredReplicas = commonqa.ConfirmInferredReplicaCounts(inferredReplicas)
+	}
 	for k, scObj := range ir.Services {
-		if scObj.Replicas < replicaCount {
+		if useInferredReplicas && scObj.ReplicasSource != "" {
+			logrus.Debugf("Using the replica count %d inferred from %s for the service %s", scObj.Replicas, scObj.ReplicasSource, scObj.Name)
+		} else if scObj.Replicas < replicaCount {
 			scObj.Replicas = replicaCount
 		}
+		if scObj.MaxReplicas > 0 && scObj.Replicas > scObj.MaxReplicas {
+			logrus.Debugf("limiting the replicas of the service %s to %d", scObj.Name, scObj.MaxReplicas)
+			scObj.Replicas = scObj.MaxReplicas
+		}
 		ir.Services[k] = scObj
 	}
 
 	return ir, nil
 }
+
+// getInferredReplicas returns a sorted summary of the replica counts inferred from the source platform
+func getInferredReplicas(ir irtypes.IR) []string {
+	inferredReplicas := []string{}
+	for _, scObj := range ir.Services {
+		if scObj.ReplicasSource == "" {
+			continue
+		}
+		inferredReplicas = append(inferredReplicas, fmt.Sprintf("%s : %d (from %s)", scObj.Name, scObj.Replicas, scObj.ReplicasSource))
+	}
+	sort.Strings(inferredReplicas)
+	return inferredReplicas
+}
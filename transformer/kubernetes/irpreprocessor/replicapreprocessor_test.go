@@ -0,0 +1,55 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+func TestReplicaPreprocessor(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", nil, nil, nil, false, false)
+
+	ir := irtypes.NewIR()
+	web := irtypes.NewServiceWithName("web")
+	cart := irtypes.NewServiceWithName("cart")
+	// services with local state that cannot be shared are limited to a single replica
+	cart.MaxReplicas = 1
+	batch := irtypes.NewServiceWithName("batch")
+	batch.Replicas = 5
+	batch.MaxReplicas = 3
+	ir.Services = map[string]irtypes.Service{"web": web, "cart": cart, "batch": batch}
+
+	actual, err := replicaPreprocessor{}.preprocess(ir)
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	expected := map[string]int{"web": minReplicas, "cart": 1, "batch": 3}
+	replicas := map[string]int{}
+	for sn, s := range actual.Services {
+		replicas[sn] = s.Replicas
+	}
+	if diff := cmp.Diff(expected, replicas); diff != "" {
+		t.Fatalf("wrong replicas for the services. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	shortDNSNameFormat          = "Short name (<service>)"
+	fullyQualifiedDNSNameFormat = "Fully qualified name (<service>.<namespace>.svc.cluster.local)"
+	defaultNamespace            = "default"
+	rewriteSeparator            = " -> "
+)
+
+var (
+	// hostInURLRegex matches the host of a url or of a connection string with credentials
+	hostInURLRegex = regexp.MustCompile(`(?:://|@)([A-Za-z0-9][A-Za-z0-9_.-]*)`)
+	// hostWithPortRegex matches a host:port pair
+	hostWithPortRegex = regexp.MustCompile(`(?:^|[\s"'=,;(])([A-Za-z0-9][A-Za-z0-9_.-]*):[0-9]+`)
+	// hostRegex matches a value that is just a host
+	hostRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// hostEnvNameRegex matches the names of env vars whose values can be just a host
+	hostEnvNameRegex = regexp.MustCompile(`(?i)(HOST|ADDR|SERVER|ENDPOINT|URL|URI|SERVICE)`)
+	// namespacedDNSNameRegex matches the dns names <service>.<namespace>, <service>.<namespace>.svc and <service>.<namespace>.svc.cluster.local
+	namespacedDNSNameRegex = regexp.MustCompile(`^([a-z0-9_-]+)\.[a-z0-9-]+(\.svc(\.cluster\.local)?)?$`)
+	rewrites               = []ServiceDiscoveryRewrite{}
+	rewritesMutex          sync.Mutex
+)
+
+// ServiceDiscoveryRewrite is a reference to another service and whether it was rewritten into a kubernetes dns name
+type ServiceDiscoveryRewrite struct {
+	Host      string
+	DNSName   string
+	Rewritten bool
+}
+
+// serviceReference is the service a host refers to
+type serviceReference struct {
+	serviceName string
+	// exact is true if the host is one of the aliases of the service, instead of a dns name with a namespace
+	exact bool
+}
+
+// serviceDiscoveryPreprocessor rewrites references to other services into kubernetes dns names
+type serviceDiscoveryPreprocessor struct {
+}
+
+func (sp serviceDiscoveryPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	aliases := getServiceAliases(ir)
+	if len(aliases) == 0 {
+		return ir, nil
+	}
+	references := map[string]serviceReference{}
+	forEachRewritableValue(&ir, func(value string, allowHostOnly bool) string {
+		for _, host := range findHostReferences(value, allowHostOnly) {
+			if reference, ok := getReferencedService(host, aliases); ok {
+				references[host] = reference
+			}
+		}
+		return value
+	})
+	if len(references) == 0 {
+		return ir, nil
+	}
+	dnsNameFormat := qaengine.FetchSelectAnswer(
+		common.ConfigServiceDiscoveryDNSNameFormatKey,
+		"Select the format of the dns names that references to other services should be rewritten to :",
+		[]string{"References to other services were found in the environment variables and config files"},
+		shortDNSNameFormat,
+		[]string{shortDNSNameFormat, fullyQualifiedDNSNameFormat},
+	)
+	namespace := ""
+	if dnsNameFormat == fullyQualifiedDNSNameFormat {
+		namespace = qaengine.FetchStringAnswer(
+			common.ConfigServiceDiscoveryNamespaceKey,
+			"Enter the namespace the services will be deployed to :",
+			[]string{"The namespace is used in the fully qualified dns names of the services"},
+			defaultNamespace,
+		)
+	}
+	candidateRewrites := []string{}
+	defaultRewrites := []string{}
+	for host, reference := range references {
+		dnsName := reference.serviceName
+		if namespace != "" {
+			dnsName = fmt.Sprintf("%s.%s.svc.cluster.local", reference.serviceName, namespace)
+		}
+		if strings.EqualFold(host, dnsName) {
+			continue
+		}
+		candidateRewrites = append(candidateRewrites, host+rewriteSeparator+dnsName)
+		if reference.exact {
+			defaultRewrites = append(defaultRewrites, host+rewriteSeparator+dnsName)
+		}
+	}
+	if len(candidateRewrites) == 0 {
+		return ir, nil
+	}
+	sort.Strings(candidateRewrites)
+	sort.Strings(defaultRewrites)
+	selectedRewrites := qaengine.FetchMultiSelectAnswer(
+		common.ConfigServiceDiscoveryRewritesKey,
+		"Select the references to other services that should be rewritten :",
+		[]string{
+			"The references to the names and aliases of the services are selected by default",
+			"Select the references with a namespace only if the services are deployed to the same namespace",
+		},
+		defaultRewrites,
+		candidateRewrites,
+	)
+	recordRewrites(candidateRewrites, selectedRewrites)
+	for _, rewrite := range selectedRewrites {
+		parts := strings.SplitN(rewrite, rewriteSeparator, 2)
+		if len(parts) != 2 {
+			logrus.Warnf("Ignoring the invalid service discovery rewrite %s", rewrite)
+			continue
+		}
+		host, dnsName := parts[0], parts[1]
+		logrus.Debugf("Rewriting the references to %s into %s", host, dnsName)
+		quotedHost := regexp.QuoteMeta(host)
+		inURLRegex := regexp.MustCompile(`(?i)(://|@)` + quotedHost + `([:/?#"'\s,;)]|$)`)
+		withPortRegex := regexp.MustCompile(`(?i)(^|[\s"'=,;(])` + quotedHost + `(:[0-9]+)`)
+		forEachRewritableValue(&ir, func(value string, allowHostOnly bool) string {
+			if allowHostOnly && strings.EqualFold(value, host) {
+				return dnsName
+			}
+			value = inURLRegex.ReplaceAllString(value, "${1}"+dnsName+"${2}")
+			return withPortRegex.ReplaceAllString(value, "${1}"+dnsName+"${2}")
+		})
+	}
+	return ir, nil
+}
+
+// getServiceAliases returns the lower case names and hostnames that other services can use to reach a service
+func getServiceAliases(ir irtypes.IR) map[string]string {
+	aliases := map[string]string{}
+	for serviceName, service := range ir.Services {
+		aliases[strings.ToLower(serviceName)] = serviceName
+		if service.Hostname != "" {
+			aliases[strings.ToLower(service.Hostname)] = serviceName
+		}
+	}
+	return aliases
+}
+
+// getReferencedService returns the service a host refers to.
+// A host refers to a service if it is one of its aliases, or a dns name of one of its aliases in a namespace,
+// like <service>.<namespace> or <service>.<namespace>.svc.cluster.local .
+// Other domain names, like api.github.com , do not refer to the service api .
+func getReferencedService(host string, aliases map[string]string) (serviceReference, bool) {
+	host = strings.ToLower(host)
+	if serviceName, ok := aliases[host]; ok {
+		return serviceReference{serviceName: serviceName, exact: true}, true
+	}
+	matches := namespacedDNSNameRegex.FindStringSubmatch(host)
+	if matches == nil {
+		return serviceReference{}, false
+	}
+	serviceName, ok := aliases[matches[1]]
+	return serviceReference{serviceName: serviceName}, ok
+}
+
+// recordRewrites adds the rewrites that were offered to the report
+func recordRewrites(candidateRewrites, selectedRewrites []string) {
+	rewritesMutex.Lock()
+	defer rewritesMutex.Unlock()
+	for _, candidateRewrite := range candidateRewrites {
+		parts := strings.SplitN(candidateRewrite, rewriteSeparator, 2)
+		rewrite := ServiceDiscoveryRewrite{Host: parts[0], DNSName: parts[1], Rewritten: common.IsPresent(selectedRewrites, candidateRewrite)}
+		if !common.IsPresent(rewrites, rewrite) {
+			rewrites = append(rewrites, rewrite)
+		}
+	}
+}
+
+// GetServiceDiscoveryRewrites returns the references to other services that were found during the run, sorted by host
+func GetServiceDiscoveryRewrites() []ServiceDiscoveryRewrite {
+	rewritesMutex.Lock()
+	defer rewritesMutex.Unlock()
+	sorted := append([]ServiceDiscoveryRewrite{}, rewrites...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Host < sorted[j].Host })
+	return sorted
+}
+
+// findHostReferences returns the hosts referenced in a value
+func findHostReferences(value string, allowHostOnly bool) []string {
+	hosts := []string{}
+	for _, re := range []*regexp.Regexp{hostInURLRegex, hostWithPortRegex} {
+		for _, match := range re.FindAllStringSubmatch(value, -1) {
+			hosts = common.AppendIfNotPresent(hosts, strings.TrimSuffix(match[1], "."))
+		}
+	}
+	if allowHostOnly && hostRegex.MatchString(value) {
+		hosts = common.AppendIfNotPresent(hosts, value)
+	}
+	return hosts
+}
+
+// forEachRewritableValue updates the env vars, commands and config map contents that can contain references to other services
+func forEachRewritableValue(ir *irtypes.IR, update func(value string, allowHostOnly bool) string) {
+	for serviceName, service := range ir.Services {
+		for i, container := range service.Containers {
+			for j, env := range container.Env {
+				if env.ValueFrom != nil {
+					continue
+				}
+				container.Env[j].Value = update(env.Value, hostEnvNameRegex.MatchString(env.Name))
+			}
+			for j, arg := range container.Args {
+				container.Args[j] = update(arg, false)
+			}
+			for j, command := range container.Command {
+				container.Command[j] = update(command, false)
+			}
+			service.Containers[i] = container
+		}
+		ir.Services[serviceName] = service
+	}
+	for i, storage := range ir.Storages {
+		if storage.StorageType != irtypes.ConfigMapKind && storage.StorageType != irtypes.SecretKind {
+			continue
+		}
+		for key, content := range storage.Content {
+			storage.Content[key] = []byte(update(string(content), false))
+		}
+		ir.Storages[i] = storage
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestFindHostReferences(t *testing.T) {
+	logrus.SetLevel(logrus.DebugLevel)
+
+	t.Run("find the hosts in urls and host port pairs", func(t *testing.T) {
+		// Setup
+		value := "postgres://user:pass@db:5432/app,cache:6379 file.txt"
+		want := []string{"user", "db", "cache"}
+
+		// Test
+		actual := findHostReferences(value, false)
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("Failed to get the expected properly. Differences:\n%s", cmp.Diff(want, actual))
+		}
+	})
+
+	t.Run("find a value that is just a host only when allowed", func(t *testing.T) {
+		// Setup
+		value := "db.internal.example.com"
+
+		// Test
+		if actual := findHostReferences(value, false); len(actual) != 0 {
+			t.Fatalf("Expected no hosts. Actual: %+v", actual)
+		}
+		want := []string{value}
+		actual := findHostReferences(value, true)
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("Failed to get the expected properly. Differences:\n%s", cmp.Diff(want, actual))
+		}
+	})
+}
+
+func TestGetReferencedService(t *testing.T) {
+	logrus.SetLevel(logrus.DebugLevel)
+	aliases := map[string]string{"db": "db", "cache-host": "cache"}
+
+	aliases["api"] = "api"
+
+	testcases := []struct {
+		host          string
+		wantReference serviceReference
+		wantOk        bool
+	}{
+		{host: "db", wantReference: serviceReference{serviceName: "db", exact: true}, wantOk: true},
+		{host: "cache-host", wantReference: serviceReference{serviceName: "cache", exact: true}, wantOk: true},
+		{host: "DB.prod", wantReference: serviceReference{serviceName: "db"}, wantOk: true},
+		{host: "db.prod.svc", wantReference: serviceReference{serviceName: "db"}, wantOk: true},
+		{host: "db.prod.svc.cluster.local", wantReference: serviceReference{serviceName: "db"}, wantOk: true},
+		{host: "db.internal.example.com", wantOk: false},
+		{host: "api.github.com", wantOk: false},
+		{host: "web", wantOk: false},
+		{host: "web.prod", wantOk: false},
+	}
+	for _, testcase := range testcases {
+		actualReference, actualOk := getReferencedService(testcase.host, aliases)
+		if actualReference != testcase.wantReference || actualOk != testcase.wantOk {
+			t.Fatalf("Failed to get the expected service for the host %s . Expected: %+v %t Actual: %+v %t", testcase.host, testcase.wantReference, testcase.wantOk, actualReference, actualOk)
+		}
+	}
+}
+
+func TestServiceDiscoveryPreprocessor(t *testing.T) {
+	logrus.SetLevel(logrus.DebugLevel)
+	qaengine.StartEngine(true, 0, true)
+
+	t.Run("rewrite only the references to the aliases of the services by default", func(t *testing.T) {
+		// Setup
+		ir := irtypes.NewIR()
+		web := irtypes.Service{Name: "web"}
+		web.Containers = []core.Container{{
+			Name: "web",
+			Env: []core.EnvVar{
+				{Name: "CACHE_HOST", Value: "cache-host"},
+				{Name: "DB_URL", Value: "postgres://db.prod:5432/app"},
+				{Name: "GITHUB_URL", Value: "https://api.github.com/repos"},
+			},
+		}}
+		ir.Services["web"] = web
+		cache := irtypes.Service{Name: "cache"}
+		cache.Hostname = "cache-host"
+		ir.Services["cache"] = cache
+		ir.Services["db"] = irtypes.Service{Name: "db"}
+		ir.Services["api"] = irtypes.Service{Name: "api"}
+		want := []core.EnvVar{
+			{Name: "CACHE_HOST", Value: "cache"},
+			{Name: "DB_URL", Value: "postgres://db.prod:5432/app"},
+			{Name: "GITHUB_URL", Value: "https://api.github.com/repos"},
+		}
+		wantRewrites := []ServiceDiscoveryRewrite{
+			{Host: "cache-host", DNSName: "cache", Rewritten: true},
+			{Host: "db.prod", DNSName: "db", Rewritten: false},
+		}
+
+		// Test
+		actual, err := serviceDiscoveryPreprocessor{}.preprocess(ir)
+		if err != nil {
+			t.Fatalf("Failed to preprocess the IR. Error: %q", err)
+		}
+		if actualEnv := actual.Services["web"].Containers[0].Env; !cmp.Equal(actualEnv, want) {
+			t.Fatalf("Failed to rewrite the references properly. Differences:\n%s", cmp.Diff(want, actualEnv))
+		}
+		if actualRewrites := GetServiceDiscoveryRewrites(); !cmp.Equal(actualRewrites, wantRewrites) {
+			t.Fatalf("Failed to record the rewrites properly. Differences:\n%s", cmp.Diff(wantRewrites, actualRewrites))
+		}
+	})
+}
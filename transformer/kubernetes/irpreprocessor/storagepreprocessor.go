@@ -0,0 +1,145 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	defaultPVCSize = "1Gi"
+)
+
+var (
+	// unsupportedHostPaths lists the host paths that cannot be migrated to persistent volume claims, most specific first
+	unsupportedHostPaths = []struct {
+		path   string
+		reason string
+	}{
+		{path: "/var/run/docker.sock", reason: "the docker socket of the node is not available in kubernetes"},
+		{path: "/var/lib/docker", reason: "the docker data directory is not available in kubernetes"},
+		{path: "/var/run", reason: "the runtime state of the node should not be shared with containers"},
+		{path: "/run", reason: "the runtime state of the node should not be shared with containers"},
+		{path: "/proc", reason: "the proc filesystem of the node should not be shared with containers"},
+		{path: "/sys", reason: "the sys filesystem of the node should not be shared with containers"},
+		{path: "/dev", reason: "devices of the node should be exposed using device plugins"},
+		{path: "/etc", reason: "the configuration of the node should not be shared with containers"},
+	}
+)
+
+// storagePreprocessor migrates host paths to persistent volume claims and fills in the claim specs
+type storagePreprocessor struct {
+}
+
+func (sp storagePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	hostPaths := []string{}
+	for _, service := range ir.Services {
+		for _, volume := range service.Volumes {
+			if volume.HostPath == nil {
+				continue
+			}
+			if reason := GetUnsupportedHostPathReason(volume.HostPath.Path); reason != "" {
+				logrus.Debugf("The host path %s used by the service %s cannot be migrated to a persistent volume claim because %s", volume.HostPath.Path, service.Name, reason)
+				continue
+			}
+			hostPaths = common.AppendIfNotPresent(hostPaths, volume.HostPath.Path)
+		}
+	}
+	if len(hostPaths) > 0 {
+		sort.Strings(hostPaths)
+		selectedHostPaths := commonqa.HostPathsToConvertToPVC(hostPaths)
+		for serviceName, service := range ir.Services {
+			for i, volume := range service.Volumes {
+				if volume.HostPath == nil || !common.IsPresent(selectedHostPaths, volume.HostPath.Path) {
+					continue
+				}
+				readOnly := isVolumeReadOnly(service, volume.Name)
+				ir.AddStorage(irtypes.Storage{Name: volume.Name, StorageType: irtypes.PVCKind, SourcePath: volume.HostPath.Path})
+				service.Volumes[i].VolumeSource = core.VolumeSource{
+					PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: volume.Name, ReadOnly: readOnly},
+				}
+			}
+			ir.Services[serviceName] = service
+		}
+	}
+	accessModes := []string{string(core.ReadWriteOnce), string(core.ReadWriteMany), string(core.ReadOnlyMany)}
+	for i, storage := range ir.Storages {
+		if storage.StorageType != irtypes.PVCKind {
+			continue
+		}
+		defaultAccessMode := string(core.ReadWriteOnce)
+		if len(storage.AccessModes) > 0 {
+			defaultAccessMode = string(storage.AccessModes[0])
+		}
+		accessMode := commonqa.PVCAccessMode(storage.Name, defaultAccessMode, accessModes)
+		storage.AccessModes = []core.PersistentVolumeAccessMode{core.PersistentVolumeAccessMode(accessMode)}
+		defaultSize := defaultPVCSize
+		if size, ok := storage.Resources.Requests[core.ResourceStorage]; ok {
+			defaultSize = size.String()
+		}
+		sizeStr := commonqa.PVCSize(storage.Name, defaultSize)
+		size, err := resource.ParseQuantity(sizeStr)
+		if err != nil {
+			logrus.Errorf("The size %s of the persistent volume claim %s is not a valid quantity. Reverting to default %s. Error: %q", sizeStr, storage.Name, defaultPVCSize, err)
+			size = resource.MustParse(defaultPVCSize)
+		}
+		if storage.Resources.Requests == nil {
+			storage.Resources.Requests = core.ResourceList{}
+		}
+		storage.Resources.Requests[core.ResourceStorage] = size
+		ir.Storages[i] = storage
+	}
+	return ir, nil
+}
+
+// GetUnsupportedHostPathReason returns the reason why a host path cannot be migrated to a persistent volume claim.
+// It returns an empty string if the host path can be migrated.
+func GetUnsupportedHostPathReason(hostPath string) string {
+	cleanPath := filepath.Clean(hostPath)
+	for _, unsupportedHostPath := range unsupportedHostPaths {
+		if cleanPath == unsupportedHostPath.path || strings.HasPrefix(cleanPath, unsupportedHostPath.path+"/") {
+			return unsupportedHostPath.reason
+		}
+	}
+	return ""
+}
+
+// isVolumeReadOnly returns true if all the mounts of the volume in the service are read only
+func isVolumeReadOnly(service irtypes.Service, volumeName string) bool {
+	mounted := false
+	for _, container := range service.Containers {
+		for _, volumeMount := range container.VolumeMounts {
+			if volumeMount.Name != volumeName {
+				continue
+			}
+			if !volumeMount.ReadOnly {
+				return false
+			}
+			mounted = true
+		}
+	}
+	return mounted
+}
@@ -0,0 +1,105 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetUnsupportedHostPathReason(t *testing.T) {
+	testcases := []struct {
+		hostPath    string
+		unsupported bool
+	}{
+		{hostPath: "/var/run/docker.sock", unsupported: true},
+		{hostPath: "/var/run/", unsupported: true},
+		{hostPath: "/proc/cpuinfo", unsupported: true},
+		{hostPath: "/etc/../etc/hosts", unsupported: true},
+		{hostPath: "/data/app", unsupported: false},
+		{hostPath: "/var/runtime", unsupported: false},
+		{hostPath: "/devices", unsupported: false},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.hostPath, func(t *testing.T) {
+			if reason := GetUnsupportedHostPathReason(testcase.hostPath); (reason != "") != testcase.unsupported {
+				t.Fatalf("expected the host path %s to be unsupported: %t . Actual reason: %q", testcase.hostPath, testcase.unsupported, reason)
+			}
+		})
+	}
+}
+
+func TestStoragePreprocessor(t *testing.T) {
+	logrus.SetLevel(logrus.DebugLevel)
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{`move2kube.storages."logs".size="5Gi"`}, nil, nil, false, false)
+
+	// Setup
+	ir := irtypes.NewIR()
+	web := irtypes.Service{Name: "web"}
+	web.Containers = []core.Container{{
+		Name: "web",
+		VolumeMounts: []core.VolumeMount{
+			{Name: "content", MountPath: "/usr/share/nginx/html", ReadOnly: true},
+			{Name: "logs", MountPath: "/var/log/nginx"},
+			{Name: "docker", MountPath: "/var/run/docker.sock"},
+		},
+	}}
+	web.Volumes = []core.Volume{
+		{Name: "content", VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: "/srv/content"}}},
+		{Name: "logs", VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: "/srv/logs"}}},
+		{Name: "docker", VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: "/var/run/docker.sock"}}},
+	}
+	ir.Services["web"] = web
+	want := []core.Volume{
+		{Name: "content", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "content", ReadOnly: true}}},
+		{Name: "logs", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "logs"}}},
+		{Name: "docker", VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: "/var/run/docker.sock"}}},
+	}
+	wantSizes := map[string]string{"content": defaultPVCSize, "logs": "5Gi"}
+	wantSourcePaths := map[string]string{"content": "/srv/content", "logs": "/srv/logs"}
+
+	// Test
+	actual, err := storagePreprocessor{}.preprocess(ir)
+	if err != nil {
+		t.Fatalf("Failed to preprocess the IR. Error: %q", err)
+	}
+	if actualVolumes := actual.Services["web"].Volumes; !cmp.Equal(actualVolumes, want) {
+		t.Fatalf("Failed to migrate the host paths properly. Differences:\n%s", cmp.Diff(want, actualVolumes))
+	}
+	actualSizes := map[string]string{}
+	actualSourcePaths := map[string]string{}
+	for _, storage := range actual.Storages {
+		if !cmp.Equal(storage.AccessModes, []core.PersistentVolumeAccessMode{core.ReadWriteOnce}) {
+			t.Fatalf("Expected the claim %s to default to ReadWriteOnce. Actual: %+v", storage.Name, storage.AccessModes)
+		}
+		size := storage.Resources.Requests[core.ResourceStorage]
+		actualSizes[storage.Name] = size.String()
+		actualSourcePaths[storage.Name] = storage.SourcePath
+	}
+	if !cmp.Equal(actualSizes, wantSizes) {
+		t.Fatalf("Failed to size the claims properly. Differences:\n%s", cmp.Diff(wantSizes, actualSizes))
+	}
+	if !cmp.Equal(actualSourcePaths, wantSourcePaths) {
+		t.Fatalf("Failed to record the source paths of the claims properly. Differences:\n%s", cmp.Diff(wantSourcePaths, actualSourcePaths))
+	}
+}
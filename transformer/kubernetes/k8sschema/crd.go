@@ -0,0 +1,379 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/jsonschema"
+	"github.com/konveyor/move2kube/types"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// CRDKind is the kind of custom resource definitions
+	CRDKind = "CustomResourceDefinition"
+	// CRDGroup is the api group of custom resource definitions
+	CRDGroup = "apiextensions.k8s.io"
+	// CRDConversionStrategyNone is the conversion strategy where only the apiVersion changes between versions
+	CRDConversionStrategyNone = "None"
+	// CRDConversionStrategyWebhook is the conversion strategy where a webhook in the cluster converts between versions
+	CRDConversionStrategyWebhook = "Webhook"
+)
+
+// CRDs stores the custom resource definitions used to validate and convert custom resources
+type CRDs struct {
+	definitions map[schema.GroupKind]collecttypes.CustomResourceDefinitionMetadata
+}
+
+// NewCRDs returns the CRDs installed in the target cluster
+func NewCRDs(clusterSpec collecttypes.ClusterMetadataSpec) *CRDs {
+	crds := &CRDs{definitions: map[schema.GroupKind]collecttypes.CustomResourceDefinitionMetadata{}}
+	for _, crd := range clusterSpec.CustomResourceDefinitions {
+		crds.Add(crd)
+	}
+	return crds
+}
+
+// Add adds a CRD. The first definition of a group and kind wins, so the CRDs from the target cluster take precedence.
+func (c *CRDs) Add(crd collecttypes.CustomResourceDefinitionMetadata) {
+	gk := schema.GroupKind{Group: crd.Group, Kind: crd.Kind}
+	if _, ok := c.definitions[gk]; ok {
+		return
+	}
+	c.definitions[gk] = crd
+}
+
+// List returns all the CRDs
+func (c *CRDs) List() []collecttypes.CustomResourceDefinitionMetadata {
+	gks := []schema.GroupKind{}
+	for gk := range c.definitions {
+		gks = append(gks, gk)
+	}
+	sort.Slice(gks, func(i, j int) bool { return gks[i].String() < gks[j].String() })
+	crds := []collecttypes.CustomResourceDefinitionMetadata{}
+	for _, gk := range gks {
+		crds = append(crds, c.definitions[gk])
+	}
+	return crds
+}
+
+// AddFromResources adds all the CRDs among the resources
+func (c *CRDs) AddFromResources(resources []K8sResourceT) {
+	for _, resource := range resources {
+		if !IsCRD(resource) {
+			continue
+		}
+		crd, err := ParseCRD(resource)
+		if err != nil {
+			logrus.Warnf("failed to parse the custom resource definition. Error: %q", err)
+			continue
+		}
+		c.Add(crd)
+	}
+}
+
+// Get returns the CRD for the group and kind of the custom resource
+func (c *CRDs) Get(apiVersion, kind string) (collecttypes.CustomResourceDefinitionMetadata, bool) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return collecttypes.CustomResourceDefinitionMetadata{}, false
+	}
+	crd, ok := c.definitions[schema.GroupKind{Group: gv.Group, Kind: kind}]
+	return crd, ok
+}
+
+// ValidateCustomResource validates the custom resource against the schema of its version
+func (c *CRDs) ValidateCustomResource(resource K8sResourceT) error {
+	kind, apiVersion, name, err := GetInfoFromK8sResource(resource)
+	if err != nil {
+		return err
+	}
+	crd, ok := c.Get(apiVersion, kind)
+	if !ok {
+		return fmt.Errorf("there is no custom resource definition for the kind %s and apiVersion %s", kind, apiVersion)
+	}
+	version, ok := getCRDVersion(crd, apiVersion)
+	if !ok {
+		return fmt.Errorf("the custom resource definition for the kind %s does not have the version in the apiVersion %s", kind, apiVersion)
+	}
+	if len(version.Schema) == 0 {
+		return nil
+	}
+	if err := jsonschema.Validate(getJSONSchema(version.Schema), map[string]interface{}(resource)); err != nil {
+		return fmt.Errorf("the custom resource %s of kind %s is not valid for the version %s . Error: %q", name, kind, apiVersion, err)
+	}
+	return nil
+}
+
+// ConvertCustomResourceToSupportedVersion returns the apiVersion the custom resource should be converted to.
+// Versions preferred by the target cluster are tried first, followed by the storage version of the CRD.
+// The conversion is only done when the CRD does not use a conversion webhook, in which case the fields
+// stay the same across versions, and the converted resource is valid for the new version.
+func (c *CRDs) ConvertCustomResourceToSupportedVersion(resource K8sResourceT, clusterSpec collecttypes.ClusterMetadataSpec) (string, error) {
+	kind, apiVersion, name, err := GetInfoFromK8sResource(resource)
+	if err != nil {
+		return "", err
+	}
+	crd, ok := c.Get(apiVersion, kind)
+	if !ok {
+		return apiVersion, fmt.Errorf("there is no custom resource definition for the kind %s and apiVersion %s", kind, apiVersion)
+	}
+	candidates := []string{}
+	for _, supportedVersion := range clusterSpec.GetSupportedVersions(kind) {
+		if gv, err := schema.ParseGroupVersion(supportedVersion); err == nil && gv.Group == crd.Group {
+			candidates = append(candidates, supportedVersion)
+		}
+	}
+	if common.IsPresent(candidates, apiVersion) {
+		return apiVersion, nil
+	}
+	if len(candidates) == 0 {
+		if version, ok := getCRDVersion(crd, apiVersion); ok && version.Served {
+			return apiVersion, nil
+		}
+		for _, version := range crd.Versions {
+			if version.Storage {
+				candidates = append(candidates, schema.GroupVersion{Group: crd.Group, Version: version.Name}.String())
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return apiVersion, nil
+	}
+	if crd.ConversionStrategy == CRDConversionStrategyWebhook {
+		return apiVersion, fmt.Errorf("the custom resource %s of kind %s requires a conversion webhook to convert from the version %s to %s", name, kind, apiVersion, candidates[0])
+	}
+	for _, candidate := range candidates {
+		version, ok := getCRDVersion(crd, candidate)
+		if !ok || !version.Served {
+			continue
+		}
+		converted := K8sResourceT{}
+		for k, v := range resource {
+			converted[k] = v
+		}
+		converted["apiVersion"] = candidate
+		if len(version.Schema) != 0 {
+			if err := jsonschema.Validate(getJSONSchema(version.Schema), map[string]interface{}(converted)); err != nil {
+				logrus.Debugf("the custom resource %s of kind %s is not valid for the version %s . Error: %q", name, kind, candidate, err)
+				continue
+			}
+		}
+		return candidate, nil
+	}
+	return apiVersion, fmt.Errorf("the custom resource %s of kind %s cannot be converted from the version %s to any of %+v", name, kind, apiVersion, candidates)
+}
+
+// GetSpecReplicasPath returns the path to the replicas field of the custom resource, if its CRD has a scale subresource
+func (c *CRDs) GetSpecReplicasPath(apiVersion, kind string) string {
+	crd, ok := c.Get(apiVersion, kind)
+	if !ok {
+		return ""
+	}
+	version, ok := getCRDVersion(crd, apiVersion)
+	if !ok {
+		return ""
+	}
+	return strings.TrimPrefix(version.SpecReplicasPath, ".")
+}
+
+// GetCustomResourcesInDir returns the custom resources and custom resource definitions in the yamls in the directory.
+// Only resources whose api group is a domain name and not known to the scheme are considered custom resources.
+func GetCustomResourcesInDir(dir string) []K8sResourceT {
+	filePaths, err := common.GetFilesByExtInCurrDir(dir, []string{".yml", ".yaml"})
+	if err != nil {
+		logrus.Errorf("Unable to fetch yaml files at path %q Error: %q", dir, err)
+		return nil
+	}
+	resources := []K8sResourceT{}
+	for _, fileResources := range readK8sResourcesFromFiles(filePaths) {
+		for _, resource := range fileResources {
+			if IsCRD(resource) || isCustomResource(resource) {
+				resources = append(resources, resource)
+			}
+		}
+	}
+	return resources
+}
+
+func isCustomResource(resource K8sResourceT) bool {
+	kind, apiVersion, _, err := GetInfoFromK8sResource(resource)
+	if err != nil {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil || !strings.Contains(gv.Group, ".") || gv.Group == types.GroupName {
+		return false
+	}
+	return !scheme.Recognizes(gv.WithKind(kind))
+}
+
+// IsCRD returns true if the resource is a custom resource definition
+func IsCRD(resource K8sResourceT) bool {
+	kind, apiVersion, _, err := GetInfoFromK8sResource(resource)
+	if err != nil || kind != CRDKind {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	return err == nil && gv.Group == CRDGroup
+}
+
+// ParseCRD parses an apiextensions.k8s.io/v1 or v1beta1 custom resource definition
+func ParseCRD(resource K8sResourceT) (collecttypes.CustomResourceDefinitionMetadata, error) {
+	crd := collecttypes.CustomResourceDefinitionMetadata{}
+	spec, ok := resource["spec"].(map[string]interface{})
+	if !ok {
+		return crd, fmt.Errorf("the custom resource definition does not have a spec")
+	}
+	crd.Group, _ = spec["group"].(string)
+	names, _ := spec["names"].(map[string]interface{})
+	crd.Kind, _ = names["kind"].(string)
+	if crd.Group == "" || crd.Kind == "" {
+		return crd, fmt.Errorf("the custom resource definition does not have a group and kind")
+	}
+	crd.ConversionStrategy = CRDConversionStrategyNone
+	if conversion, ok := spec["conversion"].(map[string]interface{}); ok {
+		if strategy, ok := conversion["strategy"].(string); ok && strategy != "" {
+			crd.ConversionStrategy = strategy
+		}
+	}
+	// v1beta1 allows the schema and subresources to be shared by all the versions
+	commonSchema := getOpenAPIV3Schema(spec["validation"])
+	commonReplicasPath := getSpecReplicasPath(spec["subresources"])
+	versions, _ := spec["versions"].([]interface{})
+	if len(versions) == 0 {
+		if version, ok := spec["version"].(string); ok && version != "" {
+			versions = []interface{}{map[string]interface{}{"name": version, "served": true, "storage": true}}
+		}
+	}
+	for _, versionI := range versions {
+		version, ok := versionI.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		crdVersion := collecttypes.CustomResourceDefinitionVersion{
+			Schema:           getOpenAPIV3Schema(version["schema"]),
+			SpecReplicasPath: getSpecReplicasPath(version["subresources"]),
+		}
+		crdVersion.Name, _ = version["name"].(string)
+		crdVersion.Served, _ = version["served"].(bool)
+		crdVersion.Storage, _ = version["storage"].(bool)
+		if crdVersion.Name == "" {
+			continue
+		}
+		if crdVersion.Schema == nil {
+			crdVersion.Schema = commonSchema
+		}
+		if crdVersion.SpecReplicasPath == "" {
+			crdVersion.SpecReplicasPath = commonReplicasPath
+		}
+		crd.Versions = append(crd.Versions, crdVersion)
+	}
+	if len(crd.Versions) == 0 {
+		return crd, fmt.Errorf("the custom resource definition for the kind %s does not have any versions", crd.Kind)
+	}
+	return crd, nil
+}
+
+func getOpenAPIV3Schema(validationI interface{}) map[string]interface{} {
+	validation, ok := validationI.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	openAPIV3Schema, _ := validation["openAPIV3Schema"].(map[string]interface{})
+	return openAPIV3Schema
+}
+
+func getSpecReplicasPath(subresourcesI interface{}) string {
+	subresources, ok := subresourcesI.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	scale, ok := subresources["scale"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	specReplicasPath, _ := scale["specReplicasPath"].(string)
+	return specReplicasPath
+}
+
+func getCRDVersion(crd collecttypes.CustomResourceDefinitionMetadata, apiVersion string) (collecttypes.CustomResourceDefinitionVersion, bool) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil || gv.Group != crd.Group {
+		return collecttypes.CustomResourceDefinitionVersion{}, false
+	}
+	for _, version := range crd.Versions {
+		if version.Name == gv.Version {
+			return version, true
+		}
+	}
+	return collecttypes.CustomResourceDefinitionVersion{}, false
+}
+
+// getJSONSchema converts an openAPIV3Schema into a JSON schema that can be validated with the draft 4 validator
+func getJSONSchema(openAPIV3Schema map[string]interface{}) jsonschema.Schema {
+	converted, _ := convertOpenAPIV3Schema(openAPIV3Schema).(map[string]interface{})
+	return jsonschema.Schema(converted)
+}
+
+func convertOpenAPIV3Schema(value interface{}) interface{} {
+	switch actualValue := value.(type) {
+	case map[string]interface{}:
+		converted := map[string]interface{}{}
+		for k, v := range actualValue {
+			if strings.HasPrefix(k, "x-kubernetes-") || k == "nullable" {
+				continue
+			}
+			if k == "properties" || k == "patternProperties" || k == "definitions" {
+				// the keys here are field names and not schema keywords
+				if props, ok := v.(map[string]interface{}); ok {
+					convertedProps := map[string]interface{}{}
+					for propName, propSchema := range props {
+						convertedProps[propName] = convertOpenAPIV3Schema(propSchema)
+					}
+					converted[k] = convertedProps
+					continue
+				}
+			}
+			converted[k] = convertOpenAPIV3Schema(v)
+		}
+		if intOrString, _ := actualValue["x-kubernetes-int-or-string"].(bool); intOrString {
+			delete(converted, "type")
+			converted["anyOf"] = []interface{}{map[string]interface{}{"type": "integer"}, map[string]interface{}{"type": "string"}}
+		}
+		if nullable, _ := actualValue["nullable"].(bool); nullable {
+			if t, ok := converted["type"].(string); ok {
+				converted["type"] = []interface{}{t, "null"}
+			}
+		}
+		return converted
+	case []interface{}:
+		converted := []interface{}{}
+		for _, v := range actualValue {
+			converted = append(converted, convertOpenAPIV3Schema(v))
+		}
+		return converted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema_test
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"gopkg.in/yaml.v3"
+)
+
+const testCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+    - name: v1alpha1
+      served: true
+      storage: false
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                size:
+                  type: integer
+    - name: v1
+      served: true
+      storage: true
+      subresources:
+        scale:
+          specReplicasPath: .spec.size
+          statusReplicasPath: .status.size
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              required: [size]
+              properties:
+                size:
+                  type: integer
+                  nullable: true
+                port:
+                  x-kubernetes-int-or-string: true
+`
+
+func getTestResource(t *testing.T, data string) k8sschema.K8sResourceT {
+	resource := k8sschema.K8sResourceT{}
+	if err := yaml.Unmarshal([]byte(data), &resource); err != nil {
+		t.Fatalf("failed to unmarshal the resource. Error: %q", err)
+	}
+	return resource
+}
+
+func TestCRDs(t *testing.T) {
+	crdResource := getTestResource(t, testCRD)
+	if !k8sschema.IsCRD(crdResource) {
+		t.Fatalf("expected the resource to be a custom resource definition")
+	}
+	crds := k8sschema.NewCRDs(collecttypes.ClusterMetadataSpec{})
+	crds.AddFromResources([]k8sschema.K8sResourceT{crdResource})
+	if _, ok := crds.Get("example.com/v1", "Widget"); !ok {
+		t.Fatalf("failed to find the custom resource definition for the kind Widget")
+	}
+	if path := crds.GetSpecReplicasPath("example.com/v1", "Widget"); path != "spec.size" {
+		t.Fatalf("expected the replicas path to be spec.size . Actual: %s", path)
+	}
+	t.Run("validate a valid custom resource", func(t *testing.T) {
+		cr := getTestResource(t, "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w1\nspec:\n  size: 2\n  port: http\n")
+		if err := crds.ValidateCustomResource(cr); err != nil {
+			t.Fatalf("expected the custom resource to be valid. Error: %q", err)
+		}
+	})
+	t.Run("validate an invalid custom resource", func(t *testing.T) {
+		cr := getTestResource(t, "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w1\nspec:\n  size: two\n")
+		if err := crds.ValidateCustomResource(cr); err == nil {
+			t.Fatalf("expected the custom resource to be invalid")
+		}
+	})
+	t.Run("convert a custom resource to the version preferred by the cluster", func(t *testing.T) {
+		cr := getTestResource(t, "apiVersion: example.com/v1alpha1\nkind: Widget\nmetadata:\n  name: w1\nspec:\n  size: 2\n")
+		clusterSpec := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{"Widget": {"example.com/v1"}}}
+		apiVersion, err := crds.ConvertCustomResourceToSupportedVersion(cr, clusterSpec)
+		if err != nil {
+			t.Fatalf("failed to convert the custom resource. Error: %q", err)
+		}
+		if apiVersion != "example.com/v1" {
+			t.Fatalf("expected the custom resource to be converted to example.com/v1 . Actual: %s", apiVersion)
+		}
+	})
+	t.Run("do not convert a custom resource that is invalid in the new version", func(t *testing.T) {
+		cr := getTestResource(t, "apiVersion: example.com/v1alpha1\nkind: Widget\nmetadata:\n  name: w1\nspec: {}\n")
+		clusterSpec := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{"Widget": {"example.com/v1"}}}
+		if _, err := crds.ConvertCustomResourceToSupportedVersion(cr, clusterSpec); err == nil {
+			t.Fatalf("expected the conversion to fail since spec.size is required in example.com/v1")
+		}
+	})
+}
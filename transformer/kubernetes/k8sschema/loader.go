@@ -0,0 +1,143 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxCachedK8sYamlBytes is the maximum total size of the yaml files whose resources are cached
+	maxCachedK8sYamlBytes = 256 * 1024 * 1024
+)
+
+// cachedK8sYamlFile has the resources decoded from a version of a yaml file
+type cachedK8sYamlFile struct {
+	size      int64
+	modTime   time.Time
+	resources []K8sResourceT
+}
+
+var (
+	cachedK8sYamlFiles      = map[string]cachedK8sYamlFile{}
+	cachedK8sYamlBytes      int64
+	cachedK8sYamlFilesMutex sync.Mutex
+)
+
+// readK8sResourcesFromFiles reads the resources in the yaml files in parallel.
+// The resources of each file are at the same index as the path of the file.
+func readK8sResourcesFromFiles(paths []string) [][]K8sResourceT {
+	return common.ProcessFilesInParallel(paths, readK8sResourcesFromFile)
+}
+
+// readK8sResourcesFromFile reads the resources in a (possibly multi document) yaml file.
+// The documents are streamed one at a time and the documents that cannot be decoded are skipped.
+// The resources are decoded once for each version of the file, since many transformers look at the same files.
+func readK8sResourcesFromFile(path string) []K8sResourceT {
+	info, err := os.Stat(path)
+	if err != nil {
+		logrus.Debugf("failed to stat the yaml file at path %s . Error: %q", path, err)
+		return nil
+	}
+	if common.SkipFileForDetection(path) {
+		return nil
+	}
+	cachedK8sYamlFilesMutex.Lock()
+	cached, ok := cachedK8sYamlFiles[path]
+	cachedK8sYamlFilesMutex.Unlock()
+	if !ok || cached.size != info.Size() || !cached.modTime.Equal(info.ModTime()) {
+		resources, err := decodeK8sYamlFile(path)
+		if err != nil {
+			logrus.Debugf("failed to read the yaml file at path %s . Error: %q", path, err)
+		}
+		cached = cachedK8sYamlFile{size: info.Size(), modTime: info.ModTime(), resources: resources}
+		if err == nil {
+			cacheK8sYamlFile(path, cached)
+		}
+	}
+	resources := make([]K8sResourceT, 0, len(cached.resources))
+	for _, resource := range cached.resources {
+		resources = append(resources, deepcopy.DeepCopy(resource).(K8sResourceT))
+	}
+	return resources
+}
+
+func cacheK8sYamlFile(path string, file cachedK8sYamlFile) {
+	cachedK8sYamlFilesMutex.Lock()
+	defer cachedK8sYamlFilesMutex.Unlock()
+	if old, ok := cachedK8sYamlFiles[path]; ok {
+		cachedK8sYamlBytes -= old.size
+		delete(cachedK8sYamlFiles, path)
+	}
+	if cachedK8sYamlBytes+file.size > maxCachedK8sYamlBytes {
+		return
+	}
+	cachedK8sYamlFiles[path] = file
+	cachedK8sYamlBytes += file.size
+}
+
+// decodeK8sYamlFile decodes the documents in the yaml file that are mappings
+func decodeK8sYamlFile(path string) ([]K8sResourceT, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	resources := []K8sResourceT{}
+	err = common.StreamYAMLDocuments(f, common.DefaultMaxYAMLDocumentSize, func(doc common.YAMLDocument) error {
+		resource, err := decodeK8sResource(doc.Data)
+		if err != nil {
+			logrus.Debugf("Skipping the yaml document at line %d of the file %s . Error: %q", doc.Line, path, err)
+			return nil
+		}
+		if resource != nil {
+			resources = append(resources, resource)
+		}
+		return nil
+	})
+	return resources, err
+}
+
+// decodeK8sResource decodes a yaml document. It returns nil if the document is not a mapping.
+func decodeK8sResource(data []byte) (K8sResourceT, error) {
+	// NOTE: This roundabout method is required to avoid yaml.v3 unmarshalling timestamps into time.Time
+	var resourceI interface{}
+	if err := yaml.Unmarshal(data, &resourceI); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the yaml. Error: %q", err)
+	}
+	if _, ok := resourceI.(map[string]interface{}); !ok {
+		return nil, nil
+	}
+	resourceJSONBytes, err := json.Marshal(resourceI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the k8s resource into json. Error: %q", err)
+	}
+	k8sResource := K8sResourceT{}
+	if err := json.Unmarshal(resourceJSONBytes, &k8sResource); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the json into a k8s resource. Error: %q", err)
+	}
+	return k8sResource, nil
+}
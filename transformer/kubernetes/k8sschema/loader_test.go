@@ -0,0 +1,88 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+)
+
+const testMultiDocumentYaml = `# the leading separator and comments do not start a document
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: first
+data:
+  key: |
+    a value with --- inside
+---
+# only a comment
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: [broken
+--- 
+apiVersion: v1
+kind: Service
+metadata:
+  name: second
+...
+`
+
+func TestGetK8sResourcesWithPaths(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "resources.yaml")
+	if err := os.WriteFile(yamlPath, []byte(testMultiDocumentYaml), 0644); err != nil {
+		t.Fatalf("failed to write the yaml file. Error: %q", err)
+	}
+	getKinds := func() []string {
+		pathedKs, err := k8sschema.GetK8sResourcesWithPaths(dir)
+		if err != nil {
+			t.Fatalf("failed to get the k8s resources. Error: %q", err)
+		}
+		kinds := []string{}
+		for _, k := range pathedKs["resources.yaml"] {
+			kind, _, _, err := k8sschema.GetInfoFromK8sResource(k)
+			if err != nil {
+				t.Fatalf("failed to get the kind of the resource %+v . Error: %q", k, err)
+			}
+			kinds = append(kinds, kind)
+			k["kind"] = "Secret"
+		}
+		return kinds
+	}
+	if kinds := getKinds(); len(kinds) != 2 || kinds[0] != "ConfigMap" || kinds[1] != "Service" {
+		t.Fatalf("the broken document should be skipped and the others kept. Actual: %+v", kinds)
+	}
+	if kinds := getKinds(); len(kinds) != 2 || kinds[0] != "ConfigMap" {
+		t.Fatalf("changing the returned resources should not change the resources read later. Actual: %+v", kinds)
+	}
+	if objs := k8sschema.GetKubernetesObjsInDir(dir); len(objs) != 2 {
+		t.Fatalf("expected 2 kubernetes objects. Actual: %d", len(objs))
+	}
+	if err := os.WriteFile(yamlPath, []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: changed-file\n"), 0644); err != nil {
+		t.Fatalf("failed to write the yaml file. Error: %q", err)
+	}
+	if kinds := getKinds(); len(kinds) != 1 || kinds[0] != "Namespace" {
+		t.Fatalf("the resources should be read again after the file changes. Actual: %+v", kinds)
+	}
+}
@@ -19,13 +19,10 @@ package k8sschema
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"path/filepath"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/types"
-	"gopkg.in/yaml.v3"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
@@ -121,20 +118,13 @@ func GetK8sResourcesWithPaths(k8sResourcesPath string) (map[string][]K8sResource
 		return nil, err
 	}
 	k8sResources := map[string][]K8sResourceT{}
-	for _, yamlPath := range yamlPaths {
-		k8sYamlBytes, err := os.ReadFile(yamlPath)
-		if err != nil {
-			logrus.Errorf("Failed to read the yaml file at path %s . Error: %q", yamlPath, err)
-			continue
-		}
-		currK8sResources, err := getK8sResourcesFromYaml(string(k8sYamlBytes))
-		if err != nil {
-			logrus.Debugf("Failed to get k8s resources from the yaml file at path %s . Error: %q", yamlPath, err)
+	for i, currK8sResources := range readK8sResourcesFromFiles(yamlPaths) {
+		if len(currK8sResources) == 0 {
 			continue
 		}
-		relYamlPath, err := filepath.Rel(k8sResourcesPath, yamlPath)
+		relYamlPath, err := filepath.Rel(k8sResourcesPath, yamlPaths[i])
 		if err != nil {
-			logrus.Errorf("failed to make the k8s yaml path %s relative to the source folder %s . Error: %q", yamlPath, k8sResourcesPath, err)
+			logrus.Errorf("failed to make the k8s yaml path %s relative to the source folder %s . Error: %q", yamlPaths[i], k8sResourcesPath, err)
 			continue
 		}
 		k8sResources[relYamlPath] = append(k8sResources[relYamlPath], currK8sResources...)
@@ -142,27 +132,7 @@ func GetK8sResourcesWithPaths(k8sResourcesPath string) (map[string][]K8sResource
 	return k8sResources, nil
 }
 
-// getK8sResourcesFromYaml decodes k8s resources from yaml
-func getK8sResourcesFromYaml(k8sYaml string) ([]K8sResourceT, error) {
-	// TODO: split yaml file into multiple resources
-
-	// NOTE: This roundabout method is required to avoid yaml.v3 unmarshalling timestamps into time.Time
-	var resourceI interface{}
-	if err := yaml.Unmarshal([]byte(k8sYaml), &resourceI); err != nil {
-		logrus.Errorf("Failed to unmarshal k8s yaml. Error: %q", err)
-		return nil, err
-	}
-	resourceJSONBytes, err := json.Marshal(resourceI)
-	if err != nil {
-		logrus.Errorf("Failed to marshal the k8s resource into json. K8s resource:\n+%v\nError: %q", resourceI, err)
-		return nil, err
-	}
-	var k8sResource K8sResourceT
-	err = json.Unmarshal(resourceJSONBytes, &k8sResource)
-	return []K8sResourceT{k8sResource}, err
-}
-
-// GetKubernetesObjsInDir returns returns all kubernetes objects in a dir
+// GetKubernetesObjsInDir returns returns all kubernetes objects in the (possibly multi document) yamls in a dir
 func GetKubernetesObjsInDir(dir string) []runtime.Object {
 	objs := []runtime.Object{}
 	codecs := serializer.NewCodecFactory(GetSchema())
@@ -171,22 +141,24 @@ func GetKubernetesObjsInDir(dir string) []runtime.Object {
 		logrus.Errorf("Unable to fetch yaml files at path %q Error: %q", dir, err)
 		return nil
 	}
-	for _, filePath := range filePaths {
-		data, err := ioutil.ReadFile(filePath)
-		if err != nil {
-			logrus.Debugf("Failed to read the yaml file at path %q Error: %q", filePath, err)
-			continue
-		}
-		obj, _, err := codecs.UniversalDeserializer().Decode(data, nil, nil)
-		if err != nil {
-			logrus.Debugf("Failed to decode the file at path %q as a k8s file. Error: %q", filePath, err)
-			continue
-		}
-		objGroupName := obj.GetObjectKind().GroupVersionKind().Group
-		if objGroupName == types.GroupName {
-			continue
+	for i, resources := range readK8sResourcesFromFiles(filePaths) {
+		for _, resource := range resources {
+			data, err := json.Marshal(resource)
+			if err != nil {
+				logrus.Debugf("Failed to marshal a resource in the file at path %q Error: %q", filePaths[i], err)
+				continue
+			}
+			obj, _, err := codecs.UniversalDeserializer().Decode(data, nil, nil)
+			if err != nil {
+				logrus.Debugf("Failed to decode a resource in the file at path %q as a k8s resource. Error: %q", filePaths[i], err)
+				continue
+			}
+			objGroupName := obj.GetObjectKind().GroupVersionKind().Group
+			if objGroupName == types.GroupName {
+				continue
+			}
+			objs = append(objs, obj)
 		}
-		objs = append(objs, obj)
 	}
 	return objs
 }
@@ -0,0 +1,140 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+const (
+	scaledObjectKind       = "ScaledObject"
+	scaledObjectAPIVersion = "keda.sh/v1alpha1"
+	// defaultKEDAMaxReplicas is used for the services that do not have a limit on their replicas
+	defaultKEDAMaxReplicas = 10
+)
+
+// kedaTriggerField is a metadata field of a KEDA trigger that is asked in the QA
+type kedaTriggerField struct {
+	name        string
+	description string
+	// getDefault returns the default of the field for the service and the address of the broker, which may be empty
+	getDefault func(serviceName, host string) string
+}
+
+var (
+	// kedaTriggerFields are the metadata fields asked for each type of event source.
+	// Fields that are not asked, like the thresholds, get the values in kedaTriggerDefaults.
+	kedaTriggerFields = map[string][]kedaTriggerField{
+		irtypes.KafkaEventSourceType: {
+			{name: "bootstrapServers", description: "the Kafka brokers", getDefault: func(_, host string) string { return defaultIfEmpty(host, "kafka:9092") }},
+			{name: "topic", description: "the topic", getDefault: func(serviceName, _ string) string { return serviceName }},
+			{name: "consumerGroup", description: "the consumer group", getDefault: func(serviceName, _ string) string { return serviceName }},
+		},
+		irtypes.RabbitMQEventSourceType: {
+			{name: "host", description: "the AMQP URL of the RabbitMQ server", getDefault: func(_, host string) string {
+				return "amqp://guest:guest@" + defaultIfEmpty(host, "rabbitmq:5672") + "/"
+			}},
+			{name: "queueName", description: "the queue", getDefault: func(serviceName, _ string) string { return serviceName }},
+		},
+		irtypes.SQSEventSourceType: {
+			{name: "queueURL", description: "the URL of the SQS queue", getDefault: func(serviceName, _ string) string {
+				return "https://sqs.us-east-1.amazonaws.com/000000000000/" + serviceName
+			}},
+			{name: "awsRegion", description: "the AWS region of the queue", getDefault: func(_, _ string) string { return "us-east-1" }},
+		},
+	}
+	kedaTriggerDefaults = map[string]map[string]string{
+		irtypes.KafkaEventSourceType:    {"lagThreshold": "10"},
+		irtypes.RabbitMQEventSourceType: {"mode": "QueueLength", "value": "20"},
+		irtypes.SQSEventSourceType:      {"queueLength": "5"},
+	}
+)
+
+// wireKEDA asks whether the services that consume events should be scaled by KEDA on their queues and topics.
+// It returns the ScaledObjects that should be written along with the other resources.
+func wireKEDA(ir irtypes.IR, clusterConfig collecttypes.ClusterMetadata) []customResourceT {
+	scaledObjects := []customResourceT{}
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if len(service.EventSources) > 0 && !service.Daemon {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+	defaultEnable := clusterConfig.Spec.GetSupportedVersions(scaledObjectKind) != nil
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		eventSourceTypes := []string{}
+		for _, eventSource := range service.EventSources {
+			eventSourceTypes = append(eventSourceTypes, eventSource.Type)
+		}
+		qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigKEDAKeySegment)
+		if !qaengine.FetchBoolAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, "enable"),
+			fmt.Sprintf("The service %s consumes events from %s. Should it be scaled by KEDA on its events instead of running a fixed number of replicas?", serviceName, strings.Join(eventSourceTypes, ", ")),
+			[]string{"KEDA has to be installed in the target cluster. The service is scaled to zero when there are no events."},
+			defaultEnable,
+		) {
+			continue
+		}
+		triggers := []interface{}{}
+		for _, eventSource := range service.EventSources {
+			metadata := map[string]string{}
+			for key, value := range kedaTriggerDefaults[eventSource.Type] {
+				metadata[key] = value
+			}
+			for _, field := range kedaTriggerFields[eventSource.Type] {
+				metadata[field.name] = qaengine.FetchStringAnswer(
+					common.JoinQASubKeys(qaKeyPrefix, eventSource.Type, strings.ToLower(field.name)),
+					fmt.Sprintf("Enter %s for the %s trigger of the service %s :", field.description, eventSource.Type, serviceName),
+					[]string{"Detected from " + eventSource.Source},
+					field.getDefault(serviceName, eventSource.Host),
+				)
+			}
+			triggers = append(triggers, map[string]interface{}{"type": eventSource.Type, "metadata": metadata})
+		}
+		maxReplicas := defaultKEDAMaxReplicas
+		if service.MaxReplicas > 0 {
+			maxReplicas = service.MaxReplicas
+		}
+		scaledObjects = append(scaledObjects, customResourceT{
+			APIVersion: scaledObjectAPIVersion,
+			Kind:       scaledObjectKind,
+			Metadata:   map[string]interface{}{"name": serviceName},
+			Spec: map[string]interface{}{
+				"scaleTargetRef":  map[string]interface{}{"name": serviceName},
+				"maxReplicaCount": maxReplicas,
+				"triggers":        triggers,
+			},
+		})
+	}
+	return scaledObjects
+}
+
+func defaultIfEmpty(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -42,6 +42,7 @@ type Kubernetes struct {
 	Config           transformertypes.Transformer
 	Env              *environment.Environment
 	KubernetesConfig *KubernetesYamlConfig
+	priceSheets      []PriceSheetT
 }
 
 // KubernetesYamlConfig stores the k8s related information
@@ -72,6 +73,7 @@ func (t *Kubernetes) Init(tc transformertypes.Transformer, e *environment.Enviro
 	if t.KubernetesConfig.OutputPath == "" {
 		t.KubernetesConfig.OutputPath = defaultK8sYamlsOutputPath
 	}
+	t.priceSheets = collectPriceSheets(t.Env.Context)
 	return nil
 }
 
@@ -121,12 +123,22 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 			logrus.Errorf("Evaluating IngressName in Kubernetes transformer resulting in empty string. Defaulting to Artifact Name.")
 			ir.Name = newArtifact.Name
 		}
+		serverlessCandidates := getServerlessCandidates(ir)
 		preprocessedIR, err := irpreprocessor.Preprocess(ir)
 		if err != nil {
 			logrus.Errorf("Unable to pre-preocess IR : %s", err)
 		} else {
 			ir = preprocessedIR
 		}
+		ir, knativeServices, openFaaSStack := wireServerless(ir, serverlessCandidates, t.Env.GetEnvironmentSource())
+		ir = wireGPUs(ir, clusterConfig)
+		ir, customResources := wireObservability(ir, clusterConfig)
+		customResources = append(customResources, knativeServices...)
+		ir, backupResources := wireBackup(ir, t.Env.ProjectName)
+		customResources = append(customResources, backupResources...)
+		customResources = append(customResources, wireKEDA(ir, clusterConfig)...)
+		ir, rolloutResources := wireRolloutStrategies(ir, clusterConfig)
+		customResources = append(customResources, rolloutResources...)
 		tempDest := filepath.Join(t.Env.TempPath, "k8s-yamls-"+common.GetRandomString())
 		logrus.Debugf("Starting Kubernetes transform")
 		logrus.Debugf("Total services to be transformed : %d", len(ir.Services))
@@ -136,6 +148,21 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 			logrus.Errorf("Unable to transform and persist IR : %s", err)
 			return nil, nil, err
 		}
+		customResourceFiles, err := persistCustomResources(tempDest, customResources)
+		if err != nil {
+			logrus.Errorf("Unable to write the custom resources : %s", err)
+		}
+		files = append(files, customResourceFiles...)
+		tenancyFiles, err := applyTenancy(ir, t.Env.ProjectName, tempDest)
+		if err != nil {
+			logrus.Errorf("Unable to organize the services into namespaces : %s", err)
+		}
+		files = append(files, tenancyFiles...)
+		if layoutFiles, err := apiresource.ApplyOutputLayout(tempDest, apiresource.GetOutputLayout(), t.Env.ProjectName); err != nil {
+			logrus.Errorf("Unable to organize the yamls using the output layout : %s", err)
+		} else {
+			files = layoutFiles
+		}
 		serviceFsPath := ""
 		if serviceFsPaths, ok := newArtifact.Paths[artifacts.ServiceDirPathType]; ok && len(serviceFsPaths) > 0 {
 			serviceFsPath = serviceFsPaths[0]
@@ -152,6 +179,36 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 			SrcPath:  tempDest,
 			DestPath: outputPath,
 		})
+		storageMigrationTempDest := filepath.Join(t.Env.TempPath, "storage-migration-"+common.GetRandomString())
+		if ok, err := persistStorageMigration(ir, storageMigrationTempDest, clusterConfig); err != nil {
+			logrus.Errorf("Unable to create the storage migration artifacts : %s", err)
+		} else if ok {
+			pathMappings = append(pathMappings, transformertypes.PathMapping{
+				Type:     transformertypes.DefaultPathMappingType,
+				SrcPath:  storageMigrationTempDest,
+				DestPath: defaultStorageMigrationOutputPath,
+			})
+		}
+		openFaaSTempDest := filepath.Join(t.Env.TempPath, "openfaas-"+common.GetRandomString())
+		if ok, err := persistOpenFaaSStack(openFaaSStack, openFaaSTempDest); err != nil {
+			logrus.Errorf("Unable to write the OpenFaaS functions : %s", err)
+		} else if ok {
+			pathMappings = append(pathMappings, transformertypes.PathMapping{
+				Type:     transformertypes.DefaultPathMappingType,
+				SrcPath:  openFaaSTempDest,
+				DestPath: defaultOpenFaaSOutputPath,
+			})
+		}
+		costEstimationTempDest := filepath.Join(t.Env.TempPath, "cost-estimation-"+common.GetRandomString())
+		if ok, err := persistCostEstimation(ir, t.priceSheets, costEstimationTempDest); err != nil {
+			logrus.Errorf("Unable to estimate the cost of the services : %s", err)
+		} else if ok {
+			pathMappings = append(pathMappings, transformertypes.PathMapping{
+				Type:     transformertypes.DefaultPathMappingType,
+				SrcPath:  costEstimationTempDest,
+				DestPath: defaultCostEstimationOutputPath,
+			})
+		}
 		createdArtifact := transformertypes.Artifact{
 			Name: t.Config.Name,
 			Type: artifacts.KubernetesYamlsArtifactType,
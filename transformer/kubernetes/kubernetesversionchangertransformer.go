@@ -20,11 +20,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/transformer/kubernetes/kustomize"
+	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
@@ -76,7 +79,11 @@ func (t *KubernetesVersionChanger) GetConfig() (transformertypes.Transformer, *e
 
 // DirectoryDetect runs detect in each subdirectory
 func (t *KubernetesVersionChanger) DirectoryDetect(dir string) (namedServices map[string][]transformertypes.Artifact, err error) {
-	if len(k8sschema.GetKubernetesObjsInDir(dir)) != 0 {
+	if kustomize.IsKustomizationDir(dir) {
+		// handled by the kustomization loader
+		return nil, nil
+	}
+	if len(k8sschema.GetKubernetesObjsInDir(dir)) != 0 || len(k8sschema.GetCustomResourcesInDir(dir)) != 0 {
 		na := transformertypes.Artifact{
 			Type: artifacts.KubernetesOrgYamlsInSourceArtifactType,
 			Paths: map[transformertypes.PathType][]string{
@@ -106,6 +113,11 @@ func (t *KubernetesVersionChanger) Transform(newArtifacts []transformertypes.Art
 			logrus.Errorf("Unable to load config for Transformer into %T : %s", sConfig, err)
 		}
 		tempDest := filepath.Join(t.Env.TempPath, "k8s-yamls-versionchanged-"+common.GetRandomString())
+		customResources := getCustomResourcesInTree(yamlsPath)
+		crds := k8sschema.NewCRDs(clusterConfig.Spec)
+		crds.AddFromResources(customResources)
+		// the CRDs in the source are used to validate and convert the custom resources in all the sub directories
+		clusterConfig.Spec.CustomResourceDefinitions = crds.List()
 		err := filepath.WalkDir(yamlsPath, func(path string, info os.DirEntry, err error) error {
 			if err != nil && path == yamlsPath {
 				// if walk for root search path return gets error
@@ -122,8 +134,8 @@ func (t *KubernetesVersionChanger) Transform(newArtifacts []transformertypes.Art
 					logrus.Errorf("Unable to convert %s as rel path of %s for yamls conversion : %s", path, yamlsPath, err)
 					return nil
 				}
-				if objs := k8sschema.GetKubernetesObjsInDir(path); len(objs) != 0 {
-					_, err := apiresource.TransformObjsAndPersist(path, filepath.Join(tempDest, relInputPath), apis, clusterConfig)
+				if objs := k8sschema.GetKubernetesObjsInDir(path); len(objs) != 0 || len(k8sschema.GetCustomResourcesInDir(path)) != 0 {
+					_, err := apiresource.TransformObjsInSourceAndPersist(path, filepath.Join(tempDest, relInputPath), apis, clusterConfig)
 					if err != nil {
 						logrus.Errorf("Unable to transform objs at %s : %s", path, err)
 						return nil
@@ -154,7 +166,52 @@ func (t *KubernetesVersionChanger) Transform(newArtifacts []transformertypes.Art
 				artifacts.KubernetesYamlsPathType: {outputPath},
 			},
 		}
+		if parameterizers := getCustomResourceParameterizers(customResources, crds); len(parameterizers) != 0 {
+			na.Configs = map[transformertypes.ConfigType]interface{}{ParameterizersConfigType: parameterizers}
+		}
 		createdArtifacts = append(createdArtifacts, na)
 	}
 	return pathMappings, createdArtifacts, nil
 }
+
+// getCustomResourcesInTree returns the custom resources and custom resource definitions in the directory and its sub directories
+func getCustomResourcesInTree(dir string) []k8sschema.K8sResourceT {
+	customResources := []k8sschema.K8sResourceT{}
+	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+		if err != nil {
+			logrus.Debugf("Skipping path %q due to error: %q", path, err)
+			return nil
+		}
+		if info.IsDir() {
+			customResources = append(customResources, k8sschema.GetCustomResourcesInDir(path)...)
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Warnf("Error in walking through files due to : %q", err)
+	}
+	return customResources
+}
+
+// getCustomResourceParameterizers parameterizes the replicas of the custom resources that have a scale subresource, the same way as native workloads
+func getCustomResourceParameterizers(customResources []k8sschema.K8sResourceT, crds *k8sschema.CRDs) []parameterizer.ParameterizerT {
+	parameterizers := []parameterizer.ParameterizerT{}
+	for _, customResource := range customResources {
+		if k8sschema.IsCRD(customResource) {
+			continue
+		}
+		kind, apiVersion, name, err := k8sschema.GetInfoFromK8sResource(customResource)
+		if err != nil {
+			continue
+		}
+		specReplicasPath := crds.GetSpecReplicasPath(apiVersion, kind)
+		if specReplicasPath == "" {
+			continue
+		}
+		parameterizers = append(parameterizers, parameterizer.ParameterizerT{
+			Target:  specReplicasPath,
+			Filters: []parameterizer.FilterT{{Kind: regexp.QuoteMeta(kind), Name: regexp.QuoteMeta(name)}},
+		})
+	}
+	return parameterizers
+}
@@ -0,0 +1,242 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/kustomize"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+const (
+	defaultKustomizationLoaderOutputPath = "{{ $rel := Rel .YamlsPath }}source/{{ $rel }}{{ if ne $rel \".\" }}/..{{end}}/{{ FilePathBase .YamlsPath }}-rendered/"
+)
+
+// KustomizationLoader implements Transformer interface
+type KustomizationLoader struct {
+	Config   transformertypes.Transformer
+	Env      *environment.Environment
+	KLConfig *KustomizationLoaderYamlConfig
+	// trees are the kustomization trees in the source, keyed by their root directories
+	trees map[string][]kustomize.Tree
+}
+
+// KustomizationLoaderYamlConfig stores the config
+type KustomizationLoaderYamlConfig struct {
+	OutputPath string `yaml:"outputPath"`
+}
+
+// Init Initializes the transformer
+func (t *KustomizationLoader) Init(tc transformertypes.Transformer, e *environment.Environment) error {
+	t.Config = tc
+	t.Env = e
+	t.KLConfig = &KustomizationLoaderYamlConfig{}
+	err := common.GetObjFromInterface(t.Config.Spec.Config, t.KLConfig)
+	if err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.KLConfig, err)
+		return err
+	}
+	if t.KLConfig.OutputPath == "" {
+		t.KLConfig.OutputPath = defaultKustomizationLoaderOutputPath
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *KustomizationLoader) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each subdirectory.
+// The kustomizations in the source are found once, so each directory only has to look up the trees rooted at it.
+func (t *KustomizationLoader) DirectoryDetect(dir string) (namedServices map[string][]transformertypes.Artifact, err error) {
+	if t.trees == nil {
+		trees, err := kustomize.GetTrees(t.Env.GetEnvironmentSource())
+		if err != nil {
+			return nil, err
+		}
+		t.trees = map[string][]kustomize.Tree{}
+		for _, tree := range trees {
+			t.trees[tree.RootDir] = append(t.trees[tree.RootDir], tree)
+		}
+	}
+	nas := []transformertypes.Artifact{}
+	for _, tree := range t.trees[dir] {
+		na := transformertypes.Artifact{
+			Type: artifacts.KustomizationInSourceArtifactType,
+			Paths: map[transformertypes.PathType][]string{
+				artifacts.KustomizationOverlayPathType: tree.OverlayDirs,
+				artifacts.ServiceDirPathType:           tree.KustomizationDirs,
+			},
+		}
+		if tree.BaseDir != "" {
+			na.Paths[artifacts.KustomizationBasePathType] = []string{tree.BaseDir}
+		}
+		nas = append(nas, na)
+	}
+	if len(nas) == 0 {
+		return nil, nil
+	}
+	return map[string][]transformertypes.Artifact{"": nas}, nil
+}
+
+// Transform transforms artifacts
+func (t *KustomizationLoader) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) (pathMappings []transformertypes.PathMapping, createdArtifacts []transformertypes.Artifact, err error) {
+	pathMappings = []transformertypes.PathMapping{}
+	apis := []apiresource.IAPIResource{new(apiresource.Deployment), new(apiresource.Service)}
+	for _, a := range newArtifacts {
+		overlayDirs := a.Paths[artifacts.KustomizationOverlayPathType]
+		if len(overlayDirs) == 0 {
+			logrus.Errorf("the artifact %s of type %s does not have the paths to any kustomizations", a.Name, a.Type)
+			continue
+		}
+		var clusterConfig collecttypes.ClusterMetadata
+		if err := a.GetConfig(ClusterMetadata, &clusterConfig); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", clusterConfig, err)
+			continue
+		}
+		kustomizationDirs := a.Paths[artifacts.ServiceDirPathType]
+		if len(kustomizationDirs) == 0 {
+			kustomizationDirs = overlayDirs
+		}
+		rootDir := kustomize.GetCommonDir(kustomizationDirs)
+		overlayEnvs := getKustomizationEnvs(rootDir, overlayDirs)
+		// only the overlays that build successfully become environments
+		envs := []string{}
+		overlays := map[string]resmap.ResMap{}
+		for i, overlayDir := range overlayDirs {
+			resMap, err := kustomize.Build(overlayDir)
+			if err != nil {
+				logrus.Errorf("failed to build the kustomization overlay at path %s . Error: %q", overlayDir, err)
+				continue
+			}
+			envs = append(envs, overlayEnvs[i])
+			overlays[overlayEnvs[i]] = resMap
+		}
+		if len(envs) == 0 {
+			continue
+		}
+		var base resmap.ResMap
+		if len(a.Paths[artifacts.KustomizationBasePathType]) > 0 {
+			baseDir := a.Paths[artifacts.KustomizationBasePathType][0]
+			if base, err = kustomize.Build(baseDir); err != nil {
+				logrus.Errorf("failed to build the kustomization base. Error: %q", err)
+				continue
+			}
+		} else {
+			// without a common base the first overlay is used as the reference
+			base = overlays[envs[0]]
+		}
+		renderedPath := filepath.Join(t.Env.TempPath, "kustomization-rendered-"+common.GetRandomString())
+		if err := writeKustomizationResources(base, renderedPath); err != nil {
+			logrus.Errorf("failed to write the resources of the kustomization at path %s . Error: %q", rootDir, err)
+			continue
+		}
+		tempDest := filepath.Join(t.Env.TempPath, "kustomization-versionchanged-"+common.GetRandomString())
+		if _, err := apiresource.TransformObjsInSourceAndPersist(renderedPath, tempDest, apis, clusterConfig); err != nil {
+			logrus.Errorf("Unable to transform objs at %s : %s", renderedPath, err)
+			continue
+		}
+		outputPathKey := outputPathTemplateName + common.GetRandomString()
+		outputPath := fmt.Sprintf("{{ .%s }}", outputPathKey)
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.PathTemplatePathMappingType,
+			SrcPath:        t.KLConfig.OutputPath,
+			TemplateConfig: OutputPathParams{PathTemplateName: outputPathKey, YamlsPath: rootDir},
+		})
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  tempDest,
+			DestPath: outputPath,
+		})
+		na := transformertypes.Artifact{
+			Name: filepath.Base(rootDir),
+			Type: artifacts.KubernetesYamlsInSourceArtifactType,
+			Paths: map[transformertypes.PathType][]string{
+				artifacts.KubernetesYamlsPathType: {outputPath},
+			},
+		}
+		if len(envs) > 1 || len(a.Paths[artifacts.KustomizationBasePathType]) > 0 {
+			parameterizers := kustomize.GetParameterizers(base, overlays)
+			logrus.Debugf("recovered %d parameterizers from the overlays of the kustomization at path %s", len(parameterizers), rootDir)
+			na.Configs = map[transformertypes.ConfigType]interface{}{
+				ParameterizersConfigType:            parameterizers,
+				ParameterizerEnvironmentsConfigType: envs,
+			}
+		}
+		createdArtifacts = append(createdArtifacts, na)
+	}
+	return pathMappings, createdArtifacts, nil
+}
+
+// getKustomizationEnvs names the environments after the overlay directories.
+// The path relative to the root directory is used when the directory names are not unique.
+func getKustomizationEnvs(rootDir string, overlayDirs []string) []string {
+	envs := []string{}
+	for _, overlayDir := range overlayDirs {
+		envs = append(envs, common.NormalizeForMetadataName(filepath.Base(overlayDir)))
+	}
+	seen := map[string]bool{}
+	unique := true
+	for _, env := range envs {
+		if env == "" || seen[env] {
+			unique = false
+			break
+		}
+		seen[env] = true
+	}
+	if unique {
+		return envs
+	}
+	for i, overlayDir := range overlayDirs {
+		rel, err := filepath.Rel(rootDir, overlayDir)
+		if err != nil {
+			rel = overlayDir
+		}
+		envs[i] = common.NormalizeForMetadataName(strings.ReplaceAll(rel, string(os.PathSeparator), "-"))
+	}
+	return envs
+}
+
+// writeKustomizationResources writes the built resources to files named after their kinds and names
+func writeKustomizationResources(resMap resmap.ResMap, outputPath string) error {
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	for _, r := range resMap.Resources() {
+		data, err := r.AsYAML()
+		if err != nil {
+			return err
+		}
+		fileName := common.MakeFileNameCompliant(strings.ToLower(r.GetKind())+"-"+r.GetName()) + ".yaml"
+		if err := os.WriteFile(filepath.Join(outputPath, fileName), data, common.DefaultFilePermission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
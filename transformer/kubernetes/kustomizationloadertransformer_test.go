@@ -0,0 +1,90 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+func TestKustomizationLoaderSkipsBrokenOverlays(t *testing.T) {
+	common.TempPath = t.TempDir()
+	dir := t.TempDir()
+	files := map[string]string{
+		"app/base/kustomization.yaml": "resources:\n  - deployment.yaml\n",
+		"app/base/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.19
+`,
+		"app/overlays/dev/kustomization.yaml":    "resources:\n  - ../../base\nimages:\n  - name: nginx\n    newTag: \"1.20\"\n",
+		"app/overlays/prod/kustomization.yaml":   "resources:\n  - ../../base\nreplicas:\n  - name: web\n    count: 3\n",
+		"app/overlays/broken/kustomization.yaml": "resources:\n  - ../../base\n  - missing.yaml\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	appDir := filepath.Join(dir, "app")
+	overlaysDir := filepath.Join(appDir, "overlays")
+	loader := &KustomizationLoader{
+		Env:      &environment.Environment{EnvInfo: environment.EnvInfo{TempPath: t.TempDir()}},
+		KLConfig: &KustomizationLoaderYamlConfig{OutputPath: defaultKustomizationLoaderOutputPath},
+	}
+	artifact := transformertypes.Artifact{
+		Type: artifacts.KustomizationInSourceArtifactType,
+		Paths: map[transformertypes.PathType][]string{
+			artifacts.KustomizationBasePathType:    {filepath.Join(appDir, "base")},
+			artifacts.KustomizationOverlayPathType: {filepath.Join(overlaysDir, "broken"), filepath.Join(overlaysDir, "dev"), filepath.Join(overlaysDir, "prod")},
+		},
+		Configs: map[transformertypes.ConfigType]interface{}{ClusterMetadata: collecttypes.ClusterMetadata{}},
+	}
+	_, createdArtifacts, err := loader.Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the kustomization. Error: %q", err)
+	}
+	if len(createdArtifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", createdArtifacts)
+	}
+	envs := []string{}
+	if err := createdArtifacts[0].GetConfig(ParameterizerEnvironmentsConfigType, &envs); err != nil {
+		t.Fatalf("failed to get the environments of the artifact. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{"dev", "prod"}, envs); diff != "" {
+		t.Fatalf("the environments should only contain the overlays that build. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kustomize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// kustomization contains the fields of a kustomization file that reference other kustomizations
+type kustomization struct {
+	Resources  []string `yaml:"resources,omitempty"`
+	Bases      []string `yaml:"bases,omitempty"`
+	Components []string `yaml:"components,omitempty"`
+}
+
+// Tree is a set of kustomizations in the source that reference each other
+type Tree struct {
+	// RootDir is the closest directory containing all the kustomizations in the tree
+	RootDir string
+	// BaseDir is the kustomization that all the overlays build on. It is empty if there is no such kustomization.
+	BaseDir string
+	// OverlayDirs are the kustomizations that are not referenced by any other kustomization in the tree
+	OverlayDirs []string
+	// KustomizationDirs are all the kustomizations in the tree
+	KustomizationDirs []string
+}
+
+// GetKustomizationFile returns the path to the kustomization file in the directory or an empty string if there is none
+func GetKustomizationFile(dir string) string {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// IsKustomizationDir returns true if the directory contains a kustomization file
+func IsKustomizationDir(dir string) bool {
+	return GetKustomizationFile(dir) != ""
+}
+
+// GetTrees returns the kustomization trees that are fully contained in the directory.
+// Trees that reference kustomizations outside the directory are skipped since they will be found in a parent directory.
+func GetTrees(dir string) ([]Tree, error) {
+	kustomizationDirs := []string{}
+	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+		if err != nil {
+			logrus.Debugf("skipping the path %s due to error. Error: %q", path, err)
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir {
+			for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
+				if dirRegExp.Match([]byte(filepath.Base(path))) {
+					return filepath.SkipDir
+				}
+			}
+		}
+		if IsKustomizationDir(path) {
+			kustomizationDirs = append(kustomizationDirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk the directory %s . Error: %q", dir, err)
+	}
+	if len(kustomizationDirs) == 0 {
+		return nil, nil
+	}
+	refs := map[string][]string{}
+	external := map[string]bool{}
+	for _, kustomizationDir := range kustomizationDirs {
+		referencedDirs, err := getReferencedDirs(kustomizationDir)
+		if err != nil {
+			logrus.Debugf("failed to get the kustomizations referenced by %s . Error: %q", kustomizationDir, err)
+			continue
+		}
+		for _, referencedDir := range referencedDirs {
+			if !common.IsPresent(kustomizationDirs, referencedDir) {
+				external[kustomizationDir] = true
+				continue
+			}
+			refs[kustomizationDir] = append(refs[kustomizationDir], referencedDir)
+		}
+	}
+	components := getConnectedComponents(kustomizationDirs, refs)
+	trees := []Tree{}
+	for _, component := range components {
+		hasExternalRefs := false
+		for _, kustomizationDir := range component {
+			if external[kustomizationDir] {
+				hasExternalRefs = true
+				break
+			}
+		}
+		if hasExternalRefs {
+			logrus.Debugf("skipping the kustomizations %+v since they reference kustomizations outside the directory %s", component, dir)
+			continue
+		}
+		trees = append(trees, getTree(component, refs))
+	}
+	return trees, nil
+}
+
+// Build builds the kustomization in the directory
+func Build(dir string) (resmap.ResMap, error) {
+	options := krusty.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(options)
+	resMap, err := k.Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the kustomization in the directory %s . Error: %q", dir, err)
+	}
+	return resMap, nil
+}
+
+// getReferencedDirs returns the local directories containing kustomizations that are referenced by the kustomization in the directory
+func getReferencedDirs(dir string) ([]string, error) {
+	k := kustomization{}
+	if err := common.ReadYaml(GetKustomizationFile(dir), &k); err != nil {
+		return nil, err
+	}
+	referencedDirs := []string{}
+	for _, ref := range append(append(append([]string{}, k.Resources...), k.Bases...), k.Components...) {
+		if strings.Contains(ref, "://") || filepath.IsAbs(ref) {
+			continue
+		}
+		refDir := filepath.Join(dir, ref)
+		if info, err := os.Stat(refDir); err != nil || !info.IsDir() || !IsKustomizationDir(refDir) {
+			continue
+		}
+		referencedDirs = append(referencedDirs, refDir)
+	}
+	return referencedDirs, nil
+}
+
+// getConnectedComponents groups the kustomizations that reference each other directly or indirectly
+func getConnectedComponents(kustomizationDirs []string, refs map[string][]string) [][]string {
+	parents := map[string]string{}
+	var find func(string) string
+	find = func(dir string) string {
+		if parents[dir] == dir {
+			return dir
+		}
+		parents[dir] = find(parents[dir])
+		return parents[dir]
+	}
+	for _, kustomizationDir := range kustomizationDirs {
+		parents[kustomizationDir] = kustomizationDir
+	}
+	for kustomizationDir, referencedDirs := range refs {
+		for _, referencedDir := range referencedDirs {
+			parents[find(kustomizationDir)] = find(referencedDir)
+		}
+	}
+	grouped := map[string][]string{}
+	roots := []string{}
+	for _, kustomizationDir := range kustomizationDirs {
+		root := find(kustomizationDir)
+		if _, ok := grouped[root]; !ok {
+			roots = append(roots, root)
+		}
+		grouped[root] = append(grouped[root], kustomizationDir)
+	}
+	components := [][]string{}
+	for _, root := range roots {
+		components = append(components, grouped[root])
+	}
+	return components
+}
+
+// getTree finds the overlays and the common base of a set of connected kustomizations
+func getTree(component []string, refs map[string][]string) Tree {
+	referenced := map[string]bool{}
+	for _, kustomizationDir := range component {
+		for _, referencedDir := range refs[kustomizationDir] {
+			referenced[referencedDir] = true
+		}
+	}
+	tree := Tree{RootDir: GetCommonDir(component), KustomizationDirs: component}
+	for _, kustomizationDir := range component {
+		if !referenced[kustomizationDir] {
+			tree.OverlayDirs = append(tree.OverlayDirs, kustomizationDir)
+		}
+	}
+	sort.Strings(tree.OverlayDirs)
+	if len(tree.OverlayDirs) == 0 {
+		// the kustomizations reference each other in a cycle
+		tree.OverlayDirs = []string{component[0]}
+		return tree
+	}
+	if len(tree.OverlayDirs) == 1 && len(component) == 1 {
+		return tree
+	}
+	// the base is the kustomization reachable from every overlay that is closest to the overlays
+	var reachable map[string]int
+	for _, overlayDir := range tree.OverlayDirs {
+		distances := getDistances(overlayDir, refs)
+		if reachable == nil {
+			reachable = distances
+			continue
+		}
+		for dir, distance := range reachable {
+			overlayDistance, ok := distances[dir]
+			if !ok {
+				delete(reachable, dir)
+				continue
+			}
+			if overlayDistance > distance {
+				reachable[dir] = overlayDistance
+			}
+		}
+	}
+	closest := -1
+	for dir, distance := range reachable {
+		if common.IsPresent(tree.OverlayDirs, dir) {
+			continue
+		}
+		if closest == -1 || distance < closest || (distance == closest && dir < tree.BaseDir) {
+			closest = distance
+			tree.BaseDir = dir
+		}
+	}
+	return tree
+}
+
+// getDistances returns the number of references that need to be followed to reach each kustomization from the given one
+func getDistances(dir string, refs map[string][]string) map[string]int {
+	distances := map[string]int{dir: 0}
+	queue := []string{dir}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, referencedDir := range refs[current] {
+			if _, ok := distances[referencedDir]; ok {
+				continue
+			}
+			distances[referencedDir] = distances[current] + 1
+			queue = append(queue, referencedDir)
+		}
+	}
+	return distances
+}
+
+// GetCommonDir returns the closest directory containing all the given directories
+func GetCommonDir(dirs []string) string {
+	commonDir := dirs[0]
+	for _, dir := range dirs[1:] {
+		for {
+			rel, err := filepath.Rel(commonDir, dir)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+				break
+			}
+			parent := filepath.Dir(commonDir)
+			if parent == commonDir {
+				break
+			}
+			commonDir = parent
+		}
+	}
+	return commonDir
+}
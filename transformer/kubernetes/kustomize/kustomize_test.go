@@ -0,0 +1,112 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kustomize_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/transformer/kubernetes/kustomize"
+	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for path, contents := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+}
+
+func TestGetTreesAndParameterizers(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/base/kustomization.yaml": "resources:\n  - deployment.yaml\n",
+		"app/base/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.19
+`,
+		"app/overlays/dev/kustomization.yaml":  "resources:\n  - ../../base\nnamePrefix: dev-\nimages:\n  - name: nginx\n    newTag: \"1.20\"\n",
+		"app/overlays/prod/kustomization.yaml": "resources:\n  - ../../base\nreplicas:\n  - name: web\n    count: 3\n",
+	})
+	appDir := filepath.Join(dir, "app")
+	trees, err := kustomize.GetTrees(filepath.Join(appDir, "overlays"))
+	if err != nil {
+		t.Fatalf("failed to get the kustomization trees. Error: %q", err)
+	}
+	if len(trees) != 0 {
+		t.Fatalf("expected overlays that reference a base outside the directory to be skipped. Actual: %+v", trees)
+	}
+	trees, err = kustomize.GetTrees(dir)
+	if err != nil {
+		t.Fatalf("failed to get the kustomization trees. Error: %q", err)
+	}
+	expectedTree := kustomize.Tree{
+		RootDir:           appDir,
+		BaseDir:           filepath.Join(appDir, "base"),
+		OverlayDirs:       []string{filepath.Join(appDir, "overlays", "dev"), filepath.Join(appDir, "overlays", "prod")},
+		KustomizationDirs: []string{filepath.Join(appDir, "base"), filepath.Join(appDir, "overlays", "dev"), filepath.Join(appDir, "overlays", "prod")},
+	}
+	if len(trees) != 1 || !cmp.Equal(trees[0], expectedTree) {
+		t.Fatalf("the kustomization trees are incorrect. Differences:\n%s", cmp.Diff([]kustomize.Tree{expectedTree}, trees))
+	}
+	base, err := kustomize.Build(expectedTree.BaseDir)
+	if err != nil {
+		t.Fatalf("failed to build the base. Error: %q", err)
+	}
+	dev, err := kustomize.Build(expectedTree.OverlayDirs[0])
+	if err != nil {
+		t.Fatalf("failed to build the dev overlay. Error: %q", err)
+	}
+	prod, err := kustomize.Build(expectedTree.OverlayDirs[1])
+	if err != nil {
+		t.Fatalf("failed to build the prod overlay. Error: %q", err)
+	}
+	parameterizers := kustomize.GetParameterizers(base, map[string]resmap.ResMap{"dev": dev, "prod": prod})
+	expected := []parameterizer.ParameterizerT{
+		{
+			Target:     "spec.template.spec.containers.[0].image",
+			Template:   "${deployment.web.spec.template.spec.containers.web.image}",
+			Filters:    []parameterizer.FilterT{{Kind: "Deployment", Name: "web"}},
+			Parameters: []parameterizer.ParameterT{{Name: "deployment.web.spec.template.spec.containers.web.image", Values: []parameterizer.ParameterValueT{{Envs: []string{"dev"}, Value: "nginx:1.20"}}}},
+		},
+		{
+			Target:     "spec.replicas",
+			Template:   "${deployment.web.spec.replicas}",
+			Filters:    []parameterizer.FilterT{{Kind: "Deployment", Name: "web"}},
+			Parameters: []parameterizer.ParameterT{{Name: "deployment.web.spec.replicas", Values: []parameterizer.ParameterValueT{{Envs: []string{"prod"}, Value: "3"}}}},
+		},
+	}
+	if !cmp.Equal(parameterizers, expected) {
+		t.Fatalf("the parameterizers are incorrect. Differences:\n%s", cmp.Diff(expected, parameterizers))
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kustomize
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// changedField is a scalar field whose value in an overlay differs from its value in the base
+type changedField struct {
+	path []string
+	// keys are the sub keys of the parameter, list elements are identified by name when possible
+	keys  []string
+	value interface{}
+}
+
+// GetParameterizers returns parameterizers that reproduce the changes each overlay makes to the values in the base resources.
+// Each field changed by an overlay becomes a parameter with a value for each environment (named after the overlay).
+// Fields added or removed by an overlay and names (which get rewritten by name prefixes, suffixes and generators) are not parameterized.
+func GetParameterizers(base resmap.ResMap, overlays map[string]resmap.ResMap) []parameterizer.ParameterizerT {
+	envs := []string{}
+	for env := range overlays {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	parameterizers := []parameterizer.ParameterizerT{}
+	for _, baseResource := range base.Resources() {
+		baseObj, err := baseResource.Map()
+		if err != nil {
+			logrus.Debugf("failed to convert the resource %s to a map. Error: %q", baseResource.CurId(), err)
+			continue
+		}
+		kind := baseResource.GetKind()
+		name := baseResource.GetName()
+		targets := []string{}
+		params := map[string]*parameterizer.ParameterizerT{}
+		for _, env := range envs {
+			overlayResource := findOverlayResource(overlays[env], baseResource)
+			if overlayResource == nil {
+				continue
+			}
+			overlayObj, err := overlayResource.Map()
+			if err != nil {
+				logrus.Debugf("failed to convert the resource %s to a map. Error: %q", overlayResource.CurId(), err)
+				continue
+			}
+			for _, changed := range getChangedFields(baseObj, overlayObj, nil, nil) {
+				target := strings.Join(changed.path, ".")
+				p, ok := params[target]
+				if !ok {
+					paramKeys := []string{strings.ToLower(kind), name}
+					paramKeys = append(paramKeys, changed.keys...)
+					quotedParamKeys := []string{}
+					for _, paramKey := range paramKeys {
+						quotedParamKeys = append(quotedParamKeys, quoteKey(paramKey))
+					}
+					paramName := strings.Join(quotedParamKeys, ".")
+					p = &parameterizer.ParameterizerT{
+						Target:     target,
+						Template:   "${" + paramName + "}",
+						Filters:    []parameterizer.FilterT{{Kind: regexp.QuoteMeta(kind), Name: regexp.QuoteMeta(name)}},
+						Parameters: []parameterizer.ParameterT{{Name: paramName}},
+					}
+					params[target] = p
+					targets = append(targets, target)
+				}
+				p.Parameters[0].Values = append(p.Parameters[0].Values, parameterizer.ParameterValueT{Envs: []string{env}, Value: toString(changed.value)})
+			}
+		}
+		for _, target := range targets {
+			parameterizers = append(parameterizers, *params[target])
+		}
+	}
+	return parameterizers
+}
+
+// findOverlayResource finds the resource in the overlay that was created from the base resource.
+// The overlay may have added a prefix or suffix to the name, so the closest name of the same kind is used when there is no exact match.
+func findOverlayResource(overlay resmap.ResMap, baseResource *resource.Resource) *resource.Resource {
+	var closest *resource.Resource
+	for _, r := range overlay.Resources() {
+		if !r.GetGvk().Equals(baseResource.GetGvk()) || !strings.Contains(r.GetName(), baseResource.GetName()) {
+			continue
+		}
+		if r.GetName() == baseResource.GetName() {
+			return r
+		}
+		if closest == nil || len(r.GetName()) < len(closest.GetName()) {
+			closest = r
+		}
+	}
+	return closest
+}
+
+// getChangedFields returns the scalar fields present in both the objects whose values differ
+func getChangedFields(baseValue, overlayValue interface{}, path, keys []string) []changedField {
+	changed := []changedField{}
+	switch actualBaseValue := baseValue.(type) {
+	case map[string]interface{}:
+		actualOverlayValue, ok := overlayValue.(map[string]interface{})
+		if !ok {
+			return changed
+		}
+		mapKeys := []string{}
+		for key := range actualBaseValue {
+			mapKeys = append(mapKeys, key)
+		}
+		sort.Strings(mapKeys)
+		for _, key := range mapKeys {
+			if key == "name" {
+				continue
+			}
+			subOverlayValue, ok := actualOverlayValue[key]
+			if !ok {
+				continue
+			}
+			changed = append(changed, getChangedFields(actualBaseValue[key], subOverlayValue, append(append([]string{}, path...), quoteKey(key)), append(append([]string{}, keys...), key))...)
+		}
+	case []interface{}:
+		actualOverlayValue, ok := overlayValue.([]interface{})
+		if !ok || len(actualOverlayValue) != len(actualBaseValue) {
+			return changed
+		}
+		for i, elem := range actualBaseValue {
+			key := strconv.Itoa(i)
+			if elemMap, ok := elem.(map[string]interface{}); ok {
+				if name, ok := elemMap["name"].(string); ok && name != "" {
+					key = name
+				}
+			}
+			changed = append(changed, getChangedFields(elem, actualOverlayValue[i], append(append([]string{}, path...), "["+strconv.Itoa(i)+"]"), append(append([]string{}, keys...), key))...)
+		}
+	case nil:
+	default:
+		switch overlayValue.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return changed
+		}
+		if !reflect.DeepEqual(baseValue, overlayValue) {
+			changed = append(changed, changedField{path: path, keys: keys, value: overlayValue})
+		}
+	}
+	return changed
+}
+
+func quoteKey(key string) string {
+	if strings.ContainsAny(key, `.[]"$ `) {
+		return `"` + key + `"`
+	}
+	return key
+}
+
+func toString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,289 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	observabilityPrometheusAnnotations = "Prometheus scrape annotations"
+	observabilityServiceMonitor        = "Prometheus ServiceMonitor"
+	observabilityOpenTelemetry         = "OpenTelemetry collector sidecar"
+	observabilityFluentBit             = "Fluent Bit log parser"
+
+	serviceMonitorKind       = "ServiceMonitor"
+	serviceMonitorAPIVersion = "monitoring.coreos.com/v1"
+
+	defaultMetricsPath          = "/metrics"
+	defaultOTelEndpoint         = "http://opentelemetry-collector:4317"
+	otelCollectorImage          = "docker.io/otel/opentelemetry-collector:latest"
+	otelCollectorConfigFileName = "config.yaml"
+	otelCollectorConfigMountDir = "/etc/otelcol"
+	otelCollectorSuffix         = "-otel-collector"
+	fluentBitParsersName        = "fluent-bit-parsers"
+	fluentBitParsersFileName    = "parsers.conf"
+	fluentBitParserAnnotation   = "fluentbit.io/parser"
+)
+
+var (
+	// fluentBitParsers contains the fluent bit parser definition for each supported log format
+	fluentBitParsers = map[string]string{
+		"json":           "[PARSER]\n    Name        json\n    Format      json\n    Time_Key    time\n    Time_Format %d/%b/%Y:%H:%M:%S %z\n",
+		"logfmt":         "[PARSER]\n    Name        logfmt\n    Format      logfmt\n",
+		"nginx":          "[PARSER]\n    Name        nginx\n    Format      regex\n    Regex       ^(?<remote>[^ ]*) (?<host>[^ ]*) (?<user>[^ ]*) \\[(?<time>[^\\]]*)\\] \"(?<method>\\S+)(?: +(?<path>[^\\\"]*?)(?: +\\S*)?)?\" (?<code>[^ ]*) (?<size>[^ ]*)(?: \"(?<referer>[^\\\"]*)\" \"(?<agent>[^\\\"]*)\")\n    Time_Key    time\n    Time_Format %d/%b/%Y:%H:%M:%S %z\n",
+		"apache2":        "[PARSER]\n    Name        apache2\n    Format      regex\n    Regex       ^(?<host>[^ ]*) [^ ]* (?<user>[^ ]*) \\[(?<time>[^\\]]*)\\] \"(?<method>\\S+)(?: +(?<path>[^ ]*) +\\S*)?\" (?<code>[^ ]*) (?<size>[^ ]*)(?: \"(?<referer>[^\\\"]*)\" \"(?<agent>[^\\\"]*)\")?$\n    Time_Key    time\n    Time_Format %d/%b/%Y:%H:%M:%S %z\n",
+		"syslog-rfc5424": "[PARSER]\n    Name        syslog-rfc5424\n    Format      regex\n    Regex       ^\\<(?<pri>[0-9]{1,5})\\>1 (?<time>[^ ]+) (?<host>[^ ]+) (?<ident>[^ ]+) (?<pid>[-0-9]+) (?<msgid>[^ ]+) (?<extradata>(\\[(.*?)\\]|-)) (?<message>.+)$\n    Time_Key    time\n    Time_Format %Y-%m-%dT%H:%M:%S.%L%z\n",
+	}
+	// imageLogFormats maps images to the log format they write
+	imageLogFormats = map[string]string{
+		"nginx":  "nginx",
+		"httpd":  "apache2",
+		"apache": "apache2",
+	}
+)
+
+// wireObservability wires the selected services for metrics, tracing and log collection.
+// It returns the ServiceMonitors that should be written along with the other resources.
+func wireObservability(ir irtypes.IR, clusterConfig collecttypes.ClusterMetadata) (irtypes.IR, []customResourceT) {
+	serviceMonitors := []customResourceT{}
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	if len(serviceNames) == 0 {
+		return ir, serviceMonitors
+	}
+	sort.Strings(serviceNames)
+	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
+		common.ConfigObservabilityServicesKey,
+		"Select the services that should be wired for observability :",
+		[]string{"Metrics, tracing and log collection can be configured for each of the selected services"},
+		[]string{},
+		serviceNames,
+	)
+	if len(selectedServiceNames) == 0 {
+		return ir, serviceMonitors
+	}
+	features := []string{observabilityPrometheusAnnotations, observabilityServiceMonitor, observabilityOpenTelemetry, observabilityFluentBit}
+	defaultFeatures := []string{observabilityPrometheusAnnotations, observabilityFluentBit}
+	if clusterConfig.Spec.GetSupportedVersions(serviceMonitorKind) != nil {
+		defaultFeatures = []string{observabilityServiceMonitor, observabilityFluentBit}
+	}
+	otelEndpoint := ""
+	logFormats := []string{}
+	for _, serviceName := range selectedServiceNames {
+		service, ok := ir.Services[serviceName]
+		if !ok {
+			continue
+		}
+		qaKeyPrefix := common.JoinQASubKeys(common.ConfigObservabilityKey, `"`+serviceName+`"`)
+		selectedFeatures := qaengine.FetchMultiSelectAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, common.ConfigObservabilityFeaturesKeySuffix),
+			fmt.Sprintf("Select the observability features for the service %s :", serviceName),
+			[]string{"ServiceMonitors require the Prometheus operator in the target cluster"},
+			defaultFeatures,
+			features,
+		)
+		if common.IsPresent(selectedFeatures, observabilityPrometheusAnnotations) || common.IsPresent(selectedFeatures, observabilityServiceMonitor) {
+			serviceMonitor, ok := addMetrics(&service, qaKeyPrefix, common.IsPresent(selectedFeatures, observabilityPrometheusAnnotations))
+			if ok && common.IsPresent(selectedFeatures, observabilityServiceMonitor) {
+				serviceMonitors = append(serviceMonitors, serviceMonitor)
+			}
+		}
+		if common.IsPresent(selectedFeatures, observabilityOpenTelemetry) {
+			if otelEndpoint == "" {
+				otelEndpoint = qaengine.FetchStringAnswer(
+					common.ConfigObservabilityOTelEndpointKey,
+					"Enter the OTLP endpoint the OpenTelemetry collector sidecars should export to :",
+					[]string{"Ex : " + defaultOTelEndpoint},
+					defaultOTelEndpoint,
+				)
+			}
+			ir.AddStorage(addOpenTelemetry(&service, otelEndpoint))
+		}
+		if common.IsPresent(selectedFeatures, observabilityFluentBit) {
+			logFormat := addFluentBitParser(&service, qaKeyPrefix)
+			logFormats = common.AppendIfNotPresent(logFormats, logFormat)
+		}
+		ir.Services[serviceName] = service
+	}
+	if len(logFormats) > 0 {
+		sort.Strings(logFormats)
+		parsers := []string{}
+		for _, logFormat := range logFormats {
+			parsers = append(parsers, fluentBitParsers[logFormat])
+		}
+		ir.AddStorage(irtypes.Storage{
+			Name:        fluentBitParsersName,
+			StorageType: irtypes.ConfigMapKind,
+			Content:     map[string][]byte{fluentBitParsersFileName: []byte(strings.Join(parsers, "\n"))},
+		})
+	}
+	return ir, serviceMonitors
+}
+
+// addMetrics asks for the metrics endpoint of the service and optionally annotates the service for scraping
+func addMetrics(service *irtypes.Service, qaKeyPrefix string, addAnnotations bool) (customResourceT, bool) {
+	if len(service.ServiceToPodPortForwardings) == 0 {
+		logrus.Warnf("The service %s does not expose any ports. Skipping the metrics configuration.", service.Name)
+		return customResourceT{}, false
+	}
+	ports := []string{}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		ports = common.AppendIfNotPresent(ports, cast.ToString(forwarding.ServicePort.Number))
+	}
+	selectedPort := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(qaKeyPrefix, common.ConfigObservabilityMetricsPortKeySuffix),
+		fmt.Sprintf("Select the port on which the service %s exposes metrics :", service.Name),
+		[]string{"Prometheus will scrape the metrics from this port"},
+		ports[0],
+		ports,
+	)
+	metricsPath := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(qaKeyPrefix, common.ConfigObservabilityMetricsPathKeySuffix),
+		fmt.Sprintf("Enter the path on which the service %s exposes metrics :", service.Name),
+		[]string{"Ex : " + defaultMetricsPath},
+		defaultMetricsPath,
+	)
+	forwarding := service.ServiceToPodPortForwardings[0]
+	for _, f := range service.ServiceToPodPortForwardings {
+		if cast.ToString(f.ServicePort.Number) == selectedPort {
+			forwarding = f
+			break
+		}
+	}
+	if addAnnotations {
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		podPort := forwarding.PodPort.Number
+		if podPort == 0 {
+			podPort = forwarding.ServicePort.Number
+		}
+		service.Annotations["prometheus.io/scrape"] = "true"
+		service.Annotations["prometheus.io/port"] = cast.ToString(podPort)
+		service.Annotations["prometheus.io/path"] = metricsPath
+	}
+	portName := forwarding.ServicePort.Name
+	if portName == "" {
+		portName = fmt.Sprintf("port-%d", forwarding.ServicePort.Number)
+	}
+	return customResourceT{
+		APIVersion: serviceMonitorAPIVersion,
+		Kind:       serviceMonitorKind,
+		Metadata:   map[string]interface{}{"name": service.Name},
+		Spec: map[string]interface{}{
+			"selector":  map[string]interface{}{"matchLabels": map[string]string{types.GroupName + "/service": service.Name}},
+			"endpoints": []map[string]interface{}{{"port": portName, "path": metricsPath}},
+		},
+	}, true
+}
+
+// addOpenTelemetry adds an OpenTelemetry collector sidecar to the service and returns the collector config
+func addOpenTelemetry(service *irtypes.Service, otelEndpoint string) irtypes.Storage {
+	collectorName := service.Name + otelCollectorSuffix
+	collectorConfig := strings.Join([]string{
+		"receivers:",
+		"  otlp:",
+		"    protocols:",
+		"      grpc:",
+		"      http:",
+		"exporters:",
+		"  otlp:",
+		"    endpoint: " + strings.TrimPrefix(strings.TrimPrefix(otelEndpoint, "http://"), "https://"),
+		"    tls:",
+		fmt.Sprintf("      insecure: %t", !strings.HasPrefix(otelEndpoint, "https://")),
+		"service:",
+		"  pipelines:",
+		"    traces:",
+		"      receivers: [otlp]",
+		"      exporters: [otlp]",
+		"    metrics:",
+		"      receivers: [otlp]",
+		"      exporters: [otlp]",
+		"",
+	}, "\n")
+	for i := range service.Containers {
+		service.Containers[i].Env = append(service.Containers[i].Env,
+			core.EnvVar{Name: "OTEL_SERVICE_NAME", Value: service.Name},
+			core.EnvVar{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: "http://localhost:4317"},
+		)
+	}
+	service.Containers = append(service.Containers, core.Container{
+		Name:         collectorName,
+		Image:        otelCollectorImage,
+		Args:         []string{"--config=" + filepath.Join(otelCollectorConfigMountDir, otelCollectorConfigFileName)},
+		VolumeMounts: []core.VolumeMount{{Name: collectorName, MountPath: otelCollectorConfigMountDir}},
+	})
+	service.AddVolume(core.Volume{
+		Name:         collectorName,
+		VolumeSource: core.VolumeSource{ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: collectorName}}},
+	})
+	return irtypes.Storage{
+		Name:        collectorName,
+		StorageType: irtypes.ConfigMapKind,
+		Content:     map[string][]byte{otelCollectorConfigFileName: []byte(collectorConfig)},
+	}
+}
+
+// addFluentBitParser annotates the service with the fluent bit parser for its log format
+func addFluentBitParser(service *irtypes.Service, qaKeyPrefix string) string {
+	detectedLogFormat := "json"
+	for _, container := range service.Containers {
+		imageName, _ := common.GetImageNameAndTag(container.Image)
+		imageName = filepath.Base(imageName)
+		for image, logFormat := range imageLogFormats {
+			if strings.Contains(imageName, image) {
+				detectedLogFormat = logFormat
+			}
+		}
+	}
+	logFormats := []string{}
+	for logFormat := range fluentBitParsers {
+		logFormats = append(logFormats, logFormat)
+	}
+	sort.Strings(logFormats)
+	logFormat := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(qaKeyPrefix, common.ConfigObservabilityLogFormatKeySuffix),
+		fmt.Sprintf("Select the log format of the service %s :", service.Name),
+		[]string{"The fluent bit parser for the format is added to the " + fluentBitParsersName + " config map"},
+		detectedLogFormat,
+		logFormats,
+	)
+	if _, ok := fluentBitParsers[logFormat]; !ok {
+		logrus.Warnf("Unsupported log format %s for the service %s. Using %s", logFormat, service.Name, detectedLogFormat)
+		logFormat = detectedLogFormat
+	}
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[fluentBitParserAnnotation] = logFormat
+	return logFormat
+}
@@ -0,0 +1,138 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/spf13/cast"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestWireObservabilityServiceMonitors(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.observability.services=["api","web","worker"]`,
+		`move2kube.observability."api".features=["Prometheus ServiceMonitor"]`,
+		`move2kube.observability."web".features=["Prometheus scrape annotations","Prometheus ServiceMonitor"]`,
+		`move2kube.observability."web".metricsport="9090"`,
+		`move2kube.observability."web".metricspath="/prometheus"`,
+		`move2kube.observability."worker".features=["Prometheus ServiceMonitor"]`,
+	}, nil, nil, false, false)
+
+	ir := irtypes.NewIR()
+	api := irtypes.NewServiceWithName("api")
+	api.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Name: "http", Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 8080}, ServiceType: core.ServiceTypeClusterIP},
+	}
+	web := irtypes.NewServiceWithName("web")
+	web.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Number: 80}, PodPort: networking.ServiceBackendPort{Number: 8080}, ServiceType: core.ServiceTypeClusterIP},
+		{ServicePort: networking.ServiceBackendPort{Number: 9090}, PodPort: networking.ServiceBackendPort{Number: 9091}, ServiceType: core.ServiceTypeClusterIP},
+	}
+	ir.Services = map[string]irtypes.Service{"api": api, "web": web, "worker": irtypes.NewServiceWithName("worker")}
+
+	ir, serviceMonitors := wireObservability(ir, collecttypes.ClusterMetadata{})
+	expectedAnnotations := map[string]string{"prometheus.io/scrape": "true", "prometheus.io/port": "9091", "prometheus.io/path": "/prometheus"}
+	if diff := cmp.Diff(expectedAnnotations, ir.Services["web"].Annotations); diff != "" {
+		t.Fatalf("wrong scrape annotations on the service web. Difference:\n%s", diff)
+	}
+	if len(ir.Services["api"].Annotations) != 0 {
+		t.Fatalf("expected the service api to not have scrape annotations. Actual: %+v", ir.Services["api"].Annotations)
+	}
+
+	outputPath := t.TempDir()
+	files, err := apiresource.TransformIRAndPersist(irtypes.NewEnhancedIRFromIR(ir), outputPath, []apiresource.IAPIResource{new(apiresource.Service)}, collecttypes.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to create the services. Error: %q", err)
+	}
+	serviceLabels := map[string]map[string]string{}
+	servicePortNames := map[string][]string{}
+	for _, file := range files {
+		service := map[string]interface{}{}
+		if err := common.ReadYaml(file, &service); err != nil {
+			t.Fatalf("failed to read the resource at %s . Error: %q", file, err)
+		}
+		if service["kind"] != common.ServiceKind {
+			continue
+		}
+		metadata := cast.ToStringMap(service["metadata"])
+		name := cast.ToString(metadata["name"])
+		serviceLabels[name] = cast.ToStringMapString(metadata["labels"])
+		for _, port := range cast.ToSlice(cast.ToStringMap(service["spec"])["ports"]) {
+			servicePortNames[name] = append(servicePortNames[name], cast.ToString(cast.ToStringMap(port)["name"]))
+		}
+	}
+
+	expectedServiceMonitors := []customResourceT{
+		{
+			APIVersion: serviceMonitorAPIVersion,
+			Kind:       serviceMonitorKind,
+			Metadata:   map[string]interface{}{"name": "api"},
+			Spec: map[string]interface{}{
+				"selector":  map[string]interface{}{"matchLabels": map[string]string{"move2kube.konveyor.io/service": "api"}},
+				"endpoints": []map[string]interface{}{{"port": "http", "path": defaultMetricsPath}},
+			},
+		},
+		{
+			APIVersion: serviceMonitorAPIVersion,
+			Kind:       serviceMonitorKind,
+			Metadata:   map[string]interface{}{"name": "web"},
+			Spec: map[string]interface{}{
+				"selector":  map[string]interface{}{"matchLabels": map[string]string{"move2kube.konveyor.io/service": "web"}},
+				"endpoints": []map[string]interface{}{{"port": "port-9090", "path": "/prometheus"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedServiceMonitors, serviceMonitors); diff != "" {
+		t.Fatalf("wrong service monitors. Difference:\n%s", diff)
+	}
+	for _, serviceMonitor := range serviceMonitors {
+		name := cast.ToString(serviceMonitor.Metadata["name"])
+		matchLabels := serviceMonitor.Spec["selector"].(map[string]interface{})["matchLabels"].(map[string]string)
+		for key, value := range matchLabels {
+			if serviceLabels[name][key] != value {
+				t.Fatalf("the service monitor %s does not select the service %s . Match labels: %+v Service labels: %+v", name, name, matchLabels, serviceLabels[name])
+			}
+		}
+		for _, endpoint := range serviceMonitor.Spec["endpoints"].([]map[string]interface{}) {
+			if !common.IsPresent(servicePortNames[name], endpoint["port"].(string)) {
+				t.Fatalf("the port %s of the service monitor %s is not a port of the service. Service ports: %+v", endpoint["port"], name, servicePortNames[name])
+			}
+		}
+	}
+
+	serviceMonitorFiles, err := persistCustomResources(outputPath, serviceMonitors)
+	if err != nil {
+		t.Fatalf("failed to write the service monitors. Error: %q", err)
+	}
+	for i, file := range serviceMonitorFiles {
+		if expected := filepath.Join(outputPath, serviceMonitors[i].Metadata["name"].(string)+"-"+strings.ToLower(serviceMonitorKind)+".yaml"); file != expected {
+			t.Fatalf("wrong path of the service monitor. Expected: %s Actual: %s", expected, file)
+		}
+	}
+}
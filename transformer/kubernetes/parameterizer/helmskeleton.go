@@ -0,0 +1,144 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package parameterizer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	helmChartFileName  = "Chart.yaml"
+	helmValuesFileName = "values.yaml"
+	// skeletonLabelsMarker is a label that gets replaced by an include of the labels template of the skeleton
+	skeletonLabelsMarker = types.GroupName + "/helm-skeleton-labels"
+)
+
+var (
+	skeletonLabelsMarkerRegex = regexp.MustCompile(`(?m)^( *)` + regexp.QuoteMeta(skeletonLabelsMarker) + `: (.+)$`)
+)
+
+// copyHelmChartSkeleton copies the files of the skeleton chart into the chart directory.
+// The Chart.yaml and values.yaml of the skeleton are not copied, since they are merged with the generated ones.
+func copyHelmChartSkeleton(skeletonDir, helmChartDir string) ([]string, error) {
+	filesWritten := []string{}
+	if _, err := os.Stat(filepath.Join(skeletonDir, helmChartFileName)); err != nil {
+		return filesWritten, fmt.Errorf("the helm chart skeleton directory %s does not have a %s . Error: %q", skeletonDir, helmChartFileName, err)
+	}
+	err := filepath.Walk(skeletonDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(skeletonDir, path)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(helmChartDir, relPath)
+		if info.IsDir() {
+			return os.MkdirAll(destPath, common.DefaultDirectoryPermission)
+		}
+		if relPath == helmChartFileName || relPath == helmValuesFileName {
+			return nil
+		}
+		if err := common.CopyFile(destPath, path); err != nil {
+			return err
+		}
+		filesWritten = append(filesWritten, destPath)
+		return nil
+	})
+	if err != nil {
+		return filesWritten, fmt.Errorf("failed to copy the helm chart skeleton from %s to %s . Error: %q", skeletonDir, helmChartDir, err)
+	}
+	return filesWritten, nil
+}
+
+// getSkeletonChartYaml returns the Chart.yaml of the skeleton with the name of the generated chart.
+// Fields that the skeleton does not set are filled in with the defaults.
+func getSkeletonChartYaml(skeletonDir string, defaultChartYaml map[string]interface{}) (map[string]interface{}, error) {
+	chartYamlPath := filepath.Join(skeletonDir, helmChartFileName)
+	chartYaml := map[string]interface{}{}
+	if err := common.ReadYaml(chartYamlPath, &chartYaml); err != nil {
+		return nil, fmt.Errorf("failed to read the Chart.yaml of the helm chart skeleton at path %s . Error: %q", chartYamlPath, err)
+	}
+	if chartYaml == nil {
+		chartYaml = map[string]interface{}{}
+	}
+	for key, value := range defaultChartYaml {
+		if _, ok := chartYaml[key]; !ok || key == "name" {
+			chartYaml[key] = value
+		}
+	}
+	return chartYaml, nil
+}
+
+// getSkeletonValues returns the values.yaml of the skeleton, or an empty map if the skeleton does not have one
+func getSkeletonValues(skeletonDir string) map[string]interface{} {
+	valuesPath := filepath.Join(skeletonDir, helmValuesFileName)
+	values := map[string]interface{}{}
+	if _, err := os.Stat(valuesPath); err != nil {
+		return values
+	}
+	if err := common.ReadYaml(valuesPath, &values); err != nil {
+		logrus.Errorf("failed to read the values.yaml of the helm chart skeleton at path %s . Error: %q", valuesPath, err)
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values
+}
+
+// mergeSkeletonValues merges the generated values on top of the values of the skeleton
+func mergeSkeletonValues(skeletonValues map[string]interface{}, values HelmValuesT) interface{} {
+	if len(skeletonValues) == 0 {
+		return values
+	}
+	return deepcopy.Merge(skeletonValues, map[string]interface{}(values))
+}
+
+// addSkeletonLabelsMarker adds a marker to the labels of the resource, which is replaced by an include of the labels template
+func addSkeletonLabelsMarker(k k8sschema.K8sResourceT, labelsTemplate string) {
+	metadata, ok := k["metadata"].(map[string]interface{})
+	if !ok {
+		logrus.Debugf("unable to add the helm chart skeleton labels to the resource without metadata: %+v", k)
+		return
+	}
+	labels, ok := metadata["labels"].(map[string]interface{})
+	if !ok {
+		labels = map[string]interface{}{}
+	}
+	labels[skeletonLabelsMarker] = labelsTemplate
+	metadata["labels"] = labels
+}
+
+// replaceSkeletonLabelsMarkers replaces the markers with an include of the labels template at the same indentation
+func replaceSkeletonLabelsMarkers(yamlBytes []byte) []byte {
+	return skeletonLabelsMarkerRegex.ReplaceAllFunc(yamlBytes, func(match []byte) []byte {
+		groups := skeletonLabelsMarkerRegex.FindSubmatch(match)
+		indent := string(groups[1])
+		labelsTemplate := strings.Trim(strings.TrimSpace(string(groups[2])), `"'`)
+		return []byte(fmt.Sprintf(`%s{{- include "%s" . | nindent %d }}`, indent, labelsTemplate, len(indent)))
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -85,9 +86,18 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		helmChartDir := filepath.Join(cleanOutDir, packSpecConfig.Helm, helmChartName)
 
 		helmTemplatesDir := filepath.Join(helmChartDir, "templates")
+		skeleton := packSpecConfig.HelmSkeleton
 		if err := os.MkdirAll(helmTemplatesDir, common.DefaultDirectoryPermission); err != nil {
 			logrus.Errorf("Unable to create directory for helm : %s", err)
 		} else {
+			if skeleton.Path != "" {
+				skeletonFilesWritten, err := copyHelmChartSkeleton(skeleton.Path, helmChartDir)
+				filesWritten = append(filesWritten, skeletonFilesWritten...)
+				if err != nil {
+					logrus.Errorf("Unable to use the helm chart skeleton. Falling back to the default layout. Error: %q", err)
+					skeleton = HelmChartSkeletonT{}
+				}
+			}
 			for kPath, ks := range pathedKs {
 				for _, k := range ks {
 					k = deepcopy.DeepCopy(k).(k8sschema.K8sResourceT)
@@ -95,6 +105,9 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 						logrus.Errorf("Unable to parameterize for helm : %s", err)
 						continue
 					}
+					if skeleton.LabelsTemplate != "" {
+						addSkeletonLabelsMarker(k, skeleton.LabelsTemplate)
+					}
 					finalKPath := filepath.Join(helmTemplatesDir, kPath)
 					if err := writeResourceStripQuotesAndAppendToFile(k, finalKPath); err != nil {
 						logrus.Errorf("Unable to write parameterized file to %s : %s", finalKPath, err)
@@ -103,17 +116,33 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 					filesWritten = append(filesWritten, finalKPath)
 				}
 			}
+			skeletonValues := map[string]interface{}{}
+			if skeleton.Path != "" {
+				skeletonValues = getSkeletonValues(skeleton.Path)
+			}
 			for env, values := range namedValues {
 				finalKPath := filepath.Join(helmChartDir, "values-"+env+".yaml")
+				var finalValues interface{} = values
 				if shouldGenerateDefaultEnv && env == parameterizerDefaultEnvironment {
 					finalKPath = filepath.Join(helmChartDir, "values.yaml")
+					// helm always uses the values.yaml, so the defaults of the skeleton are kept in it
+					finalValues = mergeSkeletonValues(skeletonValues, values)
+					skeletonValues = nil
 				}
-				if err := common.WriteYaml(finalKPath, values); err != nil {
+				if err := common.WriteYaml(finalKPath, finalValues); err != nil {
 					logrus.Errorf("Unable to write env %s : %s", env, err)
 					continue
 				}
 				filesWritten = append(filesWritten, finalKPath)
 			}
+			if len(skeletonValues) > 0 {
+				finalKPath := filepath.Join(helmChartDir, "values.yaml")
+				if err := common.WriteYaml(finalKPath, skeletonValues); err != nil {
+					logrus.Errorf("Unable to write the values of the helm chart skeleton to %s : %s", finalKPath, err)
+				} else {
+					filesWritten = append(filesWritten, finalKPath)
+				}
+			}
 			helmChartYaml := map[string]interface{}{
 				"apiVersion":  "v2",
 				"name":        helmChartName,
@@ -121,6 +150,13 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 				"description": "A Helm Chart generated by Move2Kube for " + helmChartName,
 				"keywords":    []string{helmChartName},
 			}
+			if skeleton.Path != "" {
+				if skeletonChartYaml, err := getSkeletonChartYaml(skeleton.Path, helmChartYaml); err != nil {
+					logrus.Errorf("Unable to use the Chart.yaml of the helm chart skeleton : %s", err)
+				} else {
+					helmChartYaml = skeletonChartYaml
+				}
+			}
 			finalKPath := filepath.Join(helmChartDir, "Chart.yaml")
 			if err := common.WriteYaml(finalKPath, helmChartYaml); err != nil {
 				logrus.Errorf("Unable to write %s : %s", finalKPath, err)
@@ -138,7 +174,8 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		} else {
 			kustPatches := map[string]map[PatchMetadataT][]PatchT{}
 			kPaths := []string{}
-			for kPath, ks := range pathedKs {
+			for _, kPath := range getSortedKPaths(pathedKs) {
+				ks := pathedKs[kPath]
 				for _, k := range ks {
 					// base
 					finalKPath := filepath.Join(baseDir, kPath)
@@ -202,6 +239,7 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 					metas = append(metas, kMeta)
 					filesWritten = append(filesWritten, finalKPath)
 				}
+				sort.Slice(metas, func(i, j int) bool { return metas[i].Path < metas[j].Path })
 				kustomization := map[string]interface{}{"resources": []string{"../../base"}, "patches": metas}
 				finalKPath := filepath.Join(envDir, "kustomization.yaml")
 				if err := common.WriteYaml(finalKPath, kustomization); err != nil {
@@ -216,8 +254,8 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		// openshift templates for each env
 		newKs := []k8sschema.K8sResourceT{}
 		ocParams := map[string]map[string]string{}
-		for _, ks := range pathedKs {
-			for _, k := range ks {
+		for _, kPath := range getSortedKPaths(pathedKs) {
+			for _, k := range pathedKs[kPath] {
 				k = deepcopy.DeepCopy(k).(k8sschema.K8sResourceT)
 				if err := parameterize(TargetOCTemplates, packSpecConfig.Envs, k, ps, nil, nil, ocParams); err != nil {
 					logrus.Errorf("Unable to parameterize for OC Templates : %s", err)
@@ -232,6 +270,7 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 				for k, v := range kvs {
 					singleSet = append(singleSet, OCParamT{Name: k, Value: v})
 				}
+				sort.Slice(singleSet, func(i, j int) bool { return singleSet[i].Name < singleSet[j].Name })
 				break
 			}
 		}
@@ -262,6 +301,7 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 				for k, v := range params {
 					finalParams = append(finalParams, fmt.Sprintf("%s=%s", k, v))
 				}
+				sort.Strings(finalParams)
 				if err := os.WriteFile(finalKPath, []byte(strings.Join(finalParams, "\n")), common.DefaultFilePermission); err != nil {
 					logrus.Errorf("Unable to write to %s : %s", finalKPath, err)
 					continue
@@ -276,6 +316,16 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 // ------------------------------
 // Utilities
 
+// getSortedKPaths returns the paths of the k8s resources in a stable order, so the output is the same on every run
+func getSortedKPaths(pathedKs map[string][]k8sschema.K8sResourceT) []string {
+	kPaths := []string{}
+	for kPath := range pathedKs {
+		kPaths = append(kPaths, kPath)
+	}
+	sort.Strings(kPaths)
+	return kPaths
+}
+
 func getGVKNFromK(k k8sschema.K8sResourceT) (group string, version string, kind string, metadataName string, err error) {
 	var apiVersion string
 	kind, apiVersion, metadataName, err = k8sschema.GetInfoFromK8sResource(k)
@@ -307,6 +357,29 @@ func getParameters(templ string) ([]string, error) {
 	return parameters, nil
 }
 
+// getTypedParameterValue converts the environment specific value of a parameter to the type of the original value
+func getTypedParameterValue(value string, originalValue interface{}) interface{} {
+	var typedValue interface{}
+	var err error
+	switch originalValue.(type) {
+	case bool:
+		typedValue, err = cast.ToBoolE(value)
+	case int:
+		typedValue, err = cast.ToIntE(value)
+	case int64:
+		typedValue, err = cast.ToInt64E(value)
+	case float64:
+		typedValue, err = cast.ToFloat64E(value)
+	default:
+		return value
+	}
+	if err != nil {
+		logrus.Debugf("failed to convert the parameter value %s to the type %T of the original value. Error: %q", value, originalValue, err)
+		return value
+	}
+	return typedValue
+}
+
 func doesMatchEnv(p ParameterValueT, env, kind, apiVersion, metadataName string, matches map[string]string) bool {
 	if p.Envs != nil && !common.IsPresent(p.Envs, string(env)) {
 		return false
@@ -432,7 +505,47 @@ func fillCustomTemplate(templ, kind, apiVersion, metadataName string, matches ma
 // ------------------------------
 // Parameterization
 
+// fillSubKeys returns the quoted sub keys of the parameter, after replacing the sub keys like $(metadataName) and the names of the matches
+func fillSubKeys(parameter, kind, apiVersion, metadataName string, resultKV RT) ([]string, error) {
+	subKeys := GetSubKeys(parameter)
+	for i, subKey := range subKeys {
+		if !strings.HasPrefix(subKey, "$(") || !strings.HasSuffix(subKey, ")") {
+			subKeys[i] = `"` + subKey + `"`
+			continue
+		}
+		subKey = strings.TrimSuffix(strings.TrimPrefix(subKey, "$("), ")")
+		matchedSubKey, ok := resultKV.Matches[subKey]
+		if ok {
+			subKeys[i] = `"` + matchedSubKey + `"`
+			continue
+		}
+		switch subKey {
+		case "kind":
+			subKeys[i] = `"` + kind + `"`
+		case "apiVersion":
+			subKeys[i] = `"` + apiVersion + `"`
+		case "metadataName":
+			subKeys[i] = `"` + metadataName + `"`
+		default:
+			return subKeys, fmt.Errorf("failed to find the sub key $(%s) in the any of the keys that matched: %+v", subKey, resultKV)
+		}
+	}
+	return subKeys, nil
+}
+
+// getHelmTemplateWithFallback returns a helm template that uses the value of the fallback sub keys when the sub keys are not in the values
+func getHelmTemplateWithFallback(subKeys, fallbackSubKeys []string) string {
+	parent := ".Values"
+	for _, subKey := range subKeys[:len(subKeys)-1] {
+		parent = fmt.Sprintf(`(index %s %s | default dict)`, parent, subKey)
+	}
+	last := subKeys[len(subKeys)-1]
+	return fmt.Sprintf(`{{ if hasKey %s %s }}{{ index %s %s }}{{ else }}{{ index .Values %s }}{{ end }}`, parent, last, parent, last, strings.Join(fallbackSubKeys, " "))
+}
+
 func parameterize(target ParamTargetT, envs []string, k k8sschema.K8sResourceT, ps []ParameterizerT, namedValues map[string]HelmValuesT, namedKustPatches map[string]map[string]PatchT, namedOCParams map[string]map[string]string) error {
+	// a field is only parameterized by the first parameterizer that targets it
+	parameterizedKeys := map[string]bool{}
 	for _, p := range ps {
 		ok, err := parameterizeFilter(envs, k, p)
 		if err != nil {
@@ -441,6 +554,26 @@ func parameterize(target ParamTargetT, envs []string, k k8sschema.K8sResourceT,
 		if !ok {
 			continue
 		}
+		resultKVs, err := GetAll(p.Target, k)
+		if err != nil {
+			// targets like the env of the containers are only present on some of the resources
+			logrus.Debugf("skipping the parameterizer for the target %s since the resource does not have it. Error: %q", p.Target, err)
+			continue
+		}
+		if len(resultKVs) > 0 {
+			alreadyParameterized := true
+			for _, resultKV := range resultKVs {
+				key := strings.Join(resultKV.Key, ".")
+				if !parameterizedKeys[key] {
+					alreadyParameterized = false
+				}
+				parameterizedKeys[key] = true
+			}
+			if alreadyParameterized {
+				logrus.Debugf("skipping the parameterizer for the target %s since the fields have already been parameterized", p.Target)
+				continue
+			}
+		}
 		switch target {
 		case TargetHelm:
 			if err := parameterizeHelperHelm(envs, k, p, namedValues, namedKustPatches, namedOCParams); err != nil {
@@ -551,7 +684,8 @@ func parameterizeHelperHelm(envs []string, k k8sschema.K8sResourceT, p Parameter
 			return fmt.Errorf("failed to get the parameters from the template: %s\nError: %q", templ, err)
 		}
 		paramValue := p.Default
-		if paramValue == nil {
+		if paramValue == nil || p.Fallback != "" {
+			// the default is the value of the fallback parameter, and the parameter of the template gets the value of the resource
 			paramValue = resultKV.Value
 		}
 		if p.Question != nil {
@@ -584,31 +718,28 @@ func parameterizeHelperHelm(envs []string, k k8sschema.K8sResourceT, p Parameter
 		}
 		if len(parameters) == 1 {
 			parameter := parameters[0]
-			subKeys := GetSubKeys(parameter)
-			for i, subKey := range subKeys {
-				if !strings.HasPrefix(subKey, "$(") || !strings.HasSuffix(subKey, ")") {
-					subKeys[i] = `"` + subKey + `"`
-					continue
+			subKeys, err := fillSubKeys(parameter, kind, apiVersion, metadataName, resultKV)
+			if err != nil {
+				return err
+			}
+			paramKey := strings.Join(subKeys, ".")
+			helmTemplate := fmt.Sprintf(`{{ index .Values %s }}`, strings.Join(subKeys, " "))
+			fallbackKey := ""
+			if p.Fallback != "" {
+				fallbackParameters, err := getParameters(p.Fallback)
+				if err != nil {
+					return fmt.Errorf("failed to get the parameters from the fallback template: %s\nError: %q", p.Fallback, err)
 				}
-				subKey = strings.TrimSuffix(strings.TrimPrefix(subKey, "$("), ")")
-				matchedSubKey, ok := resultKV.Matches[subKey]
-				if ok {
-					subKeys[i] = `"` + matchedSubKey + `"`
-					continue
+				if len(fallbackParameters) != 1 || p.Default == nil {
+					return fmt.Errorf("the fallback template %s must have a single parameter and the parameterizer must have a default. Parameterizer: %+v", p.Fallback, p)
 				}
-				switch subKey {
-				case "kind":
-					subKeys[i] = `"` + kind + `"`
-				case "apiVersion":
-					subKeys[i] = `"` + apiVersion + `"`
-				case "metadataName":
-					subKeys[i] = `"` + metadataName + `"`
-				default:
-					return fmt.Errorf("failed to find the sub key $(%s) in the any of the keys that matched: %+v", subKey, resultKV)
+				fallbackSubKeys, err := fillSubKeys(fallbackParameters[0], kind, apiVersion, metadataName, resultKV)
+				if err != nil {
+					return err
 				}
+				fallbackKey = strings.Join(fallbackSubKeys, ".")
+				helmTemplate = getHelmTemplateWithFallback(subKeys, fallbackSubKeys)
 			}
-			paramKey := strings.Join(subKeys, ".")
-			helmTemplate := fmt.Sprintf(`{{ index .Values %s }}`, strings.Join(subKeys, " "))
 			if len(p.Parameters) > 0 {
 				if len(p.Parameters) != 1 {
 					return fmt.Errorf("the template only has a single parameter. Expected a single paramter definition. Actual length: %d Parameters: %+v", len(p.Parameters), p.Parameters)
@@ -629,11 +760,13 @@ func parameterizeHelperHelm(envs []string, k k8sschema.K8sResourceT, p Parameter
 			}
 			for _, env := range envs {
 				origParamValue := paramValue
+				matchedValue := false
 				if len(p.Parameters) > 0 {
 					param := p.Parameters[0]
 					for _, pV := range param.Values {
 						if doesMatchEnv(pV, env, kind, apiVersion, metadataName, resultKV.Matches) {
-							paramValue = pV.Value
+							paramValue = getTypedParameterValue(pV.Value, resultKV.Value)
+							matchedValue = true
 							break
 						}
 					}
@@ -642,6 +775,15 @@ func parameterizeHelperHelm(envs []string, k k8sschema.K8sResourceT, p Parameter
 				if _, ok := namedValues[env]; !ok {
 					namedValues[env] = HelmValuesT{}
 				}
+				if fallbackKey != "" {
+					if err := setCreatingNew(fallbackKey, p.Default, namedValues[env]); err != nil {
+						return fmt.Errorf("failed to set the key %s to the value %+v in the values.yaml %+v for the env %s . Error: %q", fallbackKey, p.Default, namedValues[env], env, err)
+					}
+					if !matchedValue && fmt.Sprint(paramValue) == fmt.Sprint(p.Default) {
+						// the value of the fallback parameter is used
+						continue
+					}
+				}
 				if err := setCreatingNew(paramKey, paramValue, namedValues[env]); err != nil {
 					return fmt.Errorf("failed to set the key %s to the value %+v in the values.yaml %+v for the env %s . Error: %q", paramKey, paramValue, namedValues[env], env, err)
 				}
@@ -790,7 +932,7 @@ func parameterizeHelperKustomize(envs []string, k k8sschema.K8sResourceT, p Para
 		key := strings.Join(t1, ".")
 		JSONPointer := subKeysToJSONPointer6901(resultKV.Key)
 		paramValue := p.Default
-		if paramValue == nil {
+		if paramValue == nil || p.Fallback != "" {
 			paramValue = resultKV.Value
 		}
 		if p.Question != nil {
@@ -831,7 +973,7 @@ func parameterizeHelperKustomize(envs []string, k k8sschema.K8sResourceT, p Para
 					// no need to check the parameter name since for kustomize there should be at most one parameter
 					for _, pV := range param.Values {
 						if doesMatchEnv(pV, env, kind, apiVersion, metadataName, resultKV.Matches) {
-							paramValue = pV.Value
+							paramValue = getTypedParameterValue(pV.Value, resultKV.Value)
 							break
 						}
 					}
@@ -878,7 +1020,7 @@ func parameterizeHelperOCTemplates(envs []string, k k8sschema.K8sResourceT, p Pa
 			return fmt.Errorf("failed to get the parameters from the template: %s\nError: %q", templ, err)
 		}
 		paramValue := p.Default
-		if paramValue == nil {
+		if paramValue == nil || p.Fallback != "" {
 			paramValue = resultKV.Value
 		}
 		if p.Question != nil {
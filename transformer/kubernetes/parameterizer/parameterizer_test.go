@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/transformer/kubernetes/helmchart"
 	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
 	"github.com/sirupsen/logrus"
 )
@@ -83,3 +84,113 @@ func TestGettingAndParameterizingResources(t *testing.T) {
 		}
 	}
 }
+
+func TestParameterizingWithHelmChartSkeleton(t *testing.T) {
+	k8sResourcesPath := filepath.Join("testdata", "k8s-resources")
+	skeletonPath := t.TempDir()
+	skeletonFiles := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: skeleton\nversion: 1.2.3\ndependencies:\n  - name: common\n    version: 2.x.x\n    repository: https://charts.example.com\n",
+		"values.yaml": "team: platform\n",
+		"templates/_helpers.tpl": `{{- define "skeleton.labels" -}}
+team: {{ .Values.team }}
+{{- end }}
+`,
+	}
+	for relPath, contents := range skeletonFiles {
+		path := filepath.Join(skeletonPath, relPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create the directory for the skeleton file %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to write the skeleton file %s . Error: %q", path, err)
+		}
+	}
+	outputPath := t.TempDir()
+	psp := parameterizer.ParameterizerConfigT{
+		Helm:         "helm-chart",
+		ProjectName:  "myproject",
+		HelmSkeleton: parameterizer.HelmChartSkeletonT{Path: skeletonPath, LabelsTemplate: "skeleton.labels"},
+	}
+	if _, err := parameterizer.Parameterize(k8sResourcesPath, outputPath, psp, nil); err != nil {
+		t.Fatalf("Failed to parameterize with the helm chart skeleton. Error: %q", err)
+	}
+	chartDir := filepath.Join(outputPath, "helm-chart", "myproject")
+	wants := map[string][]string{
+		"Chart.yaml":             {"name: myproject", "version: 1.2.3", "repository: https://charts.example.com"},
+		"values.yaml":            {"team: platform"},
+		"templates/_helpers.tpl": {`define "skeleton.labels"`},
+		"templates/dep-v1.yaml":  {`{{- include "skeleton.labels" . | nindent 8 }}`},
+	}
+	for relPath, wantLines := range wants {
+		actualBytes, err := os.ReadFile(filepath.Join(chartDir, relPath))
+		if err != nil {
+			t.Fatalf("Failed to read the output file %s . Error: %q", relPath, err)
+		}
+		for _, wantLine := range wantLines {
+			if !strings.Contains(string(actualBytes), wantLine) {
+				t.Fatalf("The file %s does not contain %q . Actual:\n%s", relPath, wantLine, string(actualBytes))
+			}
+		}
+	}
+}
+
+func TestParameterizingWithFallback(t *testing.T) {
+	k8sResourcesPath := filepath.Join("testdata", "k8s-resources")
+	outputPath := t.TempDir()
+	psp := parameterizer.ParameterizerConfigT{Helm: "helm-chart", ProjectName: "myproject"}
+	ps := []parameterizer.ParameterizerT{{
+		Target:   "spec.replicas",
+		Template: "${$(metadataName).replicas}",
+		Fallback: "${common.replicas}",
+		Default:  3,
+		Filters:  []parameterizer.FilterT{{Kind: "Deployment"}},
+	}}
+	if _, err := parameterizer.Parameterize(k8sResourcesPath, outputPath, psp, ps); err != nil {
+		t.Fatalf("Failed to parameterize with the fallback. Error: %q", err)
+	}
+	chart, err := helmchart.LoadChart(filepath.Join(outputPath, "helm-chart", "myproject"))
+	if err != nil {
+		t.Fatalf("Failed to load the parameterized helm chart. Error: %q", err)
+	}
+	wantValues := map[string]interface{}{
+		"common":        map[string]interface{}{"replicas": 3},
+		"javaspringapp": map[string]interface{}{"replicas": 5},
+	}
+	if !cmp.Equal(chart.Values, wantValues) {
+		t.Fatalf("The values are different from expected. Differences:\n%s", cmp.Diff(wantValues, chart.Values))
+	}
+	testCases := []struct {
+		name   string
+		values map[string]interface{}
+		want   map[string]string
+	}{
+		{
+			name: "default values",
+			want: map[string]string{"dep-v1.yaml": "replicas: 3", "dep-v1beta1.yaml": "replicas: 5"},
+		},
+		{
+			name:   "override the fallback",
+			values: map[string]interface{}{"common": map[string]interface{}{"replicas": 7}},
+			want:   map[string]string{"dep-v1.yaml": "replicas: 7", "dep-v1beta1.yaml": "replicas: 5"},
+		},
+		{
+			name:   "scale a service to zero",
+			values: map[string]interface{}{"javaspringapp": map[string]interface{}{"replicas": 0}},
+			want:   map[string]string{"dep-v1.yaml": "replicas: 3", "dep-v1beta1.yaml": "replicas: 0"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rendered, err := helmchart.Render(chart, testCase.values, helmchart.RenderOptions{ReleaseName: "myrelease"})
+			if err != nil {
+				t.Fatalf("Failed to render the chart. Error: %q", err)
+			}
+			for name, want := range testCase.want {
+				actual := rendered[filepath.Join("myproject", "templates", name)]
+				if !strings.Contains(actual, want) {
+					t.Fatalf("The template %s does not contain %q . Actual:\n%s", name, want, actual)
+				}
+			}
+		})
+	}
+}
@@ -38,6 +38,16 @@ type ParameterizerConfigT struct {
 	Kustomize   string   `yaml:"kustomize,omitempty" json:"kustomize,omitempty"`
 	OCTemplates string   `yaml:"openshiftTemplates,omitempty" json:"openshiftTemplates,omitempty"`
 	Envs        []string `yaml:"envs,omitempty" json:"envs,omitempty"`
+	// HelmSkeleton is the chart that the parameterized templates and values are added to, instead of the default layout
+	HelmSkeleton HelmChartSkeletonT `yaml:"helmSkeleton,omitempty" json:"helmSkeleton,omitempty"`
+}
+
+// HelmChartSkeletonT is a helm chart provided by the customizations to conform to the chart standards of an organization
+type HelmChartSkeletonT struct {
+	// Path is the directory containing the Chart.yaml, the templates (like _helpers.tpl) and the sub charts of the skeleton
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// LabelsTemplate is a named template of the skeleton that is included in the labels of every generated resource
+	LabelsTemplate string `yaml:"labelsTemplate,omitempty" json:"labelsTemplate,omitempty"`
 }
 
 // ParameterizerFileT is the file format for the parameterizers
@@ -61,6 +71,9 @@ type ParameterizerT struct {
 	Question   *qaengine.Problem `yaml:"question,omitempty" json:"question,omitempty"`
 	Filters    []FilterT         `yaml:"filters,omitempty" json:"filters,omitempty"`
 	Parameters []ParameterT      `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	// Fallback is a template with a single parameter, which is used in the helm charts when the parameter of the template is not in the values.
+	// The fallback parameter is set to the default, and the parameter of the template is only set when the value of the resource is different.
+	Fallback string `yaml:"fallback,omitempty" json:"fallback,omitempty"`
 }
 
 // FilterT is used to choose the k8s resources that the parameterizer should be applied on
@@ -325,6 +325,7 @@ func writeResourceStripQuotesAndAppendToFile(k8sResource k8sschema.K8sResourceT,
 		return err
 	}
 	strippedYamlBytes := stripHelmQuotesRegex.ReplaceAll(yamlBytes, []byte("$1"))
+	strippedYamlBytes = replaceSkeletonLabelsMarkers(strippedYamlBytes)
 	if err := os.MkdirAll(filepath.Dir(outputPath), common.DefaultDirectoryPermission); err != nil {
 		logrus.Fatalf("Failed to create the output directory at path %s Error: %q", filepath.Dir(outputPath), err)
 	}
@@ -24,6 +24,7 @@ import (
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/transformer/kubernetes/kustomize"
 	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
@@ -34,8 +35,21 @@ const (
 	helmPathTemplateName       = "HelmPath"
 	kustomizePathTemplateName  = "KustomizePath"
 	ocTemplatePathTemplateName = "OCTemplatePath"
+	// ParameterizersConfigType stores the parameterizers that apply to a specific set of yamls
+	ParameterizersConfigType transformertypes.ConfigType = "Parameterizers"
+	// ParameterizerEnvironmentsConfigType stores the environments that the parameterized yamls should be generated for
+	ParameterizerEnvironmentsConfigType transformertypes.ConfigType = "ParameterizerEnvironments"
 )
 
+func init() {
+	if err := artifacts.RegisterConfigType(ParameterizersConfigType, []parameterizer.ParameterizerT{}, "Parameterizers to apply to the yamls, in addition to the ones in the customizations"); err != nil {
+		logrus.Errorf("failed to register the config type %s . Error: %q", ParameterizersConfigType, err)
+	}
+	if err := artifacts.RegisterConfigType(ParameterizerEnvironmentsConfigType, []string{}, "Environments to generate the parameterized yamls for, in place of the ones in the transformer config"); err != nil {
+		logrus.Errorf("failed to register the config type %s . Error: %q", ParameterizerEnvironmentsConfigType, err)
+	}
+}
+
 // Parameterizer implements Transformer interface
 type Parameterizer struct {
 	Config              transformertypes.Transformer
@@ -51,6 +65,8 @@ type ParameterizerYamlConfig struct {
 	KustomizePath  string   `yaml:"kustomizePath" json:"kustomizePath"`
 	ProjectName    string   `yaml:"projectName" json:"projectName"`
 	Envs           []string `yaml:"envs,omitempty" json:"envs,omitempty"`
+	// HelmChartSkeleton is the helm chart that the generated helm charts are based on. The path is relative to the transformer directory.
+	HelmChartSkeleton parameterizer.HelmChartSkeletonT `yaml:"helmChartSkeleton,omitempty" json:"helmChartSkeleton,omitempty"`
 }
 
 // ParameterizerPathTemplateConfig stores the template config
@@ -73,6 +89,9 @@ func (t *Parameterizer) Init(tc transformertypes.Transformer, e *environment.Env
 	if t.ParameterizerConfig.ProjectName == "" {
 		t.ParameterizerConfig.ProjectName = e.ProjectName
 	}
+	if skeletonPath := t.ParameterizerConfig.HelmChartSkeleton.Path; skeletonPath != "" && !filepath.IsAbs(skeletonPath) {
+		t.ParameterizerConfig.HelmChartSkeleton.Path = filepath.Join(t.Env.Context, skeletonPath)
+	}
 	psmap, err := parameterizer.CollectParamsFromPath(t.Env.Context)
 	if err != nil {
 		logrus.Errorf("Error while parsing for params : %s", err)
@@ -91,6 +110,10 @@ func (t *Parameterizer) GetConfig() (transformertypes.Transformer, *environment.
 
 // DirectoryDetect runs detect in each subdirectory
 func (t *Parameterizer) DirectoryDetect(dir string) (namedServices map[string][]transformertypes.Artifact, err error) {
+	if kustomize.IsKustomizationDir(dir) {
+		// handled by the kustomization loader
+		return nil, nil
+	}
 	if len(k8sschema.GetKubernetesObjsInDir(dir)) != 0 {
 		na := transformertypes.Artifact{
 			Paths: map[transformertypes.PathType][]string{
@@ -129,15 +152,24 @@ func (t *Parameterizer) Transform(newArtifacts []transformertypes.Artifact, alre
 			continue
 		}
 		pt := parameterizer.ParameterizerConfigT{
-			Helm:        "helm",
-			Kustomize:   "kustomize",
-			OCTemplates: "octemplates",
-			ProjectName: projectName,
-			Envs:        []string{},
+			Helm:         "helm",
+			Kustomize:    "kustomize",
+			OCTemplates:  "octemplates",
+			ProjectName:  projectName,
+			Envs:         []string{},
+			HelmSkeleton: t.ParameterizerConfig.HelmChartSkeleton,
 		}
 		if len(t.ParameterizerConfig.Envs) > 0 {
 			pt.Envs = t.ParameterizerConfig.Envs
 		}
+		if _, ok := a.Configs[ParameterizerEnvironmentsConfigType]; ok {
+			envs := []string{}
+			if err := a.GetConfig(ParameterizerEnvironmentsConfigType, &envs); err != nil {
+				logrus.Errorf("Unable to load config for Transformer into %T : %s", envs, err)
+			} else if len(envs) > 0 {
+				pt.Envs = envs
+			}
+		}
 		if len(t.ParameterizerConfig.HelmPath) == 0 {
 			pt.Helm = ""
 		}
@@ -147,7 +179,16 @@ func (t *Parameterizer) Transform(newArtifacts []transformertypes.Artifact, alre
 		if len(t.ParameterizerConfig.OCTemplatePath) == 0 {
 			pt.OCTemplates = ""
 		}
-		filesWritten, err := parameterizer.Parameterize(yamlsPath, destPath, pt, t.parameterizers)
+		parameterizers := t.parameterizers
+		if _, ok := a.Configs[ParameterizersConfigType]; ok {
+			artifactParameterizers := []parameterizer.ParameterizerT{}
+			if err := a.GetConfig(ParameterizersConfigType, &artifactParameterizers); err != nil {
+				logrus.Errorf("Unable to load config for Transformer into %T : %s", artifactParameterizers, err)
+			}
+			// the parameterizers from the artifact go first, so they take precedence for the fields they target
+			parameterizers = append(append([]parameterizer.ParameterizerT{}, artifactParameterizers...), parameterizers...)
+		}
+		filesWritten, err := parameterizer.Parameterize(yamlsPath, destPath, pt, parameterizers)
 		if err != nil {
 			logrus.Errorf("failed to parameterize the YAML files in the source directory %s and write to output directory %s . Error: %q", yamlsPath, destPath, err)
 			continue
@@ -0,0 +1,267 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	rollingUpdateStrategy   = "RollingUpdate"
+	argoBlueGreenStrategy   = "Argo Rollouts blue/green"
+	argoCanaryStrategy      = "Argo Rollouts canary"
+	flaggerCanaryStrategy   = "Flagger canary"
+	defaultMaxSurge         = "25%"
+	defaultMaxUnavailable   = "25%"
+	argoRolloutsAPIVersion  = "argoproj.io/v1alpha1"
+	flaggerAPIVersion       = "flagger.app/v1beta1"
+	defaultPrometheusURL    = "http://prometheus.monitoring:9090"
+	previewServiceSuffix    = "-preview"
+	analysisTemplateSuffix  = "-success-rate"
+	metricTemplateSuffix    = "-latency"
+	serviceNameArgumentName = "service-name"
+)
+
+// wireRolloutStrategies asks for the rollout strategy of each service that becomes a deployment.
+// The rolling update tuning is stored in the IR. The Argo Rollouts and the Flagger canaries reference the deployment,
+// so the deployment is still generated, and they are returned along with their templates to be written with the other resources.
+func wireRolloutStrategies(ir irtypes.IR, clusterConfig collecttypes.ClusterMetadata) (irtypes.IR, []customResourceT) {
+	customResources := []customResourceT{}
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if isDeployment(service, clusterConfig) {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+	strategies := []string{rollingUpdateStrategy, argoBlueGreenStrategy, argoCanaryStrategy, flaggerCanaryStrategy}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`)
+		strategy := qaengine.FetchSelectAnswer(
+			common.JoinQASubKeys(qaKeyPrefix, common.ConfigRolloutStrategyKeySuffix),
+			fmt.Sprintf("Select the rollout strategy for the service %s :", serviceName),
+			[]string{"Argo Rollouts and Flagger require their controllers to be installed in the target cluster"},
+			rollingUpdateStrategy,
+			strategies,
+		)
+		switch strategy {
+		case argoBlueGreenStrategy, argoCanaryStrategy:
+			rolloutResources, err := getArgoRollout(service, strategy)
+			if err != nil {
+				logrus.Errorf("Failed to apply the rollout strategy %s to the service %s . Error: %q", strategy, serviceName, err)
+				continue
+			}
+			customResources = append(customResources, rolloutResources...)
+		case flaggerCanaryStrategy:
+			canaryResources, err := getFlaggerCanary(service)
+			if err != nil {
+				logrus.Errorf("Failed to apply the rollout strategy %s to the service %s . Error: %q", strategy, serviceName, err)
+				continue
+			}
+			customResources = append(customResources, canaryResources...)
+		default:
+			ir.Services[serviceName] = tuneRollingUpdate(service, qaKeyPrefix)
+		}
+	}
+	return ir, customResources
+}
+
+// isDeployment returns true if the service is converted to a deployment, instead of a daemonset, a job or a deployment config
+func isDeployment(service irtypes.Service, clusterConfig collecttypes.ClusterMetadata) bool {
+	if service.Daemon || service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
+		return false
+	}
+	if clusterConfig.Spec.GetSupportedVersions(common.DeploymentKind) != nil {
+		return true
+	}
+	for _, kind := range []string{"DeploymentConfig", "ReplicationController", "Pod"} {
+		if clusterConfig.Spec.GetSupportedVersions(kind) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// tuneRollingUpdate asks for the max surge and max unavailable of the rolling updates of the service
+func tuneRollingUpdate(service irtypes.Service, qaKeyPrefix string) irtypes.Service {
+	maxSurge := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(qaKeyPrefix, common.ConfigRolloutMaxSurgeKeySuffix),
+		fmt.Sprintf("Enter the max surge for the rolling update of the service %s :", service.Name),
+		[]string{"The number or percentage of pods that can be created above the desired replicas during an update"},
+		defaultMaxSurge,
+	)
+	maxUnavailable := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(qaKeyPrefix, common.ConfigRolloutMaxUnavailableKeySuffix),
+		fmt.Sprintf("Enter the max unavailable for the rolling update of the service %s :", service.Name),
+		[]string{"The number or percentage of pods that can be unavailable during an update"},
+		defaultMaxUnavailable,
+	)
+	if maxSurge == defaultMaxSurge && maxUnavailable == defaultMaxUnavailable {
+		return service
+	}
+	service.MaxSurge = maxSurge
+	service.MaxUnavailable = maxUnavailable
+	return service
+}
+
+// getArgoRollout returns an Argo Rollout that takes over the pods of the deployment and an analysis template stub.
+// Blue/green rollouts also get a preview service.
+func getArgoRollout(service irtypes.Service, strategy string) ([]customResourceT, error) {
+	analysisTemplateName := service.Name + analysisTemplateSuffix
+	analysis := map[string]interface{}{
+		"templates": []interface{}{map[string]interface{}{"templateName": analysisTemplateName}},
+		"args":      []interface{}{map[string]interface{}{"name": serviceNameArgumentName, "value": service.Name}},
+	}
+	customResources := []customResourceT{}
+	rolloutStrategy := map[string]interface{}{}
+	if strategy == argoBlueGreenStrategy {
+		ports := getServicePorts(service)
+		if len(ports) == 0 {
+			return nil, fmt.Errorf("blue/green rollouts require a service. Use a canary rollout instead")
+		}
+		customResources = append(customResources, customResourceT{
+			APIVersion: "v1",
+			Kind:       common.ServiceKind,
+			Metadata:   map[string]interface{}{"name": service.Name + previewServiceSuffix},
+			Spec: map[string]interface{}{
+				"selector": map[string]string{serviceLabel: service.Name},
+				"ports":    ports,
+			},
+		})
+		rolloutStrategy["blueGreen"] = map[string]interface{}{
+			"activeService":        service.Name,
+			"previewService":       service.Name + previewServiceSuffix,
+			"autoPromotionEnabled": false,
+			"prePromotionAnalysis": analysis,
+		}
+	} else {
+		rolloutStrategy["canary"] = map[string]interface{}{
+			"steps": []interface{}{
+				map[string]interface{}{"setWeight": 20},
+				map[string]interface{}{"pause": map[string]interface{}{"duration": "1m"}},
+				map[string]interface{}{"analysis": analysis},
+				map[string]interface{}{"setWeight": 50},
+				map[string]interface{}{"pause": map[string]interface{}{"duration": "1m"}},
+			},
+		}
+	}
+	rollout := customResourceT{
+		APIVersion: argoRolloutsAPIVersion,
+		Kind:       "Rollout",
+		Metadata:   map[string]interface{}{"name": service.Name},
+		Spec: map[string]interface{}{
+			"replicas": service.Replicas,
+			"selector": map[string]interface{}{"matchLabels": map[string]string{serviceLabel: service.Name}},
+			"workloadRef": map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       common.DeploymentKind,
+				"name":       service.Name,
+				"scaleDown":  "onsuccess",
+			},
+			"strategy": rolloutStrategy,
+		},
+	}
+	analysisTemplate := customResourceT{
+		APIVersion: argoRolloutsAPIVersion,
+		Kind:       "AnalysisTemplate",
+		Metadata:   map[string]interface{}{"name": analysisTemplateName},
+		Spec: map[string]interface{}{
+			"args": []interface{}{map[string]interface{}{"name": serviceNameArgumentName}},
+			"metrics": []interface{}{map[string]interface{}{
+				"name":             "success-rate",
+				"interval":         "1m",
+				"count":            3,
+				"failureLimit":     1,
+				"successCondition": "result[0] >= 0.95",
+				"provider": map[string]interface{}{
+					"prometheus": map[string]interface{}{
+						"address": defaultPrometheusURL,
+						"query":   `sum(rate(http_requests_total{service="{{args.service-name}}",code!~"5.."}[1m])) / sum(rate(http_requests_total{service="{{args.service-name}}"}[1m]))`,
+					},
+				},
+			}},
+		},
+	}
+	return append(customResources, rollout, analysisTemplate), nil
+}
+
+// getFlaggerCanary returns a Flagger canary targeting the deployment and a metric template stub
+func getFlaggerCanary(service irtypes.Service) ([]customResourceT, error) {
+	ports := getServicePorts(service)
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("flagger canaries require a service with at least one port")
+	}
+	metricTemplateName := service.Name + metricTemplateSuffix
+	canary := customResourceT{
+		APIVersion: flaggerAPIVersion,
+		Kind:       "Canary",
+		Metadata:   map[string]interface{}{"name": service.Name},
+		Spec: map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": common.DeploymentKind, "name": service.Name},
+			"service":   map[string]interface{}{"port": ports[0]["port"]},
+			"analysis": map[string]interface{}{
+				"interval":   "1m",
+				"threshold":  5,
+				"maxWeight":  50,
+				"stepWeight": 10,
+				"metrics": []interface{}{
+					map[string]interface{}{"name": "request-success-rate", "interval": "1m", "thresholdRange": map[string]interface{}{"min": 99}},
+					map[string]interface{}{"name": "latency", "interval": "1m", "templateRef": map[string]interface{}{"name": metricTemplateName}, "thresholdRange": map[string]interface{}{"max": 500}},
+				},
+			},
+		},
+	}
+	metricTemplate := customResourceT{
+		APIVersion: flaggerAPIVersion,
+		Kind:       "MetricTemplate",
+		Metadata:   map[string]interface{}{"name": metricTemplateName},
+		Spec: map[string]interface{}{
+			"provider": map[string]interface{}{"type": "prometheus", "address": defaultPrometheusURL},
+			"query":    `histogram_quantile(0.99, sum(rate(http_request_duration_seconds_bucket{namespace="{{ namespace }}",service="{{ target }}"}[{{ interval }}])) by (le)) * 1000`,
+		},
+	}
+	return []customResourceT{canary, metricTemplate}, nil
+}
+
+// getServicePorts returns the ports of the k8s service of the service, named the same way as the generated service
+func getServicePorts(service irtypes.Service) []map[string]interface{} {
+	ports := []map[string]interface{}{}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		if forwarding.ServiceType == "" {
+			continue
+		}
+		name := forwarding.ServicePort.Name
+		if name == "" {
+			name = fmt.Sprintf("port-%d", forwarding.ServicePort.Number)
+		}
+		var targetPort interface{} = forwarding.PodPort.Number
+		if forwarding.PodPort.Name != "" {
+			targetPort = forwarding.PodPort.Name
+		}
+		ports = append(ports, map[string]interface{}{"name": name, "port": forwarding.ServicePort.Number, "targetPort": targetPort})
+	}
+	return ports
+}
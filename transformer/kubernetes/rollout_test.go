@@ -0,0 +1,95 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestWireRolloutStrategies(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.services."tuned".maxsurge="1"`,
+		`move2kube.services."bluegreen".rolloutstrategy="Argo Rollouts blue/green"`,
+		`move2kube.services."canary".rolloutstrategy="Argo Rollouts canary"`,
+		`move2kube.services."flagger".rolloutstrategy="Flagger canary"`,
+		`move2kube.services."headless".rolloutstrategy="Argo Rollouts blue/green"`,
+	}, nil, nil, false, false)
+
+	ports := []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Name: "http", Number: 80}, PodPort: networking.ServiceBackendPort{Number: 8080}, ServiceType: core.ServiceTypeClusterIP},
+	}
+	ir := irtypes.NewIR()
+	for _, name := range []string{"default", "tuned", "bluegreen", "canary", "flagger", "headless"} {
+		service := irtypes.NewServiceWithName(name)
+		if name != "headless" {
+			service.ServiceToPodPortForwardings = ports
+		}
+		ir.Services[name] = service
+	}
+	daemon := irtypes.NewServiceWithName("daemon")
+	daemon.Daemon = true
+	ir.Services["daemon"] = daemon
+
+	ir, customResources := wireRolloutStrategies(ir, collecttypes.ClusterMetadata{})
+	if service := ir.Services["tuned"]; service.MaxSurge != "1" || service.MaxUnavailable != defaultMaxUnavailable {
+		t.Fatalf("expected the rolling update of the service tuned to be tuned. Actual: max surge %q max unavailable %q", service.MaxSurge, service.MaxUnavailable)
+	}
+	if service := ir.Services["default"]; service.MaxSurge != "" || service.MaxUnavailable != "" {
+		t.Fatalf("expected the default rolling update to be left to kubernetes. Actual: max surge %q max unavailable %q", service.MaxSurge, service.MaxUnavailable)
+	}
+	kinds := []string{}
+	byName := map[string]customResourceT{}
+	for _, customResource := range customResources {
+		name, _ := customResource.Metadata["name"].(string)
+		kinds = append(kinds, customResource.Kind+"/"+name)
+		byName[customResource.Kind+"/"+name] = customResource
+	}
+	expectedKinds := []string{
+		"Service/bluegreen-preview", "Rollout/bluegreen", "AnalysisTemplate/bluegreen-success-rate",
+		"Rollout/canary", "AnalysisTemplate/canary-success-rate",
+		"Canary/flagger", "MetricTemplate/flagger-latency",
+	}
+	if diff := cmp.Diff(expectedKinds, kinds); diff != "" {
+		t.Fatalf("the rollout resources are incorrect. Difference:\n%s", diff)
+	}
+	blueGreen := byName["Rollout/bluegreen"].Spec["strategy"].(map[string]interface{})["blueGreen"].(map[string]interface{})
+	if blueGreen["activeService"] != "bluegreen" || blueGreen["previewService"] != "bluegreen-preview" {
+		t.Fatalf("the blue/green rollout does not use the generated services. Actual: %+v", blueGreen)
+	}
+	workloadRef := byName["Rollout/canary"].Spec["workloadRef"].(map[string]interface{})
+	if workloadRef["kind"] != common.DeploymentKind || workloadRef["name"] != "canary" {
+		t.Fatalf("the canary rollout does not reference the deployment. Actual: %+v", workloadRef)
+	}
+	previewPorts := byName["Service/bluegreen-preview"].Spec["ports"].([]map[string]interface{})
+	expectedPorts := []map[string]interface{}{{"name": "http", "port": int32(80), "targetPort": int32(8080)}}
+	if diff := cmp.Diff(expectedPorts, previewPorts); diff != "" {
+		t.Fatalf("the ports of the preview service are incorrect. Difference:\n%s", diff)
+	}
+	if port := byName["Canary/flagger"].Spec["service"].(map[string]interface{})["port"]; port != int32(80) {
+		t.Fatalf("expected the flagger canary to use the port of the service. Actual: %v", port)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	defaultOpenFaaSOutputPath = common.DeployDir + string(os.PathSeparator) + "openfaas"
+	openFaaSStackFileName     = "stack.yml"
+	// noServerlessPlatform keeps the service as a Deployment
+	noServerlessPlatform  = "none"
+	knativeServerless     = "knative"
+	openFaaSServerless    = "openfaas"
+	knativeServiceKind    = "Service"
+	knativeServingVersion = "serving.knative.dev/v1"
+)
+
+var (
+	// serverlessRuntimeFiles are the files in the build context of the small handlers, for each runtime
+	serverlessRuntimeFiles = map[string][]string{
+		"node":         {"package.json"},
+		"python":       {"requirements.txt", "Pipfile", "pyproject.toml"},
+		"cloudfoundry": {"manifest.yml", "manifest.yaml"},
+	}
+)
+
+// serverlessCandidateT is a small handler that can be packaged for a function platform instead of a Deployment
+type serverlessCandidateT struct {
+	Runtime     string
+	ContextPath string
+}
+
+// openFaaSStackT is the stack.yml used by faas-cli to build and deploy the functions
+type openFaaSStackT struct {
+	Version   string                       `yaml:"version"`
+	Provider  map[string]string            `yaml:"provider"`
+	Functions map[string]openFaaSFunctionT `yaml:"functions"`
+}
+
+// openFaaSFunctionT is a function in the stack.yml
+type openFaaSFunctionT struct {
+	Lang        string            `yaml:"lang"`
+	Handler     string            `yaml:"handler"`
+	Image       string            `yaml:"image"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+}
+
+// getServerlessCandidates returns the stateless services with a single container and a single port that are built from a Node or Python app or a Cloud Foundry app.
+// It has to be called before the IR is preprocessed, since the preprocessing changes the names of the images.
+func getServerlessCandidates(ir irtypes.IR) map[string]serverlessCandidateT {
+	candidates := map[string]serverlessCandidateT{}
+	for serviceName, service := range ir.Services {
+		if len(service.Containers) != 1 || len(service.ServiceToPodPortForwardings) > 1 || len(service.Volumes) > 0 ||
+			service.Daemon || service.OnlyIngress || service.SessionAffinity || service.MaxReplicas > 0 || len(service.EventSources) > 0 {
+			continue
+		}
+		image, ok := ir.ContainerImages[service.Containers[0].Image]
+		if !ok || image.Build.ContextPath == "" {
+			continue
+		}
+		for _, runtime := range []string{"node", "python", "cloudfoundry"} {
+			if hasAnyFile(image.Build.ContextPath, serverlessRuntimeFiles[runtime]) {
+				candidates[serviceName] = serverlessCandidateT{Runtime: runtime, ContextPath: image.Build.ContextPath}
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+// wireServerless asks whether the small handlers should be packaged for a function platform instead of a Deployment.
+// The services that are packaged are removed from the IR. It returns the Knative Services and the OpenFaaS stack of the rest.
+func wireServerless(ir irtypes.IR, candidates map[string]serverlessCandidateT, sourceDir string) (irtypes.IR, []customResourceT, openFaaSStackT) {
+	knativeServices := []customResourceT{}
+	stack := openFaaSStackT{
+		Version:   "1.0",
+		Provider:  map[string]string{"name": "openfaas", "gateway": "http://127.0.0.1:8080"},
+		Functions: map[string]openFaaSFunctionT{},
+	}
+	serviceNames := []string{}
+	for serviceName := range candidates {
+		if _, ok := ir.Services[serviceName]; ok {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		candidate := candidates[serviceName]
+		platform := qaengine.FetchSelectAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigServerlessKeySegment),
+			fmt.Sprintf("The service %s is a small %s handler. Should it be packaged for a function platform instead of a Deployment?", serviceName, candidate.Runtime),
+			[]string{
+				knativeServerless + " : a Knative Service that is scaled to zero when it is idle",
+				openFaaSServerless + " : an OpenFaaS function in " + filepath.Join(defaultOpenFaaSOutputPath, openFaaSStackFileName) + ", deployed using faas-cli",
+			},
+			noServerlessPlatform,
+			[]string{noServerlessPlatform, knativeServerless, openFaaSServerless},
+		)
+		switch platform {
+		case knativeServerless:
+			knativeService, err := getKnativeService(service)
+			if err != nil {
+				logrus.Errorf("failed to create the Knative Service for the service %s . Error: %q", serviceName, err)
+				continue
+			}
+			knativeServices = append(knativeServices, knativeService)
+		case openFaaSServerless:
+			stack.Functions[serviceName] = getOpenFaaSFunction(service, candidate, sourceDir)
+		default:
+			continue
+		}
+		delete(ir.Services, serviceName)
+	}
+	return ir, knativeServices, stack
+}
+
+// getKnativeService returns a Knative Service that runs the pod of the service and is scaled to zero when it is idle
+func getKnativeService(service irtypes.Service) (customResourceT, error) {
+	podSpec := core.PodSpec(service.PodSpec)
+	podSpec.RestartPolicy = ""
+	podSpecBytes, err := json.Marshal(k8sschema.ConvertToV1PodSpec(&podSpec))
+	if err != nil {
+		return customResourceT{}, err
+	}
+	revisionSpec := map[string]interface{}{}
+	if err := json.Unmarshal(podSpecBytes, &revisionSpec); err != nil {
+		return customResourceT{}, err
+	}
+	return customResourceT{
+		APIVersion: knativeServingVersion,
+		Kind:       knativeServiceKind,
+		Metadata:   map[string]interface{}{"name": service.Name, "labels": map[string]string{serviceLabel: service.Name}},
+		Spec: map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels":      map[string]string{serviceLabel: service.Name},
+					"annotations": map[string]string{"autoscaling.knative.dev/min-scale": "0"},
+				},
+				"spec": revisionSpec,
+			},
+		},
+	}, nil
+}
+
+// getOpenFaaSFunction returns a function that is built from the Dockerfile in the build context of the service
+func getOpenFaaSFunction(service irtypes.Service, candidate serverlessCandidateT, sourceDir string) openFaaSFunctionT {
+	container := service.Containers[0]
+	handler := candidate.ContextPath
+	if relContextPath := getOutputRelContextPath(candidate.ContextPath, sourceDir); relContextPath != "" {
+		handler = filepath.Join("..", "..", relContextPath)
+	}
+	function := openFaaSFunctionT{
+		Lang:    "dockerfile",
+		Handler: filepath.ToSlash(handler),
+		Image:   container.Image,
+		Labels:  map[string]string{"com.openfaas.scale.zero": "true", serviceLabel: service.Name},
+	}
+	for _, env := range container.Env {
+		if env.ValueFrom != nil {
+			continue
+		}
+		if function.Environment == nil {
+			function.Environment = map[string]string{}
+		}
+		function.Environment[env.Name] = env.Value
+	}
+	return function
+}
+
+// persistOpenFaaSStack writes the stack.yml if there are any functions
+func persistOpenFaaSStack(stack openFaaSStackT, outputPath string) (bool, error) {
+	if len(stack.Functions) == 0 {
+		return false, nil
+	}
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return false, fmt.Errorf("failed to create the directory %s . Error: %q", outputPath, err)
+	}
+	stackPath := filepath.Join(outputPath, openFaaSStackFileName)
+	if err := common.WriteYaml(stackPath, stack); err != nil {
+		return false, fmt.Errorf("failed to write the OpenFaaS stack to %s . Error: %q", stackPath, err)
+	}
+	return true, nil
+}
+
+// getOutputRelContextPath returns the path of the build context relative to the output directory.
+// The contexts of the generated Dockerfiles are in the copy of the source directory that the Dockerfile transformers write to the output.
+func getOutputRelContextPath(contextPath, sourceDir string) string {
+	if common.IsParent(contextPath, sourceDir) {
+		if relContextPath, err := filepath.Rel(sourceDir, contextPath); err == nil {
+			return filepath.Join(common.DefaultSourceDir, relContextPath)
+		}
+	}
+	sourceDirSegment := string(os.PathSeparator) + common.DefaultSourceDir + string(os.PathSeparator)
+	if idx := strings.LastIndex(contextPath+string(os.PathSeparator), sourceDirSegment); idx >= 0 {
+		return filepath.Clean(contextPath[idx+1:])
+	}
+	return ""
+}
+
+func hasAnyFile(dir string, fileNames []string) bool {
+	for _, fileName := range fileNames {
+		if _, err := os.Stat(filepath.Join(dir, fileName)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	defaultStorageMigrationOutputPath = common.DeployDir + string(os.PathSeparator) + "storage-migration"
+	storageMigrationReportFileName    = "README.md"
+)
+
+// persistStorageMigration writes the data migration jobs and a report of the volumes to migrate.
+// It returns false if there is nothing to migrate.
+func persistStorageMigration(ir irtypes.IR, outputPath string, clusterConfig collecttypes.ClusterMetadata) (bool, error) {
+	claims := []irtypes.Storage{}
+	for _, storage := range ir.Storages {
+		if storage.StorageType == irtypes.PVCKind {
+			claims = append(claims, storage)
+		}
+	}
+	hostPathRows := []string{}
+	for _, service := range ir.Services {
+		for _, volume := range service.Volumes {
+			if volume.HostPath == nil {
+				continue
+			}
+			reason := irpreprocessor.GetUnsupportedHostPathReason(volume.HostPath.Path)
+			if reason == "" {
+				reason = "the host path was not migrated to a persistent volume claim and is not portable across nodes"
+			}
+			logrus.Warnf("The host path %s used by the service %s cannot be migrated : %s", volume.HostPath.Path, service.Name, reason)
+			hostPathRows = append(hostPathRows, fmt.Sprintf("| %s | %s | %s |", service.Name, volume.HostPath.Path, reason))
+		}
+	}
+	if len(claims) == 0 && len(hostPathRows) == 0 {
+		return false, nil
+	}
+	if _, err := apiresource.TransformIRAndPersist(irtypes.NewEnhancedIRFromIR(ir), outputPath, []apiresource.IAPIResource{new(apiresource.DataMigrationJob)}, clusterConfig); err != nil {
+		return false, fmt.Errorf("failed to create the data migration jobs. Error: %q", err)
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].Name < claims[j].Name })
+	sort.Strings(hostPathRows)
+	report := []string{"# Storage migration", ""}
+	if len(claims) > 0 {
+		report = append(report,
+			"## Persistent volume claims", "",
+			"Replace `SOURCE_HOST` in the data migration jobs with the host containing the data and apply them after the claims are created.", "",
+			"| Claim | Source | Access mode | Size | Data migration job |",
+			"| --- | --- | --- | --- | --- |",
+		)
+		for _, claim := range claims {
+			accessModes := []string{}
+			for _, accessMode := range claim.AccessModes {
+				accessModes = append(accessModes, string(accessMode))
+			}
+			size := ""
+			if quantity, ok := claim.Resources.Requests[core.ResourceStorage]; ok {
+				size = quantity.String()
+			}
+			source, job := "-", "-"
+			if claim.SourcePath != "" {
+				source, job = claim.SourcePath, apiresource.GetDataMigrationJobName(claim.Name)
+			}
+			report = append(report, fmt.Sprintf("| %s | %s | %s | %s | %s |", claim.Name, source, strings.Join(accessModes, ", "), size, job))
+		}
+		report = append(report, "")
+	}
+	if len(hostPathRows) > 0 {
+		report = append(report,
+			"## Unsupported host mounts", "",
+			"| Service | Host path | Reason |",
+			"| --- | --- | --- |",
+		)
+		report = append(report, hostPathRows...)
+		report = append(report, "")
+	}
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		return false, fmt.Errorf("failed to create the directory %s . Error: %q", outputPath, err)
+	}
+	reportPath := filepath.Join(outputPath, storageMigrationReportFileName)
+	if err := os.WriteFile(reportPath, []byte(strings.Join(report, "\n")), common.DefaultFilePermission); err != nil {
+		return false, fmt.Errorf("failed to write the storage migration report to %s . Error: %q", reportPath, err)
+	}
+	return true, nil
+}
@@ -118,6 +118,8 @@ func (t *Tekton) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 			continue
 		}
 		ir.Name = newArtifact.Name
+		// the images of the services are prefixed with the registry during preprocessing
+		waveImageNames := getWaveImageNames(ir)
 		preprocessedIR, err := irpreprocessor.Preprocess(ir)
 		if err != nil {
 			logrus.Errorf("Unable to prepreocess IR : %s", err)
@@ -138,7 +140,7 @@ func (t *Tekton) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 		deployCICDDir := t.TektonConfig.OutputPath
 		tempDest := filepath.Join(t.Env.TempPath, deployCICDDir)
 		logrus.Debugf("Generating Tekton pipeline for CI/CD")
-		enhancedIR := t.setupEnhancedIR(ir, t.Env.GetProjectName())
+		enhancedIR := t.setupEnhancedIR(ir, t.Env.GetProjectName(), waveImageNames)
 		files, err := apiresource.TransformIRAndPersist(enhancedIR, tempDest, resources, clusterConfig)
 		if err != nil {
 			logrus.Errorf("Unable to transform and persist IR : %s", err)
@@ -169,8 +171,25 @@ func (t *Tekton) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 	return pathMappings, createdArtifacts, nil
 }
 
+// getWaveImageNames returns the images built for the services of each migration wave
+func getWaveImageNames(ir irtypes.IR) map[string][]string {
+	waveImageNames := map[string][]string{}
+	for serviceName, service := range ir.Services {
+		wave := common.ServiceWaves[serviceName]
+		if wave == "" {
+			continue
+		}
+		for _, container := range service.Containers {
+			if image, ok := ir.ContainerImages[container.Image]; ok && image.Build.ContainerBuildType != "" && !common.IsPresent(waveImageNames[wave], container.Image) {
+				waveImageNames[wave] = append(waveImageNames[wave], container.Image)
+			}
+		}
+	}
+	return waveImageNames
+}
+
 // setupEnhancedIR returns EnhancedIR containing Tekton components
-func (t *Tekton) setupEnhancedIR(oldir irtypes.IR, name string) irtypes.EnhancedIR {
+func (t *Tekton) setupEnhancedIR(oldir irtypes.IR, name string, waveImageNames map[string][]string) irtypes.EnhancedIR {
 	ir := irtypes.NewEnhancedIRFromIR(oldir)
 
 	// Prefix the project name and make the name a valid k8s name.
@@ -214,6 +233,16 @@ func (t *Tekton) setupEnhancedIR(oldir irtypes.IR, name string) irtypes.Enhanced
 		Name:          pipelineName,
 		WorkspaceName: workspaceName,
 	}}
+	// Each migration wave gets a pipeline of its own that only builds the images of the services in the wave
+	for _, wave := range common.GetWaves() {
+		if imageNames := waveImageNames[wave]; len(imageNames) > 0 {
+			res.Pipelines = append(res.Pipelines, irtypes.Pipeline{
+				Name:          p(basePipelineName + "-" + wave),
+				WorkspaceName: workspaceName,
+				ImageNames:    imageNames,
+			})
+		}
+	}
 	ir.TektonResources = res
 	var port int32 = common.DefaultServicePort
 	ir.Services = map[string]irtypes.Service{gitEventIngressName: {
@@ -0,0 +1,398 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultQuotaCPU          = "4"
+	defaultQuotaMemory       = "8Gi"
+	defaultQuotaPods         = "20"
+	defaultLimitCPU          = "500m"
+	defaultLimitMemory       = "512Mi"
+	defaultRequestCPU        = "100m"
+	defaultRequestMemory     = "128Mi"
+	defaultIngressNamespace  = "ingress-nginx"
+	namespaceNameLabel       = "kubernetes.io/metadata.name"
+	ingressKind              = "Ingress"
+	tenancyNetworkPolicyKind = "NetworkPolicy"
+)
+
+var (
+	// clusterScopedKinds are the kinds of the generated objects that do not belong to a namespace
+	clusterScopedKinds = []string{"Namespace", "PersistentVolume", "StorageClass", "ClusterRole", "ClusterRoleBinding", "CustomResourceDefinition"}
+)
+
+// applyTenancy moves the objects of the services into the namespaces of their teams and writes the baseline objects of each namespace.
+// The namespaces are read from a mapping file of namespaces to services, and the remaining services are asked for.
+func applyTenancy(ir irtypes.IR, projectName, outputPath string) ([]string, error) {
+	files := []string{}
+	if len(ir.Services) == 0 || !qaengine.FetchBoolAnswer(
+		common.ConfigTenancyEnableKey,
+		"Organize the services into multiple namespaces?",
+		[]string{"Each namespace gets a resource quota, a limit range and a baseline network policy"},
+		false,
+	) {
+		return files, nil
+	}
+	mapping, err := getTenancyMapping()
+	if err != nil {
+		logrus.Errorf("Failed to read the namespace mapping file. Asking for the namespaces of the services. Error: %q", err)
+	}
+	defaultNamespace := common.MakeStringDNSLabelNameCompliant(projectName)
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	serviceNamespaces := map[string]string{}
+	for _, serviceName := range serviceNames {
+		namespace := defaultNamespace
+		if mappedNamespace, ok := mapping[serviceName]; ok {
+			namespace = mappedNamespace
+		}
+		namespace = qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigNamespaceKeySegment),
+			fmt.Sprintf("Enter the namespace for the service %s :", serviceName),
+			[]string{"Services of the same team or app tier can share a namespace"},
+			namespace,
+		)
+		serviceNamespaces[serviceName] = common.MakeStringDNSLabelNameCompliant(namespace)
+	}
+	namespaces, err := setNamespaces(ir, outputPath, serviceNamespaces, defaultNamespace)
+	if err != nil {
+		return files, err
+	}
+	baselineFiles, err := persistNamespaceBaselines(outputPath, namespaces)
+	files = append(files, baselineFiles...)
+	return files, err
+}
+
+// getTenancyMapping reads the mapping file of namespaces to services and returns the namespace of each service
+func getTenancyMapping() (map[string]string, error) {
+	serviceNamespaces := map[string]string{}
+	mappingFile := qaengine.FetchStringAnswer(
+		common.ConfigTenancyMappingFileKey,
+		"Enter the path of the file that maps the namespaces to the services :",
+		[]string{"A yaml file with the namespaces as keys and lists of service names as values. Leave it empty to enter the namespace of each service."},
+		"",
+	)
+	if mappingFile == "" {
+		return serviceNamespaces, nil
+	}
+	namespaces := map[string][]string{}
+	if err := common.ReadYaml(mappingFile, &namespaces); err != nil {
+		return serviceNamespaces, fmt.Errorf("failed to read the namespace mapping file at path %s . Error: %q", mappingFile, err)
+	}
+	for namespace, serviceNames := range namespaces {
+		for _, serviceName := range serviceNames {
+			if existingNamespace, ok := serviceNamespaces[serviceName]; ok && existingNamespace != namespace {
+				logrus.Warnf("The service %s is mapped to both the namespaces %s and %s . Using %s", serviceName, existingNamespace, namespace, existingNamespace)
+				continue
+			}
+			serviceNamespaces[serviceName] = namespace
+		}
+	}
+	return serviceNamespaces, nil
+}
+
+// setNamespaces sets the namespaces of the objects in the output directory and returns the namespaces used.
+// Objects that are not owned by a service go to the namespaces of all the services that use them, or the default namespace.
+// Ingresses are split, so that each namespace gets the rules of its own services.
+func setNamespaces(ir irtypes.IR, outputPath string, serviceNamespaces map[string]string, defaultNamespace string) (map[string]bool, error) {
+	usedNamespaces := map[string]bool{}
+	referencedBy := getReferencingNamespaces(ir, serviceNamespaces)
+	yamlPaths, err := common.GetFilesByExt(outputPath, []string{".yaml"})
+	if err != nil {
+		return usedNamespaces, fmt.Errorf("failed to list the yamls in the directory %s . Error: %q", outputPath, err)
+	}
+	for _, yamlPath := range yamlPaths {
+		obj := map[string]interface{}{}
+		if err := common.ReadYaml(yamlPath, &obj); err != nil {
+			logrus.Debugf("Skipping the file %s while setting the namespaces. Error: %q", yamlPath, err)
+			continue
+		}
+		metadata, ok := obj["metadata"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if namespace, ok := metadata["namespace"].(string); ok && namespace != "" {
+			continue
+		}
+		kind, _ := obj["kind"].(string)
+		name, _ := metadata["name"].(string)
+		if common.IsStringPresent(clusterScopedKinds, kind) {
+			continue
+		}
+		if kind == ingressKind {
+			namespaces, err := splitIngress(obj, yamlPath, serviceNamespaces)
+			if err != nil {
+				logrus.Errorf("Failed to split the ingress %s by namespace. Error: %q", name, err)
+			}
+			for _, namespace := range namespaces {
+				usedNamespaces[namespace] = true
+			}
+			continue
+		}
+		namespaces := []string{defaultNamespace}
+		labels, _ := metadata["labels"].(map[string]interface{})
+		if serviceName, ok := labels[serviceLabel].(string); ok && serviceNamespaces[serviceName] != "" {
+			namespaces = []string{serviceNamespaces[serviceName]}
+		} else if namespace, ok := serviceNamespaces[name]; ok {
+			namespaces = []string{namespace}
+		} else if referencingNamespaces, ok := referencedBy[kind+"/"+name]; ok {
+			namespaces = referencingNamespaces
+		}
+		if err := writeNamespacedCopies(obj, yamlPath, namespaces); err != nil {
+			logrus.Errorf("Failed to set the namespace of the %s %s . Error: %q", kind, name, err)
+			continue
+		}
+		for _, namespace := range namespaces {
+			usedNamespaces[namespace] = true
+		}
+	}
+	return usedNamespaces, nil
+}
+
+// getReferencingNamespaces returns the namespaces of the services that use each storage and network, keyed by kind and name
+func getReferencingNamespaces(ir irtypes.IR, serviceNamespaces map[string]string) map[string][]string {
+	referencedBy := map[string][]string{}
+	add := func(key, namespace string) {
+		if !common.IsStringPresent(referencedBy[key], namespace) {
+			referencedBy[key] = append(referencedBy[key], namespace)
+			sort.Strings(referencedBy[key])
+		}
+	}
+	for serviceName, service := range ir.Services {
+		namespace := serviceNamespaces[serviceName]
+		for _, volume := range service.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				add(string(irtypes.PVCKind)+"/"+volume.PersistentVolumeClaim.ClaimName, namespace)
+			}
+			if volume.ConfigMap != nil {
+				add(string(irtypes.ConfigMapKind)+"/"+volume.ConfigMap.Name, namespace)
+			}
+			if volume.Secret != nil {
+				add(string(irtypes.SecretKind)+"/"+volume.Secret.SecretName, namespace)
+			}
+		}
+		for _, network := range service.Networks {
+			add(tenancyNetworkPolicyKind+"/"+network, namespace)
+		}
+	}
+	return referencedBy
+}
+
+// writeNamespacedCopies writes a copy of the object in each of the namespaces, in place of the original file
+func writeNamespacedCopies(obj map[string]interface{}, yamlPath string, namespaces []string) error {
+	if len(namespaces) == 1 {
+		obj["metadata"].(map[string]interface{})["namespace"] = namespaces[0]
+		return common.WriteYaml(yamlPath, obj)
+	}
+	if err := os.Remove(yamlPath); err != nil {
+		return err
+	}
+	for _, namespace := range namespaces {
+		copiedObj := map[string]interface{}{}
+		for key, value := range obj {
+			copiedObj[key] = value
+		}
+		metadata := map[string]interface{}{}
+		for key, value := range obj["metadata"].(map[string]interface{}) {
+			metadata[key] = value
+		}
+		metadata["namespace"] = namespace
+		copiedObj["metadata"] = metadata
+		if err := common.WriteYaml(getNamespacedPath(yamlPath, namespace), copiedObj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitIngress writes a copy of the ingress in each namespace with only the paths whose backends are in that namespace
+func splitIngress(ingress map[string]interface{}, yamlPath string, serviceNamespaces map[string]string) ([]string, error) {
+	spec, _ := ingress["spec"].(map[string]interface{})
+	rules, _ := spec["rules"].([]interface{})
+	namespacedRules := map[string][]interface{}{}
+	for _, rule := range rules {
+		ruleMap, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		http, _ := ruleMap["http"].(map[string]interface{})
+		paths, _ := http["paths"].([]interface{})
+		namespacedPaths := map[string][]interface{}{}
+		for _, path := range paths {
+			namespace := getIngressPathNamespace(path, serviceNamespaces)
+			if namespace == "" {
+				logrus.Debugf("Unable to find the namespace of the backend of the ingress path %+v . Dropping it.", path)
+				continue
+			}
+			namespacedPaths[namespace] = append(namespacedPaths[namespace], path)
+		}
+		for namespace, paths := range namespacedPaths {
+			newRule := map[string]interface{}{}
+			for key, value := range ruleMap {
+				newRule[key] = value
+			}
+			newRule["http"] = map[string]interface{}{"paths": paths}
+			namespacedRules[namespace] = append(namespacedRules[namespace], newRule)
+		}
+	}
+	namespaces := []string{}
+	for namespace := range namespacedRules {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	if err := os.Remove(yamlPath); err != nil {
+		return nil, err
+	}
+	for _, namespace := range namespaces {
+		newSpec := map[string]interface{}{}
+		for key, value := range spec {
+			newSpec[key] = value
+		}
+		newSpec["rules"] = namespacedRules[namespace]
+		newIngress := map[string]interface{}{}
+		for key, value := range ingress {
+			newIngress[key] = value
+		}
+		newIngress["spec"] = newSpec
+		newIngressPath := yamlPath
+		if len(namespaces) > 1 {
+			newIngressPath = getNamespacedPath(yamlPath, namespace)
+		}
+		if err := writeNamespacedCopies(newIngress, newIngressPath, []string{namespace}); err != nil {
+			return namespaces, err
+		}
+	}
+	return namespaces, nil
+}
+
+// getIngressPathNamespace returns the namespace of the service that is the backend of the ingress path
+func getIngressPathNamespace(path interface{}, serviceNamespaces map[string]string) string {
+	pathMap, _ := path.(map[string]interface{})
+	backend, _ := pathMap["backend"].(map[string]interface{})
+	service, _ := backend["service"].(map[string]interface{})
+	serviceName, _ := service["name"].(string)
+	if serviceName == "" {
+		// networking.k8s.io/v1beta1 and extensions/v1beta1
+		serviceName, _ = backend["serviceName"].(string)
+	}
+	return serviceNamespaces[serviceName]
+}
+
+// getNamespacedPath returns the path of the copy of a yaml in a namespace
+func getNamespacedPath(yamlPath, namespace string) string {
+	return filepath.Join(filepath.Dir(yamlPath), namespace+"-"+filepath.Base(yamlPath))
+}
+
+// persistNamespaceBaselines writes the namespace, resource quota, limit range and baseline network policy of each namespace
+func persistNamespaceBaselines(outputPath string, namespaces map[string]bool) ([]string, error) {
+	files := []string{}
+	quotaCPU := qaengine.FetchStringAnswer(common.JoinQASubKeys(common.ConfigTenancyQuotaKey, "cpu"), "Enter the total CPU that the pods in each namespace can request :", []string{"Ex : " + defaultQuotaCPU}, defaultQuotaCPU)
+	quotaMemory := qaengine.FetchStringAnswer(common.JoinQASubKeys(common.ConfigTenancyQuotaKey, "memory"), "Enter the total memory that the pods in each namespace can request :", []string{"Ex : " + defaultQuotaMemory}, defaultQuotaMemory)
+	quotaPods := qaengine.FetchStringAnswer(common.JoinQASubKeys(common.ConfigTenancyQuotaKey, "pods"), "Enter the maximum number of pods in each namespace :", []string{"Ex : " + defaultQuotaPods}, defaultQuotaPods)
+	limitCPU := qaengine.FetchStringAnswer(common.JoinQASubKeys(common.ConfigTenancyLimitRangeKey, "cpu"), "Enter the default CPU limit of the containers :", []string{"Used for the containers that do not set a CPU limit"}, defaultLimitCPU)
+	limitMemory := qaengine.FetchStringAnswer(common.JoinQASubKeys(common.ConfigTenancyLimitRangeKey, "memory"), "Enter the default memory limit of the containers :", []string{"Used for the containers that do not set a memory limit"}, defaultLimitMemory)
+	requestCPU := qaengine.FetchStringAnswer(common.JoinQASubKeys(common.ConfigTenancyLimitRangeKey, "requestcpu"), "Enter the default CPU request of the containers :", []string{"Used for the containers that do not set a CPU request"}, defaultRequestCPU)
+	requestMemory := qaengine.FetchStringAnswer(common.JoinQASubKeys(common.ConfigTenancyLimitRangeKey, "requestmemory"), "Enter the default memory request of the containers :", []string{"Used for the containers that do not set a memory request"}, defaultRequestMemory)
+	ingressNamespace := ""
+	if qaengine.FetchBoolAnswer(
+		common.ConfigTenancyNetworkPolicyKey,
+		"Generate a baseline network policy for each namespace?",
+		[]string{"Only traffic from the same namespace and from the ingress controller is allowed into the pods of the namespace"},
+		true,
+	) {
+		ingressNamespace = qaengine.FetchStringAnswer(
+			common.ConfigTenancyIngressNamespaceKey,
+			"Enter the namespace of the ingress controller :",
+			[]string{"Traffic from this namespace is allowed by the baseline network policies"},
+			defaultIngressNamespace,
+		)
+	}
+	sortedNamespaces := []string{}
+	for namespace := range namespaces {
+		sortedNamespaces = append(sortedNamespaces, namespace)
+	}
+	sort.Strings(sortedNamespaces)
+	for _, namespace := range sortedNamespaces {
+		objs := []map[string]interface{}{
+			{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": namespace},
+			},
+			{
+				"apiVersion": "v1",
+				"kind":       "ResourceQuota",
+				"metadata":   map[string]interface{}{"name": namespace + "-quota", "namespace": namespace},
+				"spec": map[string]interface{}{"hard": map[string]interface{}{
+					"requests.cpu":    quotaCPU,
+					"requests.memory": quotaMemory,
+					"pods":            quotaPods,
+				}},
+			},
+			{
+				"apiVersion": "v1",
+				"kind":       "LimitRange",
+				"metadata":   map[string]interface{}{"name": namespace + "-limits", "namespace": namespace},
+				"spec": map[string]interface{}{"limits": []interface{}{map[string]interface{}{
+					"type":           "Container",
+					"default":        map[string]interface{}{"cpu": limitCPU, "memory": limitMemory},
+					"defaultRequest": map[string]interface{}{"cpu": requestCPU, "memory": requestMemory},
+				}}},
+			},
+		}
+		if ingressNamespace != "" {
+			objs = append(objs, map[string]interface{}{
+				"apiVersion": "networking.k8s.io/v1",
+				"kind":       tenancyNetworkPolicyKind,
+				"metadata":   map[string]interface{}{"name": namespace + "-baseline", "namespace": namespace},
+				"spec": map[string]interface{}{
+					"podSelector": map[string]interface{}{},
+					"policyTypes": []string{"Ingress"},
+					"ingress": []interface{}{map[string]interface{}{"from": []interface{}{
+						map[string]interface{}{"podSelector": map[string]interface{}{}},
+						map[string]interface{}{"namespaceSelector": map[string]interface{}{"matchLabels": map[string]interface{}{namespaceNameLabel: ingressNamespace}}},
+					}}},
+				},
+			})
+		}
+		for _, obj := range objs {
+			metadata := obj["metadata"].(map[string]interface{})
+			objPath := filepath.Join(outputPath, fmt.Sprintf("%s-%s.yaml", metadata["name"], strings.ToLower(obj["kind"].(string))))
+			if err := common.WriteYaml(objPath, obj); err != nil {
+				return files, fmt.Errorf("failed to write the %s of the namespace %s to %s . Error: %q", obj["kind"], namespace, objPath, err)
+			}
+			files = append(files, objPath)
+		}
+	}
+	return files, nil
+}
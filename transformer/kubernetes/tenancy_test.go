@@ -0,0 +1,151 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestApplyTenancy(t *testing.T) {
+	common.TempPath = t.TempDir()
+	mappingPath := filepath.Join(t.TempDir(), "namespaces.yaml")
+	if err := os.WriteFile(mappingPath, []byte("team-a:\n  - orders\n  - payments\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the namespace mapping file. Error: %q", err)
+	}
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.tenancy.enable=true`,
+		`move2kube.tenancy.mappingfile="` + mappingPath + `"`,
+		`move2kube.services."catalog".namespace="Team_B"`,
+		`move2kube.tenancy.quota.cpu="2"`,
+	}, nil, nil, false, false)
+
+	ir := irtypes.NewIR()
+	configVolume := core.Volume{Name: "config", VolumeSource: core.VolumeSource{ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: "shared-config"}}}}
+	for _, serviceName := range []string{"orders", "payments", "catalog"} {
+		service := irtypes.NewServiceWithName(serviceName)
+		if serviceName != "payments" {
+			service.Volumes = []core.Volume{configVolume}
+		}
+		ir.Services[serviceName] = service
+	}
+
+	outputPath := t.TempDir()
+	objs := map[string]map[string]interface{}{
+		"orders-deployment.yaml": {"kind": "Deployment", "metadata": map[string]interface{}{"name": "orders-v2", "labels": map[string]interface{}{serviceLabel: "orders"}}},
+		"catalog-service.yaml":   {"kind": "Service", "metadata": map[string]interface{}{"name": "catalog"}},
+		"shared-config.yaml":     {"kind": string(irtypes.ConfigMapKind), "metadata": map[string]interface{}{"name": "shared-config"}},
+		"unused-secret.yaml":     {"kind": string(irtypes.SecretKind), "metadata": map[string]interface{}{"name": "unused"}},
+		"monitoring.yaml":        {"kind": "Deployment", "metadata": map[string]interface{}{"name": "monitoring", "namespace": "observability"}},
+		"storage.yaml":           {"kind": "StorageClass", "metadata": map[string]interface{}{"name": "fast"}},
+		"ingress.yaml": {"kind": ingressKind, "metadata": map[string]interface{}{"name": "myproject"}, "spec": map[string]interface{}{"rules": []interface{}{
+			map[string]interface{}{"host": "shop.example.com", "http": map[string]interface{}{"paths": []interface{}{
+				map[string]interface{}{"path": "/orders", "backend": map[string]interface{}{"service": map[string]interface{}{"name": "orders"}}},
+				map[string]interface{}{"path": "/catalog", "backend": map[string]interface{}{"serviceName": "catalog"}},
+				map[string]interface{}{"path": "/other", "backend": map[string]interface{}{"serviceName": "other"}},
+			}}},
+		}}},
+	}
+	for fileName, obj := range objs {
+		if err := common.WriteYaml(filepath.Join(outputPath, fileName), obj); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", fileName, err)
+		}
+	}
+
+	files, err := applyTenancy(ir, "My Project", outputPath)
+	if err != nil {
+		t.Fatalf("failed to apply the tenancy. Error: %q", err)
+	}
+	// the namespaces, resource quotas, limit ranges and network policies of the 3 namespaces
+	if len(files) != 12 {
+		t.Fatalf("expected 4 baseline objects for each of the 3 namespaces. Actual: %+v", files)
+	}
+
+	expectedNamespaces := map[string]string{
+		"orders-deployment.yaml":              "team-a",
+		"catalog-service.yaml":                "team-b",
+		"team-a-shared-config.yaml":           "team-a",
+		"team-b-shared-config.yaml":           "team-b",
+		"unused-secret.yaml":                  "my-project",
+		"monitoring.yaml":                     "observability",
+		"storage.yaml":                        "",
+		"team-a-ingress.yaml":                 "team-a",
+		"team-b-ingress.yaml":                 "team-b",
+		"team-a-namespace.yaml":               "",
+		"my-project-quota-resourcequota.yaml": "my-project",
+		"team-b-baseline-networkpolicy.yaml":  "team-b",
+	}
+	for fileName, expectedNamespace := range expectedNamespaces {
+		obj := map[string]interface{}{}
+		if err := common.ReadYaml(filepath.Join(outputPath, fileName), &obj); err != nil {
+			t.Fatalf("failed to read the file %s . Error: %q", fileName, err)
+		}
+		namespace, _ := obj["metadata"].(map[string]interface{})["namespace"].(string)
+		if namespace != expectedNamespace {
+			t.Fatalf("wrong namespace for the file %s . Expected: %s Actual: %s", fileName, expectedNamespace, namespace)
+		}
+	}
+	for _, fileName := range []string{"shared-config.yaml", "ingress.yaml"} {
+		if _, err := os.Stat(filepath.Join(outputPath, fileName)); err == nil {
+			t.Fatalf("expected the file %s to be replaced by the namespaced copies", fileName)
+		}
+	}
+
+	ingress := map[string]interface{}{}
+	if err := common.ReadYaml(filepath.Join(outputPath, "team-a-ingress.yaml"), &ingress); err != nil {
+		t.Fatalf("failed to read the ingress. Error: %q", err)
+	}
+	expectedRules := []interface{}{map[string]interface{}{"host": "shop.example.com", "http": map[string]interface{}{"paths": []interface{}{
+		map[string]interface{}{"path": "/orders", "backend": map[string]interface{}{"service": map[string]interface{}{"name": "orders"}}},
+	}}}}
+	if diff := cmp.Diff(expectedRules, ingress["spec"].(map[string]interface{})["rules"]); diff != "" {
+		t.Fatalf("wrong rules in the ingress of the namespace team-a. Difference:\n%s", diff)
+	}
+
+	quota := map[string]interface{}{}
+	if err := common.ReadYaml(filepath.Join(outputPath, "team-a-quota-resourcequota.yaml"), &quota); err != nil {
+		t.Fatalf("failed to read the resource quota. Error: %q", err)
+	}
+	expectedHard := map[string]interface{}{"requests.cpu": "2", "requests.memory": defaultQuotaMemory, "pods": defaultQuotaPods}
+	if diff := cmp.Diff(expectedHard, quota["spec"].(map[string]interface{})["hard"]); diff != "" {
+		t.Fatalf("wrong resource quota. Difference:\n%s", diff)
+	}
+}
+
+func TestApplyTenancyDisabled(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", nil, nil, nil, false, false)
+	ir := irtypes.NewIR()
+	ir.Services["orders"] = irtypes.NewServiceWithName("orders")
+	outputPath := t.TempDir()
+	files, err := applyTenancy(ir, "myproject", outputPath)
+	if err != nil {
+		t.Fatalf("failed to apply the tenancy. Error: %q", err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected no files when the tenancy is not enabled. Actual: %+v", files)
+	}
+}
@@ -0,0 +1,428 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	timeZoneEnvName = "TZ"
+	langEnvName     = "LANG"
+	lcAllEnvName    = "LC_ALL"
+	// defaultLocale is available in most base images without installing any locale packages
+	defaultLocale   = "C.UTF-8"
+	defaultTimeZone = "UTC"
+
+	timeZoneAssumption = "timezone"
+	localeAssumption   = "locale"
+)
+
+// localeRule is a pattern in the sources that shows that a service depends on the timezone or the locale of the machine it runs on
+type localeRule struct {
+	kind        string
+	regex       string
+	description string
+	compiled    *regexp.Regexp
+}
+
+// localeFinding is the first match of a rule in a file of a service
+type localeFinding struct {
+	rule       *localeRule
+	relPath    string
+	lineNumber int
+}
+
+var (
+	// localeRules are the uses of the local time and the default locale, which change when moving from a server to a container that runs in UTC with the POSIX locale
+	localeRules = []localeRule{
+		{kind: timeZoneAssumption, regex: `TimeZone\.getDefault\(|ZoneId\.systemDefault\(|LocalDate(?:Time)?\.now\(\s*\)|Calendar\.getInstance\(\s*\)|new\s+Date\(\s*\)\.to(?:Locale)?String\(`, description: "uses the default timezone of the JVM"},
+		{kind: timeZoneAssumption, regex: `datetime\.(?:datetime\.)?now\(\s*\)|date\.today\(\s*\)|time\.localtime\(|time\.strftime\(|time\.mktime\(`, description: "uses the local time of the python process"},
+		{kind: timeZoneAssumption, regex: `getTimezoneOffset\(|\.getHours\(\s*\)|\.toLocaleDateString\(|\.toLocaleTimeString\(|moment\(\s*\)\.format\(`, description: "uses the local time of the node process"},
+		{kind: timeZoneAssumption, regex: `Time\.now\b|Date\.today\b|DateTime\.now\b`, description: "uses the local time of the ruby process"},
+		{kind: timeZoneAssumption, regex: `time\.Local\b|\.Local\(\s*\)`, description: "uses the local time of the go process"},
+		{kind: timeZoneAssumption, regex: `DateTime\.Now\b|TimeZoneInfo\.Local\b`, description: "uses the local time of the .NET process"},
+		{kind: timeZoneAssumption, regex: `date_default_timezone_get\(|\bdate\(\s*['"]`, description: "uses the default timezone of php"},
+		{kind: localeAssumption, regex: `Locale\.getDefault\(|Charset\.defaultCharset\(|new\s+(?:InputStreamReader|OutputStreamWriter|FileReader|FileWriter)\([^,()]*\)`, description: "uses the default locale or charset of the JVM"},
+		{kind: localeAssumption, regex: `locale\.setlocale\(|locale\.getpreferredencoding\(|locale\.getlocale\(|\.toLocaleString\(`, description: "uses the locale of the process"},
+		{kind: localeAssumption, regex: `CultureInfo\.CurrentCulture\b|setlocale\(`, description: "uses the locale of the process"},
+	}
+	// explicitTimeZoneRegex matches a timezone set in a Dockerfile, a properties file or the JVM options
+	explicitTimeZoneRegex = regexp.MustCompile(`(?:-Duser\.timezone=|\bENV\s+TZ[=\s]\s*|^\s*TZ\s*[=:]\s*)["']?([A-Za-z][A-Za-z0-9_+-]*(?:/[A-Za-z0-9_+-]+)*)`)
+	// explicitLocaleRegex matches a locale or an encoding set in a Dockerfile, a properties file or the JVM options
+	explicitLocaleRegex = regexp.MustCompile(`-Dfile\.encoding=|\bENV\s+(?:LANG|LC_ALL)[=\s]|^\s*(?:LANG|LC_ALL)\s*[=:]`)
+	lastFromRegex       = regexp.MustCompile(`(?im)^\s*FROM\s+.*$`)
+)
+
+func init() {
+	for i := range localeRules {
+		localeRules[i].compiled = regexp.MustCompile(localeRules[i].regex)
+	}
+}
+
+// LocaleAnalyser implements Transformer interface
+type LocaleAnalyser struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// Init Initializes the transformer
+func (t *LocaleAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *LocaleAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *LocaleAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform finds the services that depend on the local time or the default locale of the machine they ran on,
+// and sets TZ and LANG in their containers and in the generated Dockerfiles, since the containers run in UTC with the POSIX locale otherwise.
+func (t *LocaleAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		serviceNames := []string{}
+		for sn := range ir.Services {
+			serviceNames = append(serviceNames, sn)
+		}
+		sort.Strings(serviceNames)
+		findings := map[string][]localeFinding{}
+		explicitTimeZones := map[string]string{}
+		explicitLocales := map[string]bool{}
+		defaultTZ := ""
+		for _, sn := range serviceNames {
+			serviceFindings, explicitTimeZone, explicitLocale := getLocaleFindings(ir, ir.Services[sn])
+			if explicitTimeZone != "" {
+				explicitTimeZones[sn] = explicitTimeZone
+				if defaultTZ == "" {
+					defaultTZ = explicitTimeZone
+				}
+			}
+			explicitLocales[sn] = explicitLocale
+			if len(serviceFindings) > 0 {
+				findings[sn] = serviceFindings
+			}
+		}
+		if len(findings) == 0 {
+			artifactsCreated = append(artifactsCreated, a)
+			continue
+		}
+		if defaultTZ == "" {
+			defaultTZ = getHostTimeZone()
+		}
+		affectedServices := []string{}
+		for _, sn := range serviceNames {
+			if _, ok := findings[sn]; ok {
+				affectedServices = append(affectedServices, sn)
+			}
+		}
+		projectTZ := askTimeZone(
+			common.ConfigTimeZoneKey,
+			"Enter the default timezone of the services that depend on the local time :",
+			[]string{"The containers run in UTC unless TZ is set", "Services that depend on the local time : " + strings.Join(affectedServices, ", ")},
+			defaultTZ,
+		)
+		for _, sn := range affectedServices {
+			service := ir.Services[sn]
+			qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`)
+			env := map[string]string{}
+			if hints := getLocaleFindingHints(findings[sn], timeZoneAssumption); len(hints) > 0 {
+				serviceTZ := projectTZ
+				if explicitTimeZone, ok := explicitTimeZones[sn]; ok {
+					serviceTZ = explicitTimeZone
+					hints = append(hints, "The timezone "+explicitTimeZone+" is set in the sources")
+				}
+				env[timeZoneEnvName] = askTimeZone(
+					common.JoinQASubKeys(qaKeyPrefix, common.ConfigTimeZoneKeySegment),
+					fmt.Sprintf("Enter the timezone of the service %s :", sn),
+					hints,
+					serviceTZ,
+				)
+			}
+			if hints := getLocaleFindingHints(findings[sn], localeAssumption); len(hints) > 0 && !explicitLocales[sn] {
+				env[langEnvName] = qaengine.FetchStringAnswer(
+					common.JoinQASubKeys(qaKeyPrefix, common.ConfigLocaleKeySegment),
+					fmt.Sprintf("Enter the locale of the service %s :", sn),
+					append(hints, "Locales other than "+defaultLocale+" and POSIX have to be installed in the image, like the glibc-langpack-* and locales packages"),
+					defaultLocale,
+				)
+			}
+			if len(env) == 0 {
+				continue
+			}
+			for i, container := range service.Containers {
+				service.Containers[i] = setLocaleEnv(container, env)
+				pm, ok := t.getDockerfilePathMapping(ir, container.Image, env)
+				if ok {
+					pathMappings = append(pathMappings, pm)
+				}
+			}
+			ir.Services[sn] = service
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return pathMappings, artifactsCreated, nil
+}
+
+// getLocaleFindings scans the build contexts of the images of the service for the uses of the local time and the default locale.
+// It also returns the timezone and whether a locale is already set for the service.
+func getLocaleFindings(ir irtypes.IR, service irtypes.Service) (findings []localeFinding, explicitTimeZone string, explicitLocale bool) {
+	for _, container := range service.Containers {
+		for _, env := range container.Env {
+			switch env.Name {
+			case timeZoneEnvName:
+				explicitTimeZone = env.Value
+			case langEnvName, lcAllEnvName:
+				explicitLocale = true
+			default:
+				if matches := explicitTimeZoneRegex.FindStringSubmatch(env.Value); matches != nil {
+					explicitTimeZone = matches[1]
+				}
+				explicitLocale = explicitLocale || explicitLocaleRegex.MatchString(env.Value)
+			}
+		}
+	}
+	seenContexts := map[string]bool{}
+	for _, container := range service.Containers {
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok {
+			continue
+		}
+		for _, dockerfilePath := range image.Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue] {
+			contents, err := os.ReadFile(dockerfilePath)
+			if err != nil {
+				continue
+			}
+			for _, line := range strings.Split(string(contents), "\n") {
+				if matches := explicitTimeZoneRegex.FindStringSubmatch(line); matches != nil && explicitTimeZone == "" {
+					explicitTimeZone = matches[1]
+				}
+				explicitLocale = explicitLocale || explicitLocaleRegex.MatchString(line)
+			}
+		}
+		if image.Build.ContextPath == "" || seenContexts[image.Build.ContextPath] {
+			continue
+		}
+		contextPath := image.Build.ContextPath
+		seenContexts[contextPath] = true
+		matched := map[*localeRule]bool{}
+		err := filepath.Walk(contextPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if path != contextPath && (strings.HasPrefix(info.Name(), ".") || common.IsStringPresent(localStateSkipDirs, info.Name())) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.Size() > maxLocalStateScanFileSize || !common.IsStringPresent(localStateScanExts, filepath.Ext(path)) {
+				return nil
+			}
+			relPath, err := filepath.Rel(contextPath, path)
+			if err != nil {
+				relPath = path
+			}
+			fileFindings, fileTimeZone, fileLocale := scanFileForLocale(path, relPath, matched)
+			findings = append(findings, fileFindings...)
+			if explicitTimeZone == "" {
+				explicitTimeZone = fileTimeZone
+			}
+			explicitLocale = explicitLocale || fileLocale
+			return nil
+		})
+		if err != nil {
+			logrus.Errorf("failed to walk the build context %s for timezone and locale assumptions. Error: %q", contextPath, err)
+		}
+	}
+	return findings, explicitTimeZone, explicitLocale
+}
+
+// scanFileForLocale returns the first match of each rule that has not matched yet in the service, along with the timezone and the locale set in the file
+func scanFileForLocale(path, relPath string, matched map[*localeRule]bool) (findings []localeFinding, explicitTimeZone string, explicitLocale bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		logrus.Debugf("failed to open the file at path %s . Error: %q", path, err)
+		return nil, "", false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLocalStateScanFileSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if matches := explicitTimeZoneRegex.FindStringSubmatch(line); matches != nil && explicitTimeZone == "" {
+			explicitTimeZone = matches[1]
+		}
+		explicitLocale = explicitLocale || explicitLocaleRegex.MatchString(line)
+		for i := range localeRules {
+			rule := &localeRules[i]
+			if !matched[rule] && rule.compiled.MatchString(line) {
+				matched[rule] = true
+				findings = append(findings, localeFinding{rule: rule, relPath: relPath, lineNumber: lineNumber})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logrus.Debugf("failed to scan the file at path %s . Error: %q", path, err)
+	}
+	if _, err := time.LoadLocation(explicitTimeZone); explicitTimeZone != "" && err != nil {
+		explicitTimeZone = ""
+	}
+	return findings, explicitTimeZone, explicitLocale
+}
+
+func getLocaleFindingHints(findings []localeFinding, kind string) []string {
+	hints := []string{}
+	for _, finding := range findings {
+		if finding.rule.kind == kind {
+			hints = append(hints, fmt.Sprintf("%s:%d %s", finding.relPath, finding.lineNumber, finding.rule.description))
+		}
+	}
+	return hints
+}
+
+// askTimeZone asks for a timezone until it is in the IANA timezone database
+func askTimeZone(key, desc string, hints []string, def string) string {
+	answer := qaengine.FetchStringAnswer(key, desc, hints, def)
+	if _, err := time.LoadLocation(answer); err != nil || answer == "" {
+		logrus.Warnf("the timezone %s is not in the IANA timezone database. Using %s instead. Error: %q", answer, def, err)
+		return def
+	}
+	return answer
+}
+
+// getHostTimeZone returns the timezone of the machine move2kube runs on, which is usually the machine the sources were running on
+func getHostTimeZone() string {
+	if tz := os.Getenv(timeZoneEnvName); tz != "" {
+		if _, err := time.LoadLocation(strings.TrimPrefix(tz, ":")); err == nil {
+			return strings.TrimPrefix(tz, ":")
+		}
+	}
+	if contents, err := os.ReadFile("/etc/timezone"); err == nil {
+		if tz := strings.TrimSpace(string(contents)); tz != "" {
+			return tz
+		}
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if idx := strings.Index(target, "zoneinfo/"); idx >= 0 {
+			return target[idx+len("zoneinfo/"):]
+		}
+	}
+	return defaultTimeZone
+}
+
+// setLocaleEnv sets the environment variables in the container, unless they are already set
+func setLocaleEnv(container core.Container, env map[string]string) core.Container {
+	names := []string{}
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		found := false
+		for _, containerEnv := range container.Env {
+			if containerEnv.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			container.Env = append(container.Env, core.EnvVar{Name: name, Value: env[name]})
+		}
+	}
+	return container
+}
+
+// getDockerfilePathMapping returns a path mapping that overwrites the Dockerfile generated for the image with one that sets the environment variables.
+// The Dockerfiles in the sources are not changed, since the containers get the environment variables anyway.
+func (t *LocaleAnalyser) getDockerfilePathMapping(ir irtypes.IR, imageName string, env map[string]string) (transformertypes.PathMapping, bool) {
+	image, ok := ir.ContainerImages[imageName]
+	if !ok || len(image.Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue]) == 0 {
+		return transformertypes.PathMapping{}, false
+	}
+	dockerfilePath := image.Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue][0]
+	sourceDirSegment := string(os.PathSeparator) + common.DefaultSourceDir + string(os.PathSeparator)
+	idx := strings.LastIndex(dockerfilePath, sourceDirSegment)
+	if common.IsParent(dockerfilePath, t.Env.GetEnvironmentSource()) || idx < 0 {
+		return transformertypes.PathMapping{}, false
+	}
+	contents, err := os.ReadFile(dockerfilePath)
+	if err != nil {
+		logrus.Errorf("failed to read the Dockerfile at path %s . Error: %q", dockerfilePath, err)
+		return transformertypes.PathMapping{}, false
+	}
+	fromLocs := lastFromRegex.FindAllIndex(contents, -1)
+	if len(fromLocs) == 0 {
+		return transformertypes.PathMapping{}, false
+	}
+	names := []string{}
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	envLine := "ENV"
+	for _, name := range names {
+		envLine += fmt.Sprintf(" %s=%q", name, env[name])
+	}
+	insertAt := fromLocs[len(fromLocs)-1][1]
+	newContents := string(contents[:insertAt]) + "\n" + envLine + string(contents[insertAt:])
+	tempPath := filepath.Join(t.Env.TempPath, "locale-"+common.GetRandomString(), filepath.Base(dockerfilePath))
+	if err := os.MkdirAll(filepath.Dir(tempPath), common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("failed to create the directory for the Dockerfile at path %s . Error: %q", tempPath, err)
+		return transformertypes.PathMapping{}, false
+	}
+	if err := os.WriteFile(tempPath, []byte(newContents), common.DefaultFilePermission); err != nil {
+		logrus.Errorf("failed to write the Dockerfile at path %s . Error: %q", tempPath, err)
+		return transformertypes.PathMapping{}, false
+	}
+	return transformertypes.PathMapping{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  tempPath,
+		DestPath: dockerfilePath[idx+1:],
+	}, true
+}
@@ -0,0 +1,482 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMainframeReportDir = "assessment"
+	mainframeReportFileName   = "mainframe.md"
+	// smallMainframeAppLines is the size below which a batch application is cheaper to rewrite than to rehost
+	smallMainframeAppLines = 2000
+	// cicsCopybookPrefix is the prefix of the copybooks supplied by CICS
+	cicsCopybookPrefix = "DFH"
+)
+
+const (
+	cobolProgramAsset   = "program"
+	cobolCopybookAsset  = "copybook"
+	jclAsset            = "jcl"
+	cicsDefinitionAsset = "cicsdefinition"
+	bmsMapAsset         = "bmsmap"
+	assemblerAsset      = "assembler"
+)
+
+var (
+	mainframeAssetExts = map[string]string{
+		".cbl":   cobolProgramAsset,
+		".cob":   cobolProgramAsset,
+		".cobol": cobolProgramAsset,
+		".ccp":   cobolProgramAsset,
+		".cpy":   cobolCopybookAsset,
+		".copy":  cobolCopybookAsset,
+		".jcl":   jclAsset,
+		".proc":  jclAsset,
+		".prc":   jclAsset,
+		".csd":   cicsDefinitionAsset,
+		".bms":   bmsMapAsset,
+		".hlasm": assemblerAsset,
+		".mlc":   assemblerAsset,
+	}
+	// mainframeAssetDirs are the conventional names of the directories of mainframe assets, which belong to the application in the parent directory
+	mainframeAssetDirs = []string{"cobol", "cbl", "cob", "copybook", "copybooks", "cpy", "copy", "copylib", "jcl", "proc", "procs", "proclib", "cics", "csd", "bms", "maps", "asm"}
+
+	cobolCopyRegex       = regexp.MustCompile(`(?i)\bCOPY\s+['"]?([A-Z0-9#@$-]+)`)
+	cobolCallRegex       = regexp.MustCompile(`(?i)\bCALL\s+['"]([A-Z0-9#@$-]+)['"]`)
+	cobolExecRegex       = regexp.MustCompile(`(?i)\bEXEC\s+(CICS|SQL|DLI)\b`)
+	jclProgramRegex      = regexp.MustCompile(`(?i)\bEXEC\s+PGM=([A-Z0-9#@$]+)`)
+	jclDatasetRegex      = regexp.MustCompile(`(?i)\bDSN(?:AME)?=([A-Z0-9#@$.&]+)`)
+	jclVSAMRegex         = regexp.MustCompile(`(?i)\bPGM=IDCAMS\b|\bAMP=|\bRECORG=|\bVSAM\b`)
+	cicsTransactionRegex = regexp.MustCompile(`(?i)\bDEFINE\s+TRANS(?:ACTION)?\s*\(\s*([A-Z0-9#@$]+)\s*\)`)
+	imsCalls             = []string{"CBLTDLI", "AIBTDLI"}
+)
+
+// MainframeAnalyser implements Transformer interface
+type MainframeAnalyser struct {
+	Config          transformertypes.Transformer
+	Env             *environment.Environment
+	MainframeConfig *MainframeYamlConfig
+}
+
+// MainframeYamlConfig stores the yaml configuration for the mainframe transformer
+type MainframeYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+}
+
+// Init Initializes the transformer
+func (t *MainframeAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.MainframeConfig = &MainframeYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.MainframeConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.MainframeConfig, err)
+		return err
+	}
+	if t.MainframeConfig.OutputPath == "" {
+		t.MainframeConfig.OutputPath = defaultMainframeReportDir
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *MainframeAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect detects the COBOL programs, copybooks, JCL and CICS definitions in the directory and in its subdirectories with conventional names like cobol and jcl.
+// If the directory has other subdirectories, only the conventional subdirectories are claimed, so the other subdirectories are still detected by the other transformers.
+func (t *MainframeAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the directory %s . Error: %q", dir, err)
+	}
+	assets := map[string]string{}
+	assetDirs := []string{}
+	hasOtherDirs := false
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			if kind := getMainframeAssetKind(path); kind != "" {
+				assets[path] = kind
+			}
+			continue
+		}
+		if !common.IsStringPresent(mainframeAssetDirs, strings.ToLower(entry.Name())) {
+			if !isIgnoredDir(entry.Name()) {
+				hasOtherDirs = true
+			}
+			continue
+		}
+		found := false
+		if err := filepath.WalkDir(path, func(subPath string, subEntry os.DirEntry, err error) error {
+			if err != nil || subEntry.IsDir() {
+				return nil
+			}
+			if kind := getMainframeAssetKind(subPath); kind != "" {
+				assets[subPath] = kind
+				found = true
+			}
+			return nil
+		}); err != nil {
+			logrus.Debugf("failed to walk the directory %s . Error: %q", path, err)
+		}
+		if found {
+			assetDirs = append(assetDirs, path)
+		}
+	}
+	if !hasMainframeProgramsOrJobs(assets) {
+		return nil, nil
+	}
+	if !hasOtherDirs {
+		assetDirs = []string{dir}
+	}
+	sourcePaths := []string{}
+	for path := range assets {
+		sourcePaths = append(sourcePaths, path)
+	}
+	sort.Strings(sourcePaths)
+	assessment := assessMainframeAssets(dir, assets)
+	logrus.Infof("Found mainframe assets in the directory %s . Suggested strategy: %s", dir, assessment.Strategy)
+	serviceName := common.NormalizeForMetadataName(filepath.Base(dir))
+	services = map[string][]transformertypes.Artifact{
+		serviceName: {{
+			Name: serviceName,
+			Type: artifacts.MainframeAssessmentArtifactType,
+			Paths: map[transformertypes.PathType][]string{
+				artifacts.ServiceDirPathType:      assetDirs,
+				artifacts.MainframeSourcePathType: sourcePaths,
+			},
+			Configs: map[transformertypes.ConfigType]interface{}{
+				artifacts.MainframeAssessmentConfigType: assessment,
+			},
+		}},
+	}
+	return services, nil
+}
+
+// Transform writes the assessments of the mainframe applications to a report
+func (t *MainframeAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	sections := map[string]string{}
+	for _, a := range newArtifacts {
+		assessment := artifacts.MainframeAssessment{}
+		if err := a.GetConfig(artifacts.MainframeAssessmentConfigType, &assessment); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", assessment, err)
+			continue
+		}
+		sections[a.Name] = getMainframeReportSection(a.Name, assessment)
+	}
+	if len(sections) == 0 {
+		return nil, nil, nil
+	}
+	names := []string{}
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	report := strings.Builder{}
+	report.WriteString("# Mainframe assessment\n\n")
+	report.WriteString("Move2Kube does not containerize mainframe applications. This report lists the mainframe assets that were found and suggests whether each application should be rehosted on a mainframe emulator in containers or rewritten.\n")
+	for _, name := range names {
+		report.WriteString("\n" + sections[name])
+	}
+	tempDest := filepath.Join(t.Env.TempPath, t.MainframeConfig.OutputPath)
+	if err := os.MkdirAll(tempDest, common.DefaultDirectoryPermission); err != nil {
+		return nil, nil, fmt.Errorf("failed to create the directory %s . Error: %q", tempDest, err)
+	}
+	reportPath := filepath.Join(tempDest, mainframeReportFileName)
+	if err := os.WriteFile(reportPath, []byte(report.String()), common.DefaultFilePermission); err != nil {
+		return nil, nil, fmt.Errorf("failed to write the mainframe assessment to the file at path %s . Error: %q", reportPath, err)
+	}
+	return []transformertypes.PathMapping{{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  reportPath,
+		DestPath: filepath.Join(t.MainframeConfig.OutputPath, mainframeReportFileName),
+	}}, nil, nil
+}
+
+// getMainframeAssetKind returns the kind of the mainframe asset using the extension of the file (mainframe files are often in upper case)
+func getMainframeAssetKind(path string) string {
+	return mainframeAssetExts[strings.ToLower(filepath.Ext(path))]
+}
+
+func isIgnoredDir(name string) bool {
+	for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
+		if dirRegExp.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMainframeProgramsOrJobs checks that the assets are an application and not just a few stray copybooks
+func hasMainframeProgramsOrJobs(assets map[string]string) bool {
+	for _, kind := range assets {
+		if kind == cobolProgramAsset || kind == jclAsset {
+			return true
+		}
+	}
+	return false
+}
+
+// assessMainframeAssets parses the assets and suggests a migration strategy.
+// The paths in the assessment are relative to the directory.
+func assessMainframeAssets(dir string, assets map[string]string) artifacts.MainframeAssessment {
+	assessment := artifacts.MainframeAssessment{}
+	paths := []string{}
+	for path := range assets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	transactions := map[string]bool{}
+	for _, path := range paths {
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			relPath = path
+		}
+		switch assets[path] {
+		case cobolProgramAsset:
+			program, err := parseCOBOLProgram(path)
+			if err != nil {
+				logrus.Warnf("failed to parse the COBOL program at path %s . Error: %q", path, err)
+			}
+			program.Path = relPath
+			assessment.Programs = append(assessment.Programs, program)
+		case cobolCopybookAsset:
+			assessment.Copybooks = append(assessment.Copybooks, relPath)
+		case jclAsset:
+			job, err := parseJCL(path)
+			if err != nil {
+				logrus.Warnf("failed to parse the JCL at path %s . Error: %q", path, err)
+			}
+			job.Path = relPath
+			assessment.Jobs = append(assessment.Jobs, job)
+		case cicsDefinitionAsset:
+			assessment.CICSDefinitions = append(assessment.CICSDefinitions, relPath)
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				logrus.Warnf("failed to read the CICS definitions at path %s . Error: %q", path, err)
+				continue
+			}
+			for _, match := range cicsTransactionRegex.FindAllStringSubmatch(string(contents), -1) {
+				transactions[strings.ToUpper(match[1])] = true
+			}
+		case bmsMapAsset:
+			assessment.BMSMaps = append(assessment.BMSMaps, relPath)
+		case assemblerAsset:
+			assessment.AssemblerSources = append(assessment.AssemblerSources, relPath)
+		}
+	}
+	for transaction := range transactions {
+		assessment.CICSTransactions = append(assessment.CICSTransactions, transaction)
+	}
+	sort.Strings(assessment.CICSTransactions)
+	assessment.Strategy, assessment.Reasons = getMainframeStrategy(assessment)
+	return assessment
+}
+
+// parseCOBOLProgram finds the copybooks, the static calls and the subsystems used by the program.
+// Comment lines, which have an asterisk in the indicator area (column 7), are skipped.
+func parseCOBOLProgram(path string) (artifacts.MainframeProgram, error) {
+	program := artifacts.MainframeProgram{}
+	file, err := os.Open(path)
+	if err != nil {
+		return program, err
+	}
+	defer file.Close()
+	copybooks := map[string]bool{}
+	calls := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		program.Lines++
+		if len(line) > 6 && (line[6] == '*' || line[6] == '/') {
+			continue
+		}
+		for _, match := range cobolCopyRegex.FindAllStringSubmatch(line, -1) {
+			copybooks[strings.ToUpper(match[1])] = true
+		}
+		for _, match := range cobolCallRegex.FindAllStringSubmatch(line, -1) {
+			call := strings.ToUpper(match[1])
+			if common.IsStringPresent(imsCalls, call) {
+				program.UsesIMS = true
+				continue
+			}
+			calls[call] = true
+		}
+		for _, match := range cobolExecRegex.FindAllStringSubmatch(line, -1) {
+			switch strings.ToUpper(match[1]) {
+			case "CICS":
+				program.UsesCICS = true
+			case "SQL":
+				program.UsesSQL = true
+			case "DLI":
+				program.UsesIMS = true
+			}
+		}
+	}
+	program.Copybooks = getSortedKeys(copybooks)
+	program.Calls = getSortedKeys(calls)
+	return program, scanner.Err()
+}
+
+// parseJCL finds the programs run by the steps of the job and the datasets it uses. Comment lines start with //*.
+func parseJCL(path string) (artifacts.MainframeJob, error) {
+	job := artifacts.MainframeJob{}
+	file, err := os.Open(path)
+	if err != nil {
+		return job, err
+	}
+	defer file.Close()
+	programs := map[string]bool{}
+	datasets := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "//*") {
+			continue
+		}
+		for _, match := range jclProgramRegex.FindAllStringSubmatch(line, -1) {
+			programs[strings.ToUpper(match[1])] = true
+		}
+		for _, match := range jclDatasetRegex.FindAllStringSubmatch(line, -1) {
+			datasets[strings.ToUpper(match[1])] = true
+		}
+		if jclVSAMRegex.MatchString(line) {
+			job.UsesVSAM = true
+		}
+	}
+	job.Programs = getSortedKeys(programs)
+	job.Datasets = getSortedKeys(datasets)
+	return job, scanner.Err()
+}
+
+// getMainframeStrategy suggests rehosting the application on a mainframe emulator unless it depends on features the emulators support poorly, or it is small enough to rewrite
+func getMainframeStrategy(assessment artifacts.MainframeAssessment) (string, []string) {
+	lines := 0
+	usesCICS, usesSQL, usesIMS := len(assessment.CICSDefinitions) > 0 || len(assessment.BMSMaps) > 0, false, false
+	for _, program := range assessment.Programs {
+		lines += program.Lines
+		usesCICS = usesCICS || program.UsesCICS
+		usesSQL = usesSQL || program.UsesSQL
+		usesIMS = usesIMS || program.UsesIMS
+	}
+	usesVSAM := false
+	for _, job := range assessment.Jobs {
+		usesVSAM = usesVSAM || job.UsesVSAM
+	}
+	rewriteReasons := []string{}
+	if usesIMS {
+		rewriteReasons = append(rewriteReasons, "The programs use IMS, which the mainframe emulators support only partially.")
+	}
+	if len(assessment.AssemblerSources) > 0 {
+		rewriteReasons = append(rewriteReasons, "The application has assembler sources, which cannot be compiled for other architectures.")
+	}
+	if !usesCICS && lines > 0 && lines < smallMainframeAppLines {
+		rewriteReasons = append(rewriteReasons, fmt.Sprintf("The application is small (%d lines of COBOL), so rewriting it is likely cheaper than maintaining a COBOL toolchain.", lines))
+	}
+	if len(rewriteReasons) > 0 {
+		return artifacts.RewriteMainframeStrategy, rewriteReasons
+	}
+	reasons := []string{}
+	if usesCICS {
+		reasons = append(reasons, "The application has CICS transactions, which need a CICS compatible transaction server in the container, for example Micro Focus Enterprise Server or a similar rehosting platform.")
+	} else {
+		reasons = append(reasons, "The application is a batch application. The programs can be compiled with a COBOL compiler like GnuCOBOL and the JCL jobs can become Kubernetes Jobs or CronJobs.")
+	}
+	if usesSQL {
+		reasons = append(reasons, "The programs use embedded SQL, so they need an SQL precompiler and a database that is compatible with Db2.")
+	}
+	if usesVSAM {
+		reasons = append(reasons, "The jobs use VSAM datasets, which have to be converted to indexed files or database tables supported by the emulator.")
+	}
+	return artifacts.RehostMainframeStrategy, reasons
+}
+
+// getMainframeReportSection returns the markdown section of the report for the application
+func getMainframeReportSection(name string, assessment artifacts.MainframeAssessment) string {
+	section := strings.Builder{}
+	section.WriteString(fmt.Sprintf("## %s\n\n", name))
+	section.WriteString(fmt.Sprintf("Suggested strategy: **%s**\n\n", assessment.Strategy))
+	for _, reason := range assessment.Reasons {
+		section.WriteString(fmt.Sprintf("- %s\n", reason))
+	}
+	section.WriteString("\n| Asset | Count |\n| --- | --- |\n")
+	section.WriteString(fmt.Sprintf("| COBOL programs | %d |\n", len(assessment.Programs)))
+	section.WriteString(fmt.Sprintf("| Copybooks | %d |\n", len(assessment.Copybooks)))
+	section.WriteString(fmt.Sprintf("| JCL jobs and procedures | %d |\n", len(assessment.Jobs)))
+	section.WriteString(fmt.Sprintf("| CICS definitions | %d |\n", len(assessment.CICSDefinitions)))
+	section.WriteString(fmt.Sprintf("| CICS transactions | %d |\n", len(assessment.CICSTransactions)))
+	section.WriteString(fmt.Sprintf("| BMS maps | %d |\n", len(assessment.BMSMaps)))
+	section.WriteString(fmt.Sprintf("| Assembler sources | %d |\n", len(assessment.AssemblerSources)))
+	if len(assessment.Programs) > 0 {
+		section.WriteString("\n### Programs\n\n| Program | Lines | Copybooks | Calls | Uses |\n| --- | --- | --- | --- | --- |\n")
+		for _, program := range assessment.Programs {
+			uses := []string{}
+			if program.UsesCICS {
+				uses = append(uses, "CICS")
+			}
+			if program.UsesSQL {
+				uses = append(uses, "SQL")
+			}
+			if program.UsesIMS {
+				uses = append(uses, "IMS")
+			}
+			section.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s |\n", program.Path, program.Lines, strings.Join(program.Copybooks, ", "), strings.Join(program.Calls, ", "), strings.Join(uses, ", ")))
+		}
+	}
+	if len(assessment.Jobs) > 0 {
+		section.WriteString("\n### Jobs\n\n| Job | Programs | Datasets |\n| --- | --- | --- |\n")
+		for _, job := range assessment.Jobs {
+			section.WriteString(fmt.Sprintf("| %s | %s | %s |\n", job.Path, strings.Join(job.Programs, ", "), strings.Join(job.Datasets, ", ")))
+		}
+	}
+	if missing := getMissingCopybooks(assessment); len(missing) > 0 {
+		section.WriteString(fmt.Sprintf("\nThe copybooks %s are copied by the programs but were not found. They have to be added to the sources before the programs can be compiled.\n", strings.Join(missing, ", ")))
+	}
+	return section.String()
+}
+
+// getMissingCopybooks returns the copybooks that are copied by the programs but are not in the sources
+func getMissingCopybooks(assessment artifacts.MainframeAssessment) []string {
+	found := map[string]bool{}
+	for _, copybook := range assessment.Copybooks {
+		found[strings.ToUpper(strings.TrimSuffix(filepath.Base(copybook), filepath.Ext(copybook)))] = true
+	}
+	missing := map[string]bool{}
+	for _, program := range assessment.Programs {
+		for _, copybook := range program.Copybooks {
+			if !found[copybook] && !strings.HasPrefix(copybook, cicsCopybookPrefix) {
+				missing[copybook] = true
+			}
+		}
+	}
+	return getSortedKeys(missing)
+}
@@ -0,0 +1,199 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+func getMainframeTestDir(t *testing.T, name string) string {
+	dir, err := filepath.Abs(filepath.Join("testdata", "mainframe", name))
+	if err != nil {
+		t.Fatalf("failed to get the absolute path of the fixture %s . Error: %q", name, err)
+	}
+	return dir
+}
+
+func TestMainframeDirectoryDetect(t *testing.T) {
+	cicsApp := getMainframeTestDir(t, "cicsapp")
+	batchApp := getMainframeTestDir(t, "batchapp")
+	testCases := []struct {
+		name               string
+		dir                string
+		expectedDirs       []string
+		expectedSources    []string
+		expectedAssessment artifacts.MainframeAssessment
+	}{
+		{
+			name: "claim the conventional directories of a CICS application",
+			dir:  cicsApp,
+			expectedDirs: []string{
+				filepath.Join(cicsApp, "bms"),
+				filepath.Join(cicsApp, "cobol"),
+				filepath.Join(cicsApp, "copybook"),
+				filepath.Join(cicsApp, "csd"),
+				filepath.Join(cicsApp, "jcl"),
+			},
+			expectedSources: []string{
+				filepath.Join(cicsApp, "bms", "ACCTMAP.bms"),
+				filepath.Join(cicsApp, "cobol", "ACCTINQ.cbl"),
+				filepath.Join(cicsApp, "copybook", "ACCTREC.cpy"),
+				filepath.Join(cicsApp, "csd", "ACCT.csd"),
+				filepath.Join(cicsApp, "jcl", "NIGHTLY.jcl"),
+			},
+			expectedAssessment: artifacts.MainframeAssessment{
+				Programs: []artifacts.MainframeProgram{{
+					Path:      filepath.Join("cobol", "ACCTINQ.cbl"),
+					Lines:     13,
+					Copybooks: []string{"ACCTREC", "DFHAID", "MISSING"},
+					Calls:     []string{"ACCTFMT"},
+					UsesCICS:  true,
+					UsesSQL:   true,
+				}},
+				Copybooks: []string{filepath.Join("copybook", "ACCTREC.cpy")},
+				Jobs: []artifacts.MainframeJob{{
+					Path:     filepath.Join("jcl", "NIGHTLY.jcl"),
+					Programs: []string{"ACCTBAT", "IDCAMS"},
+					Datasets: []string{"PROD.ACCT.CTL", "PROD.ACCT.MASTER"},
+					UsesVSAM: true,
+				}},
+				CICSDefinitions:  []string{filepath.Join("csd", "ACCT.csd")},
+				CICSTransactions: []string{"AINQ", "AUPD"},
+				BMSMaps:          []string{filepath.Join("bms", "ACCTMAP.bms")},
+				Strategy:         artifacts.RehostMainframeStrategy,
+				Reasons: []string{
+					"The application has CICS transactions, which need a CICS compatible transaction server in the container, for example Micro Focus Enterprise Server or a similar rehosting platform.",
+					"The programs use embedded SQL, so they need an SQL precompiler and a database that is compatible with Db2.",
+					"The jobs use VSAM datasets, which have to be converted to indexed files or database tables supported by the emulator.",
+				},
+			},
+		},
+		{
+			name:         "claim the whole directory of a batch application",
+			dir:          batchApp,
+			expectedDirs: []string{batchApp},
+			expectedSources: []string{
+				filepath.Join(batchApp, "PAYJOB.JCL"),
+				filepath.Join(batchApp, "PAYREC.CPY"),
+				filepath.Join(batchApp, "PAYROLL.CBL"),
+			},
+			expectedAssessment: artifacts.MainframeAssessment{
+				Programs: []artifacts.MainframeProgram{{
+					Path:      "PAYROLL.CBL",
+					Lines:     9,
+					Copybooks: []string{"PAYREC"},
+					Calls:     []string{"PAYCALC"},
+					UsesIMS:   true,
+				}},
+				Copybooks: []string{"PAYREC.CPY"},
+				Jobs: []artifacts.MainframeJob{{
+					Path:     "PAYJOB.JCL",
+					Programs: []string{"PAYROLL"},
+					Datasets: []string{"PROD.PAY.INPUT"},
+				}},
+				Strategy: artifacts.RewriteMainframeStrategy,
+				Reasons: []string{
+					"The programs use IMS, which the mainframe emulators support only partially.",
+					"The application is small (9 lines of COBOL), so rewriting it is likely cheaper than maintaining a COBOL toolchain.",
+				},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			services, err := (&MainframeAnalyser{}).DirectoryDetect(testCase.dir)
+			if err != nil {
+				t.Fatalf("failed to detect the directory %s . Error: %q", testCase.dir, err)
+			}
+			serviceName := filepath.Base(testCase.dir)
+			expected := map[string][]transformertypes.Artifact{serviceName: {{
+				Name: serviceName,
+				Type: artifacts.MainframeAssessmentArtifactType,
+				Paths: map[transformertypes.PathType][]string{
+					artifacts.ServiceDirPathType:      testCase.expectedDirs,
+					artifacts.MainframeSourcePathType: testCase.expectedSources,
+				},
+				Configs: map[transformertypes.ConfigType]interface{}{
+					artifacts.MainframeAssessmentConfigType: testCase.expectedAssessment,
+				},
+			}}}
+			if diff := cmp.Diff(expected, services); diff != "" {
+				t.Fatalf("the detected services are wrong. Difference:\n%s", diff)
+			}
+		})
+	}
+	t.Run("ignore the directories with only copybooks", func(t *testing.T) {
+		services, err := (&MainframeAnalyser{}).DirectoryDetect(getMainframeTestDir(t, "copybooksonly"))
+		if err != nil || services != nil {
+			t.Fatalf("expected no services. Services: %+v Error: %q", services, err)
+		}
+	})
+}
+
+func TestMainframeTransform(t *testing.T) {
+	transformer := &MainframeAnalyser{}
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{TempPath: t.TempDir()}}
+	if err := transformer.Init(transformertypes.Transformer{}, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	newArtifacts := []transformertypes.Artifact{}
+	for _, name := range []string{"cicsapp", "batchapp"} {
+		services, err := transformer.DirectoryDetect(getMainframeTestDir(t, name))
+		if err != nil {
+			t.Fatalf("failed to detect the fixture %s . Error: %q", name, err)
+		}
+		newArtifacts = append(newArtifacts, services[name]...)
+	}
+	pathMappings, _, err := transformer.Transform(newArtifacts, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the artifacts. Error: %q", err)
+	}
+	if len(pathMappings) != 1 || pathMappings[0].DestPath != filepath.Join(defaultMainframeReportDir, mainframeReportFileName) {
+		t.Fatalf("expected a path mapping of the report. Actual: %+v", pathMappings)
+	}
+	report, err := os.ReadFile(pathMappings[0].SrcPath)
+	if err != nil {
+		t.Fatalf("failed to read the report. Error: %q", err)
+	}
+	batchIdx, cicsIdx := strings.Index(string(report), "## batchapp\n"), strings.Index(string(report), "## cicsapp\n")
+	if batchIdx < 0 || cicsIdx < batchIdx {
+		t.Fatalf("expected a section for each application sorted by name. Report:\n%s", report)
+	}
+	for _, expected := range []string{
+		"Suggested strategy: **rehost**",
+		"Suggested strategy: **rewrite**",
+		"| CICS transactions | 2 |",
+		"| " + filepath.Join("cobol", "ACCTINQ.cbl") + " | 13 | ACCTREC, DFHAID, MISSING | ACCTFMT | CICS, SQL |",
+		"| " + filepath.Join("jcl", "NIGHTLY.jcl") + " | ACCTBAT, IDCAMS | PROD.ACCT.CTL, PROD.ACCT.MASTER |",
+		"The copybooks MISSING are copied by the programs but were not found.",
+	} {
+		if !strings.Contains(string(report), expected) {
+			t.Fatalf("the report does not have %q . Report:\n%s", expected, report)
+		}
+	}
+	if strings.Contains(string(report), "DFHAID are copied") || strings.Count(string(report), "were not found") != 1 {
+		t.Fatalf("only the missing copybooks that are not supplied by CICS should be reported. Report:\n%s", report)
+	}
+}
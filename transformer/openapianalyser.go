@@ -0,0 +1,307 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	noAPIGateway         = "none"
+	gatewayAPIGateway    = "GatewayAPI"
+	kongAPIGateway       = "Kong"
+	apisixAPIGateway     = "Apisix"
+	defaultAPIGatewayDir = common.DeployDir + string(os.PathSeparator) + "apigateway"
+)
+
+// OpenAPIAnalyser implements Transformer interface
+type OpenAPIAnalyser struct {
+	Config        transformertypes.Transformer
+	Env           *environment.Environment
+	OpenAPIConfig *OpenAPIYamlConfig
+	specs         []openAPISpec
+}
+
+// OpenAPIYamlConfig stores the yaml configuration for OpenAPI transformer
+type OpenAPIYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+}
+
+// openAPISpec contains the parts of an OpenAPI (v3) or Swagger (v2) spec that are needed for routing
+type openAPISpec struct {
+	Swagger string `yaml:"swagger"`
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Title string `yaml:"title"`
+	} `yaml:"info"`
+	BasePath string `yaml:"basePath"`
+	Servers  []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths map[string]interface{} `yaml:"paths"`
+	path  string
+}
+
+// Init Initializes the transformer
+func (t *OpenAPIAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.OpenAPIConfig = &OpenAPIYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.OpenAPIConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.OpenAPIConfig, err)
+		return err
+	}
+	if t.OpenAPIConfig.OutputPath == "" {
+		t.OpenAPIConfig.OutputPath = defaultAPIGatewayDir
+	}
+	t.specs, err = findOpenAPISpecs(env.GetEnvironmentSource())
+	return err
+}
+
+// findOpenAPISpecs returns the OpenAPI and Swagger specs in the directory
+func findOpenAPISpecs(dir string) ([]openAPISpec, error) {
+	specPaths, err := common.GetFilesByExt(dir, []string{".yaml", ".yml", ".json"})
+	if err != nil {
+		logrus.Errorf("Unable to fetch yaml and json files at path %s Error: %q", dir, err)
+		return nil, err
+	}
+	specs := []openAPISpec{}
+	for _, specPath := range specPaths {
+		spec := openAPISpec{}
+		if err := common.ReadYaml(specPath, &spec); err != nil || (spec.OpenAPI == "" && spec.Swagger == "") || len(spec.Paths) == 0 {
+			continue
+		}
+		logrus.Debugf("found an OpenAPI spec at path %s", specPath)
+		spec.path = specPath
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// GetConfig returns the transformer config
+func (t *OpenAPIAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *OpenAPIAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform aligns the ingress paths of the services with the base path of their OpenAPI specs
+// and optionally generates the API gateway configuration for the paths in the specs
+func (t *OpenAPIAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		routes := map[string][]string{}
+		serviceNames := []string{}
+		for sn, s := range ir.Services {
+			spec, ok := getServiceOpenAPISpec(t.specs, ir, sn, s)
+			if !ok || len(s.ServiceToPodPortForwardings) == 0 {
+				continue
+			}
+			if basePath := spec.getBasePath(); basePath != "" {
+				s.ServiceToPodPortForwardings[0].ServiceRelPath = basePath
+			}
+			ir.Services[sn] = s
+			routes[sn] = spec.getPathPrefixes()
+			serviceNames = append(serviceNames, sn)
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+		if len(serviceNames) == 0 {
+			continue
+		}
+		sort.Strings(serviceNames)
+		gatewayType := qaengine.FetchSelectAnswer(
+			common.ConfigAPIGatewayTypeKey,
+			fmt.Sprintf("OpenAPI specs were found for the services %+v . Select the API gateway configuration to generate for their paths:", serviceNames),
+			[]string{"The routes are generated for every path prefix in the specs", "Select " + noAPIGateway + " to skip the API gateway configuration"},
+			noAPIGateway,
+			[]string{noAPIGateway, gatewayAPIGateway, kongAPIGateway, apisixAPIGateway},
+		)
+		if gatewayType == noAPIGateway {
+			continue
+		}
+		gatewayName := ""
+		if gatewayType == gatewayAPIGateway {
+			gatewayName = qaengine.FetchStringAnswer(common.ConfigAPIGatewayNameKey, "Enter the name of the gateway that the routes should be attached to:", []string{"The gateway is not created by move2kube"}, common.NormalizeForMetadataName(ir.Name+"-gateway"))
+		}
+		tempDest := filepath.Join(t.Env.TempPath, t.OpenAPIConfig.OutputPath)
+		if err := os.MkdirAll(tempDest, common.DefaultDirectoryPermission); err != nil {
+			logrus.Errorf("failed to create the directory %s for the API gateway configuration. Error: %q", tempDest, err)
+			continue
+		}
+		for _, sn := range serviceNames {
+			s := ir.Services[sn]
+			obj := getAPIGatewayObject(gatewayType, gatewayName, sn, s.ServiceToPodPortForwardings[0].ServicePort.Number, routes[sn])
+			file := filepath.Join(tempDest, sn+"-"+strings.ToLower(gatewayType)+".yaml")
+			if err := common.WriteYaml(file, obj); err != nil {
+				logrus.Errorf("failed to write the API gateway configuration of the service %s to the file at path %s . Error: %q", sn, file, err)
+				continue
+			}
+			destPath, err := filepath.Rel(t.Env.TempPath, file)
+			if err != nil {
+				logrus.Errorf("failed to make the yaml path %s relative to the temporary directory %s . Error: %q", file, t.Env.TempPath, err)
+				continue
+			}
+			pathMappings = append(pathMappings, transformertypes.PathMapping{
+				Type:     transformertypes.DefaultPathMappingType,
+				SrcPath:  file,
+				DestPath: destPath,
+			})
+		}
+	}
+	return pathMappings, artifactsCreated, nil
+}
+
+// getServiceOpenAPISpec returns the spec that is in the build context of one of the images of the service.
+// If there is no such spec, the spec whose title or directory matches the name of the service is returned.
+func getServiceOpenAPISpec(specs []openAPISpec, ir irtypes.IR, serviceName string, service irtypes.Service) (openAPISpec, bool) {
+	for _, container := range service.Containers {
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok || image.Build.ContextPath == "" {
+			continue
+		}
+		for _, spec := range specs {
+			if common.IsParent(spec.path, image.Build.ContextPath) {
+				return spec, true
+			}
+		}
+	}
+	for _, spec := range specs {
+		if common.NormalizeForMetadataName(spec.Info.Title) == serviceName || common.NormalizeForMetadataName(filepath.Base(filepath.Dir(spec.path))) == serviceName {
+			return spec, true
+		}
+	}
+	return openAPISpec{}, false
+}
+
+// getBasePath returns the base path of the API, using the first server for OpenAPI v3 specs
+func (spec openAPISpec) getBasePath() string {
+	basePath := spec.BasePath
+	if basePath == "" && len(spec.Servers) > 0 {
+		basePath = spec.Servers[0].URL
+		if idx := strings.Index(basePath, "://"); idx >= 0 {
+			basePath = basePath[idx+len("://"):]
+			if idx := strings.Index(basePath, "/"); idx >= 0 {
+				basePath = basePath[idx:]
+			} else {
+				basePath = ""
+			}
+		}
+	}
+	basePath = strings.TrimSuffix(basePath, "/")
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+	return basePath
+}
+
+// getPathPrefixes returns the first segment of every path in the spec, prefixed with the base path
+func (spec openAPISpec) getPathPrefixes() []string {
+	basePath := spec.getBasePath()
+	prefixes := []string{}
+	for path := range spec.Paths {
+		segment := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+		prefix := basePath + "/" + segment
+		if segment == "" || strings.HasPrefix(segment, "{") {
+			prefix = basePath
+			if prefix == "" {
+				prefix = "/"
+			}
+		}
+		prefixes = common.AppendIfNotPresent(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// getAPIGatewayObject returns the route configuration of the service for the type of API gateway
+func getAPIGatewayObject(gatewayType, gatewayName, serviceName string, servicePort int32, prefixes []string) map[string]interface{} {
+	metadata := map[string]interface{}{"name": serviceName}
+	switch gatewayType {
+	case gatewayAPIGateway:
+		rules := []interface{}{}
+		for _, prefix := range prefixes {
+			rules = append(rules, map[string]interface{}{
+				"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": prefix}}},
+				"backendRefs": []interface{}{map[string]interface{}{"name": serviceName, "port": servicePort}},
+			})
+		}
+		return map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1beta1",
+			"kind":       "HTTPRoute",
+			"metadata":   metadata,
+			"spec": map[string]interface{}{
+				"parentRefs": []interface{}{map[string]interface{}{"name": gatewayName}},
+				"rules":      rules,
+			},
+		}
+	case apisixAPIGateway:
+		routes := []interface{}{}
+		for _, prefix := range prefixes {
+			routes = append(routes, map[string]interface{}{
+				"name":     common.NormalizeForMetadataName(serviceName + "-" + strings.Trim(prefix, "/")),
+				"match":    map[string]interface{}{"paths": []string{strings.TrimSuffix(prefix, "/") + "/*", prefix}},
+				"backends": []interface{}{map[string]interface{}{"serviceName": serviceName, "servicePort": servicePort}},
+			})
+		}
+		return map[string]interface{}{
+			"apiVersion": "apisix.apache.org/v2",
+			"kind":       "ApisixRoute",
+			"metadata":   metadata,
+			"spec":       map[string]interface{}{"http": routes},
+		}
+	}
+	// Kong is configured using ingresses of the kong ingress class
+	paths := []interface{}{}
+	for _, prefix := range prefixes {
+		paths = append(paths, map[string]interface{}{
+			"path":     prefix,
+			"pathType": "Prefix",
+			"backend":  map[string]interface{}{"service": map[string]interface{}{"name": serviceName, "port": map[string]interface{}{"number": servicePort}}},
+		})
+	}
+	metadata["annotations"] = map[string]string{"konghq.com/strip-path": "false"}
+	return map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata":   metadata,
+		"spec": map[string]interface{}{
+			"ingressClassName": "kong",
+			"rules":            []interface{}{map[string]interface{}{"http": map[string]interface{}{"paths": paths}}},
+		},
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestFindOpenAPISpecs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"orders/api/openapi.yaml": `openapi: 3.0.0
+info:
+  title: Orders
+servers:
+  - url: https://orders.example.com/v1/
+paths:
+  /orders: {}
+  /orders/{id}: {}
+  /{tenant}/status: {}
+`,
+		"inventory/swagger.json": `{"swagger": "2.0", "info": {"title": "Stock"}, "basePath": "api", "paths": {"/items": {}, "/": {}}}`,
+		"deploy/deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\n",
+		"empty/openapi.yaml":     "openapi: 3.0.0\npaths: {}\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	specs, err := findOpenAPISpecs(dir)
+	if err != nil {
+		t.Fatalf("failed to find the OpenAPI specs. Error: %q", err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("expected only the specs with paths. Actual: %+v", specs)
+	}
+	byTitle := map[string]openAPISpec{}
+	for _, spec := range specs {
+		byTitle[spec.Info.Title] = spec
+	}
+	orders, stock := byTitle["Orders"], byTitle["Stock"]
+	if basePath := orders.getBasePath(); basePath != "/v1" {
+		t.Fatalf("expected the base path of the OpenAPI v3 spec to come from its server. Actual: %q", basePath)
+	}
+	if diff := cmp.Diff([]string{"/v1", "/v1/orders"}, orders.getPathPrefixes()); diff != "" {
+		t.Fatalf("the path prefixes of the OpenAPI v3 spec are incorrect. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"/api", "/api/items"}, stock.getPathPrefixes()); diff != "" {
+		t.Fatalf("the path prefixes of the Swagger spec are incorrect. Difference:\n%s", diff)
+	}
+
+	ir := irtypes.NewIR()
+	ir.ContainerImages["orders:latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{ContextPath: filepath.Join(dir, "orders")}}
+	ordersService := irtypes.NewServiceWithName("orders-svc")
+	ordersService.Containers = []core.Container{{Name: "orders", Image: "orders:latest"}}
+	if spec, ok := getServiceOpenAPISpec(specs, ir, "orders-svc", ordersService); !ok || spec.Info.Title != "Orders" {
+		t.Fatalf("expected the spec in the build context of the service. Actual: %+v", spec)
+	}
+	if spec, ok := getServiceOpenAPISpec(specs, ir, "inventory", irtypes.NewServiceWithName("inventory")); !ok || spec.Info.Title != "Stock" {
+		t.Fatalf("expected the spec in the directory named after the service. Actual: %+v", spec)
+	}
+	if spec, ok := getServiceOpenAPISpec(specs, ir, "web", irtypes.NewServiceWithName("web")); ok {
+		t.Fatalf("expected no spec for the service web. Actual: %+v", spec)
+	}
+}
+
+func TestGetAPIGatewayObject(t *testing.T) {
+	prefixes := []string{"/v1", "/v1/orders"}
+	route := getAPIGatewayObject(gatewayAPIGateway, "shop-gateway", "orders", 8080, prefixes)
+	expectedRoute := map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1beta1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"name": "orders"},
+		"spec": map[string]interface{}{
+			"parentRefs": []interface{}{map[string]interface{}{"name": "shop-gateway"}},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/v1"}}},
+					"backendRefs": []interface{}{map[string]interface{}{"name": "orders", "port": int32(8080)}},
+				},
+				map[string]interface{}{
+					"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/v1/orders"}}},
+					"backendRefs": []interface{}{map[string]interface{}{"name": "orders", "port": int32(8080)}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedRoute, route); diff != "" {
+		t.Fatalf("the HTTPRoute is incorrect. Difference:\n%s", diff)
+	}
+	apisixRoute := getAPIGatewayObject(apisixAPIGateway, "", "orders", 8080, []string{"/v1/orders"})
+	expectedHTTP := []interface{}{map[string]interface{}{
+		"name":     "orders-v1-orders",
+		"match":    map[string]interface{}{"paths": []string{"/v1/orders/*", "/v1/orders"}},
+		"backends": []interface{}{map[string]interface{}{"serviceName": "orders", "servicePort": int32(8080)}},
+	}}
+	if diff := cmp.Diff(expectedHTTP, apisixRoute["spec"].(map[string]interface{})["http"]); diff != "" {
+		t.Fatalf("the ApisixRoute is incorrect. Difference:\n%s", diff)
+	}
+	ingress := getAPIGatewayObject(kongAPIGateway, "", "orders", 8080, prefixes)
+	if ingress["kind"] != "Ingress" || ingress["spec"].(map[string]interface{})["ingressClassName"] != "kong" {
+		t.Fatalf("expected a kong ingress. Actual: %+v", ingress)
+	}
+}
@@ -67,14 +67,15 @@ func processPathMappings(pms []transformertypes.PathMapping, sourcePath, outputP
 			}
 		case strings.ToLower(string(transformertypes.TemplatePathMappingType)):
 			if err := filesystem.TemplateCopy(pm.SrcPath, destPath,
-				filesystem.AddOnConfig{Config: pm.TemplateConfig}); err != nil {
+				filesystem.AddOnConfig{Config: pm.TemplateConfig, Libraries: pm.TemplateLibraries}); err != nil {
 				logrus.Errorf("Error while copying sourcepath for %+v . Error: %q", pm, err)
 			}
 		case strings.ToLower(string(transformertypes.SpecialTemplatePathMappingType)):
 			if err := filesystem.TemplateCopy(pm.SrcPath, destPath,
 				filesystem.AddOnConfig{OpeningDelimiter: filesystem.SpecialOpeningDelimiter,
 					ClosingDelimiter: filesystem.SpecialClosingDelimiter,
-					Config:           pm.TemplateConfig}); err != nil {
+					Config:           pm.TemplateConfig,
+					Libraries:        pm.TemplateLibraries}); err != nil {
 				logrus.Errorf("Error while copying sourcepath for %+v . Error: %q", pm, err)
 			}
 		default:
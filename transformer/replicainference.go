@@ -0,0 +1,135 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxReplicaSourceFileSize is the size of the largest file that is read for the ECS services and the service metrics
+	maxReplicaSourceFileSize = 4 * 1024 * 1024
+)
+
+// ecsServices is the output of aws ecs describe-services, in json or in yaml
+type ecsServices struct {
+	Services []ecsService `yaml:"services"`
+}
+
+// ecsService is an ECS service in the output of aws ecs describe-services
+type ecsService struct {
+	ServiceName  string `yaml:"serviceName"`
+	DesiredCount *int   `yaml:"desiredCount"`
+}
+
+// inferReplicasFromSource sets the replicas of the services, whose replica count was not inferred from their source platform,
+// using the desired count of the ECS services and the service metrics that are in the source directory.
+// The desired count of an ECS service takes precedence over the metrics.
+func inferReplicasFromSource(ir *irtypes.IR, sourceDir string) {
+	if sourceDir == "" {
+		return
+	}
+	filePaths, err := common.GetFilesByExt(sourceDir, []string{".json", ".yaml", ".yml"})
+	if err != nil {
+		logrus.Debugf("failed to look for the ECS services and the service metrics in the directory %s . Error: %q", sourceDir, err)
+		return
+	}
+	fromECS := map[string]int{}
+	fromMetrics := map[string]collecttypes.ServiceMetric{}
+	for _, filePath := range filePaths {
+		if isInSkippedDir(sourceDir, filePath) {
+			continue
+		}
+		if info, err := os.Stat(filePath); err != nil || info.Size() > maxReplicaSourceFileSize {
+			continue
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			logrus.Debugf("failed to read the file %s . Error: %q", filePath, err)
+			continue
+		}
+		if strings.Contains(string(data), string(collecttypes.ServiceMetricsKind)) {
+			serviceMetrics := collecttypes.ServiceMetrics{}
+			if err := common.ReadMove2KubeYaml(filePath, &serviceMetrics); err == nil && serviceMetrics.Kind == string(collecttypes.ServiceMetricsKind) {
+				for _, metric := range serviceMetrics.Spec.Services {
+					fromMetrics[common.NormalizeForMetadataName(metric.Name)] = metric
+				}
+				continue
+			}
+		}
+		if !strings.Contains(string(data), "desiredCount") {
+			continue
+		}
+		services := ecsServices{}
+		if err := yaml.Unmarshal(data, &services); err != nil {
+			logrus.Debugf("the file %s is not the output of aws ecs describe-services . Error: %q", filePath, err)
+			continue
+		}
+		for _, service := range services.Services {
+			if service.ServiceName != "" && service.DesiredCount != nil && *service.DesiredCount > 0 {
+				fromECS[common.NormalizeForMetadataName(service.ServiceName)] = *service.DesiredCount
+			}
+		}
+	}
+	for sn, s := range ir.Services {
+		if s.ReplicasSource != "" {
+			continue
+		}
+		if desiredCount, ok := fromECS[sn]; ok {
+			s.Replicas = desiredCount
+			s.ReplicasSource = "desiredCount of the ECS service"
+		} else if metric, ok := fromMetrics[sn]; ok {
+			if metric.AverageInstances > 0 {
+				s.Replicas = int(math.Ceil(metric.AverageInstances))
+				s.ReplicasSource = fmt.Sprintf("average instances in the service metrics (peak %d)", metric.PeakInstances)
+			} else if metric.PeakInstances > 0 {
+				s.Replicas = metric.PeakInstances
+				s.ReplicasSource = "peak instances in the service metrics"
+			} else {
+				continue
+			}
+		} else {
+			continue
+		}
+		logrus.Debugf("inferred %d replicas for the service %s from the %s", s.Replicas, sn, s.ReplicasSource)
+		ir.Services[sn] = s
+	}
+}
+
+// isInSkippedDir returns true if the file is in a hidden directory or in a directory of dependencies or build outputs
+func isInSkippedDir(sourceDir, filePath string) bool {
+	relPath, err := filepath.Rel(sourceDir, filepath.Dir(filePath))
+	if err != nil || relPath == "." {
+		return false
+	}
+	for _, dir := range strings.Split(relPath, string(os.PathSeparator)) {
+		if strings.HasPrefix(dir, ".") || common.IsStringPresent(localStateSkipDirs, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,456 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ingressConversion          = "Ingress"
+	gatewayAPIConversion       = "GatewayAPI"
+	deploymentConversion       = "Deployment"
+	noConversion               = "none"
+	defaultReverseProxyDir     = common.DeployDir + string(os.PathSeparator) + "reverseproxy"
+	nginxRewriteTargetAnnot    = "nginx.ingress.kubernetes.io/rewrite-target"
+	nginxUseRegexAnnot         = "nginx.ingress.kubernetes.io/use-regex"
+	nginxProxyImage            = "nginx:stable"
+	haproxyProxyImage          = "haproxy:lts"
+	nginxConfDir               = "/etc/nginx/conf.d"
+	nginxMainConfPath          = "/etc/nginx/nginx.conf"
+	haproxyConfPath            = "/usr/local/etc/haproxy/haproxy.cfg"
+	reverseProxyReportFileName = "report.txt"
+)
+
+// ReverseProxyAnalyser implements Transformer interface
+type ReverseProxyAnalyser struct {
+	Config             transformertypes.Transformer
+	Env                *environment.Environment
+	ReverseProxyConfig *ReverseProxyYamlConfig
+	proxyConfigPaths   map[string]string // [path]proxy type
+}
+
+// ReverseProxyYamlConfig stores the yaml configuration for the reverse proxy transformer
+type ReverseProxyYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+}
+
+// Init Initializes the transformer
+func (t *ReverseProxyAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.ReverseProxyConfig = &ReverseProxyYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.ReverseProxyConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.ReverseProxyConfig, err)
+		return err
+	}
+	if t.ReverseProxyConfig.OutputPath == "" {
+		t.ReverseProxyConfig.OutputPath = defaultReverseProxyDir
+	}
+	t.proxyConfigPaths = map[string]string{}
+	configPaths, err := common.GetFilesByExt(env.GetEnvironmentSource(), []string{".conf", ".cfg"})
+	if err != nil {
+		logrus.Errorf("Unable to fetch the conf and cfg files at path %s Error: %q", env.GetEnvironmentSource(), err)
+		return err
+	}
+	for _, configPath := range configPaths {
+		contents, err := os.ReadFile(configPath)
+		if err != nil {
+			continue
+		}
+		switch {
+		case filepath.Ext(configPath) == ".conf" && strings.Contains(string(contents), "proxy_pass"):
+			t.proxyConfigPaths[configPath] = nginxProxyType
+		case filepath.Ext(configPath) == ".cfg" && strings.Contains(filepath.Base(configPath), haproxyProxyType) && strings.Contains(string(contents), "backend"):
+			t.proxyConfigPaths[configPath] = haproxyProxyType
+		default:
+			continue
+		}
+		logrus.Debugf("found a %s config at path %s", t.proxyConfigPaths[configPath], configPath)
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *ReverseProxyAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *ReverseProxyAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform converts the routing of the nginx and HAProxy configs found in the source into ingresses or HTTP routes,
+// or deploys the proxy with its config in a config map. The directives that could not be converted are reported.
+func (t *ReverseProxyAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	if len(t.proxyConfigPaths) == 0 {
+		return pathMappings, newArtifacts, nil
+	}
+	configPaths := []string{}
+	for configPath := range t.proxyConfigPaths {
+		configPaths = append(configPaths, configPath)
+	}
+	sort.Strings(configPaths)
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		tempDest := filepath.Join(t.Env.TempPath, t.ReverseProxyConfig.OutputPath)
+		usedNames := map[string]bool{}
+		for _, configPath := range configPaths {
+			var config reverseProxyConfig
+			var err error
+			if t.proxyConfigPaths[configPath] == haproxyProxyType {
+				config, err = parseHAProxyConfig(configPath)
+			} else {
+				config, err = parseNginxConfig(configPath)
+			}
+			if err != nil {
+				logrus.Errorf("failed to parse the reverse proxy config at path %s . Error: %q", configPath, err)
+				continue
+			}
+			relConfigPath := configPath
+			if rel, err := filepath.Rel(t.Env.GetEnvironmentSource(), configPath); err == nil {
+				relConfigPath = rel
+			}
+			for i, unconverted := range config.unconverted {
+				config.unconverted[i] = strings.Replace(unconverted, configPath, relConfigPath, 1)
+			}
+			config.path = relConfigPath
+			config.routes = resolveReverseProxyBackends(&config, ir)
+			if len(config.routes) == 0 && !config.isComplex() {
+				continue
+			}
+			name := getReverseProxyName(configPath, usedNames)
+			def := ingressConversion
+			if config.isComplex() {
+				def = deploymentConversion
+			}
+			conversion := qaengine.FetchSelectAnswer(
+				common.JoinQASubKeys(common.ConfigReverseProxyKey, `"`+name+`"`, common.ConfigConversionKeySegment),
+				fmt.Sprintf("Select how the %s config at %s should be converted :", config.proxyType, relConfigPath),
+				[]string{fmt.Sprintf("%d routes can be converted and %d directives cannot be converted", len(config.routes), len(config.unconverted)), "Select " + deploymentConversion + " to run the proxy in the cluster with its config in a config map"},
+				def,
+				[]string{ingressConversion, gatewayAPIConversion, deploymentConversion, noConversion},
+			)
+			if conversion == noConversion {
+				continue
+			}
+			var objs []map[string]interface{}
+			notes := []string{}
+			switch conversion {
+			case gatewayAPIConversion:
+				gatewayName := qaengine.FetchStringAnswer(common.ConfigAPIGatewayNameKey, "Enter the name of the gateway that the routes should be attached to:", []string{"The gateway is not created by move2kube"}, common.NormalizeForMetadataName(ir.Name+"-gateway"))
+				objs, notes = getReverseProxyHTTPRoutes(name, gatewayName, config.routes)
+			case deploymentConversion:
+				objs, err = getReverseProxyDeployment(name, configPath, config)
+				if err != nil {
+					logrus.Errorf("failed to create the deployment for the reverse proxy config at path %s . Error: %q", configPath, err)
+					continue
+				}
+				notes = append(notes, "The hosts of the backends in the config need to be the names of the kubernetes services")
+			default:
+				objs = getReverseProxyIngresses(name, config.routes)
+			}
+			outputDir := filepath.Join(tempDest, name)
+			if err := os.MkdirAll(outputDir, common.DefaultDirectoryPermission); err != nil {
+				logrus.Errorf("failed to create the directory %s for the reverse proxy config. Error: %q", outputDir, err)
+				continue
+			}
+			for _, obj := range objs {
+				file := filepath.Join(outputDir, fmt.Sprintf("%s-%s.yaml", obj["metadata"].(map[string]interface{})["name"], strings.ToLower(obj["kind"].(string))))
+				if err := common.WriteYaml(file, obj); err != nil {
+					logrus.Errorf("failed to write the %s converted from %s to the file at path %s . Error: %q", obj["kind"], configPath, file, err)
+				}
+			}
+			if err := writeReverseProxyReport(filepath.Join(outputDir, reverseProxyReportFileName), relConfigPath, conversion, config, notes); err != nil {
+				logrus.Errorf("failed to write the conversion report of the reverse proxy config at path %s . Error: %q", configPath, err)
+			}
+			if len(config.unconverted) > 0 && conversion != deploymentConversion {
+				logrus.Warnf("%d directives of the %s config at path %s could not be converted. See %s", len(config.unconverted), config.proxyType, relConfigPath, filepath.Join(t.ReverseProxyConfig.OutputPath, name, reverseProxyReportFileName))
+			}
+			pathMappings = append(pathMappings, transformertypes.PathMapping{
+				Type:     transformertypes.DefaultPathMappingType,
+				SrcPath:  outputDir,
+				DestPath: filepath.Join(t.ReverseProxyConfig.OutputPath, name),
+			})
+		}
+	}
+	return pathMappings, newArtifacts, nil
+}
+
+// resolveReverseProxyBackends replaces the backend hosts of the routes with the services they refer to.
+// The routes to backends that are not services are reported as not converted.
+func resolveReverseProxyBackends(config *reverseProxyConfig, ir irtypes.IR) []reverseProxyRoute {
+	routes := []reverseProxyRoute{}
+	for _, route := range config.routes {
+		serviceName := common.NormalizeForMetadataName(strings.Split(route.backendHost, ".")[0])
+		service, ok := ir.Services[serviceName]
+		if !ok {
+			config.unconverted = append(config.unconverted, fmt.Sprintf("%s: the backend %s of the route %s%s is not one of the services", config.path, route.backendHost, route.host, route.path))
+			continue
+		}
+		route.backendHost = serviceName
+		if len(service.ServiceToPodPortForwardings) > 0 {
+			port := service.ServiceToPodPortForwardings[0].ServicePort.Number
+			for _, forwarding := range service.ServiceToPodPortForwardings {
+				if forwarding.ServicePort.Number == route.backendPort || forwarding.PodPort.Number == route.backendPort {
+					port = forwarding.ServicePort.Number
+					break
+				}
+			}
+			route.backendPort = port
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// getReverseProxyName returns a name for the resources converted from the config that has not been used yet
+func getReverseProxyName(configPath string, usedNames map[string]bool) string {
+	base := common.NormalizeForMetadataName(strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath)))
+	name := base
+	for i := 2; usedNames[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	usedNames[name] = true
+	return name
+}
+
+// getReverseProxyIngresses returns an ingress with the routes that are not rewritten,
+// and an ingress for each route that is rewritten, since the rewrites are annotations of the ingress
+func getReverseProxyIngresses(name string, routes []reverseProxyRoute) []map[string]interface{} {
+	objs := []map[string]interface{}{}
+	plainRoutes := []reverseProxyRoute{}
+	for i, route := range routes {
+		if route.rewritePrefix == "" {
+			plainRoutes = append(plainRoutes, route)
+			continue
+		}
+		prefix := strings.TrimSuffix(route.path, "/")
+		route.path = prefix + "(/|$)(.*)"
+		route.pathType = "ImplementationSpecific"
+		ingress := getReverseProxyIngress(fmt.Sprintf("%s-rewrite-%d", name, i+1), []reverseProxyRoute{route})
+		ingress["metadata"].(map[string]interface{})["annotations"] = map[string]interface{}{
+			nginxUseRegexAnnot:      "true",
+			nginxRewriteTargetAnnot: strings.TrimSuffix(route.rewritePrefix, "/") + "/$2",
+		}
+		objs = append(objs, ingress)
+	}
+	if len(plainRoutes) > 0 {
+		objs = append([]map[string]interface{}{getReverseProxyIngress(name, plainRoutes)}, objs...)
+	}
+	return objs
+}
+
+func getReverseProxyIngress(name string, routes []reverseProxyRoute) map[string]interface{} {
+	hosts := []string{}
+	hostPaths := map[string][]interface{}{}
+	tlsHosts := []string{}
+	for _, route := range routes {
+		if _, ok := hostPaths[route.host]; !ok {
+			hosts = append(hosts, route.host)
+		}
+		hostPaths[route.host] = append(hostPaths[route.host], map[string]interface{}{
+			"path":     route.path,
+			"pathType": route.pathType,
+			"backend":  map[string]interface{}{"service": map[string]interface{}{"name": route.backendHost, "port": map[string]interface{}{"number": route.backendPort}}},
+		})
+		if route.tls && route.host != "" && !common.IsStringPresent(tlsHosts, route.host) {
+			tlsHosts = append(tlsHosts, route.host)
+		}
+	}
+	rules := []interface{}{}
+	for _, host := range hosts {
+		rule := map[string]interface{}{"http": map[string]interface{}{"paths": hostPaths[host]}}
+		if host != "" {
+			rule["host"] = host
+		}
+		rules = append(rules, rule)
+	}
+	spec := map[string]interface{}{"rules": rules}
+	if len(tlsHosts) > 0 {
+		tls := []interface{}{}
+		for _, host := range tlsHosts {
+			tls = append(tls, map[string]interface{}{"hosts": []string{host}, "secretName": common.MakeStringDNSSubdomainNameCompliant(strings.TrimPrefix(host, "*.") + "-tls")})
+		}
+		spec["tls"] = tls
+	}
+	return map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       common.IngressKind,
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       spec,
+	}
+}
+
+// getReverseProxyHTTPRoutes returns an HTTP route for each host
+func getReverseProxyHTTPRoutes(name, gatewayName string, routes []reverseProxyRoute) ([]map[string]interface{}, []string) {
+	objs := []map[string]interface{}{}
+	notes := []string{}
+	hosts := []string{}
+	hostRules := map[string][]interface{}{}
+	for _, route := range routes {
+		if _, ok := hostRules[route.host]; !ok {
+			hosts = append(hosts, route.host)
+		}
+		matchType := "PathPrefix"
+		if route.pathType == exactPathType {
+			matchType = "Exact"
+		}
+		rule := map[string]interface{}{
+			"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": matchType, "value": route.path}}},
+			"backendRefs": []interface{}{map[string]interface{}{"name": route.backendHost, "port": route.backendPort}},
+		}
+		if route.rewritePrefix != "" {
+			rule["filters"] = []interface{}{map[string]interface{}{
+				"type":       "URLRewrite",
+				"urlRewrite": map[string]interface{}{"path": map[string]interface{}{"type": "ReplacePrefixMatch", "replacePrefixMatch": route.rewritePrefix}},
+			}}
+		}
+		hostRules[route.host] = append(hostRules[route.host], rule)
+		if route.tls && route.host != "" {
+			notes = common.AppendIfNotPresent(notes, fmt.Sprintf("TLS for the host %s needs to be configured in a listener of the gateway %s", route.host, gatewayName))
+		}
+	}
+	for i, host := range hosts {
+		routeName := name
+		if len(hosts) > 1 {
+			routeName = fmt.Sprintf("%s-%d", name, i+1)
+		}
+		spec := map[string]interface{}{
+			"parentRefs": []interface{}{map[string]interface{}{"name": gatewayName}},
+			"rules":      hostRules[host],
+		}
+		if host != "" {
+			spec["hostnames"] = []string{host}
+		}
+		objs = append(objs, map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1beta1",
+			"kind":       "HTTPRoute",
+			"metadata":   map[string]interface{}{"name": routeName},
+			"spec":       spec,
+		})
+	}
+	return objs, notes
+}
+
+// getReverseProxyDeployment returns a deployment of the proxy that mounts the config from a config map, and a service for it
+func getReverseProxyDeployment(name, configPath string, config reverseProxyConfig) ([]map[string]interface{}, error) {
+	contents, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	fileName := filepath.Base(configPath)
+	image, mountPath := nginxProxyImage, filepath.Join(nginxConfDir, fileName)
+	if config.proxyType == haproxyProxyType {
+		image, mountPath = haproxyProxyImage, haproxyConfPath
+	} else if fileName == filepath.Base(nginxMainConfPath) {
+		mountPath = nginxMainConfPath
+	}
+	ports := []interface{}{map[string]interface{}{"name": "http", "port": 80, "targetPort": 80}}
+	containerPorts := []interface{}{map[string]interface{}{"containerPort": 80}}
+	for _, route := range config.routes {
+		if route.tls {
+			ports = append(ports, map[string]interface{}{"name": "https", "port": 443, "targetPort": 443})
+			containerPorts = append(containerPorts, map[string]interface{}{"containerPort": 443})
+			break
+		}
+	}
+	labels := map[string]interface{}{"move2kube.konveyor.io/service": name}
+	configMapName := name + "-config"
+	return []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": configMapName},
+			"data":       map[string]interface{}{fileName: string(contents)},
+		},
+		{
+			"apiVersion": "apps/v1",
+			"kind":       common.DeploymentKind,
+			"metadata":   map[string]interface{}{"name": name, "labels": labels},
+			"spec": map[string]interface{}{
+				"replicas": 2,
+				"selector": map[string]interface{}{"matchLabels": labels},
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": labels},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{map[string]interface{}{
+							"name":         name,
+							"image":        image,
+							"ports":        containerPorts,
+							"volumeMounts": []interface{}{map[string]interface{}{"name": "config", "mountPath": mountPath, "subPath": fileName}},
+						}},
+						"volumes": []interface{}{map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": configMapName}}},
+					},
+				},
+			},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       common.ServiceKind,
+			"metadata":   map[string]interface{}{"name": name, "labels": labels},
+			"spec":       map[string]interface{}{"selector": labels, "ports": ports},
+		},
+	}, nil
+}
+
+// writeReverseProxyReport writes the routes that were converted and the directives that could not be converted
+func writeReverseProxyReport(reportPath, configPath, conversion string, config reverseProxyConfig, notes []string) error {
+	report := strings.Builder{}
+	report.WriteString(fmt.Sprintf("The %s config at %s was converted using %s.\n", config.proxyType, configPath, conversion))
+	if conversion != deploymentConversion {
+		report.WriteString(fmt.Sprintf("\nConverted routes (%d):\n", len(config.routes)))
+		for _, route := range config.routes {
+			line := fmt.Sprintf("  %s%s (%s) -> %s:%d", route.host, route.path, route.pathType, route.backendHost, route.backendPort)
+			if route.rewritePrefix != "" {
+				line += " rewritten to " + route.rewritePrefix
+			}
+			if route.tls {
+				line += " with TLS"
+			}
+			report.WriteString(line + "\n")
+		}
+		report.WriteString(fmt.Sprintf("\nNot converted (%d):\n", len(config.unconverted)))
+		for _, unconverted := range config.unconverted {
+			report.WriteString("  " + unconverted + "\n")
+		}
+	}
+	if len(notes) > 0 {
+		report.WriteString("\nNotes:\n")
+		for _, note := range notes {
+			report.WriteString("  " + note + "\n")
+		}
+	}
+	return os.WriteFile(reportPath, []byte(report.String()), common.DefaultFilePermission)
+}
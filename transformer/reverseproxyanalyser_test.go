@@ -0,0 +1,231 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestResolveReverseProxyBackends(t *testing.T) {
+	ir := irtypes.NewIR()
+	api := irtypes.NewServiceWithName("api")
+	api.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{ServicePort: networking.ServiceBackendPort{Number: 80}, PodPort: networking.ServiceBackendPort{Number: 8080}},
+		{ServicePort: networking.ServiceBackendPort{Number: 9090}, PodPort: networking.ServiceBackendPort{Number: 9090}},
+	}
+	ir.Services["api"] = api
+	ir.Services["web"] = irtypes.NewServiceWithName("web")
+	config := reverseProxyConfig{path: "nginx.conf", routes: []reverseProxyRoute{
+		{path: "/api", backendHost: "api.default.svc.cluster.local", backendPort: 8080},
+		{path: "/metrics", backendHost: "api", backendPort: 9090},
+		{path: "/other", backendHost: "api", backendPort: 1234},
+		{path: "/", backendHost: "web", backendPort: 3000},
+		{host: "example.com", path: "/db", backendHost: "db", backendPort: 5432},
+	}, unconverted: []string{}}
+	expectedRoutes := []reverseProxyRoute{
+		{path: "/api", backendHost: "api", backendPort: 80},
+		{path: "/metrics", backendHost: "api", backendPort: 9090},
+		{path: "/other", backendHost: "api", backendPort: 80},
+		{path: "/", backendHost: "web", backendPort: 3000},
+	}
+	routes := resolveReverseProxyBackends(&config, ir)
+	if diff := cmp.Diff(expectedRoutes, routes, cmp.AllowUnexported(reverseProxyRoute{})); diff != "" {
+		t.Fatalf("the resolved routes are wrong. Difference:\n%s", diff)
+	}
+	expectedUnconverted := []string{"nginx.conf: the backend db of the route example.com/db is not one of the services"}
+	if diff := cmp.Diff(expectedUnconverted, config.unconverted); diff != "" {
+		t.Fatalf("the routes that could not be converted are wrong. Difference:\n%s", diff)
+	}
+}
+
+func TestGetReverseProxyName(t *testing.T) {
+	usedNames := map[string]bool{}
+	for _, testCase := range []struct{ configPath, expectedName string }{
+		{configPath: "/src/nginx/default.conf", expectedName: "default"},
+		{configPath: "/src/other/default.conf", expectedName: "default-2"},
+		{configPath: "/src/haproxy/haproxy.cfg", expectedName: "haproxy"},
+		{configPath: "/src/default.conf", expectedName: "default-3"},
+	} {
+		if name := getReverseProxyName(testCase.configPath, usedNames); name != testCase.expectedName {
+			t.Fatalf("wrong name for the config at path %s . Expected: %s Actual: %s", testCase.configPath, testCase.expectedName, name)
+		}
+	}
+}
+
+func TestGetReverseProxyIngresses(t *testing.T) {
+	testCases := []struct {
+		name     string
+		routes   []reverseProxyRoute
+		expected []map[string]interface{}
+	}{
+		{
+			name: "group the paths by host",
+			routes: []reverseProxyRoute{
+				{host: "example.com", path: "/", pathType: prefixPathType, backendHost: "web", backendPort: 80, tls: true},
+				{host: "example.com", path: "/health", pathType: exactPathType, backendHost: "api", backendPort: 8080, tls: true},
+				{path: "/", pathType: prefixPathType, backendHost: "web", backendPort: 80},
+			},
+			expected: []map[string]interface{}{{
+				"apiVersion": "networking.k8s.io/v1",
+				"kind":       "Ingress",
+				"metadata":   map[string]interface{}{"name": "proxy"},
+				"spec": map[string]interface{}{
+					"rules": []interface{}{
+						map[string]interface{}{"host": "example.com", "http": map[string]interface{}{"paths": []interface{}{
+							map[string]interface{}{"path": "/", "pathType": prefixPathType, "backend": map[string]interface{}{"service": map[string]interface{}{"name": "web", "port": map[string]interface{}{"number": int32(80)}}}},
+							map[string]interface{}{"path": "/health", "pathType": exactPathType, "backend": map[string]interface{}{"service": map[string]interface{}{"name": "api", "port": map[string]interface{}{"number": int32(8080)}}}},
+						}}},
+						map[string]interface{}{"http": map[string]interface{}{"paths": []interface{}{
+							map[string]interface{}{"path": "/", "pathType": prefixPathType, "backend": map[string]interface{}{"service": map[string]interface{}{"name": "web", "port": map[string]interface{}{"number": int32(80)}}}},
+						}}},
+					},
+					"tls": []interface{}{map[string]interface{}{"hosts": []string{"example.com"}, "secretName": "example.com-tls"}},
+				},
+			}},
+		},
+		{
+			name: "put each rewritten route in its own ingress",
+			routes: []reverseProxyRoute{
+				{path: "/", pathType: prefixPathType, backendHost: "web", backendPort: 80},
+				{path: "/api/", pathType: prefixPathType, backendHost: "api", backendPort: 8080, rewritePrefix: "/v1/"},
+			},
+			expected: []map[string]interface{}{
+				{
+					"apiVersion": "networking.k8s.io/v1",
+					"kind":       "Ingress",
+					"metadata":   map[string]interface{}{"name": "proxy"},
+					"spec": map[string]interface{}{"rules": []interface{}{
+						map[string]interface{}{"http": map[string]interface{}{"paths": []interface{}{
+							map[string]interface{}{"path": "/", "pathType": prefixPathType, "backend": map[string]interface{}{"service": map[string]interface{}{"name": "web", "port": map[string]interface{}{"number": int32(80)}}}},
+						}}},
+					}},
+				},
+				{
+					"apiVersion": "networking.k8s.io/v1",
+					"kind":       "Ingress",
+					"metadata": map[string]interface{}{"name": "proxy-rewrite-2", "annotations": map[string]interface{}{
+						nginxUseRegexAnnot:      "true",
+						nginxRewriteTargetAnnot: "/v1/$2",
+					}},
+					"spec": map[string]interface{}{"rules": []interface{}{
+						map[string]interface{}{"http": map[string]interface{}{"paths": []interface{}{
+							map[string]interface{}{"path": "/api(/|$)(.*)", "pathType": "ImplementationSpecific", "backend": map[string]interface{}{"service": map[string]interface{}{"name": "api", "port": map[string]interface{}{"number": int32(8080)}}}},
+						}}},
+					}},
+				},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, getReverseProxyIngresses("proxy", testCase.routes)); diff != "" {
+				t.Fatalf("the ingresses are wrong. Difference:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetReverseProxyHTTPRoutes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		routes        []reverseProxyRoute
+		expected      []map[string]interface{}
+		expectedNotes []string
+	}{
+		{
+			name: "route all the hosts in one route",
+			routes: []reverseProxyRoute{
+				{path: "/", pathType: prefixPathType, backendHost: "web", backendPort: 80},
+				{path: "/health", pathType: exactPathType, backendHost: "api", backendPort: 8080},
+			},
+			expected: []map[string]interface{}{{
+				"apiVersion": "gateway.networking.k8s.io/v1beta1",
+				"kind":       "HTTPRoute",
+				"metadata":   map[string]interface{}{"name": "proxy"},
+				"spec": map[string]interface{}{
+					"parentRefs": []interface{}{map[string]interface{}{"name": "gateway"}},
+					"rules": []interface{}{
+						map[string]interface{}{
+							"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/"}}},
+							"backendRefs": []interface{}{map[string]interface{}{"name": "web", "port": int32(80)}},
+						},
+						map[string]interface{}{
+							"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "Exact", "value": "/health"}}},
+							"backendRefs": []interface{}{map[string]interface{}{"name": "api", "port": int32(8080)}},
+						},
+					},
+				},
+			}},
+			expectedNotes: []string{},
+		},
+		{
+			name: "create a route for each host and rewrite the prefixes",
+			routes: []reverseProxyRoute{
+				{host: "a.example.com", path: "/api/", pathType: prefixPathType, backendHost: "api", backendPort: 8080, rewritePrefix: "/", tls: true},
+				{host: "b.example.com", path: "/", pathType: prefixPathType, backendHost: "web", backendPort: 80},
+			},
+			expected: []map[string]interface{}{
+				{
+					"apiVersion": "gateway.networking.k8s.io/v1beta1",
+					"kind":       "HTTPRoute",
+					"metadata":   map[string]interface{}{"name": "proxy-1"},
+					"spec": map[string]interface{}{
+						"parentRefs": []interface{}{map[string]interface{}{"name": "gateway"}},
+						"hostnames":  []string{"a.example.com"},
+						"rules": []interface{}{map[string]interface{}{
+							"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/api/"}}},
+							"backendRefs": []interface{}{map[string]interface{}{"name": "api", "port": int32(8080)}},
+							"filters": []interface{}{map[string]interface{}{
+								"type":       "URLRewrite",
+								"urlRewrite": map[string]interface{}{"path": map[string]interface{}{"type": "ReplacePrefixMatch", "replacePrefixMatch": "/"}},
+							}},
+						}},
+					},
+				},
+				{
+					"apiVersion": "gateway.networking.k8s.io/v1beta1",
+					"kind":       "HTTPRoute",
+					"metadata":   map[string]interface{}{"name": "proxy-2"},
+					"spec": map[string]interface{}{
+						"parentRefs": []interface{}{map[string]interface{}{"name": "gateway"}},
+						"hostnames":  []string{"b.example.com"},
+						"rules": []interface{}{map[string]interface{}{
+							"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/"}}},
+							"backendRefs": []interface{}{map[string]interface{}{"name": "web", "port": int32(80)}},
+						}},
+					},
+				},
+			},
+			expectedNotes: []string{"TLS for the host a.example.com needs to be configured in a listener of the gateway gateway"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			objs, notes := getReverseProxyHTTPRoutes("proxy", "gateway", testCase.routes)
+			if diff := cmp.Diff(testCase.expected, objs); diff != "" {
+				t.Fatalf("the HTTP routes are wrong. Difference:\n%s", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedNotes, notes); diff != "" {
+				t.Fatalf("the notes are wrong. Difference:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,570 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	nginxProxyType   = "nginx"
+	haproxyProxyType = "haproxy"
+	exactPathType    = "Exact"
+	prefixPathType   = "Prefix"
+)
+
+var (
+	// nginxPrefixRewriteRegex matches the rewrites that replace a prefix of the path, like rewrite ^/api/(.*)$ /$1 break;
+	nginxPrefixRewriteRegex = regexp.MustCompile(`^\^(/[^()*+?\[\]{}|\\$]*)\(\.\*\)\$?$`)
+	// handledNginxDirectives are the directives that are done by the ingress controller or have no effect in the cluster
+	handledNginxDirectives = []string{"listen", "server_name", "ssl_certificate", "ssl_certificate_key", "ssl_protocols", "ssl_ciphers", "ssl_prefer_server_ciphers", "ssl_session_cache", "ssl_session_timeout", "access_log", "error_log", "proxy_http_version"}
+	// handledNginxHeaders are the request headers that the ingress controllers set
+	handledNginxHeaders = []string{"host", "x-real-ip", "x-forwarded-for", "x-forwarded-proto", "x-forwarded-host", "upgrade", "connection"}
+	// handledHAProxyKeywords are the keywords that are done by the ingress controller or have no effect in the cluster
+	handledHAProxyKeywords = []string{"mode", "balance", "log", "timeout", "option forwardfor", "option httplog", "option http-server-close", "option http-keep-alive"}
+	haproxySections        = []string{"global", "defaults", "frontend", "backend", "listen", "resolvers", "userlist", "peers", "mailers", "cache", "program"}
+)
+
+// reverseProxyConfig stores the routing of an nginx or HAProxy config that could be converted
+type reverseProxyConfig struct {
+	path        string
+	proxyType   string
+	routes      []reverseProxyRoute
+	unconverted []string
+}
+
+// reverseProxyRoute routes the requests for a host and path to a backend
+type reverseProxyRoute struct {
+	host          string
+	path          string
+	pathType      string
+	backendHost   string
+	backendPort   int32
+	rewritePrefix string // the matched path prefix is replaced by this, if it is not empty
+	tls           bool
+}
+
+// isComplex returns true if the config has directives that could not be converted
+func (c reverseProxyConfig) isComplex() bool {
+	return len(c.unconverted) > 0
+}
+
+func (c *reverseProxyConfig) addUnconverted(line int, format string, args ...interface{}) {
+	c.unconverted = append(c.unconverted, fmt.Sprintf("%s:%d: ", c.path, line)+fmt.Sprintf(format, args...))
+}
+
+// nginxDirective is a simple or block directive of an nginx config
+type nginxDirective struct {
+	name     string
+	args     []string
+	line     int
+	hasBlock bool
+	block    []nginxDirective
+}
+
+// parseNginxDirectives parses the directives of an nginx config
+func parseNginxDirectives(contents string) ([]nginxDirective, error) {
+	tokens, err := tokenizeNginxConfig(contents)
+	if err != nil {
+		return nil, err
+	}
+	directives, rest, err := parseNginxBlock(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected '}' at line %d", rest[0].line)
+	}
+	return directives, nil
+}
+
+type nginxToken struct {
+	value  string
+	line   int
+	quoted bool
+}
+
+func tokenizeNginxConfig(contents string) ([]nginxToken, error) {
+	tokens := []nginxToken{}
+	line := 1
+	for i := 0; i < len(contents); i++ {
+		c := contents[i]
+		switch {
+		case c == '\n':
+			line++
+		case c == ' ' || c == '\t' || c == '\r':
+		case c == '#':
+			for i < len(contents) && contents[i] != '\n' {
+				i++
+			}
+			i--
+		case c == ';' || c == '{' || c == '}':
+			tokens = append(tokens, nginxToken{value: string(c), line: line})
+		case c == '"' || c == '\'':
+			start := line
+			value := strings.Builder{}
+			i++
+			for ; i < len(contents) && contents[i] != c; i++ {
+				if contents[i] == '\\' && i+1 < len(contents) {
+					i++
+				}
+				if contents[i] == '\n' {
+					line++
+				}
+				value.WriteByte(contents[i])
+			}
+			if i >= len(contents) {
+				return nil, fmt.Errorf("unterminated quote at line %d", start)
+			}
+			tokens = append(tokens, nginxToken{value: value.String(), line: start, quoted: true})
+		default:
+			start := i
+			for i < len(contents) && !strings.ContainsRune(" \t\r\n;{}#", rune(contents[i])) {
+				i++
+			}
+			tokens = append(tokens, nginxToken{value: contents[start:i], line: line})
+			i--
+		}
+	}
+	return tokens, nil
+}
+
+func parseNginxBlock(tokens []nginxToken) ([]nginxDirective, []nginxToken, error) {
+	directives := []nginxDirective{}
+	for len(tokens) > 0 {
+		if !tokens[0].quoted && tokens[0].value == "}" {
+			return directives, tokens, nil
+		}
+		directive := nginxDirective{name: tokens[0].value, line: tokens[0].line}
+		tokens = tokens[1:]
+		for {
+			if len(tokens) == 0 {
+				return nil, nil, fmt.Errorf("the directive %s at line %d is not terminated", directive.name, directive.line)
+			}
+			token := tokens[0]
+			tokens = tokens[1:]
+			if !token.quoted && token.value == ";" {
+				break
+			}
+			if !token.quoted && token.value == "{" {
+				block, rest, err := parseNginxBlock(tokens)
+				if err != nil {
+					return nil, nil, err
+				}
+				if len(rest) == 0 {
+					return nil, nil, fmt.Errorf("the block of the directive %s at line %d is not closed", directive.name, directive.line)
+				}
+				directive.hasBlock = true
+				directive.block = block
+				tokens = rest[1:]
+				break
+			}
+			directive.args = append(directive.args, token.value)
+		}
+		directives = append(directives, directive)
+	}
+	return directives, tokens, nil
+}
+
+// findNginxBlocks returns the block directives with the name, looking into the blocks that are not of the same name
+func findNginxBlocks(directives []nginxDirective, name string) []nginxDirective {
+	found := []nginxDirective{}
+	for _, directive := range directives {
+		if !directive.hasBlock {
+			continue
+		}
+		if directive.name == name {
+			found = append(found, directive)
+			continue
+		}
+		found = append(found, findNginxBlocks(directive.block, name)...)
+	}
+	return found
+}
+
+// parseNginxConfig converts the server blocks of an nginx config into routes
+func parseNginxConfig(path string) (reverseProxyConfig, error) {
+	config := reverseProxyConfig{path: path, proxyType: nginxProxyType}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	directives, err := parseNginxDirectives(string(contents))
+	if err != nil {
+		return config, fmt.Errorf("failed to parse the nginx config at path %s . Error: %q", path, err)
+	}
+	upstreams := map[string]string{}
+	for _, upstream := range findNginxBlocks(directives, "upstream") {
+		if len(upstream.args) == 0 {
+			continue
+		}
+		for _, server := range upstream.block {
+			if server.name == "server" && len(server.args) > 0 {
+				upstreams[upstream.args[0]] = server.args[0]
+				break
+			}
+		}
+	}
+	for _, server := range findNginxBlocks(directives, "server") {
+		config.addNginxServer(server, upstreams)
+	}
+	return config, nil
+}
+
+func (c *reverseProxyConfig) addNginxServer(server nginxDirective, upstreams map[string]string) {
+	hosts := []string{}
+	tls := false
+	for _, directive := range server.block {
+		switch directive.name {
+		case "server_name":
+			for _, host := range directive.args {
+				if strings.HasPrefix(host, "~") {
+					c.addUnconverted(directive.line, "the regex server name %s is not supported", host)
+					continue
+				}
+				if host != "_" && host != "" && host != "localhost" {
+					hosts = append(hosts, host)
+				}
+			}
+		case "listen":
+			for _, arg := range directive.args {
+				if arg == "ssl" {
+					tls = true
+				}
+			}
+		case "ssl_certificate":
+			tls = true
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	}
+	routes := []reverseProxyRoute{}
+	for _, directive := range server.block {
+		switch {
+		case directive.name == "location" && directive.hasBlock:
+			routes = append(routes, c.getNginxLocationRoutes(directive, upstreams)...)
+		case directive.name == "return" && len(directive.args) == 2 && strings.HasPrefix(directive.args[1], "https://"):
+			// redirects to https are done by the ingress controller for the hosts with tls
+		case len(directive.args) > 0 && directive.name == "proxy_set_header" && isHandledNginxHeader(directive.args[0]):
+		case !isHandledNginxDirective(directive.name):
+			c.addUnconverted(directive.line, "the directive %s is not supported", directive.name)
+		}
+	}
+	for _, host := range hosts {
+		for _, route := range routes {
+			route.host = host
+			route.tls = tls
+			c.routes = append(c.routes, route)
+		}
+	}
+}
+
+func (c *reverseProxyConfig) getNginxLocationRoutes(location nginxDirective, upstreams map[string]string) []reverseProxyRoute {
+	route := reverseProxyRoute{pathType: prefixPathType}
+	switch {
+	case len(location.args) == 1:
+		route.path = location.args[0]
+	case len(location.args) == 2 && (location.args[0] == "=" || location.args[0] == "^~"):
+		route.path = location.args[1]
+		if location.args[0] == "=" {
+			route.pathType = exactPathType
+		}
+	default:
+		c.addUnconverted(location.line, "the regex location %s is not supported", strings.Join(location.args, " "))
+		return nil
+	}
+	if strings.HasPrefix(route.path, "@") {
+		c.addUnconverted(location.line, "the named location %s is not supported", route.path)
+		return nil
+	}
+	proxied := false
+	for _, directive := range location.block {
+		switch {
+		case directive.name == "proxy_pass" && len(directive.args) == 1:
+			if strings.Contains(directive.args[0], "$") {
+				c.addUnconverted(directive.line, "the proxy_pass %s with variables is not supported", directive.args[0])
+				return nil
+			}
+			passURL, err := url.Parse(directive.args[0])
+			if err != nil || passURL.Host == "" {
+				c.addUnconverted(directive.line, "the proxy_pass %s is not supported", directive.args[0])
+				return nil
+			}
+			backend := passURL.Host
+			if upstream, ok := upstreams[backend]; ok {
+				backend = upstream
+			}
+			route.backendHost, route.backendPort = splitHostPort(backend, passURL.Scheme)
+			if passURL.Path != "" {
+				route.rewritePrefix = passURL.Path
+			}
+			proxied = true
+		case directive.name == "rewrite" && len(directive.args) >= 2:
+			matches := nginxPrefixRewriteRegex.FindStringSubmatch(directive.args[0])
+			if matches == nil || !strings.HasSuffix(directive.args[1], "$1") || (len(directive.args) == 3 && directive.args[2] != "break" && directive.args[2] != "last") || len(directive.args) > 3 {
+				c.addUnconverted(directive.line, "the rewrite %s is not supported", strings.Join(directive.args, " "))
+				continue
+			}
+			if strings.TrimSuffix(matches[1], "/") != strings.TrimSuffix(route.path, "/") {
+				c.addUnconverted(directive.line, "the rewrite %s does not match the prefix of the location %s", strings.Join(directive.args, " "), route.path)
+				continue
+			}
+			route.rewritePrefix = strings.TrimSuffix(directive.args[1], "$1")
+		case len(directive.args) > 0 && directive.name == "proxy_set_header" && isHandledNginxHeader(directive.args[0]):
+		case !isHandledNginxDirective(directive.name):
+			c.addUnconverted(directive.line, "the directive %s in the location %s is not supported", directive.name, route.path)
+		}
+	}
+	if !proxied {
+		c.addUnconverted(location.line, "the location %s does not proxy to a backend", route.path)
+		return nil
+	}
+	return []reverseProxyRoute{route}
+}
+
+func isHandledNginxDirective(name string) bool {
+	for _, handled := range handledNginxDirectives {
+		if name == handled {
+			return true
+		}
+	}
+	return false
+}
+
+func isHandledNginxHeader(header string) bool {
+	for _, handled := range handledNginxHeaders {
+		if strings.EqualFold(header, handled) {
+			return true
+		}
+	}
+	return false
+}
+
+// haproxyACL stores the host and path that an acl of HAProxy matches
+type haproxyACL struct {
+	host     string
+	path     string
+	pathType string
+}
+
+// parseHAProxyConfig converts the frontends and listen sections of an HAProxy config into routes
+func parseHAProxyConfig(path string) (reverseProxyConfig, error) {
+	config := reverseProxyConfig{path: path, proxyType: haproxyProxyType}
+	file, err := os.Open(path)
+	if err != nil {
+		return config, err
+	}
+	defer file.Close()
+	type haproxyLine struct {
+		fields []string
+		line   int
+	}
+	type haproxySection struct {
+		kind  string
+		name  string
+		lines []haproxyLine
+	}
+	sections := []haproxySection{}
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if isHAProxySection(fields[0]) {
+			section := haproxySection{kind: fields[0]}
+			if len(fields) > 1 {
+				section.name = fields[1]
+			}
+			sections = append(sections, section)
+			continue
+		}
+		if len(sections) == 0 {
+			continue
+		}
+		sections[len(sections)-1].lines = append(sections[len(sections)-1].lines, haproxyLine{fields: fields, line: lineNum})
+	}
+	if err := scanner.Err(); err != nil {
+		return config, err
+	}
+	backends := map[string]string{}
+	for _, section := range sections {
+		if section.kind != "backend" && section.kind != "listen" {
+			continue
+		}
+		for _, line := range section.lines {
+			if line.fields[0] == "server" && len(line.fields) > 2 {
+				backends[section.name] = line.fields[2]
+				break
+			}
+		}
+	}
+	for _, section := range sections {
+		if section.kind != "frontend" && section.kind != "listen" && section.kind != "backend" {
+			continue
+		}
+		acls := map[string]haproxyACL{}
+		tls := false
+		routes := []reverseProxyRoute{}
+		addRoute := func(acl haproxyACL, backendName string, line int) {
+			backend, ok := backends[backendName]
+			if !ok {
+				config.addUnconverted(line, "the backend %s does not have any servers", backendName)
+				return
+			}
+			route := reverseProxyRoute{host: acl.host, path: acl.path, pathType: acl.pathType}
+			if route.path == "" {
+				route.path = "/"
+				route.pathType = prefixPathType
+			}
+			route.backendHost, route.backendPort = splitHostPort(backend, "http")
+			routes = append(routes, route)
+		}
+		for _, line := range section.lines {
+			fields := line.fields
+			switch {
+			case fields[0] == "bind":
+				for _, field := range fields[1:] {
+					if field == "ssl" || field == "crt" {
+						tls = true
+					}
+				}
+			case fields[0] == "acl" && len(fields) >= 4:
+				acl, ok := getHAProxyACL(fields[2:])
+				if !ok {
+					config.addUnconverted(line.line, "the acl %s is not supported", strings.Join(fields[1:], " "))
+					continue
+				}
+				existing := acls[fields[1]]
+				if acl.host == "" {
+					acl.host = existing.host
+				}
+				if acl.path == "" {
+					acl.path, acl.pathType = existing.path, existing.pathType
+				}
+				acls[fields[1]] = acl
+			case fields[0] == "use_backend" && len(fields) >= 4 && (fields[2] == "if" || fields[2] == "unless"):
+				if fields[2] == "unless" {
+					config.addUnconverted(line.line, "the condition %s is not supported", strings.Join(fields[2:], " "))
+					continue
+				}
+				combined, ok := haproxyACL{}, true
+				for _, name := range fields[3:] {
+					acl, found := acls[name]
+					if !found {
+						config.addUnconverted(line.line, "the condition %s is not supported", strings.Join(fields[3:], " "))
+						ok = false
+						break
+					}
+					if acl.host != "" {
+						combined.host = acl.host
+					}
+					if acl.path != "" {
+						combined.path, combined.pathType = acl.path, acl.pathType
+					}
+				}
+				if ok {
+					addRoute(combined, fields[1], line.line)
+				}
+			case fields[0] == "default_backend" && len(fields) == 2:
+				addRoute(haproxyACL{}, fields[1], line.line)
+			case fields[0] == "server" && section.kind == "listen":
+			case fields[0] == "server" && section.kind == "backend":
+			case strings.HasPrefix(strings.Join(fields, " "), "http-request redirect scheme https") || strings.HasPrefix(strings.Join(fields, " "), "redirect scheme https"):
+				// redirects to https are done by the ingress controller for the hosts with tls
+			case isHandledHAProxyKeyword(fields):
+			default:
+				config.addUnconverted(line.line, "the keyword %s in the %s %s is not supported", strings.Join(fields, " "), section.kind, section.name)
+			}
+		}
+		if section.kind == "listen" && len(routes) == 0 {
+			addRoute(haproxyACL{}, section.name, 0)
+		}
+		for _, route := range routes {
+			route.tls = tls
+			config.routes = append(config.routes, route)
+		}
+	}
+	return config, nil
+}
+
+// getHAProxyACL returns the host or path matched by the criterion of an acl
+func getHAProxyACL(criterion []string) (haproxyACL, bool) {
+	values := []string{}
+	for _, value := range criterion[1:] {
+		if !strings.HasPrefix(value, "-") {
+			values = append(values, value)
+		}
+	}
+	if len(values) != 1 {
+		return haproxyACL{}, false
+	}
+	switch criterion[0] {
+	case "hdr(host)", "hdr_dom(host)", "req.hdr(host)":
+		return haproxyACL{host: strings.Split(values[0], ":")[0]}, true
+	case "path_beg":
+		return haproxyACL{path: values[0], pathType: prefixPathType}, true
+	case "path":
+		return haproxyACL{path: values[0], pathType: exactPathType}, true
+	}
+	return haproxyACL{}, false
+}
+
+func isHAProxySection(keyword string) bool {
+	for _, section := range haproxySections {
+		if keyword == section {
+			return true
+		}
+	}
+	return false
+}
+
+func isHandledHAProxyKeyword(fields []string) bool {
+	line := strings.Join(fields, " ")
+	for _, handled := range handledHAProxyKeywords {
+		if line == handled || strings.HasPrefix(line, handled+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitHostPort splits the address of a backend into the host and port, using the default port of the scheme if there is no port
+func splitHostPort(address, scheme string) (string, int32) {
+	port := int32(80)
+	if scheme == "https" {
+		port = 443
+	}
+	host := address
+	if idx := strings.LastIndex(address, ":"); idx >= 0 {
+		if p, err := strconv.ParseInt(address[idx+1:], 10, 32); err == nil {
+			host, port = address[:idx], int32(p)
+		}
+	}
+	return host, port
+}
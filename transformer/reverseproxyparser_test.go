@@ -0,0 +1,291 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeReverseProxyTestConfig writes the contents to a file in a temporary directory and returns its path
+func writeReverseProxyTestConfig(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write the config to the path %s . Error: %q", path, err)
+	}
+	return path
+}
+
+// getUnconvertedWithoutPath returns the directives that could not be converted without the path of the config
+func getUnconvertedWithoutPath(config reverseProxyConfig, path string) []string {
+	unconverted := []string{}
+	for _, u := range config.unconverted {
+		unconverted = append(unconverted, strings.TrimPrefix(u, path+":"))
+	}
+	return unconverted
+}
+
+func TestParseNginxConfig(t *testing.T) {
+	testCases := []struct {
+		name                string
+		config              string
+		expectedRoutes      []reverseProxyRoute
+		expectedUnconverted []string
+	}{
+		{
+			name: "proxy a location to a backend with tls",
+			config: `server {
+    listen 443 ssl;
+    server_name example.com;
+    ssl_certificate /etc/ssl/example.crt;
+    location /api/ {
+        proxy_pass http://api:8080/;
+        proxy_set_header Host $host;
+    }
+}`,
+			expectedRoutes: []reverseProxyRoute{
+				{host: "example.com", path: "/api/", pathType: prefixPathType, backendHost: "api", backendPort: 8080, rewritePrefix: "/", tls: true},
+			},
+			expectedUnconverted: []string{},
+		},
+		{
+			name: "resolve the upstreams and the exact locations",
+			config: `http {
+    upstream backend {
+        server web:9000;
+        server web2:9000;
+    }
+    server {
+        location = /health {
+            proxy_pass http://backend;
+        }
+        location ^~ /static {
+            proxy_pass https://static;
+        }
+    }
+}`,
+			expectedRoutes: []reverseProxyRoute{
+				{path: "/health", pathType: exactPathType, backendHost: "web", backendPort: 9000},
+				{path: "/static", pathType: prefixPathType, backendHost: "static", backendPort: 443},
+			},
+			expectedUnconverted: []string{},
+		},
+		{
+			name: "convert the rewrites of the location prefix",
+			config: `server {
+    server_name a.example.com b.example.com;
+    location /api/ {
+        rewrite ^/api/(.*)$ /v1/$1 break;
+        proxy_pass http://api;
+    }
+}`,
+			expectedRoutes: []reverseProxyRoute{
+				{host: "a.example.com", path: "/api/", pathType: prefixPathType, backendHost: "api", backendPort: 80, rewritePrefix: "/v1/"},
+				{host: "b.example.com", path: "/api/", pathType: prefixPathType, backendHost: "api", backendPort: 80, rewritePrefix: "/v1/"},
+			},
+			expectedUnconverted: []string{},
+		},
+		{
+			name: "report the directives that cannot be converted",
+			config: `server {
+    server_name ~^www\d+\.example\.com$;
+    gzip on;
+    location ~ \.php$ {
+        proxy_pass http://php;
+    }
+    location @fallback {
+        proxy_pass http://fallback;
+    }
+    location /app {
+        proxy_pass http://$backend;
+    }
+    location /other {
+        rewrite ^/different/(.*)$ /$1 break;
+        proxy_pass http://other;
+    }
+    location /files {
+        root /var/www;
+    }
+}`,
+			expectedRoutes: []reverseProxyRoute{
+				{path: "/other", pathType: prefixPathType, backendHost: "other", backendPort: 80},
+			},
+			expectedUnconverted: []string{
+				`2: the regex server name ~^www\d+\.example\.com$ is not supported`,
+				"3: the directive gzip is not supported",
+				`4: the regex location ~ \.php$ is not supported`,
+				"7: the named location @fallback is not supported",
+				"11: the proxy_pass http://$backend with variables is not supported",
+				"14: the rewrite ^/different/(.*)$ /$1 break does not match the prefix of the location /other",
+				"18: the directive root in the location /files is not supported",
+				"17: the location /files does not proxy to a backend",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := writeReverseProxyTestConfig(t, "nginx.conf", testCase.config)
+			config, err := parseNginxConfig(path)
+			if err != nil {
+				t.Fatalf("failed to parse the nginx config. Error: %q", err)
+			}
+			if diff := cmp.Diff(testCase.expectedRoutes, config.routes, cmp.AllowUnexported(reverseProxyRoute{})); diff != "" {
+				t.Fatalf("the routes are wrong. Difference:\n%s", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedUnconverted, getUnconvertedWithoutPath(config, path)); diff != "" {
+				t.Fatalf("the directives that could not be converted are wrong. Difference:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseNginxConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+	}{
+		{name: "unterminated quote", config: "server {\n    add_header X \"value;\n}\n"},
+		{name: "unterminated directive", config: "server {\n    listen 80\n"},
+		{name: "unclosed block", config: "server {\n    listen 80;\n"},
+		{name: "unexpected closing brace", config: "listen 80;\n}\n"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := writeReverseProxyTestConfig(t, "nginx.conf", testCase.config)
+			if _, err := parseNginxConfig(path); err == nil {
+				t.Fatalf("expected the nginx config to fail to parse")
+			}
+		})
+	}
+}
+
+func TestParseHAProxyConfig(t *testing.T) {
+	testCases := []struct {
+		name                string
+		config              string
+		expectedRoutes      []reverseProxyRoute
+		expectedUnconverted []string
+	}{
+		{
+			name: "route the acls of a frontend to the backends",
+			config: `defaults
+    mode http
+    timeout connect 5s
+frontend main
+    bind *:443 ssl crt /etc/ssl/site.pem
+    acl is_api hdr(host) -i api.example.com:443
+    acl api_path path_beg /api
+    acl health path /health
+    use_backend api if is_api api_path
+    use_backend api if health
+    default_backend web
+backend api
+    balance roundrobin
+    server api1 api:8080 check
+    server api2 api2:8080 check
+backend web
+    server web1 web:80
+`,
+			expectedRoutes: []reverseProxyRoute{
+				{host: "api.example.com", path: "/api", pathType: prefixPathType, backendHost: "api", backendPort: 8080, tls: true},
+				{path: "/health", pathType: exactPathType, backendHost: "api", backendPort: 8080, tls: true},
+				{path: "/", pathType: prefixPathType, backendHost: "web", backendPort: 80, tls: true},
+			},
+			expectedUnconverted: []string{},
+		},
+		{
+			name: "route a listen section to its servers",
+			config: `listen app # the app
+    bind *:80
+    server app1 app:3000
+`,
+			expectedRoutes: []reverseProxyRoute{
+				{path: "/", pathType: prefixPathType, backendHost: "app", backendPort: 3000},
+			},
+			expectedUnconverted: []string{},
+		},
+		{
+			name: "report the keywords that cannot be converted",
+			config: `frontend main
+    bind *:80
+    acl is_admin src 10.0.0.0/8
+    acl is_api path_beg /api
+    use_backend api unless is_api
+    use_backend api if missing
+    use_backend empty if is_api
+    stick-table type ip size 1m
+backend api
+    server api1 api:8080
+backend empty
+`,
+			expectedRoutes: []reverseProxyRoute{},
+			expectedUnconverted: []string{
+				"3: the acl is_admin src 10.0.0.0/8 is not supported",
+				"5: the condition unless is_api is not supported",
+				"6: the condition missing is not supported",
+				"7: the backend empty does not have any servers",
+				"8: the keyword stick-table type ip size 1m in the frontend main is not supported",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := writeReverseProxyTestConfig(t, "haproxy.cfg", testCase.config)
+			config, err := parseHAProxyConfig(path)
+			if err != nil {
+				t.Fatalf("failed to parse the HAProxy config. Error: %q", err)
+			}
+			routes := config.routes
+			if routes == nil {
+				routes = []reverseProxyRoute{}
+			}
+			if diff := cmp.Diff(testCase.expectedRoutes, routes, cmp.AllowUnexported(reverseProxyRoute{})); diff != "" {
+				t.Fatalf("the routes are wrong. Difference:\n%s", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedUnconverted, getUnconvertedWithoutPath(config, path)); diff != "" {
+				t.Fatalf("the keywords that could not be converted are wrong. Difference:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSplitHostPort(t *testing.T) {
+	testCases := []struct {
+		address      string
+		scheme       string
+		expectedHost string
+		expectedPort int32
+	}{
+		{address: "api:8080", scheme: "http", expectedHost: "api", expectedPort: 8080},
+		{address: "api", scheme: "http", expectedHost: "api", expectedPort: 80},
+		{address: "api", scheme: "https", expectedHost: "api", expectedPort: 443},
+		{address: "api:http", scheme: "http", expectedHost: "api:http", expectedPort: 80},
+		{address: "10.0.0.1:9000", scheme: "https", expectedHost: "10.0.0.1", expectedPort: 9000},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.address, func(t *testing.T) {
+			host, port := splitHostPort(testCase.address, testCase.scheme)
+			if host != testCase.expectedHost || port != testCase.expectedPort {
+				t.Fatalf("failed to split the address. Expected: %s %d Actual: %s %d", testCase.expectedHost, testCase.expectedPort, host, port)
+			}
+		})
+	}
+}
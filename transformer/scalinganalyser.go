@@ -0,0 +1,366 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	sessionLocalState = "session"
+	cacheLocalState   = "cache"
+	uploadsLocalState = "uploads"
+
+	sessionAffinityMitigation = "sessionaffinity"
+	storageMitigation         = "storage"
+	singleReplicaMitigation   = "singlereplica"
+
+	defaultScalingReportDir   = common.DeployDir + string(os.PathSeparator) + "scaling"
+	scalingReportFileName     = "report.txt"
+	defaultUploadsPath        = "/app/uploads"
+	maxLocalStateScanFileSize = 1024 * 1024
+)
+
+var (
+	localStateScanExts = []string{".js", ".mjs", ".ts", ".py", ".php", ".ini", ".java", ".kt", ".properties", ".yml", ".yaml", ".xml", ".rb", ".cs", ".config", ".json", ".go"}
+	localStateSkipDirs = []string{"node_modules", "vendor", "target", "build", "dist", "bin", "obj", "__pycache__"}
+	// localStateRules are the red flags for local state that keep a service from being scaled horizontally.
+	// A rule is ignored when any of the files of the service matches its unless regex, like a clustered store.
+	localStateRules = []localStateRule{
+		{kind: sessionLocalState, regex: `session-file-store`, description: "sessions are stored in files", suggestion: "Store the sessions in Redis using connect-redis"},
+		{kind: sessionLocalState, regex: `require\(\s*['"]express-session['"]\s*\)|from\s+['"]express-session['"]`, unless: `connect-redis|connect-mongo|connect-pg-simple|connect-session-sequelize|connect-memcached|connect-dynamodb`, description: "sessions are stored in the memory of the process (express-session MemoryStore)", suggestion: "Store the sessions in Redis using connect-redis"},
+		{kind: sessionLocalState, regex: `session\.save_handler\s*=\s*"?files|session_start\s*\(`, unless: `session\.save_handler\s*=\s*"?(?:redis|rediscluster|memcached)`, description: "PHP sessions are stored in files", suggestion: "Set session.save_handler to redis using the phpredis extension"},
+		{kind: sessionLocalState, regex: `django\.contrib\.sessions\.backends\.(?:file|cache)\b`, unless: `django_redis|django\.core\.cache\.backends\.(?:redis|memcached)`, description: "Django sessions are stored in files or the local cache", suggestion: "Use the db or cached_db session engine, or a Redis cache"},
+		{kind: sessionLocalState, regex: `SESSION_TYPE['"]?\]?\s*[=:]\s*['"]filesystem`, description: "Flask sessions are stored in files", suggestion: "Set SESSION_TYPE to redis"},
+		{kind: sessionLocalState, regex: `\bHttpSession\b|\.getSession\s*\(`, unless: `spring-session|spring\.session\.store-type|hazelcast|infinispan|redisson`, description: "servlet sessions are stored in the memory of the server", suggestion: "Replicate the sessions using Spring Session with Redis"},
+		{kind: sessionLocalState, regex: `sessionState\s+mode\s*=\s*"InProc"|AddDistributedMemoryCache\s*\(`, description: "ASP.NET sessions are stored in the memory of the process", suggestion: "Store the sessions in Redis using AddStackExchangeRedisCache"},
+		{kind: cacheLocalState, regex: `require\(\s*['"](?:node-cache|memory-cache|lru-cache)['"]\s*\)|from\s+['"](?:node-cache|memory-cache|lru-cache)['"]`, description: "data is cached in the memory of the process", suggestion: "Move the shared cache to Redis"},
+		{kind: cacheLocalState, regex: `django\.core\.cache\.backends\.(?:locmem|filebased)|CACHE_TYPE['"]?\]?\s*[=:]\s*['"](?:simple|SimpleCache|filesystem|FileSystemCache)`, description: "the cache is local to each process", suggestion: "Use a Redis or memcached cache backend"},
+		{kind: cacheLocalState, regex: `\bCaffeine\.newBuilder\b|\bCacheBuilder\.newBuilder\b|\bConcurrentMapCacheManager\b|\behcache\b`, unless: `terracotta|hazelcast|infinispan|redisson|spring-boot-starter-data-redis`, description: "the cache is local to each JVM", suggestion: "Use a Redis cache or a clustered cache like Hazelcast or Infinispan"},
+		{kind: cacheLocalState, regex: `cache_store\s*[=,]\s*:(?:memory_store|file_store)`, description: "the Rails cache is local to each process", suggestion: "Use the redis_cache_store"},
+		{kind: cacheLocalState, regex: `\bAddMemoryCache\s*\(|\bIMemoryCache\b`, description: "data is cached in the memory of the process", suggestion: "Use IDistributedCache with Redis"},
+		{kind: uploadsLocalState, regex: `multer\(\s*\{\s*dest\s*:\s*['"]([^'"]+)['"]|multer\.diskStorage`, description: "uploads are stored on the local disk", suggestion: "Store the uploads in an object store"},
+		{kind: uploadsLocalState, regex: `(?:UPLOAD_FOLDER|MEDIA_ROOT)['"]?\]?\s*=\s*['"]([^'"]+)['"]|\bUPLOAD_FOLDER\b|\bMEDIA_ROOT\b`, description: "uploads are stored on the local disk", suggestion: "Store the uploads in an object store, like using django-storages"},
+		{kind: uploadsLocalState, regex: `move_uploaded_file\s*\(|upload_tmp_dir\s*=\s*"?([^"\s;]+)`, description: "uploads are stored on the local disk", suggestion: "Store the uploads in an object store"},
+		{kind: uploadsLocalState, regex: `\.transferTo\s*\(\s*new\s+File\s*\(\s*"([^"]+)"|\.transferTo\s*\(`, description: "uploads are stored on the local disk", suggestion: "Store the uploads in an object store"},
+		{kind: uploadsLocalState, regex: `service:\s*Disk`, description: "Active Storage stores the uploads on the local disk", suggestion: "Use the S3, GCS or Azure Active Storage service"},
+	}
+)
+
+// localStateRule is a pattern in the source code that indicates that a service keeps local state
+type localStateRule struct {
+	kind        string
+	regex       string
+	unless      string
+	description string
+	suggestion  string
+	compiled    *regexp.Regexp
+	compiledNot *regexp.Regexp
+}
+
+// localStateFinding is a match of a local state rule in a file of a service
+type localStateFinding struct {
+	rule    *localStateRule
+	path    string
+	line    int
+	capture string
+}
+
+// ScalingAnalyser implements Transformer interface
+type ScalingAnalyser struct {
+	Config        transformertypes.Transformer
+	Env           *environment.Environment
+	ScalingConfig *ScalingYamlConfig
+}
+
+// ScalingYamlConfig stores the yaml configuration for the scaling transformer
+type ScalingYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+}
+
+// Init Initializes the transformer
+func (t *ScalingAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.ScalingConfig = &ScalingYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.ScalingConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.ScalingConfig, err)
+		return err
+	}
+	if t.ScalingConfig.OutputPath == "" {
+		t.ScalingConfig.OutputPath = defaultScalingReportDir
+	}
+	for i := range localStateRules {
+		localStateRules[i].compiled = regexp.MustCompile(localStateRules[i].regex)
+		if localStateRules[i].unless != "" {
+			localStateRules[i].compiledNot = regexp.MustCompile(localStateRules[i].unless)
+		}
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *ScalingAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *ScalingAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform looks for local state in the services and either mitigates it, using session affinity and storage,
+// or limits the service to a single replica. The findings and the mitigations are written to a report.
+func (t *ScalingAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		inferReplicasFromSource(&ir, t.Env.GetEnvironmentSource())
+		serviceNames := []string{}
+		for sn := range ir.Services {
+			serviceNames = append(serviceNames, sn)
+		}
+		sort.Strings(serviceNames)
+		report := strings.Builder{}
+		for _, sn := range serviceNames {
+			s := ir.Services[sn]
+			findings := getLocalStateFindings(ir, s)
+			if len(findings) == 0 {
+				continue
+			}
+			kinds := map[string]bool{}
+			for _, finding := range findings {
+				kinds[finding.rule.kind] = true
+			}
+			options := []string{singleReplicaMitigation}
+			defaults := []string{}
+			hints := []string{fmt.Sprintf("Select %s to run a single replica of the service", singleReplicaMitigation)}
+			if kinds[sessionLocalState] || kinds[cacheLocalState] {
+				options = append(options, sessionAffinityMitigation)
+				defaults = append(defaults, sessionAffinityMitigation)
+				hints = append(hints, fmt.Sprintf("Select %s to send the requests of each client to the same pod", sessionAffinityMitigation))
+			}
+			if kinds[uploadsLocalState] {
+				options = append(options, storageMitigation)
+				defaults = append(defaults, storageMitigation)
+				hints = append(hints, fmt.Sprintf("Select %s to store the uploads in a volume that is shared by the pods", storageMitigation))
+			}
+			qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`)
+			mitigations := qaengine.FetchMultiSelectAnswer(
+				common.JoinQASubKeys(qaKeyPrefix, common.ConfigScalingMitigationsKeySegment),
+				fmt.Sprintf("The service %s keeps local state (%s). Select how to handle it when scaling the service:", sn, strings.Join(getSortedKeys(kinds), ", ")),
+				hints,
+				defaults,
+				options,
+			)
+			report.WriteString(fmt.Sprintf("Service %s:\n", sn))
+			for _, finding := range findings {
+				report.WriteString(fmt.Sprintf("  - %s:%d : %s. %s.\n", finding.path, finding.line, finding.rule.description, finding.rule.suggestion))
+			}
+			if common.IsStringPresent(mitigations, singleReplicaMitigation) {
+				s.MaxReplicas = 1
+				report.WriteString("  The service runs a single replica, since the local state is not shared between replicas.\n")
+			}
+			if common.IsStringPresent(mitigations, sessionAffinityMitigation) {
+				s.SessionAffinity = true
+				report.WriteString("  The service uses ClientIP session affinity. Clients behind the same NAT or proxy all go to the same pod, and the state is lost when the pod restarts.\n")
+			}
+			if common.IsStringPresent(mitigations, storageMitigation) {
+				uploadsPath := defaultUploadsPath
+				for _, finding := range findings {
+					if finding.rule.kind == uploadsLocalState && finding.capture != "" {
+						uploadsPath = finding.capture
+						if !filepath.IsAbs(uploadsPath) {
+							uploadsPath = filepath.Join(filepath.Dir(defaultUploadsPath), uploadsPath)
+						}
+						break
+					}
+				}
+				uploadsPath = qaengine.FetchStringAnswer(
+					common.JoinQASubKeys(qaKeyPrefix, common.ConfigUploadsPathKeySegment),
+					fmt.Sprintf("Enter the path in the container where the service %s stores its uploads:", sn),
+					[]string{"A ReadWriteMany volume is mounted at the path"},
+					uploadsPath,
+				)
+				addUploadsStorage(&ir, &s, uploadsPath)
+				report.WriteString(fmt.Sprintf("  A ReadWriteMany volume is mounted at %s for the uploads.\n", uploadsPath))
+			}
+			if len(mitigations) == 0 {
+				report.WriteString("  The local state was not mitigated, so the replicas of the service do not share it.\n")
+			}
+			report.WriteString("\n")
+			ir.Services[sn] = s
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+		if report.Len() == 0 {
+			continue
+		}
+		reportPath := filepath.Join(t.Env.TempPath, t.ScalingConfig.OutputPath, scalingReportFileName)
+		if err := os.MkdirAll(filepath.Dir(reportPath), common.DefaultDirectoryPermission); err != nil {
+			logrus.Errorf("failed to create the directory %s for the scaling report. Error: %q", filepath.Dir(reportPath), err)
+			continue
+		}
+		if err := os.WriteFile(reportPath, []byte(report.String()), common.DefaultFilePermission); err != nil {
+			logrus.Errorf("failed to write the scaling report to the file at path %s . Error: %q", reportPath, err)
+			continue
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  reportPath,
+			DestPath: filepath.Join(t.ScalingConfig.OutputPath, scalingReportFileName),
+		})
+	}
+	return pathMappings, artifactsCreated, nil
+}
+
+// getLocalStateFindings scans the source files in the build contexts of the images of the service for local state
+func getLocalStateFindings(ir irtypes.IR, service irtypes.Service) []localStateFinding {
+	findings := []localStateFinding{}
+	suppressed := map[*localStateRule]bool{}
+	seenContexts := map[string]bool{}
+	for _, container := range service.Containers {
+		image, ok := ir.ContainerImages[container.Image]
+		if !ok || image.Build.ContextPath == "" || seenContexts[image.Build.ContextPath] {
+			continue
+		}
+		contextPath := image.Build.ContextPath
+		seenContexts[contextPath] = true
+		err := filepath.Walk(contextPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if path != contextPath && (strings.HasPrefix(info.Name(), ".") || common.IsStringPresent(localStateSkipDirs, info.Name())) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.Size() > maxLocalStateScanFileSize || !common.IsStringPresent(localStateScanExts, filepath.Ext(path)) {
+				return nil
+			}
+			relPath, err := filepath.Rel(contextPath, path)
+			if err != nil {
+				relPath = path
+			}
+			findings = append(findings, scanFileForLocalState(path, relPath, suppressed)...)
+			return nil
+		})
+		if err != nil {
+			logrus.Errorf("failed to walk the build context %s for local state. Error: %q", contextPath, err)
+		}
+	}
+	filtered := []localStateFinding{}
+	for _, finding := range findings {
+		if !suppressed[finding.rule] {
+			filtered = append(filtered, finding)
+		}
+	}
+	return filtered
+}
+
+// scanFileForLocalState returns the first match of each rule in the file and marks the rules whose unless regex matches
+func scanFileForLocalState(path, relPath string, suppressed map[*localStateRule]bool) []localStateFinding {
+	file, err := os.Open(path)
+	if err != nil {
+		logrus.Debugf("failed to open the file at path %s . Error: %q", path, err)
+		return nil
+	}
+	defer file.Close()
+	findings := []localStateFinding{}
+	matched := map[*localStateRule]bool{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLocalStateScanFileSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		for i := range localStateRules {
+			rule := &localStateRules[i]
+			if rule.compiledNot != nil && rule.compiledNot.MatchString(line) {
+				suppressed[rule] = true
+			}
+			if matched[rule] {
+				continue
+			}
+			matches := rule.compiled.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			matched[rule] = true
+			finding := localStateFinding{rule: rule, path: relPath, line: lineNumber}
+			for _, capture := range matches[1:] {
+				if capture != "" {
+					finding.capture = capture
+					break
+				}
+			}
+			findings = append(findings, finding)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logrus.Debugf("failed to read the file at path %s . Error: %q", path, err)
+	}
+	return findings
+}
+
+// addUploadsStorage mounts a ReadWriteMany volume at the uploads path in the containers of the service
+func addUploadsStorage(ir *irtypes.IR, service *irtypes.Service, uploadsPath string) {
+	claimName := common.NormalizeForMetadataName(service.Name + "-uploads")
+	service.AddVolume(core.Volume{
+		Name: claimName,
+		VolumeSource: core.VolumeSource{
+			PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+		},
+	})
+	for i := range service.Containers {
+		service.Containers[i].VolumeMounts = append(service.Containers[i].VolumeMounts, core.VolumeMount{Name: claimName, MountPath: uploadsPath})
+	}
+	ir.AddStorage(irtypes.Storage{
+		Name:                      claimName,
+		StorageType:               irtypes.PVCKind,
+		PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteMany}},
+	})
+}
+
+func getSortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,136 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestScalingAnalyserTransform(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.services."legacy".scalingmitigations=["singlereplica"]`,
+	}, nil, nil, false, false)
+
+	sourceDir := t.TempDir()
+	files := map[string]string{
+		"cart/server.js":                "const session = require('express-session');\napp.use(session({ secret: 'x' }));\n",
+		"cart/node_modules/lib/a.js":    "const cache = require('node-cache');\n",
+		"redis-cart/server.js":          "const session = require('express-session');\nconst RedisStore = require('connect-redis')(session);\n",
+		"media/settings.py":             "DEBUG = False\nUPLOAD_FOLDER = 'static/uploads'\n",
+		"legacy/src/Cache.java":         "Cache<String, Object> cache = Caffeine.newBuilder().build();\n",
+		"stateless/index.js":            "app.get('/', (req, res) => res.send('ok'));\n",
+		"stateless/README.md":           "express-session is not used\n",
+		"legacy/src/main/Unrelated.txt": "HttpSession\n",
+	}
+	for path, contents := range files {
+		path = filepath.Join(sourceDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	ir := irtypes.NewIR()
+	for _, sn := range []string{"cart", "redis-cart", "media", "legacy", "stateless"} {
+		ir.ContainerImages[sn+":latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{ContextPath: filepath.Join(sourceDir, sn)}}
+		s := irtypes.NewServiceWithName(sn)
+		s.Containers = []core.Container{{Name: sn, Image: sn + ":latest"}}
+		ir.Services[sn] = s
+	}
+
+	envInfo := environment.EnvInfo{Name: "test", TempPath: t.TempDir()}
+	local, err := environment.NewLocal(envInfo, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	scalingAnalyser := &ScalingAnalyser{}
+	if err := scalingAnalyser.Init(transformertypes.Transformer{}, &environment.Environment{EnvInfo: envInfo, Env: local}); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	artifact := transformertypes.Artifact{Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}}
+	pathMappings, createdArtifacts, err := scalingAnalyser.Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the IR. Error: %q", err)
+	}
+	if len(createdArtifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", createdArtifacts)
+	}
+	ir = createdArtifacts[0].Configs[irtypes.IRConfigType].(irtypes.IR)
+
+	type scaling struct {
+		SessionAffinity bool
+		MaxReplicas     int
+		MountPaths      []string
+	}
+	expected := map[string]scaling{
+		"cart":       {SessionAffinity: true},
+		"redis-cart": {},
+		"media":      {MountPaths: []string{"/app/static/uploads"}},
+		"legacy":     {MaxReplicas: 1},
+		"stateless":  {},
+	}
+	actual := map[string]scaling{}
+	for sn, s := range ir.Services {
+		actualScaling := scaling{SessionAffinity: s.SessionAffinity, MaxReplicas: s.MaxReplicas}
+		for _, volumeMount := range s.Containers[0].VolumeMounts {
+			actualScaling.MountPaths = append(actualScaling.MountPaths, volumeMount.MountPath)
+		}
+		actual[sn] = actualScaling
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("wrong mitigations of the local state. Difference:\n%s", diff)
+	}
+	if len(ir.Storages) != 1 || ir.Storages[0].Name != "media-uploads" || !cmp.Equal(ir.Storages[0].AccessModes, []core.PersistentVolumeAccessMode{core.ReadWriteMany}) {
+		t.Fatalf("expected a ReadWriteMany storage for the uploads. Actual: %+v", ir.Storages)
+	}
+
+	if len(pathMappings) != 1 || pathMappings[0].DestPath != filepath.Join(defaultScalingReportDir, scalingReportFileName) {
+		t.Fatalf("expected a single path mapping for the report. Actual: %+v", pathMappings)
+	}
+	report, err := os.ReadFile(pathMappings[0].SrcPath)
+	if err != nil {
+		t.Fatalf("failed to read the scaling report. Error: %q", err)
+	}
+	for _, expectedLine := range []string{
+		"Service cart:\n  - server.js:1 : sessions are stored in the memory of the process (express-session MemoryStore). Store the sessions in Redis using connect-redis.\n",
+		"Service legacy:\n  - src/Cache.java:1 : the cache is local to each JVM.",
+		"Service media:\n  - settings.py:2 : uploads are stored on the local disk.",
+	} {
+		if !strings.Contains(string(report), expectedLine) {
+			t.Fatalf("expected the scaling report to contain %q . Actual:\n%s", expectedLine, report)
+		}
+	}
+	for _, sn := range []string{"redis-cart", "stateless"} {
+		if strings.Contains(string(report), "Service "+sn+":") {
+			t.Fatalf("expected no findings for the service %s . Actual:\n%s", sn, report)
+		}
+	}
+}
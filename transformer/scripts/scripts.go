@@ -0,0 +1,134 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package scripts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+// Format is a format in which the scripts can be generated.
+// Templates of a script in a format are identified using the extension of the format.
+type Format struct {
+	Name        string
+	Extension   string
+	Description string
+	// Default formats are selected when the user does not choose any
+	Default bool
+}
+
+var (
+	formats = []Format{
+		{Name: "bash", Extension: common.ShExt, Description: "shell scripts for Linux and macOS", Default: true},
+		{Name: "batch", Extension: common.BatExt, Description: "batch files for the Windows command prompt", Default: true},
+		{Name: "powershell", Extension: common.Ps1Ext, Description: "PowerShell scripts for Windows, Linux and macOS"},
+	}
+	formatsMutex sync.Mutex
+)
+
+// RegisterFormat adds a format to the formats the user can choose from.
+// A format with the same name replaces the existing one.
+func RegisterFormat(format Format) {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+	for i, existing := range formats {
+		if existing.Name == format.Name {
+			formats[i] = format
+			return
+		}
+	}
+	formats = append(formats, format)
+}
+
+// GetFormats returns all the registered formats
+func GetFormats() []Format {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+	return append([]Format{}, formats...)
+}
+
+// GetSelectedFormats asks the user for the formats in which the scripts should be generated
+func GetSelectedFormats() []Format {
+	allFormats := GetFormats()
+	options := []string{}
+	defaults := []string{}
+	hints := []string{}
+	for _, format := range allFormats {
+		options = append(options, format.Name)
+		hints = append(hints, fmt.Sprintf("%s : %s", format.Name, format.Description))
+		if format.Default {
+			defaults = append(defaults, format.Name)
+		}
+	}
+	selected := qaengine.FetchMultiSelectAnswer(common.ConfigScriptFormatsKey, "Select the formats in which the scripts should be generated :", hints, defaults, options)
+	selectedFormats := []Format{}
+	for _, format := range allFormats {
+		if common.IsStringPresent(selected, format.Name) {
+			selectedFormats = append(selectedFormats, format)
+		}
+	}
+	return selectedFormats
+}
+
+// GetPathMappings returns template path mappings for the files in the templates directory.
+// Scripts are only included in the selected formats. Files that are not scripts of any registered format are always included.
+func GetPathMappings(templatesDir, destDir string, templateConfig interface{}, selectedFormats []Format) ([]transformertypes.PathMapping, error) {
+	allFormats := GetFormats()
+	pathMappings := []transformertypes.PathMapping{}
+	err := filepath.Walk(templatesDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if isScript(path, allFormats) && !isScript(path, selectedFormats) {
+			return nil
+		}
+		relPath, err := filepath.Rel(templatesDir, path)
+		if err != nil {
+			return err
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        path,
+			DestPath:       filepath.Join(destDir, relPath),
+			TemplateConfig: templateConfig,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk the templates directory at path %s . Error: %q", templatesDir, err)
+	}
+	return pathMappings, nil
+}
+
+func isScript(path string, formats []Format) bool {
+	for _, format := range formats {
+		if strings.HasSuffix(path, format.Extension) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package scripts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+func TestGetPathMappings(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{`move2kube.scripts.formats=["bash","powershell"]`}, nil, nil, false, false)
+
+	templatesDir := t.TempDir()
+	for _, name := range []string{"buildimages.sh", "buildimages.bat", "buildimages.ps1", "README.md"} {
+		if err := os.WriteFile(filepath.Join(templatesDir, name), []byte("{{ .Name }}"), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the template %s . Error: %q", name, err)
+		}
+	}
+	selectedFormats := GetSelectedFormats()
+	selectedNames := []string{}
+	for _, format := range selectedFormats {
+		selectedNames = append(selectedNames, format.Name)
+	}
+	if diff := cmp.Diff([]string{"bash", "powershell"}, selectedNames); diff != "" {
+		t.Fatalf("the selected formats are incorrect. Difference:\n%s", diff)
+	}
+	pathMappings, err := GetPathMappings(templatesDir, "scripts", nil, selectedFormats)
+	if err != nil {
+		t.Fatalf("failed to get the path mappings. Error: %q", err)
+	}
+	destPaths := []string{}
+	for _, pathMapping := range pathMappings {
+		destPaths = append(destPaths, pathMapping.DestPath)
+	}
+	expected := []string{filepath.Join("scripts", "README.md"), filepath.Join("scripts", "buildimages.ps1"), filepath.Join("scripts", "buildimages.sh")}
+	if diff := cmp.Diff(expected, destPaths); diff != "" {
+		t.Fatalf("expected only the scripts of the selected formats and the other files. Difference:\n%s", diff)
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	original := GetFormats()
+	t.Cleanup(func() {
+		formatsMutex.Lock()
+		formats = original
+		formatsMutex.Unlock()
+	})
+	RegisterFormat(Format{Name: "fish", Extension: ".fish", Description: "fish shell scripts"})
+	RegisterFormat(Format{Name: "powershell", Extension: common.Ps1Ext, Description: "PowerShell scripts", Default: true})
+	names := []string{}
+	defaults := []string{}
+	for _, format := range GetFormats() {
+		names = append(names, format.Name)
+		if format.Default {
+			defaults = append(defaults, format.Name)
+		}
+	}
+	if diff := cmp.Diff([]string{"bash", "batch", "powershell", "fish"}, names); diff != "" {
+		t.Fatalf("the registered formats are incorrect. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"bash", "batch", "powershell"}, defaults); diff != "" {
+		t.Fatalf("expected the registered format to replace the existing one. Difference:\n%s", diff)
+	}
+}
@@ -0,0 +1,247 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// workspaceFiles are the build files that declare the modules of a multi-module project.
+	// If the value is not empty, the file has to contain it to be considered a workspace file.
+	workspaceFiles = map[string]string{
+		"pom.xml":             "<modules>",
+		"settings.gradle":     "",
+		"settings.gradle.kts": "",
+		"go.work":             "",
+		"lerna.json":          "",
+		"nx.json":             "",
+		"pnpm-workspace.yaml": "",
+		"package.json":        `"workspaces"`,
+		"Cargo.toml":          "[workspace]",
+	}
+	// moduleNameSuffixes are the suffixes conventionally used for the names of the modules of a single app
+	moduleNameSuffixes = []string{"api", "client", "common", "core", "domain", "impl", "lib", "model", "persistence", "server", "service", "shared", "ui", "util", "utils", "web"}
+)
+
+// serviceGroup is a set of detected services that look like the modules of a single app
+type serviceGroup struct {
+	name     string
+	services []string
+	rootDir  string
+	reason   string
+	// grouped is the default answer for grouping the services
+	grouped bool
+}
+
+// groupServices clusters the services that look like the modules of a single app and asks the user whether they should be planned as a single service.
+// Services are grouped if they are under a directory with a build file that declares modules (a workspace), or if they are in the same directory and their names only differ in a module suffix like -api or -core.
+func groupServices(sourceDir string, services map[string][]plantypes.PlanArtifact) map[string][]plantypes.PlanArtifact {
+	serviceDirs := getServiceDirs(services)
+	groups := getWorkspaceServiceGroups(sourceDir, serviceDirs)
+	groupedServices := map[string]bool{}
+	for _, group := range groups {
+		for _, sn := range group.services {
+			groupedServices[sn] = true
+		}
+	}
+	ungroupedServiceDirs := map[string]string{}
+	for sn, serviceDir := range serviceDirs {
+		if !groupedServices[sn] {
+			ungroupedServiceDirs[sn] = serviceDir
+		}
+	}
+	groups = append(groups, getNamedServiceGroups(ungroupedServiceDirs)...)
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	for _, group := range groups {
+		if _, ok := services[group.name]; ok && !common.IsPresent(group.services, group.name) {
+			logrus.Debugf("not grouping the services %+v since there is another service with the name %s", group.services, group.name)
+			continue
+		}
+		relRootDir, err := filepath.Rel(sourceDir, group.rootDir)
+		if err != nil {
+			relRootDir = group.rootDir
+		}
+		if !qaengine.FetchBoolAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+group.name+`"`, common.ConfigGroupServicesKeySegment),
+			fmt.Sprintf("The services %s look like the modules of a single app (%s). Should they be planned as a single service %s?", strings.Join(group.services, ", "), group.reason, group.name),
+			[]string{fmt.Sprintf("The grouped service is containerized using the module in the directory %s or the first of the other modules", relRootDir), "Answer no to plan the services separately"},
+			group.grouped,
+		) {
+			continue
+		}
+		logrus.Infof("Grouping the services %s into the service %s", strings.Join(group.services, ", "), group.name)
+		services[group.name] = mergeGroupedServices(group, services, serviceDirs)
+		for _, sn := range group.services {
+			if sn != group.name {
+				delete(services, sn)
+			}
+		}
+	}
+	return services
+}
+
+// getServiceDirs returns the directory of each named service that has service directories
+func getServiceDirs(services map[string][]plantypes.PlanArtifact) map[string]string {
+	serviceDirs := map[string]string{}
+	for sn, planArtifacts := range services {
+		if sn == "" {
+			continue
+		}
+		paths := []string{}
+		for _, planArtifact := range planArtifacts {
+			paths = append(paths, planArtifact.Paths[artifacts.ServiceDirPathType]...)
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		serviceDirs[sn] = common.CleanAndFindCommonDirectory(paths)
+	}
+	return serviceDirs
+}
+
+// getWorkspaceServiceGroups groups the services by the outermost workspace directory they are in
+func getWorkspaceServiceGroups(sourceDir string, serviceDirs map[string]string) []serviceGroup {
+	workspaceServices := map[string][]string{}
+	for sn, serviceDir := range serviceDirs {
+		workspaceDir := ""
+		for dir := serviceDir; common.IsParent(dir, sourceDir); dir = filepath.Dir(dir) {
+			if getWorkspaceFile(dir) != "" {
+				workspaceDir = dir
+			}
+			if dir == filepath.Dir(dir) {
+				break
+			}
+		}
+		if workspaceDir != "" {
+			workspaceServices[workspaceDir] = append(workspaceServices[workspaceDir], sn)
+		}
+	}
+	groups := []serviceGroup{}
+	for workspaceDir, sns := range workspaceServices {
+		if len(sns) < 2 {
+			continue
+		}
+		sort.Strings(sns)
+		name := common.NormalizeForMetadataName(filepath.Base(workspaceDir))
+		for _, sn := range sns {
+			if serviceDirs[sn] == workspaceDir {
+				name = sn
+			}
+		}
+		groups = append(groups, serviceGroup{
+			name:     name,
+			services: sns,
+			rootDir:  workspaceDir,
+			reason:   "they are in the workspace declared by " + getWorkspaceFile(workspaceDir),
+			grouped:  true,
+		})
+	}
+	return groups
+}
+
+// getWorkspaceFile returns the name of the workspace file in the directory, or an empty string if there is none
+func getWorkspaceFile(dir string) string {
+	fileNames := []string{}
+	for fileName := range workspaceFiles {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		contents, err := os.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			continue
+		}
+		if strings.Contains(string(contents), workspaceFiles[fileName]) {
+			return fileName
+		}
+	}
+	return ""
+}
+
+// getNamedServiceGroups groups the services in the same parent directory whose names only differ in a module suffix.
+// Since similar names are a weaker signal than a workspace, these services are not grouped by default.
+func getNamedServiceGroups(serviceDirs map[string]string) []serviceGroup {
+	type groupKey struct {
+		parentDir string
+		prefix    string
+	}
+	namedServices := map[groupKey][]string{}
+	for sn, serviceDir := range serviceDirs {
+		prefix := getServiceNamePrefix(sn)
+		if prefix == "" {
+			prefix = sn
+		}
+		key := groupKey{parentDir: filepath.Dir(serviceDir), prefix: prefix}
+		namedServices[key] = append(namedServices[key], sn)
+	}
+	groups := []serviceGroup{}
+	for key, sns := range namedServices {
+		if len(sns) < 2 {
+			continue
+		}
+		sort.Strings(sns)
+		rootDir := serviceDirs[sns[0]]
+		if common.IsPresent(sns, key.prefix) {
+			rootDir = serviceDirs[key.prefix]
+		}
+		groups = append(groups, serviceGroup{
+			name:     key.prefix,
+			services: sns,
+			rootDir:  rootDir,
+			reason:   "their names only differ in the module suffix",
+			grouped:  false,
+		})
+	}
+	return groups
+}
+
+// getServiceNamePrefix returns the name of the service without a module suffix, or an empty string if the name does not have one
+func getServiceNamePrefix(serviceName string) string {
+	idx := strings.LastIndexAny(serviceName, "-_")
+	if idx <= 0 {
+		return ""
+	}
+	if !common.IsStringPresent(moduleNameSuffixes, serviceName[idx+1:]) {
+		return ""
+	}
+	return serviceName[:idx]
+}
+
+// mergeGroupedServices returns the plan artifacts of the grouped services.
+// The artifacts of the module in the root directory of the group come first, since the first artifact is used to containerize the service.
+func mergeGroupedServices(group serviceGroup, services map[string][]plantypes.PlanArtifact, serviceDirs map[string]string) []plantypes.PlanArtifact {
+	sns := append([]string{}, group.services...)
+	sort.SliceStable(sns, func(i, j int) bool {
+		return serviceDirs[sns[i]] == group.rootDir && serviceDirs[sns[j]] != group.rootDir
+	})
+	planArtifacts := []plantypes.PlanArtifact{}
+	for _, sn := range sns {
+		planArtifacts = append(planArtifacts, services[sn]...)
+	}
+	return planArtifacts
+}
@@ -0,0 +1,148 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+)
+
+func TestGetServiceNamePrefix(t *testing.T) {
+	testCases := map[string]string{
+		"orders-api":  "orders",
+		"orders_core": "orders",
+		"my-app-web":  "my-app",
+		"orders":      "",
+		"orders-v2":   "",
+		"-api":        "",
+	}
+	for serviceName, expected := range testCases {
+		if actual := getServiceNamePrefix(serviceName); actual != expected {
+			t.Fatalf("wrong prefix for the service name %s . Expected: %s Actual: %s", serviceName, expected, actual)
+		}
+	}
+}
+
+func TestGroupServices(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{`move2kube.services."billing".groupservices=true`}, nil, nil, false, false)
+
+	sourceDir := t.TempDir()
+	files := map[string]string{
+		"shop/pom.xml":                "<project><modules><module>shop-api</module></modules></project>",
+		"shop/shop-api/pom.xml":       "<project></project>",
+		"shop/shop-core/pom.xml":      "<project></project>",
+		"billing-api/package.json":    "{}",
+		"billing-web/package.json":    "{}",
+		"orders-api/package.json":     "{}",
+		"orders-web/package.json":     "{}",
+		"inventory/package.json":      `{"name": "inventory"}`,
+		"notes/pom.xml":               "<project></project>",
+		"notes/notes-server/main.go":  "package main",
+		"notes/notes-client/index.js": "",
+	}
+	for path, contents := range files {
+		path = filepath.Join(sourceDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	newService := func(name, dir string) []plantypes.PlanArtifact {
+		return []plantypes.PlanArtifact{{
+			ServiceName:     name,
+			TransformerName: "transformer-" + name,
+			Artifact: transformertypes.Artifact{
+				Name:  name,
+				Paths: map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {filepath.Join(sourceDir, dir)}},
+			},
+		}}
+	}
+	services := map[string][]plantypes.PlanArtifact{
+		"shop":         newService("shop", "shop"),
+		"shop-api":     newService("shop-api", "shop/shop-api"),
+		"shop-core":    newService("shop-core", "shop/shop-core"),
+		"billing-api":  newService("billing-api", "billing-api"),
+		"billing-web":  newService("billing-web", "billing-web"),
+		"orders-api":   newService("orders-api", "orders-api"),
+		"orders-web":   newService("orders-web", "orders-web"),
+		"inventory":    newService("inventory", "inventory"),
+		"notes-server": newService("notes-server", "notes/notes-server"),
+		"notes-client": newService("notes-client", "notes/notes-client"),
+	}
+	services = groupServices(sourceDir, services)
+
+	// the workspace is grouped by default, the similarly named services only if the user says so
+	expected := map[string][]string{
+		"shop":         {"shop", "shop-api", "shop-core"},
+		"billing":      {"billing-api", "billing-web"},
+		"orders-api":   {"orders-api"},
+		"orders-web":   {"orders-web"},
+		"inventory":    {"inventory"},
+		"notes-server": {"notes-server"},
+		"notes-client": {"notes-client"},
+	}
+	actual := map[string][]string{}
+	for sn, planArtifacts := range services {
+		for _, planArtifact := range planArtifacts {
+			actual[sn] = append(actual[sn], planArtifact.Name)
+		}
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("failed to group the services. Difference:\n%s", diff)
+	}
+}
+
+func TestGetWorkspaceServiceGroups(t *testing.T) {
+	sourceDir := t.TempDir()
+	workspaceDir := filepath.Join(sourceDir, "mono")
+	if err := os.MkdirAll(filepath.Join(workspaceDir, "packages"), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the workspace directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspaceDir, "package.json"), []byte(`{"workspaces": ["packages/*"]}`), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the workspace file. Error: %q", err)
+	}
+	serviceDirs := map[string]string{
+		"frontend": filepath.Join(workspaceDir, "packages", "frontend"),
+		"backend":  filepath.Join(workspaceDir, "packages", "backend"),
+		"other":    filepath.Join(sourceDir, "other"),
+	}
+	groups := getWorkspaceServiceGroups(sourceDir, serviceDirs)
+	if len(groups) != 1 {
+		t.Fatalf("expected a single group. Actual: %+v", groups)
+	}
+	group := groups[0]
+	sort.Strings(group.services)
+	if group.name != "mono" || group.rootDir != workspaceDir || !group.grouped || !cmp.Equal(group.services, []string{"backend", "frontend"}) {
+		t.Fatalf("wrong workspace group. Actual: %+v", group)
+	}
+	if group.reason != "they are in the workspace declared by package.json" {
+		t.Fatalf("wrong reason for the group. Actual: %s", group.reason)
+	}
+}
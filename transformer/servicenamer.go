@@ -17,13 +17,17 @@
 package transformer
 
 import (
+	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
 	plantypes "github.com/konveyor/move2kube/types/plan"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type service struct {
@@ -217,3 +221,63 @@ func trimPrefix(files []service, prefix string) []service {
 	}
 	return files
 }
+
+// NormalizeServiceNames makes the service names valid DNS-1123 labels, so that the same names are used for the artifacts, images and manifests.
+// Services whose names collide after normalization get deterministic numeric suffixes.
+// If askOverrides is true, the normalized names can be overridden using the QA.
+func NormalizeServiceNames(services map[string][]plantypes.PlanArtifact, askOverrides bool) map[string][]plantypes.PlanArtifact {
+	originalNames := []string{}
+	validNames := map[string]bool{}
+	for sn := range services {
+		originalNames = append(originalNames, sn)
+		validNames[sn] = len(validation.IsDNS1123Label(sn)) == 0
+	}
+	// names that are already valid keep their names in case of collisions
+	sort.Slice(originalNames, func(i, j int) bool {
+		if validNames[originalNames[i]] != validNames[originalNames[j]] {
+			return validNames[originalNames[i]]
+		}
+		return originalNames[i] < originalNames[j]
+	})
+	normalizedServices := map[string][]plantypes.PlanArtifact{}
+	for _, originalName := range originalNames {
+		if originalName == "" {
+			normalizedServices[originalName] = services[originalName]
+			continue
+		}
+		name := common.NormalizeForMetadataName(originalName)
+		if askOverrides {
+			override := qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(common.ConfigServicesKey, `"`+originalName+`"`, common.ConfigServiceNameKeySegment),
+				fmt.Sprintf("Enter the name to use for the service %s :", originalName),
+				[]string{"The name is used for the images and the Kubernetes resources of the service and has to be a valid DNS-1123 label"},
+				name,
+			)
+			if override != "" {
+				name = common.NormalizeForMetadataName(override)
+			}
+		}
+		uniqueName := name
+		for i := 2; ; i++ {
+			if _, ok := normalizedServices[uniqueName]; !ok {
+				break
+			}
+			suffix := fmt.Sprintf("-%d", i)
+			uniqueName = common.NormalizeForMetadataName(truncateServiceName(name, len(suffix)) + suffix)
+		}
+		if uniqueName != name {
+			logrus.Warnf("The name of the service %s collides with another service after normalization. Using the name %s", originalName, uniqueName)
+		}
+		normalizedServices[uniqueName] = services[originalName]
+	}
+	return normalizedServices
+}
+
+// truncateServiceName shortens the name so that a suffix of the given length can be added without exceeding the maximum length of a DNS-1123 label
+func truncateServiceName(name string, suffixLength int) string {
+	const maxLength = 63
+	if len(name)+suffixLength <= maxLength {
+		return name
+	}
+	return strings.TrimRight(name[:maxLength-suffixLength], "-")
+}
@@ -0,0 +1,71 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+)
+
+func TestNormalizeServiceNames(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{`move2kube.services."Billing_API".servicename="invoices"`}, nil, nil, false, false)
+
+	longName := strings.Repeat("a", 70)
+	services := map[string][]plantypes.PlanArtifact{}
+	for _, name := range []string{"web", "Web", "WEB", "my_api", "my-api", "Billing_API", longName, longName + "_2", ""} {
+		services[name] = []plantypes.PlanArtifact{{TransformerName: name}}
+	}
+
+	t.Run("normalize the names without asking", func(t *testing.T) {
+		normalized := NormalizeServiceNames(services, false)
+		actual := map[string]string{}
+		for name, artifacts := range normalized {
+			actual[artifacts[0].TransformerName] = name
+		}
+		expected := map[string]string{
+			"web":           "web",
+			"WEB":           "web-2",
+			"Web":           "web-3",
+			"my-api":        "my-api",
+			"my_api":        "my-api-2",
+			"Billing_API":   "billing-api",
+			longName:        strings.Repeat("a", 63),
+			longName + "_2": strings.Repeat("a", 61) + "-2",
+			"":              "",
+		}
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Fatalf("the normalized service names are incorrect. Difference:\n%s", diff)
+		}
+	})
+
+	t.Run("use the names given in the QA", func(t *testing.T) {
+		normalized := NormalizeServiceNames(services, true)
+		if artifacts, ok := normalized["invoices"]; !ok || artifacts[0].TransformerName != "Billing_API" {
+			t.Fatalf("expected the service Billing_API to be renamed to invoices. Actual: %+v", normalized)
+		}
+		if _, ok := normalized["billing-api"]; ok {
+			t.Fatalf("expected the normalized name of the renamed service to not be used. Actual: %+v", normalized)
+		}
+	})
+}
@@ -0,0 +1,245 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// SidecarInjector implements Transformer interface
+type SidecarInjector struct {
+	Config   transformertypes.Transformer
+	Env      *environment.Environment
+	sidecars map[string]sidecarT
+}
+
+// sidecarT is a sidecar with its pod spec and environment variables converted to the types used in the IR
+type sidecarT struct {
+	services     []string
+	podSpec      core.PodSpec
+	env          []core.EnvVar
+	volumeMounts []core.VolumeMount
+}
+
+// Init Initializes the transformer
+func (t *SidecarInjector) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.sidecars = loadSidecars(common.AssetsPath)
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *SidecarInjector) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *SidecarInjector) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform injects the sidecars into the services they are configured for
+func (t *SidecarInjector) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		if len(t.sidecars) != 0 {
+			for sn, s := range ir.Services {
+				sidecarNames := t.getSidecarsForService(sn)
+				if len(sidecarNames) == 0 {
+					continue
+				}
+				selectedSidecarNames := qaengine.FetchMultiSelectAnswer(
+					common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`, common.ConfigSidecarsKeySegment),
+					fmt.Sprintf("Select the sidecars to inject into the service %s :", sn),
+					[]string{"Deselect the sidecars that the service does not need"},
+					sidecarNames,
+					sidecarNames,
+				)
+				for _, sidecarName := range selectedSidecarNames {
+					if sidecar, ok := t.sidecars[sidecarName]; ok {
+						logrus.Debugf("injecting the sidecar %s into the service %s", sidecarName, sn)
+						injectSidecar(&s, sidecar)
+					}
+				}
+				ir.Services[sn] = s
+			}
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return nil, artifactsCreated, nil
+}
+
+// getSidecarsForService returns the sorted names of the sidecars that match the service
+func (t *SidecarInjector) getSidecarsForService(serviceName string) []string {
+	sidecarNames := []string{}
+	for name, sidecar := range t.sidecars {
+		if len(sidecar.services) == 0 {
+			sidecarNames = append(sidecarNames, name)
+			continue
+		}
+		for _, pattern := range sidecar.services {
+			if matched, err := filepath.Match(pattern, serviceName); err == nil && matched {
+				sidecarNames = append(sidecarNames, name)
+				break
+			}
+		}
+	}
+	sort.Strings(sidecarNames)
+	return sidecarNames
+}
+
+// loadSidecars finds the sidecars in the assets
+func loadSidecars(assetsPath string) map[string]sidecarT {
+	sidecars := map[string]sidecarT{}
+	filePaths, err := common.GetFilesByExt(assetsPath, []string{".yml", ".yaml"})
+	if err != nil {
+		logrus.Errorf("failed to look for yaml files in the directory %s . Error: %q", assetsPath, err)
+		return sidecars
+	}
+	for _, filePath := range filePaths {
+		sidecar := transformertypes.NewSidecar()
+		if err := common.ReadMove2KubeYaml(filePath, &sidecar); err != nil || sidecar.Kind != transformertypes.SidecarKind {
+			continue
+		}
+		if _, ok := sidecars[sidecar.Name]; ok {
+			logrus.Warnf("Found two sidecars with the name %s . Using the one at path %s", sidecar.Name, filePath)
+		}
+		podSpec := corev1.PodSpec{}
+		if err := convertSidecarField(sidecar.Spec.PodSpec, &podSpec); err != nil {
+			logrus.Errorf("Ignoring the sidecar %s at path %s . The pod spec is invalid. Error: %q", sidecar.Name, filePath, err)
+			continue
+		}
+		envVars := []corev1.EnvVar{}
+		if err := convertSidecarField(sidecar.Spec.Env, &envVars); err != nil {
+			logrus.Errorf("Ignoring the sidecar %s at path %s . The environment variables are invalid. Error: %q", sidecar.Name, filePath, err)
+			continue
+		}
+		volumeMounts := []corev1.VolumeMount{}
+		if err := convertSidecarField(sidecar.Spec.VolumeMounts, &volumeMounts); err != nil {
+			logrus.Errorf("Ignoring the sidecar %s at path %s . The volume mounts are invalid. Error: %q", sidecar.Name, filePath, err)
+			continue
+		}
+		// the environment variables and volume mounts are converted as part of a pod spec, since the converters work on pod specs
+		servicePodSpec := k8sschema.ConvertToPodSpec(&corev1.PodSpec{Containers: []corev1.Container{{Env: envVars, VolumeMounts: volumeMounts}}})
+		sidecars[sidecar.Name] = sidecarT{
+			services:     sidecar.Spec.Services,
+			podSpec:      k8sschema.ConvertToPodSpec(&podSpec),
+			env:          servicePodSpec.Containers[0].Env,
+			volumeMounts: servicePodSpec.Containers[0].VolumeMounts,
+		}
+	}
+	return sidecars
+}
+
+// convertSidecarField converts a field of the sidecar yaml to the Kubernetes type using the json tags of the type
+func convertSidecarField(field interface{}, out interface{}) error {
+	if field == nil {
+		return nil
+	}
+	fieldJSONBytes, err := json.Marshal(field)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(fieldJSONBytes, out)
+}
+
+// injectSidecar adds the containers, volumes, environment variables and volume mounts of the sidecar to the service.
+// Containers, volumes and environment variables that the service already has with the same names are not changed.
+func injectSidecar(service *irtypes.Service, sidecar sidecarT) {
+	// the environment variables and volume mounts are added to the containers of the service, and not to the injected containers
+	for i := range service.Containers {
+		for _, envVar := range sidecar.env {
+			if !hasEnvVar(service.Containers[i].Env, envVar.Name) {
+				service.Containers[i].Env = append(service.Containers[i].Env, envVar)
+			}
+		}
+		for _, volumeMount := range sidecar.volumeMounts {
+			if !hasVolumeMount(service.Containers[i].VolumeMounts, volumeMount.MountPath) {
+				service.Containers[i].VolumeMounts = append(service.Containers[i].VolumeMounts, volumeMount)
+			}
+		}
+	}
+	for _, container := range sidecar.podSpec.Containers {
+		if !hasContainer(service.Containers, container.Name) {
+			service.Containers = append(service.Containers, container)
+		}
+	}
+	for _, container := range sidecar.podSpec.InitContainers {
+		if !hasContainer(service.InitContainers, container.Name) {
+			service.InitContainers = append(service.InitContainers, container)
+		}
+	}
+	for _, volume := range sidecar.podSpec.Volumes {
+		exists := false
+		for _, existing := range service.Volumes {
+			if existing.Name == volume.Name {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			service.Volumes = append(service.Volumes, volume)
+		}
+	}
+}
+
+func hasContainer(containers []core.Container, name string) bool {
+	for _, container := range containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEnvVar(envVars []core.EnvVar, name string) bool {
+	for _, envVar := range envVars {
+		if envVar.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVolumeMount(volumeMounts []core.VolumeMount, mountPath string) bool {
+	for _, volumeMount := range volumeMounts {
+		if volumeMount.MountPath == mountPath {
+			return true
+		}
+	}
+	return false
+}
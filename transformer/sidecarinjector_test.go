@@ -0,0 +1,167 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	loggingSidecar = `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Sidecar
+metadata:
+  name: logging
+spec:
+  podSpec:
+    containers:
+      - name: fluent-bit
+        image: fluent/fluent-bit:1.9
+    volumes:
+      - name: app-logs
+        emptyDir: {}
+  env:
+    - name: LOG_FORMAT
+      value: json
+  volumeMounts:
+    - name: app-logs
+      mountPath: /var/log/app
+`
+	envoySidecar = `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Sidecar
+metadata:
+  name: envoy
+spec:
+  services:
+    - payments-*
+  podSpec:
+    initContainers:
+      - name: envoy-init
+        image: envoyproxy/envoy:v1.22.0
+    containers:
+      - name: envoy
+        image: envoyproxy/envoy:v1.22.0
+`
+	invalidSidecar = `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Sidecar
+metadata:
+  name: invalid
+spec:
+  podSpec:
+    containers: not-a-list
+`
+)
+
+func TestSidecarInjectorTransform(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.services."payments-db".sidecars=["envoy"]`,
+	}, nil, nil, false, false)
+	oldAssetsPath := common.AssetsPath
+	common.AssetsPath = t.TempDir()
+	t.Cleanup(func() { common.AssetsPath = oldAssetsPath })
+	for name, sidecar := range map[string]string{"logging": loggingSidecar, "envoy": envoySidecar, "invalid": invalidSidecar} {
+		if err := os.WriteFile(filepath.Join(common.AssetsPath, name+".yaml"), []byte(sidecar), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the sidecar %s . Error: %q", name, err)
+		}
+	}
+
+	injector := &SidecarInjector{}
+	if err := injector.Init(transformertypes.Transformer{}, nil); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	if _, ok := injector.sidecars["invalid"]; ok || len(injector.sidecars) != 2 {
+		t.Fatalf("expected the invalid sidecar to be ignored. Actual: %+v", injector.sidecars)
+	}
+
+	ir := irtypes.NewIR()
+	for _, sn := range []string{"web", "payments-api", "payments-db"} {
+		s := irtypes.NewServiceWithName(sn)
+		s.Containers = []core.Container{{Name: sn, Image: sn + ":latest"}}
+		ir.Services[sn] = s
+	}
+	paymentsAPI := ir.Services["payments-api"]
+	paymentsAPI.Containers[0].Env = []core.EnvVar{{Name: "LOG_FORMAT", Value: "text"}}
+	ir.Services["payments-api"] = paymentsAPI
+	artifact := transformertypes.Artifact{Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}}
+	_, createdArtifacts, err := injector.Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the IR. Error: %q", err)
+	}
+	if len(createdArtifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", createdArtifacts)
+	}
+	ir = createdArtifacts[0].Configs[irtypes.IRConfigType].(irtypes.IR)
+
+	type injected struct {
+		Containers     []string
+		InitContainers []string
+		Volumes        []string
+		Env            []core.EnvVar
+		MountPaths     []string
+	}
+	expected := map[string]injected{
+		"web": {
+			Containers: []string{"web", "fluent-bit"},
+			Volumes:    []string{"app-logs"},
+			Env:        []core.EnvVar{{Name: "LOG_FORMAT", Value: "json"}},
+			MountPaths: []string{"/var/log/app"},
+		},
+		// the environment variables that the service already has are not changed
+		"payments-api": {
+			Containers:     []string{"payments-api", "envoy", "fluent-bit"},
+			InitContainers: []string{"envoy-init"},
+			Volumes:        []string{"app-logs"},
+			Env:            []core.EnvVar{{Name: "LOG_FORMAT", Value: "text"}},
+			MountPaths:     []string{"/var/log/app"},
+		},
+		// the user deselected the logging sidecar
+		"payments-db": {
+			Containers:     []string{"payments-db", "envoy"},
+			InitContainers: []string{"envoy-init"},
+		},
+	}
+	actual := map[string]injected{}
+	for sn, s := range ir.Services {
+		actualInjected := injected{Env: s.Containers[0].Env}
+		for _, container := range s.Containers {
+			actualInjected.Containers = append(actualInjected.Containers, container.Name)
+		}
+		for _, container := range s.InitContainers {
+			actualInjected.InitContainers = append(actualInjected.InitContainers, container.Name)
+		}
+		for _, volume := range s.Volumes {
+			actualInjected.Volumes = append(actualInjected.Volumes, volume.Name)
+		}
+		for _, volumeMount := range s.Containers[0].VolumeMounts {
+			actualInjected.MountPaths = append(actualInjected.MountPaths, volumeMount.MountPath)
+		}
+		actual[sn] = actualInjected
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("wrong sidecars injected into the services. Difference:\n%s", diff)
+	}
+}
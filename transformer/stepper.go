@@ -0,0 +1,128 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	stepRunOption      = "Run the transformer"
+	stepSkipOption     = "Skip the transformer"
+	stepInspectOption  = "Inspect the input artifacts"
+	stepContinueOption = "Run this and the remaining transformers without stopping"
+	stepAbortOption    = "Abort the transform"
+)
+
+var (
+	// stepAbort cancels the transform when the user aborts it while stepping through the transformers
+	stepAbort context.CancelFunc = func() {}
+)
+
+// startStepping returns a context that is cancelled when the user aborts the transform while stepping through the transformers
+func startStepping(ctx context.Context) context.Context {
+	if !common.StepMode {
+		return ctx
+	}
+	logrus.Infof("The transform will pause before each transformer runs.")
+	ctx, stepAbort = context.WithCancel(ctx)
+	return ctx
+}
+
+// stepBeforeTransformer pauses before the transformer runs and shows the artifacts it will consume.
+// It returns false if the user chose to skip the transformer.
+func stepBeforeTransformer(iteration int, tConfig transformertypes.Transformer, artifacts []transformertypes.Artifact) bool {
+	if !common.StepMode {
+		return true
+	}
+	fmt.Printf("\nIteration %d: the transformer %s will consume %d artifacts:\n", iteration, tConfig.Name, len(artifacts))
+	for _, summary := range getArtifactSummaries(artifacts) {
+		fmt.Println("  - " + summary)
+	}
+	for {
+		var answer string
+		prompt := &survey.Select{
+			Message: fmt.Sprintf("What should be done with the transformer %s ?", tConfig.Name),
+			Options: []string{stepRunOption, stepSkipOption, stepInspectOption, stepContinueOption, stepAbortOption},
+			Default: stepRunOption,
+		}
+		if err := survey.AskOne(prompt, &answer); err != nil {
+			if !errors.Is(err, terminal.InterruptErr) {
+				logrus.Warnf("Running the remaining transformers without stopping, since the step prompt failed. Error: %q", err)
+				common.StepMode = false
+				return true
+			}
+			answer = stepAbortOption
+		}
+		switch answer {
+		case stepRunOption:
+			return true
+		case stepSkipOption:
+			logrus.Infof("Skipping the transformer %s", tConfig.Name)
+			return false
+		case stepInspectOption:
+			artifactsYaml, err := yaml.Marshal(artifacts)
+			if err != nil {
+				logrus.Errorf("failed to marshal the artifacts of the transformer %s to yaml. Error: %q", tConfig.Name, err)
+				continue
+			}
+			fmt.Println(string(artifactsYaml))
+		case stepContinueOption:
+			common.StepMode = false
+			return true
+		case stepAbortOption:
+			logrus.Infof("Aborting the transform before the transformer %s", tConfig.Name)
+			stepAbort()
+			return false
+		}
+	}
+}
+
+// stepAfterTransformer shows what the transformer produced
+func stepAfterTransformer(tConfig transformertypes.Transformer, artifacts []transformertypes.Artifact, pathMappings []transformertypes.PathMapping, err error) {
+	if !common.StepMode {
+		return
+	}
+	if err != nil {
+		fmt.Printf("The transformer %s failed. Error: %q\n", tConfig.Name, err)
+		return
+	}
+	fmt.Printf("The transformer %s produced %d artifacts and %d path mappings:\n", tConfig.Name, len(artifacts), len(pathMappings))
+	for _, summary := range getArtifactSummaries(artifacts) {
+		fmt.Println("  - " + summary)
+	}
+}
+
+// getArtifactSummaries returns the sorted types and names of the artifacts
+func getArtifactSummaries(artifacts []transformertypes.Artifact) []string {
+	summaries := []string{}
+	for _, a := range artifacts {
+		summaries = append(summaries, fmt.Sprintf("%s %s", a.Type, a.Name))
+	}
+	sort.Strings(summaries)
+	return summaries
+}
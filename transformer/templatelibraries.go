@@ -0,0 +1,172 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/Masterminds/sprig"
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	templateLibraryExt = ".tpl"
+	// templateLibraryVersionSeparator separates the name of a template library from the version constraint in the transformer config
+	templateLibraryVersionSeparator = "@"
+)
+
+var (
+	templateLibraries map[string][]transformertypes.TemplateLibrary // [name] sorted by version in descending order
+)
+
+// loadTemplateLibraries finds the template libraries in the assets
+func loadTemplateLibraries(assetsPath string) map[string][]transformertypes.TemplateLibrary {
+	libraries := map[string][]transformertypes.TemplateLibrary{}
+	filePaths, err := common.GetFilesByExt(assetsPath, []string{".yml", ".yaml"})
+	if err != nil {
+		logrus.Errorf("failed to look for yaml files in the directory %s . Error: %q", assetsPath, err)
+		return libraries
+	}
+	for _, filePath := range filePaths {
+		library := transformertypes.NewTemplateLibrary()
+		if err := common.ReadMove2KubeYaml(filePath, &library); err != nil || library.Kind != transformertypes.TemplateLibraryKind {
+			continue
+		}
+		if _, err := semver.NewVersion(library.Spec.Version); err != nil {
+			logrus.Errorf("the template library %s at path %s has an invalid version %s . Ignoring it. Error: %q", library.Name, filePath, library.Spec.Version, err)
+			continue
+		}
+		library.Spec.FilePath = filePath
+		if err := validateTemplateLibrary(library); err != nil {
+			logrus.Errorf("Ignoring the template library %s at path %s . Error: %q", library.Name, filePath, err)
+			continue
+		}
+		libraries[library.Name] = append(libraries[library.Name], library)
+	}
+	for _, versions := range libraries {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return semver.MustParse(versions[i].Spec.Version).GreaterThan(semver.MustParse(versions[j].Spec.Version))
+		})
+	}
+	return libraries
+}
+
+// getTemplateLibraryFiles returns the template files of the library
+func getTemplateLibraryFiles(library transformertypes.TemplateLibrary) ([]string, error) {
+	templatesDir := filepath.Join(filepath.Dir(library.Spec.FilePath), library.Spec.TemplatesDir)
+	return common.GetFilesByExt(templatesDir, []string{templateLibraryExt})
+}
+
+// validateTemplateLibrary checks that the templates defined by the library are namespaced with the name of the library
+func validateTemplateLibrary(library transformertypes.TemplateLibrary) error {
+	libraryFiles, err := getTemplateLibraryFiles(library)
+	if err != nil {
+		return fmt.Errorf("failed to find the template files. Error: %q", err)
+	}
+	funcs := sprig.TxtFuncMap()
+	funcs["include"] = func(string, interface{}) (string, error) { return "", nil }
+	for _, libraryFile := range libraryFiles {
+		tmpl, err := template.New(libraryFile).Funcs(funcs).ParseFiles(libraryFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse the template file %s . Error: %q", libraryFile, err)
+		}
+		for _, t := range tmpl.Templates() {
+			if t.Name() == filepath.Base(libraryFile) || t.Name() == libraryFile {
+				continue
+			}
+			if !strings.HasPrefix(t.Name(), library.Name+".") {
+				return fmt.Errorf("the name of the template %s in the file %s has to start with %s", t.Name(), libraryFile, library.Name+".")
+			}
+		}
+	}
+	return nil
+}
+
+// getTemplateLibrariesForTransformer returns the template files of the libraries that the transformer uses.
+// A library is referred to by its name or by its name and a version constraint like acme@^1.2 . The latest matching version is used.
+func getTemplateLibrariesForTransformer(tc transformertypes.Transformer) []string {
+	if len(tc.Spec.TemplateLibraries) == 0 {
+		return nil
+	}
+	if templateLibraries == nil {
+		templateLibraries = loadTemplateLibraries(common.AssetsPath)
+	}
+	libraryFiles := []string{}
+	for _, ref := range tc.Spec.TemplateLibraries {
+		library, err := getTemplateLibrary(ref)
+		if err != nil {
+			logrus.Errorf("failed to find the template library %s used by the transformer %s . Error: %q", ref, tc.Name, err)
+			continue
+		}
+		files, err := getTemplateLibraryFiles(library)
+		if err != nil {
+			logrus.Errorf("failed to find the template files of the library %s . Error: %q", ref, err)
+			continue
+		}
+		logrus.Debugf("the transformer %s uses the version %s of the template library %s", tc.Name, library.Spec.Version, library.Name)
+		libraryFiles = append(libraryFiles, files...)
+	}
+	return libraryFiles
+}
+
+// getTemplateLibrary returns the latest version of the library that matches the reference
+func getTemplateLibrary(ref string) (transformertypes.TemplateLibrary, error) {
+	name, constraintStr := ref, ""
+	if idx := strings.Index(ref, templateLibraryVersionSeparator); idx >= 0 {
+		name, constraintStr = ref[:idx], ref[idx+1:]
+	}
+	versions, ok := templateLibraries[name]
+	if !ok || len(versions) == 0 {
+		return transformertypes.TemplateLibrary{}, fmt.Errorf("there is no template library named %s", name)
+	}
+	if constraintStr == "" {
+		return versions[0], nil
+	}
+	constraint, err := semver.NewConstraint(constraintStr)
+	if err != nil {
+		return transformertypes.TemplateLibrary{}, fmt.Errorf("the version constraint %s is invalid. Error: %q", constraintStr, err)
+	}
+	available := []string{}
+	for _, library := range versions {
+		if constraint.Check(semver.MustParse(library.Spec.Version)) {
+			return library, nil
+		}
+		available = append(available, library.Spec.Version)
+	}
+	return transformertypes.TemplateLibrary{}, fmt.Errorf("none of the versions %s match the constraint %s", strings.Join(available, ", "), constraintStr)
+}
+
+// addTemplateLibraries adds the template files of the libraries to the template path mappings
+func addTemplateLibraries(pathMappings []transformertypes.PathMapping, tc transformertypes.Transformer) []transformertypes.PathMapping {
+	libraryFiles := getTemplateLibrariesForTransformer(tc)
+	if len(libraryFiles) == 0 {
+		return pathMappings
+	}
+	for i, pathMapping := range pathMappings {
+		if strings.EqualFold(string(pathMapping.Type), string(transformertypes.TemplatePathMappingType)) || strings.EqualFold(string(pathMapping.Type), string(transformertypes.SpecialTemplatePathMappingType)) {
+			pathMappings[i].TemplateLibraries = libraryFiles
+		}
+	}
+	return pathMappings
+}
@@ -0,0 +1,137 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func writeTemplateLibrary(t *testing.T, assetsPath, dir, name, version, templates string) {
+	t.Helper()
+	libraryDir := filepath.Join(assetsPath, dir)
+	if err := os.MkdirAll(filepath.Join(libraryDir, "templates"), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the template library directory. Error: %q", err)
+	}
+	library := "apiVersion: move2kube.konveyor.io/v1alpha1\nkind: TemplateLibrary\nmetadata:\n  name: " + name + "\nspec:\n  version: " + version + "\n"
+	if err := os.WriteFile(filepath.Join(libraryDir, "library.yaml"), []byte(library), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the template library. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(libraryDir, "templates", "helpers.tpl"), []byte(templates), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the templates of the library. Error: %q", err)
+	}
+}
+
+func setupTemplateLibraries(t *testing.T) string {
+	assetsPath := t.TempDir()
+	writeTemplateLibrary(t, assetsPath, "acme-1.0.0", "acme", "1.0.0", `{{- define "acme.labels" -}}app: {{ .Name }}{{- end -}}`)
+	writeTemplateLibrary(t, assetsPath, "acme-1.2.0", "acme", "1.2.0", `{{- define "acme.labels" -}}app.kubernetes.io/name: {{ .Name }}{{- end -}}`)
+	writeTemplateLibrary(t, assetsPath, "acme-2.0.0", "acme", "2.0.0", `{{- define "acme.labels" -}}name: {{ .Name }}{{- end -}}`)
+	writeTemplateLibrary(t, assetsPath, "bad-version", "other", "latest", `{{- define "other.labels" -}}{{- end -}}`)
+	writeTemplateLibrary(t, assetsPath, "bad-names", "unscoped", "1.0.0", `{{- define "labels" -}}{{- end -}}`)
+	oldTemplateLibraries := templateLibraries
+	templateLibraries = loadTemplateLibraries(assetsPath)
+	t.Cleanup(func() { templateLibraries = oldTemplateLibraries })
+	return assetsPath
+}
+
+func TestLoadTemplateLibraries(t *testing.T) {
+	setupTemplateLibraries(t)
+	versions := []string{}
+	for _, library := range templateLibraries["acme"] {
+		versions = append(versions, library.Spec.Version)
+	}
+	if diff := cmp.Diff([]string{"2.0.0", "1.2.0", "1.0.0"}, versions); diff != "" {
+		t.Fatalf("expected the versions of the library in descending order. Difference:\n%s", diff)
+	}
+	for _, name := range []string{"other", "unscoped"} {
+		if _, ok := templateLibraries[name]; ok {
+			t.Fatalf("expected the invalid template library %s to be ignored", name)
+		}
+	}
+}
+
+func TestGetTemplateLibrary(t *testing.T) {
+	setupTemplateLibraries(t)
+	testCases := []struct {
+		ref      string
+		expected string
+		wantErr  bool
+	}{
+		{ref: "acme", expected: "2.0.0"},
+		{ref: "acme@^1.0", expected: "1.2.0"},
+		{ref: "acme@~1.0.0", expected: "1.0.0"},
+		{ref: "acme@>=3", wantErr: true},
+		{ref: "acme@not-a-constraint", wantErr: true},
+		{ref: "missing", wantErr: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.ref, func(t *testing.T) {
+			library, err := getTemplateLibrary(testCase.ref)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error. Actual: %+v", library)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get the template library. Error: %q", err)
+			}
+			if library.Spec.Version != testCase.expected {
+				t.Fatalf("wrong version of the template library. Expected: %s Actual: %s", testCase.expected, library.Spec.Version)
+			}
+		})
+	}
+}
+
+func TestAddTemplateLibraries(t *testing.T) {
+	assetsPath := setupTemplateLibraries(t)
+	tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{TemplateLibraries: []string{"acme@^1.0", "missing"}}}
+	pathMappings := addTemplateLibraries([]transformertypes.PathMapping{
+		{Type: transformertypes.TemplatePathMappingType, SrcPath: "templates"},
+		{Type: transformertypes.DefaultPathMappingType, SrcPath: "files"},
+	}, tc)
+	expected := []string{filepath.Join(assetsPath, "acme-1.2.0", "templates", "helpers.tpl")}
+	if diff := cmp.Diff(expected, pathMappings[0].TemplateLibraries); diff != "" {
+		t.Fatalf("wrong template libraries for the template path mapping. Difference:\n%s", diff)
+	}
+	if len(pathMappings[1].TemplateLibraries) != 0 {
+		t.Fatalf("expected no template libraries for the default path mapping. Actual: %+v", pathMappings[1].TemplateLibraries)
+	}
+
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "deployment.yaml"), []byte(`labels: {{ include "acme.labels" . | upper }}`), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the template. Error: %q", err)
+	}
+	destDir := t.TempDir()
+	if err := filesystem.TemplateCopy(srcDir, destDir, filesystem.AddOnConfig{Config: map[string]string{"Name": "web"}, Libraries: pathMappings[0].TemplateLibraries}); err != nil {
+		t.Fatalf("failed to copy the template. Error: %q", err)
+	}
+	data, err := os.ReadFile(filepath.Join(destDir, "deployment.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read the templated file. Error: %q", err)
+	}
+	if actual := string(data); actual != "labels: APP.KUBERNETES.IO/NAME: WEB" {
+		t.Fatalf("wrong templated file. Actual: %q", actual)
+	}
+}
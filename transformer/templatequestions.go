@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/mitchellh/mapstructure"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+const (
+	// questionConfigPathSeparator separates the keys of the path in the template config that an answer is set at
+	questionConfigPathSeparator = "."
+)
+
+// addQuestionAnswers asks the questions declared in the transformer config and sets the answers in the template configs of the path mappings
+func addQuestionAnswers(pathMappings []transformertypes.PathMapping, tc transformertypes.Transformer) []transformertypes.PathMapping {
+	if len(tc.Spec.Questions) == 0 {
+		return pathMappings
+	}
+	templateIdxs := []int{}
+	for i, pathMapping := range pathMappings {
+		if strings.EqualFold(string(pathMapping.Type), string(transformertypes.TemplatePathMappingType)) || strings.EqualFold(string(pathMapping.Type), string(transformertypes.SpecialTemplatePathMappingType)) {
+			templateIdxs = append(templateIdxs, i)
+		}
+	}
+	if len(templateIdxs) == 0 {
+		return pathMappings
+	}
+	answers := getQuestionAnswers(tc)
+	for _, i := range templateIdxs {
+		config, err := getTemplateConfigMap(pathMappings[i].TemplateConfig)
+		if err != nil {
+			logrus.Errorf("failed to add the answers of the questions of the transformer %s to the template config of %s . Error: %q", tc.Name, pathMappings[i].SrcPath, err)
+			continue
+		}
+		for _, question := range tc.Spec.Questions {
+			answer, ok := answers[question.ID]
+			if !ok {
+				continue
+			}
+			configPath := question.ConfigPath
+			if configPath == "" {
+				configPath = question.ID
+			}
+			if err := setTemplateConfigValue(config, strings.Split(configPath, questionConfigPathSeparator), answer); err != nil {
+				logrus.Errorf("failed to set the answer of the question %s of the transformer %s at %s in the template config. Error: %q", question.ID, tc.Name, configPath, err)
+			}
+		}
+		pathMappings[i].TemplateConfig = config
+	}
+	return pathMappings
+}
+
+// getQuestionAnswers asks the questions declared in the transformer config and returns the answers keyed by the question ids
+func getQuestionAnswers(tc transformertypes.Transformer) map[string]interface{} {
+	answers := map[string]interface{}{}
+	for _, question := range tc.Spec.Questions {
+		if question.ID == "" {
+			logrus.Errorf("the transformer %s has a question without an id. Ignoring it.", tc.Name)
+			continue
+		}
+		problem, err := getQuestionProblem(question, getQuestionKey(tc.Name, question.ID))
+		if err != nil {
+			logrus.Errorf("the question %s of the transformer %s is invalid. Ignoring it. Error: %q", question.ID, tc.Name, err)
+			continue
+		}
+		problem, err = qaengine.FetchAnswer(problem)
+		if err != nil {
+			logrus.Errorf("failed to fetch the answer of the question %s of the transformer %s . Error: %q", question.ID, tc.Name, err)
+			continue
+		}
+		answers[question.ID] = problem.Answer
+	}
+	return answers
+}
+
+// getQuestionKey returns the QA key of a question. Ids that do not start with the base key are relative to the key of the transformer.
+func getQuestionKey(transformerName, id string) string {
+	if strings.HasPrefix(id, common.BaseKey+questionConfigPathSeparator) {
+		return id
+	}
+	return common.JoinQASubKeys(common.ConfigTransformersKey, `"`+transformerName+`"`, id)
+}
+
+// getQuestionProblem creates the problem for a declared question
+func getQuestionProblem(question transformertypes.Question, key string) (qatypes.Problem, error) {
+	switch question.Type {
+	case qatypes.SelectSolutionFormType:
+		def := cast.ToString(question.Default)
+		if def == "" && len(question.Options) > 0 {
+			def = question.Options[0]
+		}
+		return qatypes.NewSelectProblem(key, question.Description, question.Hints, def, question.Options)
+	case qatypes.MultiSelectSolutionFormType:
+		return qatypes.NewMultiSelectProblem(key, question.Description, question.Hints, cast.ToStringSlice(question.Default), question.Options)
+	case qatypes.ConfirmSolutionFormType:
+		return qatypes.NewConfirmProblem(key, question.Description, question.Hints, cast.ToBool(question.Default))
+	case qatypes.MultilineInputSolutionFormType:
+		return qatypes.NewMultilineInputProblem(key, question.Description, question.Hints, cast.ToString(question.Default))
+	case qatypes.PasswordSolutionFormType:
+		return qatypes.NewPasswordProblem(key, question.Description, question.Hints)
+	case qatypes.InputSolutionFormType, "":
+		return qatypes.NewInputProblem(key, question.Description, question.Hints, cast.ToString(question.Default))
+	}
+	return qatypes.Problem{}, fmt.Errorf("unsupported question type %s", question.Type)
+}
+
+// getTemplateConfigMap converts the template config to a map, keeping the field names of structs, so the existing templates keep working
+func getTemplateConfigMap(templateConfig interface{}) (map[string]interface{}, error) {
+	if templateConfig == nil {
+		return map[string]interface{}{}, nil
+	}
+	if config, ok := templateConfig.(map[string]interface{}); ok {
+		return config, nil
+	}
+	config := map[string]interface{}{}
+	if err := mapstructure.Decode(templateConfig, &config); err != nil {
+		return nil, fmt.Errorf("the template config of type %T is not an object. Error: %q", templateConfig, err)
+	}
+	return config, nil
+}
+
+// setTemplateConfigValue sets the value at the path in the template config, creating the objects along the path
+func setTemplateConfigValue(config map[string]interface{}, keys []string, value interface{}) error {
+	if len(keys) == 1 {
+		config[keys[0]] = value
+		return nil
+	}
+	child, err := getTemplateConfigMap(config[keys[0]])
+	if err != nil {
+		return err
+	}
+	config[keys[0]] = child
+	return setTemplateConfigValue(child, keys[1:], value)
+}
@@ -0,0 +1,92 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestAddQuestionAnswers(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.transformers."MyTransformer".replicas="3"`,
+		`move2kube.features.tracing=false`,
+	}, nil, nil, false, false)
+
+	type templateConfig struct {
+		Name string
+	}
+	tc := transformertypes.Transformer{Spec: transformertypes.TransformerSpec{Questions: []transformertypes.Question{
+		{ID: "replicas", Description: "Enter the number of replicas :", Default: "2", ConfigPath: "app.replicas"},
+		{ID: "tier", Type: qatypes.SelectSolutionFormType, Description: "Select the tier :", Options: []string{"frontend", "backend"}},
+		{ID: "move2kube.features.tracing", Type: qatypes.ConfirmSolutionFormType, Description: "Enable tracing?", Default: true, ConfigPath: "tracing"},
+		{ID: "ports", Type: qatypes.MultiSelectSolutionFormType, Description: "Select the ports :", Options: []string{"8080", "8443"}, Default: []interface{}{"8443"}},
+		{Description: "A question without an id"},
+		{ID: "unsupported", Type: "Bogus", Description: "A question of an unsupported type"},
+	}}}
+	tc.Name = "MyTransformer"
+	pathMappings := addQuestionAnswers([]transformertypes.PathMapping{
+		{Type: transformertypes.TemplatePathMappingType, TemplateConfig: templateConfig{Name: "web"}},
+		{Type: transformertypes.SpecialTemplatePathMappingType, TemplateConfig: map[string]interface{}{"app": map[string]interface{}{"port": 8080}}},
+		{Type: transformertypes.DefaultPathMappingType},
+	}, tc)
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"Name":    "web",
+			"app":     map[string]interface{}{"replicas": "3"},
+			"tier":    "frontend",
+			"tracing": false,
+			"ports":   []string{"8443"},
+		},
+		map[string]interface{}{
+			"app":     map[string]interface{}{"port": 8080, "replicas": "3"},
+			"tier":    "frontend",
+			"tracing": false,
+			"ports":   []string{"8443"},
+		},
+		nil,
+	}
+	for i, pathMapping := range pathMappings {
+		if diff := cmp.Diff(expected[i], pathMapping.TemplateConfig); diff != "" {
+			t.Fatalf("wrong template config for the path mapping %d . Difference:\n%s", i, diff)
+		}
+	}
+}
+
+func TestGetQuestionKey(t *testing.T) {
+	if actual := getQuestionKey("MyTransformer", "replicas"); actual != `move2kube.transformers."MyTransformer".replicas` {
+		t.Fatalf("wrong key for a relative question id. Actual: %s", actual)
+	}
+	if actual := getQuestionKey("MyTransformer", "move2kube.target.replicas"); actual != "move2kube.target.replicas" {
+		t.Fatalf("wrong key for an absolute question id. Actual: %s", actual)
+	}
+}
+
+func TestSetTemplateConfigValue(t *testing.T) {
+	config := map[string]interface{}{"app": "not an object"}
+	if err := setTemplateConfigValue(config, []string{"app", "replicas"}, 3); err == nil {
+		t.Fatalf("expected an error when setting a value inside a string. Actual: %+v", config)
+	}
+}
@@ -17,6 +17,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -26,6 +27,7 @@ import (
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/telemetry"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/filesystem"
 	"github.com/konveyor/move2kube/qaengine"
@@ -56,6 +58,11 @@ type Transformer interface {
 	Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error)
 }
 
+// stoppableTransformer is implemented by the transformers that run servers, which have to be stopped at the end of the run
+type stoppableTransformer interface {
+	Stop()
+}
+
 type processType int
 
 const (
@@ -69,12 +76,17 @@ var (
 	transformerTypes = map[string]reflect.Type{}
 	transformers     = []Transformer{}
 	transformerMap   = map[string]Transformer{}
+	// budgetExceeded is set when the run exceeds its budget. No more transformers are run after that.
+	budgetExceeded *common.BudgetExceededError
 )
 
 func init() {
 	transformerObjs := []Transformer{
 		new(external.Starlark),
 		new(external.Executable),
+		new(external.GRPCTransformer),
+		new(external.WASM),
+		new(external.Webhook),
 
 		new(Router),
 
@@ -97,6 +109,22 @@ func init() {
 		new(java.MavenAnalyser),
 		new(java.GradleAnalyser),
 		new(java.ZuulAnalyser),
+		new(OpenAPIAnalyser),
+		new(GRPCAnalyser),
+		new(ReverseProxyAnalyser),
+		new(CronJobAnalyser),
+		new(JVMHeapAnalyser),
+		new(ScalingAnalyser),
+		new(SidecarInjector),
+		new(VaultAnalyser),
+		new(MainframeAnalyser),
+		new(ConnectivityAnalyser),
+		new(ExternalServiceAnalyser),
+		new(EventDrivenAnalyser),
+		new(GPUAnalyser),
+		new(LocaleAnalyser),
+		new(BackstageCatalogGenerator),
+		new(IaCGenerator),
 		new(windows.WinConsoleAppDockerfileGenerator),
 		new(windows.WinSilverLightWebAppDockerfileGenerator),
 		new(windows.WinWebAppDockerfileGenerator),
@@ -113,9 +141,12 @@ func init() {
 		new(kubernetes.Knative),
 		new(kubernetes.Tekton),
 		new(kubernetes.ArgoCD),
+		new(kubernetes.GitOps),
 		new(kubernetes.BuildConfig),
 		new(kubernetes.Parameterizer),
 		new(kubernetes.KubernetesVersionChanger),
+		new(kubernetes.HelmChartLoader),
+		new(kubernetes.KustomizationLoader),
 
 		new(ReadMeGenerator),
 	}
@@ -135,8 +166,9 @@ func RegisterTransformer(tf Transformer) error {
 	return nil
 }
 
-// Init initializes the transformers
-func Init(assetsPath, sourcePath string, selector labels.Selector, outputPath, projName string) (err error) {
+// Init initializes the transformers.
+// The environments of the transformers stop their commands and containers when the context is cancelled.
+func Init(ctx context.Context, assetsPath, sourcePath string, selector labels.Selector, outputPath, projName string) (err error) {
 	filePaths, err := common.GetFilesByExt(assetsPath, []string{".yml", ".yaml"})
 	if err != nil {
 		return fmt.Errorf("failed to look for yaml files in the directory %s . Error: %q", assetsPath, err)
@@ -153,14 +185,14 @@ func Init(assetsPath, sourcePath string, selector labels.Selector, outputPath, p
 		}
 		transformerFiles[tc.Name] = filePath
 	}
-	if err := InitTransformers(transformerFiles, selector, sourcePath, outputPath, projName, false); err != nil {
+	if err := InitTransformers(ctx, transformerFiles, selector, sourcePath, outputPath, projName, false); err != nil {
 		return fmt.Errorf("failed to initialize the transformers. Error: %q", err)
 	}
 	return nil
 }
 
 // InitTransformers initializes a subset of transformers
-func InitTransformers(transformerToInit map[string]string, selector labels.Selector, sourcePath string, outputPath, projName string, logError bool) error {
+func InitTransformers(ctx context.Context, transformerToInit map[string]string, selector labels.Selector, sourcePath string, outputPath, projName string, logError bool) error {
 	if initialized {
 		return nil
 	}
@@ -181,51 +213,79 @@ func InitTransformers(transformerToInit map[string]string, selector labels.Selec
 	sort.Strings(transformerNames)
 	selectedTransformerNames := qaengine.FetchMultiSelectAnswer(common.ConfigTransformerTypesKey, "Select all transformer types that you are interested in:", []string{"Services that don't support any of the transformer types you are interested in will be ignored."}, transformerNames, transformerNames)
 	for _, selectedTransformerName := range selectedTransformerNames {
-		transformerConfig := transformerConfigs[selectedTransformerName]
-		transformerClass, ok := transformerTypes[transformerConfig.Spec.Class]
-		if !ok {
-			logrus.Errorf("failed to find the transformer class %s . Valid tranformer classes are: %+v", transformerConfig.Spec.Class, transformerTypes)
-			continue
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
-		transformer := reflect.New(transformerClass).Interface().(Transformer)
-		transformerContextPath := filepath.Dir(transformerConfig.Spec.FilePath)
-		envInfo := environment.EnvInfo{
-			Name:            transformerConfig.Name,
-			ProjectName:     projName,
-			Isolated:        transformerConfig.Spec.Isolated,
-			Source:          sourcePath,
-			Output:          outputPath,
-			Context:         transformerContextPath,
-			RelTemplatesDir: transformerConfig.Spec.TemplatesDir,
-		}
-		for src, dest := range transformerConfig.Spec.ExternalFiles {
-			if err := filesystem.Replicate(filepath.Join(transformerContextPath, src), filepath.Join(transformerContextPath, dest)); err != nil {
-				logrus.Errorf("Error while copying external files in transformer %s (%s:%s) : %s", transformerConfig.Name, src, dest, err)
-			}
-		}
-		env, err := environment.NewEnvironment(envInfo, nil, environmenttypes.Container{})
+		transformerConfig := transformerConfigs[selectedTransformerName]
+		transformer, err := newTransformer(ctx, transformerConfig, sourcePath, outputPath, projName)
 		if err != nil {
-			logrus.Errorf("failed to create the environment %+v . Error: %q", envInfo, err)
-			return err
-		}
-		if err := transformer.Init(transformerConfig, env); err != nil {
+			if _, ok := err.(*environmentCreationError); ok {
+				logrus.Errorf("%s", err)
+				return err
+			}
 			if _, ok := err.(*transformertypes.TransformerDisabledError); ok {
 				logrus.Debugf("Unable to initialize transformer %s . Error: %q", transformerConfig.Name, err)
 			} else {
 				logrus.Errorf("Unable to initialize transformer %s . Error: %q", transformerConfig.Name, err)
 			}
-		} else {
-			transformers = append(transformers, transformer)
-			transformerMap[selectedTransformerName] = transformer
+			continue
 		}
+		transformers = append(transformers, transformer)
+		transformerMap[selectedTransformerName] = transformer
 	}
 	initialized = true
 	return nil
 }
 
+// environmentCreationError is returned when the environment of a transformer cannot be created
+type environmentCreationError struct {
+	envInfo environment.EnvInfo
+	err     error
+}
+
+func (e *environmentCreationError) Error() string {
+	return fmt.Sprintf("failed to create the environment %+v . Error: %q", e.envInfo, e.err)
+}
+
+// newTransformer creates and initializes a transformer along with its environment
+func newTransformer(ctx context.Context, transformerConfig transformertypes.Transformer, sourcePath, outputPath, projName string) (Transformer, error) {
+	transformerClass, ok := transformerTypes[transformerConfig.Spec.Class]
+	if !ok {
+		return nil, fmt.Errorf("failed to find the transformer class %s . Valid tranformer classes are: %+v", transformerConfig.Spec.Class, transformerTypes)
+	}
+	transformer := reflect.New(transformerClass).Interface().(Transformer)
+	transformerContextPath := filepath.Dir(transformerConfig.Spec.FilePath)
+	envInfo := environment.EnvInfo{
+		Name:            transformerConfig.Name,
+		ProjectName:     projName,
+		Isolated:        transformerConfig.Spec.Isolated,
+		Source:          sourcePath,
+		Output:          outputPath,
+		Context:         transformerContextPath,
+		RelTemplatesDir: transformerConfig.Spec.TemplatesDir,
+		Ctx:             ctx,
+	}
+	for src, dest := range transformerConfig.Spec.ExternalFiles {
+		if err := filesystem.Replicate(filepath.Join(transformerContextPath, src), filepath.Join(transformerContextPath, dest)); err != nil {
+			logrus.Errorf("Error while copying external files in transformer %s (%s:%s) : %s", transformerConfig.Name, src, dest, err)
+		}
+	}
+	env, err := environment.NewEnvironment(envInfo, nil, environmenttypes.Container{})
+	if err != nil {
+		return nil, &environmentCreationError{envInfo: envInfo, err: err}
+	}
+	if err := transformer.Init(transformerConfig, env); err != nil {
+		return nil, err
+	}
+	return transformer, nil
+}
+
 // Destroy destroys the transformers
 func Destroy() {
 	for _, t := range transformers {
+		if st, ok := t.(stoppableTransformer); ok {
+			st.Stop()
+		}
 		_, env := t.GetConfig()
 		if err := env.Destroy(); err != nil {
 			logrus.Errorf("Unable to destroy environment : %s", err)
@@ -262,12 +322,19 @@ func GetInitializedTransformersF(filters labels.Selector) []Transformer {
 	return filteredTransformers
 }
 
-// GetServices returns the list of services detected in a directory
-func GetServices(prjName string, dir string) (map[string][]plantypes.PlanArtifact, error) {
+// GetServices returns the list of services detected in a directory.
+// The directories and files that exceed the detection limits are skipped.
+// It stops detecting and returns the error of the context when the context is cancelled.
+func GetServices(ctx context.Context, prjName string, dir string) (map[string][]plantypes.PlanArtifact, error) {
 	services := map[string][]plantypes.PlanArtifact{}
+	common.StartDetection(dir)
+	defer common.StopDetection()
 	logrus.Infoln("Planning started on the base directory")
 	logrus.Debugf("Transformers: %+v", transformers)
 	for _, transformer := range transformers {
+		if ctx.Err() != nil {
+			return services, ctx.Err()
+		}
 		config, env := transformer.GetConfig()
 		if err := env.Reset(); err != nil {
 			logrus.Errorf("failed to reset the environment for the transformer %s . Error: %q", config.Name, err)
@@ -293,7 +360,10 @@ func GetServices(prjName string, dir string) (map[string][]plantypes.PlanArtifac
 	logrus.Infof("[Base Directory] %s", getNamedAndUnNamedServicesLogMessage(services))
 	logrus.Infoln("Planning finished on the base directory")
 	logrus.Infoln("Planning started on its sub directories")
-	nservices, err := walkForServices(dir, services)
+	nservices, err := walkForServices(ctx, dir, services)
+	if ctx.Err() != nil {
+		return services, ctx.Err()
+	}
 	if err != nil {
 		logrus.Errorf("Transformation planning - Directory Walk failed : %s", err)
 	} else {
@@ -302,11 +372,13 @@ func GetServices(prjName string, dir string) (map[string][]plantypes.PlanArtifac
 	}
 	logrus.Infof("[Directory Walk] %s", getNamedAndUnNamedServicesLogMessage(services))
 	services = nameServices(prjName, services)
+	services = groupServices(dir, services)
+	services = NormalizeServiceNames(services, true)
 	logrus.Infof("[Named Services] Identified %d named services", len(services))
 	return services, nil
 }
 
-func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArtifact) (map[string][]plantypes.PlanArtifact, error) {
+func walkForServices(ctx context.Context, inputPath string, bservices map[string][]plantypes.PlanArtifact) (map[string][]plantypes.PlanArtifact, error) {
 	services := bservices
 	ignoreDirectories, ignoreContents := getIgnorePaths(inputPath)
 	knownServiceDirPaths := []string{}
@@ -319,11 +391,17 @@ func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArti
 		if !info.IsDir() {
 			return nil
 		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
 			if dirRegExp.Match([]byte(filepath.Base(path))) {
 				return filepath.SkipDir
 			}
 		}
+		if common.SkipDirForDetection(path) {
+			return filepath.SkipDir
+		}
 		if common.IsPresent(knownServiceDirPaths, path) {
 			return filepath.SkipDir // TODO: Should we go inside the directory in this case?
 		}
@@ -401,8 +479,11 @@ func summarizePathMappings(pathMappings []transformertypes.PathMapping) string {
 }
 
 // Transform transforms as per the plan
-func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath string) error {
+// If the run exceeds its budget, the output of the transformers that already ran is written and a *common.BudgetExceededError is returned.
+// If the context is cancelled, the output of the transformers that already ran is written and the error of the context is returned.
+func Transform(ctx context.Context, planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath string) error {
 	var allArtifacts []transformertypes.Artifact
+	ctx = startStepping(ctx)
 	newArtifactsToProcess := []transformertypes.Artifact{}
 	pathMappings := []transformertypes.PathMapping{}
 	iteration := 1
@@ -425,6 +506,7 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 		newArtifactsToProcess = append(newArtifactsToProcess, planArtifact.Artifact)
 	}
 	allArtifacts = newArtifactsToProcess
+	setBuildContextOverrides(planArtifacts, sourceDir, outputPath)
 
 	// logging
 	graph := graphtypes.NewGraph()
@@ -437,7 +519,7 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 	for {
 		iteration++
 		logrus.Infof("Iteration %d - %d artifacts to process", iteration, len(newArtifactsToProcess))
-		newPathMappings, newArtifacts, _ := transform(newArtifactsToProcess, allArtifacts, consume, nil, graph, iteration)
+		newPathMappings, newArtifacts, _ := transform(ctx, newArtifactsToProcess, allArtifacts, consume, nil, graph, iteration)
 		pathMappings = append(pathMappings, newPathMappings...)
 		if err := os.RemoveAll(outputPath); err != nil {
 			return fmt.Errorf("failed to remove the output directory %s . Error: %q", outputPath, err)
@@ -445,7 +527,7 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 		if err := processPathMappings(pathMappings, sourceDir, outputPath); err != nil {
 			return fmt.Errorf("failed to process the path mappings: %+v . Error: %q", pathMappings, err)
 		}
-		if len(newArtifacts) == 0 {
+		if len(newArtifacts) == 0 || budgetExceeded != nil || ctx.Err() != nil {
 			break
 		}
 		logrus.Infof("Created %d pathMappings and %d artifacts. Total Path Mappings : %d. Total Artifacts : %d.", len(newPathMappings), len(newArtifacts), len(pathMappings), len(allArtifacts))
@@ -469,14 +551,18 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 	}
 	// logging
 
-	return nil
+	if budgetExceeded != nil {
+		return budgetExceeded
+	}
+	return ctx.Err()
 }
 
-func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact, pt processType, depSel labels.Selector, graph *graphtypes.Graph, iteration int) (pathMappings []transformertypes.PathMapping, newArtifactsCreated, updatedArtifacts []transformertypes.Artifact) {
+func transform(ctx context.Context, newArtifactsToProcess, allArtifacts []transformertypes.Artifact, pt processType, depSel labels.Selector, graph *graphtypes.Graph, iteration int) (pathMappings []transformertypes.PathMapping, newArtifactsCreated, updatedArtifacts []transformertypes.Artifact) {
 	if pt == dependency && (depSel == nil || depSel.String() == "") {
 		return nil, nil, newArtifactsToProcess
 	}
 	for _, transformer := range transformers {
+		transformer = reloadIfChanged(transformer)
 		tConfig, env := transformer.GetConfig()
 		if pt == dependency && !depSel.Matches(labels.Set(tConfig.Labels)) {
 			continue
@@ -485,11 +571,24 @@ func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact,
 		if len(artifactsToProcess) == 0 {
 			continue
 		}
+		if budgetExceeded == nil && common.HasRunBudget() {
+			if budgetExceeded = common.CheckRunBudget(tConfig.Name); budgetExceeded != nil {
+				logrus.Warnf("Stopping the transform, since %s . The output of the transformers that already ran will be written.", budgetExceeded)
+			}
+		}
+		if budgetExceeded != nil {
+			logrus.Debugf("Skipping the transformer %s since the run exceeded its budget", tConfig.Name)
+			continue
+		}
+		if ctx.Err() != nil {
+			logrus.Debugf("Skipping the transformer %s since the run was interrupted", tConfig.Name)
+			continue
+		}
 
 		logrus.Debugf("Transformer %s will be processing %d artifacts in %d mode", tConfig.Name, len(artifactsToProcess), pt)
 
 		// Dependency processing
-		dependencyCreatedNewPathMappings, dependencyCreatedNewArtifacts, dependencyUpdatedArtifacts := transform(artifactsToProcess, allArtifacts, dependency, tConfig.Spec.DependencySelector, graph, iteration)
+		dependencyCreatedNewPathMappings, dependencyCreatedNewArtifacts, dependencyUpdatedArtifacts := transform(ctx, artifactsToProcess, allArtifacts, dependency, tConfig.Spec.DependencySelector, graph, iteration)
 		pathMappings = append(pathMappings, dependencyCreatedNewPathMappings...)
 		// Dependency processing
 
@@ -500,7 +599,30 @@ func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact,
 
 		logrus.Infof("Transformer %s processing %d artifacts", tConfig.Name, len(artifactsToConsume))
 
+		if !stepBeforeTransformer(iteration, tConfig, artifactsToConsume) {
+			continue
+		}
+		if err := runPreTransformerHooks(tConfig.Name, len(artifactsToConsume)); err != nil {
+			logrus.Errorf("Skipping the transformer %s since a hook run before it failed. Error: %q", tConfig.Name, err)
+			continue
+		}
+		doneTransformerRun := telemetry.StartTransformerRun(tConfig.Spec.Class)
 		producedNewPathMappings, producedNewArtifacts, err := runSingleTransform(artifactsToConsume, allArtifacts, transformer, tConfig, env, graph, iteration)
+		doneTransformerRun()
+		writeDebugSnapshot(iteration, tConfig, env, artifactsToConsume, producedNewArtifacts, producedNewPathMappings, err)
+		stepAfterTransformer(tConfig, producedNewArtifacts, producedNewPathMappings, err)
+		runPostTransformerHooks(tConfig.Name, len(producedNewArtifacts), len(producedNewPathMappings), err)
+		if ctx.Err() != nil && budgetExceeded == nil && common.HasRunBudget() {
+			// the context of the run is cancelled when the run exceeds its budget
+			if budgetExceeded = common.CheckRunBudget(tConfig.Name); budgetExceeded != nil {
+				logrus.Warnf("The transformer %s was stopped since %s . The output of the transformers that already ran will be written.", tConfig.Name, budgetExceeded)
+				continue
+			}
+		}
+		if err != nil && ctx.Err() != nil {
+			logrus.Warnf("The transformer %s was stopped since the run was interrupted. Error: %q", tConfig.Name, err)
+			continue
+		}
 		if err != nil {
 			logrus.Errorf("failed to run a single transformation using the transformer %+v on the artifacts %+v . Error: %q", tConfig, artifactsToConsume, err)
 			continue
@@ -521,7 +643,7 @@ func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact,
 			}
 		}
 
-		passedThroughPathMappings, passedThroughNewArtifactsCreated, passedThroughUpdatedArtifacts := transform(artifactsToPassThrough, allArtifacts, passthrough, nil, graph, iteration)
+		passedThroughPathMappings, passedThroughNewArtifactsCreated, passedThroughUpdatedArtifacts := transform(ctx, artifactsToPassThrough, allArtifacts, passthrough, nil, graph, iteration)
 
 		pathMappings = append(pathMappings, passedThroughPathMappings...)
 		newArtifactsCreated = append(newArtifactsCreated, passedThroughNewArtifactsCreated...)
@@ -606,13 +728,25 @@ func runSingleTransform(artifactsToProcess, allArtifacts []transformertypes.Arti
 		}
 	}
 	newArtifacts = filteredArtifacts
+	for i := range newArtifacts {
+		if newArtifacts[i].ProducedBy == "" {
+			newArtifacts[i].ProducedBy = tconfig.Name
+		}
+	}
 	newPathMappings = env.ProcessPathMappings(newPathMappings)
 	newPathMappings = *env.DownloadAndDecode(&newPathMappings, true).(*[]transformertypes.PathMapping)
+	newPathMappings = overrideDockerfileTemplates(newPathMappings, newArtifacts, tconfig)
+	newPathMappings = addTemplateLibraries(newPathMappings, tconfig)
+	newPathMappings = addQuestionAnswers(newPathMappings, tconfig)
 	if err := processPathMappings(newPathMappings, env.Source, env.Output); err != nil {
 		return newPathMappings, newArtifacts, fmt.Errorf("failed to process the path mappings: %+v . Error: %q", newPathMappings, err)
 	}
 	newArtifacts = *env.DownloadAndDecode(&newArtifacts, false).(*[]transformertypes.Artifact)
+	for i, newArtifact := range newArtifacts {
+		newArtifacts[i] = validateArtifactConfigs(newArtifact, tconfig)
+	}
 	newArtifacts = postProcessArtifacts(newArtifacts, tconfig)
+	newArtifacts = overrideBuildContexts(newArtifacts)
 	return newPathMappings, newArtifacts, nil
 }
 
@@ -666,5 +800,5 @@ func getArtifactsToProcess(newArtifactsToProcess, allArtifacts []transformertype
 
 	}
 
-	return artifactsToProcess, artifactsToNotProcess
+	return applyMergeRules(artifactsToProcess, tConfig), artifactsToNotProcess
 }
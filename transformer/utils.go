@@ -50,6 +50,12 @@ func getTransformerConfig(path string) (tc transformertypes.Transformer, err err
 		tc.Labels = map[string]string{}
 	}
 	tc.Labels[transformertypes.LabelName] = tc.Name
+	// the same transformer can be reused across environments by using environment variables in its config
+	tc.Spec.Config = common.ExpandEnvVarsInObject(tc.Spec.Config)
+	tc.Spec.TemplatesDir = common.ExpandEnvVars(tc.Spec.TemplatesDir)
+	for src, dest := range tc.Spec.ExternalFiles {
+		tc.Spec.ExternalFiles[src] = common.ExpandEnvVars(dest)
+	}
 	if tc.Spec.OverrideSelector, err = getSelectorFromInterface(tc.Spec.Override); err != nil {
 		logrus.Errorf("Unable to parse override selector for %s, Ignoring selector : %+v", tc.Name, tc.Spec.Override)
 		tc.Spec.OverrideSelector = nil
@@ -214,13 +220,22 @@ func getPlanArtifactsFromArtifacts(services map[string][]transformertypes.Artifa
 		for _, st := range s {
 			planServices[sn] = append(planServices[sn], plantypes.PlanArtifact{
 				TransformerName: t.Name,
-				Artifact:        st,
+				Artifact:        validateArtifactConfigs(st, t),
 			})
 		}
 	}
 	return planServices
 }
 
+// validateArtifactConfigs warns about the configs of the artifact that are invalid against their registered schemas.
+// The artifact is returned as it is, so that no data is lost when a schema is wrong.
+func validateArtifactConfigs(artifact transformertypes.Artifact, t transformertypes.Transformer) transformertypes.Artifact {
+	for _, err := range artifacts.GetInvalidConfigErrors(artifact) {
+		logrus.Warnf("The transformer %s produced the artifact %s of type %s with a config that does not match its schema. Error: %q", t.Name, artifact.Name, artifact.Type, err)
+	}
+	return artifact
+}
+
 func getNamedAndUnNamedServicesLogMessage(services map[string][]plantypes.PlanArtifact) string {
 	nnservices := len(services)
 	nuntransformers := len(services[""])
@@ -250,6 +265,10 @@ func getFilteredTransformers(transformerPaths map[string]string, selector labels
 			logrus.Debugf("Ignoring transformer %s because of filter", tn)
 			continue
 		}
+		if tc.Spec.FeatureGate != "" && !common.IsFeatureEnabled(tc.Spec.FeatureGate) {
+			logrus.Debugf("Ignoring transformer %s because the feature gate %s is not enabled", tn, tc.Spec.FeatureGate)
+			continue
+		}
 		if tc.Spec.OverrideSelector != nil {
 			overrideSelectors = append(overrideSelectors, tc.Spec.OverrideSelector)
 		}
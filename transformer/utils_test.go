@@ -0,0 +1,85 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestValidateArtifactConfigs(t *testing.T) {
+	irConfig := ir.NewIR()
+	irConfig.Name = "app1"
+	artifact := transformertypes.Artifact{
+		Name: "svc1",
+		Type: ir.IRArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{
+			ir.IRConfigType:             irConfig,
+			artifacts.ServiceConfigType: map[string]interface{}{"serviceName": []interface{}{"svc1"}},
+		},
+	}
+	expected := transformertypes.Artifact{
+		Name: "svc1",
+		Type: ir.IRArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{
+			ir.IRConfigType:             irConfig,
+			artifacts.ServiceConfigType: map[string]interface{}{"serviceName": []interface{}{"svc1"}},
+		},
+	}
+	actual := validateArtifactConfigs(artifact, transformertypes.Transformer{})
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("expected the configs that do not match their schemas to be kept. Difference:\n%s", diff)
+	}
+}
+
+func TestGetFilteredTransformersFeatureGates(t *testing.T) {
+	transformersDir := t.TempDir()
+	transformerPaths := map[string]string{}
+	for name, featureGate := range map[string]string{"Stable": "", "Experimental": "ExperimentalTransformerGate", "Preview": "PreviewTransformerGate"} {
+		config := "apiVersion: move2kube.konveyor.io/v1alpha1\nkind: Transformer\nmetadata:\n  name: " + name + "\nspec:\n  class: Kubernetes\n"
+		if featureGate != "" {
+			config += "  featureGate: " + featureGate + "\n"
+		}
+		transformerPath := filepath.Join(transformersDir, name+".yaml")
+		if err := os.WriteFile(transformerPath, []byte(config), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the transformer config %s . Error: %q", name, err)
+		}
+		transformerPaths[name] = transformerPath
+	}
+	if err := common.SetFeatureGates([]string{"PreviewTransformerGate=true"}); err != nil {
+		t.Fatalf("failed to set the feature gates. Error: %q", err)
+	}
+	t.Cleanup(func() { common.SetFeatureGates([]string{"PreviewTransformerGate=false"}) })
+
+	names := []string{}
+	for name := range getFilteredTransformers(transformerPaths, labels.Everything(), true) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if diff := cmp.Diff([]string{"Preview", "Stable"}, names); diff != "" {
+		t.Fatalf("expected the transformers whose feature gates are disabled to be ignored. Difference:\n%s", diff)
+	}
+}
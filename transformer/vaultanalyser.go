@@ -0,0 +1,446 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	kubernetesSecretsProvider = "kubernetes"
+	vaultAgentSecretsProvider = "vaultagent"
+	vaultCSISecretsProvider   = "vaultcsi"
+
+	defaultVaultDir        = common.DeployDir + string(os.PathSeparator) + "vault"
+	defaultVaultAddress    = "http://vault.vault:8200"
+	vaultAnnotationPrefix  = "vault.hashicorp.com/"
+	vaultAgentSecretsDir   = "/vault/secrets"
+	vaultCSIDriver         = "secrets-store.csi.k8s.io"
+	vaultCSIVolumeName     = "vault-secrets"
+	vaultCSIMountPath      = "/mnt/secrets-store"
+	secretProviderClassAPI = "secrets-store.csi.x-k8s.io/v1"
+)
+
+// VaultAnalyser implements Transformer interface
+type VaultAnalyser struct {
+	Config      transformertypes.Transformer
+	Env         *environment.Environment
+	VaultConfig *VaultYamlConfig
+}
+
+// VaultYamlConfig stores the yaml configuration for the Vault transformer
+type VaultYamlConfig struct {
+	OutputPath string `yaml:"outputPath" json:"outputPath"`
+}
+
+// vaultSecretUse is how a service uses a Kubernetes secret
+type vaultSecretUse struct {
+	keys map[string]bool
+	// files maps the keys to the names of the files in the secret volumes, when they differ from the keys
+	files map[string]string
+	// volumes are the names of the secret volumes
+	volumes []string
+	// envVars maps the environment variables to the keys of the secret. All the keys are used by the containers that have the secret in envFrom.
+	envVars map[string]string
+	envFrom bool
+}
+
+// Init Initializes the transformer
+func (t *VaultAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.VaultConfig = &VaultYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.VaultConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.VaultConfig, err)
+		return err
+	}
+	if t.VaultConfig.OutputPath == "" {
+		t.VaultConfig.OutputPath = defaultVaultDir
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *VaultAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *VaultAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform makes the services that are chosen by the user fetch their secrets from Vault, using the Vault Agent injector or the secrets store CSI driver
+func (t *VaultAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		serviceNames := []string{}
+		for sn := range ir.Services {
+			serviceNames = append(serviceNames, sn)
+		}
+		sort.Strings(serviceNames)
+		// the secrets that are still used by services that do not fetch them from Vault
+		kubernetesSecrets := map[string]bool{}
+		vaultSecrets := map[string]bool{}
+		for _, sn := range serviceNames {
+			s := ir.Services[sn]
+			secretUses := getVaultSecretUses(ir, s)
+			if len(secretUses) == 0 {
+				continue
+			}
+			secretNames := getSortedSecretNames(secretUses)
+			qaKeyPrefix := common.JoinQASubKeys(common.ConfigServicesKey, `"`+sn+`"`)
+			provider := qaengine.FetchSelectAnswer(
+				common.JoinQASubKeys(qaKeyPrefix, common.ConfigSecretsProviderKeySegment),
+				fmt.Sprintf("Select where the service %s should fetch its secrets %s from:", sn, strings.Join(secretNames, ", ")),
+				[]string{
+					vaultAgentSecretsProvider + " : the Vault Agent injector writes the secrets to files in the pods",
+					vaultCSISecretsProvider + " : the secrets store CSI driver mounts the secrets from Vault and syncs them to Kubernetes secrets",
+				},
+				kubernetesSecretsProvider,
+				[]string{kubernetesSecretsProvider, vaultAgentSecretsProvider, vaultCSISecretsProvider},
+			)
+			if provider == kubernetesSecretsProvider {
+				for _, secretName := range secretNames {
+					kubernetesSecrets[secretName] = true
+				}
+				continue
+			}
+			role := qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(qaKeyPrefix, common.ConfigVaultRoleKeySegment),
+				fmt.Sprintf("Enter the Vault role of the service %s :", sn),
+				[]string{"The role has to be bound to the service account of the service in the Kubernetes auth method of Vault"},
+				sn,
+			)
+			paths := map[string]string{}
+			for _, secretName := range secretNames {
+				paths[secretName] = qaengine.FetchStringAnswer(
+					common.JoinQASubKeys(qaKeyPrefix, common.ConfigVaultPathsKeySegment, `"`+secretName+`"`),
+					fmt.Sprintf("Enter the Vault path of the secret %s of the service %s :", secretName, sn),
+					[]string{"The path of a secret in a KV version 2 secrets engine, including the data segment"},
+					fmt.Sprintf("secret/data/%s/%s", t.Env.GetProjectName(), secretName),
+				)
+				vaultSecrets[secretName] = true
+			}
+			if provider == vaultAgentSecretsProvider {
+				addVaultAgentAnnotations(&s, role, secretUses, paths)
+			} else {
+				if err := addVaultCSIVolume(&s, sn); err != nil {
+					logrus.Errorf("failed to add the Vault CSI volume to the service %s . Error: %q", sn, err)
+					continue
+				}
+				address := qaengine.FetchStringAnswer(common.ConfigVaultAddressKey, "Enter the address of the Vault server:", []string{"The secrets store CSI driver fetches the secrets from this address"}, defaultVaultAddress)
+				obj, err := getSecretProviderClass(sn, role, address, secretUses, paths)
+				if err != nil {
+					logrus.Errorf("failed to create the secret provider class of the service %s . Error: %q", sn, err)
+					continue
+				}
+				pathMapping, err := t.writeVaultYaml(common.NormalizeForMetadataName(sn)+"-secretproviderclass.yaml", obj)
+				if err != nil {
+					logrus.Errorf("failed to write the secret provider class of the service %s . Error: %q", sn, err)
+					continue
+				}
+				pathMappings = append(pathMappings, pathMapping)
+			}
+			ir.Services[sn] = s
+		}
+		// the secrets in Vault are not generated as Kubernetes secrets, unless other services still use them
+		storages := []irtypes.Storage{}
+		for _, storage := range ir.Storages {
+			if storage.StorageType == irtypes.SecretKind && vaultSecrets[storage.Name] && !kubernetesSecrets[storage.Name] {
+				logrus.Debugf("the secret %s is fetched from Vault. Not generating a Kubernetes secret for it", storage.Name)
+				continue
+			}
+			storages = append(storages, storage)
+		}
+		ir.Storages = storages
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return pathMappings, artifactsCreated, nil
+}
+
+// writeVaultYaml writes the object to a file in the output directory of the transformer
+func (t *VaultAnalyser) writeVaultYaml(fileName string, obj interface{}) (transformertypes.PathMapping, error) {
+	tempDest := filepath.Join(t.Env.TempPath, t.VaultConfig.OutputPath)
+	if err := os.MkdirAll(tempDest, common.DefaultDirectoryPermission); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to create the directory %s . Error: %q", tempDest, err)
+	}
+	file := filepath.Join(tempDest, fileName)
+	if err := common.WriteYaml(file, obj); err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to write the yaml to the file at path %s . Error: %q", file, err)
+	}
+	destPath, err := filepath.Rel(t.Env.TempPath, file)
+	if err != nil {
+		return transformertypes.PathMapping{}, fmt.Errorf("failed to make the yaml path %s relative to the temporary directory %s . Error: %q", file, t.Env.TempPath, err)
+	}
+	return transformertypes.PathMapping{Type: transformertypes.DefaultPathMappingType, SrcPath: file, DestPath: destPath}, nil
+}
+
+// getVaultSecretUses finds the secrets that the service mounts as volumes or uses in environment variables.
+// The keys of the secrets are taken from the references and from the secrets in the IR.
+func getVaultSecretUses(ir irtypes.IR, service irtypes.Service) map[string]*vaultSecretUse {
+	uses := map[string]*vaultSecretUse{}
+	getUse := func(secretName string) *vaultSecretUse {
+		if _, ok := uses[secretName]; !ok {
+			uses[secretName] = &vaultSecretUse{keys: map[string]bool{}, files: map[string]string{}, envVars: map[string]string{}}
+		}
+		return uses[secretName]
+	}
+	for _, volume := range service.Volumes {
+		if volume.Secret == nil || volume.Secret.SecretName == "" {
+			continue
+		}
+		use := getUse(volume.Secret.SecretName)
+		use.volumes = append(use.volumes, volume.Name)
+		for _, item := range volume.Secret.Items {
+			use.keys[item.Key] = true
+			if item.Path != "" && item.Path != item.Key {
+				use.files[item.Key] = item.Path
+			}
+		}
+	}
+	for _, container := range service.Containers {
+		for _, envVar := range container.Env {
+			if envVar.ValueFrom == nil || envVar.ValueFrom.SecretKeyRef == nil {
+				continue
+			}
+			use := getUse(envVar.ValueFrom.SecretKeyRef.Name)
+			use.envVars[envVar.Name] = envVar.ValueFrom.SecretKeyRef.Key
+			use.keys[envVar.ValueFrom.SecretKeyRef.Key] = true
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				getUse(envFrom.SecretRef.Name).envFrom = true
+			}
+		}
+	}
+	for _, storage := range ir.Storages {
+		if use, ok := uses[storage.Name]; ok && storage.StorageType == irtypes.SecretKind {
+			for key := range storage.Content {
+				use.keys[key] = true
+			}
+		}
+	}
+	return uses
+}
+
+// addVaultAgentAnnotations annotates the pods of the service for the Vault Agent injector.
+// Each key of a secret volume is rendered to a file at the mount path of the volume, so the files stay where the service expects them.
+// The environment variables are rendered to a file that the entrypoint of the container has to source.
+func addVaultAgentAnnotations(service *irtypes.Service, role string, uses map[string]*vaultSecretUse, paths map[string]string) {
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[vaultAnnotationPrefix+"agent-inject"] = "true"
+	service.Annotations[vaultAnnotationPrefix+"role"] = role
+	removedVolumes := map[string]bool{}
+	envFiles := []string{}
+	for _, secretName := range getSortedSecretNames(uses) {
+		use := uses[secretName]
+		path := paths[secretName]
+		for _, volumeName := range use.volumes {
+			mountPath := getVolumeMountPath(*service, volumeName)
+			for _, key := range getSortedKeys(use.keys) {
+				fileName := key
+				if file, ok := use.files[key]; ok {
+					fileName = file
+				}
+				if _, ok := service.Annotations[vaultAnnotationPrefix+"agent-inject-secret-"+fileName]; ok {
+					logrus.Warnf("the key %s of the secret %s is written to the same file as a key of another secret of the service %s . Not injecting it from Vault.", key, secretName, service.Name)
+					continue
+				}
+				service.Annotations[vaultAnnotationPrefix+"agent-inject-secret-"+fileName] = path
+				service.Annotations[vaultAnnotationPrefix+"agent-inject-template-"+fileName] = fmt.Sprintf(`{{- with secret "%s" -}}{{ index .Data.data "%s" }}{{- end }}`, path, key)
+				if mountPath != "" {
+					service.Annotations[vaultAnnotationPrefix+"secret-volume-path-"+fileName] = mountPath
+				}
+			}
+			if len(use.keys) == 0 {
+				logrus.Warnf("the keys of the secret %s of the service %s are not known. Add the agent-inject-secret annotations for them.", secretName, service.Name)
+			}
+			removedVolumes[volumeName] = true
+		}
+		if len(use.envVars) == 0 && !use.envFrom {
+			continue
+		}
+		envFileName := secretName + ".env"
+		template := fmt.Sprintf(`{{- with secret "%s" }}`, path)
+		if use.envFrom {
+			template += "{{ range $k, $v := .Data.data }}\nexport {{ $k }}=\"{{ $v }}\"{{ end }}"
+		} else {
+			envNames := []string{}
+			for envName := range use.envVars {
+				envNames = append(envNames, envName)
+			}
+			sort.Strings(envNames)
+			for _, envName := range envNames {
+				template += fmt.Sprintf("\nexport %s=\"{{ index .Data.data \"%s\" }}\"", envName, use.envVars[envName])
+			}
+		}
+		template += "\n{{- end }}"
+		service.Annotations[vaultAnnotationPrefix+"agent-inject-secret-"+envFileName] = path
+		service.Annotations[vaultAnnotationPrefix+"agent-inject-template-"+envFileName] = template
+		envFiles = append(envFiles, vaultAgentSecretsDir+"/"+envFileName)
+	}
+	// the secrets are no longer generated as Kubernetes secrets, so the references to them are removed
+	for i, container := range service.Containers {
+		env := []core.EnvVar{}
+		for _, envVar := range container.Env {
+			if envVar.ValueFrom != nil && envVar.ValueFrom.SecretKeyRef != nil {
+				if _, ok := uses[envVar.ValueFrom.SecretKeyRef.Name]; ok {
+					continue
+				}
+			}
+			env = append(env, envVar)
+		}
+		envFrom := []core.EnvFromSource{}
+		for _, source := range container.EnvFrom {
+			if source.SecretRef != nil {
+				if _, ok := uses[source.SecretRef.Name]; ok {
+					continue
+				}
+			}
+			envFrom = append(envFrom, source)
+		}
+		volumeMounts := []core.VolumeMount{}
+		for _, volumeMount := range container.VolumeMounts {
+			if !removedVolumes[volumeMount.Name] {
+				volumeMounts = append(volumeMounts, volumeMount)
+			}
+		}
+		service.Containers[i].Env = env
+		service.Containers[i].EnvFrom = envFrom
+		service.Containers[i].VolumeMounts = volumeMounts
+	}
+	volumes := []core.Volume{}
+	for _, volume := range service.Volumes {
+		if !removedVolumes[volume.Name] {
+			volumes = append(volumes, volume)
+		}
+	}
+	service.Volumes = volumes
+	if len(envFiles) > 0 {
+		service.Annotations[common.TODOAnnotation+"vault"] = fmt.Sprintf("The entrypoint of the container has to source %s to set the environment variables from Vault", strings.Join(envFiles, " and "))
+	}
+}
+
+// addVaultCSIVolume mounts the secrets of the secret provider class of the service into its containers.
+// The CSI driver syncs the secrets to Kubernetes secrets while the volume is mounted, so the references to the secrets keep working.
+func addVaultCSIVolume(service *irtypes.Service, serviceName string) error {
+	for _, volume := range service.Volumes {
+		if volume.Name == vaultCSIVolumeName {
+			return fmt.Errorf("the service already has a volume named %s", vaultCSIVolumeName)
+		}
+	}
+	readOnly := true
+	service.Volumes = append(service.Volumes, core.Volume{
+		Name: vaultCSIVolumeName,
+		VolumeSource: core.VolumeSource{CSI: &core.CSIVolumeSource{
+			Driver:           vaultCSIDriver,
+			ReadOnly:         &readOnly,
+			VolumeAttributes: map[string]string{"secretProviderClass": getSecretProviderClassName(serviceName)},
+		}},
+	})
+	for i := range service.Containers {
+		service.Containers[i].VolumeMounts = append(service.Containers[i].VolumeMounts, core.VolumeMount{Name: vaultCSIVolumeName, MountPath: vaultCSIMountPath, ReadOnly: true})
+	}
+	return nil
+}
+
+func getSecretProviderClassName(serviceName string) string {
+	return common.NormalizeForMetadataName(serviceName + "-vault")
+}
+
+// getSecretProviderClass returns a secret provider class that fetches the secrets of the service from Vault and syncs them to Kubernetes secrets with the same names
+func getSecretProviderClass(serviceName, role, address string, uses map[string]*vaultSecretUse, paths map[string]string) (map[string]interface{}, error) {
+	objects := []map[string]string{}
+	secretObjects := []interface{}{}
+	for _, secretName := range getSortedSecretNames(uses) {
+		keys := getSortedKeys(uses[secretName].keys)
+		if len(keys) == 0 {
+			logrus.Warnf("the keys of the secret %s of the service %s are not known. Add them to the secret provider class.", secretName, serviceName)
+			continue
+		}
+		data := []interface{}{}
+		for _, key := range keys {
+			objectName := secretName + "-" + key
+			objects = append(objects, map[string]string{"objectName": objectName, "secretPath": paths[secretName], "secretKey": key})
+			data = append(data, map[string]interface{}{"objectName": objectName, "key": key})
+		}
+		secretObjects = append(secretObjects, map[string]interface{}{"secretName": secretName, "type": string(core.SecretTypeOpaque), "data": data})
+	}
+	objectsYaml, err := yaml.Marshal(objects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the objects of the secret provider class. Error: %q", err)
+	}
+	return map[string]interface{}{
+		"apiVersion": secretProviderClassAPI,
+		"kind":       "SecretProviderClass",
+		"metadata":   map[string]interface{}{"name": getSecretProviderClassName(serviceName)},
+		"spec": map[string]interface{}{
+			"provider": "vault",
+			"parameters": map[string]interface{}{
+				"vaultAddress": address,
+				"roleName":     role,
+				"objects":      string(objectsYaml),
+			},
+			"secretObjects": secretObjects,
+		},
+	}, nil
+}
+
+// getVolumeMountPath returns the path at which the containers of the service mount the volume
+func getVolumeMountPath(service irtypes.Service, volumeName string) string {
+	for _, container := range service.Containers {
+		for _, volumeMount := range container.VolumeMounts {
+			if volumeMount.Name == volumeName {
+				return volumeMount.MountPath
+			}
+		}
+	}
+	return ""
+}
+
+// getSortedSecretNames returns the names of the secrets in sorted order
+func getSortedSecretNames(uses map[string]*vaultSecretUse) []string {
+	secretNames := []string{}
+	for secretName := range uses {
+		secretNames = append(secretNames, secretName)
+	}
+	sort.Strings(secretNames)
+	return secretNames
+}
@@ -0,0 +1,181 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"gopkg.in/yaml.v3"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestVaultAnalyserTransform(t *testing.T) {
+	common.TempPath = t.TempDir()
+	qaengine.StartEngine(true, 0, true)
+	qaengine.SetupConfigFile("", []string{
+		`move2kube.services."api".secretsprovider="vaultagent"`,
+		`move2kube.services."api".vaultpaths."tls"="secret/data/certs/api"`,
+		`move2kube.services."worker".secretsprovider="vaultcsi"`,
+		`move2kube.services."worker".vaultrole="workers"`,
+		`move2kube.vault.address="https://vault.example.com:8200"`,
+	}, nil, nil, false, false)
+
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{ProjectName: "shop", TempPath: t.TempDir()}}
+	vaultAnalyser := &VaultAnalyser{}
+	if err := vaultAnalyser.Init(transformertypes.Transformer{}, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+
+	dbPassword := core.EnvVar{Name: "DB_PASSWORD", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "db-creds"}, Key: "password"}}}
+	ir := irtypes.NewIR()
+	frontend := irtypes.NewServiceWithName("frontend")
+	frontend.Containers = []core.Container{{Name: "frontend", Env: []core.EnvVar{dbPassword}}}
+	ir.Services["frontend"] = frontend
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{
+		Name:         "api",
+		Env:          []core.EnvVar{dbPassword, {Name: "PORT", Value: "8080"}},
+		VolumeMounts: []core.VolumeMount{{Name: "tls", MountPath: "/etc/tls"}},
+	}}
+	api.Volumes = []core.Volume{{Name: "tls", VolumeSource: core.VolumeSource{Secret: &core.SecretVolumeSource{
+		SecretName: "tls",
+		Items:      []core.KeyToPath{{Key: "cert", Path: "cert.pem"}},
+	}}}}
+	ir.Services["api"] = api
+	worker := irtypes.NewServiceWithName("worker")
+	worker.Containers = []core.Container{{Name: "worker", EnvFrom: []core.EnvFromSource{{SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "worker-config"}}}}}}
+	ir.Services["worker"] = worker
+	ir.Storages = []irtypes.Storage{
+		{Name: "db-creds", StorageType: irtypes.SecretKind, Content: map[string][]byte{"password": []byte("x")}},
+		{Name: "tls", StorageType: irtypes.SecretKind, Content: map[string][]byte{"cert": []byte("x")}},
+		{Name: "worker-config", StorageType: irtypes.SecretKind, Content: map[string][]byte{"token": []byte("x"), "url": []byte("x")}},
+	}
+
+	artifact := transformertypes.Artifact{Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}}
+	pathMappings, createdArtifacts, err := vaultAnalyser.Transform([]transformertypes.Artifact{artifact}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform the IR. Error: %q", err)
+	}
+	if len(createdArtifacts) != 1 {
+		t.Fatalf("expected a single artifact. Actual: %+v", createdArtifacts)
+	}
+	ir = createdArtifacts[0].Configs[irtypes.IRConfigType].(irtypes.IR)
+
+	t.Run("keep the secrets of the services that use kubernetes secrets", func(t *testing.T) {
+		if diff := cmp.Diff(frontend.Containers, ir.Services["frontend"].Containers); diff != "" {
+			t.Fatalf("the frontend service was changed. Difference:\n%s", diff)
+		}
+		storageNames := []string{}
+		for _, storage := range ir.Storages {
+			storageNames = append(storageNames, storage.Name)
+		}
+		if diff := cmp.Diff([]string{"db-creds"}, storageNames); diff != "" {
+			t.Fatalf("wrong secrets kept in the IR. Difference:\n%s", diff)
+		}
+	})
+
+	t.Run("annotate the service for the vault agent injector", func(t *testing.T) {
+		api := ir.Services["api"]
+		expectedAnnotations := map[string]string{
+			"vault.hashicorp.com/agent-inject":                       "true",
+			"vault.hashicorp.com/role":                               "api",
+			"vault.hashicorp.com/agent-inject-secret-cert.pem":       "secret/data/certs/api",
+			"vault.hashicorp.com/agent-inject-template-cert.pem":     `{{- with secret "secret/data/certs/api" -}}{{ index .Data.data "cert" }}{{- end }}`,
+			"vault.hashicorp.com/secret-volume-path-cert.pem":        "/etc/tls",
+			"vault.hashicorp.com/agent-inject-secret-db-creds.env":   "secret/data/shop/db-creds",
+			"vault.hashicorp.com/agent-inject-template-db-creds.env": "{{- with secret \"secret/data/shop/db-creds\" }}\nexport DB_PASSWORD=\"{{ index .Data.data \"password\" }}\"\n{{- end }}",
+			common.TODOAnnotation + "vault":                          "The entrypoint of the container has to source /vault/secrets/db-creds.env to set the environment variables from Vault",
+		}
+		if diff := cmp.Diff(expectedAnnotations, api.Annotations); diff != "" {
+			t.Fatalf("wrong annotations. Difference:\n%s", diff)
+		}
+		expectedContainers := []core.Container{{Name: "api", Env: []core.EnvVar{{Name: "PORT", Value: "8080"}}, EnvFrom: []core.EnvFromSource{}, VolumeMounts: []core.VolumeMount{}}}
+		if diff := cmp.Diff(expectedContainers, api.Containers); diff != "" {
+			t.Fatalf("the references to the secrets were not removed. Difference:\n%s", diff)
+		}
+		if len(api.Volumes) != 0 {
+			t.Fatalf("expected the secret volume to be removed. Actual: %+v", api.Volumes)
+		}
+	})
+
+	t.Run("mount the secrets store csi volume and write the secret provider class", func(t *testing.T) {
+		worker := ir.Services["worker"]
+		if len(worker.Volumes) != 1 || worker.Volumes[0].CSI == nil || worker.Volumes[0].CSI.VolumeAttributes["secretProviderClass"] != "worker-vault" {
+			t.Fatalf("expected a csi volume for the secret provider class. Actual: %+v", worker.Volumes)
+		}
+		if diff := cmp.Diff([]core.VolumeMount{{Name: "vault-secrets", MountPath: "/mnt/secrets-store", ReadOnly: true}}, worker.Containers[0].VolumeMounts); diff != "" {
+			t.Fatalf("wrong volume mounts. Difference:\n%s", diff)
+		}
+		if len(worker.Containers[0].EnvFrom) != 1 {
+			t.Fatalf("expected the reference to the synced secret to be kept. Actual: %+v", worker.Containers[0].EnvFrom)
+		}
+		expectedPathMappings := []transformertypes.PathMapping{{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  filepath.Join(env.TempPath, "deploy", "vault", "worker-secretproviderclass.yaml"),
+			DestPath: filepath.Join("deploy", "vault", "worker-secretproviderclass.yaml"),
+		}}
+		if diff := cmp.Diff(expectedPathMappings, pathMappings); diff != "" {
+			t.Fatalf("wrong path mappings. Difference:\n%s", diff)
+		}
+		data, err := os.ReadFile(expectedPathMappings[0].SrcPath)
+		if err != nil {
+			t.Fatalf("failed to read the secret provider class. Error: %q", err)
+		}
+		secretProviderClass := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &secretProviderClass); err != nil {
+			t.Fatalf("failed to parse the secret provider class. Error: %q", err)
+		}
+		expected := map[string]interface{}{
+			"apiVersion": "secrets-store.csi.x-k8s.io/v1",
+			"kind":       "SecretProviderClass",
+			"metadata":   map[string]interface{}{"name": "worker-vault"},
+			"spec": map[string]interface{}{
+				"provider": "vault",
+				"parameters": map[string]interface{}{
+					"vaultAddress": "https://vault.example.com:8200",
+					"roleName":     "workers",
+					"objects": `- objectName: worker-config-token
+  secretKey: token
+  secretPath: secret/data/shop/worker-config
+- objectName: worker-config-url
+  secretKey: url
+  secretPath: secret/data/shop/worker-config
+`,
+				},
+				"secretObjects": []interface{}{map[string]interface{}{
+					"secretName": "worker-config",
+					"type":       "Opaque",
+					"data": []interface{}{
+						map[string]interface{}{"objectName": "worker-config-token", "key": "token"},
+						map[string]interface{}{"objectName": "worker-config-url", "key": "url"},
+					},
+				}},
+			},
+		}
+		if diff := cmp.Diff(expected, secretProviderClass); diff != "" {
+			t.Fatalf("wrong secret provider class. Difference:\n%s", diff)
+		}
+	})
+}
@@ -78,6 +78,31 @@ type ClusterMetadataSpec struct {
 	StorageClasses    []string            `yaml:"storageClasses"`
 	APIKindVersionMap map[string][]string `yaml:"apiKindVersionMap"` //[kubernetes kind]["gv1", "gv2",...,"gvn"] prioritized group-version
 	Host              string              `yaml:"host,omitempty"`    // Optional field, either collected with move2kube collect or by asking the user.
+	// CustomResourceDefinitions are the CRDs installed in the cluster, used to validate and convert the custom resources in the source
+	CustomResourceDefinitions []CustomResourceDefinitionMetadata `yaml:"customResourceDefinitions,omitempty"`
+	// NodeResources are the extended resources, like nvidia.com/gpu, that can be allocated on the nodes of the cluster, summed over the nodes.
+	// It is nil when the nodes were not collected, as in the built-in cluster metadata.
+	NodeResources map[string]int64 `yaml:"nodeResources"`
+}
+
+// CustomResourceDefinitionMetadata stores the parts of a CRD required to validate and convert custom resources
+type CustomResourceDefinitionMetadata struct {
+	Group string `yaml:"group"`
+	Kind  string `yaml:"kind"`
+	// ConversionStrategy is the strategy used by the cluster to convert between the versions (None or Webhook)
+	ConversionStrategy string                            `yaml:"conversionStrategy,omitempty"`
+	Versions           []CustomResourceDefinitionVersion `yaml:"versions"`
+}
+
+// CustomResourceDefinitionVersion stores the schema of a single version of a CRD
+type CustomResourceDefinitionVersion struct {
+	Name    string `yaml:"name"`
+	Served  bool   `yaml:"served"`
+	Storage bool   `yaml:"storage"`
+	// Schema is the openAPIV3Schema of the version
+	Schema map[string]interface{} `yaml:"schema,omitempty"`
+	// SpecReplicasPath is the path to the replicas in the scale subresource of the version
+	SpecReplicasPath string `yaml:"specReplicasPath,omitempty"`
 }
 
 // Merge helps merge clustermetadata
@@ -101,6 +126,20 @@ func (c *ClusterMetadataSpec) Merge(newc ClusterMetadataSpec) bool {
 		}
 	}
 	c.APIKindVersionMap = apiversionkindmap
+	// Allow only intersection of custom resource definitions
+	var crds []CustomResourceDefinitionMetadata
+	for _, newCRD := range newc.CustomResourceDefinitions {
+		for _, crd := range c.CustomResourceDefinitions {
+			if crd.Group == newCRD.Group && crd.Kind == newCRD.Kind {
+				crds = append(crds, newCRD)
+				break
+			}
+		}
+	}
+	c.CustomResourceDefinitions = crds
+	if newc.NodeResources != nil {
+		c.NodeResources = newc.NodeResources
+	}
 	c.Host = newc.Host
 	return true
 }
@@ -102,6 +102,28 @@ func TestMerge(t *testing.T) {
 			t.Fatalf("Failed to merge ClusterMetadata properly. Difference:\n%s:", cmp.Diff(want, cmeta1))
 		}
 	})
+
+	t.Run("merging node resources from filled metadata into filled metadata", func(t *testing.T) {
+		cmeta1 := collection.NewClusterMetadata("")
+		cmeta1.Spec.NodeResources = map[string]int64{"nvidia.com/gpu": 2}
+
+		cmeta2 := collection.NewClusterMetadata("")
+		cmeta2.Spec.NodeResources = map[string]int64{"nvidia.com/gpu": 8, "amd.com/gpu": 1}
+
+		want := collection.NewClusterMetadata("")
+		want.Spec.StorageClasses = []string{"default"}
+		want.Spec.NodeResources = map[string]int64{"nvidia.com/gpu": 8, "amd.com/gpu": 1}
+
+		if merged := cmeta1.Merge(cmeta2); !merged || !reflect.DeepEqual(cmeta1, want) {
+			t.Fatalf("Failed to merge ClusterMetadata properly. Difference:\n%s:", cmp.Diff(want, cmeta1))
+		}
+
+		// the node resources are kept when the nodes were not collected
+		cmeta3 := collection.NewClusterMetadata("")
+		if merged := cmeta1.Merge(cmeta3); !merged || !reflect.DeepEqual(cmeta1.Spec.NodeResources, want.Spec.NodeResources) {
+			t.Fatalf("Failed to merge ClusterMetadata properly. Difference:\n%s:", cmp.Diff(want.Spec.NodeResources, cmeta1.Spec.NodeResources))
+		}
+	})
 }
 
 func TestGetSupportedVersions(t *testing.T) {
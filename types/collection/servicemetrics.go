@@ -0,0 +1,43 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collection
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// ServiceMetricsKind defines the kind of the file with the metrics of the services on their current platform
+const ServiceMetricsKind types.Kind = "ServiceMetrics"
+
+// ServiceMetrics has the metrics of the services collected from their current platform, like from its monitoring
+type ServiceMetrics struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             ServiceMetricsSpec `yaml:"spec,omitempty"`
+}
+
+// ServiceMetricsSpec stores the data
+type ServiceMetricsSpec struct {
+	Services []ServiceMetric `yaml:"services"`
+}
+
+// ServiceMetric has the number of instances of a service observed over a period of time
+type ServiceMetric struct {
+	Name             string  `yaml:"name"`
+	AverageInstances float64 `yaml:"averageInstances,omitempty"`
+	PeakInstances    int     `yaml:"peakInstances,omitempty"`
+}
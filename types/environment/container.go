@@ -18,12 +18,70 @@ package environment
 
 // Container stores container based execution information
 type Container struct {
+	// Image is the image to run. If a build is specified, the image is built with this name, which defaults to one derived from the transformer name.
 	Image          string         `yaml:"image"`
 	WorkingDir     string         `yaml:"workingDir,omitempty"`
 	ContainerBuild ContainerBuild `yaml:"build"`
+	// Resources limits the resources of the container, so that a misbehaving transformer cannot use up the resources of the host
+	Resources ContainerResources `yaml:"resources,omitempty"`
+	// PullPolicy is when the image is pulled from its registry. It defaults to Always.
+	PullPolicy PullPolicy `yaml:"pullPolicy,omitempty"`
+	// ReuseOnReset reuses one container for each directory and each run of the transformer, instead of creating a new one each time.
+	// Only the directories uploaded into the container are removed between the runs, so it is only safe for the transformers that do not change the other files of their container.
+	ReuseOnReset bool `yaml:"reuseOnReset,omitempty"`
+	// Network is the network of the container. It defaults to none, so that the transformer cannot send the source code anywhere.
+	Network ContainerNetwork `yaml:"network,omitempty"`
+	// MountSource mounts the source directory read-only into the container instead of copying it, which is faster for large sources.
+	// The source is copied when the engine cannot mount it, like for remote daemons and pods.
+	MountSource bool `yaml:"mountSource,omitempty"`
+	// Platform is the platform of the image, like linux/amd64, which runs using emulation on hosts of other architectures. It defaults to the platform of the host.
+	Platform string `yaml:"platform,omitempty"`
+	// Secrets are injected into the container when it runs, as environment variables of its commands or as files in directories kept in memory,
+	// so that credentials like the tokens of private registries are never stored in its image.
+	Secrets []ContainerSecret `yaml:"secrets,omitempty"`
 }
 
-// ContainerBuild stores container build information
+// ContainerSecret is a value read from an environment variable or a file on the host, which is injected into a container as an environment variable or a file
+type ContainerSecret struct {
+	FromEnv  string `yaml:"fromEnv,omitempty"`  // environment variable on the host
+	FromFile string `yaml:"fromFile,omitempty"` // file on the host, relative to the directory of the transformer yaml
+	Env      string `yaml:"env,omitempty"`      // environment variable of the commands run in the container
+	File     string `yaml:"file,omitempty"`     // absolute path of the file in the container, whose directory is kept in memory
+}
+
+// ContainerNetwork is the network a container is attached to
+type ContainerNetwork string
+
+const (
+	// NetworkNone gives the container no network access
+	NetworkNone ContainerNetwork = "none"
+	// NetworkHost shares the network of the host with the container
+	NetworkHost ContainerNetwork = "host"
+	// NetworkBridge attaches the container to the default network of the engine, which can reach the external hosts
+	NetworkBridge ContainerNetwork = "bridge"
+)
+
+// PullPolicy is when the image of a container is pulled from its registry
+type PullPolicy string
+
+const (
+	// PullAlways pulls the image once in each run, even if it is present locally
+	PullAlways PullPolicy = "Always"
+	// PullIfNotPresent pulls the image only if it is not present locally
+	PullIfNotPresent PullPolicy = "IfNotPresent"
+)
+
+// ContainerResources stores the limits of a container.
+// The CPUs and the memory are quantities like 1.5 or 500m and 512Mi or 2G. The ulimits are like 1024 or 1024:2048 for the soft and hard limits.
+type ContainerResources struct {
+	CPUs    string            `yaml:"cpus,omitempty"`
+	Memory  string            `yaml:"memory,omitempty"`
+	PIDs    int64             `yaml:"pids,omitempty"`
+	Ulimits map[string]string `yaml:"ulimits,omitempty"`
+}
+
+// ContainerBuild stores container build information.
+// The image is built once and only rebuilt when the Dockerfile or the files in the context change.
 type ContainerBuild struct {
 	Dockerfile string `yaml:"dockerfile"` // Default : Look for Dockerfile in the same folder
 	Context    string `yaml:"context"`    // Default : Same folder as the yaml
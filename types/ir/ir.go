@@ -39,6 +39,21 @@ const IRArtifactType transformertypes.ArtifactType = "IR"
 // IRConfigType represents config type of IR
 const IRConfigType transformertypes.ConfigType = "IR"
 
+const (
+	// KafkaEventSourceType is the type of the event sources that are Kafka topics
+	KafkaEventSourceType = "kafka"
+	// RabbitMQEventSourceType is the type of the event sources that are RabbitMQ queues
+	RabbitMQEventSourceType = "rabbitmq"
+	// SQSEventSourceType is the type of the event sources that are AWS SQS queues
+	SQSEventSourceType = "aws-sqs-queue"
+)
+
+// NvidiaGPUResource is the extended resource of the nodes with NVIDIA GPUs, advertised by the NVIDIA device plugin
+const NvidiaGPUResource = "nvidia.com/gpu"
+
+// H2CAppProtocol is the application protocol of the ports that serve HTTP/2 over cleartext, like gRPC services
+const H2CAppProtocol = "kubernetes.io/h2c"
+
 const (
 	// DockerfileContainerBuildType represents dockerfile container build type
 	DockerfileContainerBuildType ContainerBuildTypeValue = "Dockerfile"
@@ -59,6 +74,24 @@ type IR struct {
 	ContainerImages map[string]ContainerImage // [imageName]
 	Services        map[string]Service
 	Storages        []Storage
+
+	// ExternalServices is an optional field with the dependencies that stay outside the cluster
+	ExternalServices map[string]ExternalService
+}
+
+// ExternalService is a dependency that stays outside the cluster, like a mainframe, a managed database or a SaaS.
+// The services reach it using a stable name in the cluster, so it can be migrated later without changing them.
+type ExternalService struct {
+	Name string `yaml:"name" json:"name"`
+	// Host is the DNS name of the dependency, which becomes an ExternalName service
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+	// IPs are the IP addresses of the dependency when it does not have a DNS name, which become an endpoint slice
+	IPs   []string `yaml:"ips,omitempty" json:"ips,omitempty"`
+	Ports []int32  `yaml:"ports,omitempty" json:"ports,omitempty"`
+	// Clients are the names of the services that connect to the dependency
+	Clients []string `yaml:"clients,omitempty" json:"clients,omitempty"`
+	// EgressPolicy allows the clients to connect to the dependency and restricts their other egress to the cluster
+	EgressPolicy bool `yaml:"egressPolicy,omitempty" json:"egressPolicy,omitempty"`
 }
 
 // PodSpec is type alias for core.PodSpec
@@ -102,9 +135,50 @@ type Service struct {
 	Labels                      map[string]string
 	ServiceToPodPortForwardings []ServiceToPodPortForwarding
 	Replicas                    int
+	ReplicasSource              string // Optional field describing where the replica count was inferred from
 	Networks                    []string
+	NetworkAliases              []string // Optional field listing the other names of the service on its networks, which get services of their own
+	Internal                    bool     // Optional field for the services that are only on internal networks, which are not exposed outside the cluster
 	OnlyIngress                 bool
 	Daemon                      bool //Gets converted to DaemonSet
+	Headless                    bool // Optional field to create a headless service, used for client side load balancing
+	SessionAffinity             bool // Optional field to route the requests of each client to the same pod, used for services with local state
+	MaxReplicas                 int  // Optional field to limit the number of replicas, used for services with local state that cannot be scaled horizontally
+
+	// ExternalEndpoints is an optional field listing the endpoints outside the cluster that the service connects to
+	ExternalEndpoints []ExternalEndpoint
+	// EgressPolicy is an optional field to restrict the egress of the service to the cluster and its external endpoints
+	EgressPolicy bool
+	// EventSources is an optional field listing the queues and topics that the service consumes, used to scale it on its events
+	EventSources []EventSource
+	// DeviceRequirements is an optional field listing the devices, like GPUs, that the service needs on its nodes
+	DeviceRequirements []DeviceRequirement
+	// MaxSurge and MaxUnavailable are optional fields tuning the rolling updates of the deployment, as a number or a percentage of the pods
+	MaxSurge       string
+	MaxUnavailable string
+}
+
+// DeviceRequirement is a device that a service needs, requested as an extended resource of the nodes
+type DeviceRequirement struct {
+	Resource string `yaml:"resource" json:"resource"`                 // The extended resource, like nvidia.com/gpu
+	Count    int64  `yaml:"count,omitempty" json:"count,omitempty"`   // The number of devices, if it is known
+	Source   string `yaml:"source,omitempty" json:"source,omitempty"` // The dependency, base image or compose device the requirement was detected from
+}
+
+// EventSource is a message broker or a queue service that a service consumes events from
+type EventSource struct {
+	Type   string `yaml:"type" json:"type"`                         // The KEDA scaler type, like kafka, rabbitmq or aws-sqs-queue
+	Host   string `yaml:"host,omitempty" json:"host,omitempty"`     // The address of the broker, if it is known
+	Source string `yaml:"source,omitempty" json:"source,omitempty"` // The dependency or the service the event source was detected from
+}
+
+// ExternalEndpoint is an endpoint outside the cluster that a service connects to, like a SOAP service, a message broker or a remote EJB
+type ExternalEndpoint struct {
+	Kind   string `yaml:"kind" json:"kind"`
+	URL    string `yaml:"url,omitempty" json:"url,omitempty"`
+	Host   string `yaml:"host,omitempty" json:"host,omitempty"`
+	Port   int32  `yaml:"port,omitempty" json:"port,omitempty"`
+	Source string `yaml:"source,omitempty" json:"source,omitempty"` // The file and line where the endpoint was found
 }
 
 // ServiceToPodPortForwarding forwards a k8s service port to a k8s pod port
@@ -113,6 +187,7 @@ type ServiceToPodPortForwarding struct {
 	PodPort        networking.ServiceBackendPort
 	ServiceRelPath string
 	ServiceType    core.ServiceType
+	AppProtocol    string // Optional field with the application protocol of the port, like kubernetes.io/h2c
 }
 
 // ContainerBuildTypeValue stores the container build type
@@ -147,6 +222,7 @@ type Storage struct {
 	StorageType                    StorageKindType   //Type of storage cfgmap, secret, pvc
 	SecretType                     core.SecretType   // Optional field to store the type of secret data
 	Content                        map[string][]byte //Optional field meant to store content for cfgmap or secret
+	SourcePath                     string            // Optional field storing where the data of a pvc lives on the source platform
 }
 
 const (
@@ -227,17 +303,37 @@ func (service *Service) merge(nService Service) {
 	service.Labels = common.MergeStringMaps(service.Labels, nService.Labels)
 	if nService.Replicas != 0 {
 		service.Replicas = nService.Replicas
+		service.ReplicasSource = nService.ReplicasSource
 	}
 	service.Networks = common.MergeSlices(service.Networks, nService.Networks)
+	service.NetworkAliases = common.MergeSlices(service.NetworkAliases, nService.NetworkAliases)
+	service.Internal = service.Internal || nService.Internal
 	service.OnlyIngress = service.OnlyIngress && nService.OnlyIngress
 	service.Daemon = service.Daemon && nService.Daemon
+	service.Headless = service.Headless || nService.Headless
+	for _, endpoint := range nService.ExternalEndpoints {
+		if !common.IsPresent(service.ExternalEndpoints, endpoint) {
+			service.ExternalEndpoints = append(service.ExternalEndpoints, endpoint)
+		}
+	}
+	service.EgressPolicy = service.EgressPolicy || nService.EgressPolicy
+	if nService.MaxSurge != "" || nService.MaxUnavailable != "" {
+		service.MaxSurge = nService.MaxSurge
+		service.MaxUnavailable = nService.MaxUnavailable
+	}
 	for _, pf := range nService.ServiceToPodPortForwardings {
-		service.AddPortForwarding(pf.ServicePort, pf.PodPort, pf.ServiceRelPath)
+		service.AddServiceToPodPortForwarding(pf)
 	}
 }
 
 // AddPortForwarding adds a new port forwarding to the service.
 func (service *Service) AddPortForwarding(servicePort networking.ServiceBackendPort, podPort networking.ServiceBackendPort, relPath string) error {
+	return service.AddServiceToPodPortForwarding(ServiceToPodPortForwarding{ServicePort: servicePort, PodPort: podPort, ServiceRelPath: relPath})
+}
+
+// AddServiceToPodPortForwarding adds a new port forwarding to the service, keeping all the fields of the forwarding.
+func (service *Service) AddServiceToPodPortForwarding(newForwarding ServiceToPodPortForwarding) error {
+	servicePort, podPort := newForwarding.ServicePort, newForwarding.PodPort
 	if podPort.Number == 0 || servicePort.Number == 0 {
 		return fmt.Errorf("PodPort or ServicePort can not be 0")
 	}
@@ -251,7 +347,6 @@ func (service *Service) AddPortForwarding(servicePort networking.ServiceBackendP
 			return err
 		}
 	}
-	newForwarding := ServiceToPodPortForwarding{ServicePort: servicePort, PodPort: podPort, ServiceRelPath: relPath}
 	for _, pf := range service.ServiceToPodPortForwardings {
 		if pf.PodPort == newForwarding.PodPort || pf.ServicePort == newForwarding.ServicePort {
 			return fmt.Errorf("mapping exists for port %v:%v in service %s. Ignoring", pf.PodPort, pf.ServicePort, service.Name)
@@ -338,6 +433,7 @@ func NewIR() IR {
 	ir.ContainerImages = map[string]ContainerImage{}
 	ir.Services = map[string]Service{}
 	ir.Storages = []Storage{}
+	ir.ExternalServices = map[string]ExternalService{}
 	return ir
 }
 
@@ -361,6 +457,9 @@ func (ir *IR) Merge(newirC interface{}) bool {
 	for _, newst := range newirptr.Storages {
 		ir.AddStorage(newst)
 	}
+	for _, externalService := range newirptr.ExternalServices {
+		ir.AddExternalService(externalService)
+	}
 	return true
 }
 
@@ -377,6 +476,9 @@ func (s *Storage) Merge(newst Storage) bool {
 		}
 		s.StorageType = newst.StorageType
 		s.PersistentVolumeClaimSpec = newst.PersistentVolumeClaimSpec
+		if newst.SourcePath != "" {
+			s.SourcePath = newst.SourcePath
+		}
 		return true
 	}
 	logrus.Debugf("Mismatching storages [%s, %s]", s.Name, newst.Name)
@@ -393,6 +495,26 @@ func (ir *IR) AddContainer(imageName string, container ContainerImage) {
 	}
 }
 
+// AddExternalService adds an external service to the IR. The addresses, ports and clients of an external service with the same name are merged.
+func (ir *IR) AddExternalService(externalService ExternalService) {
+	if ir.ExternalServices == nil {
+		ir.ExternalServices = map[string]ExternalService{}
+	}
+	existing, ok := ir.ExternalServices[externalService.Name]
+	if !ok {
+		ir.ExternalServices[externalService.Name] = externalService
+		return
+	}
+	if externalService.Host != "" {
+		existing.Host = externalService.Host
+	}
+	existing.IPs = common.MergeSlices(existing.IPs, externalService.IPs)
+	existing.Ports = common.MergeSlices(existing.Ports, externalService.Ports)
+	existing.Clients = common.MergeSlices(existing.Clients, externalService.Clients)
+	existing.EgressPolicy = existing.EgressPolicy || externalService.EgressPolicy
+	ir.ExternalServices[externalService.Name] = existing
+}
+
 // AddStorage adds a storage to IR
 func (ir *IR) AddStorage(st Storage) {
 	merged := false
@@ -51,4 +51,6 @@ type TriggerTemplate struct {
 type Pipeline struct {
 	Name          string
 	WorkspaceName string
+	// ImageNames restricts the pipeline to building these images. All the images are built if it is empty.
+	ImageNames []string
 }
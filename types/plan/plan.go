@@ -17,9 +17,13 @@
 package plan
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/types"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,6 +43,8 @@ type Spec struct {
 	CustomizationsDir string `yaml:"customizationsDir,omitempty"`
 
 	Services map[string][]PlanArtifact `yaml:"services"` //[servicename]
+	// Waves groups the services into migration waves, which are migrated in the sorted order of their names
+	Waves map[string][]string `yaml:"waves,omitempty"` //[wave]servicenames
 
 	TransformerSelector metav1.LabelSelector `yaml:"transformerSelector,omitempty"`
 	Transformers        map[string]string    `yaml:"transformers,omitempty" m2kpath:"normal"` //[name]filepath
@@ -70,6 +76,48 @@ func NewPlan() Plan {
 	return plan
 }
 
+// GetServiceWaves returns the migration wave of each service that is in a wave
+func (p Plan) GetServiceWaves() map[string]string {
+	serviceWaves := map[string]string{}
+	for wave, serviceNames := range p.Spec.Waves {
+		for _, serviceName := range serviceNames {
+			if existingWave, ok := serviceWaves[serviceName]; ok && existingWave != wave {
+				logrus.Warnf("The service %s is in the waves %s and %s . Using the wave %s .", serviceName, existingWave, wave, existingWave)
+				continue
+			}
+			serviceWaves[serviceName] = wave
+		}
+	}
+	return serviceWaves
+}
+
+// GetWaves returns the names of the migration waves in sorted order
+func (p Plan) GetWaves() []string {
+	waves := []string{}
+	for wave := range p.Spec.Waves {
+		waves = append(waves, wave)
+	}
+	sort.Strings(waves)
+	return waves
+}
+
+// SelectWaves removes the services that are not in the given migration waves from the plan
+func (p *Plan) SelectWaves(waves []string) error {
+	for _, wave := range waves {
+		if _, ok := p.Spec.Waves[wave]; !ok {
+			return fmt.Errorf("the wave %s is not in the plan. Valid waves are: %+v", wave, p.GetWaves())
+		}
+	}
+	serviceWaves := p.GetServiceWaves()
+	for serviceName := range p.Spec.Services {
+		if !common.IsPresent(waves, serviceWaves[serviceName]) {
+			logrus.Debugf("Ignoring the service %s since it is not in the waves %+v", serviceName, waves)
+			delete(p.Spec.Services, serviceName)
+		}
+	}
+	return nil
+}
+
 // MergeServices merges two service maps
 func MergeServices(s1 map[string][]PlanArtifact, s2 map[string][]PlanArtifact) map[string][]PlanArtifact {
 	if s1 == nil {
@@ -28,3 +28,18 @@ func TestNewPlan(t *testing.T) {
 		t.Error("Failed to instantiate the plan fields properly. Actual:", p)
 	}
 }
+
+func TestSelectWaves(t *testing.T) {
+	p := plan.NewPlan()
+	p.Spec.Services = map[string][]plan.PlanArtifact{"frontend": {}, "orders": {}, "billing": {}}
+	p.Spec.Waves = map[string][]string{"wave-1": {"frontend"}, "wave-2": {"orders"}}
+	if err := p.SelectWaves([]string{"wave-3"}); err == nil {
+		t.Fatal("Expected an error for a wave that is not in the plan")
+	}
+	if err := p.SelectWaves([]string{"wave-2"}); err != nil {
+		t.Fatalf("Failed to select the wave. Error: %q", err)
+	}
+	if _, ok := p.Spec.Services["orders"]; !ok || len(p.Spec.Services) != 1 {
+		t.Fatalf("Expected only the services of the wave to be left. Actual: %+v", p.Spec.Services)
+	}
+}
@@ -17,11 +17,15 @@
 package plan
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/common/jsonschema"
 	"github.com/konveyor/move2kube/common/pathconverters"
 	"github.com/sirupsen/logrus"
 )
@@ -30,6 +34,10 @@ import (
 func ReadPlan(path string, sourceDir string) (Plan, error) {
 	plan := Plan{}
 	var err error
+	if err = ValidatePlanFile(path); err != nil {
+		logrus.Errorf("The plan file at path %s is invalid. Error: %q", path, err)
+		return plan, err
+	}
 	if err = common.ReadMove2KubeYaml(path, &plan); err != nil {
 		logrus.Errorf("Failed to load the plan file at path %q Error %q", path, err)
 		return plan, err
@@ -49,6 +57,32 @@ func ReadPlan(path string, sourceDir string) (Plan, error) {
 	return plan, err
 }
 
+// GetPlanSchema returns the JSON schema of the plan file
+func GetPlanSchema() jsonschema.Schema {
+	return jsonschema.GenerateSchema(reflect.TypeOf(Plan{}), string(PlanKind))
+}
+
+// ValidatePlanFile validates the plan file against the plan schema.
+// The error contains the line and column of every violation, so that hand edited plans are easy to fix.
+func ValidatePlanFile(path string) error {
+	planBytes, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the plan file at path %s . Error: %q", path, err)
+	}
+	violations, err := jsonschema.ValidateYaml(GetPlanSchema(), planBytes)
+	if err != nil {
+		return fmt.Errorf("failed to validate the plan file at path %s . Error: %q", path, err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := []string{}
+	for _, violation := range violations {
+		messages = append(messages, path+":"+violation.String())
+	}
+	return fmt.Errorf("%s", strings.Join(messages, "; "))
+}
+
 // WritePlan encodes the plan to yaml converting absolute paths to relative.
 func WritePlan(path string, plan Plan) error {
 	newPlan := deepcopy.DeepCopy(plan).(Plan)
@@ -17,6 +17,7 @@
 package qaengine_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/konveyor/move2kube/types"
@@ -29,3 +30,91 @@ func TestNewCache(t *testing.T) {
 		t.Fatal("Failed to initialize QACache properly.")
 	}
 }
+
+func TestCacheGetSolution(t *testing.T) {
+	cache := qaengine.NewCache(filepath.Join(t.TempDir(), "cache.yaml"), false)
+	solutions := []qaengine.Problem{
+		{ID: "move2kube.hooks.enable", Type: qaengine.ConfirmSolutionFormType, Answer: true},
+		{ID: "move2kube.target.imageregistry.namespace", Type: qaengine.InputSolutionFormType, Answer: "myproject"},
+		{ID: "move2kube.services.port", Type: qaengine.InputSolutionFormType, Desc: "Enter the port of the service .*", Answer: "8080"},
+	}
+	for _, solution := range solutions {
+		if err := cache.AddSolution(solution); err != nil {
+			t.Fatalf("failed to add the solution %s to the cache. Error: %q", solution.ID, err)
+		}
+	}
+	testcases := []struct {
+		name    string
+		problem qaengine.Problem
+		want    interface{}
+	}{
+		{
+			name:    "same id",
+			problem: qaengine.Problem{ID: "move2kube.target.imageregistry.namespace", Type: qaengine.InputSolutionFormType, Desc: "Enter the namespace :"},
+			want:    "myproject",
+		},
+		{
+			name:    "empty description does not match other problems",
+			problem: qaengine.Problem{ID: "move2kube.target.imageregistry.url", Type: qaengine.InputSolutionFormType, Desc: "Enter the url :"},
+		},
+		{
+			name:    "empty descriptions do not match each other",
+			problem: qaengine.Problem{ID: "move2kube.transformers.enable", Type: qaengine.ConfirmSolutionFormType},
+		},
+		{
+			name:    "description matches the regex of a cached problem",
+			problem: qaengine.Problem{ID: `move2kube.services."svc1".port`, Type: qaengine.InputSolutionFormType, Desc: "Enter the port of the service svc1"},
+			want:    "8080",
+		},
+		{
+			name:    "description matches a cached problem of a different type",
+			problem: qaengine.Problem{ID: `move2kube.services."svc1".port`, Type: qaengine.SelectSolutionFormType, Desc: "Enter the port of the service svc1"},
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			actual, err := cache.GetSolution(testcase.problem)
+			if testcase.want == nil {
+				if err == nil {
+					t.Fatalf("expected no solution. Actual: %+v", actual.Answer)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get the solution. Error: %q", err)
+			}
+			if actual.Answer != testcase.want {
+				t.Fatalf("the solution is wrong. Expected: %+v Actual: %+v", testcase.want, actual.Answer)
+			}
+		})
+	}
+}
+
+func TestCacheLoad(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.yaml")
+	previous := qaengine.NewCache(cachePath, false)
+	for _, solution := range []qaengine.Problem{
+		{ID: "move2kube.hooks.enable", Type: qaengine.ConfirmSolutionFormType, Answer: true},
+		{ID: "move2kube.transformers.enable", Type: qaengine.ConfirmSolutionFormType, Answer: false},
+		{ID: "move2kube.services.port", Type: qaengine.InputSolutionFormType, Desc: "Enter the port", Answer: "9090"},
+	} {
+		if err := previous.AddSolution(solution); err != nil {
+			t.Fatalf("failed to add the solution %s to the cache. Error: %q", solution.ID, err)
+		}
+	}
+	cache := qaengine.NewCache(cachePath, false)
+	cache.Spec.Problems = []qaengine.Problem{{ID: "move2kube.services.port", Type: qaengine.InputSolutionFormType, Desc: "Enter the port", Answer: "8080"}}
+	if err := cache.Load(); err != nil {
+		t.Fatalf("failed to load the cache. Error: %q", err)
+	}
+	// the problems without a description are all kept, while the cached problem with the same description is ignored
+	want := map[string]interface{}{"move2kube.services.port": "8080", "move2kube.hooks.enable": true, "move2kube.transformers.enable": false}
+	if len(cache.Spec.Problems) != len(want) {
+		t.Fatalf("expected %d problems in the cache. Actual: %+v", len(want), cache.Spec.Problems)
+	}
+	for _, problem := range cache.Spec.Problems {
+		if answer, ok := want[problem.ID]; !ok || answer != problem.Answer {
+			t.Fatalf("the problem %s has the wrong answer. Expected: %+v Actual: %+v", problem.ID, answer, problem.Answer)
+		}
+	}
+}
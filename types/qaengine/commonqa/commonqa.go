@@ -81,6 +81,46 @@ func MinimumReplicaCount(defaultminreplicas string) string {
 	return qaengine.FetchStringAnswer(common.ConfigMinReplicasKey, "Provide the minimum number of replicas each service should have", []string{"If the value is 0 pods won't be started by default"}, defaultminreplicas)
 }
 
+// ConfirmInferredReplicaCounts asks whether the replica counts inferred from the source platform should be used
+func ConfirmInferredReplicaCounts(inferredReplicas []string) bool {
+	context := append([]string{"The following replica counts were inferred from the source :"}, inferredReplicas...)
+	context = append(context, "If not confirmed, the minimum replica count will be used for services below it")
+	return qaengine.FetchBoolAnswer(common.ConfigInferredReplicasKey, "Use the replica counts inferred from the source for these services?", context, true)
+}
+
+// HostPathsToConvertToPVC returns the host paths that should be migrated to persistent volume claims
+func HostPathsToConvertToPVC(hostPaths []string) []string {
+	return qaengine.FetchMultiSelectAnswer(common.ConfigStoragesPVCForHostPathKey, "Select the host paths that should be migrated to persistent volume claims :", []string{"Host paths are not portable across nodes. The data in the selected paths can be copied using the generated data migration jobs"}, hostPaths, hostPaths)
+}
+
+// PVCAccessMode returns the access mode for a persistent volume claim
+func PVCAccessMode(claimName string, defaultAccessMode string, accessModes []string) string {
+	key := common.JoinQASubKeys(common.ConfigStoragesKey, `"`+claimName+`"`, common.ConfigStoragesAccessModeKeySuffix)
+	return qaengine.FetchSelectAnswer(key, fmt.Sprintf("Select the access mode for the persistent volume claim %s :", claimName), []string{"ReadWriteMany is required if the volume is shared by multiple services"}, defaultAccessMode, accessModes)
+}
+
+// PVCSize returns the size of a persistent volume claim
+func PVCSize(claimName string, defaultSize string) string {
+	key := common.JoinQASubKeys(common.ConfigStoragesKey, `"`+claimName+`"`, common.ConfigStoragesSizeKeySuffix)
+	return qaengine.FetchStringAnswer(key, fmt.Sprintf("Enter the size of the persistent volume claim %s :", claimName), []string{"Ex : 1Gi, 500Mi"}, defaultSize)
+}
+
+// PerClaimStorageClass returns whether each persistent volume claim should have its own storage class
+func PerClaimStorageClass() bool {
+	return qaengine.FetchBoolAnswer(common.ConfigStoragesPerClaimStorageClassKey, "Do you want to choose a different storage class for each persistent volume claim?", []string{"Otherwise the same storage class will be used for all the claims"}, false)
+}
+
+// StorageClass returns the storage class for a persistent volume claim. Use an empty claim name to get the common storage class.
+func StorageClass(claimName string, storageClasses []string) string {
+	key := common.JoinQASubKeys(common.ConfigStoragesKey, common.ConfigStoragesStorageClassKeySuffix)
+	desc := "Select the storage class for the persistent volume claims :"
+	if claimName != "" {
+		key = common.JoinQASubKeys(common.ConfigStoragesKey, `"`+claimName+`"`, common.ConfigStoragesStorageClassKeySuffix)
+		desc = fmt.Sprintf("Select the storage class for the persistent volume claim %s :", claimName)
+	}
+	return qaengine.FetchSelectAnswer(key, desc, []string{"The storage classes are from the target cluster"}, storageClasses[0], storageClasses)
+}
+
 // GetPortsForService returns ports used by a service
 func GetPortsForService(detectedPorts []int32, qaSubKey string) []int32 {
 	var selectedPortsStr, detectedPortsStr []string
@@ -45,6 +45,9 @@ type Config struct {
 	writeYamlMap     mapT
 	OutputPath       string
 	persistPasswords bool
+	// requestedKeys are the keys of the questions looked up in the config
+	requestedKeys map[string]bool
+	docs          ConfigDocs
 }
 
 var arrayIndexRegex = regexp.MustCompile(`^\[(\d+)\]$`)
@@ -173,6 +176,7 @@ func (c *Config) specialGetSolution(p Problem) (Problem, error) {
 
 // GetSolution reads a solution from the config
 func (c *Config) GetSolution(p Problem) (Problem, error) {
+	c.requestedKeys[p.ID] = true
 	if strings.Contains(p.ID, common.Special) {
 		if p.Type != MultiSelectSolutionFormType {
 			return p, fmt.Errorf("cannot use the %s selector with non multi select problems:%+v", common.Special, p)
@@ -182,9 +186,25 @@ func (c *Config) GetSolution(p Problem) (Problem, error) {
 	return c.normalGetSolution(p)
 }
 
+// Resume loads the answers previously written to the output path.
+// This makes sure they are not lost when the config is written out again.
+func (c *Config) Resume() error {
+	yamlData, err := os.ReadFile(c.OutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the config file at path %s . Error: %q", c.OutputPath, err)
+	}
+	writeYamlMap, err := MergeYAMLDatasIntoMap([]string{string(yamlData)})
+	if err != nil {
+		return fmt.Errorf("failed to parse the config file at path %s . Error: %q", c.OutputPath, err)
+	}
+	c.writeYamlMap = writeYamlMap
+	return nil
+}
+
 // Write writes the config to disk
 func (c *Config) Write() error {
 	logrus.Debugf("Config.Write write the file out")
+	c.writeDocs()
 	return common.WriteYaml(c.OutputPath, c.writeYamlMap)
 }
 
@@ -196,6 +216,7 @@ func (c *Config) AddSolution(p Problem) error {
 		logrus.Warn(err)
 		return err
 	}
+	c.addKeyDoc(p)
 	if p.Type != MultiSelectSolutionFormType {
 		set(p.ID, p.Answer, c.yamlMap)
 		if c.persistPasswords || p.Type != PasswordSolutionFormType {
@@ -264,6 +285,8 @@ func NewConfig(outputPath string, configStrings, configFiles []string, persistPa
 		configStrings:    configStrings,
 		OutputPath:       outputPath,
 		persistPasswords: persistPasswords,
+		requestedKeys:    map[string]bool{},
+		docs:             NewConfigDocs(),
 	}
 }
 
@@ -0,0 +1,84 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestConfigResume(t *testing.T) {
+	previousConfig := "move2kube:\n  target:\n    imageregistry:\n      url: quay.io\n"
+	problem := qaengine.Problem{ID: `move2kube.services."svc1".port`, Type: qaengine.InputSolutionFormType, Desc: "Enter the port :", Answer: "8080"}
+
+	t.Run("keep the answers of the previous run", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "m2kconfig.yaml")
+		if err := os.WriteFile(configPath, []byte(previousConfig), 0644); err != nil {
+			t.Fatalf("failed to write the config file. Error: %q", err)
+		}
+		c := qaengine.NewConfig(configPath, nil, []string{configPath}, false)
+		if err := c.Load(); err != nil {
+			t.Fatalf("failed to load the config. Error: %q", err)
+		}
+		if err := c.Resume(); err != nil {
+			t.Fatalf("failed to resume the config. Error: %q", err)
+		}
+		if err := c.AddSolution(problem); err != nil {
+			t.Fatalf("failed to add the solution. Error: %q", err)
+		}
+		resumed := qaengine.NewConfig("", nil, []string{configPath}, false)
+		if err := resumed.Load(); err != nil {
+			t.Fatalf("failed to load the written config. Error: %q", err)
+		}
+		for key, want := range map[string]string{"move2kube.target.imageregistry.url": "quay.io", problem.ID: "8080"} {
+			if value, ok := resumed.Get(key); !ok || value != want {
+				t.Fatalf("the written config has the wrong value for the key %s . Expected: %s Actual: %+v", key, want, value)
+			}
+		}
+	})
+
+	t.Run("lose the answers of the previous run without resuming", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "m2kconfig.yaml")
+		if err := os.WriteFile(configPath, []byte(previousConfig), 0644); err != nil {
+			t.Fatalf("failed to write the config file. Error: %q", err)
+		}
+		c := qaengine.NewConfig(configPath, nil, nil, false)
+		if err := c.Load(); err != nil {
+			t.Fatalf("failed to load the config. Error: %q", err)
+		}
+		if err := c.AddSolution(problem); err != nil {
+			t.Fatalf("failed to add the solution. Error: %q", err)
+		}
+		written := qaengine.NewConfig("", nil, []string{configPath}, false)
+		if err := written.Load(); err != nil {
+			t.Fatalf("failed to load the written config. Error: %q", err)
+		}
+		if value, ok := written.Get("move2kube.target.imageregistry.url"); ok {
+			t.Fatalf("expected the answer of the previous run to be overwritten. Actual: %+v", value)
+		}
+	})
+
+	t.Run("fail if there is no config to resume", func(t *testing.T) {
+		c := qaengine.NewConfig(filepath.Join(t.TempDir(), "m2kconfig.yaml"), nil, nil, false)
+		if err := c.Resume(); err == nil {
+			t.Fatal("expected resuming without a config file to fail")
+		}
+	})
+}
@@ -0,0 +1,224 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+)
+
+// ConfigDocsKind defines kind of the config key documentation
+const ConfigDocsKind types.Kind = "ConfigDocs"
+
+const (
+	configDocsFileSuffix = ".docs"
+)
+
+// ConfigDocs documents the keys of a config file
+type ConfigDocs struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             ConfigDocsSpec `yaml:"spec,omitempty"`
+}
+
+// ConfigDocsSpec stores the documentation of the keys in the order in which the questions were asked
+type ConfigDocsSpec struct {
+	Keys []ConfigKeyDoc `yaml:"keys"`
+}
+
+// ConfigKeyDoc documents a key of the config file
+type ConfigKeyDoc struct {
+	// Number is the position of the question in the order in which the questions were asked, starting from 1
+	Number        int              `yaml:"number"`
+	Key           string           `yaml:"key"`
+	Question      string           `yaml:"question,omitempty"`
+	Hints         []string         `yaml:"hints,omitempty"`
+	Type          SolutionFormType `yaml:"type,omitempty"`
+	AllowedValues []string         `yaml:"allowedValues,omitempty"`
+	Default       interface{}      `yaml:"default,omitempty"`
+}
+
+// NewConfigDocs creates a new config docs instance
+func NewConfigDocs() ConfigDocs {
+	return ConfigDocs{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(ConfigDocsKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
+
+// GetConfigDocsPath returns the path of the documentation written alongside the config file
+func GetConfigDocsPath(configPath string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + configDocsFileSuffix + ext
+}
+
+// ReadConfigDocs reads the documentation of the config keys from a file
+func ReadConfigDocs(docsPath string) (ConfigDocs, error) {
+	docs := ConfigDocs{}
+	if err := common.ReadMove2KubeYaml(docsPath, &docs); err != nil {
+		return docs, err
+	}
+	return docs, nil
+}
+
+// Find returns the documentation of the key.
+// The key can also be the number of the question.
+func (docs ConfigDocs) Find(key string) (ConfigKeyDoc, bool) {
+	for _, keyDoc := range docs.Spec.Keys {
+		if keyDoc.Key == key || strconv.Itoa(keyDoc.Number) == key {
+			return keyDoc, true
+		}
+	}
+	return ConfigKeyDoc{}, false
+}
+
+// Keys returns all the documented keys
+func (docs ConfigDocs) Keys() []string {
+	keys := []string{}
+	for _, keyDoc := range docs.Spec.Keys {
+		keys = append(keys, keyDoc.Key)
+	}
+	return keys
+}
+
+// addKeyDoc documents the key of the problem. Keys are numbered in the order in which they were first asked.
+func (c *Config) addKeyDoc(p Problem) {
+	keyDoc := ConfigKeyDoc{
+		Key:           p.ID,
+		Question:      p.Desc,
+		Hints:         p.Hints,
+		Type:          p.Type,
+		AllowedValues: p.Options,
+		Default:       p.Default,
+	}
+	if p.Type == PasswordSolutionFormType {
+		keyDoc.Default = nil
+	}
+	for i, existing := range c.docs.Spec.Keys {
+		if existing.Key == p.ID {
+			keyDoc.Number = existing.Number
+			c.docs.Spec.Keys[i] = keyDoc
+			return
+		}
+	}
+	keyDoc.Number = len(c.docs.Spec.Keys) + 1
+	c.docs.Spec.Keys = append(c.docs.Spec.Keys, keyDoc)
+}
+
+// writeDocs writes the documentation of the keys alongside the config file
+func (c *Config) writeDocs() {
+	if c.OutputPath == "" || len(c.docs.Spec.Keys) == 0 {
+		return
+	}
+	docsPath := GetConfigDocsPath(c.OutputPath)
+	if err := common.WriteYaml(docsPath, c.docs); err != nil {
+		logrus.Warnf("failed to write the documentation of the config keys to the file at path %s . Error: %q", docsPath, err)
+	}
+}
+
+// GetRequestedKeys returns the keys of all the questions looked up in the config so far
+func (c *Config) GetRequestedKeys() []string {
+	keys := []string{}
+	for key := range c.requestedKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetKeys returns the keys of all the answers in the config
+func (c *Config) GetKeys() []string {
+	keys := []string{}
+	for _, leafSubKeys := range getLeafSubKeys(c.yamlMap, nil) {
+		keys = append(keys, joinSubKeys(leafSubKeys))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetUnmatchedKeys returns the keys that did not match any of the questions looked up in the config so far
+func (c *Config) GetUnmatchedKeys(keys []string) []string {
+	requestedSubKeys := [][]string{}
+	for key := range c.requestedKeys {
+		requestedSubKeys = append(requestedSubKeys, getSubKeys(key))
+	}
+	unmatchedKeys := []string{}
+	for _, key := range keys {
+		matched := false
+		for _, subKeys := range requestedSubKeys {
+			if matchSubKeys(getSubKeys(key), subKeys) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatchedKeys = append(unmatchedKeys, key)
+		}
+	}
+	return unmatchedKeys
+}
+
+// getLeafSubKeys returns the sub keys of all the leaves in the config
+func getLeafSubKeys(config interface{}, prefix []string) [][]string {
+	subMap, ok := config.(mapT)
+	if !ok || len(subMap) == 0 {
+		if len(prefix) == 0 {
+			return nil
+		}
+		return [][]string{prefix}
+	}
+	leaves := [][]string{}
+	for key, value := range subMap {
+		subKeys := append(append([]string{}, prefix...), key)
+		leaves = append(leaves, getLeafSubKeys(value, subKeys)...)
+	}
+	return leaves
+}
+
+// matchSubKeys checks if a key in the config matches a question key.
+// The special segment of multi select questions matches any of the options.
+func matchSubKeys(configSubKeys, questionSubKeys []string) bool {
+	if len(configSubKeys) != len(questionSubKeys) {
+		return false
+	}
+	for i, questionSubKey := range questionSubKeys {
+		if questionSubKey == common.Special || configSubKeys[i] == common.MatchAll || configSubKeys[i] == questionSubKey {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func joinSubKeys(subKeys []string) string {
+	quotedSubKeys := []string{}
+	for _, subKey := range subKeys {
+		if strings.Contains(subKey, common.Delim) {
+			subKey = `"` + subKey + `"`
+		}
+		quotedSubKeys = append(quotedSubKeys, subKey)
+	}
+	return strings.Join(quotedSubKeys, common.Delim)
+}
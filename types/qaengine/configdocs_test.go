@@ -0,0 +1,58 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestGetUnmatchedKeys(t *testing.T) {
+	c := qaengine.NewConfig("", nil, nil, false)
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load the config. Error: %q", err)
+	}
+	questions := []qaengine.Problem{
+		{ID: "move2kube.target.imageregistry.url", Type: qaengine.InputSolutionFormType},
+		{ID: `move2kube.services."svc1".port`, Type: qaengine.InputSolutionFormType},
+		{ID: "move2kube.services.[].enable", Type: qaengine.MultiSelectSolutionFormType},
+	}
+	for _, question := range questions {
+		if _, err := c.GetSolution(question); err == nil {
+			t.Fatalf("expected the question %s to not have an answer", question.ID)
+		}
+	}
+	keys := []string{
+		"move2kube.target.imageregistry.url",
+		"move2kube.target.imageregistry.ur",
+		"move2kube.services.*.port",
+		"move2kube.services.svc2.enable",
+		"move2kube.services.svc2.enabled",
+	}
+	want := []string{"move2kube.target.imageregistry.ur", "move2kube.services.svc2.enabled"}
+	if got := c.GetUnmatchedKeys(keys); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unmatched keys are wrong. Expected: %v Actual: %v", want, got)
+	}
+}
+
+func TestGetConfigDocsPath(t *testing.T) {
+	if got := qaengine.GetConfigDocsPath("out/m2kconfig.yaml"); got != "out/m2kconfig.docs.yaml" {
+		t.Fatalf("expected out/m2kconfig.docs.yaml Actual: %s", got)
+	}
+}
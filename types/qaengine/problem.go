@@ -178,7 +178,8 @@ func (p *Problem) SetAnswer(ansI interface{}) error {
 
 // Matches checks if the problems are same
 func (p *Problem) matches(np Problem) bool {
-	return p.Type == np.Type && p.matchString(p.Desc, np.Desc)
+	// an empty description would match the description of every problem
+	return p.Type == np.Type && p.Desc != "" && p.matchString(p.Desc, np.Desc)
 }
 
 // Compares str1 with str2 in a case-insensitive manner
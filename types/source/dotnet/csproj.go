@@ -63,8 +63,9 @@ type ItemGroup struct {
 
 // Reference is used in .csproj files to list dependencies
 type Reference struct {
-	XMLName xml.Name `xml:"Reference"`
-	Include string   `xml:"Include,attr"`
+	XMLName  xml.Name `xml:"Reference"`
+	Include  string   `xml:"Include,attr"`
+	HintPath string   `xml:"HintPath,omitempty"`
 }
 
 // PackageReference is used in .csproj files to list dependencies
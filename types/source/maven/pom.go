@@ -388,6 +388,7 @@ type Configuration struct {
 	Classifier            string    `xml:"classifier,omitempty"`
 	Source                string    `xml:"source,omitempty"`
 	Target                string    `xml:"target,omitempty"`
+	Release               string    `xml:"release,omitempty"`
 	ConfigurationProfiles *[]string `xml:"profiles>profile,omitempty"`
 	FinalName             string    `xml:"finalName,omitempty"`
 }
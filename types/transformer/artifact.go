@@ -42,6 +42,8 @@ type Artifact struct {
 
 	Paths   map[PathType][]string      `yaml:"paths,omitempty" json:"paths,omitempty" m2kpath:"normal"`
 	Configs map[ConfigType]interface{} `yaml:"configs,omitempty" json:"config,omitempty"` // Could be IR or template config or any custom configuration
+
+	ProducedBy string `yaml:"-" json:"-"` // name of the transformer that produced the artifact, used by the merge rules
 }
 
 // GetConfig returns the config that has a particular config name
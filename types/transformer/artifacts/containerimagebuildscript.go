@@ -30,8 +30,12 @@ const (
 	ContainerImageBuildShScriptPathType transformertypes.PathType = "ContainerImageBuildShScript"
 	// ContainerImageBuildBatScriptPathType represents the image build script path type
 	ContainerImageBuildBatScriptPathType transformertypes.PathType = "ContainerImageBuildBatScript"
+	// ContainerImageBuildPs1ScriptPathType represents the image build script path type
+	ContainerImageBuildPs1ScriptPathType transformertypes.PathType = "ContainerImageBuildPs1Script"
 	// ContainerImageBuildShScriptContextPathType represents the image build script path type
 	ContainerImageBuildShScriptContextPathType transformertypes.PathType = "ContainerImageBuildShScriptContextScript"
 	// ContainerImageBuildBatScriptContextPathType represents the image build script path type
 	ContainerImageBuildBatScriptContextPathType transformertypes.PathType = "ContainerImageBuildBatScriptContextScript"
+	// ContainerImageBuildPs1ScriptContextPathType represents the image build script path type
+	ContainerImageBuildPs1ScriptContextPathType transformertypes.PathType = "ContainerImageBuildPs1ScriptContextScript"
 )
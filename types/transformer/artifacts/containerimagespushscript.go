@@ -30,4 +30,6 @@ const (
 	ContainerImagesPushShScriptPathType transformertypes.PathType = "ContainerImagesPushShScript"
 	// ContainerImagesPushBatScriptPathType represents the image push script path type
 	ContainerImagesPushBatScriptPathType transformertypes.PathType = "ContainerImagesPushBatScript"
+	// ContainerImagesPushPs1ScriptPathType represents the image push script path type
+	ContainerImagesPushPs1ScriptPathType transformertypes.PathType = "ContainerImagesPushPs1Script"
 )
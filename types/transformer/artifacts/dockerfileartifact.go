@@ -34,4 +34,9 @@ const (
 const (
 	// DockerfileTemplateConfigConfigType stores the imagename for the dockerfile
 	DockerfileTemplateConfigConfigType transformertypes.ConfigType = "DockerfileTemplateConfig"
+	// TemplateConfigType stores the values that are filled into the templates of a transformer, like the output of the detect command of an executable transformer
+	TemplateConfigType transformertypes.ConfigType = "TemplateConfig"
 )
+
+// TemplateConfig stores the values that are filled into the templates of a transformer
+type TemplateConfig map[string]interface{}
@@ -32,4 +32,19 @@ const (
 
 	// KubernetesYamlsPathType is points to the kubernetes Yamls
 	KubernetesYamlsPathType transformertypes.PathType = "KubernetesYamls"
+
+	// HelmChartInSourceArtifactType is the name of the artifact type for helm charts found in the source
+	HelmChartInSourceArtifactType transformertypes.ArtifactType = "HelmChartInSource"
+
+	// HelmChartPathType points to the directory containing a helm chart
+	HelmChartPathType transformertypes.PathType = "HelmChart"
+
+	// KustomizationInSourceArtifactType is the name of the artifact type for kustomizations found in the source
+	KustomizationInSourceArtifactType transformertypes.ArtifactType = "KustomizationInSource"
+
+	// KustomizationOverlayPathType points to the directories containing the overlay kustomizations
+	KustomizationOverlayPathType transformertypes.PathType = "KustomizationOverlay"
+
+	// KustomizationBasePathType points to the directory containing the kustomization that the overlays build on
+	KustomizationBasePathType transformertypes.PathType = "KustomizationBase"
 )
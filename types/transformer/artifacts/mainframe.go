@@ -0,0 +1,82 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package artifacts
+
+import (
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+const (
+	// MainframeAssessmentArtifactType represents the artifact type of the assessment of mainframe assets
+	MainframeAssessmentArtifactType transformertypes.ArtifactType = "MainframeAssessment"
+	// MainframeAssessmentConfigType represents the config type of the assessment of mainframe assets
+	MainframeAssessmentConfigType transformertypes.ConfigType = "MainframeAssessment"
+	// MainframeSourcePathType represents the path type of the mainframe source files
+	MainframeSourcePathType transformertypes.PathType = "MainframeSource"
+)
+
+const (
+	// RehostMainframeStrategy means running the programs unchanged on a mainframe emulator in containers
+	RehostMainframeStrategy = "rehost"
+	// RewriteMainframeStrategy means rewriting the programs in a language that can be containerized directly
+	RewriteMainframeStrategy = "rewrite"
+)
+
+// MainframeAssessment stores the mainframe assets found in a directory and the suggested migration strategy
+type MainframeAssessment struct {
+	// Programs are the COBOL programs
+	Programs []MainframeProgram `yaml:"programs,omitempty" json:"programs,omitempty"`
+	// Copybooks are the COBOL copybooks
+	Copybooks []string `yaml:"copybooks,omitempty" json:"copybooks,omitempty"`
+	// Jobs are the JCL jobs and procedures
+	Jobs []MainframeJob `yaml:"jobs,omitempty" json:"jobs,omitempty"`
+	// CICSDefinitions are the files with CICS resource definitions
+	CICSDefinitions []string `yaml:"cicsDefinitions,omitempty" json:"cicsDefinitions,omitempty"`
+	// CICSTransactions are the transaction IDs defined in the CICS resource definitions
+	CICSTransactions []string `yaml:"cicsTransactions,omitempty" json:"cicsTransactions,omitempty"`
+	// BMSMaps are the BMS map sets of the CICS screens
+	BMSMaps []string `yaml:"bmsMaps,omitempty" json:"bmsMaps,omitempty"`
+	// AssemblerSources are the assembler sources
+	AssemblerSources []string `yaml:"assemblerSources,omitempty" json:"assemblerSources,omitempty"`
+	// Strategy is the suggested migration strategy, either rehost or rewrite
+	Strategy string `yaml:"strategy" json:"strategy"`
+	// Reasons explain the suggested strategy
+	Reasons []string `yaml:"reasons,omitempty" json:"reasons,omitempty"`
+}
+
+// MainframeProgram stores the details of a COBOL program
+type MainframeProgram struct {
+	Path  string `yaml:"path" json:"path"`
+	Lines int    `yaml:"lines" json:"lines"`
+	// Copybooks are the names of the copybooks the program copies
+	Copybooks []string `yaml:"copybooks,omitempty" json:"copybooks,omitempty"`
+	// Calls are the names of the programs the program calls statically
+	Calls    []string `yaml:"calls,omitempty" json:"calls,omitempty"`
+	UsesCICS bool     `yaml:"usesCICS,omitempty" json:"usesCICS,omitempty"`
+	UsesSQL  bool     `yaml:"usesSQL,omitempty" json:"usesSQL,omitempty"`
+	UsesIMS  bool     `yaml:"usesIMS,omitempty" json:"usesIMS,omitempty"`
+}
+
+// MainframeJob stores the details of a JCL job or procedure
+type MainframeJob struct {
+	Path string `yaml:"path" json:"path"`
+	// Programs are the programs run by the steps of the job
+	Programs []string `yaml:"programs,omitempty" json:"programs,omitempty"`
+	// Datasets are the datasets used by the job
+	Datasets []string `yaml:"datasets,omitempty" json:"datasets,omitempty"`
+	UsesVSAM bool     `yaml:"usesVSAM,omitempty" json:"usesVSAM,omitempty"`
+}
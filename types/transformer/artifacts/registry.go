@@ -0,0 +1,165 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package artifacts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/konveyor/move2kube/common/jsonschema"
+	"github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// RegisteredConfigType stores the details of a config type that artifacts can carry
+type RegisteredConfigType struct {
+	ConfigType  transformertypes.ConfigType `json:"configType"`
+	Description string                      `json:"description,omitempty"`
+	Schema      jsonschema.Schema           `json:"schema"`
+}
+
+var (
+	registeredConfigTypes      = map[transformertypes.ConfigType]RegisteredConfigType{}
+	registeredConfigTypesMutex sync.RWMutex
+)
+
+func init() {
+	builtInConfigTypes := []struct {
+		configType  transformertypes.ConfigType
+		obj         interface{}
+		description string
+	}{
+		{ServiceConfigType, ServiceConfig{}, "Name of the service the artifact belongs to"},
+		{ir.IRConfigType, ir.IR{}, "Intermediate representation of the services, images and storages"},
+		{NewImagesConfigType, NewImages{}, "Container images that will be created by the generated build scripts"},
+		{ImageNameConfigType, ImageName{}, "Name of the container image to create for the service"},
+		{MavenConfigType, MavenConfig{}, "Maven project details"},
+		{GradleConfigType, GradleConfig{}, "Gradle project details"},
+		{SpringBootConfigType, SpringBootConfig{}, "Spring Boot application details"},
+		{DotNetConfigType, DotNetConfig{}, "Dot Net project details"},
+		{JarConfigType, JarArtifactConfig{}, "Details required to containerize a jar file"},
+		{WarConfigType, WarArtifactConfig{}, "Details required to containerize a war file"},
+		{EarConfigType, EarArtifactConfig{}, "Details required to containerize an ear file"},
+		{CloudFoundryConfigType, CloudFoundryConfig{}, "Cloud Foundry application details"},
+		{ContainerizationOptionsConfigType, ContainerizationOptionsConfig{}, "Containerization options available for the service"},
+		{MainframeAssessmentConfigType, MainframeAssessment{}, "Mainframe assets found in a directory and the suggested migration strategy"},
+		{TemplateConfigType, TemplateConfig{}, "Values that are filled into the templates of the transformer"},
+	}
+	for _, builtIn := range builtInConfigTypes {
+		if err := RegisterConfigType(builtIn.configType, builtIn.obj, builtIn.description); err != nil {
+			logrus.Errorf("failed to register the built-in config type %s . Error: %q", builtIn.configType, err)
+		}
+	}
+}
+
+// RegisterConfigType registers a config type along with the JSON schema generated from the given object
+func RegisterConfigType(configType transformertypes.ConfigType, obj interface{}, description string) error {
+	if configType == "" {
+		return fmt.Errorf("the config type name is empty")
+	}
+	if obj == nil {
+		return fmt.Errorf("the object for the config type %s is nil", configType)
+	}
+	registeredConfigTypesMutex.Lock()
+	defer registeredConfigTypesMutex.Unlock()
+	if _, ok := registeredConfigTypes[configType]; ok {
+		return fmt.Errorf("the config type %s has already been registered", configType)
+	}
+	registeredConfigTypes[configType] = RegisteredConfigType{
+		ConfigType:  configType,
+		Description: description,
+		Schema:      jsonschema.GenerateSchema(reflect.TypeOf(obj), string(configType)),
+	}
+	return nil
+}
+
+// GetRegisteredConfigTypes returns all the registered config types sorted by name
+func GetRegisteredConfigTypes() []RegisteredConfigType {
+	registeredConfigTypesMutex.RLock()
+	defer registeredConfigTypesMutex.RUnlock()
+	configTypes := []RegisteredConfigType{}
+	for _, registered := range registeredConfigTypes {
+		configTypes = append(configTypes, registered)
+	}
+	sort.Slice(configTypes, func(i, j int) bool { return configTypes[i].ConfigType < configTypes[j].ConfigType })
+	return configTypes
+}
+
+// GetRegisteredConfigType returns the registered config type with the given name
+func GetRegisteredConfigType(configType transformertypes.ConfigType) (RegisteredConfigType, bool) {
+	registeredConfigTypesMutex.RLock()
+	defer registeredConfigTypesMutex.RUnlock()
+	registered, ok := registeredConfigTypes[configType]
+	return registered, ok
+}
+
+// ValidateConfig validates the config against the schema of its config type.
+// Configs of unregistered config types are not validated.
+func ValidateConfig(configType transformertypes.ConfigType, config interface{}) error {
+	registered, ok := GetRegisteredConfigType(configType)
+	if !ok {
+		return nil
+	}
+	configBytes, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the config of type %s to yaml. Error: %q", configType, err)
+	}
+	var obj interface{}
+	if err := yaml.Unmarshal(configBytes, &obj); err != nil {
+		return fmt.Errorf("failed to unmarshal the config of type %s from yaml. Error: %q", configType, err)
+	}
+	if err := jsonschema.Validate(registered.Schema, obj); err != nil {
+		return fmt.Errorf("the config of type %s is invalid. Error: %q", configType, err)
+	}
+	return nil
+}
+
+// GetInvalidConfigErrors returns the errors of all the configs of the artifact that are invalid against their registered schemas.
+// The configs are left as they are, since the schemas are generated from the types and might not describe all the valid values.
+func GetInvalidConfigErrors(artifact transformertypes.Artifact) []error {
+	configTypes := []string{}
+	for configType := range artifact.Configs {
+		configTypes = append(configTypes, configType)
+	}
+	sort.Strings(configTypes)
+	errs := []error{}
+	for _, configType := range configTypes {
+		if err := ValidateConfig(configType, artifact.Configs[configType]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// ValidateArtifactConfigs validates all the configs of the artifact against their registered schemas
+func ValidateArtifactConfigs(artifact transformertypes.Artifact) error {
+	configTypes := []string{}
+	for configType := range artifact.Configs {
+		configTypes = append(configTypes, configType)
+	}
+	sort.Strings(configTypes)
+	for _, configType := range configTypes {
+		if err := ValidateConfig(configType, artifact.Configs[configType]); err != nil {
+			return fmt.Errorf("artifact %s of type %s has an invalid config. Error: %q", artifact.Name, artifact.Type, err)
+		}
+	}
+	return nil
+}
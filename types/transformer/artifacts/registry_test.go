@@ -0,0 +1,282 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package artifacts_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Run("valid config struct", func(t *testing.T) {
+		if err := artifacts.ValidateConfig(artifacts.ServiceConfigType, artifacts.ServiceConfig{ServiceName: "svc1"}); err != nil {
+			t.Fatalf("expected the config to be valid. Error: %q", err)
+		}
+	})
+
+	t.Run("valid config map", func(t *testing.T) {
+		config := map[string]interface{}{"serviceName": "svc1"}
+		if err := artifacts.ValidateConfig(artifacts.ServiceConfigType, config); err != nil {
+			t.Fatalf("expected the config to be valid. Error: %q", err)
+		}
+	})
+
+	t.Run("config map with wrong field type", func(t *testing.T) {
+		config := map[string]interface{}{"serviceName": []string{"svc1"}}
+		if err := artifacts.ValidateConfig(artifacts.ServiceConfigType, config); err == nil {
+			t.Fatal("expected the config to be invalid")
+		}
+	})
+
+	t.Run("config with nil pointers and slices", func(t *testing.T) {
+		if err := artifacts.ValidateConfig(artifacts.SpringBootConfigType, artifacts.SpringBootConfig{SpringBootAppName: "app1"}); err != nil {
+			t.Fatalf("expected the config to be valid. Error: %q", err)
+		}
+	})
+
+	t.Run("ir config", func(t *testing.T) {
+		irConfig := ir.NewIR()
+		irConfig.Name = "app1"
+		irConfig.Services["svc1"] = ir.NewServiceWithName("svc1")
+		if err := artifacts.ValidateConfig(ir.IRConfigType, irConfig); err != nil {
+			t.Fatalf("expected the config to be valid. Error: %q", err)
+		}
+	})
+
+	t.Run("template config", func(t *testing.T) {
+		config := map[string]interface{}{"port": 8080, "nested": map[string]interface{}{"enabled": true}}
+		if err := artifacts.ValidateConfig(artifacts.TemplateConfigType, config); err != nil {
+			t.Fatalf("expected the config to be valid. Error: %q", err)
+		}
+		if err := artifacts.ValidateConfig(artifacts.TemplateConfigType, nil); err != nil {
+			t.Fatalf("expected an empty config to be valid. Error: %q", err)
+		}
+		if err := artifacts.ValidateConfig(artifacts.TemplateConfigType, "port=8080"); err == nil {
+			t.Fatal("expected the config to be invalid")
+		}
+	})
+
+	t.Run("unregistered config type", func(t *testing.T) {
+		if err := artifacts.ValidateConfig("UnregisteredConfigType", 42); err != nil {
+			t.Fatalf("expected configs of unregistered types to be skipped. Error: %q", err)
+		}
+	})
+}
+
+func TestRegisterConfigType(t *testing.T) {
+	t.Run("register a new config type", func(t *testing.T) {
+		type testConfig struct {
+			Count int `yaml:"count"`
+		}
+		configType := transformertypes.ConfigType("TestRegisterConfigType")
+		if err := artifacts.RegisterConfigType(configType, testConfig{}, "test config"); err != nil {
+			t.Fatalf("failed to register the config type. Error: %q", err)
+		}
+		if _, ok := artifacts.GetRegisteredConfigType(configType); !ok {
+			t.Fatal("the config type was not found after registering it")
+		}
+		if err := artifacts.ValidateConfig(configType, map[string]interface{}{"count": "one"}); err == nil {
+			t.Fatal("expected the config to be invalid")
+		}
+		if err := artifacts.RegisterConfigType(configType, testConfig{}, "test config"); err == nil {
+			t.Fatal("expected registering the same config type twice to fail")
+		}
+	})
+}
+
+func TestGetInvalidConfigErrors(t *testing.T) {
+	t.Run("keep the invalid configs", func(t *testing.T) {
+		artifact := transformertypes.Artifact{
+			Name: "svc1",
+			Configs: map[transformertypes.ConfigType]interface{}{
+				artifacts.ServiceConfigType:  map[string]interface{}{"serviceName": []string{"svc1"}},
+				artifacts.TemplateConfigType: map[string]interface{}{"port": 8080},
+			},
+		}
+		errs := artifacts.GetInvalidConfigErrors(artifact)
+		if len(errs) != 1 {
+			t.Fatalf("expected one invalid config. Actual: %+v", errs)
+		}
+		if len(artifact.Configs) != 2 {
+			t.Fatalf("expected the configs of the artifact to be left as they are. Actual: %+v", artifact.Configs)
+		}
+	})
+
+	t.Run("valid artifact", func(t *testing.T) {
+		artifact := transformertypes.Artifact{
+			Name:    "svc1",
+			Configs: map[transformertypes.ConfigType]interface{}{artifacts.ServiceConfigType: artifacts.ServiceConfig{ServiceName: "svc1"}},
+		}
+		if errs := artifacts.GetInvalidConfigErrors(artifact); len(errs) != 0 {
+			t.Fatalf("expected no invalid configs. Actual: %+v", errs)
+		}
+	})
+}
+
+func getTestIR() ir.IR {
+	irConfig := ir.NewIR()
+	irConfig.Name = "app1"
+	service := ir.NewServiceWithName("svc1")
+	service.Annotations = map[string]string{"move2kube.konveyor.io/source": "compose"}
+	service.Labels = map[string]string{"tier": "backend"}
+	service.Replicas = 2
+	service.ReplicasSource = "docker-compose.yaml"
+	service.Networks = []string{"backend"}
+	service.NetworkAliases = []string{"db-client"}
+	service.ServiceToPodPortForwardings = []ir.ServiceToPodPortForwarding{{
+		ServicePort:    networking.ServiceBackendPort{Name: "http", Number: 80},
+		PodPort:        networking.ServiceBackendPort{Number: 8080},
+		ServiceRelPath: "/api",
+		ServiceType:    core.ServiceTypeClusterIP,
+		AppProtocol:    ir.H2CAppProtocol,
+	}}
+	service.ExternalEndpoints = []ir.ExternalEndpoint{{Kind: "soap", URL: "http://legacy:8080/ws", Host: "legacy", Port: 8080, Source: "src/Client.java:12"}}
+	service.EventSources = []ir.EventSource{{Type: ir.KafkaEventSourceType, Host: "kafka:9092", Source: "spring-kafka"}}
+	service.DeviceRequirements = []ir.DeviceRequirement{{Resource: ir.NvidiaGPUResource, Count: 1, Source: "torch"}}
+	service.MaxSurge = "25%"
+	service.MaxUnavailable = "0"
+	service.Containers = []core.Container{{
+		Name:    "svc1",
+		Image:   "quay.io/org/svc1:latest",
+		Command: []string{"java"},
+		Args:    []string{"-jar", "app.jar"},
+		Ports:   []core.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: core.ProtocolTCP}},
+		Env: []core.EnvVar{
+			{Name: "MODE", Value: "prod"},
+			{Name: "PASSWORD", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "svc1"}, Key: "password"}}},
+		},
+		Resources: core.ResourceRequirements{
+			Limits:   core.ResourceList{core.ResourceMemory: resource.MustParse("512Mi"), core.ResourceCPU: resource.MustParse("500m")},
+			Requests: core.ResourceList{core.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		VolumeMounts: []core.VolumeMount{{Name: "data", MountPath: "/data"}},
+		LivenessProbe: &core.Probe{
+			ProbeHandler:  core.ProbeHandler{HTTPGet: &core.HTTPGetAction{Path: "/health", Port: intstr.FromInt(8080)}},
+			PeriodSeconds: 30,
+		},
+	}}
+	service.Volumes = []core.Volume{{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}}
+	service.RestartPolicy = core.RestartPolicyAlways
+	irConfig.Services["svc1"] = service
+	irConfig.ContainerImages["quay.io/org/svc1:latest"] = ir.ContainerImage{
+		ExposedPorts: []int32{8080},
+		UserID:       1001,
+		AccessedDirs: []string{"/data"},
+		Build:        ir.ContainerBuild{ContainerBuildType: ir.DockerfileContainerBuildType, ContextPath: "svc1"},
+	}
+	irConfig.Storages = []ir.Storage{
+		{
+			Name:        "data",
+			Annotations: map[string]string{"backup": "true"},
+			PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{
+				AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+				Resources:   core.ResourceRequirements{Requests: core.ResourceList{core.ResourceStorage: resource.MustParse("1Gi")}},
+			},
+			StorageType: ir.PVCKind,
+			SourcePath:  "/var/lib/data",
+		},
+		{Name: "svc1", StorageType: ir.SecretKind, SecretType: core.SecretTypeOpaque, Content: map[string][]byte{"password": []byte("secret")}},
+	}
+	irConfig.ExternalServices = map[string]ir.ExternalService{"legacy": {Name: "legacy", Host: "legacy.example.com", IPs: []string{"10.0.0.1"}, Ports: []int32{8080}, Clients: []string{"svc1"}, EgressPolicy: true}}
+	return irConfig
+}
+
+// TestValidateBuiltInConfigs round trips populated configs of the built-in config types through yaml,
+// like the configs in a plan or passed to an external transformer, and validates both forms
+func TestValidateBuiltInConfigs(t *testing.T) {
+	springBootProfiles := []string{"dev", "prod"}
+	configs := map[transformertypes.ConfigType]interface{}{
+		artifacts.ServiceConfigType:   artifacts.ServiceConfig{ServiceName: "svc1"},
+		ir.IRConfigType:               getTestIR(),
+		artifacts.NewImagesConfigType: artifacts.NewImages{ImageNames: []string{"svc1", "svc2"}},
+		artifacts.ImageNameConfigType: artifacts.ImageName{ImageName: "svc1"},
+		artifacts.MavenConfigType: artifacts.MavenConfig{
+			MavenAppName:  "app1",
+			PackagingType: artifacts.WarPackaging,
+			MavenProfiles: []string{"dev"},
+			IsMvnwPresent: true,
+			ChildModules:  []artifacts.ChildModule{{Name: "core", RelPomPath: "core/pom.xml"}},
+		},
+		artifacts.GradleConfigType: artifacts.GradleConfig{
+			RootProjectName:  "app1",
+			PackagingType:    artifacts.JarPackaging,
+			IsGradlewPresent: true,
+			ChildModules:     []artifacts.GradleChildModule{{Name: "core", RelBuildScriptPath: "core/build.gradle"}},
+		},
+		artifacts.SpringBootConfigType: artifacts.SpringBootConfig{
+			SpringBootVersion:      "2.7.0",
+			SpringBootAppName:      "app1",
+			SpringBootProfiles:     &springBootProfiles,
+			SpringBootProfilePorts: map[string][]int32{"dev": {8080}, "prod": {80, 443}},
+		},
+		artifacts.DotNetConfigType: artifacts.DotNetConfig{
+			IsDotNetCore:          true,
+			DotNetAppName:         "app1",
+			IsSolutionFilePresent: true,
+			ChildProjects:         []artifacts.DotNetChildProject{{Name: "web", RelCSProjPath: "web/web.csproj", TargetFramework: "net6.0"}},
+		},
+		artifacts.JarConfigType:                     artifacts.JarArtifactConfig{Port: 8080, JavaVersion: "17", BuildContainerName: "builder", DeploymentFilePath: "target/app.jar", EnvVariables: map[string]string{"MODE": "prod"}},
+		artifacts.WarConfigType:                     artifacts.WarArtifactConfig{Port: 8080, JavaVersion: "11", DeploymentFilePath: "target/app.war"},
+		artifacts.EarConfigType:                     artifacts.EarArtifactConfig{Port: 9080, JavaVersion: "8", DeploymentFilePath: "target/app.ear"},
+		artifacts.CloudFoundryConfigType:            artifacts.CloudFoundryConfig{ServiceName: "svc1", ImageName: "svc1"},
+		artifacts.ContainerizationOptionsConfigType: artifacts.ContainerizationOptionsConfig{"Maven", "Dockerfile"},
+		artifacts.MainframeAssessmentConfigType: artifacts.MainframeAssessment{
+			Programs:         []artifacts.MainframeProgram{{Path: "cobol/ACCT.cbl", Lines: 120, Copybooks: []string{"ACCTREC"}, Calls: []string{"DATEUTIL"}, UsesCICS: true, UsesSQL: true}},
+			Copybooks:        []string{"copybook/ACCTREC.cpy"},
+			Jobs:             []artifacts.MainframeJob{{Path: "jcl/NIGHTLY.jcl", Programs: []string{"ACCT"}, Datasets: []string{"PROD.ACCT.DATA"}, UsesVSAM: true}},
+			CICSDefinitions:  []string{"csd/ACCT.csd"},
+			CICSTransactions: []string{"ACCT"},
+			BMSMaps:          []string{"bms/ACCTMAP.bms"},
+			Strategy:         "rehost",
+			Reasons:          []string{"uses CICS"},
+		},
+		artifacts.TemplateConfigType: artifacts.TemplateConfig{"port": 8080, "env": []interface{}{"dev", "prod"}, "nested": map[string]interface{}{"enabled": true}},
+	}
+	for _, registered := range artifacts.GetRegisteredConfigTypes() {
+		// the config types registered by the other tests are not built in
+		if _, ok := configs[registered.ConfigType]; !ok && !strings.HasPrefix(registered.ConfigType, "Test") {
+			t.Fatalf("the built-in config type %s is not tested", registered.ConfigType)
+		}
+	}
+	for configType, config := range configs {
+		t.Run(configType, func(t *testing.T) {
+			if err := artifacts.ValidateConfig(configType, config); err != nil {
+				t.Fatalf("expected the config to be valid. Error: %q", err)
+			}
+			configBytes, err := yaml.Marshal(config)
+			if err != nil {
+				t.Fatalf("failed to marshal the config to yaml. Error: %q", err)
+			}
+			var decoded interface{}
+			if err := yaml.Unmarshal(configBytes, &decoded); err != nil {
+				t.Fatalf("failed to unmarshal the config from yaml. Error: %q", err)
+			}
+			if err := artifacts.ValidateConfig(configType, decoded); err != nil {
+				t.Fatalf("expected the decoded config to be valid. Error: %q\nConfig:\n%s", err, configBytes)
+			}
+		})
+	}
+}
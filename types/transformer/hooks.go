@@ -0,0 +1,64 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// HooksKind represents the Hooks kind
+const HooksKind = "Hooks"
+
+// Hooks are commands and webhooks that are run before and after the transform and the transformers, with the metadata of the run
+type Hooks struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             HooksSpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// HooksSpec stores the data
+type HooksSpec struct {
+	PreTransform  []Hook                      `yaml:"preTransform,omitempty" json:"preTransform,omitempty"`
+	PostTransform []Hook                      `yaml:"postTransform,omitempty" json:"postTransform,omitempty"`
+	Transformers  map[string]TransformerHooks `yaml:"transformers,omitempty" json:"transformers,omitempty"` // [transformer name]
+}
+
+// TransformerHooks are the hooks that are run before and after a transformer
+type TransformerHooks struct {
+	Pre  []Hook `yaml:"pre,omitempty" json:"pre,omitempty"`
+	Post []Hook `yaml:"post,omitempty" json:"post,omitempty"`
+}
+
+// Hook is a command or a webhook. The metadata of the run is given to the command on stdin and posted to the webhook as JSON.
+type Hook struct {
+	Name    string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Command []string          `yaml:"command,omitempty" json:"command,omitempty"`
+	URL     string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"` // environment variables like ${TOKEN} are expanded
+	// FailOnError stops the transform when a pre hook fails, and skips the transformer when a pre hook of a transformer fails
+	FailOnError bool `yaml:"failOnError,omitempty" json:"failOnError,omitempty"`
+}
+
+// NewHooks creates a new instance of hooks
+func NewHooks() Hooks {
+	return Hooks{
+		TypeMeta: types.TypeMeta{
+			Kind:       HooksKind,
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
@@ -42,4 +42,6 @@ type PathMapping struct {
 	SrcPath        string          `yaml:"sourcePath" json:"sourcePath" m2kpath:"normal"`
 	DestPath       string          `yaml:"destinationPath" json:"destinationPath" m2kpath:"normal"` // Relative to output directory
 	TemplateConfig interface{}     `yaml:"templateConfig" json:"templateConfig"`
+	// TemplateLibraries are the paths of the template library files whose templates can be included in the template
+	TemplateLibraries []string `yaml:"-" json:"-"`
 }
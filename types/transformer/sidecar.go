@@ -0,0 +1,50 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// SidecarKind represents the Sidecar kind
+const SidecarKind = "Sidecar"
+
+// Sidecar is a set of containers, volumes and environment variables that are injected into the pods of the generated services
+type Sidecar struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             SidecarSpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// SidecarSpec stores the data
+type SidecarSpec struct {
+	FilePath     string      `yaml:"-" json:"-"`
+	Services     []string    `yaml:"services,omitempty" json:"services,omitempty"`         // glob patterns of the names of the services. All the services if it is empty.
+	PodSpec      interface{} `yaml:"podSpec" json:"podSpec"`                               // corev1.PodSpec with the containers, init containers and volumes to inject
+	Env          interface{} `yaml:"env,omitempty" json:"env,omitempty"`                   // []corev1.EnvVar that are added to the containers of the service
+	VolumeMounts interface{} `yaml:"volumeMounts,omitempty" json:"volumeMounts,omitempty"` // []corev1.VolumeMount that are added to the containers of the service
+}
+
+// NewSidecar creates a new instance of sidecar
+func NewSidecar() Sidecar {
+	return Sidecar{
+		TypeMeta: types.TypeMeta{
+			Kind:       SidecarKind,
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+	}
+}
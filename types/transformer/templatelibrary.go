@@ -0,0 +1,51 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// TemplateLibraryKind represents the TemplateLibrary kind
+const TemplateLibraryKind = "TemplateLibrary"
+
+// TemplateLibrary is a versioned set of named templates that the templates of the transformers can include
+type TemplateLibrary struct {
+	types.TypeMeta   `yaml:",inline" json:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Spec             TemplateLibrarySpec `yaml:"spec,omitempty" json:"spec,omitempty"`
+}
+
+// TemplateLibrarySpec stores the data
+type TemplateLibrarySpec struct {
+	FilePath     string `yaml:"-" json:"-"`
+	Version      string `yaml:"version" json:"version"`
+	TemplatesDir string `yaml:"templates" json:"templates"` // Relative to yaml directory
+}
+
+// NewTemplateLibrary creates a new instance of template library
+func NewTemplateLibrary() TemplateLibrary {
+	return TemplateLibrary{
+		TypeMeta: types.TypeMeta{
+			Kind:       TemplateLibraryKind,
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		Spec: TemplateLibrarySpec{
+			TemplatesDir: "templates/",
+		},
+	}
+}
@@ -18,6 +18,7 @@ package transformer
 
 import (
 	"github.com/konveyor/move2kube/types"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -44,10 +45,25 @@ type TransformerSpec struct {
 	Override           interface{}                            `yaml:"override" json:"override"`     // metav1.LabelSelector
 	DependencySelector labels.Selector                        `yaml:"-" json:"-"`
 	OverrideSelector   labels.Selector                        `yaml:"-" json:"-"`
-	TemplatesDir       string                                 `yaml:"templates" json:"templates"` // Relative to yaml directory or working directory in image
+	TemplatesDir       string                                 `yaml:"templates" json:"templates"`                                     // Relative to yaml directory or working directory in image
+	TemplateLibraries  []string                               `yaml:"templateLibraries,omitempty" json:"templateLibraries,omitempty"` // name or name@version constraint
+	Questions          []Question                             `yaml:"questions,omitempty" json:"questions,omitempty"`
+	FeatureGate        string                                 `yaml:"featureGate,omitempty" json:"featureGate,omitempty"` // the transformer is only used if the feature gate is enabled
+	Priority           int                                    `yaml:"priority,omitempty" json:"priority,omitempty"`       // used by the PreferHigherPriority merge rule, higher is preferred
 	Config             interface{}                            `yaml:"config" json:"config"`
 }
 
+// Question is a question declared in the transformer config, whose answer is added to the config of the templates
+type Question struct {
+	ID          string                   `yaml:"id" json:"id"` // relative to the transformer key, unless it starts with the base key
+	Type        qatypes.SolutionFormType `yaml:"type" json:"type"`
+	Description string                   `yaml:"description" json:"description"`
+	Hints       []string                 `yaml:"hints,omitempty" json:"hints,omitempty"`
+	Options     []string                 `yaml:"options,omitempty" json:"options,omitempty"`
+	Default     interface{}              `yaml:"default,omitempty" json:"default,omitempty"`
+	ConfigPath  string                   `yaml:"configPath,omitempty" json:"configPath,omitempty"` // dot separated path in the template config, defaults to the id
+}
+
 // DirectoryDetect stores the config on how to iterate over the directories
 type DirectoryDetect struct {
 	Levels int `yaml:"levels"` // Supports only 0,1 and -1 currently - default behaviour is -1, when directory detect section is missing
@@ -65,11 +81,27 @@ const (
 	OnDemandPassThrough ArtifactProcessingMode = "OnDemandPassThrough"
 )
 
+// ArtifactMergeRule denotes how the artifacts of the same type and name are combined before a transformer consumes them
+type ArtifactMergeRule string
+
+const (
+	// KeepAll keeps all the artifacts, even exact duplicates. It is the default.
+	KeepAll ArtifactMergeRule = "KeepAll"
+	// UnionPaths combines the artifacts into one with the paths of all of them and the configs of the first one
+	UnionPaths ArtifactMergeRule = "UnionPaths"
+	// DeepMerge combines the artifacts into one with the paths of all of them and their configs deep merged
+	DeepMerge ArtifactMergeRule = "DeepMerge"
+	// PreferHigherPriority keeps only the artifact produced by the transformer with the highest priority
+	PreferHigherPriority ArtifactMergeRule = "PreferHigherPriority"
+)
+
 // ArtifactProcessConfig stores config for preprocessing artifact
 type ArtifactProcessConfig struct {
 	Merge    bool                   `yaml:"merge" json:"merge"`
 	Mode     ArtifactProcessingMode `yaml:"mode" json:"mode"`
 	Disabled bool                   `yaml:"disabled" json:"disabled"` // default is false
+
+	MergeRule ArtifactMergeRule `yaml:"mergeRule,omitempty" json:"mergeRule,omitempty"` // how the near duplicate artifacts are combined, default is KeepAll
 }
 
 // ProducedArtifact stores config for postprocessing produced artifact